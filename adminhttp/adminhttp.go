@@ -0,0 +1,221 @@
+// Package adminhttp provides an http.Handler exposing the admin and debug
+// endpoints of a cache.Cache, so services do not each re-implement them:
+//
+//	GET  /stats                  cache statistics
+//	GET  /health                 health status, 503 when unhealthy
+//	GET  /config                 sanitized description of the effective configuration
+//	GET  /peers                  other pods sharing the sync channel
+//	GET  /keys?prefix=           keys held in the local cache
+//	GET  /get?key=               value and HitInfo of a key, 404 when not found
+//	POST /invalidate?key=        delete a key from every level and every pod
+//	POST /clear-prefix?prefix=   delete every key with a prefix from every level and every pod
+//
+// Responses are JSON. Mount the handler under a path prefix with http.StripPrefix,
+// and protect it like any other admin endpoint.
+package adminhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/huykn/distributed-cache/cache"
+)
+
+// DefaultTimeout bounds each cache operation made by the handler.
+const DefaultTimeout = 5 * time.Second
+
+// handler serves the admin endpoints of a cache.
+type handler struct {
+	cache   cache.Cache
+	timeout time.Duration
+}
+
+// NewHandler returns an http.Handler serving the admin endpoints of c.
+func NewHandler(c cache.Cache) http.Handler {
+	h := &handler{cache: c, timeout: DefaultTimeout}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /stats", h.stats)
+	mux.HandleFunc("GET /health", h.health)
+	mux.HandleFunc("GET /config", h.config)
+	mux.HandleFunc("GET /peers", h.peers)
+	mux.HandleFunc("GET /keys", h.keys)
+	mux.HandleFunc("GET /get", h.get)
+	mux.HandleFunc("POST /invalidate", h.invalidate)
+	mux.HandleFunc("POST /clear-prefix", h.clearPrefix)
+	return mux
+}
+
+// PeersResponse is the body returned by /peers.
+type PeersResponse struct {
+	Peers []cache.Peer `json:"peers"`
+	Count int          `json:"count"`
+}
+
+// KeysResponse is the body returned by /keys.
+type KeysResponse struct {
+	Keys  []string `json:"keys"`
+	Count int      `json:"count"`
+}
+
+// GetResponse is the body returned by /get.
+type GetResponse struct {
+	Key   string          `json:"key"`
+	Found bool            `json:"found"`
+	Value json.RawMessage `json:"value,omitempty"`
+	Info  cache.HitInfo   `json:"info"`
+}
+
+// InvalidateResponse is the body returned by /invalidate.
+type InvalidateResponse struct {
+	Key string `json:"key"`
+}
+
+// ClearPrefixResponse is the body returned by /clear-prefix.
+type ClearPrefixResponse struct {
+	Prefix  string `json:"prefix"`
+	Deleted int    `json:"deleted"`
+}
+
+// errorResponse is the body returned on failure.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (h *handler) stats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.cache.Stats())
+}
+
+func (h *handler) health(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := h.context(r)
+	defer cancel()
+
+	status := h.cache.Health(ctx)
+	code := http.StatusOK
+	if !status.Healthy {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, status)
+}
+
+func (h *handler) config(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.cache.Describe())
+}
+
+func (h *handler) peers(w http.ResponseWriter, r *http.Request) {
+	peers := h.cache.Peers()
+	if peers == nil {
+		peers = make([]cache.Peer, 0)
+	}
+	writeJSON(w, http.StatusOK, PeersResponse{Peers: peers, Count: len(peers)})
+}
+
+func (h *handler) keys(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	keys := make([]string, 0)
+	for _, key := range h.cache.LocalKeys() {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	writeJSON(w, http.StatusOK, KeysResponse{Keys: keys, Count: len(keys)})
+}
+
+func (h *handler) get(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		writeError(w, http.StatusBadRequest, "missing key")
+		return
+	}
+	ctx, cancel := h.context(r)
+	defer cancel()
+
+	value, found, info := h.cache.GetWithInfo(ctx, key)
+	resp := GetResponse{Key: key, Found: found, Info: info}
+	if !found {
+		writeJSON(w, http.StatusNotFound, resp)
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		// Report values that cannot be encoded as JSON by their Go representation
+		data, _ = json.Marshal(fmt.Sprintf("%v", value))
+	}
+	resp.Value = data
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *handler) invalidate(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		writeError(w, http.StatusBadRequest, "missing key")
+		return
+	}
+	ctx, cancel := h.context(r)
+	defer cancel()
+
+	if err := h.cache.Delete(ctx, key); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, InvalidateResponse{Key: key})
+}
+
+// clearPrefix deletes the keys with a prefix found in the remote store or in
+// this pod's local cache. Keys are deleted through the cache, so other pods
+// drop them too. Without a store that can scan keys only local keys are found.
+func (h *handler) clearPrefix(w http.ResponseWriter, r *http.Request) {
+	prefix := r.URL.Query().Get("prefix")
+	if prefix == "" {
+		writeError(w, http.StatusBadRequest, "missing prefix; use Clear to remove every key")
+		return
+	}
+	ctx, cancel := h.context(r)
+	defer cancel()
+
+	deleted := make(map[string]bool)
+	deleteKeys := func(keys []string) error {
+		for _, key := range keys {
+			if deleted[key] || !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			if err := h.cache.Delete(ctx, key); err != nil {
+				return err
+			}
+			deleted[key] = true
+		}
+		return nil
+	}
+	err := h.cache.RemoteKeys(ctx, prefix, deleteKeys)
+	if err == nil || errors.Is(err, cache.ErrScanNotSupported) {
+		err = deleteKeys(h.cache.LocalKeys())
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ClearPrefixResponse{Prefix: prefix, Deleted: len(deleted)})
+}
+
+// context returns the request context bounded by the handler timeout.
+func (h *handler) context(r *http.Request) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(r.Context(), h.timeout)
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a JSON error response.
+func writeError(w http.ResponseWriter, code int, message string) {
+	writeJSON(w, code, errorResponse{Error: message})
+}
@@ -0,0 +1,176 @@
+package adminhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/huykn/distributed-cache/cache"
+	"github.com/huykn/distributed-cache/cachetest"
+)
+
+// serve sends a request to a handler bound to c and decodes the JSON response into v.
+func serve(t *testing.T, c cache.Cache, method, target string, v any) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	NewHandler(c).ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	if v != nil {
+		if err := json.NewDecoder(rec.Body).Decode(v); err != nil {
+			t.Fatalf("%s %s: failed to decode response: %v", method, target, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestHandlerKeysAndGet(t *testing.T) {
+	c := cachetest.New()
+	c.Seed(map[string]any{"user:1": "alice", "user:2": "bob", "order:1": 42})
+
+	var keys KeysResponse
+	if code := serve(t, c, http.MethodGet, "/keys?prefix=user:", &keys); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if keys.Count != 2 || keys.Keys[0] != "user:1" || keys.Keys[1] != "user:2" {
+		t.Errorf("Expected the user keys, got %+v", keys)
+	}
+
+	var got GetResponse
+	if code := serve(t, c, http.MethodGet, "/get?key=user:1", &got); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if !got.Found || string(got.Value) != `"alice"` {
+		t.Errorf("Expected alice, got %+v", got)
+	}
+
+	if code := serve(t, c, http.MethodGet, "/get?key=missing", &got); code != http.StatusNotFound || got.Found {
+		t.Errorf("Expected 404 for a missing key, got %d %+v", code, got)
+	}
+	if code := serve(t, c, http.MethodGet, "/get", nil); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a key, got %d", code)
+	}
+}
+
+func TestHandlerInvalidateAndClearPrefix(t *testing.T) {
+	c := cachetest.New()
+	c.Seed(map[string]any{"user:1": "alice", "user:2": "bob", "order:1": 42})
+
+	if code := serve(t, c, http.MethodGet, "/invalidate?key=order:1", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected mutations to require POST, got %d", code)
+	}
+	if code := serve(t, c, http.MethodPost, "/invalidate?key=order:1", nil); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+
+	var cleared ClearPrefixResponse
+	if code := serve(t, c, http.MethodPost, "/clear-prefix?prefix=user:", &cleared); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if cleared.Deleted != 2 {
+		t.Errorf("Expected 2 keys deleted, got %d", cleared.Deleted)
+	}
+	if keys := c.LocalKeys(); len(keys) != 0 {
+		t.Errorf("Expected every key to be deleted, got %v", keys)
+	}
+	if code := serve(t, c, http.MethodPost, "/clear-prefix", nil); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a prefix, got %d", code)
+	}
+
+	c.Seed(map[string]any{"user:3": "carol"})
+	c.FailOn(cachetest.OpDelete, errors.New("redis down"))
+	if code := serve(t, c, http.MethodPost, "/invalidate?key=user:3", nil); code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 when Delete fails, got %d", code)
+	}
+}
+
+// remoteOnly is a cache whose keys are all held in the remote store, as on a
+// pod that never read them.
+type remoteOnly struct {
+	*cachetest.Cache
+}
+
+func (remoteOnly) LocalKeys() []string { return nil }
+
+func TestHandlerClearPrefixRemoteKeys(t *testing.T) {
+	c := cachetest.New()
+	c.Seed(map[string]any{"user:1": "alice", "user:2": "bob", "order:1": 42})
+
+	var cleared ClearPrefixResponse
+	if code := serve(t, remoteOnly{c}, http.MethodPost, "/clear-prefix?prefix=user:", &cleared); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if cleared.Deleted != 2 {
+		t.Errorf("Expected the 2 remote keys to be deleted, got %d", cleared.Deleted)
+	}
+	if keys := c.LocalKeys(); len(keys) != 1 || keys[0] != "order:1" {
+		t.Errorf("Expected only order:1 to be left, got %v", keys)
+	}
+
+	// Without a store that can scan keys, the local keys are still cleared
+	c.Seed(map[string]any{"user:3": "carol"})
+	c.FailOn(cachetest.OpRemoteKeys, cache.ErrScanNotSupported)
+	if code := serve(t, c, http.MethodPost, "/clear-prefix?prefix=user:", &cleared); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if cleared.Deleted != 1 {
+		t.Errorf("Expected the local key to be deleted, got %d", cleared.Deleted)
+	}
+
+	c.FailOn(cachetest.OpRemoteKeys, errors.New("redis down"))
+	if code := serve(t, c, http.MethodPost, "/clear-prefix?prefix=user:", nil); code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 when the scan fails, got %d", code)
+	}
+}
+
+func TestHandlerConfig(t *testing.T) {
+	var desc cache.Description
+	if code := serve(t, cachetest.New(), http.MethodGet, "/config", &desc); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if desc.PodID != "cachetest" {
+		t.Errorf("Expected the description of the cache, got %+v", desc)
+	}
+}
+
+func TestHandlerPeers(t *testing.T) {
+	c := cachetest.New()
+
+	var peers PeersResponse
+	if code := serve(t, c, http.MethodGet, "/peers", &peers); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if peers.Count != 0 || peers.Peers == nil {
+		t.Errorf("Expected an empty list of peers, got %+v", peers)
+	}
+
+	c.SetPeers(cache.Peer{PodID: "pod-2", Version: "v1.0.4"})
+	if code := serve(t, c, http.MethodGet, "/peers", &peers); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if peers.Count != 1 || peers.Peers[0].PodID != "pod-2" || peers.Peers[0].Version != "v1.0.4" {
+		t.Errorf("Expected pod-2, got %+v", peers)
+	}
+}
+
+func TestHandlerStatsAndHealth(t *testing.T) {
+	c := cachetest.New()
+	_, _ = c.Get(context.Background(), "missing")
+
+	var stats map[string]any
+	if code := serve(t, c, http.MethodGet, "/stats", &stats); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if stats["LocalMisses"] != float64(1) {
+		t.Errorf("Expected one local miss, got %v", stats["LocalMisses"])
+	}
+
+	if code := serve(t, c, http.MethodGet, "/health", nil); code != http.StatusOK {
+		t.Errorf("Expected a healthy cache, got %d", code)
+	}
+	c.FailOn(cachetest.OpHealth, errors.New("redis down"))
+	if code := serve(t, c, http.MethodGet, "/health", nil); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 for an unhealthy cache, got %d", code)
+	}
+}
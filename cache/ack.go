@@ -0,0 +1,239 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ackPayload is the payload of ActionAckRequest and ActionAck events, which
+// name the write to confirm by the clock it was published with.
+type ackPayload struct {
+	ID    string `json:"id"`
+	Clock uint64 `json:"hlc"`
+	Value bool   `json:"value,omitempty"` // the write propagated its value rather than an invalidation
+}
+
+// publishedEvent receives the synchronization event published for a write.
+type publishedEvent struct {
+	event InvalidationEvent
+	sent  bool
+	err   error
+}
+
+// record records the outcome of publishing event. It is a no-op on a nil receiver.
+func (p *publishedEvent) record(event InvalidationEvent, err error) {
+	if p != nil {
+		p.event, p.sent, p.err = event, err == nil, err
+	}
+}
+
+// ackWaiter collects the pods acknowledging one SetAck.
+type ackWaiter struct {
+	key    string
+	clock  uint64
+	mu     sync.Mutex
+	pods   map[string]struct{}
+	notify chan struct{}
+}
+
+// count returns the number of distinct pods that acknowledged.
+func (w *ackWaiter) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.pods)
+}
+
+// ackWaiters holds the SetAck calls waiting for acknowledgements. The zero value is ready to use.
+type ackWaiters struct {
+	mu      sync.Mutex
+	waiters map[string]*ackWaiter
+}
+
+// add registers a waiter for the acknowledgements of id, confirming the write
+// to key stamped with clock.
+func (aw *ackWaiters) add(id, key string, clock uint64) *ackWaiter {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	if aw.waiters == nil {
+		aw.waiters = make(map[string]*ackWaiter)
+	}
+	w := &ackWaiter{key: key, clock: clock, pods: make(map[string]struct{}), notify: make(chan struct{}, 1)}
+	aw.waiters[id] = w
+	return w
+}
+
+// remove unregisters the waiter of id.
+func (aw *ackWaiters) remove(id string) {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	delete(aw.waiters, id)
+}
+
+// ack records that pod acknowledged id for the write to key stamped with clock.
+// Acknowledgements of unknown ids, such as those of other pods' SetAck calls,
+// and of other writes are ignored.
+func (aw *ackWaiters) ack(id, key string, clock uint64, pod string) {
+	aw.mu.Lock()
+	w := aw.waiters[id]
+	aw.mu.Unlock()
+	if w == nil || w.key != key || w.clock != clock {
+		return
+	}
+	w.mu.Lock()
+	w.pods[pod] = struct{}{}
+	w.mu.Unlock()
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// SetAck stores a value like Set, then waits until minAcks other pods confirm
+// they applied it, for at most timeout (ContextTimeout when 0). It returns the
+// number of pods that confirmed, and ErrInsufficientAcks when fewer than minAcks
+// did in time. With minAcks 0 it waits the whole timeout and counts every pod.
+//
+// Unlike Set, SetAck fails when the synchronization event cannot be published.
+// Pods confirm the write by the clock it was published with, and only when the
+// last write they applied to key is that one, holding its value unless it was
+// propagated as an invalidation, so a confirmation guarantees the pod reads the
+// new value. Writes whose event is not published, such as those queued by the
+// circuit breaker or over PublishRateLimit, are confirmed by no pod.
+func (sc *SyncedCache) SetAck(ctx context.Context, key string, value any, minAcks int, timeout time.Duration) (int, error) {
+	if sc.options.ReadOnly {
+		return 0, ErrReadOnly
+	}
+	if atomic.LoadInt32(&sc.closed) != 0 {
+		return 0, ErrCacheClosed
+	}
+	if timeout <= 0 {
+		timeout = sc.options.ContextTimeout
+	}
+	id, err := newLockToken()
+	if err != nil {
+		return 0, err
+	}
+
+	var written publishedEvent
+	cfg := sc.setConfig(key, nil)
+	cfg.published = &written
+	if err := sc.setInternal(ctx, key, value, cfg); err != nil {
+		return 0, err
+	}
+	if written.err != nil {
+		return 0, categorize(ErrPublish, written.err)
+	}
+	if !written.sent {
+		if minAcks > 0 {
+			return 0, ErrInsufficientAcks
+		}
+		return 0, nil
+	}
+	waiter := sc.acks.add(id, key, written.event.Clock)
+	defer sc.acks.remove(id)
+
+	data, _ := json.Marshal(ackPayload{ID: id, Clock: written.event.Clock, Value: written.event.Action == ActionSet})
+	err = sc.synchronizer.Publish(ctx, InvalidationEvent{
+		Key:    key,
+		Sender: sc.options.PodID,
+		Action: ActionAckRequest,
+		Value:  data,
+	})
+	if err != nil {
+		sc.reportError(OpSet, key, ErrPublish, err)
+		return 0, categorize(ErrPublish, err)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		acks := waiter.count()
+		if minAcks > 0 && acks >= minAcks {
+			return acks, nil
+		}
+		select {
+		case <-waiter.notify:
+			continue
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+		acks = waiter.count()
+		if acks < minAcks {
+			if sc.logging(DebugSync) {
+				sc.logger.Warn("SetAck: not enough pods confirmed", "key", key, "acks", acks, "min_acks", minAcks)
+			}
+			return acks, ErrInsufficientAcks
+		}
+		return acks, nil
+	}
+}
+
+// serveAck confirms an ActionAckRequest to its sender in the background when
+// this pod applied the requested write. The request is applied after the events
+// published before it for the same key, which are routed to the same channel and
+// apply worker.
+func (sc *SyncedCache) serveAck(event InvalidationEvent) {
+	var request ackPayload
+	if err := json.Unmarshal(event.Value, &request); err != nil || request.ID == "" {
+		return
+	}
+	if !sc.appliedWrite(event.Key, request.Clock, request.Value) {
+		if sc.debugging(DebugSync) {
+			sc.logger.Debug("SetAck: not confirming a write this pod does not hold", "key", event.Key, "target", event.Sender)
+		}
+		return
+	}
+
+	sc.goBackground(func(bgCtx context.Context) {
+		ctx, cancel := context.WithTimeout(bgCtx, sc.options.ContextTimeout)
+		defer cancel()
+
+		err := sc.synchronizer.Publish(ctx, InvalidationEvent{
+			Key:    event.Key,
+			Sender: sc.options.PodID,
+			Action: ActionAck,
+			Value:  event.Value,
+		})
+		if err != nil {
+			sc.reportError(OpSync, event.Key, ErrPublish, err)
+			if sc.logging(DebugSync) {
+				sc.logger.Error("SetAck: failed to confirm", "key", event.Key, "target", event.Sender, "error", err)
+			}
+		}
+	})
+}
+
+// appliedWrite reports whether the last write applied to key is the one stamped
+// with clock and, when it propagated its value, whether the local cache holds it.
+func (sc *SyncedCache) appliedWrite(key string, clock uint64, value bool) bool {
+	if clock == 0 || sc.clocks.last(key) != clock {
+		return false
+	}
+	if !value {
+		return true
+	}
+	if admitting, ok := sc.local.(AdmittingLocalCache); ok {
+		// Buffered sets, such as Ristretto's, become visible once applied
+		admitting.Wait()
+	}
+	if _, found := sc.local.Get(key); !found {
+		return false
+	}
+	return sc.entryInfos.clock(key) == clock
+}
+
+// applyAck records a confirmation of one of this pod's SetAck calls.
+func (sc *SyncedCache) applyAck(event InvalidationEvent) {
+	var ack ackPayload
+	if err := json.Unmarshal(event.Value, &ack); err != nil {
+		return
+	}
+	sc.acks.ack(ack.ID, event.Key, ack.Clock, event.Sender)
+}
+
+// ErrInsufficientAcks is returned by SetAck when fewer pods than requested
+// confirmed the value before the timeout.
+var ErrInsufficientAcks = NewError("not enough pods acknowledged the write")
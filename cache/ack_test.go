@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSyncedCacheSetAck(t *testing.T) {
+	bus := &cacheBus{}
+	writer, _ := bus.join(t, "pod-1", nil)
+	reader1, _ := bus.join(t, "pod-2", nil)
+	reader2, _ := bus.join(t, "pod-3", nil)
+	defer writer.Close()
+	defer reader1.Close()
+	defer reader2.Close()
+
+	ctx := context.Background()
+	acks, err := writer.SetAck(ctx, "config", "v2", 2, time.Second)
+	if err != nil || acks != 2 {
+		t.Fatalf("Expected 2 acks, got %d, %v", acks, err)
+	}
+	for _, reader := range []*SyncedCache{reader1, reader2} {
+		if value, found := reader.local.Get("config"); !found || value != "v2" {
+			t.Errorf("Expected %s to hold the acknowledged value, got %v", reader.options.PodID, value)
+		}
+	}
+
+	start := time.Now()
+	acks, err = writer.SetAck(ctx, "config", "v3", 3, 50*time.Millisecond)
+	if !errors.Is(err, ErrInsufficientAcks) || acks != 2 {
+		t.Fatalf("Expected ErrInsufficientAcks with 2 acks, got %d, %v", acks, err)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Error("Expected SetAck to wait for the timeout")
+	}
+
+	acks, err = writer.SetAck(ctx, "config", "v4", 0, 20*time.Millisecond)
+	if err != nil || acks != 2 {
+		t.Fatalf("Expected every pod to be counted, got %d, %v", acks, err)
+	}
+}
+
+func TestSyncedCacheSetAckIgnoresForeignAcks(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1"})
+	defer c.Close()
+
+	waiter := c.acks.add("mine", "config", 7)
+	c.acks.ack("other", "config", 7, "pod-2")
+	c.acks.ack("mine", "config", 6, "pod-3")
+	c.acks.ack("mine", "other", 7, "pod-3")
+	c.acks.ack("mine", "config", 7, "pod-2")
+	c.acks.ack("mine", "config", 7, "pod-2")
+	if count := waiter.count(); count != 1 {
+		t.Fatalf("Expected a single ack per pod for the awaited write, got %d", count)
+	}
+}
+
+func TestSyncedCacheSetAckOnlyCountsPodsHoldingTheWrite(t *testing.T) {
+	bus := &cacheBus{}
+	writer, _ := bus.join(t, "pod-1", nil)
+	reader, _ := bus.join(t, "pod-2", nil)
+	filtering, _ := bus.join(t, "pod-3", nil)
+	defer writer.Close()
+	defer reader.Close()
+	defer filtering.Close()
+	filtering.options.EventFilter = func(event InvalidationEvent) bool { return event.Key != "config" }
+
+	acks, err := writer.SetAck(context.Background(), "config", "v2", 2, 50*time.Millisecond)
+	if !errors.Is(err, ErrInsufficientAcks) || acks != 1 {
+		t.Fatalf("Expected only the pod applying the write to confirm, got %d, %v", acks, err)
+	}
+	if _, found := filtering.local.Get("config"); found {
+		t.Error("Expected the filtering pod not to hold the value")
+	}
+}
+
+func TestSyncedCacheSetAckPublishError(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1"})
+	defer c.Close()
+	c.synchronizer = &errorSynchronizer{publishError: errors.New("publish error")}
+
+	acks, err := c.SetAck(context.Background(), "config", "v2", 1, time.Second)
+	if !errors.Is(err, ErrPublish) || acks != 0 {
+		t.Fatalf("Expected the publish error of the write, got %d, %v", acks, err)
+	}
+}
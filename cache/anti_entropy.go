@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+)
+
+// digestChunkSize is the number of entries sent per digest event.
+const digestChunkSize = 500
+
+// antiEntropyDigest is the payload of an ActionDigest event, carrying the ETag of
+// the serialized value of local cache entries of the sending pod.
+type antiEntropyDigest struct {
+	ETags map[string]string `json:"etags"`
+}
+
+// startAntiEntropy starts publishing digests of the local cache every
+// Options.AntiEntropyInterval. It is a no-op when the interval is not set.
+func (sc *SyncedCache) startAntiEntropy() {
+	if sc.options.AntiEntropyInterval <= 0 {
+		return
+	}
+	sc.goBackground(func(ctx context.Context) {
+		ticker := time.NewTicker(sc.options.AntiEntropyInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := sc.sendDigests(ctx); err != nil {
+					sc.reportError(OpAntiEntropy, "", ErrPublish, err)
+					if sc.logging(DebugSync) {
+						sc.logger.Error("AntiEntropy: failed to publish digest", "error", err)
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// sendDigests publishes the ETags of the local cache entries in chunks.
+func (sc *SyncedCache) sendDigests(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, sc.options.ContextTimeout)
+	defer cancel()
+
+	digest := antiEntropyDigest{ETags: make(map[string]string)}
+	chunks := 0
+	publish := func() error {
+		data, err := json.Marshal(digest)
+		if err != nil {
+			return err
+		}
+		err = sc.synchronizer.Publish(ctx, InvalidationEvent{
+			Key:    "*",
+			Sender: sc.options.PodID,
+			Action: ActionDigest,
+			Value:  data,
+		})
+		chunks++
+		digest.ETags = make(map[string]string)
+		return err
+	}
+
+	for _, key := range sc.entryInfos.entries.Keys() {
+		if etag, ok := sc.localETag(key); ok {
+			digest.ETags[key] = etag
+		}
+		if len(digest.ETags) == digestChunkSize {
+			if err := publish(); err != nil {
+				return err
+			}
+		}
+	}
+	if len(digest.ETags) > 0 {
+		if err := publish(); err != nil {
+			return err
+		}
+	}
+	if sc.debugging(DebugSync) {
+		sc.logger.Debug("AntiEntropy: published digest", "chunks", chunks)
+	}
+	return nil
+}
+
+// localETag returns the ETag of the local cache entry for key, encoding the value
+// as a Set would have when neither its ETag nor its serialized bytes were kept.
+func (sc *SyncedCache) localETag(key string) (string, bool) {
+	value, found := sc.local.Get(key)
+	if !found || sc.entryInfos.expired(key) {
+		return "", false
+	}
+	if etag, ok := sc.entryInfos.etag(key); ok {
+		return etag, true
+	}
+	data, err := sc.encodeLocal(key, value)
+	if err != nil {
+		return "", false
+	}
+	return ETag(data), true
+}
+
+// applyDigest compares the digest of another pod with the local cache. Entries
+// both pods hold with different values are dropped and fetched again from the
+// remote store, so the pod holding a stale value converges on the stored one.
+func (sc *SyncedCache) applyDigest(event InvalidationEvent) {
+	if sc.options.AntiEntropyInterval <= 0 {
+		return
+	}
+	var digest antiEntropyDigest
+	if err := json.Unmarshal(event.Value, &digest); err != nil {
+		return
+	}
+
+	var diverged []string
+	for key, etag := range digest.ETags {
+		local, ok := sc.localETag(key)
+		if !ok || local == etag {
+			continue
+		}
+		sc.local.Delete(key)
+		sc.entryInfos.remove(key)
+		sc.changes.emit(ChangeEvent{Type: ChangeInvalidated, Key: key, Remote: true})
+		diverged = append(diverged, key)
+	}
+	if len(diverged) == 0 {
+		return
+	}
+
+	atomic.AddInt64(&sc.stats.AntiEntropyRepairs, int64(len(diverged)))
+	if sc.debugging(DebugSync) {
+		sc.logger.Debug("AntiEntropy: repairing diverged entries", "sender", event.Sender, "count", len(diverged))
+	}
+	if !sc.options.DisableRemoteStore {
+		sc.Prefetch(context.Background(), diverged...)
+	}
+}
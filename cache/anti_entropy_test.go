@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// valueStore is a store serving fixed values.
+type valueStore struct {
+	errorStore
+	values map[string][]byte
+}
+
+func (vs *valueStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if data, ok := vs.values[key]; ok {
+		return data, nil
+	}
+	return vs.errorStore.Get(ctx, key)
+}
+
+func TestSyncedCacheAntiEntropyRepairsDivergedEntries(t *testing.T) {
+	bus := &cacheBus{}
+	store := &valueStore{values: map[string][]byte{"user:1": []byte(`"fresh"`)}}
+	join := func(podID string) *SyncedCache {
+		c := newMockedCache(t, Options{PodID: podID, AntiEntropyInterval: time.Minute})
+		c.synchronizer = &busSynchronizer{bus: bus, podID: podID}
+		c.store = store
+		bus.caches = append(bus.caches, c)
+		return c
+	}
+	pod1 := join("pod-1")
+	pod2 := join("pod-2")
+	defer pod1.Close()
+	defer pod2.Close()
+
+	pod1.applyEvent(InvalidationEvent{Key: "user:1", Sender: "other", Action: ActionSet, Value: []byte(`"fresh"`)})
+	pod1.applyEvent(InvalidationEvent{Key: "user:2", Sender: "other", Action: ActionSet, Value: []byte(`"same"`)})
+	// pod-2 missed the event updating user:1
+	pod2.applyEvent(InvalidationEvent{Key: "user:1", Sender: "other", Action: ActionSet, Value: []byte(`"stale"`)})
+	pod2.applyEvent(InvalidationEvent{Key: "user:2", Sender: "other", Action: ActionSet, Value: []byte(`"same"`)})
+	var mu sync.Mutex
+	var changes []ChangeEvent
+	pod2.Subscribe(func(event ChangeEvent) {
+		mu.Lock()
+		changes = append(changes, event)
+		mu.Unlock()
+	})
+
+	if err := pod1.sendDigests(context.Background()); err != nil {
+		t.Fatalf("sendDigests failed: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if value, found := pod2.local.Get("user:1"); found && value == "fresh" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the diverged entry to be fetched again from the store")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := pod2.Stats().AntiEntropyRepairs; got != 1 {
+		t.Errorf("Expected 1 repair, got %d", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) == 0 || changes[0].Type != ChangeInvalidated || changes[0].Key != "user:1" {
+		t.Errorf("Expected the diverged entry to be reported invalidated, got %+v", changes)
+	}
+	if info := pod2.entryInfos.hitInfo("user:2"); info.Source != SourcePropagated {
+		t.Errorf("Expected the matching entry to be kept, got source %v", info.Source)
+	}
+	if got := pod1.Stats().AntiEntropyRepairs; got != 0 {
+		t.Errorf("Expected the sender not to repair its own entries, got %d", got)
+	}
+}
+
+func TestSyncedCacheAntiEntropyDisabledIgnoresDigests(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	defer c.Close()
+	c.applyEvent(InvalidationEvent{Key: "user:1", Sender: "other", Action: ActionSet, Value: []byte(`"value"`)})
+
+	c.applyEvent(InvalidationEvent{Key: "*", Sender: "other", Action: ActionDigest, Value: []byte(`{"etags":{"user:1":"\"0\""}}`)})
+	if _, found := c.local.Get("user:1"); !found {
+		t.Fatal("Expected digests to be ignored when AntiEntropyInterval is not set")
+	}
+}
+
+func TestSyncedCacheLocalETagEncodesValues(t *testing.T) {
+	ctx := context.Background()
+	types := NewTypeRegistry()
+	types.Register("feed:", &wrapperspb.StringValue{}, 1)
+	rules := map[string]KeyMarshaller{"feed:*": {ID: 7, Marshaller: NewProtoMarshaller(nil)}}
+	c := newMockedCache(t, Options{PodID: "pod-1", TypeRegistry: types, MarshallerRules: rules})
+	defer c.Close()
+	publisher := &publishingSynchronizer{}
+	c.synchronizer = publisher
+
+	if err := c.Set(ctx, "feed:1", wrapperspb.String("news")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	waitForLocal(c, "feed:1", time.Second)
+	etag, ok := c.localETag("feed:1")
+	if want := ETag(publisher.events[0].Value); !ok || etag != want {
+		t.Errorf("Expected the ETag of the propagated payload %s, got %s", want, etag)
+	}
+}
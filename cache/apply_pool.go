@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"container/list"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultApplyQueueSize is the number of events buffered per propagation worker
+// when Options.PropagationQueueSize is not set.
+const defaultApplyQueueSize = 1024
+
+// queuedEvent is an event waiting on a worker queue with its backlog handle.
+type queuedEvent struct {
+	event    InvalidationEvent
+	received *list.Element
+}
+
+// applyPool applies synchronization events on a fixed set of workers.
+// Events for the same key always go to the same worker so per-key ordering is
+// preserved, while deserialization of different keys runs in parallel.
+// Clear events act as a barrier: they are applied only after every event
+// received before them has been applied.
+// Worker queues are bounded: when the queue of a key's worker is full, dispatch
+// counts an overflow and waits for room, applying backpressure to the listener
+// rather than reordering or dropping events.
+type applyPool struct {
+	queues    []chan queuedEvent
+	apply     func(event InvalidationEvent)
+	backlog   *eventBacklog
+	overflows atomic.Int64
+	pending   sync.WaitGroup
+	wg        sync.WaitGroup
+}
+
+// newApplyPool starts workers that call apply for each dispatched event, each
+// buffering up to queueSize events (defaultApplyQueueSize when 0).
+// Events are recorded in backlog from dispatch until they have been applied.
+func newApplyPool(workers, queueSize int, backlog *eventBacklog, apply func(event InvalidationEvent)) *applyPool {
+	if queueSize <= 0 {
+		queueSize = defaultApplyQueueSize
+	}
+	p := &applyPool{
+		queues:  make([]chan queuedEvent, workers),
+		apply:   apply,
+		backlog: backlog,
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan queuedEvent, queueSize)
+		p.wg.Add(1)
+		go p.work(p.queues[i])
+	}
+	return p
+}
+
+// dispatch queues an event on the worker owning its key.
+// It must be called from a single goroutine (the synchronizer listener).
+func (p *applyPool) dispatch(event InvalidationEvent) {
+	received := p.backlog.add()
+
+	if event.Action == ActionClear {
+		p.pending.Wait()
+		p.apply(event)
+		p.backlog.done(received)
+		return
+	}
+
+	p.pending.Add(1)
+	queue := p.queues[p.worker(event.Key)]
+	queued := queuedEvent{event: event, received: received}
+	select {
+	case queue <- queued:
+	default:
+		p.overflows.Add(1)
+		queue <- queued
+	}
+}
+
+// worker returns the index of the worker owning key.
+func (p *applyPool) worker(key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+// work applies events from a queue until it is closed.
+func (p *applyPool) work(queue chan queuedEvent) {
+	defer p.wg.Done()
+	for queued := range queue {
+		p.apply(queued.event)
+		p.backlog.done(queued.received)
+		p.pending.Done()
+	}
+}
+
+// close stops the workers after the queued events have been applied.
+func (p *applyPool) close() {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+}
@@ -0,0 +1,165 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestApplyPoolPreservesPerKeyOrdering(t *testing.T) {
+	var mu sync.Mutex
+	applied := make(map[string][]string)
+
+	pool := newApplyPool(4, 0, newEventBacklog(), func(event InvalidationEvent) {
+		mu.Lock()
+		applied[event.Key] = append(applied[event.Key], string(event.Value))
+		mu.Unlock()
+	})
+
+	for i := range 100 {
+		for _, key := range []string{"a", "b", "c"} {
+			pool.dispatch(InvalidationEvent{Key: key, Action: ActionSet, Value: []byte(fmt.Sprint(i))})
+		}
+	}
+	pool.close()
+
+	for _, key := range []string{"a", "b", "c"} {
+		if len(applied[key]) != 100 {
+			t.Fatalf("Expected 100 events for %s, got %d", key, len(applied[key]))
+		}
+		for i, v := range applied[key] {
+			if v != fmt.Sprint(i) {
+				t.Fatalf("Events for %s applied out of order at %d: %s", key, i, v)
+			}
+		}
+	}
+}
+
+func TestApplyPoolClearIsBarrier(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+
+	pool := newApplyPool(2, 0, newEventBacklog(), func(event InvalidationEvent) {
+		if event.Action == ActionSet {
+			time.Sleep(5 * time.Millisecond)
+		}
+		mu.Lock()
+		order = append(order, string(event.Action)+":"+event.Key)
+		mu.Unlock()
+	})
+
+	pool.dispatch(InvalidationEvent{Key: "k1", Action: ActionSet})
+	pool.dispatch(InvalidationEvent{Key: "k2", Action: ActionSet})
+	pool.dispatch(InvalidationEvent{Key: "*", Action: ActionClear})
+	pool.dispatch(InvalidationEvent{Key: "k3", Action: ActionSet})
+	pool.close()
+
+	if len(order) != 4 {
+		t.Fatalf("Expected 4 applied events, got %v", order)
+	}
+	if order[2] != "clear:*" {
+		t.Fatalf("Clear should be applied after earlier events and before later ones, got %v", order)
+	}
+}
+
+func TestApplyPoolTracksBacklog(t *testing.T) {
+	backlog := newEventBacklog()
+	release := make(chan struct{})
+
+	pool := newApplyPool(1, 0, backlog, func(event InvalidationEvent) {
+		<-release
+	})
+
+	pool.dispatch(InvalidationEvent{Key: "k1", Action: ActionSet})
+	pool.dispatch(InvalidationEvent{Key: "k2", Action: ActionSet})
+
+	if pending, _ := backlog.snapshot(); pending != 2 {
+		t.Fatalf("Expected 2 pending events, got %d", pending)
+	}
+
+	close(release)
+	pool.close()
+
+	if pending, oldest := backlog.snapshot(); pending != 0 || oldest != 0 {
+		t.Fatalf("Expected drained backlog, got pending=%d oldest=%v", pending, oldest)
+	}
+}
+
+func TestApplyPoolCountsOverflows(t *testing.T) {
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+	var applied atomic.Int32
+
+	pool := newApplyPool(1, 1, newEventBacklog(), func(event InvalidationEvent) {
+		started <- struct{}{}
+		<-release
+		applied.Add(1)
+	})
+
+	pool.dispatch(InvalidationEvent{Key: "k1", Action: ActionSet})
+	<-started
+	pool.dispatch(InvalidationEvent{Key: "k2", Action: ActionSet})
+
+	dispatched := make(chan struct{})
+	go func() {
+		pool.dispatch(InvalidationEvent{Key: "k3", Action: ActionSet})
+		close(dispatched)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for pool.overflows.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected dispatch to a full queue to count an overflow")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	close(release)
+	<-dispatched
+	pool.close()
+
+	if applied.Load() != 3 {
+		t.Fatalf("Expected every event to be applied, got %d", applied.Load())
+	}
+	if got := pool.overflows.Load(); got != 1 {
+		t.Fatalf("Expected 1 overflow, got %d", got)
+	}
+}
+
+func TestSyncedCachePropagationWorkers(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PodID = "test-pod-propagation-workers"
+	opts.RedisAddr = "localhost:6379"
+	opts.LocalCacheFactory = NewLRUCacheFactory(1000)
+	opts.PropagationWorkers = 4
+
+	c, err := New(opts)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	for i := range 50 {
+		data, _ := json.Marshal(fmt.Sprintf("value-%d", i))
+		c.handleInvalidation(InvalidationEvent{
+			Key:    fmt.Sprintf("test:workers:%d", i),
+			Sender: "other-pod",
+			Action: ActionSet,
+			Value:  data,
+		})
+	}
+
+	for i := range 50 {
+		key := fmt.Sprintf("test:workers:%d", i)
+		if !waitForLocal(c, key, 2*time.Second) {
+			t.Fatalf("Expected %s to be applied by worker pool", key)
+		}
+		value, _ := c.local.Get(key)
+		if value != fmt.Sprintf("value-%d", i) {
+			t.Fatalf("Expected value-%d, got %v", i, value)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// eventBacklog tracks synchronization events that were received but not yet
+// applied to the local cache, so queue depth and lag can be reported in Stats.
+type eventBacklog struct {
+	mu      sync.Mutex
+	pending *list.List // receive times in arrival order
+}
+
+// newEventBacklog creates an empty backlog.
+func newEventBacklog() *eventBacklog {
+	return &eventBacklog{pending: list.New()}
+}
+
+// add records an event received now and returns a handle to pass to done.
+func (b *eventBacklog) add() *list.Element {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pending.PushBack(time.Now())
+}
+
+// done records that the event identified by e has been applied.
+func (b *eventBacklog) done(e *list.Element) {
+	b.mu.Lock()
+	b.pending.Remove(e)
+	b.mu.Unlock()
+}
+
+// snapshot returns the number of pending events and the age of the oldest one.
+func (b *eventBacklog) snapshot() (int64, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	front := b.pending.Front()
+	if front == nil {
+		return 0, 0
+	}
+	return int64(b.pending.Len()), time.Since(front.Value.(time.Time))
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventBacklogSnapshot(t *testing.T) {
+	backlog := newEventBacklog()
+
+	if pending, oldest := backlog.snapshot(); pending != 0 || oldest != 0 {
+		t.Fatalf("Expected empty backlog, got pending=%d oldest=%v", pending, oldest)
+	}
+
+	first := backlog.add()
+	time.Sleep(20 * time.Millisecond)
+	second := backlog.add()
+
+	pending, oldest := backlog.snapshot()
+	if pending != 2 {
+		t.Fatalf("Expected 2 pending events, got %d", pending)
+	}
+	if oldest < 20*time.Millisecond {
+		t.Fatalf("Expected oldest age of at least 20ms, got %v", oldest)
+	}
+
+	backlog.done(first)
+	if _, age := backlog.snapshot(); age >= oldest {
+		t.Fatalf("Expected oldest age to drop once the first event is applied, got %v", age)
+	}
+
+	backlog.done(second)
+	if pending, oldest := backlog.snapshot(); pending != 0 || oldest != 0 {
+		t.Fatalf("Expected drained backlog, got pending=%d oldest=%v", pending, oldest)
+	}
+}
@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// defaultBloomFilterKeys is the number of keys the Bloom filter is sized for
+// when Options.BloomFilterKeys is 0.
+const defaultBloomFilterKeys = 100000
+
+// bloomFalsePositiveRate is the rate of absent keys the Bloom filter reports as
+// present when it holds the number of keys it is sized for.
+const bloomFalsePositiveRate = 0.01
+
+// bloomScanBatchSize is the number of keys scanned per batch while building the
+// Bloom filter.
+const bloomScanBatchSize = 1000
+
+// bloomFilter is a Bloom filter of the keys existing in Redis. It reports keys
+// that were never added as absent, except for a small rate of false positives,
+// and never reports an added key as absent. It is safe for concurrent use.
+type bloomFilter struct {
+	bits   []uint64
+	hashes uint64
+}
+
+// newBloomFilter creates a Bloom filter sized for n keys at bloomFalsePositiveRate.
+func newBloomFilter(n int) *bloomFilter {
+	if n <= 0 {
+		n = defaultBloomFilterKeys
+	}
+	m := math.Ceil(-float64(n) * math.Log(bloomFalsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := math.Max(1, math.Round(m/float64(n)*math.Ln2))
+	return &bloomFilter{bits: make([]uint64, (uint64(m)+63)/64), hashes: uint64(k)}
+}
+
+// positions calls fn with the bit position of each hash of key, derived from
+// the two halves of its 64-bit FNV-1a hash.
+func (bf *bloomFilter) positions(key string, fn func(word int, mask uint64) bool) {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	sum := h.Sum64()
+	h1, h2 := sum&0xffffffff, sum>>32|1
+	size := uint64(len(bf.bits)) * 64
+	for i := uint64(0); i < bf.hashes; i++ {
+		bit := (h1 + i*h2) % size
+		if !fn(int(bit/64), 1<<(bit%64)) {
+			return
+		}
+	}
+}
+
+// add adds key to the filter.
+func (bf *bloomFilter) add(key string) {
+	bf.positions(key, func(word int, mask uint64) bool {
+		atomic.OrUint64(&bf.bits[word], mask)
+		return true
+	})
+}
+
+// mayContain reports false when key was definitely never added.
+func (bf *bloomFilter) mayContain(key string) bool {
+	found := true
+	bf.positions(key, func(word int, mask uint64) bool {
+		found = atomic.LoadUint64(&bf.bits[word])&mask != 0
+		return found
+	})
+	return found
+}
+
+// startBloomFilter builds the Bloom filter of the keys in Redis, then rebuilds it
+// every Options.BloomFilterInterval to forget deleted keys. It is a no-op when the
+// interval is not set.
+func (sc *SyncedCache) startBloomFilter() {
+	if sc.options.BloomFilterInterval <= 0 || sc.options.DisableRemoteStore {
+		return
+	}
+	store, ok := sc.store.(WarmupStore)
+	if !ok {
+		sc.logger.Error("BloomFilter: store cannot scan keys, disabling the Bloom filter")
+		return
+	}
+
+	sc.goBackground(func(ctx context.Context) {
+		ticker := time.NewTicker(sc.options.BloomFilterInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := sc.buildBloomFilter(ctx, store); err != nil && ctx.Err() == nil {
+				sc.reportError(OpBloomFilter, "", ErrRemoteStore, err)
+				if sc.logging(DebugOps) {
+					sc.logger.Error("BloomFilter: failed to scan keys", "error", err)
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// buildBloomFilter scans the keys in Redis into a new filter and swaps it in.
+// Keys written while scanning are added to both the current and the new filter,
+// as the scan may miss them.
+func (sc *SyncedCache) buildBloomFilter(ctx context.Context, store WarmupStore) error {
+	filter := newBloomFilter(sc.options.BloomFilterKeys)
+	sc.building.Store(filter)
+	defer sc.building.Store(nil)
+
+	err := store.ScanKeys(ctx, "*", bloomScanBatchSize, func(keys []string) error {
+		for _, key := range keys {
+			filter.add(key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sc.bloom.Store(filter)
+	atomic.AddInt64(&sc.stats.BloomFilterBuilds, 1)
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("BloomFilter: rebuilt from the keys in Redis")
+	}
+	return nil
+}
+
+// bloomAdd records that key exists in Redis.
+func (sc *SyncedCache) bloomAdd(key string) {
+	if filter := sc.bloom.Load(); filter != nil {
+		filter.add(key)
+	}
+	if filter := sc.building.Load(); filter != nil {
+		filter.add(key)
+	}
+}
+
+// bloomSkips reports whether the Bloom filter shows that key does not exist in
+// Redis, counting it in Stats.BloomFilterSkips. It is false until the filter was
+// first built.
+func (sc *SyncedCache) bloomSkips(key string) bool {
+	filter := sc.bloom.Load()
+	if filter == nil || filter.mayContain(key) {
+		return false
+	}
+	atomic.AddInt64(&sc.stats.BloomFilterSkips, 1)
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("Get: skipping remote cache for key absent from the Bloom filter", "key", key)
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// scanCountingStore is a warmupStore counting Get round trips.
+type scanCountingStore struct {
+	warmupStore
+	gets int64
+}
+
+func (cs *scanCountingStore) Get(ctx context.Context, key string) ([]byte, error) {
+	atomic.AddInt64(&cs.gets, 1)
+	if data, ok := cs.values[key]; ok {
+		return data, nil
+	}
+	return cs.warmupStore.Get(ctx, key)
+}
+
+func TestBloomFilter(t *testing.T) {
+	bf := newBloomFilter(1000)
+	for i := 0; i < 1000; i++ {
+		bf.add("key:" + strconv.Itoa(i))
+	}
+	for i := 0; i < 1000; i++ {
+		if !bf.mayContain("key:" + strconv.Itoa(i)) {
+			t.Fatalf("Expected added key:%d to be found", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if bf.mayContain("absent:" + strconv.Itoa(i)) {
+			falsePositives++
+		}
+	}
+	if falsePositives > 300 {
+		t.Errorf("Expected about 1%% false positives, got %d of 10000", falsePositives)
+	}
+}
+
+func TestSyncedCacheBloomFilterSkipsAbsentKeys(t *testing.T) {
+	c := newMockedCache(t, Options{WritePolicy: WritePolicyWriteThrough})
+	store := &scanCountingStore{warmupStore: warmupStore{values: map[string][]byte{"a": []byte(`"1"`)}}}
+	c.store = store
+	ctx := context.Background()
+
+	if err := c.buildBloomFilter(ctx, store); err != nil {
+		t.Fatalf("buildBloomFilter failed: %v", err)
+	}
+	if got := c.Stats().BloomFilterBuilds; got != 1 {
+		t.Errorf("Expected 1 build, got %d", got)
+	}
+
+	if _, found := c.Get(ctx, "missing"); found {
+		t.Error("Expected missing key not to be found")
+	}
+	if got := atomic.LoadInt64(&store.gets); got != 0 {
+		t.Errorf("Expected Redis not to be read for an absent key, got %d reads", got)
+	}
+	if got := c.Stats().BloomFilterSkips; got != 1 {
+		t.Errorf("Expected 1 skip, got %d", got)
+	}
+
+	if value, found := c.Get(ctx, "a"); !found || value != "1" {
+		t.Errorf("Expected scanned key to be read from Redis, got %v, %v", value, found)
+	}
+	if got := atomic.LoadInt64(&store.gets); got != 1 {
+		t.Errorf("Expected 1 Redis read, got %d", got)
+	}
+
+	if err := c.Set(ctx, "b", "2"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if !c.bloom.Load().mayContain("b") {
+		t.Error("Expected a written key to be added to the filter")
+	}
+}
+
+func TestSyncedCacheBloomFilterWithoutBuild(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	store := &scanCountingStore{}
+	c.store = store
+
+	c.Get(context.Background(), "missing")
+	if got := atomic.LoadInt64(&store.gets); got != 1 {
+		t.Errorf("Expected Redis to be read before the filter is built, got %d reads", got)
+	}
+	if got := c.Stats().BloomFilterSkips; got != 0 {
+		t.Errorf("Expected no skips, got %d", got)
+	}
+}
@@ -0,0 +1,312 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/huykn/distributed-cache/storage"
+)
+
+// defaultBreakerCooldown is how long the breaker stays open before letting a
+// probe through when Options.BreakerCooldown is not set.
+const defaultBreakerCooldown = 5 * time.Second
+
+// defaultDegradedQueueSize bounds the writes queued while the breaker is open when
+// Options.DegradedQueueSize is not set.
+const defaultDegradedQueueSize = 1024
+
+// CircuitState is the state of the circuit breaker around Redis, see Options.BreakerThreshold.
+type CircuitState string
+
+const (
+	// CircuitClosed lets every Redis call through.
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen skips Redis: Get serves the local cache only and writes follow
+	// Options.DegradedWritePolicy.
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe call through to decide whether to close again.
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// DegradedWritePolicy selects what Set, SetWithInvalidate and Delete do while the
+// circuit breaker is open.
+type DegradedWritePolicy string
+
+const (
+	// DegradedWriteFailFast fails writes with ErrCircuitOpen without touching any cache level.
+	DegradedWriteFailFast DegradedWritePolicy = "fail-fast"
+	// DegradedWriteQueue applies writes to the local cache and queues them, keeping the
+	// latest write per key, to be stored in Redis and published once the breaker closes.
+	// Writes fail with ErrDegradedQueueFull when DegradedQueueSize keys are queued.
+	DegradedWriteQueue DegradedWritePolicy = "queue"
+)
+
+// circuitBreaker opens after a number of consecutive Redis failures and lets a
+// probe through every cooldown. A nil breaker is disabled and always closed.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	state     CircuitState
+	changedAt time.Time
+	opens     int64
+	onClose   func()
+}
+
+// newCircuitBreaker returns a breaker opening after threshold consecutive failures,
+// or nil when threshold is 0. onClose is called when the breaker closes after being open.
+func newCircuitBreaker(threshold int, cooldown time.Duration, onClose func()) *circuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &circuitBreaker{threshold: threshold, cooldown: cooldown, state: CircuitClosed, onClose: onClose}
+}
+
+// allow reports whether a Redis call may be attempted. Once the cooldown has elapsed,
+// an open breaker half-opens and lets one probe through per cooldown.
+func (cb *circuitBreaker) allow() bool {
+	if cb == nil {
+		return true
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == CircuitClosed {
+		return true
+	}
+	if time.Since(cb.changedAt) < cb.cooldown {
+		return false
+	}
+	// Open past its cooldown, or a half-open probe that never reported back
+	cb.state = CircuitHalfOpen
+	cb.changedAt = time.Now()
+	return true
+}
+
+// record reports the outcome of a Redis call. Missing keys and cancellations by
+// the caller are not failures.
+func (cb *circuitBreaker) record(err error) {
+	if cb == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		cb.failure()
+		return
+	}
+
+	cb.mu.Lock()
+	reopened := cb.state != CircuitClosed
+	cb.failures = 0
+	cb.state = CircuitClosed
+	cb.mu.Unlock()
+	if reopened && cb.onClose != nil {
+		cb.onClose()
+	}
+}
+
+// failure counts a failed call, opening the breaker at the threshold or when a probe fails.
+func (cb *circuitBreaker) failure() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.failures++
+	if cb.state == CircuitHalfOpen || (cb.state == CircuitClosed && cb.failures >= cb.threshold) {
+		if cb.state == CircuitClosed {
+			cb.opens++
+		}
+		cb.state = CircuitOpen
+		cb.changedAt = time.Now()
+	}
+}
+
+// snapshot returns the current state and the number of times the breaker opened.
+func (cb *circuitBreaker) snapshot() (CircuitState, int64) {
+	if cb == nil {
+		return CircuitClosed, 0
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state, cb.opens
+}
+
+// degradedWrite is a write queued while the breaker is open, replayed with the
+// options of the call that made it.
+type degradedWrite struct {
+	key      string
+	data     []byte
+	cfg      setConfig
+	queuedAt time.Time
+	delete   bool
+}
+
+// degradedQueue holds the latest queued write per key, in the order keys were first queued.
+type degradedQueue struct {
+	mu     sync.Mutex
+	size   int
+	keys   []string
+	writes map[string]degradedWrite
+}
+
+// newDegradedQueue creates a queue holding writes for at most size keys.
+func newDegradedQueue(size int) *degradedQueue {
+	if size <= 0 {
+		size = defaultDegradedQueueSize
+	}
+	return &degradedQueue{size: size, writes: make(map[string]degradedWrite)}
+}
+
+// push queues w, replacing a queued write to the same key. It returns false when full.
+func (dq *degradedQueue) push(w degradedWrite) bool {
+	dq.mu.Lock()
+	defer dq.mu.Unlock()
+	if _, queued := dq.writes[w.key]; !queued {
+		if len(dq.keys) >= dq.size {
+			return false
+		}
+		dq.keys = append(dq.keys, w.key)
+	}
+	dq.writes[w.key] = w
+	return true
+}
+
+// take removes and returns the queued writes in order.
+func (dq *degradedQueue) take() []degradedWrite {
+	dq.mu.Lock()
+	defer dq.mu.Unlock()
+	writes := make([]degradedWrite, 0, len(dq.keys))
+	for _, key := range dq.keys {
+		writes = append(writes, dq.writes[key])
+	}
+	dq.keys = nil
+	dq.writes = make(map[string]degradedWrite)
+	return writes
+}
+
+// len returns the number of queued writes.
+func (dq *degradedQueue) len() int {
+	dq.mu.Lock()
+	defer dq.mu.Unlock()
+	return len(dq.keys)
+}
+
+// pushIfAbsent queues w unless a write to the same key is already queued.
+func (dq *degradedQueue) pushIfAbsent(w degradedWrite) {
+	dq.mu.Lock()
+	_, queued := dq.writes[w.key]
+	dq.mu.Unlock()
+	if !queued {
+		dq.push(w)
+	}
+}
+
+// degradedSet queues a Set made while the breaker is open under DegradedWriteQueue,
+// storing the value in the local cache as the Set would have.
+func (sc *SyncedCache) degradedSet(key string, value any, data []byte, cfg setConfig) error {
+	// Queued writes are not published before the breaker closes
+	cfg.published = nil
+	if !sc.degraded.push(degradedWrite{key: key, data: data, cfg: cfg, queuedAt: time.Now()}) {
+		return ErrDegradedQueueFull
+	}
+	sc.storeLocal(key, value, data, SourceSet, cfg)
+	sc.deps.set(key, cfg.dependsOn)
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("Set: circuit open, queued write", "key", key)
+	}
+	return nil
+}
+
+// degradedDelete handles a Delete while the breaker is open, per Options.DegradedWritePolicy.
+func (sc *SyncedCache) degradedDelete(key string) error {
+	if sc.degraded == nil {
+		return ErrCircuitOpen
+	}
+	if !sc.degraded.push(degradedWrite{key: key, queuedAt: time.Now(), delete: true}) {
+		return ErrDegradedQueueFull
+	}
+	sc.local.Delete(key)
+	sc.entryInfos.remove(key)
+	sc.forgetStale(key)
+	sc.changes.emit(ChangeEvent{Type: ChangeDeleted, Key: key})
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("Delete: circuit open, queued delete", "key", key)
+	}
+	return nil
+}
+
+// replayDegraded stores and publishes the writes queued while the breaker was open.
+// It is called in the background when the breaker closes.
+func (sc *SyncedCache) replayDegraded() {
+	if sc.degraded == nil {
+		return
+	}
+	sc.goBackground(func(ctx context.Context) {
+		writes := sc.degraded.take()
+		if sc.logging(DebugOps) && len(writes) > 0 {
+			sc.logger.Info("Circuit closed: replaying queued writes", "count", len(writes))
+		}
+		for i, w := range writes {
+			if err := sc.replayWrite(ctx, w); err != nil {
+				// Requeue what is left unless newer writes replaced it
+				for _, rest := range writes[i:] {
+					sc.degraded.pushIfAbsent(rest)
+				}
+				sc.reportError(OpReplay, w.key, ErrRemoteStore, err)
+				return
+			}
+		}
+	})
+}
+
+// replayWrite stores and publishes a queued write, as Set or Delete would have.
+// A value whose TTL ran out while queued is deleted instead.
+func (sc *SyncedCache) replayWrite(parent context.Context, w degradedWrite) error {
+	ctx, cancel := context.WithTimeout(parent, sc.options.ContextTimeout)
+	defer cancel()
+
+	if !w.delete && w.cfg.ttl > 0 {
+		w.cfg.ttl -= time.Since(w.queuedAt)
+		w.delete = w.cfg.ttl <= 0
+	}
+
+	var event InvalidationEvent
+	if w.delete {
+		if sc.externalFormatFor(w.key) == nil {
+			err := sc.store.Delete(ctx, w.key)
+			sc.breaker.record(err)
+			if err != nil {
+				return err
+			}
+		}
+		event = sc.stamp(InvalidationEvent{Key: w.key, Sender: sc.options.PodID, Action: ActionDelete})
+	} else {
+		published, err := sc.storeRemote(ctx, w.key, w.data, w.cfg)
+		if err != nil {
+			return err
+		}
+		if published || w.cfg.noPropagate {
+			sc.dependencyWritten(ctx, w.key, w.cfg.dependsOn)
+			return nil
+		}
+		event = sc.setEvent(w.key, w.data, w.cfg)
+	}
+
+	err := sc.publish(ctx, event)
+	sc.breaker.record(err)
+	if err != nil {
+		return err
+	}
+	sc.dependencyWritten(ctx, w.key, w.cfg.dependsOn)
+	return nil
+}
+
+// ErrCircuitOpen is returned by writes while the circuit breaker around Redis is
+// open and Options.DegradedWritePolicy is DegradedWriteFailFast.
+var ErrCircuitOpen = NewError("circuit breaker open: redis unavailable")
+
+// ErrDegradedQueueFull is returned by writes while the circuit breaker is open and
+// the queue of DegradedWriteQueue is full.
+var ErrDegradedQueueFull = NewError("degraded write queue is full")
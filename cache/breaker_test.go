@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/huykn/distributed-cache/storage"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	closed := 0
+	cb := newCircuitBreaker(2, 20*time.Millisecond, func() { closed++ })
+
+	cb.record(errors.New("down"))
+	if !cb.allow() {
+		t.Fatal("Expected the breaker to stay closed below the threshold")
+	}
+	cb.record(storage.ErrNotFound)
+	cb.record(context.Canceled)
+	cb.record(errors.New("down"))
+	if state, _ := cb.snapshot(); state != CircuitClosed {
+		t.Fatalf("Expected missing keys to reset failures and cancellations to be ignored, got %s", state)
+	}
+
+	cb.record(errors.New("down"))
+	if state, opens := cb.snapshot(); state != CircuitOpen || opens != 1 {
+		t.Fatalf("Expected the breaker to open once, got %s after %d opens", state, opens)
+	}
+	if cb.allow() {
+		t.Fatal("Expected calls to be skipped while open")
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if !cb.allow() {
+		t.Fatal("Expected a probe after the cooldown")
+	}
+	if cb.allow() {
+		t.Fatal("Expected a single probe while half-open")
+	}
+	cb.record(errors.New("down"))
+	if state, opens := cb.snapshot(); state != CircuitOpen || opens != 1 {
+		t.Fatalf("Expected a failed probe to reopen the breaker, got %s after %d opens", state, opens)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	cb.allow()
+	cb.record(nil)
+	if state, _ := cb.snapshot(); state != CircuitClosed || closed != 1 {
+		t.Fatalf("Expected a successful probe to close the breaker, got %s and %d close callbacks", state, closed)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	cb := newCircuitBreaker(0, 0, nil)
+	cb.record(errors.New("down"))
+	if !cb.allow() {
+		t.Fatal("Expected a disabled breaker to allow every call")
+	}
+	if state, _ := cb.snapshot(); state != CircuitClosed {
+		t.Fatalf("Expected a disabled breaker to report closed, got %s", state)
+	}
+}
+
+func TestSyncedCacheBreakerServesLocalOnly(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	store := newCountingStore(&errorStore{getError: errors.New("connection refused")}, 0)
+	c.store = store
+	c.breaker = newCircuitBreaker(2, time.Minute, nil)
+	c.setLocal("local", "value", 1, SourceSet)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, found := c.Get(ctx, "remote"); found {
+			t.Fatal("Expected a miss")
+		}
+	}
+	store.mu.Lock()
+	gets := store.getCounts["remote"]
+	store.mu.Unlock()
+	if gets != 2 {
+		t.Fatalf("Expected the store to be skipped once the breaker opened, got %d calls", gets)
+	}
+	if value, found := c.Get(ctx, "local"); !found || value != "value" {
+		t.Fatal("Expected local values to be served while the breaker is open")
+	}
+	if stats := c.Stats(); stats.CircuitState != CircuitOpen || stats.CircuitOpens != 1 {
+		t.Fatalf("Expected an open breaker in stats, got %s after %d opens", stats.CircuitState, stats.CircuitOpens)
+	}
+}
+
+// openBreaker returns a breaker that is open for a minute.
+func openBreaker(onClose func()) *circuitBreaker {
+	cb := newCircuitBreaker(1, time.Minute, onClose)
+	cb.record(errors.New("down"))
+	return cb
+}
+
+func TestSyncedCacheBreakerFailFast(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	c.breaker = openBreaker(nil)
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "key", "value"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if _, found := c.local.Get("key"); found {
+		t.Fatal("Expected a failed-fast Set not to touch the local cache")
+	}
+	if err := c.Delete(ctx, "key"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+}
+
+func TestSyncedCacheBreakerQueuesWrites(t *testing.T) {
+	c := newMockedCache(t, Options{DegradedQueueSize: 2})
+	synchronizer := &publishingSynchronizer{}
+	c.synchronizer = synchronizer
+	c.breaker = openBreaker(c.replayDegraded)
+	c.degraded = newDegradedQueue(c.options.DegradedQueueSize)
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "a", "1"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := c.Set(ctx, "a", "2"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := c.Delete(ctx, "b"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := c.Set(ctx, "c", "3"); !errors.Is(err, ErrDegradedQueueFull) {
+		t.Fatalf("Expected ErrDegradedQueueFull, got %v", err)
+	}
+	if value, found := c.local.Get("a"); !found || value != "2" {
+		t.Fatalf("Expected the queued value in the local cache, got %v", value)
+	}
+	if queued := c.Stats().QueuedWrites; queued != 2 {
+		t.Fatalf("Expected 2 queued writes, got %d", queued)
+	}
+	if len(synchronizer.events) != 0 {
+		t.Fatalf("Expected nothing published while open, got %d events", len(synchronizer.events))
+	}
+
+	c.breaker.record(nil)
+	c.Close()
+
+	synchronizer.mu.Lock()
+	defer synchronizer.mu.Unlock()
+	var replayed []InvalidationEvent
+	for _, event := range synchronizer.events {
+		if event.Action != ActionLifecycle {
+			replayed = append(replayed, event)
+		}
+	}
+	if len(replayed) != 2 || replayed[0].Key != "a" || string(replayed[0].Value) != `"2"` ||
+		replayed[1].Key != "b" || replayed[1].Action != ActionDelete {
+		t.Fatalf("Expected the latest write per key to be replayed in order, got %+v", replayed)
+	}
+}
+
+func TestSyncedCacheBreakerReplaysSetOptions(t *testing.T) {
+	c := newMockedCache(t, Options{WritePolicy: WritePolicyWriteThrough})
+	store := &expiringStore{ttls: make(map[string]time.Duration)}
+	c.store = store
+	synchronizer := &publishingSynchronizer{}
+	c.synchronizer = synchronizer
+	c.breaker = openBreaker(c.replayDegraded)
+	c.degraded = newDegradedQueue(0)
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "a", "1", WithTTL(time.Minute), WithTags("t")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := c.Set(ctx, "b", "2", WithNoPropagate()); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if expiry := c.entryInfos.expiry("a"); expiry.IsZero() {
+		t.Fatal("Expected the queued value to expire from the local cache with its TTL")
+	}
+
+	c.breaker.record(nil)
+	c.Close()
+
+	if ttl := store.ttls["a"]; ttl <= 0 || ttl > time.Minute {
+		t.Errorf("Expected the replayed write to keep its TTL, got %s", ttl)
+	}
+	synchronizer.mu.Lock()
+	defer synchronizer.mu.Unlock()
+	var replayed []InvalidationEvent
+	for _, event := range synchronizer.events {
+		if event.Action != ActionLifecycle {
+			replayed = append(replayed, event)
+		}
+	}
+	if len(replayed) != 1 || replayed[0].Key != "a" || replayed[0].TTL <= 0 || len(replayed[0].Tags) != 1 {
+		t.Fatalf("Expected only the propagated write to be published with its options, got %+v", replayed)
+	}
+}
@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ChangeType identifies a change to an entry of the local cache.
+type ChangeType string
+
+const (
+	// ChangeSet is emitted when a value is stored in the local cache, whether
+	// written on this pod, fetched from Redis, loaded or propagated by a peer.
+	ChangeSet ChangeType = "set"
+	// ChangeInvalidated is emitted when a value is dropped because it may be out
+	// of date: invalidated by a peer, or rolled back after a failed remote write.
+	ChangeInvalidated ChangeType = "invalidated"
+	// ChangeDeleted is emitted when a value is deleted on this pod or by a peer.
+	ChangeDeleted ChangeType = "deleted"
+	// ChangeEvicted is emitted when a value expires, or is evicted by a local cache
+	// implementing EvictingLocalCache.
+	ChangeEvicted ChangeType = "evicted"
+	// ChangeCleared is emitted when the whole local cache is cleared.
+	ChangeCleared ChangeType = "cleared"
+)
+
+// ChangeEvent describes a change to this pod's local cache, delivered to the
+// functions registered with Subscribe.
+type ChangeEvent struct {
+	Type ChangeType
+
+	// Key is the changed key. It is empty for ChangeCleared.
+	Key string
+
+	// Value is the value stored, for ChangeSet.
+	Value any
+
+	// Source is how the value entered the local cache, for ChangeSet.
+	Source HitSource
+
+	// Remote reports that the change was caused by an event from another pod.
+	Remote bool
+}
+
+// changeSubscribers holds the functions registered with Subscribe. The zero value is ready to use.
+type changeSubscribers struct {
+	mu     sync.RWMutex
+	nextID int
+	fns    map[int]func(event ChangeEvent)
+	count  atomic.Int32
+}
+
+// add registers fn and returns its id.
+func (cs *changeSubscribers) add(fn func(event ChangeEvent)) int {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if cs.fns == nil {
+		cs.fns = make(map[int]func(event ChangeEvent))
+	}
+	cs.nextID++
+	cs.fns[cs.nextID] = fn
+	cs.count.Add(1)
+	return cs.nextID
+}
+
+// remove unregisters the function with the given id.
+func (cs *changeSubscribers) remove(id int) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	if _, ok := cs.fns[id]; ok {
+		delete(cs.fns, id)
+		cs.count.Add(-1)
+	}
+}
+
+// emit calls every registered function with event.
+func (cs *changeSubscribers) emit(event ChangeEvent) {
+	if cs.count.Load() == 0 {
+		return
+	}
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	for _, fn := range cs.fns {
+		fn(event)
+	}
+}
+
+// Subscribe registers fn to be called for every change to this pod's local cache:
+// values set, invalidated, deleted, evicted or cleared, whether caused by this pod
+// or by an event from a peer. This lets applications maintain derived state, such
+// as in-memory indexes, in step with the cache.
+//
+// fn is called synchronously on the goroutine making the change, which for
+// evictions may belong to the local cache, so it must be fast and must not write
+// to the cache. Evictions are only reported by local caches implementing
+// EvictingLocalCache. Calling the returned function unregisters fn.
+func (sc *SyncedCache) Subscribe(fn func(event ChangeEvent)) (unsubscribe func()) {
+	id := sc.changes.add(fn)
+	var once sync.Once
+	return func() {
+		once.Do(func() { sc.changes.remove(id) })
+	}
+}
+
+// onLocalEvict reports an entry evicted by the local cache to subscribers.
+func (sc *SyncedCache) onLocalEvict(key string) {
+	if key == healthProbeKey {
+		return
+	}
+	sc.changes.emit(ChangeEvent{Type: ChangeEvicted, Key: key})
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSubscribe(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	local, err := NewLRUCache(2)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	local.OnEvict(c.onLocalEvict)
+	c.local = local
+	ctx := context.Background()
+
+	var events []ChangeEvent
+	unsubscribe := c.Subscribe(func(event ChangeEvent) { events = append(events, event) })
+
+	if err := c.Set(ctx, "user:1", "alice"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	c.applyEvent(InvalidationEvent{Key: "user:2", Action: ActionSet, Value: []byte(`"bob"`)})
+	c.applyEvent(InvalidationEvent{Key: "user:3", Action: ActionSet, Value: []byte(`"carol"`)})
+	c.applyEvent(InvalidationEvent{Key: "user:2", Action: ActionInvalidate})
+	if err := c.Delete(ctx, "user:3"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := c.Clear(ctx); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+
+	want := []ChangeEvent{
+		{Type: ChangeSet, Key: "user:1", Value: "alice", Source: SourceSet},
+		{Type: ChangeSet, Key: "user:2", Value: "bob", Source: SourcePropagated, Remote: true},
+		{Type: ChangeEvicted, Key: "user:1"},
+		{Type: ChangeSet, Key: "user:3", Value: "carol", Source: SourcePropagated, Remote: true},
+		{Type: ChangeInvalidated, Key: "user:2", Remote: true},
+		{Type: ChangeDeleted, Key: "user:3"},
+		{Type: ChangeCleared},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("Expected %d events, got %+v", len(want), events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("Event %d: expected %+v, got %+v", i, want[i], events[i])
+		}
+	}
+
+	unsubscribe()
+	if err := c.Set(ctx, "user:4", "dave"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if len(events) != len(want) {
+		t.Fatalf("Expected no events after unsubscribing, got %+v", events[len(want):])
+	}
+}
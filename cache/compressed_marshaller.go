@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// compressedMagic prefixes compressed payloads. 0xC1 is never produced by JSON
+// and is reserved as "never used" in MessagePack, so uncompressed payloads
+// written by the inner marshaller can never be mistaken for compressed ones.
+const compressedMagic byte = 0xC1
+
+// Codec defines a compression algorithm used by CompressedMarshaller.
+// Implement it to plug in algorithms such as snappy or zstd.
+type Codec interface {
+	// ID returns a unique identifier stored in the payload header.
+	ID() byte
+
+	// Compress compresses data.
+	Compress(data []byte) ([]byte, error)
+
+	// Decompress decompresses data produced by Compress.
+	Decompress(data []byte) ([]byte, error)
+}
+
+// CompressionStats reports the effect of a CompressedMarshaller.
+type CompressionStats struct {
+	// Compressed is the number of payloads that were compressed.
+	Compressed int64
+	// Skipped is the number of payloads left uncompressed because they were
+	// below the size threshold or did not shrink.
+	Skipped int64
+	// BytesIn is the total size of compressed payloads before compression.
+	BytesIn int64
+	// BytesOut is the total size of compressed payloads after compression.
+	BytesOut int64
+	// BytesSaved is BytesIn - BytesOut.
+	BytesSaved int64
+}
+
+// CompressedMarshaller wraps another Marshaller and compresses payloads above a
+// size threshold before they are written to Redis and pub/sub.
+// Decompression is transparent, and uncompressed payloads are still readable.
+type CompressedMarshaller struct {
+	inner      Marshaller
+	codec      Codec
+	minSize    int
+	compressed int64
+	skipped    int64
+	bytesIn    int64
+	bytesOut   int64
+}
+
+// NewCompressedMarshaller creates a marshaller that compresses the output of inner
+// with codec when it is at least minSize bytes.
+func NewCompressedMarshaller(inner Marshaller, codec Codec, minSize int) *CompressedMarshaller {
+	return &CompressedMarshaller{
+		inner:   inner,
+		codec:   codec,
+		minSize: minSize,
+	}
+}
+
+// Marshal serializes v with the inner marshaller and compresses the result if large enough.
+func (cm *CompressedMarshaller) Marshal(v any) ([]byte, error) {
+	data, err := cm.inner.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < cm.minSize {
+		atomic.AddInt64(&cm.skipped, 1)
+		return data, nil
+	}
+
+	compressed, err := cm.codec.Compress(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(compressed)+2 >= len(data) {
+		atomic.AddInt64(&cm.skipped, 1)
+		return data, nil
+	}
+
+	out := make([]byte, 0, len(compressed)+2)
+	out = append(out, compressedMagic, cm.codec.ID())
+	out = append(out, compressed...)
+
+	atomic.AddInt64(&cm.compressed, 1)
+	atomic.AddInt64(&cm.bytesIn, int64(len(data)))
+	atomic.AddInt64(&cm.bytesOut, int64(len(out)))
+	return out, nil
+}
+
+// Unmarshal decompresses data if needed and deserializes it with the inner marshaller.
+func (cm *CompressedMarshaller) Unmarshal(data []byte, v any) error {
+	if len(data) >= 2 && data[0] == compressedMagic && data[1] == cm.codec.ID() {
+		decompressed, err := cm.codec.Decompress(data[2:])
+		if err != nil {
+			return err
+		}
+		data = decompressed
+	}
+	return cm.inner.Unmarshal(data, v)
+}
+
+// Stats returns compression statistics.
+func (cm *CompressedMarshaller) Stats() CompressionStats {
+	bytesIn := atomic.LoadInt64(&cm.bytesIn)
+	bytesOut := atomic.LoadInt64(&cm.bytesOut)
+	return CompressionStats{
+		Compressed: atomic.LoadInt64(&cm.compressed),
+		Skipped:    atomic.LoadInt64(&cm.skipped),
+		BytesIn:    bytesIn,
+		BytesOut:   bytesOut,
+		BytesSaved: bytesIn - bytesOut,
+	}
+}
+
+// GzipCodec compresses payloads with gzip.
+type GzipCodec struct {
+	level   int
+	writers sync.Pool
+}
+
+// NewGzipCodec creates a gzip codec with the given compression level
+// (e.g. gzip.DefaultCompression or gzip.BestSpeed).
+func NewGzipCodec(level int) *GzipCodec {
+	return &GzipCodec{level: level}
+}
+
+// ID returns the gzip codec identifier.
+func (gc *GzipCodec) ID() byte {
+	return 1
+}
+
+// Compress compresses data with gzip.
+func (gc *GzipCodec) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, ok := gc.writers.Get().(*gzip.Writer)
+	if ok {
+		w.Reset(&buf)
+	} else {
+		var err error
+		if w, err = gzip.NewWriterLevel(&buf, gc.level); err != nil {
+			return nil, err
+		}
+	}
+	defer gc.writers.Put(w)
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress decompresses gzip data.
+func (gc *GzipCodec) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"compress/gzip"
+	"strings"
+	"testing"
+)
+
+func TestCompressedMarshallerRoundTrip(t *testing.T) {
+	cm := NewCompressedMarshaller(NewJSONMarshaller(), NewGzipCodec(gzip.DefaultCompression), 64)
+
+	value := strings.Repeat("compressible-", 100)
+	data, err := cm.Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if data[0] != compressedMagic {
+		t.Fatal("Expected large payload to be compressed")
+	}
+
+	var result any
+	if err := cm.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if result != value {
+		t.Fatal("Round trip value mismatch")
+	}
+
+	stats := cm.Stats()
+	if stats.Compressed != 1 {
+		t.Fatalf("Expected 1 compressed payload, got %d", stats.Compressed)
+	}
+	if stats.BytesSaved <= 0 || stats.BytesSaved != stats.BytesIn-stats.BytesOut {
+		t.Fatalf("Unexpected bytes saved: %+v", stats)
+	}
+}
+
+func TestCompressedMarshallerBelowThreshold(t *testing.T) {
+	cm := NewCompressedMarshaller(NewJSONMarshaller(), NewGzipCodec(gzip.BestSpeed), 1024)
+
+	data, err := cm.Marshal("small")
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != `"small"` {
+		t.Fatalf("Expected uncompressed JSON, got %q", data)
+	}
+
+	var result any
+	if err := cm.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if result != "small" {
+		t.Fatalf("Expected 'small', got %v", result)
+	}
+
+	if cm.Stats().Skipped != 1 {
+		t.Fatalf("Expected 1 skipped payload, got %d", cm.Stats().Skipped)
+	}
+}
+
+func TestCompressedMarshallerReadsUncompressedPayloads(t *testing.T) {
+	cm := NewCompressedMarshaller(NewJSONMarshaller(), NewGzipCodec(gzip.DefaultCompression), 0)
+
+	var result map[string]any
+	if err := cm.Unmarshal([]byte(`{"name":"legacy"}`), &result); err != nil {
+		t.Fatalf("Unmarshal of legacy payload failed: %v", err)
+	}
+	if result["name"] != "legacy" {
+		t.Fatalf("Expected 'legacy', got %v", result["name"])
+	}
+}
+
+func TestCompressedMarshallerInnerError(t *testing.T) {
+	cm := NewCompressedMarshaller(&errorMarshaller{}, NewGzipCodec(gzip.DefaultCompression), 0)
+
+	if _, err := cm.Marshal("value"); err == nil {
+		t.Fatal("Expected inner marshal error")
+	}
+}
+
+func TestCompressedMarshallerCorruptPayload(t *testing.T) {
+	cm := NewCompressedMarshaller(NewJSONMarshaller(), NewGzipCodec(gzip.DefaultCompression), 0)
+
+	var result any
+	if err := cm.Unmarshal([]byte{compressedMagic, 1, 'x', 'y'}, &result); err == nil {
+		t.Fatal("Expected error for corrupt compressed payload")
+	}
+}
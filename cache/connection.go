@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"github.com/redis/go-redis/v9"
+
+	cachesync "github.com/huykn/distributed-cache/sync"
+)
+
+// ConnectionManager shares one Redis client and one Pub/Sub connection between
+// several SyncedCaches in the same process, such as one cache per tenant. Each
+// cache is created with the manager in Options.ConnectionManager and its own
+// InvalidationChannel and Namespace; events received on the shared connection
+// are routed to the cache subscribed to their channel.
+//
+// The shared Pub/Sub connection is only used with SyncTransportPubSub. With
+// SyncTransportStreams, the caches share the client's connection pool.
+type ConnectionManager struct {
+	client redis.UniversalClient
+	mux    *cachesync.PubSubMux
+}
+
+// NewConnectionManager creates a connection manager on client. The caches using
+// it do not close client, and neither does Close.
+func NewConnectionManager(client redis.UniversalClient) *ConnectionManager {
+	return &ConnectionManager{client: client, mux: cachesync.NewPubSubMux(client)}
+}
+
+// Client returns the shared Redis client.
+func (cm *ConnectionManager) Client() redis.UniversalClient {
+	return cm.client
+}
+
+// Subscriptions returns the number of channels the shared Pub/Sub connection is
+// subscribed to.
+func (cm *ConnectionManager) Subscriptions() int {
+	return cm.mux.Channels()
+}
+
+// Close closes the shared Pub/Sub connection. Close the caches using the
+// manager first; caches still open stop receiving events.
+func (cm *ConnectionManager) Close() error {
+	return cm.mux.Close()
+}
@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// DebugCategory groups the records logged in DebugMode.
+type DebugCategory string
+
+// Debug log categories.
+const (
+	// DebugOps covers Get, Set, Delete, Clear and the other cache operations.
+	DebugOps DebugCategory = "ops"
+
+	// DebugSync covers publishing and receiving synchronization, lifecycle and snapshot events.
+	DebugSync DebugCategory = "sync"
+
+	// DebugSerialization covers marshalling and unmarshalling values.
+	DebugSerialization DebugCategory = "serialization"
+)
+
+// logging reports whether DebugMode records of the category are enabled.
+// Use it for Info, Warn and Error records, which are never sampled.
+func (sc *SyncedCache) logging(category DebugCategory) bool {
+	if !sc.options.DebugMode {
+		return false
+	}
+	return len(sc.options.DebugCategories) == 0 || slices.Contains(sc.options.DebugCategories, category)
+}
+
+// debugging reports whether a Debug record of the category should be logged,
+// sampling at Options.DebugSampleRate.
+func (sc *SyncedCache) debugging(category DebugCategory) bool {
+	if !sc.logging(category) {
+		return false
+	}
+	rate := sc.options.DebugSampleRate
+	return rate == 0 || rate >= 1 || rand.Float64() < rate
+}
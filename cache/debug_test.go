@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDebugCategories(t *testing.T) {
+	sc := newMockedCache(t, Options{DebugMode: true, DebugCategories: []DebugCategory{DebugSync}})
+	defer sc.Close()
+
+	if !sc.debugging(DebugSync) || !sc.logging(DebugSync) {
+		t.Error("Expected sync records to be logged")
+	}
+	if sc.debugging(DebugOps) || sc.logging(DebugSerialization) {
+		t.Error("Expected records outside DebugCategories to be dropped")
+	}
+
+	sc.options.DebugMode = false
+	if sc.logging(DebugSync) {
+		t.Error("Expected nothing to be logged without DebugMode")
+	}
+}
+
+func TestDebugSampleRate(t *testing.T) {
+	sc := newMockedCache(t, Options{DebugMode: true, DebugSampleRate: 0.1})
+	defer sc.Close()
+
+	const n = 10000
+	sampled := 0
+	for range n {
+		if sc.debugging(DebugOps) {
+			sampled++
+		}
+		if !sc.logging(DebugOps) {
+			t.Fatal("Expected Warn and Error records not to be sampled")
+		}
+	}
+	if sampled < n/20 || sampled > n/5 {
+		t.Errorf("Expected about 10%% of %d records, got %d", n, sampled)
+	}
+}
+
+func TestDebugSampleRateLogsOperations(t *testing.T) {
+	logger := &argsLogger{}
+	sc := newMockedCache(t, Options{DebugMode: true, DebugCategories: []DebugCategory{DebugOps}})
+	sc.logger = logger
+	defer sc.Close()
+
+	sc.Get(context.Background(), "missing")
+	if len(logger.calls) == 0 {
+		t.Fatal("Expected Get to log operation records")
+	}
+
+	logger.calls = nil
+	sc.options.DebugSampleRate = 1e-9
+	sc.Get(context.Background(), "missing")
+	if len(logger.calls) != 0 {
+		t.Errorf("Expected sampling to drop Get records, got %d", len(logger.calls))
+	}
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// dependencyGraph keeps the keys each value was declared to depend on with
+// WithDependsOn, and the reverse edges, to find the dependents of a changed key.
+// It only lives in the memory of this pod, see WithDependsOn. Dependents are kept
+// in a bounded LRU; forgotten dependents are no longer invalidated with their
+// dependencies. The zero value is ready to use.
+type dependencyGraph struct {
+	mu         sync.Mutex
+	size       int                            // maximum number of dependents, defaultEntryInfoSize when 0
+	parents    *lru.Cache[string, []string]   // dependent to the keys it depends on
+	dependents map[string]map[string]struct{} // key to the keys depending on it
+}
+
+// init creates the LRU on first use. It must be called with mu held.
+func (dg *dependencyGraph) init() {
+	if dg.parents != nil {
+		return
+	}
+	size := dg.size
+	if size <= 0 {
+		size = defaultEntryInfoSize
+	}
+	dg.dependents = make(map[string]map[string]struct{})
+	// Called synchronously by Add and Remove, with mu held
+	dg.parents, _ = lru.NewWithEvict(size, dg.unlink)
+}
+
+// unlink removes the edges from the keys key depends on to key.
+func (dg *dependencyGraph) unlink(key string, parents []string) {
+	for _, parent := range parents {
+		delete(dg.dependents[parent], key)
+		if len(dg.dependents[parent]) == 0 {
+			delete(dg.dependents, parent)
+		}
+	}
+}
+
+// set records that key depends on parents, replacing its previous
+// dependencies. Empty parents forget the dependencies of key; a key depending
+// on itself is ignored.
+func (dg *dependencyGraph) set(key string, parents []string) {
+	dg.mu.Lock()
+	defer dg.mu.Unlock()
+	if dg.parents == nil && len(parents) == 0 {
+		return
+	}
+	dg.init()
+	dg.parents.Remove(key)
+	if len(parents) == 0 {
+		return
+	}
+	dg.parents.Add(key, parents)
+	for _, parent := range parents {
+		if parent == key {
+			continue
+		}
+		if dg.dependents[parent] == nil {
+			dg.dependents[parent] = make(map[string]struct{})
+		}
+		dg.dependents[parent][key] = struct{}{}
+	}
+}
+
+// take returns the keys directly depending on key, sorted, and forgets their
+// dependencies, as they are about to be invalidated.
+func (dg *dependencyGraph) take(key string) []string {
+	dg.mu.Lock()
+	defer dg.mu.Unlock()
+	if len(dg.dependents[key]) == 0 {
+		return nil
+	}
+	dependents := make([]string, 0, len(dg.dependents[key]))
+	for dependent := range dg.dependents[key] {
+		dependents = append(dependents, dependent)
+	}
+	for _, dependent := range dependents {
+		dg.parents.Remove(dependent)
+	}
+	slices.Sort(dependents)
+	return dependents
+}
+
+// clear forgets every dependency.
+func (dg *dependencyGraph) clear() {
+	dg.mu.Lock()
+	defer dg.mu.Unlock()
+	dg.parents = nil
+	dg.dependents = nil
+}
+
+// dependencyWritten records the dependencies declared by a write of key made by
+// this pod and deletes the values depending on key.
+func (sc *SyncedCache) dependencyWritten(ctx context.Context, key string, dependsOn []string) {
+	sc.deps.set(key, dependsOn)
+	sc.invalidateDependents(ctx, key)
+}
+
+// invalidateDependents deletes the values depending on key, which this pod just
+// changed, from the local cache, Redis and the other pods. Their own dependents
+// are deleted in turn. Failures are reported via OnError by Delete.
+func (sc *SyncedCache) invalidateDependents(ctx context.Context, key string) {
+	for _, dependent := range sc.deps.take(key) {
+		atomic.AddInt64(&sc.stats.CascadedInvalidations, 1)
+		if sc.debugging(DebugOps) {
+			sc.logger.Debug("Deleting dependent of changed key", "key", dependent, "depends_on", key)
+		}
+		_ = sc.Delete(ctx, dependent)
+	}
+}
+
+// invalidateDependentsLocal removes the values depending on key, which another
+// pod changed, and their own dependents from the local cache. The pod that
+// changed key deletes them from Redis.
+func (sc *SyncedCache) invalidateDependentsLocal(key string) {
+	queue := sc.deps.take(key)
+	for len(queue) > 0 {
+		dependent := queue[0]
+		queue = append(queue[1:], sc.deps.take(dependent)...)
+
+		sc.local.Delete(dependent)
+		sc.entryInfos.remove(dependent)
+		sc.forgetStale(dependent)
+		atomic.AddInt64(&sc.stats.CascadedInvalidations, 1)
+		sc.changes.emit(ChangeEvent{Type: ChangeInvalidated, Key: dependent, Remote: true})
+		if sc.debugging(DebugSync) {
+			sc.logger.Debug("Sync: removed dependent of changed key from local cache", "key", dependent, "depends_on", key)
+		}
+	}
+}
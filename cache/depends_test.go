@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestDependencyGraph(t *testing.T) {
+	var dg dependencyGraph
+	dg.set("report", []string{"user:1", "user:2"})
+	dg.set("summary", []string{"user:1"})
+
+	if got := dg.take("user:1"); !slices.Equal(got, []string{"report", "summary"}) {
+		t.Fatalf("Expected the dependents of user:1, got %v", got)
+	}
+	if got := dg.take("user:2"); got != nil {
+		t.Errorf("Expected taken dependents to be forgotten, got %v", got)
+	}
+
+	dg.set("report", []string{"user:1"})
+	dg.set("report", []string{"user:3"})
+	if got := dg.take("user:1"); got != nil {
+		t.Errorf("Expected replaced dependencies to be forgotten, got %v", got)
+	}
+	dg.clear()
+	if got := dg.take("user:3"); got != nil {
+		t.Errorf("Expected cleared dependencies to be forgotten, got %v", got)
+	}
+}
+
+func TestDependencyGraphBounded(t *testing.T) {
+	dg := dependencyGraph{size: 1}
+	dg.set("a", []string{"parent"})
+	dg.set("b", []string{"parent"})
+
+	if got := dg.take("parent"); !slices.Equal(got, []string{"b"}) {
+		t.Errorf("Expected only the most recent dependent, got %v", got)
+	}
+}
+
+func TestSyncedCacheWithDependsOnDeletesDependents(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1", WritePolicy: WritePolicyWriteThrough})
+	publisher := &publishingSynchronizer{}
+	c.synchronizer = publisher
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "report", "total", WithDependsOn("user:1")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := c.Set(ctx, "digest", "weekly", WithDependsOn("report")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := c.Set(ctx, "user:1", "alice"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	for _, key := range []string{"report", "digest"} {
+		if _, found := c.local.Get(key); found {
+			t.Errorf("Expected dependent %s to be deleted", key)
+		}
+	}
+	if got := c.Stats().CascadedInvalidations; got != 2 {
+		t.Errorf("Expected 2 cascaded invalidations, got %d", got)
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	var deleted []string
+	for _, event := range publisher.events {
+		if event.Action == ActionDelete {
+			deleted = append(deleted, event.Key)
+		}
+		if event.Key == "report" && event.Action == ActionSet && !slices.Equal(event.DependsOn, []string{"user:1"}) {
+			t.Errorf("Expected the set event to carry its dependencies, got %v", event.DependsOn)
+		}
+	}
+	if !slices.Equal(deleted, []string{"digest", "report"}) {
+		t.Errorf("Expected delete events for the dependents, got %v", deleted)
+	}
+}
+
+func TestSyncedCacheDependentsOfRemoteChanges(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1"})
+	c.applyEvent(InvalidationEvent{Key: "report", Sender: "pod-2", Action: ActionSet, Value: []byte(`"total"`), DependsOn: []string{"user:1"}})
+	c.applyEvent(InvalidationEvent{Key: "digest", Sender: "pod-2", Action: ActionSet, Value: []byte(`"weekly"`), DependsOn: []string{"report", "digest"}})
+	c.setLocal("user:2", "bob", 1, SourceSet)
+
+	c.applyEvent(InvalidationEvent{Key: "user:1", Sender: "pod-2", Action: ActionDelete})
+
+	for _, key := range []string{"report", "digest"} {
+		if _, found := c.local.Get(key); found {
+			t.Errorf("Expected dependent %s to be removed", key)
+		}
+	}
+	if _, found := c.local.Get("user:2"); !found {
+		t.Error("Expected unrelated key to be kept")
+	}
+	if got := c.Stats().CascadedInvalidations; got != 2 {
+		t.Errorf("Expected 2 cascaded invalidations, got %d", got)
+	}
+}
@@ -0,0 +1,272 @@
+package cache
+
+import (
+	"fmt"
+)
+
+// Description is a sanitized, structured snapshot of the effective cache configuration.
+// Secrets such as the Redis password are never included; only whether they are set.
+type Description struct {
+	PodID               string            `json:"pod_id"`
+	RedisAddr           string            `json:"redis_addr"`
+	RedisDB             int               `json:"redis_db"`
+	RedisUsername       string            `json:"redis_username,omitempty"`
+	RedisPasswordSet    bool              `json:"redis_password_set"`
+	RedisTLS            bool              `json:"redis_tls"`
+	RedisPoolSize       int               `json:"redis_pool_size,omitempty"`
+	RedisMinIdleConns   int               `json:"redis_min_idle_conns,omitempty"`
+	RedisDialTimeout    string            `json:"redis_dial_timeout"`
+	RedisReadTimeout    string            `json:"redis_read_timeout"`
+	RedisWriteTimeout   string            `json:"redis_write_timeout"`
+	RedisClient         string            `json:"redis_client"`
+	RedisPartitions     []string          `json:"redis_partitions,omitempty"`
+	PartitionVNodes     int               `json:"partition_vnodes,omitempty"`
+	PartitionReplicas   int               `json:"partition_replicas,omitempty"`
+	ConnectionManager   bool              `json:"connection_manager,omitempty"`
+	SyncRedisAddr       string            `json:"sync_redis_addr,omitempty"`
+	SyncRedisClient     string            `json:"sync_redis_client"`
+	Namespace           string            `json:"namespace"`
+	DangerousFullFlush  bool              `json:"dangerous_full_flush"`
+	InvalidationChannel string            `json:"invalidation_channel"`
+	SyncTransport       SyncTransport     `json:"sync_transport"`
+	StreamMaxLen        int64             `json:"stream_max_len,omitempty"`
+	EventEncoding       EventEncoding     `json:"event_encoding,omitempty"`
+	ReceiveOwnEvents    bool              `json:"receive_own_events,omitempty"`
+	PrefixChannels      map[string]string `json:"prefix_channels,omitempty"`
+	PatternChannels     []string          `json:"pattern_channels,omitempty"`
+	AdditionalChannels  []string          `json:"additional_channels,omitempty"`
+	PropagationRules    map[string]string `json:"propagation_rules,omitempty"`
+	MarshallerRules     map[string]byte   `json:"marshaller_rules,omitempty"`
+	PayloadEnvelope     bool              `json:"payload_envelope,omitempty"`
+	ExternalFormats     map[string]string `json:"external_formats,omitempty"`
+	SerializationFormat string            `json:"serialization_format"`
+	LocalCacheFactory   string            `json:"local_cache_factory"`
+	LocalCacheConfig    LocalCacheConfig  `json:"local_cache_config"`
+	LocalCacheShards    int               `json:"local_cache_shards,omitempty"`
+	SpilloverDir        string            `json:"spillover_dir,omitempty"`
+	SpilloverThreshold  int64             `json:"spillover_threshold,omitempty"`
+	SpilloverMaxBytes   int64             `json:"spillover_max_bytes,omitempty"`
+	Marshaller          string            `json:"marshaller"`
+	Logger              string            `json:"logger"`
+	ContextTimeout      string            `json:"context_timeout"`
+	RemoteGetTimeout    string            `json:"remote_get_timeout"`
+	LocalTTL            string            `json:"local_ttl"`
+	MaxStaleness        string            `json:"stale_while_revalidate"`
+	DebugMode           bool              `json:"debug_mode"`
+	DebugSampleRate     float64           `json:"debug_sample_rate,omitempty"`
+	DebugCategories     []DebugCategory   `json:"debug_categories,omitempty"`
+	EnableMetrics       bool              `json:"enable_metrics"`
+	WritePolicy         WritePolicy       `json:"write_policy"`
+	DisableRemoteStore  bool              `json:"disable_remote_store,omitempty"`
+	ReadOnly            bool              `json:"read_only,omitempty"`
+	ReaderCanSetToRedis bool              `json:"reader_can_set_to_redis"`
+	ConsistencyMode     ConsistencyMode   `json:"consistency_mode"`
+	RollbackLocal       bool              `json:"rollback_local_on_error"`
+	OnErrorSet          bool              `json:"on_error_set"`
+	OnErrorExSet        bool              `json:"on_error_ex_set,omitempty"`
+	ShouldCacheLocalSet bool              `json:"should_cache_locally_set"`
+	EventFilterSet      bool              `json:"event_filter_set"`
+	OnSetLocalCacheSet  bool              `json:"on_set_local_cache_set"`
+	CostFuncSet         bool              `json:"cost_func_set"`
+	DecodeIntoSet       bool              `json:"decode_into_set,omitempty"`
+	TypeRegistrySet     bool              `json:"type_registry_set,omitempty"`
+	LocalValueMode      LocalValueMode    `json:"local_value_mode,omitempty"`
+	KeepRawBytes        bool              `json:"keep_raw_bytes,omitempty"`
+	ComputeETags        bool              `json:"compute_etags,omitempty"`
+	RejectedSetPolicy   RejectedSetPolicy `json:"rejected_set_policy"`
+	PrefetchHintSet     bool              `json:"prefetch_hint_set"`
+	HotKeys             int               `json:"hot_keys"`
+	HotKeysInterval     string            `json:"hot_keys_interval"`
+	WarmupKeys          int               `json:"warmup_keys"`
+	WarmupPattern       string            `json:"warmup_pattern,omitempty"`
+	PeerWarmup          bool              `json:"peer_warmup"`
+	PeerWarmupTimeout   string            `json:"peer_warmup_timeout"`
+	AntiEntropyInterval string            `json:"anti_entropy_interval"`
+	BloomFilterInterval string            `json:"bloom_filter_interval"`
+	BloomFilterKeys     int               `json:"bloom_filter_keys,omitempty"`
+	HeartbeatInterval   string            `json:"heartbeat_interval"`
+	LeaderElection      bool              `json:"leader_election"`
+	LeaderLeaseTTL      string            `json:"leader_lease_ttl"`
+	TTLJitterPercent    int               `json:"ttl_jitter_percent,omitempty"`
+	MaxPropagationSize  int               `json:"max_propagation_size,omitempty"`
+	RelayRemoteHits     bool              `json:"relay_remote_hits,omitempty"`
+	MaxClockSkew        string            `json:"max_clock_skew"`
+	MaxValueSize        int               `json:"max_value_size,omitempty"`
+	PropagationWorkers  int               `json:"propagation_workers"`
+	PropagationQueue    int               `json:"propagation_queue_size"`
+	SubscribeTimeout    string            `json:"subscribe_timeout"`
+	BreakerThreshold    int               `json:"breaker_threshold"`
+	BreakerCooldown     string            `json:"breaker_cooldown"`
+	DegradedWrites      string            `json:"degraded_write_policy"`
+	DegradedQueueSize   int               `json:"degraded_queue_size"`
+	PublishQueueSize    int               `json:"publish_queue_size"`
+	PublishRetries      int               `json:"publish_retries"`
+	PublishRetryBackoff string            `json:"publish_retry_backoff"`
+	OnPublishDroppedSet bool              `json:"on_publish_dropped_set"`
+	PublishRateLimit    float64           `json:"publish_rate_limit,omitempty"`
+	PublishBurst        int               `json:"publish_burst,omitempty"`
+	RateLimitPolicy     RateLimitPolicy   `json:"rate_limit_policy,omitempty"`
+	WriteRateLimit      float64           `json:"write_rate_limit,omitempty"`
+	WriteBurst          int               `json:"write_burst,omitempty"`
+	OnEventGapSet       bool              `json:"on_event_gap_set"`
+	InvalidateOnGap     bool              `json:"invalidate_on_gap"`
+	Writer              string            `json:"writer"`
+	WriteBehind         bool              `json:"write_behind"`
+	WriteQueueSize      int               `json:"write_behind_queue_size"`
+	WriteRetries        int               `json:"write_retries"`
+	OnWriteFailedSet    bool              `json:"on_write_failed_set"`
+	FaultInjector       string            `json:"fault_injector"`
+}
+
+// Describe returns a sanitized description of the effective configuration,
+// including the factories and marshaller chosen after defaults were applied.
+// It is intended for logging at startup and for exposure via admin endpoints.
+func (sc *SyncedCache) Describe() Description {
+	return describeOptions(sc.options)
+}
+
+// describeOptions builds a Description from options.
+func describeOptions(o Options) Description {
+	var prefixChannels map[string]string
+	if len(o.PrefixChannels) > 0 {
+		prefixChannels = make(map[string]string, len(o.PrefixChannels))
+		for prefix, channel := range o.PrefixChannels {
+			prefixChannels[prefix] = channel
+		}
+	}
+	var propagationRules map[string]string
+	if len(o.PropagationRules) > 0 {
+		propagationRules = make(map[string]string, len(o.PropagationRules))
+		for pattern, propagation := range o.PropagationRules {
+			propagationRules[pattern] = string(propagation)
+		}
+	}
+
+	var marshallerRules map[string]byte
+	if len(o.MarshallerRules) > 0 {
+		marshallerRules = make(map[string]byte, len(o.MarshallerRules))
+		for pattern, rule := range o.MarshallerRules {
+			marshallerRules[pattern] = rule.ID
+		}
+	}
+
+	return Description{
+		PodID:               o.PodID,
+		RedisAddr:           o.RedisAddr,
+		RedisDB:             o.RedisDB,
+		RedisUsername:       o.RedisUsername,
+		RedisPasswordSet:    o.RedisPassword != "",
+		RedisTLS:            o.RedisTLSConfig != nil,
+		RedisPoolSize:       o.RedisPoolSize,
+		RedisMinIdleConns:   o.RedisMinIdleConns,
+		RedisDialTimeout:    o.RedisDialTimeout.String(),
+		RedisReadTimeout:    o.RedisReadTimeout.String(),
+		RedisWriteTimeout:   o.RedisWriteTimeout.String(),
+		RedisClient:         typeName(o.RedisClient),
+		RedisPartitions:     o.RedisPartitions,
+		PartitionVNodes:     o.PartitionVNodes,
+		PartitionReplicas:   o.PartitionReplicas,
+		ConnectionManager:   o.ConnectionManager != nil,
+		SyncRedisAddr:       o.SyncRedisAddr,
+		SyncRedisClient:     typeName(o.SyncRedisClient),
+		Namespace:           o.Namespace,
+		DangerousFullFlush:  o.DangerousFullFlush,
+		InvalidationChannel: o.InvalidationChannel,
+		SyncTransport:       o.SyncTransport,
+		StreamMaxLen:        o.StreamMaxLen,
+		EventEncoding:       o.EventEncoding,
+		ReceiveOwnEvents:    o.ReceiveOwnEvents,
+		PrefixChannels:      prefixChannels,
+		PatternChannels:     append([]string(nil), o.PatternChannels...),
+		AdditionalChannels:  append([]string(nil), o.AdditionalChannels...),
+		PropagationRules:    propagationRules,
+		MarshallerRules:     marshallerRules,
+		PayloadEnvelope:     o.PayloadEnvelope,
+		ExternalFormats:     describeExternalFormats(o.ExternalFormats),
+		SerializationFormat: o.SerializationFormat,
+		LocalCacheFactory:   typeName(o.LocalCacheFactory),
+		LocalCacheConfig:    o.LocalCacheConfig,
+		LocalCacheShards:    o.LocalCacheShards,
+		SpilloverDir:        o.SpilloverDir,
+		SpilloverThreshold:  o.SpilloverThreshold,
+		SpilloverMaxBytes:   o.SpilloverMaxBytes,
+		Marshaller:          typeName(o.Marshaller),
+		Logger:              typeName(o.Logger),
+		ContextTimeout:      o.ContextTimeout.String(),
+		RemoteGetTimeout:    o.RemoteGetTimeout.String(),
+		LocalTTL:            o.LocalTTL.String(),
+		MaxStaleness:        o.StaleWhileRevalidate.String(),
+		DebugMode:           o.DebugMode,
+		DebugSampleRate:     o.DebugSampleRate,
+		DebugCategories:     o.DebugCategories,
+		EnableMetrics:       o.EnableMetrics,
+		WritePolicy:         o.WritePolicy,
+		DisableRemoteStore:  o.DisableRemoteStore,
+		ReadOnly:            o.ReadOnly,
+		ReaderCanSetToRedis: o.ReaderCanSetToRedis,
+		ConsistencyMode:     o.ConsistencyMode,
+		RollbackLocal:       o.RollbackLocalOnError,
+		OnErrorSet:          o.OnError != nil,
+		OnErrorExSet:        o.OnErrorEx != nil,
+		ShouldCacheLocalSet: o.ShouldCacheLocally != nil,
+		EventFilterSet:      o.EventFilter != nil,
+		OnSetLocalCacheSet:  o.OnSetLocalCache != nil,
+		CostFuncSet:         o.CostFunc != nil,
+		DecodeIntoSet:       o.DecodeInto != nil,
+		TypeRegistrySet:     o.TypeRegistry != nil,
+		LocalValueMode:      o.LocalValueMode,
+		KeepRawBytes:        o.KeepRawBytes,
+		ComputeETags:        o.ComputeETags,
+		RejectedSetPolicy:   o.RejectedSetPolicy,
+		PrefetchHintSet:     o.PrefetchHint != nil,
+		HotKeys:             o.HotKeys,
+		HotKeysInterval:     o.HotKeysInterval.String(),
+		WarmupKeys:          len(o.WarmupKeys),
+		WarmupPattern:       o.WarmupPattern,
+		PeerWarmup:          o.PeerWarmup,
+		PeerWarmupTimeout:   o.PeerWarmupTimeout.String(),
+		AntiEntropyInterval: o.AntiEntropyInterval.String(),
+		BloomFilterInterval: o.BloomFilterInterval.String(),
+		BloomFilterKeys:     o.BloomFilterKeys,
+		HeartbeatInterval:   o.HeartbeatInterval.String(),
+		LeaderElection:      o.LeaderElection,
+		LeaderLeaseTTL:      o.LeaderLeaseTTL.String(),
+		TTLJitterPercent:    o.TTLJitterPercent,
+		MaxPropagationSize:  o.MaxPropagationSize,
+		RelayRemoteHits:     o.RelayRemoteHits,
+		MaxClockSkew:        o.MaxClockSkew.String(),
+		MaxValueSize:        o.MaxValueSize,
+		PropagationWorkers:  o.PropagationWorkers,
+		PropagationQueue:    o.PropagationQueueSize,
+		SubscribeTimeout:    o.SubscribeTimeout.String(),
+		BreakerThreshold:    o.BreakerThreshold,
+		BreakerCooldown:     o.BreakerCooldown.String(),
+		DegradedWrites:      string(o.DegradedWritePolicy),
+		DegradedQueueSize:   o.DegradedQueueSize,
+		PublishQueueSize:    o.PublishQueueSize,
+		PublishRetries:      o.PublishRetries,
+		PublishRetryBackoff: o.PublishRetryBackoff.String(),
+		OnPublishDroppedSet: o.OnPublishDropped != nil,
+		PublishRateLimit:    o.PublishRateLimit,
+		PublishBurst:        o.PublishBurst,
+		RateLimitPolicy:     o.RateLimitPolicy,
+		WriteRateLimit:      o.WriteRateLimit,
+		WriteBurst:          o.WriteBurst,
+		OnEventGapSet:       o.OnEventGap != nil,
+		InvalidateOnGap:     o.InvalidateOnGap,
+		Writer:              typeName(o.Writer),
+		WriteBehind:         o.WriteBehind,
+		WriteQueueSize:      o.WriteBehindQueueSize,
+		WriteRetries:        o.WriteRetries,
+		OnWriteFailedSet:    o.OnWriteFailed != nil,
+		FaultInjector:       typeName(o.FaultInjector),
+	}
+}
+
+// typeName returns the dynamic type name of v, or "none" when v is nil.
+func typeName(v any) string {
+	if v == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%T", v)
+}
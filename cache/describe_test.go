@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDescribeOptionsSanitized(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PodID = "pod-describe"
+	opts.RedisPassword = "super-secret"
+	opts.RedisTLSConfig = &tls.Config{}
+	opts.PrefixChannels = map[string]string{"user:": "cache:invalidate:users"}
+	opts.OnError = func(error) {}
+
+	desc := describeOptions(opts)
+	if desc.PodID != "pod-describe" {
+		t.Fatalf("Expected PodID 'pod-describe', got %s", desc.PodID)
+	}
+	if !desc.RedisPasswordSet {
+		t.Fatal("RedisPasswordSet should be true")
+	}
+	if !desc.RedisTLS {
+		t.Fatal("RedisTLS should be true")
+	}
+	if !desc.OnErrorSet {
+		t.Fatal("OnErrorSet should be true")
+	}
+	if desc.PrefixChannels["user:"] != "cache:invalidate:users" {
+		t.Fatalf("Expected prefix channel to be described, got %v", desc.PrefixChannels)
+	}
+	if desc.LocalCacheFactory != "none" || desc.Marshaller != "none" {
+		t.Fatalf("Expected unset factory and marshaller to be 'none', got %s and %s", desc.LocalCacheFactory, desc.Marshaller)
+	}
+
+	data, err := json.Marshal(desc)
+	if err != nil {
+		t.Fatalf("Failed to marshal description: %v", err)
+	}
+	if strings.Contains(string(data), "super-secret") {
+		t.Fatal("Description must not contain the Redis password")
+	}
+}
+
+func TestSyncedCacheDescribe(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PodID = "test-pod-describe"
+	opts.RedisAddr = "localhost:6379"
+	opts.LocalCacheFactory = NewLRUCacheFactory(100)
+
+	c, err := New(opts)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	desc := c.Describe()
+	if desc.LocalCacheFactory != "*cache.LRUCacheFactory" {
+		t.Fatalf("Expected LRU factory, got %s", desc.LocalCacheFactory)
+	}
+	if desc.Marshaller != "*cache.JSONMarshaller" {
+		t.Fatalf("Expected default JSON marshaller, got %s", desc.Marshaller)
+	}
+	if desc.Logger != "*cache.NoOpLogger" {
+		t.Fatalf("Expected default no-op logger, got %s", desc.Logger)
+	}
+	if desc.ContextTimeout != "5s" {
+		t.Fatalf("Expected context timeout '5s', got %s", desc.ContextTimeout)
+	}
+}
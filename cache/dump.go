@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"sync/atomic"
+)
+
+// dumpBatchSize is the number of keys scanned and read per round trip by DumpRemote.
+const dumpBatchSize = 100
+
+// DumpEntry is a line written by DumpRemote and read by RestoreRemote: a key of
+// the remote store and its serialized value, base64 encoded.
+type DumpEntry struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// DumpRemote writes every key of the remote store namespace and its serialized
+// value to w, as JSON lines of DumpEntry, e.g. to seed a staging environment
+// with production-like cache state. Keys written meanwhile may be missed. TTLs
+// are not kept. It requires a store implementing WarmupStore.
+func (sc *SyncedCache) DumpRemote(ctx context.Context, w io.Writer) error {
+	if atomic.LoadInt32(&sc.closed) != 0 {
+		return ErrCacheClosed
+	}
+	store, ok := sc.store.(WarmupStore)
+	if !ok || sc.options.DisableRemoteStore {
+		return ErrDumpNotSupported
+	}
+
+	enc := json.NewEncoder(w)
+	entries := 0
+	var writeErr error
+	err := store.ScanKeys(ctx, "*", dumpBatchSize, func(keys []string) error {
+		values, err := store.GetMulti(ctx, keys)
+		if err != nil {
+			return err
+		}
+		// Keys deleted since they were scanned are missing from values
+		slices.Sort(keys)
+		for _, key := range keys {
+			if data, ok := values[key]; ok {
+				if writeErr = enc.Encode(DumpEntry{Key: key, Value: data}); writeErr != nil {
+					return writeErr
+				}
+				entries++
+			}
+		}
+		return nil
+	})
+	if writeErr != nil {
+		return writeErr
+	}
+	if err != nil {
+		sc.reportError(OpDump, "", ErrRemoteStore, err)
+		if sc.logging(DebugOps) {
+			sc.logger.Error("DumpRemote: failed to read remote cache", "error", err)
+		}
+		return categorize(ErrRemoteStore, err)
+	}
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("DumpRemote: dumped remote cache", "keys", entries)
+	}
+	return nil
+}
+
+// RestoreRemote writes the entries written by DumpRemote from r to the remote
+// store, overwriting existing keys, and invalidates them on every pod. Entries
+// restored before an error are kept.
+func (sc *SyncedCache) RestoreRemote(ctx context.Context, r io.Reader) error {
+	if sc.options.ReadOnly {
+		return ErrReadOnly
+	}
+	if !sc.beginWrite() {
+		return ErrCacheClosed
+	}
+	defer sc.writes.end()
+	if sc.options.DisableRemoteStore {
+		return ErrDumpNotSupported
+	}
+
+	dec := json.NewDecoder(r)
+	entries := 0
+	for {
+		var entry DumpEntry
+		if err := dec.Decode(&entry); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			sc.reportError(OpDump, "", ErrDeserialization, err)
+			return categorize(ErrDeserialization, err)
+		}
+		sc.bloomAdd(entry.Key)
+		if err := sc.store.Set(ctx, entry.Key, entry.Value); err != nil {
+			sc.breaker.record(err)
+			sc.reportError(OpDump, entry.Key, ErrRemoteStore, err)
+			if sc.logging(DebugOps) {
+				sc.logger.Error("RestoreRemote: failed to store in remote cache", "key", entry.Key, "error", err)
+			}
+			return categorize(ErrRemoteStore, err)
+		}
+		sc.local.Delete(entry.Key)
+		sc.entryInfos.remove(entry.Key)
+		sc.forgetStale(entry.Key)
+		sc.changes.emit(ChangeEvent{Type: ChangeInvalidated, Key: entry.Key})
+		sc.publishSet(ctx, entry.Key, entry.Value, setConfig{invalidateOnly: true})
+		entries++
+	}
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("RestoreRemote: restored remote cache", "keys", entries)
+	}
+	return nil
+}
+
+// ErrDumpNotSupported is returned by DumpRemote when the store does not implement
+// WarmupStore, and by DumpRemote and RestoreRemote when DisableRemoteStore is set.
+var ErrDumpNotSupported = NewError("store does not support dumping keys")
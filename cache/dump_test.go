@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// restoringStore is a warmupStore storing the values written to it.
+type restoringStore struct {
+	warmupStore
+}
+
+func (rs *restoringStore) Set(ctx context.Context, key string, value []byte) error {
+	rs.values[key] = value
+	return nil
+}
+
+func TestSyncedCacheDumpAndRestoreRemote(t *testing.T) {
+	ctx := context.Background()
+	source := newMockedCache(t, Options{})
+	source.store = &warmupStore{values: map[string][]byte{"b": []byte(`"2"`), "a": []byte(`{"n":1}`)}}
+
+	var buf bytes.Buffer
+	if err := source.DumpRemote(ctx, &buf); err != nil {
+		t.Fatalf("DumpRemote failed: %v", err)
+	}
+	if want := "{\"key\":\"a\",\"value\":\"eyJuIjoxfQ==\"}\n{\"key\":\"b\",\"value\":\"IjIi\"}\n"; buf.String() != want {
+		t.Fatalf("Expected JSON lines sorted by key, got %q", buf.String())
+	}
+
+	target := newMockedCache(t, Options{PodID: "pod-2"})
+	store := &restoringStore{warmupStore{values: map[string][]byte{"b": []byte(`"old"`)}}}
+	target.store = store
+	publisher := &publishingSynchronizer{}
+	target.synchronizer = publisher
+	target.setLocal("b", "old", 1, SourceRemote)
+
+	if err := target.RestoreRemote(ctx, &buf); err != nil {
+		t.Fatalf("RestoreRemote failed: %v", err)
+	}
+	if string(store.values["a"]) != `{"n":1}` || string(store.values["b"]) != `"2"` {
+		t.Errorf("Expected the dumped values to be restored, got %v", store.values)
+	}
+	if _, found := target.local.Get("b"); found {
+		t.Error("Expected the restored key to be dropped from the local cache")
+	}
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	if len(publisher.events) != 2 || publisher.events[0].Action != ActionInvalidate {
+		t.Errorf("Expected an invalidation per restored key, got %v", publisher.events)
+	}
+}
+
+func TestSyncedCacheRestoreRemoteMalformed(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	c.store = &restoringStore{warmupStore{values: map[string][]byte{}}}
+
+	err := c.RestoreRemote(context.Background(), strings.NewReader("{\"key\":\"a\",\"value\":\"IjEi\"}\nnot json\n"))
+	if !errors.Is(err, ErrDeserialization) {
+		t.Fatalf("Expected ErrDeserialization, got %v", err)
+	}
+}
+
+func TestSyncedCacheDumpRemoteNotSupported(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	if err := c.DumpRemote(context.Background(), &bytes.Buffer{}); !errors.Is(err, ErrDumpNotSupported) {
+		t.Fatalf("Expected ErrDumpNotSupported, got %v", err)
+	}
+}
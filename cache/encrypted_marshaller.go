@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+)
+
+// encryptedMagic prefixes payloads produced by EncryptedMarshaller.
+const encryptedMagic byte = 0xE5
+
+// ErrDecryptionFailed is returned when a payload cannot be decrypted.
+var ErrDecryptionFailed = NewError("decryption failed")
+
+// EncryptionConfig configures an EncryptedMarshaller.
+type EncryptionConfig struct {
+	// Keys maps key versions to AES keys (16, 24 or 32 bytes for AES-128/192/256).
+	// Keep retired versions here while data encrypted with them may still exist.
+	Keys map[byte][]byte
+
+	// ActiveKeyID is the key version used to encrypt new payloads.
+	ActiveKeyID byte
+
+	// OnDecryptFailure is called when a payload cannot be decrypted, e.g. because
+	// it was written with an unknown key version or has been tampered with.
+	OnDecryptFailure func(keyID byte, err error)
+}
+
+// EncryptedMarshaller wraps another Marshaller and encrypts payloads with AES-GCM
+// before they are written to Redis and pub/sub. Each payload records the key
+// version it was encrypted with, so keys can be rotated by adding a new version,
+// switching ActiveKeyID, and removing the old version once its data has expired.
+type EncryptedMarshaller struct {
+	inner            Marshaller
+	aeads            map[byte]cipher.AEAD
+	activeKeyID      byte
+	onDecryptFailure func(keyID byte, err error)
+}
+
+// NewEncryptedMarshaller creates a marshaller that encrypts the output of inner.
+func NewEncryptedMarshaller(inner Marshaller, config EncryptionConfig) (*EncryptedMarshaller, error) {
+	if _, ok := config.Keys[config.ActiveKeyID]; !ok {
+		return nil, fmt.Errorf("active key %d is not configured: %w", config.ActiveKeyID, ErrInvalidConfig)
+	}
+
+	aeads := make(map[byte]cipher.AEAD, len(config.Keys))
+	for id, key := range config.Keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %w", id, err)
+		}
+		aeads[id] = aead
+	}
+
+	return &EncryptedMarshaller{
+		inner:            inner,
+		aeads:            aeads,
+		activeKeyID:      config.ActiveKeyID,
+		onDecryptFailure: config.OnDecryptFailure,
+	}, nil
+}
+
+// Marshal serializes v with the inner marshaller and encrypts the result with the active key.
+// The payload layout is: magic | key ID | nonce | ciphertext.
+func (em *EncryptedMarshaller) Marshal(v any) ([]byte, error) {
+	data, err := em.inner.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	aead := em.aeads[em.activeKeyID]
+	out := make([]byte, 2+aead.NonceSize(), 2+aead.NonceSize()+len(data)+aead.Overhead())
+	out[0] = encryptedMagic
+	out[1] = em.activeKeyID
+	nonce := out[2:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(out, nonce, data, out[:2]), nil
+}
+
+// Unmarshal decrypts data with the key version recorded in it and deserializes
+// the plaintext with the inner marshaller.
+func (em *EncryptedMarshaller) Unmarshal(data []byte, v any) error {
+	plaintext, keyID, err := em.decrypt(data)
+	if err != nil {
+		if em.onDecryptFailure != nil {
+			em.onDecryptFailure(keyID, err)
+		}
+		return err
+	}
+	return em.inner.Unmarshal(plaintext, v)
+}
+
+// decrypt returns the plaintext of data and the key version it was encrypted with.
+func (em *EncryptedMarshaller) decrypt(data []byte) ([]byte, byte, error) {
+	if len(data) < 2 || data[0] != encryptedMagic {
+		return nil, 0, fmt.Errorf("payload is not encrypted: %w", ErrDecryptionFailed)
+	}
+
+	keyID := data[1]
+	aead, ok := em.aeads[keyID]
+	if !ok {
+		return nil, keyID, fmt.Errorf("unknown key %d: %w", keyID, ErrDecryptionFailed)
+	}
+	if len(data) < 2+aead.NonceSize() {
+		return nil, keyID, fmt.Errorf("payload too short: %w", ErrDecryptionFailed)
+	}
+
+	nonce := data[2 : 2+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, data[2+aead.NonceSize():], data[:2])
+	if err != nil {
+		return nil, keyID, fmt.Errorf("%w: %v", ErrDecryptionFailed, err)
+	}
+	return plaintext, keyID, nil
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+var (
+	testKeyV1 = bytes.Repeat([]byte{1}, 32)
+	testKeyV2 = bytes.Repeat([]byte{2}, 32)
+)
+
+func TestEncryptedMarshallerRoundTrip(t *testing.T) {
+	em, err := NewEncryptedMarshaller(NewJSONMarshaller(), EncryptionConfig{
+		Keys:        map[byte][]byte{1: testKeyV1},
+		ActiveKeyID: 1,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create marshaller: %v", err)
+	}
+
+	data, err := em.Marshal(map[string]any{"ssn": "123-45-6789"})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if bytes.Contains(data, []byte("123-45-6789")) {
+		t.Fatal("Payload must not contain plaintext")
+	}
+
+	var result map[string]any
+	if err := em.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if result["ssn"] != "123-45-6789" {
+		t.Fatalf("Expected decrypted value, got %v", result)
+	}
+}
+
+func TestEncryptedMarshallerKeyRotation(t *testing.T) {
+	oldMarshaller, _ := NewEncryptedMarshaller(NewJSONMarshaller(), EncryptionConfig{
+		Keys:        map[byte][]byte{1: testKeyV1},
+		ActiveKeyID: 1,
+	})
+	oldData, _ := oldMarshaller.Marshal("old")
+
+	rotated, err := NewEncryptedMarshaller(NewJSONMarshaller(), EncryptionConfig{
+		Keys:        map[byte][]byte{1: testKeyV1, 2: testKeyV2},
+		ActiveKeyID: 2,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create rotated marshaller: %v", err)
+	}
+
+	var result any
+	if err := rotated.Unmarshal(oldData, &result); err != nil || result != "old" {
+		t.Fatalf("Rotated marshaller should decrypt data from old key, got %v %v", result, err)
+	}
+
+	newData, _ := rotated.Marshal("new")
+	if newData[1] != 2 {
+		t.Fatalf("Expected new payloads to use key 2, got %d", newData[1])
+	}
+}
+
+func TestEncryptedMarshallerOnDecryptFailure(t *testing.T) {
+	writer, _ := NewEncryptedMarshaller(NewJSONMarshaller(), EncryptionConfig{
+		Keys:        map[byte][]byte{2: testKeyV2},
+		ActiveKeyID: 2,
+	})
+	data, _ := writer.Marshal("secret")
+
+	var failedKey byte
+	reader, _ := NewEncryptedMarshaller(NewJSONMarshaller(), EncryptionConfig{
+		Keys:        map[byte][]byte{1: testKeyV1},
+		ActiveKeyID: 1,
+		OnDecryptFailure: func(keyID byte, err error) {
+			failedKey = keyID
+		},
+	})
+
+	var result any
+	err := reader.Unmarshal(data, &result)
+	if !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf("Expected ErrDecryptionFailed, got %v", err)
+	}
+	if failedKey != 2 {
+		t.Fatalf("Expected OnDecryptFailure with key 2, got %d", failedKey)
+	}
+
+	// Tampered payload
+	tampered, _ := reader.Marshal("value")
+	tampered[len(tampered)-1] ^= 0xFF
+	if err := reader.Unmarshal(tampered, &result); !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf("Expected ErrDecryptionFailed for tampered payload, got %v", err)
+	}
+
+	// Plaintext payload
+	if err := reader.Unmarshal([]byte(`"plain"`), &result); !errors.Is(err, ErrDecryptionFailed) {
+		t.Fatalf("Expected ErrDecryptionFailed for plaintext payload, got %v", err)
+	}
+}
+
+func TestNewEncryptedMarshallerInvalidConfig(t *testing.T) {
+	if _, err := NewEncryptedMarshaller(NewJSONMarshaller(), EncryptionConfig{
+		Keys:        map[byte][]byte{1: testKeyV1},
+		ActiveKeyID: 2,
+	}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for missing active key, got %v", err)
+	}
+
+	if _, err := NewEncryptedMarshaller(NewJSONMarshaller(), EncryptionConfig{
+		Keys:        map[byte][]byte{1: []byte("short")},
+		ActiveKeyID: 1,
+	}); err == nil {
+		t.Fatal("Expected error for invalid key size")
+	}
+}
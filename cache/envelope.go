@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// envelopeMagic prefixes payloads written with Options.PayloadEnvelope. It is
+// never produced by JSON; MessagePack only produces it for the integer -9, which
+// never has bytes after it.
+const envelopeMagic byte = 0xF7
+
+// envelopeFormat is the version of the envelope layout, following envelopeMagic:
+//
+//	magic, format, flags, Marshaller ID (0 for Options.Marshaller),
+//	schema version (uvarint, when flagged), creation time (varint Unix
+//	milliseconds), origin pod (uvarint length and bytes), serialized value
+const envelopeFormat byte = 1
+
+// Envelope flags.
+const (
+	envelopeCompressed byte = 1 << iota // the value was compressed by a CompressedMarshaller
+	envelopeVersioned                   // a schema version follows the Marshaller ID
+)
+
+// envelope is the metadata a payload carries in its envelope.
+type envelope struct {
+	codec      byte
+	schema     uint64
+	versioned  bool
+	compressed bool
+	createdAt  time.Time
+	origin     string
+}
+
+// seal wraps data, a value of key serialized by the Marshaller of ID codec, in an
+// envelope written by this pod now.
+func (sc *SyncedCache) seal(key string, codec byte, data []byte) []byte {
+	var flags byte
+	if len(data) > 0 && data[0] == compressedMagic {
+		flags |= envelopeCompressed
+	}
+	s := sc.options.TypeRegistry.lookup(key)
+	if s != nil {
+		flags |= envelopeVersioned
+	}
+
+	origin := sc.options.PodID
+	out := make([]byte, 0, len(data)+len(origin)+4+3*binary.MaxVarintLen64)
+	out = append(out, envelopeMagic, envelopeFormat, flags, codec)
+	if s != nil {
+		out = binary.AppendUvarint(out, s.version)
+	}
+	out = binary.AppendVarint(out, time.Now().UnixMilli())
+	out = binary.AppendUvarint(out, uint64(len(origin)))
+	out = append(out, origin...)
+	return append(out, data...)
+}
+
+// parseEnvelope returns the envelope of data and the serialized value it holds.
+// ok is false when data has no envelope or it is malformed.
+func parseEnvelope(data []byte) (env envelope, body []byte, ok bool) {
+	if len(data) < 4 || data[0] != envelopeMagic || data[1] != envelopeFormat {
+		return envelope{}, data, false
+	}
+	flags := data[2]
+	env.codec = data[3]
+	env.compressed = flags&envelopeCompressed != 0
+	rest := data[4:]
+
+	if flags&envelopeVersioned != 0 {
+		version, n := binary.Uvarint(rest)
+		if n <= 0 {
+			return envelope{}, data, false
+		}
+		env.schema, env.versioned = version, true
+		rest = rest[n:]
+	}
+	millis, n := binary.Varint(rest)
+	if n <= 0 {
+		return envelope{}, data, false
+	}
+	env.createdAt = time.UnixMilli(millis)
+	rest = rest[n:]
+	length, n := binary.Uvarint(rest)
+	if n <= 0 || uint64(len(rest)-n) < length {
+		return envelope{}, data, false
+	}
+	env.origin = string(rest[n : n+int(length)])
+	return env, rest[n+int(length):], true
+}
+
+// open returns the Marshaller decoding data, a payload of key, its schema version
+// if it has one, and the serialized value it holds. Payloads without an envelope
+// are read from the headers written without Options.PayloadEnvelope.
+func (sc *SyncedCache) open(serializer Marshaller, key string, data []byte) (Marshaller, uint64, bool, []byte) {
+	if env, body, ok := parseEnvelope(data); ok {
+		if marshaller, known := sc.codecs.byID[env.codec]; known {
+			serializer = marshaller
+		}
+		return serializer, env.schema, env.versioned, body
+	}
+	version, body, versioned := sc.splitSchema(key, data)
+	serializer, body = sc.splitCodec(serializer, body)
+	return serializer, version, versioned, body
+}
+
+// writtenBy returns when and by which pod data was written, when it has an envelope.
+func writtenBy(data []byte) (time.Time, string) {
+	env, _, ok := parseEnvelope(data)
+	if !ok {
+		return time.Time{}, ""
+	}
+	return env.createdAt, env.origin
+}
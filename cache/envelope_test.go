@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestParseEnvelopeRejectsOtherPayloads(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte(`"value"`), {envelopeMagic}, {envelopeMagic, envelopeFormat, 0, 0}, {envelopeMagic, 2, 0, 0, 0, 0}} {
+		if _, body, ok := parseEnvelope(data); ok || string(body) != string(data) {
+			t.Errorf("Expected %q not to be read as an envelope", data)
+		}
+	}
+}
+
+func TestSyncedCachePayloadEnvelope(t *testing.T) {
+	ctx := context.Background()
+	types := NewTypeRegistry()
+	types.Register("feed:", &wrapperspb.StringValue{}, 3)
+	opts := Options{
+		PayloadEnvelope: true,
+		TypeRegistry:    types,
+		MarshallerRules: map[string]KeyMarshaller{"feed:*": {ID: 7, Marshaller: NewProtoMarshaller(nil)}},
+	}
+
+	opts.PodID = "pod-1"
+	pod1 := newMockedCache(t, opts)
+	defer pod1.Close()
+	sync1 := &publishingSynchronizer{}
+	pod1.synchronizer = sync1
+	before := time.Now().Add(-time.Second)
+	if err := pod1.Set(ctx, "feed:1", wrapperspb.String("news")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := pod1.Set(ctx, "config:1", "on"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	env, body, ok := parseEnvelope(sync1.events[0].Value)
+	if !ok || env.codec != 7 || !env.versioned || env.schema != 3 || env.origin != "pod-1" || env.createdAt.Before(before) {
+		t.Fatalf("Expected the envelope of the write, got %+v (ok=%v)", env, ok)
+	}
+	var msg wrapperspb.StringValue
+	if err := NewProtoMarshaller(nil).Unmarshal(body, &msg); err != nil || msg.GetValue() != "news" {
+		t.Fatalf("Expected the protobuf value in the envelope, got %q", body)
+	}
+
+	// Pods read envelopes whatever their own setting
+	opts.PodID = "pod-2"
+	opts.PayloadEnvelope = false
+	pod2 := newMockedCache(t, opts)
+	defer pod2.Close()
+	for _, event := range sync1.events {
+		pod2.applyEvent(event)
+	}
+	value, found, info := pod2.GetWithInfo(ctx, "feed:1")
+	if !found || value.(*wrapperspb.StringValue).GetValue() != "news" {
+		t.Fatalf("Expected the feed decoded from its envelope, got %#v", value)
+	}
+	if info.Origin != "pod-1" || info.CreatedAt.Before(before) {
+		t.Errorf("Expected the origin and creation time of the value, got %+v", info)
+	}
+	if value, _ := pod2.Get(ctx, "config:1"); value != "on" {
+		t.Errorf("Expected the config decoded with the default Marshaller, got %#v", value)
+	}
+	if raw, _ := pod2.GetRaw(ctx, "config:1"); string(raw) != `"on"` {
+		t.Errorf("Expected GetRaw to serve the value without its envelope, got %q", raw)
+	}
+}
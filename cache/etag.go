@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+)
+
+// ETag returns the strong HTTP ETag of a serialized value, quoted so it can be
+// used as the ETag header as-is. Equal bytes always have the same ETag.
+func ETag(data []byte) string {
+	h := fnv.New64a()
+	h.Write(data)
+	return fmt.Sprintf(`"%016x"`, h.Sum64())
+}
+
+// GetWithETag retrieves a value along with its ETag. Local hits use the ETag
+// computed when the value was stored (see Options.ComputeETags) or the bytes kept
+// with Options.KeepRawBytes; other values are encoded with the Marshaller and
+// hashed, so a value always comes with an ETag that is not newer than itself.
+func (sc *SyncedCache) GetWithETag(ctx context.Context, key string) (any, string, bool) {
+	// Read the ETag before the value: local writes store the value first, so the
+	// ETag is never newer than the value and a 304 never hides a change
+	etag, known := sc.entryInfos.etag(key)
+	value, found, info := sc.get(ctx, key)
+	if !found {
+		return nil, "", false
+	}
+	if known && info.Level == LevelLocal && !info.Stale {
+		return value, etag, true
+	}
+
+	data, err := sc.marshalLocal(key, value)
+	if err != nil {
+		sc.reportError(OpGet, key, ErrSerialization, err)
+		if sc.logging(DebugSerialization) {
+			sc.logger.Error("GetWithETag: serialization failed", "key", key, "error", err)
+		}
+		return nil, "", false
+	}
+	return value, ETag(data), true
+}
+
+// CheckETag reports whether etag matches the current value of key, so an HTTP
+// handler can answer 304 Not Modified to a request with If-None-Match. It is
+// false when the key is not found.
+func (sc *SyncedCache) CheckETag(ctx context.Context, key string, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	_, current, found := sc.GetWithETag(ctx, key)
+	return found && current == etag
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestETag(t *testing.T) {
+	a, b := ETag([]byte(`"alice"`)), ETag([]byte(`"bob"`))
+	if a == b || a != ETag([]byte(`"alice"`)) {
+		t.Fatalf("Expected ETags to identify content, got %s and %s", a, b)
+	}
+	if len(a) != 18 || a[0] != '"' || a[17] != '"' {
+		t.Fatalf("Expected a quoted ETag, got %s", a)
+	}
+}
+
+func TestGetWithETag(t *testing.T) {
+	for _, computed := range []bool{false, true} {
+		c := newMockedCache(t, Options{})
+		c.entryInfos.etags = computed
+		ctx := context.Background()
+
+		received := []byte(`{"name": "alice"}`)
+		c.applyEvent(InvalidationEvent{Key: "user:1", Action: ActionSet, Value: received})
+		value, etag, found := c.GetWithETag(ctx, "user:1")
+		if !found || value.(map[string]any)["name"] != "alice" {
+			t.Fatalf("Expected alice, got %v (found=%v)", value, found)
+		}
+
+		// Computed ETags hash the bytes received, others the value encoded again
+		want := ETag([]byte(`{"name":"alice"}`))
+		if computed {
+			want = ETag(received)
+		}
+		if etag != want {
+			t.Fatalf("computed=%v: expected ETag %s, got %s", computed, want, etag)
+		}
+		if !c.CheckETag(ctx, "user:1", etag) {
+			t.Fatalf("computed=%v: expected the ETag to match", computed)
+		}
+
+		c.applyEvent(InvalidationEvent{Key: "user:1", Action: ActionSet, Value: []byte(`{"name":"bob"}`)})
+		if c.CheckETag(ctx, "user:1", etag) {
+			t.Fatalf("computed=%v: expected the ETag not to match a new value", computed)
+		}
+		if c.CheckETag(ctx, "missing", etag) || c.CheckETag(ctx, "user:1", "") {
+			t.Fatalf("computed=%v: expected missing keys and empty ETags not to match", computed)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Encoding is the Redis encoding of values written by systems other than this library.
+type Encoding string
+
+const (
+	// EncodingString reads the value with GET and decodes it with the format's Marshaller.
+	EncodingString Encoding = "string"
+	// EncodingHash reads the value with HGETALL. The fields are encoded as a JSON
+	// object of strings and decoded with the format's Marshaller.
+	EncodingHash Encoding = "hash"
+)
+
+// ExternalFormat describes how values under a key prefix are stored by another writer,
+// so they can be read through the cache alongside legacy code. See Options.ExternalFormats.
+type ExternalFormat struct {
+	// Encoding is the Redis encoding of the values. When empty, EncodingString is used.
+	Encoding Encoding
+
+	// RedisPrefix replaces the key prefix to form the Redis key, e.g. mapping
+	// "user:" to "legacy_user_v1:". When empty, the key is used as is.
+	// Redis keys are read outside Options.Namespace.
+	RedisPrefix string
+
+	// Marshaller decodes the values, e.g. a msgpack Marshaller for msgpack-encoded strings.
+	// When nil, JSON is used.
+	Marshaller Marshaller
+}
+
+// prefixFormat is an ExternalFormat configured for a key prefix.
+type prefixFormat struct {
+	prefix string
+	format ExternalFormat
+}
+
+// newPrefixFormats returns the configured external formats with defaults applied,
+// ordered longest prefix first.
+func newPrefixFormats(formats map[string]ExternalFormat) []prefixFormat {
+	prefixFormats := make([]prefixFormat, 0, len(formats))
+	for prefix, format := range formats {
+		if format.Encoding == "" {
+			format.Encoding = EncodingString
+		}
+		if format.Marshaller == nil {
+			format.Marshaller = NewJSONMarshaller()
+		}
+		prefixFormats = append(prefixFormats, prefixFormat{prefix: prefix, format: format})
+	}
+	sort.Slice(prefixFormats, func(i, j int) bool {
+		return len(prefixFormats[i].prefix) > len(prefixFormats[j].prefix)
+	})
+	return prefixFormats
+}
+
+// redisKey returns the Redis key of key as written by the external writer.
+func (pf *prefixFormat) redisKey(key string) string {
+	if pf.format.RedisPrefix == "" {
+		return key
+	}
+	return pf.format.RedisPrefix + strings.TrimPrefix(key, pf.prefix)
+}
+
+// externalFormatFor returns the external format for the longest prefix matching key,
+// or nil if the key is stored by this library.
+func (sc *SyncedCache) externalFormatFor(key string) *prefixFormat {
+	for i, pf := range sc.extFormats {
+		if strings.HasPrefix(key, pf.prefix) {
+			return &sc.extFormats[i]
+		}
+	}
+	return nil
+}
+
+// getExternal reads the value of key written by another system, returning the
+// bytes to decode with the format's Marshaller.
+func (sc *SyncedCache) getExternal(ctx context.Context, pf *prefixFormat, key string) ([]byte, error) {
+	store, ok := sc.store.(ExternalStore)
+	if !ok {
+		return nil, ErrExternalNotSupported
+	}
+
+	redisKey := pf.redisKey(key)
+	if pf.format.Encoding != EncodingHash {
+		return store.GetExternal(ctx, redisKey)
+	}
+
+	fields, err := store.GetExternalHash(ctx, redisKey)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
+
+// describeExternalFormats returns a printable description of each external format.
+func describeExternalFormats(formats map[string]ExternalFormat) map[string]string {
+	if len(formats) == 0 {
+		return nil
+	}
+	described := make(map[string]string, len(formats))
+	for _, pf := range newPrefixFormats(formats) {
+		described[pf.prefix] = fmt.Sprintf("encoding=%s redis_prefix=%q marshaller=%s",
+			pf.format.Encoding, pf.format.RedisPrefix, typeName(pf.format.Marshaller))
+	}
+	return described
+}
+
+// ErrExternalNotSupported is returned when a key matches Options.ExternalFormats but
+// the store does not implement ExternalStore.
+var ErrExternalNotSupported = NewError("store does not support reading external values")
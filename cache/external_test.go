@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// externalStore is a store holding values written by another system.
+type externalStore struct {
+	errorStore
+	strings map[string][]byte
+	hashes  map[string]map[string]string
+	writes  int
+}
+
+func (es *externalStore) GetExternal(ctx context.Context, key string) ([]byte, error) {
+	if data, ok := es.strings[key]; ok {
+		return data, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (es *externalStore) GetExternalHash(ctx context.Context, key string) (map[string]string, error) {
+	if fields, ok := es.hashes[key]; ok {
+		return fields, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (es *externalStore) Set(ctx context.Context, key string, value []byte) error {
+	es.writes++
+	return nil
+}
+
+func (es *externalStore) Delete(ctx context.Context, key string) error {
+	es.writes++
+	return nil
+}
+
+// upperMarshaller decodes values as upper-cased strings, standing in for a non-JSON format.
+type upperMarshaller struct{}
+
+func (upperMarshaller) Marshal(v any) ([]byte, error) {
+	return []byte(v.(string)), nil
+}
+
+func (upperMarshaller) Unmarshal(data []byte, v any) error {
+	*(v.(*any)) = "UPPER:" + string(data)
+	return nil
+}
+
+func TestSyncedCacheExternalString(t *testing.T) {
+	c := newMockedCache(t, Options{ExternalFormats: map[string]ExternalFormat{
+		"user:":       {RedisPrefix: "legacy_user_v1:"},
+		"user:admin:": {RedisPrefix: "admins:", Marshaller: upperMarshaller{}},
+	}})
+	c.store = &externalStore{strings: map[string][]byte{
+		"legacy_user_v1:1": []byte(`{"name":"alice"}`),
+		"admins:root":      []byte("root"),
+	}}
+	defer c.Close()
+
+	ctx := context.Background()
+	value, found, info := c.GetWithInfo(ctx, "user:1")
+	if !found || info.Level != LevelRemote {
+		t.Fatalf("Expected remote hit, got %v (found=%v, %+v)", value, found, info)
+	}
+	if user, ok := value.(map[string]any); !ok || user["name"] != "alice" {
+		t.Fatalf("Expected decoded JSON user, got %#v", value)
+	}
+
+	// The longest prefix wins
+	if value, _ := c.Get(ctx, "user:admin:root"); value != "UPPER:root" {
+		t.Fatalf("Expected value decoded with the admin marshaller, got %#v", value)
+	}
+
+	if _, found := c.Get(ctx, "user:2"); found {
+		t.Fatal("Expected miss for a key the external writer never wrote")
+	}
+}
+
+func TestSyncedCacheExternalHash(t *testing.T) {
+	c := newMockedCache(t, Options{ExternalFormats: map[string]ExternalFormat{
+		"session:": {Encoding: EncodingHash},
+	}})
+	c.store = &externalStore{hashes: map[string]map[string]string{
+		"session:abc": {"user": "42", "role": "admin"},
+	}}
+	defer c.Close()
+
+	value, found := c.Get(context.Background(), "session:abc")
+	session, ok := value.(map[string]any)
+	if !found || !ok || session["user"] != "42" || session["role"] != "admin" {
+		t.Fatalf("Expected hash fields, got %#v (found=%v)", value, found)
+	}
+}
+
+func TestSyncedCacheExternalKeysAreNotWritten(t *testing.T) {
+	c := newMockedCache(t, Options{
+		ReaderCanSetToRedis: true,
+		ExternalFormats:     map[string]ExternalFormat{"user:": {}},
+	})
+	store := &externalStore{}
+	c.store = store
+	defer c.Close()
+
+	ctx := context.Background()
+	if err := c.Set(ctx, "user:1", "alice"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if value, found := c.Get(ctx, "user:1"); !found || value != "alice" {
+		t.Fatalf("Expected local value, got %v (found=%v)", value, found)
+	}
+	if err := c.Delete(ctx, "user:1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if store.writes != 0 {
+		t.Fatalf("Expected no Redis writes for external keys, got %d", store.writes)
+	}
+
+	if err := c.Set(ctx, "order:1", "x"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if store.writes != 1 {
+		t.Fatalf("Expected other keys to be written to Redis, got %d writes", store.writes)
+	}
+}
+
+func TestSyncedCacheExternalNotSupported(t *testing.T) {
+	var reported error
+	c := newMockedCache(t, Options{
+		ExternalFormats: map[string]ExternalFormat{"user:": {}},
+		OnError:         func(err error) { reported = err },
+	})
+	defer c.Close()
+
+	if _, found := c.Get(context.Background(), "user:1"); found {
+		t.Fatal("Expected miss when the store cannot read external values")
+	}
+	if reported != nil {
+		t.Fatalf("Misses should not be reported, got %v", reported)
+	}
+}
+
+func TestOptionsValidateExternalFormats(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ExternalFormats = map[string]ExternalFormat{"user:": {Encoding: "xml"}}
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for unknown encoding, got %v", err)
+	}
+
+	opts.ExternalFormats = map[string]ExternalFormat{"": {}}
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for empty prefix, got %v", err)
+	}
+
+	opts.ExternalFormats = map[string]ExternalFormat{"user:": {Encoding: EncodingHash}}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected valid options, got %v", err)
+	}
+}
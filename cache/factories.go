@@ -25,6 +25,7 @@ func NewNoOpLogger() Logger {
 	return &NoOpLogger{}
 }
 
+// ConsoleLogger is a logger that prints to stdout, rendering args as key=value pairs.
 type ConsoleLogger struct {
 	prefix string
 }
@@ -33,7 +34,7 @@ type ConsoleLogger struct {
 func (cl *ConsoleLogger) Debug(msg string, args ...any) {
 	fmt.Printf("[DEBUG] %s: %s", cl.prefix, msg)
 	if len(args) > 0 {
-		fmt.Printf(" %v", args)
+		fmt.Printf(" %s", formatArgs(args))
 	}
 	fmt.Println()
 }
@@ -42,7 +43,7 @@ func (cl *ConsoleLogger) Debug(msg string, args ...any) {
 func (cl *ConsoleLogger) Info(msg string, args ...any) {
 	fmt.Printf("[INFO] %s: %s", cl.prefix, msg)
 	if len(args) > 0 {
-		fmt.Printf(" %v", args)
+		fmt.Printf(" %s", formatArgs(args))
 	}
 	fmt.Println()
 }
@@ -51,7 +52,7 @@ func (cl *ConsoleLogger) Info(msg string, args ...any) {
 func (cl *ConsoleLogger) Warn(msg string, args ...any) {
 	fmt.Printf("[WARN] %s: %s", cl.prefix, msg)
 	if len(args) > 0 {
-		fmt.Printf(" %v", args)
+		fmt.Printf(" %s", formatArgs(args))
 	}
 	fmt.Println()
 }
@@ -60,7 +61,7 @@ func (cl *ConsoleLogger) Warn(msg string, args ...any) {
 func (cl *ConsoleLogger) Error(msg string, args ...any) {
 	fmt.Printf("[ERROR] %s: %s", cl.prefix, msg)
 	if len(args) > 0 {
-		fmt.Printf(" %v", args)
+		fmt.Printf(" %s", formatArgs(args))
 	}
 	fmt.Println()
 }
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/huykn/distributed-cache/storage"
+)
+
+// EventFault describes how the delivery of a received synchronization event is altered.
+type EventFault struct {
+	// Drop discards the event, as if it was lost in transit.
+	Drop bool
+
+	// Duplicate delivers the event twice.
+	Duplicate bool
+
+	// Delay postpones the delivery, letting later events overtake it.
+	Delay time.Duration
+}
+
+// RandomFaults is a FaultInjector failing a random fraction of store operations
+// and dropping, duplicating or delaying a random fraction of events.
+type RandomFaults struct {
+	// StoreErrorRate is the fraction of store operations failing with ErrInjectedFault.
+	StoreErrorRate float64
+
+	// DropRate and DuplicateRate are the fractions of events dropped and duplicated.
+	DropRate      float64
+	DuplicateRate float64
+
+	// MaxDelay delays each event by a random duration below it. When 0, events are not delayed.
+	MaxDelay time.Duration
+}
+
+var _ FaultInjector = RandomFaults{}
+
+// StoreFault fails the operation with ErrInjectedFault at StoreErrorRate.
+func (rf RandomFaults) StoreFault(ctx context.Context, op string) error {
+	if rf.StoreErrorRate > 0 && rand.Float64() < rf.StoreErrorRate {
+		return ErrInjectedFault
+	}
+	return nil
+}
+
+// EventFault drops, duplicates and delays the event at the configured rates.
+func (rf RandomFaults) EventFault(event InvalidationEvent) EventFault {
+	var fault EventFault
+	fault.Drop = rf.DropRate > 0 && rand.Float64() < rf.DropRate
+	fault.Duplicate = rf.DuplicateRate > 0 && rand.Float64() < rf.DuplicateRate
+	if rf.MaxDelay > 0 {
+		fault.Delay = rand.N(rf.MaxDelay)
+	}
+	return fault
+}
+
+// injectEventFaults returns callback with the faults chosen by injector applied
+// to each event.
+func injectEventFaults(injector FaultInjector, callback func(event InvalidationEvent)) func(event InvalidationEvent) {
+	return func(event InvalidationEvent) {
+		fault := injector.EventFault(event)
+		if fault.Drop {
+			return
+		}
+		deliveries := 1
+		if fault.Duplicate {
+			deliveries = 2
+		}
+		for range deliveries {
+			if fault.Delay > 0 {
+				time.AfterFunc(fault.Delay, func() { callback(event) })
+			} else {
+				callback(event)
+			}
+		}
+	}
+}
+
+// faultHook is a Redis client hook failing the commands chosen by a FaultInjector.
+type faultHook struct {
+	injector FaultInjector
+}
+
+func (h faultHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h faultHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.injector.StoreFault(ctx, storage.CommandName(cmd)); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h faultHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if err := h.injector.StoreFault(ctx, storage.CommandName(cmd)); err != nil {
+				for _, cmd := range cmds {
+					cmd.SetErr(err)
+				}
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+// faultyStore is a Store whose operations fail when a FaultInjector says so.
+type faultyStore struct {
+	Store
+	injector FaultInjector
+}
+
+// NewFaultyStore returns a Store failing the operations of store chosen by injector,
+// for unit tests exercising error handling without a hand-rolled failing Store.
+// Only the Store methods are exposed, not the optional store interfaces.
+func NewFaultyStore(store Store, injector FaultInjector) Store {
+	return &faultyStore{Store: store, injector: injector}
+}
+
+func (fs *faultyStore) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := fs.injector.StoreFault(ctx, "get"); err != nil {
+		return nil, err
+	}
+	return fs.Store.Get(ctx, key)
+}
+
+func (fs *faultyStore) Set(ctx context.Context, key string, value []byte) error {
+	if err := fs.injector.StoreFault(ctx, "set"); err != nil {
+		return err
+	}
+	return fs.Store.Set(ctx, key, value)
+}
+
+func (fs *faultyStore) Delete(ctx context.Context, key string) error {
+	if err := fs.injector.StoreFault(ctx, "delete"); err != nil {
+		return err
+	}
+	return fs.Store.Delete(ctx, key)
+}
+
+func (fs *faultyStore) Clear(ctx context.Context) error {
+	if err := fs.injector.StoreFault(ctx, "clear"); err != nil {
+		return err
+	}
+	return fs.Store.Clear(ctx)
+}
+
+// faultySynchronizer is a Synchronizer delivering events with the faults chosen by a FaultInjector.
+type faultySynchronizer struct {
+	Synchronizer
+	injector FaultInjector
+}
+
+// NewFaultySynchronizer returns a Synchronizer delivering the events received by
+// synchronizer with the faults chosen by injector. Only the Synchronizer methods
+// are exposed, not the optional synchronizer interfaces.
+func NewFaultySynchronizer(synchronizer Synchronizer, injector FaultInjector) Synchronizer {
+	return &faultySynchronizer{Synchronizer: synchronizer, injector: injector}
+}
+
+func (fs *faultySynchronizer) OnInvalidate(callback func(event InvalidationEvent)) {
+	fs.Synchronizer.OnInvalidate(injectEventFaults(fs.injector, callback))
+}
+
+// ErrInjectedFault is returned by store operations failed by RandomFaults.
+var ErrInjectedFault = NewError("injected fault")
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedFaults fails the listed store operations and applies the same fault to every event.
+type scriptedFaults struct {
+	ops   map[string]bool
+	event EventFault
+}
+
+func (sf scriptedFaults) StoreFault(ctx context.Context, op string) error {
+	if sf.ops[op] {
+		return ErrInjectedFault
+	}
+	return nil
+}
+
+func (sf scriptedFaults) EventFault(event InvalidationEvent) EventFault {
+	return sf.event
+}
+
+func TestFaultyStoreOpensBreaker(t *testing.T) {
+	c := newMockedCache(t, Options{WritePolicy: WritePolicyWriteThrough})
+	c.store = NewFaultyStore(&errorStore{}, scriptedFaults{ops: map[string]bool{"set": true}})
+	c.breaker = newCircuitBreaker(2, time.Minute, nil)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := c.Set(ctx, "key", "value"); !errors.Is(err, ErrInjectedFault) {
+			t.Fatalf("Expected ErrInjectedFault, got %v", err)
+		}
+	}
+	if err := c.Set(ctx, "key", "value"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Expected ErrCircuitOpen once the breaker opened, got %v", err)
+	}
+	if err := c.store.Delete(ctx, "key"); err != nil {
+		t.Fatalf("Expected operations without a fault to reach the store, got %v", err)
+	}
+}
+
+func TestInjectEventFaults(t *testing.T) {
+	event := InvalidationEvent{Key: "key", Action: ActionSet}
+	tests := []struct {
+		name  string
+		fault EventFault
+		want  int
+	}{
+		{"none", EventFault{}, 1},
+		{"drop", EventFault{Drop: true}, 0},
+		{"duplicate", EventFault{Duplicate: true}, 2},
+		{"delay", EventFault{Delay: 10 * time.Millisecond}, 1},
+		{"delayed duplicate", EventFault{Duplicate: true, Delay: 10 * time.Millisecond}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var delivered int
+			callback := injectEventFaults(scriptedFaults{event: tt.fault}, func(InvalidationEvent) {
+				mu.Lock()
+				delivered++
+				mu.Unlock()
+			})
+
+			callback(event)
+			if tt.fault.Delay > 0 {
+				mu.Lock()
+				if delivered != 0 {
+					t.Errorf("Expected a delayed delivery, got %d immediate deliveries", delivered)
+				}
+				mu.Unlock()
+				time.Sleep(5 * tt.fault.Delay)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if delivered != tt.want {
+				t.Errorf("Expected %d deliveries, got %d", tt.want, delivered)
+			}
+		})
+	}
+}
+
+func TestRandomFaultsRates(t *testing.T) {
+	always := RandomFaults{StoreErrorRate: 1, DropRate: 1, DuplicateRate: 1, MaxDelay: time.Millisecond}
+	if err := always.StoreFault(context.Background(), "get"); !errors.Is(err, ErrInjectedFault) {
+		t.Errorf("Expected ErrInjectedFault, got %v", err)
+	}
+	fault := always.EventFault(InvalidationEvent{})
+	if !fault.Drop || !fault.Duplicate || fault.Delay >= time.Millisecond {
+		t.Errorf("Unexpected fault %+v", fault)
+	}
+
+	var never RandomFaults
+	if err := never.StoreFault(context.Background(), "get"); err != nil {
+		t.Errorf("Expected no fault, got %v", err)
+	}
+	if fault := never.EventFault(InvalidationEvent{}); fault != (EventFault{}) {
+		t.Errorf("Expected no event fault, got %+v", fault)
+	}
+}
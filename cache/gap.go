@@ -0,0 +1,28 @@
+package cache
+
+import "sync/atomic"
+
+// handleGap reports synchronization events lost between two numbered events from
+// the same sender. The lost keys are unknown, so with Options.InvalidateOnGap the
+// whole local cache is cleared and values are fetched again from the remote store.
+func (sc *SyncedCache) handleGap(gap EventGap) {
+	atomic.AddInt64(&sc.stats.EventGaps, 1)
+	atomic.AddInt64(&sc.stats.MissedEvents, int64(gap.Missed()))
+
+	if sc.logging(DebugSync) {
+		sc.logger.Warn("Sync: detected lost events", "sender", gap.Sender, "channel", gap.Channel,
+			"from", gap.From, "to", gap.To)
+	}
+
+	if sc.options.OnEventGap != nil {
+		sc.options.OnEventGap(gap)
+	}
+
+	if sc.options.InvalidateOnGap {
+		sc.local.Clear()
+		sc.entryInfos.clear()
+		sc.clearStale()
+		sc.changes.emit(ChangeEvent{Type: ChangeCleared})
+		atomic.AddInt64(&sc.stats.Invalidations, 1)
+	}
+}
@@ -0,0 +1,38 @@
+package cache
+
+import "testing"
+
+func TestSyncedCacheHandleGap(t *testing.T) {
+	var reported []EventGap
+	c := newMockedCache(t, Options{OnEventGap: func(gap EventGap) {
+		reported = append(reported, gap)
+	}})
+	c.setLocal("key", "value", 1, SourceSet)
+
+	c.handleGap(EventGap{Sender: "pod-2", Channel: "cache:invalidate", From: 3, To: 5})
+
+	if len(reported) != 1 || reported[0].Missed() != 3 {
+		t.Fatalf("Expected one reported gap of 3 events, got %+v", reported)
+	}
+	stats := c.Stats()
+	if stats.EventGaps != 1 || stats.MissedEvents != 3 {
+		t.Fatalf("Expected 1 gap and 3 missed events, got %d and %d", stats.EventGaps, stats.MissedEvents)
+	}
+	if _, ok := c.local.Get("key"); !ok {
+		t.Fatal("Expected local cache to be kept without InvalidateOnGap")
+	}
+}
+
+func TestSyncedCacheHandleGapInvalidates(t *testing.T) {
+	c := newMockedCache(t, Options{InvalidateOnGap: true})
+	c.setLocal("key", "value", 1, SourceSet)
+
+	c.handleGap(EventGap{Sender: "pod-2", From: 1, To: 1})
+
+	if _, ok := c.local.Get("key"); ok {
+		t.Fatal("Expected local cache to be cleared on gap")
+	}
+	if c.Stats().Invalidations != 1 {
+		t.Fatalf("Expected 1 invalidation, got %d", c.Stats().Invalidations)
+	}
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// DeleteGroup removes every value written with WithGroup(group) from Redis and
+// from the local cache of every pod, e.g. all the entries of an offboarded
+// tenant, along with the values depending on them. It requires a store
+// implementing GroupStore. Delete events are published per key, subject to
+// Options.PublishRateLimit.
+func (sc *SyncedCache) DeleteGroup(ctx context.Context, group string) error {
+	if sc.options.ReadOnly {
+		return ErrReadOnly
+	}
+	if !sc.beginWrite() {
+		return ErrCacheClosed
+	}
+	defer sc.writes.end()
+	store, ok := sc.store.(GroupStore)
+	if !ok || sc.options.DisableRemoteStore {
+		return ErrGroupsNotSupported
+	}
+
+	keys, err := store.DeleteGroup(ctx, group)
+	if err != nil {
+		sc.breaker.record(err)
+		sc.reportError(OpDelete, group, ErrRemoteStore, err)
+		if sc.logging(DebugOps) {
+			sc.logger.Error("DeleteGroup: failed to remove group from remote cache", "group", group, "error", err)
+		}
+		return categorize(ErrRemoteStore, err)
+	}
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("DeleteGroup: removed group from remote cache", "group", group, "keys", len(keys))
+	}
+
+	for _, key := range keys {
+		sc.local.Delete(key)
+		sc.entryInfos.remove(key)
+		sc.forgetStale(key)
+		sc.changes.emit(ChangeEvent{Type: ChangeDeleted, Key: key})
+		sc.dependencyWritten(ctx, key, nil)
+		sc.publishDelete(ctx, key)
+	}
+	return nil
+}
+
+// addToGroup records key, just written to the remote store with ttl, under
+// group, when group is set.
+func (sc *SyncedCache) addToGroup(ctx context.Context, key, group string, ttl time.Duration) error {
+	if group == "" {
+		return nil
+	}
+	store, ok := sc.store.(GroupStore)
+	if !ok {
+		return ErrGroupsNotSupported
+	}
+	if err := store.AddToGroup(ctx, group, ttl, key); err != nil {
+		sc.breaker.record(err)
+		sc.reportError(OpSet, key, ErrRemoteStore, err)
+		if sc.logging(DebugOps) {
+			sc.logger.Error("Set: failed to add key to group", "key", key, "group", group, "error", err)
+		}
+		return categorize(ErrRemoteStore, err)
+	}
+	return nil
+}
+
+// ErrGroupsNotSupported is returned by DeleteGroup, and by Set with WithGroup,
+// when the store does not implement GroupStore.
+var ErrGroupsNotSupported = NewError("store does not support grouping keys")
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// groupStore is a store recording the keys written under each group.
+type groupStore struct {
+	errorStore
+	groups map[string][]string
+}
+
+func (gs *groupStore) AddToGroup(ctx context.Context, group string, ttl time.Duration, keys ...string) error {
+	gs.groups[group] = append(gs.groups[group], keys...)
+	return nil
+}
+
+func (gs *groupStore) DeleteGroup(ctx context.Context, group string) ([]string, error) {
+	keys := gs.groups[group]
+	delete(gs.groups, group)
+	return keys, nil
+}
+
+func TestSyncedCacheDeleteGroup(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1", WritePolicy: WritePolicyWriteThrough})
+	store := &groupStore{groups: make(map[string][]string)}
+	c.store = store
+	publisher := &publishingSynchronizer{}
+	c.synchronizer = publisher
+	ctx := context.Background()
+
+	for _, key := range []string{"tenant:1:a", "tenant:1:b"} {
+		if err := c.Set(ctx, key, "v", WithGroup("tenant:1")); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	if err := c.Set(ctx, "tenant:2:a", "v", WithGroup("tenant:2")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if err := c.DeleteGroup(ctx, "tenant:1"); err != nil {
+		t.Fatalf("DeleteGroup failed: %v", err)
+	}
+	for _, key := range []string{"tenant:1:a", "tenant:1:b"} {
+		if _, found := c.local.Get(key); found {
+			t.Errorf("Expected %s to be deleted", key)
+		}
+	}
+	if _, found := c.local.Get("tenant:2:a"); !found {
+		t.Error("Expected the other group to be kept")
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	var deleted []string
+	for _, event := range publisher.events {
+		if event.Action == ActionDelete {
+			deleted = append(deleted, event.Key)
+		}
+	}
+	if !slices.Equal(deleted, []string{"tenant:1:a", "tenant:1:b"}) {
+		t.Errorf("Expected delete events for the group, got %v", deleted)
+	}
+}
+
+func TestSyncedCacheGroupsNotSupported(t *testing.T) {
+	c := newMockedCache(t, Options{WritePolicy: WritePolicyWriteThrough})
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "key", "v", WithGroup("group")); !errors.Is(err, ErrGroupsNotSupported) {
+		t.Errorf("Expected ErrGroupsNotSupported from Set, got %v", err)
+	}
+	if err := c.DeleteGroup(ctx, "group"); !errors.Is(err, ErrGroupsNotSupported) {
+		t.Errorf("Expected ErrGroupsNotSupported from DeleteGroup, got %v", err)
+	}
+	if err := c.Set(ctx, "key", "v", WithGroup("group"), WithWritePolicy(WritePolicyLocalOnly)); err != nil {
+		t.Errorf("Expected a local-only write to ignore the group, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// healthProbeKey is the local cache key written and read back by Health.
+const healthProbeKey = "__distributed_cache_health__"
+
+// Health is the status of the cache and its dependencies returned by Cache.Health,
+// suitable for Kubernetes readiness and liveness probes.
+type Health struct {
+	// Healthy reports that Redis is reachable, the subscription is alive and the
+	// local cache is usable.
+	Healthy bool `json:"healthy"`
+
+	// RedisReachable reports that the remote store answered a ping in RedisLatency.
+	RedisReachable bool          `json:"redis_reachable"`
+	RedisLatency   time.Duration `json:"redis_latency"`
+	RedisError     string        `json:"redis_error,omitempty"`
+
+	// SubscriptionAlive reports that a ping published on every sync channel came back.
+	// It is true when the synchronizer does not implement ReadySynchronizer.
+	SubscriptionAlive bool   `json:"subscription_alive"`
+	SubscriptionError string `json:"subscription_error,omitempty"`
+
+	// LocalCacheUsable reports that a probe value could be stored and read back locally.
+	LocalCacheUsable bool `json:"local_cache_usable"`
+
+	// LastEventAt is when the last synchronization event was received from another
+	// pod, and SinceLastEvent how long ago. Both are zero if none was received.
+	LastEventAt    time.Time     `json:"last_event_at,omitempty"`
+	SinceLastEvent time.Duration `json:"since_last_event"`
+}
+
+// Ping checks that the remote store is reachable. It is a no-op returning nil when
+// the store does not implement PingableStore.
+func (sc *SyncedCache) Ping(ctx context.Context) error {
+	if atomic.LoadInt32(&sc.closed) != 0 {
+		return ErrCacheClosed
+	}
+	if pinger, ok := sc.store.(PingableStore); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// Health checks the remote store, the sync subscription and the local cache, each
+// bounded by ctx. A closed cache is reported unhealthy.
+func (sc *SyncedCache) Health(ctx context.Context) Health {
+	var health Health
+	if nanos := atomic.LoadInt64(&sc.lastEvent); nanos != 0 {
+		health.LastEventAt = time.Unix(0, nanos)
+		health.SinceLastEvent = time.Since(health.LastEventAt)
+	}
+	if atomic.LoadInt32(&sc.closed) != 0 {
+		health.RedisError = ErrCacheClosed.Error()
+		health.SubscriptionError = ErrCacheClosed.Error()
+		return health
+	}
+
+	start := time.Now()
+	if err := sc.Ping(ctx); err != nil {
+		health.RedisError = err.Error()
+	} else {
+		health.RedisReachable = true
+		health.RedisLatency = time.Since(start)
+	}
+
+	health.SubscriptionAlive = true
+	if ready, ok := sc.synchronizer.(ReadySynchronizer); ok {
+		if err := ready.WaitReady(ctx); err != nil {
+			health.SubscriptionAlive = false
+			health.SubscriptionError = err.Error()
+		}
+	}
+
+	health.LocalCacheUsable = sc.probeLocal()
+	health.Healthy = health.RedisReachable && health.SubscriptionAlive && health.LocalCacheUsable
+
+	if sc.logging(DebugOps) && !health.Healthy {
+		sc.logger.Warn("Health: cache unhealthy", "redis_error", health.RedisError,
+			"subscription_error", health.SubscriptionError, "local_cache_usable", health.LocalCacheUsable)
+	}
+	return health
+}
+
+// probeLocal stores a probe value in the local cache and reads it back.
+func (sc *SyncedCache) probeLocal() bool {
+	if !sc.local.Set(healthProbeKey, true, 1) {
+		return false
+	}
+	if admitting, ok := sc.local.(AdmittingLocalCache); ok {
+		admitting.Wait()
+	}
+	_, found := sc.local.Get(healthProbeKey)
+	sc.local.Delete(healthProbeKey)
+	return found
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// pingStore is a store whose Ping returns a fixed error.
+type pingStore struct {
+	errorStore
+	pingError error
+}
+
+func (ps *pingStore) Ping(ctx context.Context) error {
+	return ps.pingError
+}
+
+func TestSyncedCacheHealth(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	c.store = &pingStore{}
+	c.synchronizer = &readySynchronizer{}
+	defer c.Close()
+
+	health := c.Health(context.Background())
+	if !health.Healthy || !health.RedisReachable || !health.SubscriptionAlive || !health.LocalCacheUsable {
+		t.Fatalf("Expected a healthy cache, got %+v", health)
+	}
+	if !health.LastEventAt.IsZero() || health.SinceLastEvent != 0 {
+		t.Fatalf("Expected no last event, got %+v", health)
+	}
+	if _, found := c.local.Get(healthProbeKey); found {
+		t.Fatal("Expected the probe value to be removed from the local cache")
+	}
+
+	c.handleInvalidation(InvalidationEvent{Key: "key", Sender: "pod-2", Action: ActionDelete})
+	time.Sleep(time.Millisecond)
+	if health := c.Health(context.Background()); health.LastEventAt.IsZero() || health.SinceLastEvent <= 0 {
+		t.Fatalf("Expected the last event to be reported, got %+v", health)
+	}
+}
+
+func TestSyncedCacheHealthUnhealthy(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	c.store = &pingStore{pingError: errors.New("connection refused")}
+	c.synchronizer = &readySynchronizer{readyError: context.DeadlineExceeded}
+	defer c.Close()
+
+	health := c.Health(context.Background())
+	if health.Healthy || health.RedisReachable || health.SubscriptionAlive {
+		t.Fatalf("Expected Redis and the subscription to be reported down, got %+v", health)
+	}
+	if health.RedisError != "connection refused" || health.SubscriptionError != context.DeadlineExceeded.Error() {
+		t.Fatalf("Unexpected errors: %+v", health)
+	}
+	if !health.LocalCacheUsable {
+		t.Fatal("Expected the local cache to be usable")
+	}
+	if err := c.Ping(context.Background()); err == nil {
+		t.Fatal("Expected Ping to fail")
+	}
+}
+
+func TestSyncedCacheHealthClosed(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	c.store = &pingStore{}
+	c.Close()
+
+	if health := c.Health(context.Background()); health.Healthy || health.RedisReachable {
+		t.Fatalf("Expected a closed cache to be unhealthy, got %+v", health)
+	}
+	if err := c.Ping(context.Background()); !errors.Is(err, ErrCacheClosed) {
+		t.Fatalf("Expected ErrCacheClosed, got %v", err)
+	}
+}
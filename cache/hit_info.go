@@ -0,0 +1,274 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// HitLevel describes which cache level served a value returned by GetWithInfo.
+type HitLevel string
+
+const (
+	// LevelMiss means the key was not found at any level.
+	LevelMiss HitLevel = "miss"
+	// LevelLocal means the value was served from the local in-process cache.
+	LevelLocal HitLevel = "local"
+	// LevelRemote means the value was fetched from the remote store during this call.
+	LevelRemote HitLevel = "remote"
+	// LevelLoader means the value was loaded from the source by a registered loader during this call.
+	LevelLoader HitLevel = "loader"
+)
+
+// HitSource describes how a value entered the local cache.
+type HitSource string
+
+const (
+	// SourceNone means the key was not found.
+	SourceNone HitSource = "none"
+	// SourceSet means the value was written by Set on this pod.
+	SourceSet HitSource = "set"
+	// SourceRemote means the value was populated from the remote store.
+	SourceRemote HitSource = "remote"
+	// SourcePropagated means the value was received from another pod via a
+	// propagation (ActionSet) event.
+	SourcePropagated HitSource = "propagated"
+	// SourceLoader means the value was loaded from the source by a registered loader.
+	SourceLoader HitSource = "loader"
+	// SourceUnknown means the value was served from the local cache, but how it
+	// entered it is no longer tracked.
+	SourceUnknown HitSource = "unknown"
+)
+
+// HitInfo describes a value returned by GetWithInfo.
+type HitInfo struct {
+	// Level is the cache level that served the value.
+	Level HitLevel
+
+	// Source is how the value entered the local cache.
+	Source HitSource
+
+	// Age is how long ago the value was stored in the local cache.
+	// It is zero for values fetched from the remote store during the call.
+	Age time.Duration
+
+	// Version is a per-key counter incremented every time this pod replaces the
+	// local entry, so callers can detect that a value changed between two reads.
+	Version uint64
+
+	// Size is the serialized size of the value in bytes, or 0 if unknown.
+	Size int
+
+	// Stale reports that the value was invalidated and is being served under
+	// stale-while-revalidate while a fresh value is fetched in the background.
+	Stale bool
+
+	// Tags are the tags the value was stored with, see WithTags.
+	Tags []string
+
+	// CreatedAt is when the value was written, on whichever pod wrote it, and
+	// Origin is the PodID of that pod. They are only known for values written
+	// with Options.PayloadEnvelope, and are zero otherwise.
+	CreatedAt time.Time
+	Origin    string
+}
+
+// missInfo is the HitInfo returned when a key is not found.
+var missInfo = HitInfo{Level: LevelMiss, Source: SourceNone}
+
+// defaultEntryInfoSize bounds the number of tracked entries when LocalCacheConfig.MaxSize is not set.
+const defaultEntryInfoSize = 10000
+
+// entryInfo is the metadata tracked for a local cache entry.
+type entryInfo struct {
+	source    HitSource
+	storedAt  time.Time
+	expiresAt time.Time // zero if the entry does not expire
+	version   uint64
+	size      int
+	tags      []string
+	createdAt time.Time // when the value was written, from its envelope
+	origin    string    // pod that wrote the value, from its envelope
+	clock     uint64    // hybrid logical clock of the propagated write, 0 if unknown
+	raw       []byte    // serialized value, kept when Options.KeepRawBytes is set
+	etag      string    // ETag of the serialized value, computed when Options.ComputeETags is set
+}
+
+// entryInfos tracks metadata for local cache entries in a bounded LRU,
+// so values in the local cache are stored untouched. The expiry of an entry is
+// only known while its metadata is tracked, so entries that expire are dropped
+// from the local cache along with their metadata.
+type entryInfos struct {
+	entries  *lru.Cache[string, entryInfo]
+	localTTL time.Duration    // default local freshness window, 0 if entries stay fresh
+	keepRaw  bool             // keep the serialized value of entries, see Options.KeepRawBytes
+	etags    bool             // compute the ETag of entries, see Options.ComputeETags
+	onDrop   func(key string) // removes an expiring entry pushed out of the table from the local cache
+}
+
+// newEntryInfos creates an entry metadata table holding at most size entries,
+// expiring them localTTL after they are recorded when it is positive.
+func newEntryInfos(size int, localTTL time.Duration) *entryInfos {
+	if size <= 0 {
+		size = defaultEntryInfoSize
+	}
+	ei := &entryInfos{localTTL: localTTL}
+	ei.entries, _ = lru.NewWithEvict(size, ei.evicted)
+	return ei
+}
+
+// evicted is called by the LRU for every entry it forgets. Expiring entries are
+// dropped from the local cache, which is a no-op for those removed with it.
+func (ei *entryInfos) evicted(key string, info entryInfo) {
+	if !info.expiresAt.IsZero() && ei.onDrop != nil {
+		ei.onDrop(key)
+	}
+}
+
+// record stores metadata for a key whose serialized value data was just written
+// to the local cache.
+func (ei *entryInfos) record(key string, source HitSource, data []byte) {
+	var version uint64 = 1
+	if prev, ok := ei.entries.Peek(key); ok {
+		version = prev.version + 1
+	}
+	info := entryInfo{
+		source:   source,
+		storedAt: time.Now(),
+		version:  version,
+		size:     len(data),
+	}
+	info.createdAt, info.origin = writtenBy(data)
+	if ei.keepRaw {
+		info.raw = data
+	}
+	if ei.etags {
+		info.etag = ETag(data)
+	}
+	if ei.localTTL > 0 {
+		info.expiresAt = info.storedAt.Add(ei.localTTL)
+	}
+	ei.entries.Add(key, info)
+}
+
+// annotate records the expiry and tags of a key that was just recorded. The
+// entry expires after ttl or after its local freshness window, whichever comes
+// first; a positive localTTL replaces the default window for this entry.
+func (ei *entryInfos) annotate(key string, ttl, localTTL time.Duration, tags []string) {
+	if ttl <= 0 && localTTL <= 0 && len(tags) == 0 {
+		return
+	}
+	info, ok := ei.entries.Peek(key)
+	if !ok {
+		return
+	}
+	info.expiresAt = time.Time{}
+	if window := ei.window(ttl, localTTL); window > 0 {
+		info.expiresAt = info.storedAt.Add(window)
+	}
+	info.tags = tags
+	ei.entries.Add(key, info)
+}
+
+// stamp records that the entry of key, which was just recorded, holds the value
+// of the propagated write stamped with clock.
+func (ei *entryInfos) stamp(key string, clock uint64) {
+	if info, ok := ei.entries.Peek(key); ok {
+		info.clock = clock
+		ei.entries.Add(key, info)
+	}
+}
+
+// clock returns the clock of the propagated write held by the entry of key, or 0.
+func (ei *entryInfos) clock(key string) uint64 {
+	info, _ := ei.entries.Peek(key)
+	return info.clock
+}
+
+// window returns how long an entry stored with ttl and localTTL stays in the
+// local cache: until ttl or its local freshness window ends, whichever comes
+// first, where a positive localTTL replaces the default window. It returns 0
+// when the entry does not expire.
+func (ei *entryInfos) window(ttl, localTTL time.Duration) time.Duration {
+	window := ei.localTTL
+	if localTTL > 0 {
+		window = localTTL
+	}
+	if ttl > 0 && (window <= 0 || ttl < window) {
+		window = ttl
+	}
+	return window
+}
+
+// expired reports whether the local entry for key has outlived its TTL.
+func (ei *entryInfos) expired(key string) bool {
+	info, ok := ei.entries.Peek(key)
+	return ok && !info.expiresAt.IsZero() && time.Now().After(info.expiresAt)
+}
+
+// expiry returns when the local entry for key expires, or the zero time.
+func (ei *entryInfos) expiry(key string) time.Time {
+	info, _ := ei.entries.Peek(key)
+	return info.expiresAt
+}
+
+// hitInfo returns the HitInfo for a key served from the local cache.
+func (ei *entryInfos) hitInfo(key string) HitInfo {
+	info, ok := ei.entries.Get(key)
+	if !ok {
+		return HitInfo{Level: LevelLocal, Source: SourceUnknown}
+	}
+	return HitInfo{
+		Level:     LevelLocal,
+		Source:    info.source,
+		Age:       time.Since(info.storedAt),
+		Version:   info.version,
+		Size:      info.size,
+		Tags:      info.tags,
+		CreatedAt: info.createdAt,
+		Origin:    info.origin,
+	}
+}
+
+// raw returns the serialized value kept for key, if any.
+func (ei *entryInfos) raw(key string) ([]byte, bool) {
+	info, ok := ei.entries.Peek(key)
+	if !ok || info.raw == nil {
+		return nil, false
+	}
+	return info.raw, true
+}
+
+// etag returns the ETag of the value tracked for key, computed when it was
+// stored or from its kept serialized bytes.
+func (ei *entryInfos) etag(key string) (string, bool) {
+	info, ok := ei.entries.Peek(key)
+	switch {
+	case !ok:
+		return "", false
+	case info.etag != "":
+		return info.etag, true
+	case info.raw != nil:
+		return ETag(info.raw), true
+	}
+	return "", false
+}
+
+// remove forgets metadata for a key.
+func (ei *entryInfos) remove(key string) {
+	ei.entries.Remove(key)
+}
+
+// clear forgets all tracked metadata.
+func (ei *entryInfos) clear() {
+	ei.entries.Purge()
+}
+
+// GetWithInfo retrieves a value from the cache along with metadata describing
+// which level served it, how it entered the local cache, its age, version and serialized size.
+// This lets handlers emit accurate X-Cache headers and metrics, or apply conditional
+// logic such as only re-validating remote hits.
+func (sc *SyncedCache) GetWithInfo(ctx context.Context, key string) (any, bool, HitInfo) {
+	return sc.get(ctx, key)
+}
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEntryInfosRecordAndVersion(t *testing.T) {
+	ei := newEntryInfos(10, 0)
+
+	ei.record("key", SourceSet, make([]byte, 5))
+	info := ei.hitInfo("key")
+	if info.Level != LevelLocal || info.Source != SourceSet || info.Version != 1 || info.Size != 5 {
+		t.Fatalf("Unexpected info after first record: %+v", info)
+	}
+
+	ei.record("key", SourcePropagated, make([]byte, 7))
+	info = ei.hitInfo("key")
+	if info.Source != SourcePropagated || info.Version != 2 || info.Size != 7 {
+		t.Fatalf("Unexpected info after second record: %+v", info)
+	}
+
+	ei.remove("key")
+	info = ei.hitInfo("key")
+	if info.Version != 0 || info.Level != LevelLocal || info.Source != SourceUnknown {
+		t.Fatalf("Expected unknown local info after remove, got %+v", info)
+	}
+}
+
+func TestEntryInfosLocalTTL(t *testing.T) {
+	ei := newEntryInfos(10, time.Minute)
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		localTTL time.Duration
+		want     time.Duration
+	}{
+		{"default window", 0, 0, time.Minute},
+		{"shorter ttl", time.Second, 0, time.Second},
+		{"longer ttl", time.Hour, 0, time.Minute},
+		{"per-entry window", 0, time.Hour, time.Hour},
+		{"per-entry window and ttl", 2 * time.Hour, time.Hour, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ei.record("key", SourceSet, make([]byte, 1))
+			ei.annotate("key", tt.ttl, tt.localTTL, nil)
+			info, _ := ei.entries.Peek("key")
+			if got := info.expiresAt.Sub(info.storedAt); got != tt.want {
+				t.Errorf("Expected the entry to expire after %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEntryInfosBounded(t *testing.T) {
+	ei := newEntryInfos(2, 0)
+	ei.record("a", SourceSet, make([]byte, 1))
+	ei.record("b", SourceSet, make([]byte, 1))
+	ei.record("c", SourceSet, make([]byte, 1))
+
+	if ei.entries.Len() != 2 {
+		t.Fatalf("Expected 2 tracked entries, got %d", ei.entries.Len())
+	}
+}
+
+func TestEntryInfosDropsExpiringEntries(t *testing.T) {
+	ei := newEntryInfos(2, 0)
+	var dropped []string
+	ei.onDrop = func(key string) { dropped = append(dropped, key) }
+
+	ei.record("expiring", SourceSet, make([]byte, 1))
+	ei.annotate("expiring", 0, time.Minute, nil)
+	ei.record("b", SourceSet, make([]byte, 1))
+	ei.record("c", SourceSet, make([]byte, 1))
+	ei.record("d", SourceSet, make([]byte, 1))
+
+	if len(dropped) != 1 || dropped[0] != "expiring" {
+		t.Fatalf("Expected only the expiring entry pushed out to be dropped, got %v", dropped)
+	}
+}
+
+func TestSyncedCacheGetWithInfoSources(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PodID = "test-pod-get-with-info"
+	opts.RedisAddr = "localhost:6379"
+	opts.LocalCacheFactory = NewLRUCacheFactory(100)
+	opts.ReaderCanSetToRedis = true
+
+	c, err := New(opts)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key := "test:get-with-info"
+	if err := c.Set(ctx, key, "value"); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	// Local hit
+	value, found, info := c.GetWithInfo(ctx, key)
+	if !found || value != "value" {
+		t.Fatalf("Expected local hit, got %v %v", value, found)
+	}
+	if info.Level != LevelLocal || info.Source != SourceSet {
+		t.Fatalf("Expected local hit of a set value, got %s/%s", info.Level, info.Source)
+	}
+	if info.Size != len(`"value"`) {
+		t.Fatalf("Expected size %d, got %d", len(`"value"`), info.Size)
+	}
+
+	// Remote hit
+	c.local.Delete(key)
+	_, found, info = c.GetWithInfo(ctx, key)
+	if !found || info.Level != LevelRemote || info.Source != SourceRemote {
+		t.Fatalf("Expected remote hit, got found=%v level=%s", found, info.Level)
+	}
+
+	// Local hit of a value populated from remote
+	_, found, info = c.GetWithInfo(ctx, key)
+	if !found || info.Level != LevelLocal || info.Source != SourceRemote {
+		t.Fatalf("Expected local hit of remote value, got found=%v %s/%s", found, info.Level, info.Source)
+	}
+
+	// Propagated hit
+	data, _ := c.serializer.Marshal("propagated")
+	c.handleInvalidation(InvalidationEvent{Key: key, Sender: "other-pod", Action: ActionSet, Value: data})
+	value, found, info = c.GetWithInfo(ctx, key)
+	if !found || value != "propagated" || info.Level != LevelLocal || info.Source != SourcePropagated {
+		t.Fatalf("Expected propagated hit, got %v %v %s", value, found, info.Source)
+	}
+
+	// Miss
+	_, found, info = c.GetWithInfo(ctx, "test:get-with-info:missing")
+	if found || info.Level != LevelMiss || info.Source != SourceNone {
+		t.Fatalf("Expected miss, got found=%v level=%s", found, info.Level)
+	}
+}
+
+func TestSyncedCacheGetWithInfoUntrackedSource(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	defer c.Close()
+	local, err := NewLRUCacheFactory(10).Create()
+	if err != nil {
+		t.Fatalf("Failed to create local cache: %v", err)
+	}
+	c.local = local
+	c.local.Set("key", "value", 1)
+
+	_, found, info := c.GetWithInfo(context.Background(), "key")
+	if !found || info.Level != LevelLocal || info.Source != SourceUnknown {
+		t.Fatalf("Expected a local hit of unknown source, got %+v", info)
+	}
+	stats := c.Stats()
+	if stats.LocalHits != 1 || stats.LocalHitsSet+stats.LocalHitsRemote+stats.LocalHitsPropagated+stats.LocalHitsLoader != 0 {
+		t.Errorf("Expected the hit to be counted in LocalHits only, got %+v", stats)
+	}
+}
@@ -0,0 +1,194 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// hlcLogicalBits is the number of low bits of a hybrid logical clock timestamp
+// holding its logical counter; the high bits hold Unix milliseconds.
+const hlcLogicalBits = 16
+
+// defaultMaxClockSkew is the bound used when Options.MaxClockSkew is 0.
+const defaultMaxClockSkew = time.Minute
+
+// hybridClock is a hybrid logical clock stamping the writes of this pod. Its
+// timestamps follow the wall clock, never go backwards and are always after
+// every timestamp observed from other pods, so writes are ordered consistently
+// by every pod even when their wall clocks are seconds apart. The zero value is
+// ready to use.
+type hybridClock struct {
+	mu      sync.Mutex
+	last    uint64
+	maxSkew time.Duration // how far ahead of the wall clock observed timestamps may be, unbounded when 0
+}
+
+// tick returns a timestamp after every timestamp returned or observed so far.
+func (hc *hybridClock) tick() uint64 {
+	wall := uint64(time.Now().UnixMilli()) << hlcLogicalBits
+
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if wall > hc.last {
+		hc.last = wall
+	} else {
+		hc.last++
+	}
+	return hc.last
+}
+
+// observe moves the clock past a timestamp received from another pod and reports
+// whether it did. Timestamps more than maxSkew ahead of the wall clock are
+// rejected, so that a pod with a clock set far in the future cannot drag the
+// clocks of the other pods along.
+func (hc *hybridClock) observe(remote uint64) bool {
+	if hc.maxSkew > 0 && remote>>hlcLogicalBits > uint64(time.Now().Add(hc.maxSkew).UnixMilli()) {
+		return false
+	}
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if remote > hc.last {
+		hc.last = remote
+	}
+	return true
+}
+
+// keyClocks keeps the timestamp of the last write applied to each key, and of the
+// last Clear, to discard events reordered behind a newer write. Keys are kept in
+// a bounded LRU; events for forgotten keys are applied. The zero value is ready
+// to use.
+type keyClocks struct {
+	mu      sync.Mutex
+	size    int // maximum number of keys, defaultEntryInfoSize when 0
+	entries *lru.Cache[string, uint64]
+	cleared uint64
+}
+
+// advance records clock as the last write to key and reports whether it is newer
+// than the last write recorded for key and than the last Clear.
+func (kc *keyClocks) advance(key string, clock uint64) bool {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if clock <= kc.cleared {
+		return false
+	}
+	if kc.entries == nil {
+		size := kc.size
+		if size <= 0 {
+			size = defaultEntryInfoSize
+		}
+		kc.entries, _ = lru.New[string, uint64](size)
+	}
+	if last, ok := kc.entries.Get(key); ok && clock <= last {
+		return false
+	}
+	kc.entries.Add(key, clock)
+	return true
+}
+
+// newer reports whether clock is newer than the last write recorded for key and
+// than the last Clear, without recording it.
+func (kc *keyClocks) newer(key string, clock uint64) bool {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if clock <= kc.cleared {
+		return false
+	}
+	if kc.entries != nil {
+		if last, ok := kc.entries.Peek(key); ok && clock <= last {
+			return false
+		}
+	}
+	return true
+}
+
+// untouched reports whether no write to key and no Clear were recorded.
+func (kc *keyClocks) untouched(key string) bool {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if kc.cleared != 0 {
+		return false
+	}
+	return kc.entries == nil || !kc.entries.Contains(key)
+}
+
+// last returns the clock of the last write recorded for key, or 0 if none is known.
+func (kc *keyClocks) last(key string) uint64 {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if kc.entries == nil {
+		return 0
+	}
+	clock, _ := kc.entries.Peek(key)
+	return clock
+}
+
+// clear records clock as the last Clear, which supersedes every earlier write,
+// and reports whether it is newer than the last Clear.
+func (kc *keyClocks) clear(clock uint64) bool {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	if clock <= kc.cleared {
+		return false
+	}
+	kc.cleared = clock
+	if kc.entries != nil {
+		kc.entries.Purge()
+	}
+	return true
+}
+
+// stamp stamps a write event of this pod with the hybrid logical clock and
+// records it as the last write to its key, or as the last Clear.
+func (sc *SyncedCache) stamp(event InvalidationEvent) InvalidationEvent {
+	event.Clock = sc.clock.tick()
+	if event.Action == ActionClear {
+		sc.clocks.clear(event.Clock)
+	} else {
+		sc.clocks.advance(event.Key, event.Clock)
+	}
+	return event
+}
+
+// inOrder reports whether a received write event must be applied: events
+// stamped with a hybrid logical clock older than the last write applied to
+// their key, or than the last Clear, are counted in Stats.ReorderedEvents and
+// discarded. Relays carry the clock of a read from Redis rather than of a write:
+// they are discarded behind a newer write but not recorded, so they never discard
+// the writes that follow. Unstamped events, sent by older versions, are always
+// applied, as are events stamped more than Options.MaxClockSkew ahead of the wall
+// clock, counted in Stats.SkewedEvents, whose stamp is ignored.
+func (sc *SyncedCache) inOrder(event InvalidationEvent) bool {
+	if event.Clock == 0 {
+		return true
+	}
+	if !sc.clock.observe(event.Clock) {
+		atomic.AddInt64(&sc.stats.SkewedEvents, 1)
+		if sc.logging(DebugSync) {
+			sc.logger.Warn("Sync: ignored the clock of an event stamped too far ahead", "key", event.Key, "action", event.Action, "sender", event.Sender)
+		}
+		return true
+	}
+
+	var newer bool
+	switch {
+	case event.Relay:
+		newer = sc.clocks.newer(event.Key, event.Clock)
+	case event.Action == ActionSet, event.Action == ActionInvalidate, event.Action == ActionDelete:
+		newer = sc.clocks.advance(event.Key, event.Clock)
+	case event.Action == ActionClear:
+		newer = sc.clocks.clear(event.Clock)
+	default:
+		return true
+	}
+	if !newer {
+		atomic.AddInt64(&sc.stats.ReorderedEvents, 1)
+		if sc.debugging(DebugSync) {
+			sc.logger.Debug("Sync: discarded event older than the last write", "key", event.Key, "action", event.Action, "sender", event.Sender)
+		}
+	}
+	return newer
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHybridClock(t *testing.T) {
+	var hc hybridClock
+	first := hc.tick()
+	if second := hc.tick(); second <= first {
+		t.Fatalf("Expected increasing timestamps, got %d then %d", first, second)
+	}
+
+	// A pod whose wall clock runs a minute ahead
+	ahead := uint64(time.Now().Add(time.Minute).UnixMilli()) << hlcLogicalBits
+	hc.observe(ahead)
+	if next := hc.tick(); next <= ahead {
+		t.Errorf("Expected timestamps after the observed one, got %d <= %d", next, ahead)
+	}
+	hc.observe(first)
+	if next := hc.tick(); next <= ahead {
+		t.Error("Expected observing an older timestamp not to move the clock back")
+	}
+}
+
+func TestSyncedCacheDiscardsReorderedEvents(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1"})
+	defer c.Close()
+
+	c.applyEvent(InvalidationEvent{Key: "k", Sender: "pod-2", Action: ActionSet, Value: []byte(`"new"`), Clock: 20})
+	c.applyEvent(InvalidationEvent{Key: "k", Sender: "pod-3", Action: ActionSet, Value: []byte(`"old"`), Clock: 10})
+	if value, _ := c.local.Get("k"); value != "new" {
+		t.Errorf("Expected the older write to be discarded, got %v", value)
+	}
+	c.applyEvent(InvalidationEvent{Key: "k", Sender: "pod-3", Action: ActionSet, Value: []byte(`"legacy"`)})
+	if value, _ := c.local.Get("k"); value != "legacy" {
+		t.Errorf("Expected unstamped events to be applied, got %v", value)
+	}
+
+	c.applyEvent(InvalidationEvent{Key: "*", Sender: "pod-2", Action: ActionClear, Clock: 30})
+	c.applyEvent(InvalidationEvent{Key: "k", Sender: "pod-3", Action: ActionSet, Value: []byte(`"before clear"`), Clock: 25})
+	if _, found := c.local.Get("k"); found {
+		t.Error("Expected a write older than the last Clear to be discarded")
+	}
+	if stats := c.Stats(); stats.ReorderedEvents != 2 {
+		t.Errorf("Expected 2 reordered events, got %d", stats.ReorderedEvents)
+	}
+}
+
+func TestSyncedCacheIgnoresSkewedClocks(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1"})
+	defer c.Close()
+	c.clock.maxSkew = time.Minute
+
+	future := uint64(time.Now().Add(time.Hour).UnixMilli()) << hlcLogicalBits
+	c.applyEvent(InvalidationEvent{Key: "k", Sender: "pod-2", Action: ActionSet, Value: []byte(`"skewed"`), Clock: future})
+	if value, _ := c.local.Get("k"); value != "skewed" {
+		t.Errorf("Expected the skewed event to be applied, got %v", value)
+	}
+	if c.clock.tick() >= future {
+		t.Error("Expected the clock not to move to the skewed stamp")
+	}
+
+	c.applyEvent(InvalidationEvent{Key: "k", Sender: "pod-3", Action: ActionSet, Value: []byte(`"next"`), Clock: c.clock.tick()})
+	if value, _ := c.local.Get("k"); value != "next" {
+		t.Errorf("Expected the skewed stamp not to discard later writes, got %v", value)
+	}
+	if stats := c.Stats(); stats.SkewedEvents != 1 || stats.ReorderedEvents != 0 {
+		t.Errorf("Expected 1 skewed and no reordered event, got %d and %d", stats.SkewedEvents, stats.ReorderedEvents)
+	}
+}
+
+func TestSyncedCacheLocalWriteWinsOverOlderRemoteWrite(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1", WritePolicy: WritePolicyWriteThrough})
+	defer c.Close()
+	synchronizer := &publishingSynchronizer{}
+	c.synchronizer = synchronizer
+	c.store = &valueStore{}
+
+	// pod-2 wrote first, with a wall clock running behind, but its event arrives late
+	remote := c.clock.tick()
+	if err := c.Set(context.Background(), "k", "local"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if len(synchronizer.events) != 1 || synchronizer.events[0].Clock <= remote {
+		t.Fatalf("Expected the published event to be stamped after %d, got %+v", remote, synchronizer.events)
+	}
+	c.applyEvent(InvalidationEvent{Key: "k", Sender: "pod-2", Action: ActionSet, Value: []byte(`"remote"`), Clock: remote})
+	if value, _ := c.local.Get("k"); value != "local" {
+		t.Errorf("Expected the newer local write to be kept, got %v", value)
+	}
+}
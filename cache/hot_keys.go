@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultHotKeysInterval is how often access counts are flushed to the hot list
+// when Options.HotKeysInterval is not set.
+const defaultHotKeysInterval = 10 * time.Second
+
+// hotKeysTrackFactor bounds the hot list kept in the remote store to this many
+// times Options.HotKeys, so keys just below the top N can still climb into it.
+const hotKeysTrackFactor = 10
+
+// maxPendingHotKeys bounds the number of distinct keys counted between flushes.
+const maxPendingHotKeys = 10000
+
+// hotKeyCounter counts key accesses between flushes to the hot list.
+type hotKeyCounter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+// newHotKeyCounter creates an empty access counter.
+func newHotKeyCounter() *hotKeyCounter {
+	return &hotKeyCounter{counts: make(map[string]int64)}
+}
+
+// record counts an access to key. New keys are dropped once maxPendingHotKeys
+// distinct keys are pending.
+func (hc *hotKeyCounter) record(key string) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if _, ok := hc.counts[key]; !ok && len(hc.counts) >= maxPendingHotKeys {
+		return
+	}
+	hc.counts[key]++
+}
+
+// take returns the pending counts and resets them.
+func (hc *hotKeyCounter) take() map[string]int64 {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	counts := hc.counts
+	hc.counts = make(map[string]int64)
+	return counts
+}
+
+// recordHotKey counts an access to key for the hot list, when enabled.
+func (sc *SyncedCache) recordHotKey(key string) {
+	if sc.hotKeys != nil {
+		sc.hotKeys.record(key)
+	}
+}
+
+// startHotKeys warms the local cache from the persisted hot list and starts
+// flushing access counts to it. It is a no-op when Options.HotKeys is not set
+// or the store does not implement HotKeysStore.
+func (sc *SyncedCache) startHotKeys() {
+	if sc.options.HotKeys <= 0 {
+		return
+	}
+	store, ok := sc.store.(HotKeysStore)
+	if !ok {
+		return
+	}
+
+	sc.hotKeys = newHotKeyCounter()
+	sc.warmHotKeys(store)
+	sc.goBackground(func(ctx context.Context) {
+		sc.runHotKeys(ctx, store)
+	})
+}
+
+// warmHotKeys prefetches the top keys of the persisted hot list.
+func (sc *SyncedCache) warmHotKeys(store HotKeysStore) {
+	ctx, cancel := context.WithTimeout(context.Background(), sc.options.ContextTimeout)
+	defer cancel()
+
+	keys, err := store.HotKeys(ctx, sc.options.HotKeys)
+	if err != nil {
+		sc.reportError(OpHotKeys, "", ErrRemoteStore, err)
+		if sc.logging(DebugOps) {
+			sc.logger.Error("HotKeys: failed to read hot list", "error", err)
+		}
+		return
+	}
+
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("HotKeys: warming local cache", "count", len(keys))
+	}
+	sc.Prefetch(ctx, keys...)
+}
+
+// runHotKeys flushes access counts to the hot list every HotKeysInterval until
+// ctx is cancelled, then flushes the remaining counts once more so a pod leaving
+// during a deploy still contributes its last interval.
+func (sc *SyncedCache) runHotKeys(ctx context.Context, store HotKeysStore) {
+	interval := sc.options.HotKeysInterval
+	if interval <= 0 {
+		interval = defaultHotKeysInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sc.flushHotKeys(ctx, store)
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), sc.options.ContextTimeout)
+			sc.flushHotKeys(flushCtx, store)
+			cancel()
+			return
+		}
+	}
+}
+
+// flushHotKeys adds the pending access counts to the hot list.
+func (sc *SyncedCache) flushHotKeys(ctx context.Context, store HotKeysStore) {
+	counts := sc.hotKeys.take()
+	if len(counts) == 0 {
+		return
+	}
+	if err := store.RecordHotKeys(ctx, counts, sc.options.HotKeys*hotKeysTrackFactor); err != nil {
+		sc.reportError(OpHotKeys, "", ErrRemoteStore, err)
+		if sc.logging(DebugOps) {
+			sc.logger.Error("HotKeys: failed to update hot list", "error", err)
+		}
+	}
+}
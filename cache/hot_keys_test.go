@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hotKeysStore is a store keeping values and the hot list in memory.
+type hotKeysStore struct {
+	errorStore
+	mu     sync.Mutex
+	values map[string][]byte
+	counts map[string]int64
+	keep   int
+}
+
+func (hs *hotKeysStore) Get(ctx context.Context, key string) ([]byte, error) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if data, ok := hs.values[key]; ok {
+		return data, nil
+	}
+	return hs.errorStore.Get(ctx, key)
+}
+
+func (hs *hotKeysStore) RecordHotKeys(ctx context.Context, counts map[string]int64, keep int) error {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	for key, count := range counts {
+		hs.counts[key] += count
+	}
+	hs.keep = keep
+	return nil
+}
+
+func (hs *hotKeysStore) HotKeys(ctx context.Context, n int) ([]string, error) {
+	return []string{"hot:1", "hot:2"}, nil
+}
+
+func (hs *hotKeysStore) count(key string) int64 {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return hs.counts[key]
+}
+
+func TestSyncedCacheHotKeysWarmOnStart(t *testing.T) {
+	c := newMockedCache(t, Options{HotKeys: 2})
+	c.store = &hotKeysStore{
+		values: map[string][]byte{"hot:1": []byte(`"a"`), "hot:2": []byte(`"b"`)},
+		counts: make(map[string]int64),
+	}
+	defer c.Close()
+
+	c.startHotKeys()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		_, found1 := c.local.Get("hot:1")
+		_, found2 := c.local.Get("hot:2")
+		if found1 && found2 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("Expected hot keys to be warmed into the local cache")
+}
+
+func TestSyncedCacheHotKeysRecordsAccesses(t *testing.T) {
+	c := newMockedCache(t, Options{HotKeys: 5, HotKeysInterval: 10 * time.Millisecond})
+	store := &hotKeysStore{counts: make(map[string]int64)}
+	c.store = store
+
+	c.startHotKeys()
+	ctx := context.Background()
+	if err := c.Set(ctx, "user:1", "alice"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		c.Get(ctx, "user:1")
+	}
+	c.Get(ctx, "missing")
+
+	deadline := time.Now().Add(time.Second)
+	for store.count("user:1") < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := store.count("user:1"); got != 3 {
+		t.Fatalf("Expected 3 recorded accesses, got %d", got)
+	}
+	if store.count("missing") != 0 {
+		t.Fatal("Misses should not be recorded as hot")
+	}
+	if store.keep != 5*hotKeysTrackFactor {
+		t.Fatalf("Expected hot list trimmed to %d keys, got %d", 5*hotKeysTrackFactor, store.keep)
+	}
+
+	// Counts pending at Close are flushed
+	c.Get(ctx, "user:1")
+	c.Close()
+	if got := store.count("user:1"); got != 4 {
+		t.Fatalf("Expected pending access to be flushed on Close, got %d", got)
+	}
+}
+
+func TestHotKeyCounterBound(t *testing.T) {
+	hc := newHotKeyCounter()
+	for i := 0; i < maxPendingHotKeys+10; i++ {
+		hc.record(strconv.Itoa(i))
+	}
+	hc.record("0")
+
+	counts := hc.take()
+	if len(counts) != maxPendingHotKeys {
+		t.Fatalf("Expected %d pending keys, got %d", maxPendingHotKeys, len(counts))
+	}
+	if counts["0"] != 2 {
+		t.Fatalf("Expected existing keys to keep counting, got %d", counts["0"])
+	}
+	if len(hc.take()) != 0 {
+		t.Fatal("Expected take to reset the counts")
+	}
+}
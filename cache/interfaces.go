@@ -2,6 +2,8 @@ package cache
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/huykn/distributed-cache/types"
 )
@@ -21,6 +23,17 @@ type Logger interface {
 	Error(msg string, args ...any)
 }
 
+// FieldLogger is an optional interface for loggers that can carry structured
+// fields, such as slog, zap or zerolog adapters. The cache attaches pod_id and
+// channel to every record through With; loggers without it get them appended
+// to each call's args.
+type FieldLogger interface {
+	Logger
+
+	// With returns a logger that attaches the key-value pairs in args to every record.
+	With(args ...any) Logger
+}
+
 // Marshaller defines the interface for JSON marshalling/unmarshalling.
 type Marshaller interface {
 	// Marshal serializes a value to bytes.
@@ -51,12 +64,66 @@ type LocalCache interface {
 	Metrics() LocalCacheMetrics
 }
 
+// AdmittingLocalCache is an optional interface implemented by local caches whose
+// admission policy may reject or asynchronously drop Sets (e.g. Ristretto).
+// It is used to detect rejected Sets and apply Options.RejectedSetPolicy.
+type AdmittingLocalCache interface {
+	// Wait blocks until buffered Sets have been applied.
+	Wait()
+
+	// Contains reports whether key is present without counting as an access.
+	Contains(key string) bool
+
+	// ForceSet stores a value, working around the admission policy where possible.
+	// It reports whether the value is present afterwards.
+	ForceSet(key string, value any, cost int64) bool
+}
+
+// ExpiringLocalCache is an optional interface implemented by local caches that can
+// expire entries on their own. It is used for values stored with WithTTL or
+// WithLocalTTL, or when Options.LocalTTL is set, so expired entries free memory
+// without waiting for a Get.
+type ExpiringLocalCache interface {
+	// SetWithTTL stores a value that expires after ttl.
+	SetWithTTL(key string, value any, cost int64, ttl time.Duration) bool
+}
+
+// EvictingLocalCache is an optional interface implemented by local caches that can
+// report the entries they evict or expire on their own. It is used to deliver
+// ChangeEvicted events to Cache.Subscribe.
+type EvictingLocalCache interface {
+	// OnEvict registers fn to be called with the key of each entry evicted or
+	// expired by the cache, not for Delete or Clear. It is called before the cache
+	// is used; fn must not block and may run on a goroutine of the cache.
+	OnEvict(fn func(key string))
+}
+
+// IterableLocalCache is an optional interface implemented by local caches that can
+// enumerate their entries. It is used by Cache.LocalKeys and Cache.IterateLocal.
+type IterableLocalCache interface {
+	// Keys returns the keys currently held.
+	Keys() []string
+
+	// Iterate calls fn for each entry without counting as an access, stopping when
+	// fn returns false.
+	Iterate(fn func(key string, value any) bool)
+}
+
 // LocalCacheMetrics represents local cache metrics.
 type LocalCacheMetrics struct {
 	Hits      int64
 	Misses    int64
 	Evictions int64
-	Size      int64
+
+	// Size is the used cost for cost-based caches such as Ristretto and MapCache,
+	// and the capacity in entries for the LRU, ARC and 2Q caches.
+	Size int64
+
+	// CostAdded and CostEvicted are the total cost of the values admitted to and
+	// removed from cost-based caches. Items is the number of entries held.
+	CostAdded   int64
+	CostEvicted int64
+	Items       int64
 }
 
 // LocalCacheFactory defines the interface for creating local cache implementations.
@@ -71,28 +138,155 @@ type Cache interface {
 	// Returns the value and true if found, nil and false otherwise.
 	Get(ctx context.Context, key string) (any, bool)
 
+	// GetWithInfo retrieves a value from the cache along with metadata describing
+	// where it came from, its age, version and serialized size.
+	GetWithInfo(ctx context.Context, key string) (any, bool, HitInfo)
+
+	// GetRaw retrieves the serialized bytes of a value, as encoded by the Marshaller,
+	// so they can be written to a response without marshalling the value again.
+	GetRaw(ctx context.Context, key string) ([]byte, bool)
+
+	// GetWithETag retrieves a value along with an ETag identifying its content,
+	// for HTTP conditional requests.
+	GetWithETag(ctx context.Context, key string) (any, string, bool)
+
+	// CheckETag reports whether etag matches the current value of key, in which
+	// case an HTTP handler can answer 304 Not Modified.
+	CheckETag(ctx context.Context, key string, etag string) bool
+
+	// Prefetch warms the local cache with the given keys in the background.
+	// It does not block; keys already present locally are skipped and the others
+	// are read from the remote store in batches.
+	Prefetch(ctx context.Context, keys ...string)
+
+	// Warmup populates the local cache with the given keys from the remote store,
+	// blocking until they are loaded or ctx is done.
+	Warmup(ctx context.Context, keys ...string) error
+
+	// RegisterLoader registers a read-through loader for keys matching pattern,
+	// where '*' matches any sequence of characters. On a miss at every level, Get
+	// loads the value with the loader, stores it and propagates it to other pods.
+	RegisterLoader(pattern string, loader LoaderFunc)
+
+	// RefreshAhead reloads key with loader every interval and stores the result
+	// like Set, on the leader pod when leader election is enabled. An interval of
+	// 0 stops refreshing key.
+	RefreshAhead(key string, interval time.Duration, loader LoaderFunc) error
+
 	// Set stores a value in the cache and propagates it to other pods.
 	// The value is stored in both local and remote storage, and other pods
 	// receive the value directly to update their local caches.
-	Set(ctx context.Context, key string, value any) error
+	// opts customize a single call, e.g. WithTTL, WithInvalidateOnly or WithVersion.
+	Set(ctx context.Context, key string, value any, opts ...SetOption) error
 
 	// SetWithInvalidate stores a value in the cache and invalidates it on other pods.
 	// The value is stored in both local and remote storage, but other pods
 	// only receive an invalidation event and must fetch from Redis if needed.
 	SetWithInvalidate(ctx context.Context, key string, value any) error
 
+	// SetAck stores a value like Set and waits, for at most timeout, until minAcks
+	// other pods confirm they applied it. It returns the number of pods that
+	// confirmed, ErrInsufficientAcks when fewer than minAcks did, and ErrPublish
+	// when the value could not be published.
+	SetAck(ctx context.Context, key string, value any, minAcks int, timeout time.Duration) (int, error)
+
+	// SetIfVersion stores a value only if its version in the remote store equals
+	// expectedVersion (0 for a key never written with SetIfVersion), and returns the
+	// new version. On conflict it returns the current version and ErrVersionConflict.
+	SetIfVersion(ctx context.Context, key string, value any, expectedVersion uint64) (uint64, error)
+
+	// Version returns the version of key in the remote store, as used by SetIfVersion.
+	Version(ctx context.Context, key string) (uint64, error)
+
 	// Delete removes a value from the cache.
 	// The value is removed from both local and remote storage.
 	Delete(ctx context.Context, key string) error
 
+	// DeleteGroup removes every value written with WithGroup(group) from both
+	// local and remote storage, on every pod.
+	DeleteGroup(ctx context.Context, group string) error
+
 	// Clear removes all values from the cache.
 	Clear(ctx context.Context) error
 
+	// DumpRemote writes every key of the remote store and its serialized value
+	// to w as JSON lines of DumpEntry.
+	DumpRemote(ctx context.Context, w io.Writer) error
+
+	// RestoreRemote writes the entries written by DumpRemote from r to the
+	// remote store and invalidates them on every pod.
+	RestoreRemote(ctx context.Context, r io.Reader) error
+
+	// Lock acquires a distributed lock on key, held for at most ttl, blocking until
+	// it is acquired or ctx is done. Use it to serialize read-modify-write updates
+	// to the same key across pods.
+	Lock(ctx context.Context, key string, ttl time.Duration) (Lock, error)
+
+	// Watch returns a channel receiving the lifecycle events of every pod sharing
+	// the sync channel. It is closed when ctx is done or the cache is closed.
+	Watch(ctx context.Context) <-chan LifecycleEvent
+
+	// Subscribe registers fn to be called for every change to this pod's local
+	// cache, until the returned function is called.
+	Subscribe(fn func(event ChangeEvent)) (unsubscribe func())
+
+	// Announce publishes a lifecycle event from this pod, e.g. LifecycleConfigReloaded.
+	Announce(ctx context.Context, eventType LifecycleType, details map[string]string) error
+
+	// LocalKeys returns the keys held in this pod's local cache.
+	LocalKeys() []string
+
+	// RemoteKeys calls fn with batches of the keys of the remote store starting
+	// with prefix.
+	RemoteKeys(ctx context.Context, prefix string, fn func(keys []string) error) error
+
+	// IterateLocal calls fn for each entry in this pod's local cache, stopping when
+	// fn returns false.
+	IterateLocal(fn func(key string, value any) bool)
+
+	// EstimateMemory estimates the memory held by this pod's local cache per key
+	// prefix, measuring one entry in sampleRate.
+	EstimateMemory(sampleRate int) MemoryEstimate
+
+	// Ping checks that the remote store is reachable.
+	Ping(ctx context.Context) error
+
+	// Health checks the remote store, the sync subscription and the local cache,
+	// returning a structured status suitable for readiness and liveness probes.
+	Health(ctx context.Context) Health
+
+	// Shutdown stops accepting operations, waits until ctx is done for in-flight
+	// writes to publish their events and for received events to be applied, then
+	// releases all resources.
+	Shutdown(ctx context.Context) error
+
 	// Close closes the cache and releases all resources.
 	Close() error
 
 	// Stats returns cache statistics.
 	Stats() Stats
+
+	// Describe returns a sanitized description of the effective configuration.
+	Describe() Description
+
+	// Peers returns the other pods currently sharing the sync channel, known from
+	// their heartbeats, see Options.HeartbeatInterval.
+	Peers() []Peer
+
+	// IsLeader reports whether this pod is the leader, see Options.LeaderElection.
+	IsLeader() bool
+
+	// OnLeadershipChange registers fn to be called when this pod becomes or stops
+	// being the leader.
+	OnLeadershipChange(fn func(leader bool))
+}
+
+// Writer persists cache values to a backing database.
+// It is called by Set either synchronously (write-through) or asynchronously
+// from a bounded queue (write-behind), see Options.WriteBehind.
+type Writer interface {
+	// Write persists the value stored under key.
+	Write(ctx context.Context, key string, value any) error
 }
 
 // Store defines the interface for remote storage backends (e.g., Redis).
@@ -113,6 +307,129 @@ type Store interface {
 	Close() error
 }
 
+// ExpiringStore is an optional interface implemented by stores that can expire
+// values. It is used by Set with WithTTL.
+type ExpiringStore interface {
+	// SetWithTTL stores a value that expires after ttl.
+	SetWithTTL(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// SizedStore is an optional interface implemented by stores that can report
+// the number of keys they hold. It is used to populate Stats.RemoteSize.
+type SizedStore interface {
+	// Size returns the number of keys in the store.
+	Size(ctx context.Context) (int64, error)
+}
+
+// FlushableStore is an optional interface implemented by stores that can remove
+// every key in their database, beyond the keys they own. It is used by Clear
+// when Options.DangerousFullFlush is set.
+type FlushableStore interface {
+	// FlushDB removes every key in the database.
+	FlushDB(ctx context.Context) error
+}
+
+// PingableStore is an optional interface implemented by stores that can check
+// their connection. It is used by Cache.Ping and Cache.Health.
+type PingableStore interface {
+	// Ping checks that the store is reachable.
+	Ping(ctx context.Context) error
+}
+
+// ExternalStore is an optional interface implemented by stores that can read keys
+// written by other systems, outside the store's namespace. It is used for keys
+// matching Options.ExternalFormats.
+type ExternalStore interface {
+	// GetExternal returns the string value of a Redis key.
+	GetExternal(ctx context.Context, key string) ([]byte, error)
+
+	// GetExternalHash returns the fields of a Redis hash.
+	GetExternalHash(ctx context.Context, key string) (map[string]string, error)
+}
+
+// VersionedStore is an optional interface implemented by stores that can write
+// values conditionally on a per-key version. It is used by Cache.SetIfVersion.
+type VersionedStore interface {
+	// SetIfVersion stores value only if the version of key equals expectedVersion,
+	// where 0 means the key has no version. It returns the new version and true on
+	// success, or the current version and false on conflict. Stores must also
+	// increment an existing version when the value is written by Set.
+	SetIfVersion(ctx context.Context, key string, value []byte, expectedVersion uint64) (uint64, bool, error)
+
+	// Version returns the version of key, or 0 if it has none.
+	Version(ctx context.Context, key string) (uint64, error)
+}
+
+// WarmupStore is an optional interface implemented by stores that can list and
+// read keys in batches. It is used by Cache.Warmup and Options.WarmupPattern.
+type WarmupStore interface {
+	// ScanKeys calls fn with batches of at most batchSize keys matching the glob
+	// pattern, until every key was visited or fn returns an error.
+	ScanKeys(ctx context.Context, pattern string, batchSize int, fn func(keys []string) error) error
+
+	// GetMulti returns the values of the given keys. Missing keys are omitted.
+	GetMulti(ctx context.Context, keys []string) (map[string][]byte, error)
+}
+
+// GroupStore is an optional interface implemented by stores that can record the
+// keys written under a group. It is used by WithGroup and Cache.DeleteGroup.
+// A key belongs to the group it was last written under: deleting it, or writing
+// it again without a group, removes it from its group.
+type GroupStore interface {
+	// AddToGroup records that keys, just written with ttl, belong to group. The
+	// group lives at least as long as its keys; a ttl of 0 means they do not expire.
+	AddToGroup(ctx context.Context, group string, ttl time.Duration, keys ...string) error
+
+	// DeleteGroup removes the keys written under group, and the group itself,
+	// and returns them.
+	DeleteGroup(ctx context.Context, group string) ([]string, error)
+}
+
+// HotKeysStore is an optional interface implemented by stores that can persist
+// a ranked list of the most accessed keys. It is used when Options.HotKeys is set.
+type HotKeysStore interface {
+	// RecordHotKeys adds access counts to the hot list, keeping only its top keep keys.
+	RecordHotKeys(ctx context.Context, counts map[string]int64, keep int) error
+
+	// HotKeys returns the n most accessed keys, most accessed first.
+	HotKeys(ctx context.Context, n int) ([]string, error)
+}
+
+// LockingStore is an optional interface implemented by stores that can hold
+// distributed locks. It is used by Cache.Lock.
+type LockingStore interface {
+	// TryLock acquires the lock on key for ttl if it is free, recording token as
+	// its owner. It reports whether the lock was acquired.
+	TryLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+
+	// Unlock releases the lock on key if it is still owned by token.
+	// It reports whether the lock was released.
+	Unlock(ctx context.Context, key, token string) (bool, error)
+}
+
+// LeasingStore is an optional interface implemented by stores that can extend
+// the expiry of a lock held in a LockingStore. It is used for leader election.
+type LeasingStore interface {
+	// ExtendLock resets the expiry of the lock on key to ttl if it is still owned
+	// by token. It reports whether the lock was extended.
+	ExtendLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+}
+
+// OutboxStore is an optional interface implemented by stores that can write a
+// value and publish a message in a single transaction. It is used with ConsistencyOutbox.
+type OutboxStore interface {
+	// SetAndPublish stores value under key and publishes message on channel
+	// atomically: either both happen or neither does.
+	SetAndPublish(ctx context.Context, key string, value []byte, channel string, message []byte) error
+}
+
+// PartitionedStore is an optional interface implemented by stores spreading keys
+// over several Redis instances. It is used to populate Stats.Partitions.
+type PartitionedStore interface {
+	// Partitions returns the health of every partition.
+	Partitions() []PartitionStatus
+}
+
 // Synchronizer defines the interface for cache synchronization across nodes.
 type Synchronizer interface {
 	// Subscribe starts listening for invalidation events.
@@ -128,9 +445,63 @@ type Synchronizer interface {
 	Close() error
 }
 
+// ReadySynchronizer is an optional interface implemented by synchronizers that can
+// confirm their subscription is active. It is used by New when SubscribeTimeout is set.
+type ReadySynchronizer interface {
+	// WaitReady blocks until the subscription is confirmed active or ctx is done.
+	WaitReady(ctx context.Context) error
+}
+
+// GapDetectingSynchronizer is an optional interface implemented by synchronizers
+// that number their events and detect events lost in transit.
+type GapDetectingSynchronizer interface {
+	// OnGap registers a callback for lost events.
+	OnGap(callback func(gap types.EventGap))
+}
+
+// DuplicateDetectingSynchronizer is an optional interface implemented by
+// synchronizers that detect another pod publishing with the same pod ID.
+type DuplicateDetectingSynchronizer interface {
+	// OnDuplicatePodID registers a callback for events revealing a duplicate pod ID.
+	OnDuplicatePodID(callback func())
+}
+
+// OutboxSynchronizer is an optional interface implemented by synchronizers that
+// deliver events as plain Redis Pub/Sub messages, so that an OutboxStore can
+// publish them in the same transaction as the write. It is used with ConsistencyOutbox.
+type OutboxSynchronizer interface {
+	// PrepareEvent returns the channel and message that Publish would send for event.
+	// The event counts as published for sequence numbering until it is canceled.
+	PrepareEvent(event types.InvalidationEvent) (channel string, message []byte, err error)
+
+	// CancelEvent reports that the message prepared for channel was not published,
+	// as the transaction failed, so that its sequence number can be reused and
+	// subscribers do not detect a gap.
+	CancelEvent(channel string, message []byte)
+}
+
+// FaultInjector injects faults into remote store operations and received
+// synchronization events, to test stale-data behavior and the circuit breaker
+// deterministically. It is set with Options.FaultInjector or applied to a Store
+// and Synchronizer with NewFaultyStore and NewFaultySynchronizer.
+type FaultInjector interface {
+	// StoreFault is called before each remote store operation; a non-nil error
+	// fails the operation without performing it. op is the lowercase Redis command
+	// name (e.g. "get", "set", "del"), "set" for the script writing values, or,
+	// for NewFaultyStore, the Store method name.
+	StoreFault(ctx context.Context, op string) error
+
+	// EventFault is called for each synchronization event received from another
+	// pod and returns the fault to apply to its delivery.
+	EventFault(event types.InvalidationEvent) EventFault
+}
+
 // InvalidationEvent is an alias for types.InvalidationEvent for backward compatibility
 type InvalidationEvent = types.InvalidationEvent
 
+// EventGap is an alias for types.EventGap
+type EventGap = types.EventGap
+
 // Action is an alias for types.Action for backward compatibility
 type Action = types.Action
 
@@ -140,6 +511,16 @@ const (
 	ActionInvalidate = types.Invalidate
 	ActionDelete     = types.Delete
 	ActionClear      = types.Clear
+	ActionLifecycle  = types.Lifecycle
+
+	ActionSnapshotRequest = types.SnapshotRequest
+	ActionSnapshotChunk   = types.SnapshotChunk
+
+	ActionDigest    = types.Digest
+	ActionHeartbeat = types.Heartbeat
+
+	ActionAckRequest = types.AckRequest
+	ActionAck        = types.Ack
 )
 
 // Stats represents cache statistics.
@@ -150,5 +531,134 @@ type Stats struct {
 	RemoteMisses  int64
 	LocalSize     int64
 	RemoteSize    int64
+	Evictions     int64
 	Invalidations int64
+
+	// LocalHitsSet, LocalHitsRemote, LocalHitsPropagated and LocalHitsLoader split
+	// LocalHits by how the served entry entered the local cache: Set on this pod,
+	// populated from the remote store, received via propagation, or loaded by a loader.
+	// Hits on entries whose source is no longer tracked are only counted in LocalHits.
+	// LocalHitsPropagated quantifies the reads saved by ActionSet propagation.
+	LocalHitsSet        int64
+	LocalHitsRemote     int64
+	LocalHitsPropagated int64
+	LocalHitsLoader     int64
+
+	// StaleHits is the number of Get calls served a stale value under stale-while-revalidate.
+	// They are also counted in LocalMisses.
+	StaleHits int64
+
+	// RejectedSets is the number of values the local cache rejected or dropped,
+	// after applying Options.RejectedSetPolicy.
+	RejectedSets int64
+
+	// PendingEvents is the number of synchronization events received but not yet applied.
+	PendingEvents int64
+
+	// OldestPendingEventAge is how long the oldest pending event has been waiting.
+	// A growing value means this pod is falling behind applying invalidations.
+	OldestPendingEventAge time.Duration
+
+	// EventGaps is the number of gaps detected in the sequence of events received
+	// from other pods, and MissedEvents the total number of events lost in them.
+	EventGaps    int64
+	MissedEvents int64
+
+	// CircuitState is the state of the circuit breaker around Redis, CircuitOpens
+	// the number of times it opened and QueuedWrites the number of keys whose
+	// writes are queued under DegradedWriteQueue.
+	CircuitState CircuitState
+	CircuitOpens int64
+	QueuedWrites int64
+
+	// PendingPublishes is the number of failed synchronization events waiting to
+	// be published again and DroppedPublishes the number given up on, see
+	// Options.PublishQueueSize.
+	PendingPublishes int64
+	DroppedPublishes int64
+
+	// EventQueueOverflows is the number of received events that found their
+	// propagation worker's queue full, see Options.PropagationQueueSize.
+	EventQueueOverflows int64
+
+	// OversizedPropagations is the number of Set calls whose value exceeded
+	// Options.MaxPropagationSize and was propagated as an invalidation.
+	OversizedPropagations int64
+
+	// OversizedValues is the number of values rejected with ErrValueTooLarge
+	// because they exceeded Options.MaxValueSize.
+	OversizedValues int64
+
+	// AntiEntropyRepairs is the number of local entries found to differ from
+	// another pod's by the anti-entropy job and fetched again, see
+	// Options.AntiEntropyInterval.
+	AntiEntropyRepairs int64
+
+	// BloomFilterSkips is the number of remote reads skipped because the Bloom
+	// filter showed the key does not exist in Redis, and BloomFilterBuilds the
+	// number of times the filter was built, see Options.BloomFilterInterval.
+	BloomFilterSkips  int64
+	BloomFilterBuilds int64
+
+	// CascadedInvalidations is the number of values invalidated because a key
+	// they depend on changed, see WithDependsOn.
+	CascadedInvalidations int64
+
+	// LocalSkips is the number of values not stored in the local cache because
+	// Options.ShouldCacheLocally rejected their key.
+	LocalSkips int64
+
+	// FilteredEvents is the number of events received from other pods and dropped
+	// by Options.EventFilter.
+	FilteredEvents int64
+
+	// ReorderedEvents is the number of events discarded because their hybrid
+	// logical clock was older than the last write applied to their key, e.g. a
+	// write of another pod delivered after a newer local write.
+	ReorderedEvents int64
+
+	// SkewedEvents is the number of events stamped more than Options.MaxClockSkew
+	// ahead of this pod's wall clock, applied as if they were not stamped.
+	SkewedEvents int64
+
+	// Refreshes is the number of keys reloaded and stored by RefreshAhead.
+	Refreshes int64
+
+	// RelayedHits is the number of values read from Redis after a local miss and
+	// published to the other pods, see Options.RelayRemoteHits.
+	RelayedHits int64
+
+	// CoalescedEvents is the number of events over Options.PublishRateLimit
+	// deferred and merged per key by RateLimitCoalesce, and RateLimitedEvents the
+	// number of events over it that were dropped. RateLimitedWrites is the number
+	// of writes rejected with ErrRateLimited by Options.WriteRateLimit.
+	CoalescedEvents   int64
+	RateLimitedEvents int64
+	RateLimitedWrites int64
+
+	// DuplicatePodIDs is the number of times another pod was seen publishing with
+	// this pod's PodID, see Options.HeartbeatInterval.
+	DuplicatePodIDs int64
+
+	// LastEventAt is when this pod last received a synchronization event and
+	// SinceLastEvent how long ago that was; both are zero until the first event.
+	// A SinceLastEvent growing while other pods write means this pod silently
+	// stopped receiving invalidations.
+	LastEventAt    time.Time
+	SinceLastEvent time.Duration
+
+	// PropagationLagP50 and PropagationLagP99 are the median and 99th percentile
+	// of the delay between the publication of the last received events and their
+	// reception, measured against the publishing pod's clock.
+	PropagationLagP50 time.Duration
+	PropagationLagP99 time.Duration
+
+	// Latencies are the latency histograms of local and remote reads,
+	// serialization, publishing and received events, keyed by operation. They
+	// are nil unless Options.EnableMetrics is set.
+	Latencies map[LatencyOp]LatencyHistogram
+
+	// Partitions is the health of each Redis instance when the store is
+	// partitioned with Options.RedisPartitions.
+	Partitions []PartitionStatus
 }
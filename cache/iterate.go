@@ -0,0 +1,43 @@
+package cache
+
+// LocalKeys returns the keys held in this pod's local cache, so operators and
+// admin endpoints can inspect what a pod is actually serving.
+// Local caches that cannot enumerate their entries (see IterableLocalCache), such
+// as Ristretto, report the keys this cache stored in them that are still present.
+func (sc *SyncedCache) LocalKeys() []string {
+	var keys []string
+	sc.IterateLocal(func(key string, _ any) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// IterateLocal calls fn for each entry in this pod's local cache, stopping when fn
+// returns false. Expired entries are skipped. Entries may be added or removed
+// concurrently, so the iteration is not a consistent snapshot.
+func (sc *SyncedCache) IterateLocal(fn func(key string, value any) bool) {
+	if iterable, ok := sc.local.(IterableLocalCache); ok {
+		iterable.Iterate(func(key string, value any) bool {
+			if sc.entryInfos.expired(key) {
+				return true
+			}
+			return fn(key, value)
+		})
+		return
+	}
+
+	// Fall back to the keys tracked alongside the local cache
+	for _, key := range sc.entryInfos.entries.Keys() {
+		if sc.entryInfos.expired(key) {
+			continue
+		}
+		value, found := sc.local.Get(key)
+		if !found {
+			continue
+		}
+		if !fn(key, value) {
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSyncedCacheLocalKeys(t *testing.T) {
+	sc := newMockedCache(t, Options{})
+	defer sc.Close()
+	ctx := context.Background()
+
+	_ = sc.Set(ctx, "a", 1)
+	_ = sc.Set(ctx, "b", 2)
+	_ = sc.Set(ctx, "expiring", 3, WithTTL(time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	keys := sc.LocalKeys()
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("Expected the live local keys, got %v", keys)
+	}
+
+	values := make(map[string]any)
+	sc.IterateLocal(func(key string, value any) bool {
+		values[key] = value
+		return true
+	})
+	if values["a"] != 1 || values["b"] != 2 {
+		t.Errorf("Expected the local values, got %v", values)
+	}
+}
+
+// opaqueLocalCache hides the iteration support of the local cache it wraps.
+type opaqueLocalCache struct {
+	LocalCache
+}
+
+func TestSyncedCacheLocalKeysWithoutIterableLocalCache(t *testing.T) {
+	sc := newMockedCache(t, Options{})
+	defer sc.Close()
+	sc.local = opaqueLocalCache{sc.local}
+	ctx := context.Background()
+
+	_ = sc.Set(ctx, "a", 1)
+	_ = sc.Set(ctx, "b", 2)
+	sc.local.Delete("b")
+
+	if keys := sc.LocalKeys(); len(keys) != 1 || keys[0] != "a" {
+		t.Fatalf("Expected the tracked keys still present, got %v", keys)
+	}
+}
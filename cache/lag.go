@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// lagSamples is the number of recent propagation delays kept to compute percentiles.
+const lagSamples = 1024
+
+// propagationLag keeps the most recent delays between the publication of an
+// event by another pod and its reception, so lag percentiles can be reported
+// in Stats. The zero value is ready to use.
+type propagationLag struct {
+	mu      sync.Mutex
+	samples [lagSamples]time.Duration
+	n       int // number of samples recorded, up to lagSamples
+	next    int // index of the next sample to overwrite
+}
+
+// record adds the delay between sent, in Unix nanoseconds, and received.
+// Delays made negative by clock skew between pods are recorded as zero.
+func (l *propagationLag) record(sent int64, received time.Time) {
+	lag := received.Sub(time.Unix(0, sent))
+	if lag < 0 {
+		lag = 0
+	}
+
+	l.mu.Lock()
+	l.samples[l.next] = lag
+	l.next = (l.next + 1) % lagSamples
+	if l.n < lagSamples {
+		l.n++
+	}
+	l.mu.Unlock()
+}
+
+// percentiles returns the median and 99th percentile of the recorded delays,
+// or zeros when none was recorded.
+func (l *propagationLag) percentiles() (p50, p99 time.Duration) {
+	l.mu.Lock()
+	samples := slices.Clone(l.samples[:l.n])
+	l.mu.Unlock()
+
+	if len(samples) == 0 {
+		return 0, 0
+	}
+	slices.Sort(samples)
+	return samples[(len(samples)-1)*50/100], samples[(len(samples)-1)*99/100]
+}
@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyOp is an operation whose latency is recorded in Stats.Latencies when
+// Options.EnableMetrics is set.
+type LatencyOp string
+
+const (
+	// LatencyLocalGet is a read of the local cache by Get, including the wait for its locks.
+	LatencyLocalGet LatencyOp = "local_get"
+
+	// LatencyRemoteGet is a read of Redis after a local miss.
+	LatencyRemoteGet LatencyOp = "remote_get"
+
+	// LatencyMarshal is the serialization of a value written by Set.
+	LatencyMarshal LatencyOp = "marshal"
+
+	// LatencyUnmarshal is the deserialization of a value read from Redis or
+	// received from another pod.
+	LatencyUnmarshal LatencyOp = "unmarshal"
+
+	// LatencyPublish is the publication of a synchronization event of a write.
+	LatencyPublish LatencyOp = "publish"
+
+	// LatencyApplyEvent is the application of an event received from another pod.
+	LatencyApplyEvent LatencyOp = "apply_event"
+)
+
+// latencyBounds are the upper bounds of the latency histogram buckets. A last
+// bucket counts the operations slower than the last bound.
+var latencyBounds = [...]time.Duration{
+	time.Microsecond, 2500 * time.Nanosecond, 5 * time.Microsecond,
+	10 * time.Microsecond, 25 * time.Microsecond, 50 * time.Microsecond,
+	100 * time.Microsecond, 250 * time.Microsecond, 500 * time.Microsecond,
+	time.Millisecond, 2500 * time.Microsecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second,
+}
+
+// LatencyHistogram counts the operations of a kind per latency bucket.
+type LatencyHistogram struct {
+	// Bounds are the upper bounds of the buckets, in increasing order.
+	Bounds []time.Duration `json:"bounds"`
+
+	// Counts holds the number of operations per bucket: Counts[i] took at most
+	// Bounds[i] and more than Bounds[i-1]. Its last entry counts the operations
+	// slower than the last bound.
+	Counts []int64 `json:"counts"`
+
+	// Count is the number of operations and Sum their total duration.
+	Count int64         `json:"count"`
+	Sum   time.Duration `json:"sum"`
+}
+
+// Quantile returns the upper bound of the bucket holding the q quantile of the
+// recorded latencies, e.g. 0.99 for the 99th percentile, or 0 when none was
+// recorded. Latencies beyond the last bound are reported as the last bound.
+func (h LatencyHistogram) Quantile(q float64) time.Duration {
+	if h.Count == 0 || len(h.Bounds) == 0 {
+		return 0
+	}
+	rank := max(int64(math.Ceil(q*float64(h.Count))), 1)
+	var seen int64
+	for i, count := range h.Counts {
+		seen += count
+		if seen >= rank && i < len(h.Bounds) {
+			return h.Bounds[i]
+		}
+	}
+	return h.Bounds[len(h.Bounds)-1]
+}
+
+// Sub returns the operations recorded in h since prev was taken.
+func (h LatencyHistogram) Sub(prev LatencyHistogram) LatencyHistogram {
+	delta := LatencyHistogram{
+		Bounds: h.Bounds,
+		Counts: make([]int64, len(h.Counts)),
+		Count:  h.Count - prev.Count,
+		Sum:    h.Sum - prev.Sum,
+	}
+	for i, count := range h.Counts {
+		delta.Counts[i] = count
+		if i < len(prev.Counts) {
+			delta.Counts[i] -= prev.Counts[i]
+		}
+	}
+	return delta
+}
+
+// latencyHistogram is a lock-free latency histogram over latencyBounds. The
+// zero value is ready to use.
+type latencyHistogram struct {
+	counts [len(latencyBounds) + 1]atomic.Int64 // one per bound, and one for slower operations
+	sum    atomic.Int64
+}
+
+// observe records the latency of an operation started at start, unless start
+// is zero because latencies are not recorded.
+func (h *latencyHistogram) observe(start time.Time) {
+	if start.IsZero() {
+		return
+	}
+	d := time.Since(start)
+	i := 0
+	for i < len(latencyBounds) && d > latencyBounds[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.sum.Add(int64(d))
+}
+
+// snapshot returns the current counts of h.
+func (h *latencyHistogram) snapshot() LatencyHistogram {
+	snap := LatencyHistogram{
+		Bounds: latencyBounds[:],
+		Counts: make([]int64, len(h.counts)),
+		Sum:    time.Duration(h.sum.Load()),
+	}
+	for i := range h.counts {
+		snap.Counts[i] = h.counts[i].Load()
+		snap.Count += snap.Counts[i]
+	}
+	return snap
+}
+
+// latencyHistograms holds a histogram per LatencyOp.
+type latencyHistograms struct {
+	localGet   latencyHistogram
+	remoteGet  latencyHistogram
+	marshal    latencyHistogram
+	unmarshal  latencyHistogram
+	publish    latencyHistogram
+	applyEvent latencyHistogram
+}
+
+// snapshot returns the histograms keyed by operation.
+func (lh *latencyHistograms) snapshot() map[LatencyOp]LatencyHistogram {
+	return map[LatencyOp]LatencyHistogram{
+		LatencyLocalGet:   lh.localGet.snapshot(),
+		LatencyRemoteGet:  lh.remoteGet.snapshot(),
+		LatencyMarshal:    lh.marshal.snapshot(),
+		LatencyUnmarshal:  lh.unmarshal.snapshot(),
+		LatencyPublish:    lh.publish.snapshot(),
+		LatencyApplyEvent: lh.applyEvent.snapshot(),
+	}
+}
+
+// subLatencies returns the operations recorded in current since prev was taken.
+func subLatencies(current, prev map[LatencyOp]LatencyHistogram) map[LatencyOp]LatencyHistogram {
+	if current == nil {
+		return nil
+	}
+	delta := make(map[LatencyOp]LatencyHistogram, len(current))
+	for op, h := range current {
+		delta[op] = h.Sub(prev[op])
+	}
+	return delta
+}
+
+// startTimer returns the start time of an operation whose latency is recorded,
+// or the zero time when Options.EnableMetrics is not set.
+func (sc *SyncedCache) startTimer() time.Time {
+	if !sc.options.EnableMetrics {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// applyTimed applies a received event, recording its latency.
+func (sc *SyncedCache) applyTimed(event InvalidationEvent) {
+	start := sc.startTimer()
+	sc.applyEvent(event)
+	sc.latencies.applyEvent.observe(start)
+}
+
+// publish publishes a synchronization event of a write, recording its latency.
+func (sc *SyncedCache) publish(ctx context.Context, event InvalidationEvent) error {
+	start := sc.startTimer()
+	err := sc.synchronizer.Publish(ctx, event)
+	sc.latencies.publish.observe(start)
+	return err
+}
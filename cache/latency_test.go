@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	var h latencyHistogram
+	h.observe(time.Time{})
+	if snap := h.snapshot(); snap.Count != 0 {
+		t.Fatalf("Expected a zero start not to be recorded, got %d", snap.Count)
+	}
+
+	now := time.Now()
+	for range 98 {
+		h.observe(now)
+	}
+	h.observe(now.Add(-30 * time.Millisecond))
+	h.observe(now.Add(-2 * time.Second))
+
+	snap := h.snapshot()
+	if snap.Count != 100 || len(snap.Counts) != len(snap.Bounds)+1 {
+		t.Fatalf("Expected 100 operations over %d buckets, got %+v", len(snap.Bounds)+1, snap)
+	}
+	if snap.Counts[len(snap.Counts)-1] != 1 {
+		t.Errorf("Expected the slowest operation in the last bucket, got %v", snap.Counts)
+	}
+	if p99 := snap.Quantile(0.99); p99 != 50*time.Millisecond {
+		t.Errorf("Expected p99 in the 50ms bucket, got %v", p99)
+	}
+	if p100 := snap.Quantile(1); p100 != time.Second {
+		t.Errorf("Expected the slowest operation reported as the last bound, got %v", p100)
+	}
+
+	h.observe(now)
+	delta := h.snapshot().Sub(snap)
+	if delta.Count != 1 || delta.Counts[len(delta.Counts)-1] != 0 {
+		t.Errorf("Expected a single new operation, got %+v", delta)
+	}
+}
+
+func TestSyncedCacheLatencies(t *testing.T) {
+	ctx := context.Background()
+	c := newMockedCache(t, Options{PodID: "pod-1", WritePolicy: WritePolicyWriteThrough})
+	if c.Stats().Latencies != nil {
+		t.Fatal("Expected no latencies without EnableMetrics")
+	}
+
+	c.options.EnableMetrics = true
+	c.synchronizer = &publishingSynchronizer{}
+	c.store = &valueStore{values: map[string][]byte{"remote": []byte(`"r"`)}}
+	prev := c.Stats()
+
+	if err := c.Set(ctx, "key", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	c.Get(ctx, "key")
+	c.Get(ctx, "remote")
+	c.handleInvalidation(InvalidationEvent{Key: "other", Sender: "pod-2", Action: ActionSet, Value: []byte(`"v"`)})
+
+	latencies := c.StatsSince(prev).Latencies
+	for op, want := range map[LatencyOp]int64{
+		LatencyLocalGet:   2,
+		LatencyRemoteGet:  1,
+		LatencyMarshal:    1,
+		LatencyUnmarshal:  2,
+		LatencyPublish:    1,
+		LatencyApplyEvent: 1,
+	} {
+		if got := latencies[op].Count; got != want {
+			t.Errorf("Expected %d %s operations, got %d", want, op, got)
+		}
+	}
+}
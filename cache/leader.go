@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultLeaderLeaseTTL is the leader lease used when Options.LeaderLeaseTTL is 0.
+const defaultLeaderLeaseTTL = 15 * time.Second
+
+// leaderLockKey is the lock held by the leader pod. Lock refuses reserved keys,
+// so no lock taken by the application blocks or steals the lease.
+const leaderLockKey = reservedLockPrefix + "leader-election"
+
+// leaderCallbacks holds the functions registered with OnLeadershipChange.
+type leaderCallbacks struct {
+	mu  sync.RWMutex
+	fns []func(leader bool)
+}
+
+// add registers fn.
+func (lc *leaderCallbacks) add(fn func(leader bool)) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.fns = append(lc.fns, fn)
+}
+
+// emit calls every registered function with leader.
+func (lc *leaderCallbacks) emit(leader bool) {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	for _, fn := range lc.fns {
+		fn(leader)
+	}
+}
+
+// IsLeader reports whether this pod currently holds the leader lease, see
+// Options.LeaderElection. It is always false when leader election is disabled.
+func (sc *SyncedCache) IsLeader() bool {
+	return atomic.LoadInt32(&sc.leader) != 0
+}
+
+// OnLeadershipChange registers fn to be called with true when this pod becomes
+// the leader and with false when it loses or gives up the lease. fn is called on
+// the election goroutine and must not block.
+func (sc *SyncedCache) OnLeadershipChange(fn func(leader bool)) {
+	sc.leaderFns.add(fn)
+}
+
+// startLeaderElection starts competing for the leader lease when
+// Options.LeaderElection is set. The lease is a lock in the store renewed every
+// third of LeaderLeaseTTL, and released on Close so another pod takes over
+// without waiting for it to expire.
+func (sc *SyncedCache) startLeaderElection() {
+	if !sc.options.LeaderElection {
+		return
+	}
+	locking, ok := sc.store.(LockingStore)
+	leasing, ok2 := sc.store.(LeasingStore)
+	if !ok || !ok2 {
+		sc.logger.Error("Leader: store does not support leader election")
+		return
+	}
+	token, err := newLockToken()
+	if err != nil {
+		return
+	}
+	ttl := sc.options.LeaderLeaseTTL
+
+	sc.goBackground(func(ctx context.Context) {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			held, err := sc.campaign(ctx, locking, leasing, token, ttl)
+			if err != nil && ctx.Err() == nil {
+				sc.reportError(OpLeader, leaderLockKey, ErrRemoteStore, err)
+				if sc.logging(DebugOps) {
+					sc.logger.Error("Leader: failed to renew the lease", "error", err)
+				}
+			}
+			sc.setLeader(held)
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				if sc.IsLeader() {
+					unlockCtx, cancel := context.WithTimeout(context.Background(), sc.options.ContextTimeout)
+					_, _ = locking.Unlock(unlockCtx, leaderLockKey, token)
+					cancel()
+					sc.setLeader(false)
+				}
+				return
+			}
+		}
+	})
+}
+
+// campaign extends the leader lease when this pod still holds it and tries to
+// acquire it otherwise. It reports whether this pod holds the lease; on error
+// the pod steps down, as it cannot tell whether another pod took over.
+func (sc *SyncedCache) campaign(ctx context.Context, locking LockingStore, leasing LeasingStore, token string, ttl time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, sc.options.ContextTimeout)
+	defer cancel()
+
+	held, err := leasing.ExtendLock(ctx, leaderLockKey, token, ttl)
+	if err != nil || held {
+		return held && err == nil, err
+	}
+	held, err = locking.TryLock(ctx, leaderLockKey, token, ttl)
+	return held && err == nil, err
+}
+
+// setLeader records whether this pod is the leader and notifies the
+// OnLeadershipChange callbacks when it changed.
+func (sc *SyncedCache) setLeader(leader bool) {
+	var value int32
+	if leader {
+		value = 1
+	}
+	if atomic.SwapInt32(&sc.leader, value) == value {
+		return
+	}
+	if sc.logging(DebugOps) {
+		sc.logger.Info("Leader: leadership changed", "leader", leader)
+	}
+	sc.leaderFns.emit(leader)
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitFor fails the test unless cond becomes true within a second.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSyncedCacheLeaderElection(t *testing.T) {
+	store := &lockingStore{locks: make(map[string]string)}
+	opts := Options{LeaderElection: true, LeaderLeaseTTL: 30 * time.Millisecond}
+
+	first := newMockedCache(t, opts)
+	first.store = store
+	changes := make(chan bool, 4)
+	first.OnLeadershipChange(func(leader bool) { changes <- leader })
+	first.startLeaderElection()
+	waitFor(t, "the first pod to lead", first.IsLeader)
+	if leader := <-changes; !leader {
+		t.Fatal("Expected a notification of the leadership")
+	}
+
+	second := newMockedCache(t, opts)
+	second.store = store
+	defer second.Close()
+	second.startLeaderElection()
+	time.Sleep(3 * opts.LeaderLeaseTTL)
+	if second.IsLeader() || !first.IsLeader() {
+		t.Fatalf("Expected the first pod to keep the lease, got first=%v second=%v", first.IsLeader(), second.IsLeader())
+	}
+
+	first.Close()
+	if first.IsLeader() {
+		t.Error("Expected the lease to be given up on Close")
+	}
+	if leader := <-changes; leader {
+		t.Error("Expected a notification of the lost leadership")
+	}
+	waitFor(t, "the second pod to take over", second.IsLeader)
+}
+
+func TestSyncedCacheLeaderLeaseIgnoresApplicationLocks(t *testing.T) {
+	store := &lockingStore{locks: make(map[string]string)}
+	c := newMockedCache(t, Options{LeaderElection: true, LeaderLeaseTTL: 30 * time.Millisecond})
+	defer c.Close()
+	c.store = store
+
+	lock, err := c.Lock(context.Background(), "leader-election", time.Minute)
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	defer lock.Unlock(context.Background())
+	if _, err := c.Lock(context.Background(), leaderLockKey, time.Minute); !errors.Is(err, ErrReservedLockKey) {
+		t.Fatalf("Expected ErrReservedLockKey for the lease, got %v", err)
+	}
+
+	c.startLeaderElection()
+	waitFor(t, "the pod to lead despite the application lock", c.IsLeader)
+}
+
+func TestSyncedCacheLeaderElectionDisabled(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	defer c.Close()
+	c.store = &lockingStore{locks: make(map[string]string)}
+
+	c.startLeaderElection()
+	if c.IsLeader() {
+		t.Fatal("Expected no leader without LeaderElection")
+	}
+}
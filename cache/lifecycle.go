@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// lifecycleWatchBuffer is the number of events buffered per Watch channel.
+// Events are dropped for watchers that fall further behind.
+const lifecycleWatchBuffer = 64
+
+// LifecycleType identifies an operational event in the life of a pod or namespace.
+type LifecycleType string
+
+const (
+	// LifecyclePodJoined is announced by a pod once New has subscribed to the sync channel.
+	LifecyclePodJoined LifecycleType = "pod_joined"
+	// LifecyclePodLeaving is announced by a pod when Close is called.
+	LifecyclePodLeaving LifecycleType = "pod_leaving"
+	// LifecycleCleared is announced after Clear removed the keys of a namespace,
+	// or the whole database when Details["full_flush"] is "true".
+	LifecycleCleared LifecycleType = "cleared"
+	// LifecycleConfigReloaded is announced by applications via Announce after
+	// reloading their configuration.
+	LifecycleConfigReloaded LifecycleType = "config_reloaded"
+)
+
+// LifecycleEvent is an operational event published on the sync channel so that
+// dashboards and operators can correlate cache behavior with fleet events.
+type LifecycleEvent struct {
+	Type      LifecycleType     `json:"type"`
+	PodID     string            `json:"pod_id"`
+	Namespace string            `json:"namespace,omitempty"`
+	Time      time.Time         `json:"time"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// lifecycleWatchers holds the channels returned by Watch. The zero value is ready to use.
+type lifecycleWatchers struct {
+	mu       sync.Mutex
+	nextID   int
+	channels map[int]chan LifecycleEvent
+}
+
+// add registers a watcher channel and returns its id.
+func (lw *lifecycleWatchers) add(ch chan LifecycleEvent) int {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if lw.channels == nil {
+		lw.channels = make(map[int]chan LifecycleEvent)
+	}
+	lw.nextID++
+	lw.channels[lw.nextID] = ch
+	return lw.nextID
+}
+
+// remove unregisters a watcher and closes its channel.
+func (lw *lifecycleWatchers) remove(id int) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if ch, ok := lw.channels[id]; ok {
+		delete(lw.channels, id)
+		close(ch)
+	}
+}
+
+// deliver sends event to every watcher without blocking.
+func (lw *lifecycleWatchers) deliver(event LifecycleEvent) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	for _, ch := range lw.channels {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Watch returns a channel receiving the lifecycle events of every pod sharing the
+// sync channel, including this one. The channel is closed when ctx is done or the
+// cache is closed. Events are dropped if the receiver falls behind.
+func (sc *SyncedCache) Watch(ctx context.Context) <-chan LifecycleEvent {
+	ch := make(chan LifecycleEvent, lifecycleWatchBuffer)
+	id := sc.watchers.add(ch)
+
+	started := sc.goBackground(func(bgCtx context.Context) {
+		select {
+		case <-ctx.Done():
+		case <-bgCtx.Done():
+		}
+		sc.watchers.remove(id)
+	})
+	if !started {
+		sc.watchers.remove(id)
+	}
+	return ch
+}
+
+// Announce publishes a lifecycle event from this pod, e.g. LifecycleConfigReloaded
+// after the application reloaded its configuration.
+func (sc *SyncedCache) Announce(ctx context.Context, eventType LifecycleType, details map[string]string) error {
+	if atomic.LoadInt32(&sc.closed) != 0 {
+		return ErrCacheClosed
+	}
+	return sc.announce(ctx, eventType, details)
+}
+
+// announce publishes a lifecycle event and delivers it to local watchers, which
+// do not receive this pod's events from the sync channel.
+func (sc *SyncedCache) announce(ctx context.Context, eventType LifecycleType, details map[string]string) error {
+	event := LifecycleEvent{
+		Type:      eventType,
+		PodID:     sc.options.PodID,
+		Namespace: sc.options.Namespace,
+		Time:      time.Now(),
+		Details:   details,
+	}
+	sc.watchers.deliver(event)
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	err = sc.synchronizer.Publish(ctx, InvalidationEvent{
+		Key:    "*",
+		Sender: sc.options.PodID,
+		Action: ActionLifecycle,
+		Value:  data,
+	})
+	if err != nil {
+		sc.reportError(OpLifecycle, "", ErrPublish, err)
+		if sc.logging(DebugSync) {
+			sc.logger.Warn("Lifecycle: failed to publish event", "type", eventType, "error", err)
+		}
+		return err
+	}
+
+	if sc.debugging(DebugSync) {
+		sc.logger.Debug("Lifecycle: published event", "type", eventType)
+	}
+	return nil
+}
+
+// announceBackground publishes a lifecycle event emitted by the cache itself,
+// bounded by ContextTimeout. Failures are only reported via OnError.
+func (sc *SyncedCache) announceBackground(eventType LifecycleType, details map[string]string) {
+	ctx, cancel := context.WithTimeout(context.Background(), sc.options.ContextTimeout)
+	defer cancel()
+	_ = sc.announce(ctx, eventType, details)
+}
+
+// applyLifecycle delivers a lifecycle event received from another pod to local watchers.
+func (sc *SyncedCache) applyLifecycle(event InvalidationEvent) {
+	var lifecycle LifecycleEvent
+	if err := json.Unmarshal(event.Value, &lifecycle); err != nil {
+		if sc.logging(DebugSerialization) {
+			sc.logger.Error("Sync: failed to decode lifecycle event", "sender", event.Sender, "error", err)
+		}
+		return
+	}
+	if sc.logging(DebugSync) {
+		sc.logger.Info("Sync: received lifecycle event", "type", lifecycle.Type, "sender", lifecycle.PodID)
+	}
+	if lifecycle.Type == LifecyclePodLeaving {
+		sc.peers.remove(lifecycle.PodID)
+	}
+	sc.watchers.deliver(lifecycle)
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+// publishingSynchronizer is a synchronizer recording published events.
+type publishingSynchronizer struct {
+	errorSynchronizer
+	mu     sync.Mutex
+	events []InvalidationEvent
+}
+
+func (ps *publishingSynchronizer) Publish(ctx context.Context, event InvalidationEvent) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.events = append(ps.events, event)
+	return nil
+}
+
+func (ps *publishingSynchronizer) lifecycleTypes() []LifecycleType {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	var types []LifecycleType
+	for _, event := range ps.events {
+		if event.Action != ActionLifecycle {
+			continue
+		}
+		var lifecycle LifecycleEvent
+		if err := json.Unmarshal(event.Value, &lifecycle); err == nil {
+			types = append(types, lifecycle.Type)
+		}
+	}
+	return types
+}
+
+// receive returns the next event of ch or fails after a second.
+func receive(t *testing.T, ch <-chan LifecycleEvent) LifecycleEvent {
+	t.Helper()
+	select {
+	case event := <-ch:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("Expected a lifecycle event")
+		return LifecycleEvent{}
+	}
+}
+
+func TestSyncedCacheAnnounceAndWatch(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-a", Namespace: "orders:"})
+	synchronizer := &publishingSynchronizer{}
+	c.synchronizer = synchronizer
+
+	ctx := context.Background()
+	events := c.Watch(ctx)
+
+	if err := c.Announce(ctx, LifecycleConfigReloaded, map[string]string{"version": "42"}); err != nil {
+		t.Fatalf("Announce failed: %v", err)
+	}
+	event := receive(t, events)
+	if event.Type != LifecycleConfigReloaded || event.PodID != "pod-a" || event.Namespace != "orders:" || event.Details["version"] != "42" {
+		t.Fatalf("Unexpected event: %+v", event)
+	}
+
+	if err := c.Clear(ctx); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if event := receive(t, events); event.Type != LifecycleCleared {
+		t.Fatalf("Expected cleared event, got %+v", event)
+	}
+
+	c.Close()
+	if event := receive(t, events); event.Type != LifecyclePodLeaving {
+		t.Fatalf("Expected pod leaving event, got %+v", event)
+	}
+	if _, ok := <-events; ok {
+		t.Fatal("Expected Watch channel to be closed on Close")
+	}
+
+	types := synchronizer.lifecycleTypes()
+	want := []LifecycleType{LifecycleConfigReloaded, LifecycleCleared, LifecyclePodLeaving}
+	if len(types) != len(want) {
+		t.Fatalf("Expected published events %v, got %v", want, types)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("Expected published events %v, got %v", want, types)
+		}
+	}
+}
+
+func TestSyncedCacheWatchReceivesRemoteEvents(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-a"})
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := c.Watch(ctx)
+
+	data, _ := json.Marshal(LifecycleEvent{Type: LifecyclePodJoined, PodID: "pod-b"})
+	c.handleInvalidation(InvalidationEvent{Key: "*", Sender: "pod-b", Action: ActionLifecycle, Value: data})
+
+	if event := receive(t, events); event.Type != LifecyclePodJoined || event.PodID != "pod-b" {
+		t.Fatalf("Unexpected event: %+v", event)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("Expected no further events")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Watch channel to be closed when ctx is done")
+	}
+}
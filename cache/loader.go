@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// LoaderFunc loads the value for a key from its source of truth on a cache miss.
+// Returning a nil value reports that the key does not exist at the source.
+type LoaderFunc func(ctx context.Context, key string) (any, error)
+
+// patternLoader is a loader registered for a key pattern.
+type patternLoader struct {
+	pattern string
+	load    LoaderFunc
+}
+
+// RegisterLoader registers a read-through loader for keys matching pattern,
+// where '*' matches any sequence of characters (e.g. "user:*").
+// When Get misses both the local and remote caches for a matching key, the value
+// is loaded, stored in both levels and propagated to other pods, so call sites
+// do not need a separate get-or-load step. When several patterns match a key the
+// longest one wins. Registering a pattern again replaces its loader.
+func (sc *SyncedCache) RegisterLoader(pattern string, loader LoaderFunc) {
+	sc.loadersMutex.Lock()
+	defer sc.loadersMutex.Unlock()
+
+	for i, pl := range sc.loaders {
+		if pl.pattern == pattern {
+			sc.loaders[i].load = loader
+			return
+		}
+	}
+
+	sc.loaders = append(sc.loaders, patternLoader{pattern: pattern, load: loader})
+	sort.SliceStable(sc.loaders, func(i, j int) bool {
+		return len(sc.loaders[i].pattern) > len(sc.loaders[j].pattern)
+	})
+}
+
+// loaderFor returns the loader registered for the most specific pattern matching key.
+func (sc *SyncedCache) loaderFor(key string) LoaderFunc {
+	sc.loadersMutex.RLock()
+	defer sc.loadersMutex.RUnlock()
+
+	for _, pl := range sc.loaders {
+		if MatchPattern(pl.pattern, key) {
+			return pl.load
+		}
+	}
+	return nil
+}
+
+// load loads a missing key with its registered loader, then stores and propagates it.
+// It returns nil when no loader matches, the loader fails or the key does not exist.
+func (sc *SyncedCache) load(ctx context.Context, key string) *getResult {
+	loader := sc.loaderFor(key)
+	if loader == nil {
+		return nil
+	}
+
+	value, err := loader(ctx, key)
+	if err != nil {
+		sc.reportError(OpLoad, key, nil, err)
+		if sc.logging(DebugOps) {
+			sc.logger.Error("Get: loader failed", "key", key, "error", err)
+		}
+		return nil
+	}
+	if value == nil {
+		if sc.debugging(DebugOps) {
+			sc.logger.Debug("Get: not found by loader", "key", key)
+		}
+		return nil
+	}
+
+	data, err := sc.encode(key, value)
+	if err != nil {
+		sc.reportError(OpLoad, key, ErrSerialization, err)
+		if sc.logging(DebugSerialization) {
+			sc.logger.Error("Get: serialization of loaded value failed", "key", key, "error", err)
+		}
+		return nil
+	}
+	if sc.checkValueSize(key, data) != nil {
+		return &getResult{value: sc.localValue(key, value, data), info: HitInfo{Level: LevelLoader, Source: SourceLoader, Size: len(data)}}
+	}
+
+	// The loaded value is returned even if caching it fails; errors are reported via OnError
+	_ = sc.storeAndPublish(ctx, key, value, data, SourceLoader, sc.setConfig(key, nil))
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("Get: loaded value from source", "key", key)
+	}
+
+	return &getResult{value: sc.localValue(key, value, data), info: HitInfo{Level: LevelLoader, Source: SourceLoader, Size: len(data)}}
+}
+
+// MatchPattern reports whether key matches a RegisterLoader pattern, where '*' matches any
+// sequence of characters and every other character matches itself.
+func MatchPattern(pattern, key string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == key
+	}
+
+	if !strings.HasPrefix(key, parts[0]) {
+		return false
+	}
+	key = key[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(key, part)
+		if i < 0 {
+			return false
+		}
+		key = key[i+len(part):]
+	}
+	return len(key) >= len(last) && strings.HasSuffix(key, last)
+}
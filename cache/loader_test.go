@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"user:*", "user:1", true},
+		{"user:*", "user:", true},
+		{"user:*", "order:1", false},
+		{"user:1", "user:1", true},
+		{"user:1", "user:10", false},
+		{"*:profile", "user:1:profile", true},
+		{"*:profile", "user:1:settings", false},
+		{"user:*:profile", "user:1:profile", true},
+		{"user:*:profile", "user:1:settings", false},
+		{"a*a", "a", false},
+		{"*", "anything", true},
+	}
+
+	for _, tt := range tests {
+		if got := MatchPattern(tt.pattern, tt.key); got != tt.want {
+			t.Errorf("MatchPattern(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestSyncedCacheRegisterLoaderMostSpecificWins(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	defer c.Close()
+
+	c.RegisterLoader("user:*", func(ctx context.Context, key string) (any, error) {
+		return "generic", nil
+	})
+	c.RegisterLoader("user:admin:*", func(ctx context.Context, key string) (any, error) {
+		return "admin", nil
+	})
+
+	if value, _ := c.Get(context.Background(), "user:admin:1"); value != "admin" {
+		t.Fatalf("Expected most specific loader to win, got %v", value)
+	}
+	if value, _ := c.Get(context.Background(), "user:1"); value != "generic" {
+		t.Fatalf("Expected generic loader, got %v", value)
+	}
+}
+
+func TestSyncedCacheGetLoadsOnMiss(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	defer c.Close()
+
+	calls := 0
+	c.RegisterLoader("user:*", func(ctx context.Context, key string) (any, error) {
+		calls++
+		return "loaded-" + key, nil
+	})
+
+	value, found, info := c.GetWithInfo(context.Background(), "user:1")
+	if !found || value != "loaded-user:1" {
+		t.Fatalf("Expected loaded value, got %v (found=%v)", value, found)
+	}
+	if info.Level != LevelLoader || info.Source != SourceLoader {
+		t.Fatalf("Expected loader hit, got %s/%s", info.Level, info.Source)
+	}
+
+	_, _, info = c.GetWithInfo(context.Background(), "user:1")
+	if info.Level != LevelLocal || info.Source != SourceLoader {
+		t.Fatalf("Expected local hit of loaded value, got %s/%s", info.Level, info.Source)
+	}
+	if calls != 1 {
+		t.Fatalf("Expected loader to be called once, got %d", calls)
+	}
+
+	if _, found := c.Get(context.Background(), "order:1"); found {
+		t.Fatal("Keys without a matching loader should miss")
+	}
+}
+
+func TestSyncedCacheLoaderNotFoundAndError(t *testing.T) {
+	var reported error
+	c := newMockedCache(t, Options{OnError: func(err error) { reported = err }})
+	defer c.Close()
+
+	c.RegisterLoader("missing:*", func(ctx context.Context, key string) (any, error) {
+		return nil, nil
+	})
+	c.RegisterLoader("failing:*", func(ctx context.Context, key string) (any, error) {
+		return nil, errors.New("source unavailable")
+	})
+
+	if _, found := c.Get(context.Background(), "missing:1"); found {
+		t.Fatal("A nil loaded value should be reported as a miss")
+	}
+	if _, found := c.Get(context.Background(), "failing:1"); found {
+		t.Fatal("A failing loader should be reported as a miss")
+	}
+	if reported == nil || reported.Error() != "source unavailable" {
+		t.Fatalf("Expected loader error to be reported via OnError, got %v", reported)
+	}
+}
@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+
+	arc "github.com/hashicorp/golang-lru/arc/v2"
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// ARCCacheFactory creates ARC cache instances.
+type ARCCacheFactory struct {
+	maxSize int
+}
+
+// NewARCCacheFactory creates a new ARC cache factory.
+func NewARCCacheFactory(maxSize int) LocalCacheFactory {
+	return &ARCCacheFactory{maxSize: maxSize}
+}
+
+// Create creates a new ARC cache instance.
+func (acf *ARCCacheFactory) Create() (LocalCache, error) {
+	return NewARCCache(acf.maxSize)
+}
+
+// TwoQueueCacheFactory creates 2Q cache instances.
+type TwoQueueCacheFactory struct {
+	maxSize int
+}
+
+// New2QCacheFactory creates a new 2Q cache factory.
+func New2QCacheFactory(maxSize int) LocalCacheFactory {
+	return &TwoQueueCacheFactory{maxSize: maxSize}
+}
+
+// Create creates a new 2Q cache instance.
+func (tcf *TwoQueueCacheFactory) Create() (LocalCache, error) {
+	return New2QCache(tcf.maxSize)
+}
+
+// ARCCache is a local cache using golang-lru's Adaptive Replacement Cache, which
+// balances recency and frequency so that one-off scans do not flush frequently
+// used entries. It tracks twice maxSize keys, half of them without values.
+type ARCCache struct {
+	*policyCache
+}
+
+// NewARCCache creates a new ARC-based local cache holding at most maxSize entries.
+func NewARCCache(maxSize int) (*ARCCache, error) {
+	cache, err := arc.NewARC[string, any](maxSize)
+	if err != nil {
+		return nil, err
+	}
+	return &ARCCache{policyCache: &policyCache{cache: cache, maxSize: int64(maxSize)}}, nil
+}
+
+// TwoQueueCache is a local cache using golang-lru's 2Q algorithm, which keeps
+// entries accessed once apart from frequently accessed ones, so scans only evict
+// other recently added entries. It is cheaper than ARC.
+type TwoQueueCache struct {
+	*policyCache
+}
+
+// New2QCache creates a new 2Q-based local cache holding at most maxSize entries.
+func New2QCache(maxSize int) (*TwoQueueCache, error) {
+	cache, err := lru.New2Q[string, any](maxSize)
+	if err != nil {
+		return nil, err
+	}
+	return &TwoQueueCache{policyCache: &policyCache{cache: cache, maxSize: int64(maxSize)}}, nil
+}
+
+// replacementCache is the API shared by the golang-lru ARC and 2Q caches.
+type replacementCache interface {
+	Get(key string) (any, bool)
+	Add(key string, value any)
+	Peek(key string) (any, bool)
+	Remove(key string)
+	Purge()
+	Keys() []string
+	Len() int
+}
+
+// policyCache implements LocalCache on top of a replacementCache.
+type policyCache struct {
+	cache   replacementCache
+	hits    int64
+	misses  int64
+	maxSize int64
+}
+
+// Get retrieves a value from the local cache.
+func (pc *policyCache) Get(key string) (any, bool) {
+	value, found := pc.cache.Get(key)
+	if found {
+		if value, found = unwrapExpiring(value); !found {
+			pc.cache.Remove(key)
+		}
+	}
+	if found {
+		atomic.AddInt64(&pc.hits, 1)
+	} else {
+		atomic.AddInt64(&pc.misses, 1)
+	}
+	return value, found
+}
+
+// Set stores a value in the local cache.
+func (pc *policyCache) Set(key string, value any, _ int64) bool {
+	pc.cache.Add(key, value)
+	return true
+}
+
+// SetWithTTL stores a value that expires after ttl. Expired values are removed
+// when they are next read or iterated, or when evicted by the replacement policy.
+func (pc *policyCache) SetWithTTL(key string, value any, _ int64, ttl time.Duration) bool {
+	pc.cache.Add(key, &expiringValue{value: value, expiresAt: time.Now().Add(ttl)})
+	return true
+}
+
+// Delete removes a value from the local cache.
+func (pc *policyCache) Delete(key string) {
+	pc.cache.Remove(key)
+}
+
+// Clear removes all values from the local cache.
+func (pc *policyCache) Clear() {
+	pc.cache.Purge()
+}
+
+// Close closes the local cache.
+func (pc *policyCache) Close() {
+	pc.cache.Purge()
+}
+
+// Keys returns the keys currently held.
+func (pc *policyCache) Keys() []string {
+	keys := make([]string, 0, pc.cache.Len())
+	pc.Iterate(func(key string, _ any) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Iterate calls fn for each entry without updating recency or hit counts.
+// It stops when fn returns false.
+func (pc *policyCache) Iterate(fn func(key string, value any) bool) {
+	for _, key := range pc.cache.Keys() {
+		value, ok := pc.cache.Peek(key)
+		if ok {
+			if value, ok = unwrapExpiring(value); !ok {
+				pc.cache.Remove(key)
+			}
+		}
+		if !ok {
+			continue
+		}
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+// Metrics returns cache metrics.
+func (pc *policyCache) Metrics() LocalCacheMetrics {
+	return LocalCacheMetrics{
+		Hits:   atomic.LoadInt64(&pc.hits),
+		Misses: atomic.LoadInt64(&pc.misses),
+		Size:   pc.maxSize,
+		Items:  int64(pc.cache.Len()),
+	}
+}
@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestReplacementCaches(t *testing.T) {
+	factories := map[string]LocalCacheFactory{
+		"arc": NewARCCacheFactory(10),
+		"2q":  New2QCacheFactory(10),
+	}
+
+	for name, factory := range factories {
+		t.Run(name, func(t *testing.T) {
+			local, err := factory.Create()
+			if err != nil {
+				t.Fatalf("Failed to create cache: %v", err)
+			}
+			defer local.Close()
+
+			local.Set("key1", "value1", 1)
+			if value, found := local.Get("key1"); !found || value != "value1" {
+				t.Fatalf("Expected value1, got %v (found=%v)", value, found)
+			}
+			local.Delete("key1")
+			if _, found := local.Get("key1"); found {
+				t.Fatal("Expected a miss after Delete")
+			}
+
+			local.(ExpiringLocalCache).SetWithTTL("short", "value", 1, 20*time.Millisecond)
+			time.Sleep(30 * time.Millisecond)
+			if _, found := local.Get("short"); found {
+				t.Fatal("Expected the value to expire")
+			}
+
+			metrics := local.Metrics()
+			if metrics.Hits != 1 || metrics.Misses != 2 || metrics.Size != 10 || metrics.Items != 0 {
+				t.Fatalf("Unexpected metrics: %+v", metrics)
+			}
+
+			local.Set("key2", "value2", 1)
+			local.Clear()
+			if keys := local.(IterableLocalCache).Keys(); len(keys) != 0 {
+				t.Fatalf("Expected no keys after Clear, got %v", keys)
+			}
+		})
+	}
+}
+
+func TestReplacementCachesResistScans(t *testing.T) {
+	factories := map[string]LocalCacheFactory{
+		"arc": NewARCCacheFactory(10),
+		"2q":  New2QCacheFactory(10),
+	}
+
+	for name, factory := range factories {
+		t.Run(name, func(t *testing.T) {
+			local, err := factory.Create()
+			if err != nil {
+				t.Fatalf("Failed to create cache: %v", err)
+			}
+			defer local.Close()
+
+			local.Set("hot", "value", 1)
+			local.Get("hot")
+			for i := 0; i < 50; i++ {
+				local.Set(fmt.Sprintf("scan:%d", i), i, 1)
+			}
+			if _, found := local.Get("hot"); !found {
+				t.Error("Expected a frequently used key to survive a scan")
+			}
+		})
+	}
+}
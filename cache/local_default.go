@@ -0,0 +1,8 @@
+//go:build !noristretto
+
+package cache
+
+// defaultLocalCacheFactory returns the factory used when Options.LocalCacheFactory is nil.
+func defaultLocalCacheFactory(config LocalCacheConfig) LocalCacheFactory {
+	return NewLFUCacheFactory(config)
+}
@@ -0,0 +1,13 @@
+//go:build noristretto
+
+package cache
+
+// mapCacheShards is the number of MapCache shards of the default local cache.
+const mapCacheShards = 16
+
+// defaultLocalCacheFactory returns the factory used when Options.LocalCacheFactory
+// is nil. Builds using the noristretto tag leave out Ristretto and its
+// dependencies, defaulting to a sharded MapCache bounded by config.MaxCost.
+func defaultLocalCacheFactory(config LocalCacheConfig) LocalCacheFactory {
+	return NewShardedLocalCacheFactory(NewMapCacheFactory(max(config.MaxCost/mapCacheShards, 1)), mapCacheShards)
+}
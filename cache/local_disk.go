@@ -0,0 +1,292 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DiskCacheFactory creates disk cache instances.
+type DiskCacheFactory struct {
+	dir        string
+	maxBytes   int64
+	marshaller Marshaller
+}
+
+// NewDiskCacheFactory creates a new disk cache factory. Each cache gets its own
+// directory under dir, holding at most maxBytes of values encoded with marshaller
+// (JSON when nil).
+func NewDiskCacheFactory(dir string, maxBytes int64, marshaller Marshaller) LocalCacheFactory {
+	return &DiskCacheFactory{dir: dir, maxBytes: maxBytes, marshaller: marshaller}
+}
+
+// Create creates a new disk cache instance.
+func (dcf *DiskCacheFactory) Create() (LocalCache, error) {
+	return NewDiskCache(dcf.dir, dcf.maxBytes, dcf.marshaller)
+}
+
+// DiskCache is a local cache keeping each value in a file, for values too big to
+// hold in memory. Values are encoded with a Marshaller, so they are read back the
+// way values fetched from Redis are, e.g. structs come back as map[string]any.
+// When more than maxBytes are stored, the least recently used values are evicted.
+//
+// The cache does not survive restarts: its directory is created empty by
+// NewDiskCache and removed by Close, since invalidations may be missed meanwhile.
+type DiskCache struct {
+	mu          sync.Mutex
+	dir         string
+	marshaller  Marshaller
+	entries     map[string]*list.Element
+	order       *list.List // most recently used first
+	bytes       int64
+	maxBytes    int64
+	hits        int64
+	misses      int64
+	evictions   int64
+	costAdded   int64
+	costEvicted int64
+	onEvict     func(key string)
+}
+
+// diskEntry is a value held in a file by a DiskCache.
+type diskEntry struct {
+	key       string
+	path      string
+	size      int64
+	expiresAt time.Time
+}
+
+// NewDiskCache creates a new disk-backed local cache in a new directory under dir,
+// holding at most maxBytes of values encoded with marshaller (JSON when nil).
+func NewDiskCache(dir string, maxBytes int64, marshaller Marshaller) (*DiskCache, error) {
+	if maxBytes <= 0 {
+		return nil, ErrInvalidConfig
+	}
+	if marshaller == nil {
+		marshaller = NewJSONMarshaller()
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	own, err := os.MkdirTemp(dir, "cache-")
+	if err != nil {
+		return nil, err
+	}
+
+	return &DiskCache{
+		dir:        own,
+		marshaller: marshaller,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		maxBytes:   maxBytes,
+	}, nil
+}
+
+// Get retrieves a value from the local cache, reading and decoding its file.
+func (dc *DiskCache) Get(key string) (any, bool) {
+	value, found := dc.read(key, true)
+	if found {
+		atomic.AddInt64(&dc.hits, 1)
+	} else {
+		atomic.AddInt64(&dc.misses, 1)
+	}
+	return value, found
+}
+
+// read returns the decoded value of key, marking it as recently used when touch is true.
+func (dc *DiskCache) read(key string, touch bool) (any, bool) {
+	dc.mu.Lock()
+	elem, found := dc.entries[key]
+	if !found {
+		dc.mu.Unlock()
+		return nil, false
+	}
+	entry := elem.Value.(*diskEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		dc.remove(elem, true)
+		dc.mu.Unlock()
+		dc.evicted(key)
+		return nil, false
+	}
+	if touch {
+		dc.order.MoveToFront(elem)
+	}
+	dc.mu.Unlock()
+
+	// The file may be removed concurrently, which is reported as a miss
+	data, err := os.ReadFile(entry.path)
+	if err != nil {
+		return nil, false
+	}
+	var value any
+	if err := dc.marshaller.Unmarshal(data, &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set stores a value in its own file. The cost is ignored in favor of the
+// encoded size. It is rejected when the value cannot be encoded or written, or
+// is bigger than the cache.
+func (dc *DiskCache) Set(key string, value any, _ int64) bool {
+	return dc.set(key, value, time.Time{})
+}
+
+// SetWithTTL stores a value that expires after ttl. Expired values are removed
+// when they are next read, or evicted to make room for new values.
+func (dc *DiskCache) SetWithTTL(key string, value any, _ int64, ttl time.Duration) bool {
+	return dc.set(key, value, time.Now().Add(ttl))
+}
+
+func (dc *DiskCache) set(key string, value any, expiresAt time.Time) bool {
+	data, err := dc.marshaller.Marshal(value)
+	if err != nil || int64(len(data)) > dc.maxBytes {
+		return false
+	}
+	path, err := dc.write(key, data)
+	if err != nil {
+		return false
+	}
+
+	dc.mu.Lock()
+	if elem, found := dc.entries[key]; found {
+		dc.remove(elem, false)
+	}
+	entry := &diskEntry{key: key, path: path, size: int64(len(data)), expiresAt: expiresAt}
+	dc.entries[key] = dc.order.PushFront(entry)
+	dc.bytes += entry.size
+	atomic.AddInt64(&dc.costAdded, entry.size)
+	var evicted []string
+	for dc.bytes > dc.maxBytes {
+		evicted = append(evicted, dc.remove(dc.order.Back(), true))
+	}
+	dc.mu.Unlock()
+
+	for _, key := range evicted {
+		dc.evicted(key)
+	}
+	return true
+}
+
+// evicted reports an evicted key to the OnEvict function. It is called without dc.mu held.
+func (dc *DiskCache) evicted(key string) {
+	if dc.onEvict != nil {
+		dc.onEvict(key)
+	}
+}
+
+// OnEvict registers fn to be called with the key of each value evicted as least
+// recently used, or removed once expired.
+func (dc *DiskCache) OnEvict(fn func(key string)) {
+	dc.onEvict = fn
+}
+
+// write stores data in a new file named after key, so concurrent Sets of a key
+// never write to the same file.
+func (dc *DiskCache) write(key string, data []byte) (string, error) {
+	sum := sha256.Sum256([]byte(key))
+	file, err := os.CreateTemp(dc.dir, hex.EncodeToString(sum[:8])+"-")
+	if err != nil {
+		return "", err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// remove deletes an entry and its file, counting it as an eviction when evicted
+// is true, and returns its key. It must be called with dc.mu held.
+func (dc *DiskCache) remove(elem *list.Element, evicted bool) string {
+	entry := dc.order.Remove(elem).(*diskEntry)
+	delete(dc.entries, entry.key)
+	dc.bytes -= entry.size
+	atomic.AddInt64(&dc.costEvicted, entry.size)
+	if evicted {
+		atomic.AddInt64(&dc.evictions, 1)
+	}
+	os.Remove(entry.path)
+	return entry.key
+}
+
+// Delete removes a value from the local cache.
+func (dc *DiskCache) Delete(key string) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	if elem, found := dc.entries[key]; found {
+		dc.remove(elem, false)
+	}
+}
+
+// Clear removes all values from the local cache.
+func (dc *DiskCache) Clear() {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	for dc.order.Len() > 0 {
+		dc.remove(dc.order.Front(), false)
+	}
+}
+
+// Close removes the directory of the cache.
+func (dc *DiskCache) Close() {
+	dc.Clear()
+	os.RemoveAll(dc.dir)
+}
+
+// Keys returns the keys currently held.
+func (dc *DiskCache) Keys() []string {
+	now := time.Now()
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	keys := make([]string, 0, len(dc.entries))
+	for key, elem := range dc.entries {
+		if expiresAt := elem.Value.(*diskEntry).expiresAt; expiresAt.IsZero() || now.Before(expiresAt) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Iterate calls fn for each entry without updating recency or hit counts,
+// stopping when fn returns false. Each value is read from disk.
+func (dc *DiskCache) Iterate(fn func(key string, value any) bool) {
+	for _, key := range dc.Keys() {
+		value, found := dc.read(key, false)
+		if !found {
+			continue
+		}
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+// Dir returns the directory holding the files of the cache.
+func (dc *DiskCache) Dir() string {
+	return dc.dir
+}
+
+// Metrics returns cache metrics. Size and costs are in encoded bytes.
+func (dc *DiskCache) Metrics() LocalCacheMetrics {
+	dc.mu.Lock()
+	size, items := dc.bytes, int64(len(dc.entries))
+	dc.mu.Unlock()
+	return LocalCacheMetrics{
+		Hits:        atomic.LoadInt64(&dc.hits),
+		Misses:      atomic.LoadInt64(&dc.misses),
+		Evictions:   atomic.LoadInt64(&dc.evictions),
+		Size:        size,
+		CostAdded:   atomic.LoadInt64(&dc.costAdded),
+		CostEvicted: atomic.LoadInt64(&dc.costEvicted),
+		Items:       items,
+	}
+}
@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	local, err := NewDiskCache(dir, 1<<20, nil)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	value := map[string]any{"name": "alice", "blob": strings.Repeat("x", 1000)}
+	if !local.Set("user:1", value, 0) {
+		t.Fatal("Expected the value to be stored")
+	}
+	got, found := local.Get("user:1")
+	if !found {
+		t.Fatal("Expected a hit")
+	}
+	if got.(map[string]any)["name"] != "alice" {
+		t.Fatalf("Unexpected value %v", got)
+	}
+	if keys := local.Keys(); len(keys) != 1 || keys[0] != "user:1" {
+		t.Fatalf("Expected [user:1], got %v", keys)
+	}
+
+	local.SetWithTTL("short", "value", 0, 20*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	if _, found := local.Get("short"); found {
+		t.Fatal("Expected the value to expire")
+	}
+
+	local.Delete("user:1")
+	if _, found := local.Get("user:1"); found {
+		t.Fatal("Expected a miss after Delete")
+	}
+	metrics := local.Metrics()
+	if metrics.Hits != 1 || metrics.Misses != 2 || metrics.Evictions != 1 || metrics.Size != 0 || metrics.Items != 0 {
+		t.Fatalf("Unexpected metrics: %+v", metrics)
+	}
+
+	local.Close()
+	if _, err := os.Stat(local.Dir()); !os.IsNotExist(err) {
+		t.Fatalf("Expected Close to remove the cache directory, got %v", err)
+	}
+}
+
+func TestDiskCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	local, err := NewDiskCache(t.TempDir(), 30, nil)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer local.Close()
+
+	// Each value encodes to 10 bytes
+	local.Set("a", "aaaaaaaa", 0)
+	local.Set("b", "bbbbbbbb", 0)
+	local.Set("c", "cccccccc", 0)
+	local.Get("a")
+	local.Set("d", "dddddddd", 0)
+
+	if _, found := local.Get("b"); found {
+		t.Fatal("Expected the least recently used value to be evicted")
+	}
+	for _, key := range []string{"a", "c", "d"} {
+		if _, found := local.Get(key); !found {
+			t.Fatalf("Expected %s to be kept", key)
+		}
+	}
+	if local.Set("huge", strings.Repeat("x", 40), 0) {
+		t.Fatal("Expected a value bigger than the cache to be rejected")
+	}
+	entries, err := os.ReadDir(local.Dir())
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 files, got %d", len(entries))
+	}
+}
@@ -1,7 +1,10 @@
+//go:build !noristretto
+
 package cache
 
 import (
 	"sync/atomic"
+	"time"
 
 	lfu "github.com/dgraph-io/ristretto"
 )
@@ -23,22 +26,31 @@ func (rcf *LFUCacheFactory) Create() (LocalCache, error) {
 
 // NewLFUCache creates a new Ristretto-based local cache.
 func NewLFUCache(config LocalCacheConfig) (*LFUCache, error) {
+	rc := &LFUCache{}
 	cache, err := lfu.NewCache(&lfu.Config{
 		NumCounters:        config.NumCounters,
 		MaxCost:            config.MaxCost,
 		BufferItems:        config.BufferItems,
 		IgnoreInternalCost: config.IgnoreInternalCost,
+		Metrics:            true,
 		OnEvict: func(item *lfu.Item) {
-			// Track evictions
+			// Called for values evicted by the policy or expired, not for deletes,
+			// and for every value on Clear
+			if rc.clearing.Load() {
+				return
+			}
+			atomic.AddInt64(&rc.evictions, 1)
+			if kv, ok := item.Value.(*keyedValue); ok && rc.onEvict != nil {
+				rc.onEvict(kv.key)
+			}
 		},
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &LFUCache{
-		cache: cache,
-	}, nil
+	rc.cache = cache
+	return rc, nil
 }
 
 // LFUCache is a local LFU cache implementation using lfu.
@@ -47,11 +59,37 @@ type LFUCache struct {
 	hits      int64
 	misses    int64
 	evictions int64
+	onEvict   func(key string)
+	clearing  atomic.Bool
+}
+
+// keyedValue wraps the values stored once OnEvict is called, since Ristretto
+// only reports the hash of evicted keys.
+type keyedValue struct {
+	key   string
+	value any
+}
+
+// wrap returns the value to store in Ristretto for key.
+func (rc *LFUCache) wrap(key string, value any) any {
+	if rc.onEvict == nil {
+		return value
+	}
+	return &keyedValue{key: key, value: value}
+}
+
+// OnEvict registers fn to be called with the key of each value evicted or expired.
+// Values are then stored along with their key.
+func (rc *LFUCache) OnEvict(fn func(key string)) {
+	rc.onEvict = fn
 }
 
 // Get retrieves a value from the local cache.
 func (rc *LFUCache) Get(key string) (any, bool) {
 	value, found := rc.cache.Get(key)
+	if kv, ok := value.(*keyedValue); ok {
+		value = kv.value
+	}
 	if found {
 		atomic.AddInt64(&rc.hits, 1)
 	} else {
@@ -62,9 +100,52 @@ func (rc *LFUCache) Get(key string) (any, bool) {
 
 // Set stores a value in the local cache.
 func (rc *LFUCache) Set(key string, value any, cost int64) bool {
-	return rc.cache.Set(key, value, cost)
+	return rc.cache.Set(key, rc.wrap(key, value), cost)
+}
+
+// SetWithTTL stores a value that Ristretto expires after ttl.
+func (rc *LFUCache) SetWithTTL(key string, value any, cost int64, ttl time.Duration) bool {
+	return rc.cache.SetWithTTL(key, rc.wrap(key, value), cost, ttl)
+}
+
+// Wait blocks until buffered Sets have been applied.
+func (rc *LFUCache) Wait() {
+	rc.cache.Wait()
+}
+
+// Contains reports whether key is present without counting as an access.
+func (rc *LFUCache) Contains(key string) bool {
+	_, found := rc.cache.GetTTL(key)
+	return found
 }
 
+// ForceSet stores a value and keeps retrying while the admission policy rejects it.
+// Before each retry the key is accessed to raise its estimated frequency, which is
+// what Ristretto compares against eviction candidates. Admission is still not
+// guaranteed, for example when the cost exceeds MaxCost.
+func (rc *LFUCache) ForceSet(key string, value any, cost int64) bool {
+	for attempt := 0; attempt < forceSetAttempts; attempt++ {
+		if attempt > 0 {
+			for range forceSetTouches {
+				rc.cache.Get(key)
+			}
+		}
+		if rc.cache.Set(key, rc.wrap(key, value), cost) {
+			rc.cache.Wait()
+			if rc.Contains(key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// forceSetAttempts is the number of Sets tried by ForceSet.
+const forceSetAttempts = 3
+
+// forceSetTouches is the number of accesses recorded between ForceSet attempts.
+const forceSetTouches = 64
+
 // Delete removes a value from the local cache.
 func (rc *LFUCache) Delete(key string) {
 	rc.cache.Del(key)
@@ -72,20 +153,29 @@ func (rc *LFUCache) Delete(key string) {
 
 // Clear removes all values from the local cache.
 func (rc *LFUCache) Clear() {
+	rc.clearing.Store(true)
+	defer rc.clearing.Store(false)
 	rc.cache.Clear()
 }
 
 // Close closes the local cache.
 func (rc *LFUCache) Close() {
+	rc.clearing.Store(true)
 	rc.cache.Close()
 }
 
-// Metrics returns cache metrics.
+// Metrics returns cache metrics. Size is the cost currently used, while cost and
+// item counts come from Ristretto's metrics, which count deletes as removals.
 func (rc *LFUCache) Metrics() LocalCacheMetrics {
+	metrics := rc.cache.Metrics
+	costAdded, costEvicted := int64(metrics.CostAdded()), int64(metrics.CostEvicted())
 	return LocalCacheMetrics{
-		Hits:      atomic.LoadInt64(&rc.hits),
-		Misses:    atomic.LoadInt64(&rc.misses),
-		Evictions: atomic.LoadInt64(&rc.evictions),
-		Size:      int64(rc.cache.MaxCost()),
+		Hits:        atomic.LoadInt64(&rc.hits),
+		Misses:      atomic.LoadInt64(&rc.misses),
+		Evictions:   atomic.LoadInt64(&rc.evictions),
+		Size:        costAdded - costEvicted,
+		CostAdded:   costAdded,
+		CostEvicted: costEvicted,
+		Items:       int64(metrics.KeysAdded() - metrics.KeysEvicted()),
 	}
 }
@@ -1,6 +1,10 @@
+//go:build !noristretto
+
 package cache
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 )
@@ -149,3 +153,138 @@ func TestLFUCacheFactory(t *testing.T) {
 		t.Fatal("Cache should not be nil")
 	}
 }
+
+// TestLFUCacheContainsAndForceSet tests admission helpers used for rejected Sets
+func TestLFUCacheContainsAndForceSet(t *testing.T) {
+	cache, err := NewLFUCache(DefaultLocalCacheConfig())
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer cache.Close()
+
+	if cache.Contains("key1") {
+		t.Fatal("Contains should be false for a missing key")
+	}
+
+	if !cache.ForceSet("key1", "value1", 1) {
+		t.Fatal("ForceSet should admit a value into a cache with free capacity")
+	}
+	if !cache.Contains("key1") {
+		t.Fatal("Contains should be true after ForceSet")
+	}
+
+	if cache.ForceSet("huge", "value", DefaultLocalCacheConfig().MaxCost+1) {
+		t.Fatal("ForceSet should not admit a value exceeding MaxCost")
+	}
+
+	if metrics := cache.Metrics(); metrics.Hits != 0 || metrics.Misses != 0 {
+		t.Fatalf("Contains and ForceSet should not count as accesses, got %+v", metrics)
+	}
+}
+
+func TestLFUCacheSetWithTTL(t *testing.T) {
+	cache, err := NewLFUCache(DefaultLocalCacheConfig())
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer cache.Close()
+
+	if !cache.SetWithTTL("key1", "value1", 1, 20*time.Millisecond) {
+		t.Fatal("SetWithTTL should succeed")
+	}
+	cache.Wait()
+	if _, found := cache.Get("key1"); !found {
+		t.Fatal("Expected the value before it expires")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, found := cache.Get("key1"); found {
+		t.Error("Expected the value to expire")
+	}
+}
+
+func TestLFUCacheEvictionMetrics(t *testing.T) {
+	cache, err := NewLFUCache(LocalCacheConfig{NumCounters: 1000, MaxCost: 10, BufferItems: 64, IgnoreInternalCost: true})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer cache.Close()
+
+	for i := 0; i < 50; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), i, 1)
+		cache.Wait()
+	}
+
+	metrics := cache.Metrics()
+	if metrics.Evictions == 0 {
+		t.Fatal("Expected evictions once MaxCost is exceeded")
+	}
+	if metrics.Items <= 0 || metrics.Items > 10 || metrics.Size != metrics.Items {
+		t.Fatalf("Expected at most 10 items using one cost each, got %+v", metrics)
+	}
+	if metrics.CostAdded-metrics.CostEvicted != metrics.Size {
+		t.Fatalf("Expected Size to be the cost added minus the cost evicted, got %+v", metrics)
+	}
+
+	before := cache.Metrics().Evictions
+	cache.Delete("key49")
+	cache.Wait()
+	if after := cache.Metrics().Evictions; after != before {
+		t.Fatalf("Expected deletes not to count as evictions, got %d then %d", before, after)
+	}
+}
+
+func TestLFUCacheOnEvict(t *testing.T) {
+	cache, err := NewLFUCache(LocalCacheConfig{NumCounters: 1000, MaxCost: 10, BufferItems: 64, IgnoreInternalCost: true})
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer cache.Close()
+	var mu sync.Mutex
+	evicted := make(map[string]bool)
+	cache.OnEvict(func(key string) {
+		mu.Lock()
+		evicted[key] = true
+		mu.Unlock()
+	})
+
+	for i := 0; i < 50; i++ {
+		cache.Set(fmt.Sprintf("key%d", i), i, 1)
+		cache.Wait()
+	}
+	if value, found := cache.Get("key49"); found && value != 49 {
+		t.Fatalf("Expected values to be unwrapped, got %v", value)
+	}
+
+	mu.Lock()
+	count := len(evicted)
+	mu.Unlock()
+	if count == 0 || int64(count) != cache.Metrics().Evictions {
+		t.Fatalf("Expected every eviction to be reported with its key, got %d of %d", count, cache.Metrics().Evictions)
+	}
+
+	cache.Clear()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(evicted) != count {
+		t.Fatalf("Expected Clear not to report evictions, got %d then %d", count, len(evicted))
+	}
+}
+
+func TestShardedLocalCacheOverLFU(t *testing.T) {
+	local, err := NewShardedLocalCache(NewLFUCacheFactory(DefaultLocalCacheConfig()), 4)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer local.Close()
+
+	if _, ok := local.(IterableLocalCache); ok {
+		t.Fatal("Expected a cache over non-iterable shards not to be iterable")
+	}
+	admitting := local.(AdmittingLocalCache)
+	local.Set("key", "value", 1)
+	admitting.Wait()
+	if !admitting.Contains("key") {
+		t.Fatal("Expected the value to be admitted")
+	}
+}
@@ -1,7 +1,9 @@
 package cache
 
 import (
+	"sync"
 	"sync/atomic"
+	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2"
 )
@@ -28,24 +30,79 @@ type LRUCache struct {
 	misses    int64
 	evictions int64
 	maxSize   int64
+	onEvict   func(key string)
+	removing  sync.Map // keys being deleted, which golang-lru reports like evictions
+	purging   atomic.Bool
 }
 
 // NewLRUCache creates a new LRU-based local cache.
 func NewLRUCache(maxSize int) (*LRUCache, error) {
-	cache, err := lru.New[string, any](maxSize)
+	lc := &LRUCache{maxSize: int64(maxSize)}
+	cache, err := lru.NewWithEvict[string, any](maxSize, lc.evicted)
 	if err != nil {
 		return nil, err
 	}
 
-	return &LRUCache{
-		cache:   cache,
-		maxSize: int64(maxSize),
-	}, nil
+	lc.cache = cache
+	return lc, nil
+}
+
+// evicted is called by golang-lru for every removed entry. Entries removed by
+// Delete or Clear are not counted as evictions.
+func (lc *LRUCache) evicted(key string, _ any) {
+	if lc.purging.Load() {
+		return
+	}
+	if _, deleting := lc.removing.Load(key); deleting {
+		return
+	}
+	atomic.AddInt64(&lc.evictions, 1)
+	if lc.onEvict != nil {
+		lc.onEvict(key)
+	}
+}
+
+// OnEvict registers fn to be called with the key of each entry evicted as least
+// recently used, or removed once expired.
+func (lc *LRUCache) OnEvict(fn func(key string)) {
+	lc.onEvict = fn
+}
+
+// expiringValue wraps a value stored with SetWithTTL.
+type expiringValue struct {
+	value     any
+	expiresAt time.Time
+}
+
+// unwrapExpiring returns the value held in a local cache and whether it is
+// still fresh, unwrapping values stored with SetWithTTL.
+func unwrapExpiring(held any) (any, bool) {
+	ev, ok := held.(*expiringValue)
+	if !ok {
+		return held, true
+	}
+	if time.Now().After(ev.expiresAt) {
+		return nil, false
+	}
+	return ev.value, true
+}
+
+// unwrap returns the value held for key and whether it is present and not
+// expired, removing it once expired.
+func (lc *LRUCache) unwrap(key string, held any) (any, bool) {
+	value, fresh := unwrapExpiring(held)
+	if !fresh {
+		lc.cache.Remove(key)
+	}
+	return value, fresh
 }
 
 // Get retrieves a value from the local cache.
 func (lc *LRUCache) Get(key string) (any, bool) {
 	value, found := lc.cache.Get(key)
+	if found {
+		value, found = lc.unwrap(key, value)
+	}
 	if found {
 		atomic.AddInt64(&lc.hits, 1)
 	} else {
@@ -60,19 +117,57 @@ func (lc *LRUCache) Set(key string, value any, _ int64) bool {
 	return true
 }
 
+// SetWithTTL stores a value that expires after ttl. Expired values are removed
+// when they are next read or iterated, or when evicted as least recently used.
+func (lc *LRUCache) SetWithTTL(key string, value any, _ int64, ttl time.Duration) bool {
+	lc.cache.Add(key, &expiringValue{value: value, expiresAt: time.Now().Add(ttl)})
+	return true
+}
+
 // Delete removes a value from the local cache.
 func (lc *LRUCache) Delete(key string) {
+	lc.removing.Store(key, struct{}{})
+	defer lc.removing.Delete(key)
 	lc.cache.Remove(key)
 }
 
 // Clear removes all values from the local cache.
 func (lc *LRUCache) Clear() {
+	lc.purging.Store(true)
+	defer lc.purging.Store(false)
 	lc.cache.Purge()
 }
 
 // Close closes the local cache.
 func (lc *LRUCache) Close() {
-	lc.cache.Purge()
+	lc.Clear()
+}
+
+// Keys returns the keys currently held, from oldest to newest.
+func (lc *LRUCache) Keys() []string {
+	keys := make([]string, 0, lc.cache.Len())
+	lc.Iterate(func(key string, _ any) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Iterate calls fn for each entry, from oldest to newest, without updating recency
+// or hit counts. It stops when fn returns false.
+func (lc *LRUCache) Iterate(fn func(key string, value any) bool) {
+	for _, key := range lc.cache.Keys() {
+		value, ok := lc.cache.Peek(key)
+		if ok {
+			value, ok = lc.unwrap(key, value)
+		}
+		if !ok {
+			continue
+		}
+		if !fn(key, value) {
+			return
+		}
+	}
 }
 
 // Metrics returns cache metrics.
@@ -82,5 +177,6 @@ func (lc *LRUCache) Metrics() LocalCacheMetrics {
 		Misses:    atomic.LoadInt64(&lc.misses),
 		Evictions: atomic.LoadInt64(&lc.evictions),
 		Size:      lc.maxSize,
+		Items:     int64(lc.cache.Len()),
 	}
 }
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"testing"
+	"time"
 )
 
 func TestLRUCacheNew(t *testing.T) {
@@ -304,3 +305,77 @@ func TestLRUCacheSetWithDifferentTypes(t *testing.T) {
 		t.Fatal("Struct value should match")
 	}
 }
+
+func TestLRUCacheKeysAndIterate(t *testing.T) {
+	cache, err := NewLRUCache(10)
+	if err != nil {
+		t.Fatalf("Failed to create LRU cache: %v", err)
+	}
+	cache.Set("a", 1, 1)
+	cache.Set("b", 2, 1)
+	cache.Set("c", 3, 1)
+
+	keys := cache.Keys()
+	if len(keys) != 3 || keys[0] != "a" || keys[2] != "c" {
+		t.Fatalf("Expected keys from oldest to newest, got %v", keys)
+	}
+
+	var visited []string
+	cache.Iterate(func(key string, value any) bool {
+		visited = append(visited, key)
+		return key != "b"
+	})
+	if len(visited) != 2 {
+		t.Errorf("Expected iteration to stop after b, got %v", visited)
+	}
+	if hits := cache.Metrics().Hits; hits != 0 {
+		t.Errorf("Expected iteration not to count as hits, got %d", hits)
+	}
+}
+
+func TestLRUCacheSetWithTTL(t *testing.T) {
+	cache, err := NewLRUCache(100)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer cache.Close()
+
+	cache.SetWithTTL("key1", "value1", 1, 20*time.Millisecond)
+	cache.Set("key2", "value2", 1)
+	if value, found := cache.Get("key1"); !found || value != "value1" {
+		t.Fatalf("Expected value1 before it expires, got %v (found=%v)", value, found)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if keys := cache.Keys(); len(keys) != 1 || keys[0] != "key2" {
+		t.Errorf("Expected only key2 after expiry, got %v", keys)
+	}
+	if _, found := cache.Get("key1"); found {
+		t.Error("Expected the value to expire")
+	}
+	if _, found := cache.Get("key2"); !found {
+		t.Error("Expected values without TTL to be kept")
+	}
+}
+
+func TestLRUCacheOnEvict(t *testing.T) {
+	cache, err := NewLRUCache(2)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	var evicted []string
+	cache.OnEvict(func(key string) { evicted = append(evicted, key) })
+
+	cache.Set("key1", "value1", 1)
+	cache.Set("key2", "value2", 1)
+	cache.Set("key3", "value3", 1)
+	cache.Delete("key2")
+	cache.Clear()
+
+	if len(evicted) != 1 || evicted[0] != "key1" {
+		t.Fatalf("Expected only key1 to be evicted, got %v", evicted)
+	}
+	if evictions := cache.Metrics().Evictions; evictions != 1 {
+		t.Fatalf("Expected 1 eviction, got %d", evictions)
+	}
+}
@@ -0,0 +1,231 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MapCacheFactory creates map cache instances.
+type MapCacheFactory struct {
+	maxCost int64
+}
+
+// NewMapCacheFactory creates a new map cache factory.
+func NewMapCacheFactory(maxCost int64) LocalCacheFactory {
+	return &MapCacheFactory{maxCost: maxCost}
+}
+
+// Create creates a new map cache instance.
+func (mcf *MapCacheFactory) Create() (LocalCache, error) {
+	return NewMapCache(mcf.maxCost)
+}
+
+// MapCache is a lightweight local cache backed by a plain map, bounded by the
+// total cost of its values. When full, it evicts arbitrary entries. It has no
+// dependencies, which makes it the default local cache of builds using the
+// noristretto tag; wrap it in a ShardedLocalCache to spread lock contention.
+type MapCache struct {
+	mu          sync.Mutex
+	entries     map[string]mapEntry
+	cost        int64
+	maxCost     int64
+	hits        int64
+	misses      int64
+	evictions   int64
+	costAdded   int64
+	costEvicted int64
+	onEvict     func(key string)
+}
+
+// mapEntry is a value held by a MapCache.
+type mapEntry struct {
+	value     any
+	cost      int64
+	expiresAt time.Time
+}
+
+// expired reports whether the entry was stored with a TTL that has elapsed.
+func (e mapEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// NewMapCache creates a new map-based local cache holding values of at most maxCost in total.
+func NewMapCache(maxCost int64) (*MapCache, error) {
+	if maxCost <= 0 {
+		return nil, ErrInvalidConfig
+	}
+	return &MapCache{entries: make(map[string]mapEntry), maxCost: maxCost}, nil
+}
+
+// Get retrieves a value from the local cache.
+func (mc *MapCache) Get(key string) (any, bool) {
+	mc.mu.Lock()
+	entry, found := mc.entries[key]
+	expired := found && entry.expired(time.Now())
+	if expired {
+		mc.remove(key, entry, true)
+		found = false
+	}
+	mc.mu.Unlock()
+
+	if expired {
+		mc.evicted(key)
+	}
+	if found {
+		atomic.AddInt64(&mc.hits, 1)
+	} else {
+		atomic.AddInt64(&mc.misses, 1)
+	}
+	return entry.value, found
+}
+
+// Set stores a value in the local cache. It is rejected when cost exceeds the
+// maximum cost of the cache.
+func (mc *MapCache) Set(key string, value any, cost int64) bool {
+	return mc.set(key, mapEntry{value: value, cost: cost})
+}
+
+// SetWithTTL stores a value that expires after ttl. Expired values are removed
+// when they are next read or iterated, or to make room for new values.
+func (mc *MapCache) SetWithTTL(key string, value any, cost int64, ttl time.Duration) bool {
+	return mc.set(key, mapEntry{value: value, cost: cost, expiresAt: time.Now().Add(ttl)})
+}
+
+func (mc *MapCache) set(key string, entry mapEntry) bool {
+	if entry.cost > mc.maxCost {
+		return false
+	}
+
+	mc.mu.Lock()
+	if old, found := mc.entries[key]; found {
+		mc.remove(key, old, false)
+	}
+	var evicted []string
+	if mc.cost+entry.cost > mc.maxCost {
+		evicted = mc.makeRoom(entry.cost)
+	}
+	mc.entries[key] = entry
+	mc.cost += entry.cost
+	atomic.AddInt64(&mc.costAdded, entry.cost)
+	mc.mu.Unlock()
+
+	for _, key := range evicted {
+		mc.evicted(key)
+	}
+	return true
+}
+
+// makeRoom evicts expired entries, then arbitrary ones, until cost more fits,
+// and returns the evicted keys. It must be called with mc.mu held.
+func (mc *MapCache) makeRoom(cost int64) []string {
+	var evicted []string
+	now := time.Now()
+	for key, entry := range mc.entries {
+		if entry.expired(now) {
+			mc.remove(key, entry, true)
+			evicted = append(evicted, key)
+		}
+	}
+	for key, entry := range mc.entries {
+		if mc.cost+cost <= mc.maxCost {
+			break
+		}
+		mc.remove(key, entry, true)
+		evicted = append(evicted, key)
+	}
+	return evicted
+}
+
+// evicted reports an evicted key to the OnEvict function. It is called without mc.mu held.
+func (mc *MapCache) evicted(key string) {
+	if mc.onEvict != nil {
+		mc.onEvict(key)
+	}
+}
+
+// OnEvict registers fn to be called with the key of each entry evicted to make
+// room for new values, or removed once expired.
+func (mc *MapCache) OnEvict(fn func(key string)) {
+	mc.onEvict = fn
+}
+
+// remove deletes an entry, counting it as an eviction when evicted is true.
+// It must be called with mc.mu held.
+func (mc *MapCache) remove(key string, entry mapEntry, evicted bool) {
+	delete(mc.entries, key)
+	mc.cost -= entry.cost
+	atomic.AddInt64(&mc.costEvicted, entry.cost)
+	if evicted {
+		atomic.AddInt64(&mc.evictions, 1)
+	}
+}
+
+// Delete removes a value from the local cache.
+func (mc *MapCache) Delete(key string) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if entry, found := mc.entries[key]; found {
+		mc.remove(key, entry, false)
+	}
+}
+
+// Clear removes all values from the local cache.
+func (mc *MapCache) Clear() {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	atomic.AddInt64(&mc.costEvicted, mc.cost)
+	mc.entries = make(map[string]mapEntry)
+	mc.cost = 0
+}
+
+// Close closes the local cache.
+func (mc *MapCache) Close() {
+	mc.Clear()
+}
+
+// Keys returns the keys currently held.
+func (mc *MapCache) Keys() []string {
+	var keys []string
+	mc.Iterate(func(key string, _ any) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Iterate calls fn for each entry without counting as an access, stopping when
+// fn returns false. It iterates over a copy, so fn may modify the cache.
+func (mc *MapCache) Iterate(fn func(key string, value any) bool) {
+	now := time.Now()
+	mc.mu.Lock()
+	entries := make(map[string]any, len(mc.entries))
+	for key, entry := range mc.entries {
+		if !entry.expired(now) {
+			entries[key] = entry.value
+		}
+	}
+	mc.mu.Unlock()
+
+	for key, value := range entries {
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+// Metrics returns cache metrics. Size is the cost currently used.
+func (mc *MapCache) Metrics() LocalCacheMetrics {
+	mc.mu.Lock()
+	size, items := mc.cost, int64(len(mc.entries))
+	mc.mu.Unlock()
+	return LocalCacheMetrics{
+		Hits:        atomic.LoadInt64(&mc.hits),
+		Misses:      atomic.LoadInt64(&mc.misses),
+		Evictions:   atomic.LoadInt64(&mc.evictions),
+		Size:        size,
+		CostAdded:   atomic.LoadInt64(&mc.costAdded),
+		CostEvicted: atomic.LoadInt64(&mc.costEvicted),
+		Items:       items,
+	}
+}
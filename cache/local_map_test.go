@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMapCache(t *testing.T) {
+	local, err := NewMapCache(10)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer local.Close()
+
+	local.Set("key1", "value1", 2)
+	if value, found := local.Get("key1"); !found || value != "value1" {
+		t.Fatalf("Expected value1, got %v (found=%v)", value, found)
+	}
+	local.Set("key1", "value2", 3)
+	local.Delete("key1")
+	if _, found := local.Get("key1"); found {
+		t.Fatal("Expected a miss after Delete")
+	}
+
+	local.SetWithTTL("short", "value", 1, 20*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	if keys := local.Keys(); len(keys) != 0 {
+		t.Fatalf("Expected expired values not to be iterated, got %v", keys)
+	}
+	if _, found := local.Get("short"); found {
+		t.Fatal("Expected the value to expire")
+	}
+
+	metrics := local.Metrics()
+	if metrics.Hits != 1 || metrics.Misses != 2 || metrics.Evictions != 1 || metrics.Size != 0 || metrics.Items != 0 {
+		t.Fatalf("Unexpected metrics: %+v", metrics)
+	}
+	if metrics.CostAdded != 6 || metrics.CostEvicted != 6 {
+		t.Fatalf("Unexpected cost metrics: %+v", metrics)
+	}
+}
+
+func TestMapCacheBoundsCost(t *testing.T) {
+	local, err := NewMapCache(5)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer local.Close()
+
+	if local.Set("huge", "value", 6) {
+		t.Fatal("Expected a value costlier than the cache to be rejected")
+	}
+	for i := 0; i < 20; i++ {
+		if !local.Set(fmt.Sprintf("key%d", i), i, 1) {
+			t.Fatalf("Expected key%d to be admitted", i)
+		}
+	}
+
+	metrics := local.Metrics()
+	if metrics.Size != 5 || metrics.Items != 5 || metrics.Evictions != 15 {
+		t.Fatalf("Unexpected metrics: %+v", metrics)
+	}
+	if _, found := local.Get("key19"); !found {
+		t.Fatal("Expected the last value to be kept")
+	}
+
+	local.Clear()
+	if metrics := local.Metrics(); metrics.Size != 0 || metrics.Items != 0 {
+		t.Fatalf("Expected an empty cache after Clear, got %+v", metrics)
+	}
+}
+
+func TestNewMapCacheInvalid(t *testing.T) {
+	if _, err := NewMapCache(0); err != ErrInvalidConfig {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestDefaultLocalCacheFactory(t *testing.T) {
+	local, err := defaultLocalCacheFactory(DefaultLocalCacheConfig()).Create()
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer local.Close()
+
+	local.Set("key", "value", 1)
+	if admitting, ok := local.(AdmittingLocalCache); ok {
+		admitting.Wait()
+	}
+	if value, found := local.Get("key"); !found || value != "value" {
+		t.Fatalf("Expected value, got %v (found=%v)", value, found)
+	}
+}
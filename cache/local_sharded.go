@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"hash/maphash"
+	"time"
+)
+
+// ShardedLocalCacheFactory creates sharded local cache instances.
+type ShardedLocalCacheFactory struct {
+	factory LocalCacheFactory
+	shards  int
+}
+
+// NewShardedLocalCacheFactory creates a factory splitting each local cache into
+// shards caches created by factory. Each shard is created with the full
+// configuration of factory, so size factory for one shard.
+func NewShardedLocalCacheFactory(factory LocalCacheFactory, shards int) LocalCacheFactory {
+	return &ShardedLocalCacheFactory{factory: factory, shards: shards}
+}
+
+// Create creates a new sharded local cache instance.
+func (scf *ShardedLocalCacheFactory) Create() (LocalCache, error) {
+	return NewShardedLocalCache(scf.factory, scf.shards)
+}
+
+// perShard returns the configuration of one of shards caches sharing the capacity
+// of config, each holding at least one entry.
+func (config LocalCacheConfig) perShard(shards int) LocalCacheConfig {
+	n := int64(shards)
+	if config.NumCounters > 0 {
+		config.NumCounters = max(config.NumCounters/n, 1)
+	}
+	if config.MaxCost > 0 {
+		config.MaxCost = max(config.MaxCost/n, 1)
+	}
+	if config.MaxSize > 0 {
+		config.MaxSize = max(config.MaxSize/shards, 1)
+	}
+	return config
+}
+
+// ShardedLocalCache spreads keys over several local caches by key hash, so that
+// concurrent writes to different keys do not contend on a single cache lock.
+//
+// It implements AdmittingLocalCache and ExpiringLocalCache whatever the shards
+// are: Wait is a no-op and ForceSet a Set for shards that do not admit values,
+// Contains falls back to a Get (counted as an access), and SetWithTTL falls back
+// to Set for shards that cannot expire entries.
+type ShardedLocalCache struct {
+	shards []LocalCache
+	seed   maphash.Seed
+}
+
+// NewShardedLocalCache creates a local cache of shards caches created by factory.
+// The returned cache implements IterableLocalCache when the shards do.
+func NewShardedLocalCache(factory LocalCacheFactory, shards int) (LocalCache, error) {
+	if factory == nil || shards < 1 {
+		return nil, ErrInvalidConfig
+	}
+
+	sc := &ShardedLocalCache{shards: make([]LocalCache, shards), seed: maphash.MakeSeed()}
+	for i := range sc.shards {
+		shard, err := factory.Create()
+		if err != nil {
+			sc.Close()
+			return nil, err
+		}
+		sc.shards[i] = shard
+	}
+
+	if _, ok := sc.shards[0].(IterableLocalCache); ok {
+		return &iterableShardedLocalCache{ShardedLocalCache: sc}, nil
+	}
+	return sc, nil
+}
+
+// shard returns the shard holding key.
+func (sc *ShardedLocalCache) shard(key string) LocalCache {
+	return sc.shards[maphash.String(sc.seed, key)%uint64(len(sc.shards))]
+}
+
+// Get retrieves a value from the local cache.
+func (sc *ShardedLocalCache) Get(key string) (any, bool) {
+	return sc.shard(key).Get(key)
+}
+
+// Set stores a value in the local cache.
+func (sc *ShardedLocalCache) Set(key string, value any, cost int64) bool {
+	return sc.shard(key).Set(key, value, cost)
+}
+
+// SetWithTTL stores a value that expires after ttl.
+func (sc *ShardedLocalCache) SetWithTTL(key string, value any, cost int64, ttl time.Duration) bool {
+	shard := sc.shard(key)
+	if expiring, ok := shard.(ExpiringLocalCache); ok {
+		return expiring.SetWithTTL(key, value, cost, ttl)
+	}
+	return shard.Set(key, value, cost)
+}
+
+// Wait blocks until buffered Sets have been applied on every shard.
+func (sc *ShardedLocalCache) Wait() {
+	for _, shard := range sc.shards {
+		if admitting, ok := shard.(AdmittingLocalCache); ok {
+			admitting.Wait()
+		}
+	}
+}
+
+// Contains reports whether key is present.
+func (sc *ShardedLocalCache) Contains(key string) bool {
+	shard := sc.shard(key)
+	if admitting, ok := shard.(AdmittingLocalCache); ok {
+		return admitting.Contains(key)
+	}
+	_, found := shard.Get(key)
+	return found
+}
+
+// ForceSet stores a value, working around the admission policy of the shard where possible.
+func (sc *ShardedLocalCache) ForceSet(key string, value any, cost int64) bool {
+	shard := sc.shard(key)
+	if admitting, ok := shard.(AdmittingLocalCache); ok {
+		return admitting.ForceSet(key, value, cost)
+	}
+	return shard.Set(key, value, cost)
+}
+
+// OnEvict registers fn with every shard implementing EvictingLocalCache.
+func (sc *ShardedLocalCache) OnEvict(fn func(key string)) {
+	for _, shard := range sc.shards {
+		if evicting, ok := shard.(EvictingLocalCache); ok {
+			evicting.OnEvict(fn)
+		}
+	}
+}
+
+// Delete removes a value from the local cache.
+func (sc *ShardedLocalCache) Delete(key string) {
+	sc.shard(key).Delete(key)
+}
+
+// Clear removes all values from every shard.
+func (sc *ShardedLocalCache) Clear() {
+	for _, shard := range sc.shards {
+		shard.Clear()
+	}
+}
+
+// Close closes every shard.
+func (sc *ShardedLocalCache) Close() {
+	for _, shard := range sc.shards {
+		if shard != nil {
+			shard.Close()
+		}
+	}
+}
+
+// Metrics returns the sum of the metrics of every shard.
+func (sc *ShardedLocalCache) Metrics() LocalCacheMetrics {
+	var total LocalCacheMetrics
+	for _, shard := range sc.shards {
+		m := shard.Metrics()
+		total.Hits += m.Hits
+		total.Misses += m.Misses
+		total.Evictions += m.Evictions
+		total.Size += m.Size
+		total.CostAdded += m.CostAdded
+		total.CostEvicted += m.CostEvicted
+		total.Items += m.Items
+	}
+	return total
+}
+
+// iterableShardedLocalCache is a ShardedLocalCache over IterableLocalCache shards.
+type iterableShardedLocalCache struct {
+	*ShardedLocalCache
+}
+
+// Keys returns the keys currently held by every shard.
+func (ic *iterableShardedLocalCache) Keys() []string {
+	var keys []string
+	for _, shard := range ic.shards {
+		keys = append(keys, shard.(IterableLocalCache).Keys()...)
+	}
+	return keys
+}
+
+// Iterate calls fn for each entry of every shard, stopping when fn returns false.
+func (ic *iterableShardedLocalCache) Iterate(fn func(key string, value any) bool) {
+	for _, shard := range ic.shards {
+		stopped := false
+		shard.(IterableLocalCache).Iterate(func(key string, value any) bool {
+			if !fn(key, value) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if stopped {
+			return
+		}
+	}
+}
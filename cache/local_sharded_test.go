@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShardedLocalCache(t *testing.T) {
+	local, err := NewShardedLocalCacheFactory(NewLRUCacheFactory(10), 4).Create()
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer local.Close()
+
+	for i := 0; i < 20; i++ {
+		local.Set(fmt.Sprintf("key%d", i), i, 1)
+	}
+	for i := 0; i < 20; i++ {
+		if value, found := local.Get(fmt.Sprintf("key%d", i)); !found || value != i {
+			t.Fatalf("Expected %d, got %v (found=%v)", i, value, found)
+		}
+	}
+	local.Delete("key0")
+	if _, found := local.Get("key0"); found {
+		t.Fatal("Expected a miss after Delete")
+	}
+
+	keys := local.(IterableLocalCache).Keys()
+	if len(keys) != 19 || slices.Contains(keys, "key0") {
+		t.Fatalf("Expected the 19 remaining keys, got %v", keys)
+	}
+	var visited int
+	local.(IterableLocalCache).Iterate(func(string, any) bool {
+		visited++
+		return visited < 3
+	})
+	if visited != 3 {
+		t.Fatalf("Expected Iterate to stop after 3 entries, visited %d", visited)
+	}
+
+	metrics := local.Metrics()
+	if metrics.Hits != 20 || metrics.Misses != 1 || metrics.Size != 40 || metrics.Items != 19 {
+		t.Fatalf("Unexpected metrics: %+v", metrics)
+	}
+
+	local.(ExpiringLocalCache).SetWithTTL("short", "value", 1, 20*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	if _, found := local.Get("short"); found {
+		t.Fatal("Expected the value to expire")
+	}
+
+	local.Clear()
+	if items := local.Metrics().Items; items != 0 {
+		t.Fatalf("Expected no items after Clear, got %d", items)
+	}
+}
+
+func TestShardedLocalCacheConcurrentWrites(t *testing.T) {
+	local, err := NewShardedLocalCache(NewLRUCacheFactory(1000), 8)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer local.Close()
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				key := fmt.Sprintf("w%d:%d", w, i)
+				local.Set(key, i, 1)
+				local.Get(key)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if items := local.Metrics().Items; items != 800 {
+		t.Fatalf("Expected 800 items, got %d", items)
+	}
+}
+
+func TestNewShardedLocalCacheInvalid(t *testing.T) {
+	if _, err := NewShardedLocalCache(NewLRUCacheFactory(10), 0); err != ErrInvalidConfig {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestLocalCacheConfigPerShard(t *testing.T) {
+	config := LocalCacheConfig{NumCounters: 1000, MaxCost: 1 << 20, BufferItems: 64, MaxSize: 10}
+	shard := config.perShard(16)
+	if shard.NumCounters != 62 || shard.MaxCost != 1<<16 || shard.MaxSize != 1 || shard.BufferItems != 64 {
+		t.Fatalf("Expected the capacity divided among 16 shards, got %+v", shard)
+	}
+	if shard := (LocalCacheConfig{}).perShard(16); shard != (LocalCacheConfig{}) {
+		t.Fatalf("Expected unset limits to stay unset, got %+v", shard)
+	}
+}
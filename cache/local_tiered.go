@@ -0,0 +1,177 @@
+package cache
+
+import "time"
+
+// defaultSpilloverThreshold is the cost from which values spill over to disk
+// when Options.SpilloverThreshold is 0.
+const defaultSpilloverThreshold = 1 << 20 // 1MB
+
+// defaultSpilloverMaxBytes bounds the disk used for spilled values when
+// Options.SpilloverMaxBytes is 0.
+const defaultSpilloverMaxBytes = 1 << 30 // 1GB
+
+// TieredLocalCacheFactory creates tiered local cache instances.
+type TieredLocalCacheFactory struct {
+	memory    LocalCacheFactory
+	disk      LocalCacheFactory
+	threshold int64
+}
+
+// NewTieredLocalCacheFactory creates a factory of caches keeping values costing
+// at least threshold in a cache created by disk, and the others in a cache
+// created by memory.
+func NewTieredLocalCacheFactory(memory, disk LocalCacheFactory, threshold int64) LocalCacheFactory {
+	return &TieredLocalCacheFactory{memory: memory, disk: disk, threshold: threshold}
+}
+
+// Create creates a new tiered local cache instance.
+func (tcf *TieredLocalCacheFactory) Create() (LocalCache, error) {
+	memory, err := tcf.memory.Create()
+	if err != nil {
+		return nil, err
+	}
+	disk, err := tcf.disk.Create()
+	if err != nil {
+		memory.Close()
+		return nil, err
+	}
+	return NewTieredLocalCache(memory, disk, tcf.threshold), nil
+}
+
+// TieredLocalCache is a local cache in two tiers: values costing less than a
+// threshold are held by an in-memory cache, bigger ones spill over to a second
+// cache, typically a DiskCache. Gets try the memory tier first, then the
+// spillover tier, before the SyncedCache falls through to Redis.
+//
+// It implements AdmittingLocalCache and ExpiringLocalCache on behalf of the tier
+// holding each value, falling back as ShardedLocalCache does.
+type TieredLocalCache struct {
+	memory    LocalCache
+	spill     LocalCache
+	threshold int64
+}
+
+// NewTieredLocalCache creates a local cache holding values costing at least
+// threshold in spill and the others in memory.
+func NewTieredLocalCache(memory, spill LocalCache, threshold int64) *TieredLocalCache {
+	return &TieredLocalCache{memory: memory, spill: spill, threshold: threshold}
+}
+
+// tier returns the cache holding values of the given cost, after removing the
+// key from the other tier so a value never lives in both.
+func (tc *TieredLocalCache) tier(key string, cost int64) LocalCache {
+	if cost >= tc.threshold {
+		tc.memory.Delete(key)
+		return tc.spill
+	}
+	tc.spill.Delete(key)
+	return tc.memory
+}
+
+// Get retrieves a value from the memory tier, then from the spillover tier.
+func (tc *TieredLocalCache) Get(key string) (any, bool) {
+	if value, found := tc.memory.Get(key); found {
+		return value, true
+	}
+	return tc.spill.Get(key)
+}
+
+// Set stores a value in the tier matching its cost.
+func (tc *TieredLocalCache) Set(key string, value any, cost int64) bool {
+	return tc.tier(key, cost).Set(key, value, cost)
+}
+
+// SetWithTTL stores a value that expires after ttl in the tier matching its cost.
+func (tc *TieredLocalCache) SetWithTTL(key string, value any, cost int64, ttl time.Duration) bool {
+	tier := tc.tier(key, cost)
+	if expiring, ok := tier.(ExpiringLocalCache); ok {
+		return expiring.SetWithTTL(key, value, cost, ttl)
+	}
+	return tier.Set(key, value, cost)
+}
+
+// Wait blocks until buffered Sets have been applied on both tiers.
+func (tc *TieredLocalCache) Wait() {
+	for _, tier := range []LocalCache{tc.memory, tc.spill} {
+		if admitting, ok := tier.(AdmittingLocalCache); ok {
+			admitting.Wait()
+		}
+	}
+}
+
+// Contains reports whether key is present in either tier.
+func (tc *TieredLocalCache) Contains(key string) bool {
+	for _, tier := range []LocalCache{tc.memory, tc.spill} {
+		if admitting, ok := tier.(AdmittingLocalCache); ok {
+			if admitting.Contains(key) {
+				return true
+			}
+		} else if _, found := tier.Get(key); found {
+			return true
+		}
+	}
+	return false
+}
+
+// ForceSet stores a value in the tier matching its cost, working around its
+// admission policy where possible.
+func (tc *TieredLocalCache) ForceSet(key string, value any, cost int64) bool {
+	tier := tc.tier(key, cost)
+	if admitting, ok := tier.(AdmittingLocalCache); ok {
+		return admitting.ForceSet(key, value, cost)
+	}
+	return tier.Set(key, value, cost)
+}
+
+// OnEvict registers fn with the tiers implementing EvictingLocalCache. Values
+// moving between tiers are not reported as evicted.
+func (tc *TieredLocalCache) OnEvict(fn func(key string)) {
+	for _, tier := range []LocalCache{tc.memory, tc.spill} {
+		if evicting, ok := tier.(EvictingLocalCache); ok {
+			evicting.OnEvict(fn)
+		}
+	}
+}
+
+// Delete removes a value from both tiers.
+func (tc *TieredLocalCache) Delete(key string) {
+	tc.memory.Delete(key)
+	tc.spill.Delete(key)
+}
+
+// Clear removes all values from both tiers.
+func (tc *TieredLocalCache) Clear() {
+	tc.memory.Clear()
+	tc.spill.Clear()
+}
+
+// Close closes both tiers.
+func (tc *TieredLocalCache) Close() {
+	tc.memory.Close()
+	tc.spill.Close()
+}
+
+// Metrics returns the sum of the metrics of both tiers. Every memory miss is
+// retried on the spillover tier, so misses are those of the spillover tier.
+func (tc *TieredLocalCache) Metrics() LocalCacheMetrics {
+	memory, spill := tc.memory.Metrics(), tc.spill.Metrics()
+	return LocalCacheMetrics{
+		Hits:        memory.Hits + spill.Hits,
+		Misses:      spill.Misses,
+		Evictions:   memory.Evictions + spill.Evictions,
+		Size:        memory.Size + spill.Size,
+		CostAdded:   memory.CostAdded + spill.CostAdded,
+		CostEvicted: memory.CostEvicted + spill.CostEvicted,
+		Items:       memory.Items + spill.Items,
+	}
+}
+
+// MemoryMetrics returns the metrics of the memory tier.
+func (tc *TieredLocalCache) MemoryMetrics() LocalCacheMetrics {
+	return tc.memory.Metrics()
+}
+
+// SpillMetrics returns the metrics of the spillover tier.
+func (tc *TieredLocalCache) SpillMetrics() LocalCacheMetrics {
+	return tc.spill.Metrics()
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTieredLocalCache(t *testing.T) {
+	factory := NewTieredLocalCacheFactory(NewLRUCacheFactory(10), NewDiskCacheFactory(t.TempDir(), 1<<20, nil), 100)
+	local, err := factory.Create()
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer local.Close()
+	tiered := local.(*TieredLocalCache)
+
+	big := strings.Repeat("x", 200)
+	local.Set("small", "value", 5)
+	local.Set("big", big, 202)
+	if value, found := local.Get("small"); !found || value != "value" {
+		t.Fatalf("Expected value, got %v (found=%v)", value, found)
+	}
+	if value, found := local.Get("big"); !found || value != big {
+		t.Fatalf("Expected the big value from disk, got %v (found=%v)", value, found)
+	}
+	if items := tiered.SpillMetrics().Items; items != 1 {
+		t.Fatalf("Expected 1 spilled value, got %d", items)
+	}
+
+	// A value moving between tiers must not be left behind in the other one
+	local.Set("big", "now small", 9)
+	if items := tiered.SpillMetrics().Items; items != 0 {
+		t.Fatalf("Expected no spilled value, got %d", items)
+	}
+	if value, _ := local.Get("big"); value != "now small" {
+		t.Fatalf("Expected the new value, got %v", value)
+	}
+	if !tiered.Contains("small") || tiered.Contains("missing") {
+		t.Fatal("Unexpected Contains result")
+	}
+
+	local.Delete("small")
+	if _, found := local.Get("small"); found {
+		t.Fatal("Expected a miss after Delete")
+	}
+	metrics := local.Metrics()
+	if metrics.Hits != 4 || metrics.Misses != 2 || metrics.Items != 1 {
+		t.Fatalf("Unexpected metrics: %+v", metrics)
+	}
+}
+
+func TestSpilloverThroughCache(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	disk, err := NewDiskCache(t.TempDir(), 1<<20, nil)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	tiered := NewTieredLocalCache(c.local, disk, 100)
+	c.local = tiered
+	defer tiered.Close()
+	ctx := context.Background()
+
+	big := strings.Repeat("x", 200)
+	if err := c.Set(ctx, "big", big); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if value, found := c.Get(ctx, "big"); !found || value != big {
+		t.Fatalf("Expected the big value, got %v (found=%v)", value, found)
+	}
+	if items := tiered.SpillMetrics().Items; items != 1 {
+		t.Fatalf("Expected the big value on disk, got %d spilled values", items)
+	}
+	if keys := c.LocalKeys(); len(keys) != 1 || keys[0] != "big" {
+		t.Fatalf("Expected [big], got %v", keys)
+	}
+}
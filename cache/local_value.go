@@ -0,0 +1,66 @@
+package cache
+
+// localValue returns the form of value of key, serialized as data, held by the
+// local cache under Options.LocalValueMode.
+func (sc *SyncedCache) localValue(key string, value any, data []byte) any {
+	if sc.options.LocalValueMode == LocalValueRaw {
+		return sc.payload(key, data)
+	}
+	return value
+}
+
+// payload returns the serialized value held by data, a payload of key, without
+// its envelope or the headers of its schema version and Marshaller.
+func (sc *SyncedCache) payload(key string, data []byte) []byte {
+	_, _, _, body := sc.open(sc.serializer, key, data)
+	return body
+}
+
+// decodeLocal decodes data of key, read from Redis or received from another
+// pod, into the value held by the local cache: into the type registered in
+// Options.TypeRegistry or returned by Options.DecodeInto, if any, or else into
+// an any. Under LocalValueRaw the serialized value is held without decoding it.
+//
+// Payloads written with a MarshallerRules Marshaller are decoded with it rather
+// than with serializer.
+func (sc *SyncedCache) decodeLocal(serializer Marshaller, key string, data []byte) (any, error) {
+	serializer, version, versioned, body := sc.open(serializer, key, data)
+	if sc.options.LocalValueMode == LocalValueRaw {
+		return body, nil
+	}
+	start := sc.startTimer()
+	defer sc.latencies.unmarshal.observe(start)
+
+	if s := sc.options.TypeRegistry.lookup(key); s != nil {
+		return sc.decodeSchema(s, serializer, key, version, versioned, body)
+	}
+	if sc.options.DecodeInto != nil {
+		if target := sc.options.DecodeInto(key); target != nil {
+			return decodeInto(serializer, body, target)
+		}
+	}
+	var value any
+	err := serializer.Unmarshal(body, &value)
+	return value, err
+}
+
+// marshalLocal returns the serialized form of a value of key held by the local
+// cache, which is the value itself under LocalValueRaw.
+func (sc *SyncedCache) marshalLocal(key string, value any) ([]byte, error) {
+	if data, ok := value.([]byte); ok && sc.options.LocalValueMode == LocalValueRaw {
+		return data, nil
+	}
+	serializer, _ := sc.marshallerFor(key)
+	return serializer.Marshal(value)
+}
+
+// encodeLocal returns the payload of a value of key held by the local cache, as
+// encode would have written it. Under LocalValueRaw the held serialized value is
+// wrapped as it is.
+func (sc *SyncedCache) encodeLocal(key string, value any) ([]byte, error) {
+	if data, ok := value.([]byte); ok && sc.options.LocalValueMode == LocalValueRaw {
+		_, id := sc.marshallerFor(key)
+		return sc.wrap(key, id, data), nil
+	}
+	return sc.encode(key, value)
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSyncedCacheLocalValueRaw(t *testing.T) {
+	ctx := context.Background()
+	c := newMockedCache(t, Options{PodID: "pod-1", LocalValueMode: LocalValueRaw})
+	defer c.Close()
+	c.synchronizer = &publishingSynchronizer{}
+	c.store = &valueStore{values: map[string][]byte{"remote": []byte(`{"name": "carol"}`)}}
+
+	if err := c.Set(ctx, "set", map[string]any{"name": "alice"}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	c.applyEvent(InvalidationEvent{Key: "propagated", Sender: "pod-2", Action: ActionSet, Value: []byte(`{"name": "bob"}`)})
+
+	for key, want := range map[string]string{
+		"set":        `{"name":"alice"}`,
+		"propagated": `{"name": "bob"}`,
+		"remote":     `{"name": "carol"}`,
+	} {
+		value, found := c.Get(ctx, key)
+		if data, ok := value.([]byte); !found || !ok || string(data) != want {
+			t.Errorf("Expected %s held as bytes, got %#v (found=%v)", key, value, found)
+		}
+		if raw, found := c.GetRaw(ctx, key); !found || string(raw) != want {
+			t.Errorf("Expected GetRaw to serve the held bytes of %s, got %s", key, raw)
+		}
+	}
+	if local, _ := c.local.Get("remote"); local == nil {
+		t.Error("Expected the remote hit to populate the local cache")
+	}
+}
+
+func TestSyncedCacheLocalValueBoth(t *testing.T) {
+	ctx := context.Background()
+	c := newMockedCache(t, Options{LocalValueMode: LocalValueBoth})
+	defer c.Close()
+	c.entryInfos.keepRaw = true // as set by New for LocalValueBoth
+
+	received := []byte(`{"name": "bob"}`)
+	c.applyEvent(InvalidationEvent{Key: "user:1", Sender: "pod-2", Action: ActionSet, Value: received})
+	value, found := c.Get(ctx, "user:1")
+	if decoded, ok := value.(map[string]any); !found || !ok || decoded["name"] != "bob" {
+		t.Fatalf("Expected the decoded value, got %#v", value)
+	}
+	if raw, _ := c.GetRaw(ctx, "user:1"); string(raw) != string(received) {
+		t.Errorf("Expected the received bytes to be kept, got %s", raw)
+	}
+}
+
+type decodedUser struct {
+	Name string `json:"name"`
+}
+
+func TestSyncedCacheDecodeInto(t *testing.T) {
+	ctx := context.Background()
+	c := newMockedCache(t, Options{DecodeInto: func(key string) any {
+		if strings.HasPrefix(key, "user:") {
+			return &decodedUser{}
+		}
+		return nil
+	}})
+	defer c.Close()
+	c.store = &valueStore{values: map[string][]byte{"user:1": []byte(`{"name": "alice"}`)}}
+
+	c.applyEvent(InvalidationEvent{Key: "user:2", Sender: "pod-2", Action: ActionSet, Value: []byte(`{"name": "bob"}`)})
+	c.applyEvent(InvalidationEvent{Key: "other", Sender: "pod-2", Action: ActionSet, Value: []byte(`{"name": "carol"}`)})
+
+	for key, want := range map[string]string{"user:1": "alice", "user:2": "bob"} {
+		value, found := c.Get(ctx, key)
+		if user, ok := value.(decodedUser); !found || !ok || user.Name != want {
+			t.Errorf("Expected %s decoded as decodedUser, got %#v", key, value)
+		}
+	}
+	if value, _ := c.Get(ctx, "other"); reflect.TypeOf(value) != reflect.TypeOf(map[string]any{}) {
+		t.Errorf("Expected keys without a type decoded into an any, got %#v", value)
+	}
+
+	c.applyEvent(InvalidationEvent{Key: "user:3", Sender: "pod-2", Action: ActionSet, Value: []byte(`[1]`)})
+	if _, found := c.Get(ctx, "user:3"); found {
+		t.Error("Expected a value not matching the type to be dropped")
+	}
+}
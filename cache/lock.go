@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// lockRetryInterval is how often Lock retries a lock held by another owner.
+const lockRetryInterval = 50 * time.Millisecond
+
+// reservedLockPrefix starts the keys of the locks the cache takes for itself,
+// such as the leader lease. Lock returns ErrReservedLockKey for them.
+const reservedLockPrefix = "\x00"
+
+// Lock is a distributed lock on a key, obtained from Cache.Lock.
+type Lock interface {
+	// Key returns the locked key.
+	Key() string
+
+	// Unlock releases the lock. It returns ErrLockNotHeld if the lock expired
+	// before being released, in which case another owner may have acquired it.
+	Unlock(ctx context.Context) error
+}
+
+// storeLock is a Lock held in a LockingStore.
+type storeLock struct {
+	store    LockingStore
+	key      string
+	token    string
+	released int32
+}
+
+// Key returns the locked key.
+func (l *storeLock) Key() string {
+	return l.key
+}
+
+// Unlock releases the lock. Releasing a lock more than once is a no-op.
+func (l *storeLock) Unlock(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&l.released, 0, 1) {
+		return nil
+	}
+	released, err := l.store.Unlock(ctx, l.key, l.token)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Lock acquires a distributed lock on key, held for at most ttl, so writers on
+// different pods can serialize read-modify-write updates to the same key.
+// It blocks until the lock is acquired or ctx is done, in which case it returns
+// ErrLockNotAcquired. The lock expires after ttl even if Unlock is never called,
+// so ttl should exceed the time needed to complete the update. Keys starting
+// with a NUL byte are reserved for the cache and rejected with ErrReservedLockKey.
+func (sc *SyncedCache) Lock(ctx context.Context, key string, ttl time.Duration) (Lock, error) {
+	if atomic.LoadInt32(&sc.closed) != 0 {
+		return nil, ErrCacheClosed
+	}
+	if ttl <= 0 {
+		return nil, ErrInvalidLockTTL
+	}
+	if strings.HasPrefix(key, reservedLockPrefix) {
+		return nil, ErrReservedLockKey
+	}
+	store, ok := sc.store.(LockingStore)
+	if !ok {
+		return nil, ErrLockingNotSupported
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	ticker := time.NewTicker(lockRetryInterval)
+	defer ticker.Stop()
+	for {
+		acquired, err := store.TryLock(ctx, key, token, ttl)
+		if err != nil && ctx.Err() == nil {
+			return nil, err
+		}
+		if acquired {
+			if sc.debugging(DebugOps) {
+				sc.logger.Debug("Lock: acquired", "key", key, "ttl", ttl)
+			}
+			return &storeLock{store: store, key: key, token: token}, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ErrLockNotAcquired
+		case <-ticker.C:
+		}
+	}
+}
+
+// newLockToken returns a random token identifying a single lock owner.
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ErrLockNotAcquired is returned by Lock when the lock is still held by another
+// owner once the context is done.
+var ErrLockNotAcquired = NewError("lock not acquired")
+
+// ErrLockNotHeld is returned by Unlock when the lock expired before it was released.
+var ErrLockNotHeld = NewError("lock not held")
+
+// ErrInvalidLockTTL is returned by Lock when the ttl is not positive.
+var ErrInvalidLockTTL = NewError("lock ttl must be positive")
+
+// ErrReservedLockKey is returned by Lock for keys starting with a NUL byte, which
+// are reserved for the locks the cache takes for itself.
+var ErrReservedLockKey = NewError("lock key is reserved")
+
+// ErrLockingNotSupported is returned by Lock when the store does not implement LockingStore.
+var ErrLockingNotSupported = NewError("store does not support locking")
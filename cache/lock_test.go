@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockingStore is a store holding locks in memory.
+type lockingStore struct {
+	errorStore
+	mu    sync.Mutex
+	locks map[string]string
+}
+
+func (ls *lockingStore) TryLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if _, held := ls.locks[key]; held {
+		return false, nil
+	}
+	ls.locks[key] = token
+	return true, nil
+}
+
+func (ls *lockingStore) Unlock(ctx context.Context, key, token string) (bool, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if ls.locks[key] != token {
+		return false, nil
+	}
+	delete(ls.locks, key)
+	return true, nil
+}
+
+func (ls *lockingStore) ExtendLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	return ls.locks[key] == token, nil
+}
+
+func TestSyncedCacheLock(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	c.store = &lockingStore{locks: make(map[string]string)}
+	defer c.Close()
+
+	ctx := context.Background()
+	lock, err := c.Lock(ctx, "counter", time.Second)
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	if lock.Key() != "counter" {
+		t.Fatalf("Expected lock on counter, got %q", lock.Key())
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 2*lockRetryInterval)
+	defer cancel()
+	if _, err := c.Lock(waitCtx, "counter", time.Second); err != ErrLockNotAcquired {
+		t.Fatalf("Expected ErrLockNotAcquired while held, got %v", err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		second, err := c.Lock(ctx, "counter", time.Second)
+		if err == nil {
+			err = second.Unlock(ctx)
+		}
+		acquired <- err
+	}()
+
+	if err := lock.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := lock.Unlock(ctx); err != nil {
+		t.Fatalf("Second Unlock should be a no-op, got %v", err)
+	}
+
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("Waiting Lock failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Waiting Lock was not acquired after Unlock")
+	}
+}
+
+func TestSyncedCacheUnlockExpired(t *testing.T) {
+	store := &lockingStore{locks: make(map[string]string)}
+	c := newMockedCache(t, Options{})
+	c.store = store
+	defer c.Close()
+
+	ctx := context.Background()
+	lock, err := c.Lock(ctx, "counter", time.Second)
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+
+	// Simulate the lock expiring and being taken by another owner
+	store.locks["counter"] = "other"
+	if err := lock.Unlock(ctx); err != ErrLockNotHeld {
+		t.Fatalf("Expected ErrLockNotHeld, got %v", err)
+	}
+}
+
+func TestSyncedCacheLockErrors(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	ctx := context.Background()
+
+	if _, err := c.Lock(ctx, "counter", time.Second); err != ErrLockingNotSupported {
+		t.Fatalf("Expected ErrLockingNotSupported, got %v", err)
+	}
+
+	c.store = &lockingStore{locks: make(map[string]string)}
+	if _, err := c.Lock(ctx, "counter", 0); err != ErrInvalidLockTTL {
+		t.Fatalf("Expected ErrInvalidLockTTL, got %v", err)
+	}
+
+	c.Close()
+	if _, err := c.Lock(ctx, "counter", time.Second); err != ErrCacheClosed {
+		t.Fatalf("Expected ErrCacheClosed, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+package cache
+
+import "sort"
+
+// codecMagic prefixes payloads written by a MarshallerRules Marshaller, followed
+// by its KeyMarshaller.ID. It is never produced by JSON; MessagePack only
+// produces it for the integer -10, which never has a byte after it.
+const codecMagic byte = 0xF6
+
+// KeyMarshaller is the Marshaller of a MarshallerRules pattern.
+type KeyMarshaller struct {
+	// ID identifies the Marshaller in the header of the payloads it writes, so
+	// every pod decodes them with it whatever the rules of the key it runs with.
+	// It must not be 0, and patterns sharing an ID must share the Marshaller.
+	ID byte
+
+	// Marshaller serializes the values of matching keys.
+	Marshaller Marshaller
+}
+
+// marshallerRule is a MarshallerRules entry.
+type marshallerRule struct {
+	pattern    string
+	marshaller KeyMarshaller
+}
+
+// keyMarshallers holds the MarshallerRules, ordered from the longest pattern to
+// the shortest, and their Marshallers by ID.
+type keyMarshallers struct {
+	rules []marshallerRule
+	byID  map[byte]Marshaller
+}
+
+// newKeyMarshallers returns the Marshallers of rules.
+func newKeyMarshallers(rules map[string]KeyMarshaller) keyMarshallers {
+	km := keyMarshallers{byID: make(map[byte]Marshaller, len(rules))}
+	for pattern, marshaller := range rules {
+		km.rules = append(km.rules, marshallerRule{pattern: pattern, marshaller: marshaller})
+	}
+	sort.Slice(km.rules, func(i, j int) bool {
+		if len(km.rules[i].pattern) != len(km.rules[j].pattern) {
+			return len(km.rules[i].pattern) > len(km.rules[j].pattern)
+		}
+		return km.rules[i].pattern < km.rules[j].pattern
+	})
+	for _, rule := range km.rules {
+		if _, ok := km.byID[rule.marshaller.ID]; !ok {
+			km.byID[rule.marshaller.ID] = rule.marshaller.Marshaller
+		}
+	}
+	return km
+}
+
+// marshallerFor returns the Marshaller of the longest rule pattern matching key
+// and its ID, or the default Marshaller and 0 when no rule matches.
+func (sc *SyncedCache) marshallerFor(key string) (Marshaller, byte) {
+	for _, rule := range sc.codecs.rules {
+		if MatchPattern(rule.pattern, key) {
+			return rule.marshaller.Marshaller, rule.marshaller.ID
+		}
+	}
+	return sc.serializer, 0
+}
+
+// splitCodec returns the Marshaller decoding data and the serialized value it
+// holds: the Marshaller named by its header, or serializer when it has none.
+func (sc *SyncedCache) splitCodec(serializer Marshaller, data []byte) (Marshaller, []byte) {
+	if len(data) < 2 || data[0] != codecMagic {
+		return serializer, data
+	}
+	if marshaller, ok := sc.codecs.byID[data[1]]; ok {
+		return marshaller, data[2:]
+	}
+	return serializer, data
+}
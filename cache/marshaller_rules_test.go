@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestSyncedCacheMarshallerRules(t *testing.T) {
+	ctx := context.Background()
+	types := NewTypeRegistry()
+	types.Register("feed:", &wrapperspb.StringValue{}, 1)
+	rules := map[string]KeyMarshaller{"feed:*": {ID: 7, Marshaller: NewProtoMarshaller(nil)}}
+
+	pod1 := newMockedCache(t, Options{PodID: "pod-1", TypeRegistry: types, MarshallerRules: rules})
+	defer pod1.Close()
+	sync1 := &publishingSynchronizer{}
+	pod1.synchronizer = sync1
+	if err := pod1.Set(ctx, "feed:1", wrapperspb.String("news")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := pod1.Set(ctx, "config:1", map[string]any{"debug": true}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if data := sync1.events[1].Value; string(data) != `{"debug":true}` {
+		t.Errorf("Expected keys without a rule to use the default Marshaller, got %q", data)
+	}
+
+	pod2 := newMockedCache(t, Options{PodID: "pod-2", TypeRegistry: types, MarshallerRules: rules})
+	defer pod2.Close()
+	for _, event := range sync1.events {
+		pod2.applyEvent(event)
+	}
+	if value, _ := pod2.Get(ctx, "feed:1"); value.(*wrapperspb.StringValue).GetValue() != "news" {
+		t.Errorf("Expected the feed decoded with its rule's Marshaller, got %#v", value)
+	}
+	if value, _ := pod2.Get(ctx, "config:1"); value.(map[string]any)["debug"] != true {
+		t.Errorf("Expected the config decoded as JSON, got %#v", value)
+	}
+
+	// GetRaw serves the protobuf bytes without their headers
+	raw, _ := pod2.GetRaw(ctx, "feed:1")
+	var msg wrapperspb.StringValue
+	if err := NewProtoMarshaller(nil).Unmarshal(raw, &msg); err != nil || msg.GetValue() != "news" {
+		t.Errorf("Expected GetRaw to serve the protobuf bytes, got %q (err=%v)", raw, err)
+	}
+}
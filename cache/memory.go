@@ -0,0 +1,88 @@
+package cache
+
+import "strings"
+
+// MemoryEstimate is the approximate memory held by this pod's local cache, as
+// reported by EstimateMemory.
+type MemoryEstimate struct {
+	// Entries is the number of local entries.
+	Entries int64 `json:"entries"`
+
+	// Bytes is the estimated size of the entries: the length of their keys plus
+	// the length of their serialized values. Decoded values usually take more
+	// heap than their serialized form, so treat it as a lower bound.
+	Bytes int64 `json:"bytes"`
+
+	// Cost is the local cache cost of the entries as computed by Options.CostFunc,
+	// to compare with Bytes when the cost is not a size (e.g. 1 per entry).
+	Cost int64 `json:"cost"`
+
+	// Sampled is set when only a sample of the entries was measured, and Bytes
+	// and Cost were scaled from it.
+	Sampled bool `json:"sampled,omitempty"`
+
+	// Prefixes breaks the estimate down by key prefix: the part of the key up to
+	// and including its first ':', e.g. "user:", or "" for keys without one.
+	Prefixes map[string]PrefixMemory `json:"prefixes"`
+}
+
+// PrefixMemory is the estimated memory held by the local entries of a key prefix.
+type PrefixMemory struct {
+	Entries int64 `json:"entries"`
+	Bytes   int64 `json:"bytes"`
+	Cost    int64 `json:"cost"`
+}
+
+// add accounts for an entry of key. Entries measured in a sample of one in rate
+// pass their size and cost scaled by rate; the others pass zeros.
+func (m *MemoryEstimate) add(key string, bytes, cost int64) {
+	prefix := ""
+	if i := strings.IndexByte(key, ':'); i >= 0 {
+		prefix = key[:i+1]
+	}
+	p := m.Prefixes[prefix]
+	p.Entries++
+	p.Bytes += bytes
+	p.Cost += cost
+	m.Prefixes[prefix] = p
+
+	m.Entries++
+	m.Bytes += bytes
+	m.Cost += cost
+}
+
+// EstimateMemory walks this pod's local cache and estimates the memory held by
+// its entries per key prefix, for when LocalCacheConfig.MaxCost does not track
+// memory because CostFunc is not a size. Values are measured by their kept raw
+// bytes (see Options.KeepRawBytes) or by marshalling them again, so a full walk
+// of a large cache is expensive: with sampleRate above 1, only one entry in
+// sampleRate is measured and its size and cost are scaled by sampleRate.
+//
+// Costs set with WithCost are not tracked and are reported as CostFunc computes them.
+func (sc *SyncedCache) EstimateMemory(sampleRate int) MemoryEstimate {
+	rate := int64(max(sampleRate, 1))
+	estimate := MemoryEstimate{
+		Sampled:  rate > 1,
+		Prefixes: make(map[string]PrefixMemory),
+	}
+
+	var seen int64
+	sc.IterateLocal(func(key string, value any) bool {
+		seen++
+		if (seen-1)%rate != 0 {
+			estimate.add(key, 0, 0)
+			return true
+		}
+		data, ok := sc.entryInfos.raw(key)
+		if !ok {
+			var err error
+			if data, err = sc.marshalLocal(key, value); err != nil {
+				// Values transformed by OnSetLocalCache may not be serializable
+				data = nil
+			}
+		}
+		estimate.add(key, int64(len(key)+len(data))*rate, sc.cost(key, value, data)*rate)
+		return true
+	})
+	return estimate
+}
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSyncedCacheEstimateMemory(t *testing.T) {
+	sc := newMockedCache(t, Options{CostFunc: func(string, any, []byte) int64 { return 1 }})
+	defer sc.Close()
+	ctx := context.Background()
+
+	_ = sc.Set(ctx, "user:1", "alice")  // 6 + 7 bytes
+	_ = sc.Set(ctx, "user:2", "bob")    // 6 + 5 bytes
+	_ = sc.Set(ctx, "config", "abcdef") // 6 + 8 bytes
+
+	estimate := sc.EstimateMemory(0)
+	if estimate.Entries != 3 || estimate.Bytes != 38 || estimate.Cost != 3 || estimate.Sampled {
+		t.Fatalf("Expected 3 entries of 38 bytes at a cost of 3, got %+v", estimate)
+	}
+	if user := estimate.Prefixes["user:"]; user != (PrefixMemory{Entries: 2, Bytes: 24, Cost: 2}) {
+		t.Errorf("Expected the user: entries grouped, got %+v", user)
+	}
+	if other := estimate.Prefixes[""]; other != (PrefixMemory{Entries: 1, Bytes: 14, Cost: 1}) {
+		t.Errorf("Expected keys without prefix grouped under \"\", got %+v", other)
+	}
+
+	sampled := sc.EstimateMemory(3)
+	if !sampled.Sampled || sampled.Entries != 3 || sampled.Cost != 3 || sampled.Bytes%3 != 0 {
+		t.Errorf("Expected one entry measured and scaled by 3, got %+v", sampled)
+	}
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDisableRemoteStore(t *testing.T) {
+	sc := newMockedCache(t, Options{DisableRemoteStore: true})
+	defer sc.Close()
+	storeErr := errors.New("store must not be used")
+	sc.store = &errorStore{getError: storeErr, setError: storeErr, deleteError: storeErr, clearError: storeErr}
+	publisher := &publishingSynchronizer{}
+	sc.synchronizer = publisher
+	ctx := context.Background()
+
+	if err := sc.Set(ctx, "user:1", "alice"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := sc.Delete(ctx, "user:1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if len(publisher.events) != 2 || publisher.events[0].Action != ActionSet || publisher.events[1].Action != ActionDelete {
+		t.Fatalf("Expected a set and a delete event, got %+v", publisher.events)
+	}
+
+	// A local miss goes straight to the loader
+	sc.RegisterLoader("user:*", func(ctx context.Context, key string) (any, error) {
+		return "loaded", nil
+	})
+	if value, found := sc.Get(ctx, "user:2"); !found || value != "loaded" {
+		t.Fatalf("Expected the loaded value, got %v (found=%v)", value, found)
+	}
+	if _, found := sc.Get(ctx, "other:1"); found {
+		t.Error("Expected a miss for a key without loader")
+	}
+
+	if err := sc.Clear(ctx); err != nil {
+		t.Errorf("Clear failed: %v", err)
+	}
+	if _, err := sc.Version(ctx, "user:1"); !errors.Is(err, ErrVersioningNotSupported) {
+		t.Errorf("Expected ErrVersioningNotSupported, got %v", err)
+	}
+}
@@ -1,7 +1,20 @@
 package cache
 
 import (
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
 	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	cachesync "github.com/huykn/distributed-cache/sync"
 )
 
 // LocalCacheConfig configures the local cache.
@@ -22,9 +35,68 @@ type LocalCacheConfig struct {
 	IgnoreInternalCost bool
 
 	// MaxSize is the maximum number of items in the cache (LRU only).
+	// It also bounds how many entries have metadata tracked for GetWithInfo.
+	// Entries that expire, see LocalTTL, are dropped from the local cache when
+	// their metadata is, as their expiry is no longer known.
 	MaxSize int
 }
 
+// RejectedSetPolicy selects how the cache reacts when the local cache rejects
+// or drops a Set, which Ristretto does under buffer contention or when its
+// admission policy considers a new key not worth keeping.
+type RejectedSetPolicy string
+
+const (
+	// RejectedSetLog counts Sets the local cache reports as dropped in
+	// Stats.RejectedSets and logs them in debug mode.
+	RejectedSetLog RejectedSetPolicy = "log"
+	// RejectedSetRetry waits for the local cache buffers to drain after each Set,
+	// verifies the value was admitted and retries once if it was not.
+	// This detects asynchronous drops at the cost of a slower Set.
+	RejectedSetRetry RejectedSetPolicy = "retry"
+	// RejectedSetForcePropagated behaves like RejectedSetRetry and additionally
+	// forces admission of values received via propagation events, so hot values
+	// pushed by other pods are not silently discarded.
+	RejectedSetForcePropagated RejectedSetPolicy = "force-propagated"
+)
+
+// LocalValueMode selects the form values take in the local cache.
+type LocalValueMode string
+
+const (
+	// LocalValueDecoded stores values decoded by the Marshaller, as Get returns them.
+	LocalValueDecoded LocalValueMode = "decoded"
+	// LocalValueRaw stores the serialized bytes of values, which Get returns as
+	// a []byte. Values read from Redis or received from other pods are not
+	// decoded, for pods serving cached bytes as-is.
+	LocalValueRaw LocalValueMode = "raw"
+	// LocalValueBoth stores decoded values and keeps their serialized bytes next
+	// to them, as with Options.KeepRawBytes, so GetRaw serves them as-is.
+	LocalValueBoth LocalValueMode = "both"
+)
+
+// SyncTransport selects how synchronization events are exchanged between pods.
+type SyncTransport string
+
+const (
+	// SyncTransportPubSub exchanges events over Redis Pub/Sub. Delivery is
+	// fire-and-forget: a pod misses the events published while it is down.
+	SyncTransportPubSub SyncTransport = "pubsub"
+	// SyncTransportStreams exchanges events over a Redis Stream with a consumer
+	// group per pod. A pod restarting with the same PodID replays the events it
+	// missed, so PodID must be stable across restarts (e.g. a StatefulSet name).
+	SyncTransportStreams SyncTransport = "streams"
+)
+
+// EventEncoding selects how synchronization events are encoded on the wire.
+type EventEncoding = cachesync.EventEncoding
+
+// Event encodings.
+const (
+	EventEncodingJSON   = cachesync.EventEncodingJSON
+	EventEncodingBinary = cachesync.EventEncodingBinary
+)
+
 // Options configures a SyncedCache instance.
 type Options struct {
 	// PodID is the unique identifier for this pod/instance.
@@ -38,18 +110,159 @@ type Options struct {
 	// If nil, defaults to Ristretto factory.
 	LocalCacheFactory LocalCacheFactory
 
+	// LocalCacheShards splits the local cache into this many caches created by
+	// LocalCacheFactory, selected by key hash, to reduce lock contention under
+	// concurrent writes. When LocalCacheFactory is nil, the capacity set by
+	// LocalCacheConfig is divided among the shards. A custom LocalCacheFactory
+	// creates every shard with its full configuration, so size it for one shard.
+	// When 0 or 1 (default), the local cache is not sharded.
+	LocalCacheShards int
+
+	// SpilloverDir enables a disk tier for big values: values costing at least
+	// SpilloverThreshold (default 1MB) are kept in files under this directory
+	// instead of in LocalCacheFactory's cache, using at most SpilloverMaxBytes
+	// (default 1GB). Costs come from CostFunc, by default the serialized size.
+	// Spilled values are decoded with Marshaller when read, like values fetched
+	// from Redis. When empty (default), all values are kept in memory.
+	SpilloverDir       string
+	SpilloverThreshold int64
+	SpilloverMaxBytes  int64
+
 	// RedisAddr is the Redis server address (e.g., "localhost:6379").
 	RedisAddr string
 
+	// RedisUsername is the optional Redis ACL username (Redis 6+). When empty,
+	// RedisPassword authenticates the default user.
+	RedisUsername string
+
 	// RedisPassword is the optional Redis password.
 	RedisPassword string
 
 	// RedisDB is the Redis database number.
 	RedisDB int
 
+	// RedisTLSConfig enables TLS for connections to Redis, as required by managed
+	// services such as ElastiCache with in-transit encryption or Azure Cache for Redis.
+	RedisTLSConfig *tls.Config
+
+	// RedisPoolSize is the maximum number of connections to Redis.
+	// When 0, the go-redis default of 10 connections per CPU is used.
+	RedisPoolSize int
+
+	// RedisMinIdleConns is the number of idle connections kept open to Redis.
+	RedisMinIdleConns int
+
+	// RedisDialTimeout bounds establishing a new connection. When 0, the go-redis default (5s) is used.
+	RedisDialTimeout time.Duration
+
+	// RedisReadTimeout bounds socket reads. When 0, the go-redis default (3s) is used.
+	RedisReadTimeout time.Duration
+
+	// RedisWriteTimeout bounds socket writes. When 0, it defaults to RedisReadTimeout.
+	RedisWriteTimeout time.Duration
+
+	// RedisClient is a pre-built Redis client, such as a *redis.ClusterClient or a
+	// failover client, used instead of RedisAddr, RedisPassword, RedisDB and the
+	// pool and timeout settings above. The cache does not close it.
+	RedisClient redis.UniversalClient
+
+	// ConnectionManager shares one Redis client and one Pub/Sub connection with
+	// the other caches created with the same manager, instead of opening
+	// connections per cache. It takes precedence over RedisAddr and RedisClient
+	// and cannot be combined with SyncRedisAddr or SyncRedisClient. Each cache
+	// sharing a manager needs its own InvalidationChannel and Namespace.
+	ConnectionManager *ConnectionManager
+
+	// RedisPartitions spreads keys over several Redis instances with consistent
+	// hashing, for datasets larger than one instance. Each address is reached with
+	// the credentials, TLS, pool and timeout settings above, and RedisAddr is
+	// ignored. Synchronization uses the first address unless SyncRedisAddr or
+	// SyncRedisClient is set. Every pod must list the same addresses. Cannot be
+	// combined with RedisClient or ConnectionManager, and SetIfVersion and
+	// ConsistencyOutbox are not supported across partitions.
+	RedisPartitions []string
+
+	// PartitionVNodes is the number of points each partition gets on the
+	// hash ring; more points spread keys more evenly. When 0, it defaults to 160.
+	PartitionVNodes int
+
+	// PartitionReplicas is the number of partitions each key is written to. Reads
+	// fall back to the next replica when a partition fails. When 0, it defaults to 1.
+	PartitionReplicas int
+
+	// Namespace is a prefix applied to every key stored in Redis. It scopes Clear
+	// to the keys of this cache, so applications sharing a Redis database cannot
+	// wipe each other's data. Without a Namespace, Clear flushes the entire Redis
+	// database as it always has.
+	Namespace string
+
+	// DangerousFullFlush makes Clear flush the entire Redis database with FLUSHDB
+	// even when Namespace is set, including keys written by other applications.
+	// Every such flush is logged as a warning.
+	DangerousFullFlush bool
+
+	// SyncRedisAddr moves synchronization (pub/sub or streams) to its own connection
+	// pool on this address, so a slow SUBSCRIBE or blocked connection cannot stall data
+	// operations. It may point to a replica of the data Redis, or equal RedisAddr for a
+	// separate pool on the same server. It reuses the credentials, TLS and pool settings.
+	// With ConsistencyOutbox, events are published on the data connection and must reach
+	// this address, so it must be the same server or one of its replicas.
+	SyncRedisAddr string
+
+	// SyncRedisClient is a pre-built client used for synchronization instead of
+	// SyncRedisAddr. The cache does not close it.
+	SyncRedisClient redis.UniversalClient
+
 	// InvalidationChannel is the Redis pub/sub channel for cache invalidation.
 	InvalidationChannel string
 
+	// SyncTransport selects the transport used for synchronization events.
+	// With SyncTransportStreams, InvalidationChannel is used as the stream key,
+	// trimmed to approximately StreamMaxLen entries (default 10000), and
+	// PrefixChannels is not supported. When empty, SyncTransportPubSub is used.
+	SyncTransport SyncTransport
+	StreamMaxLen  int64
+
+	// EventEncoding selects how published events are encoded: EventEncodingJSON
+	// (default) or the compact EventEncodingBinary, which does not base64 encode
+	// values. Received events are decoded whatever their encoding, so roll out a
+	// version with this option first and switch to binary once every pod runs it.
+	EventEncoding EventEncoding
+
+	// ReceiveOwnEvents makes the cache apply the events published by other caches
+	// with the same PodID, e.g. several caches in one process, instead of dropping
+	// them as its own. Events are then filtered by the random instance ID of each
+	// cache's synchronizer, so every cache sharing the PodID must set it. It cannot
+	// be used with SyncTransportStreams, whose consumer group is named after PodID.
+	ReceiveOwnEvents bool
+
+	// PrefixChannels maps key prefixes to dedicated pub/sub channels.
+	// Events for keys matching a prefix are published on the mapped channel instead of
+	// InvalidationChannel (longest prefix wins), and the cache subscribes to every mapped channel.
+	// This lets independent services sharing one Redis isolate their event traffic.
+	PrefixChannels map[string]string
+
+	// AdditionalChannels are channels Set, Delete and Clear also publish their
+	// events to, in the same round trip as InvalidationChannel, e.g. a global
+	// channel next to per-tenant ones, or one watched by audit or change data
+	// capture consumers that do not subscribe to the main channel. It cannot be
+	// used with ConsistencyOutbox or SyncTransportStreams.
+	AdditionalChannels []string
+
+	// PatternChannels are glob-style channel patterns, e.g. "cache:invalidate:*",
+	// the cache also subscribes to, receiving the events of several logical
+	// channels, such as one per namespace or tenant, through one subscription.
+	// Events keep the channel they were received on in InvalidationEvent.Channel,
+	// so OnSetLocalCache can route them. Patterns should not overlap each other.
+	PatternChannels []string
+
+	// ExternalFormats maps key prefixes to the encoding used by other systems writing
+	// those keys to Redis (longest prefix wins), enabling incremental adoption alongside
+	// legacy cache code. Matching keys are read through their ExternalFormat on a local miss and
+	// are never written or deleted in Redis by this cache; Set and Delete only update
+	// local caches and notify other pods.
+	ExternalFormats map[string]ExternalFormat
+
 	// SerializationFormat specifies how values are serialized ("json" or "msgpack").
 	SerializationFormat string
 
@@ -57,6 +270,21 @@ type Options struct {
 	// If nil, defaults to JSON marshaller.
 	Marshaller Marshaller
 
+	// PayloadEnvelope wraps the values written to Redis and pub/sub in an envelope
+	// recording their Marshaller, schema version, whether they are compressed, when
+	// they were written and by which pod, so GetWithInfo reports the CreatedAt and
+	// Origin of values whatever pod wrote them. Every pod reads enveloped values
+	// whatever its setting, so enable it once every pod runs a version reading them.
+	PayloadEnvelope bool
+
+	// MarshallerRules maps key patterns, where '*' matches any sequence of
+	// characters (e.g. "feed:*"), to the Marshaller serializing matching keys
+	// instead of Marshaller, such as protobuf for feeds and JSON for configuration.
+	// The longest matching pattern wins. Payloads carry the ID of their Marshaller
+	// and are decoded with it whatever the key, so add a rule to every pod before
+	// any pod writes with it.
+	MarshallerRules map[string]KeyMarshaller
+
 	// Logger is the logger for debug logging.
 	// If nil, defaults to no-op logger.
 	Logger Logger
@@ -64,21 +292,113 @@ type Options struct {
 	// DebugMode enables debug logging.
 	DebugMode bool
 
+	// DebugSampleRate is the fraction of Debug records logged in DebugMode, in (0, 1].
+	// Info, Warn and Error records are never sampled. 0 logs every record.
+	DebugSampleRate float64
+
+	// DebugCategories limits DebugMode logging to the listed categories
+	// (DebugOps, DebugSync, DebugSerialization). Empty logs every category.
+	DebugCategories []DebugCategory
+
 	// ContextTimeout is the default timeout for cache operations.
 	ContextTimeout time.Duration
 
-	// EnableMetrics enables metrics collection.
+	// StaleWhileRevalidate enables stale-while-revalidate and sets the maximum staleness.
+	// Values invalidated by other pods, or whose local TTL ran out, are kept locally as
+	// stale for up to this duration; Get returns them immediately (flagged by HitInfo.Stale)
+	// while refreshing the key from the remote store or its loader in the background.
+	// Deleted keys are never served stale.
+	// When 0 (default), invalidated and expired values are dropped immediately.
+	StaleWhileRevalidate time.Duration
+
+	// LocalTTL is how long values stay fresh in the local cache, whatever their
+	// Redis TTL. Expired entries are revalidated from Redis on the next Get, a
+	// safety net against missed synchronization events. WithLocalTTL overrides it
+	// per call. When 0 (default), local entries only expire with WithTTL.
+	LocalTTL time.Duration
+
+	// RemoteGetTimeout bounds how long Get waits on the remote store (and any
+	// registered loader) after a local miss. Each caller also gives up when its
+	// own ctx is done. Timeouts are reported via OnError as ErrTimeout so callers
+	// can tell them apart from misses. When 0 (default), ContextTimeout is used.
+	RemoteGetTimeout time.Duration
+
+	// EnableMetrics enables metrics collection: the latencies of local and remote
+	// reads, serialization, publishing and received events are recorded in
+	// histograms reported in Stats.Latencies, to tell whether a slower p99 comes
+	// from Redis, marshalling or local cache contention.
 	EnableMetrics bool
 
 	// OnError is called when an error occurs in background operations.
 	OnError func(error)
 
+	// OnErrorEx is called with the same errors as OnError, together with the
+	// operation that failed (OpGet, OpSet, OpSync...) and the key involved, empty
+	// for operations on no single key. The error wraps a category telling what
+	// failed, to be tested with errors.Is: ErrSerialization, ErrDeserialization,
+	// ErrRemoteStore, ErrPublish or ErrSubscription. Errors of loaders and Writer,
+	// and ErrValueTooLarge, are passed without a category.
+	OnErrorEx func(op, key string, err error)
+
+	// WritePolicy selects which levels Set writes and which event it publishes:
+	// WritePolicyWriteThrough, WritePolicyLocalOnly, WritePolicyInvalidateOnly or
+	// WritePolicyWriteBehind. When empty (default), it is derived from
+	// ReaderCanSetToRedis and WriteBehind.
+	WritePolicy WritePolicy
+
+	// DisableRemoteStore uses Redis only as a message bus between pods: Get never
+	// reads values from Redis and falls back to the loaders on a local miss, while
+	// Set and Delete never write to Redis but still propagate to other pods.
+	// SetIfVersion and Version return ErrVersioningNotSupported.
+	DisableRemoteStore bool
+
+	// ReadOnly makes Set, SetIfVersion, SetAck, Delete and Clear return ErrReadOnly
+	// without touching the local cache, Redis or other pods, for reader-tier
+	// deployments. Get, registered loaders and events from other pods still update
+	// the local cache.
+	ReadOnly bool
+
 	// ReaderCanSetToRedis controls whether reader nodes are allowed to write data to Redis.
 	// When false (default), reader nodes will only update local cache but NOT write to Redis.
 	// When true, reader nodes can write data to Redis.
 	// This prevents stale data from readers overwriting fresh data in Redis.
+	//
+	// Deprecated: Use WritePolicy, which takes precedence when set.
 	ReaderCanSetToRedis bool
 
+	// ConsistencyMode selects the order in which Set updates the local cache, writes
+	// Redis and publishes the synchronization event. ConsistencyRemoteFirst only
+	// updates the local cache and notifies other pods once the Redis write succeeded,
+	// and ConsistencyOutbox also makes the write and the publish a single transaction.
+	// Defaults to ConsistencyLocalFirst.
+	ConsistencyMode ConsistencyMode
+
+	// RollbackLocalOnError removes the value from the local cache when the Redis
+	// write of a Set fails under ConsistencyLocalFirst, so the writing pod does not
+	// keep a value that neither Redis nor other pods have. Use ConsistencyRemoteFirst
+	// to not update the local cache before the write succeeded instead.
+	// When false (default), the local value is kept.
+	RollbackLocalOnError bool
+
+	// ShouldCacheLocally, when set, reports whether this pod keeps key in its local
+	// cache. Values of rejected keys read from Redis, loaded by a loader, warmed up
+	// or propagated by other pods are not stored locally, and are counted in
+	// Stats.LocalSkips, so pods behind a load balancer sharding keys across them
+	// do not hold the keys of other shards. Reads of such keys are served from
+	// Redis. Set still stores the value locally. It must be fast and safe for
+	// concurrent use.
+	ShouldCacheLocally func(key string) bool
+
+	// EventFilter, when set, is called with every "set", "invalidate" and "delete"
+	// event received from other pods before it is applied; events for which it
+	// returns false are dropped and counted in Stats.FilteredEvents. Pods serving
+	// only a shard of the keys use it to skip the updates of keys they never read,
+	// reducing local cache churn. Only drop events of keys the pod does not cache:
+	// a dropped invalidation leaves a cached value stale. Other events, such as
+	// "clear", are always applied. It is called on the goroutine applying events
+	// and must not block.
+	EventFilter func(event InvalidationEvent) bool
+
 	// OnSetLocalCache is a callback for custom processing of data before storing in local cache.
 	// This callback is invoked when an invalidation event with action "set" is received.
 	// The callback receives the invalidation event and returns the value to store in local cache.
@@ -89,15 +409,313 @@ type Options struct {
 	// - Parse and transform event data into a pre-processed wrapper struct for zero-cost reads
 	// - Extract structured metadata (hash, timestamp, data) from events for custom handling
 	OnSetLocalCache func(event InvalidationEvent) any
+
+	// CostFunc computes the local cache cost of an entry from its key, value and serialized bytes.
+	// It is used when storing values on Set, on remote Get population and on propagation.
+	// When nil (default), the cost is the serialized size in bytes, which makes
+	// LocalCacheConfig.MaxCost a memory bound for Ristretto.
+	CostFunc func(key string, value any, serialized []byte) int64
+
+	// DecodeInto returns a pointer to a new value of the type the value of key is
+	// decoded into, e.g. &User{} for keys prefixed "user:", so values read from
+	// Redis, warmed up or received from other pods keep the type they were Set
+	// with instead of becoming a map[string]any. The pointed-to value is stored,
+	// so Get returns a User, except for protobuf messages, which are stored as
+	// the returned pointer. When nil (default), or when it returns nil for a
+	// key, values are decoded into an any.
+	DecodeInto func(key string) any
+
+	// TypeRegistry registers the Go type and schema version of the values of key
+	// prefixes. Payloads of registered keys are written with their schema version
+	// and decoded into the registered type, converting values written by pods
+	// running another version. It takes precedence over DecodeInto.
+	TypeRegistry *TypeRegistry
+
+	// LocalValueMode selects whether the local cache holds decoded values, their
+	// serialized bytes, or both. It applies alike to values stored by Set, read
+	// from Redis by Get, warmed up and received from other pods; OnSetLocalCache,
+	// when set, still decides what propagated values are stored as.
+	// When empty, LocalValueDecoded is used.
+	LocalValueMode LocalValueMode
+
+	// KeepRawBytes keeps the serialized bytes of local entries next to their
+	// decoded values, so GetRaw serves them without marshalling the value again.
+	// Kept bytes are bounded like GetWithInfo metadata, by LocalCacheConfig.MaxSize
+	// entries, and take memory in addition to the local cache.
+	KeepRawBytes bool
+
+	// ComputeETags computes the ETag of local entries from their serialized bytes
+	// when they are stored, so GetWithETag and CheckETag serve local hits without
+	// encoding and hashing the value. Otherwise ETags are computed on each call.
+	ComputeETags bool
+
+	// RejectedSetPolicy selects what happens when the local cache rejects or drops a Set.
+	// Detecting and retrying asynchronous drops requires a local cache implementing
+	// AdmittingLocalCache, such as the default Ristretto cache.
+	// When empty, RejectedSetLog is used.
+	RejectedSetPolicy RejectedSetPolicy
+
+	// PrefetchHint is an optional predictive prefetcher. It is called with every key
+	// found by Get and returns related keys that are likely to be accessed next
+	// (e.g. detail page -> related items). Returned keys are warmed into the local
+	// cache in the background as if passed to Prefetch.
+	PrefetchHint func(key string) []string
+
+	// HotKeys enables a hot list of the most accessed keys persisted in Redis per
+	// Namespace. Every pod counts the keys found by Get and adds the counts to the
+	// list every HotKeysInterval (default 10s); new pods prefetch the top HotKeys
+	// keys of the list at startup, so cold starts stay stable across rolling deploys
+	// without maintaining key lists by hand. When 0 (default), no hot list is kept.
+	HotKeys         int
+	HotKeysInterval time.Duration
+
+	// WarmupKeys are loaded from the remote store into the local cache before New
+	// returns, so a new pod does not serve its first requests from a cold cache.
+	WarmupKeys []string
+
+	// WarmupPattern is a glob pattern, e.g. "config:*", whose matching keys are
+	// scanned and loaded into the local cache before New returns. It requires a
+	// store implementing WarmupStore. Startup warmup is bounded by ContextTimeout.
+	WarmupPattern string
+
+	// PeerWarmup makes a new pod request a snapshot of the local cache of an existing
+	// pod over the sync channel before New returns, instead of reading every key from
+	// Redis on deploys. Pods with PeerWarmup set answer such requests; when the store
+	// implements LockingStore, a single pod answers each request. Entries are sent in
+	// chunks, encoded as Sets propagate them, with the clock of the last write to
+	// their key; the new pod skips keys it holds or has seen a newer write to.
+	PeerWarmup bool
+
+	// AntiEntropyInterval makes each pod publish a digest of its local cache on the
+	// sync channel at this interval. Pods receiving it drop the entries whose value
+	// differs from the sender's and fetch them again from Redis, bounding how long
+	// a pod serves a stale value after a lost event. Digests serialize every local
+	// value unless ComputeETags or KeepRawBytes is set. When 0 (default), no
+	// digests are exchanged.
+	AntiEntropyInterval time.Duration
+
+	// BloomFilterInterval makes each pod keep a Bloom filter of the keys in Redis,
+	// built by scanning them at startup and rebuilt at this interval to forget
+	// deleted keys, so a Get missing the local cache skips Redis for keys that
+	// definitely do not exist and goes to the registered loader directly. Keys
+	// written by this pod or announced by the events of other pods are added as
+	// they are written. Keys written without an event, e.g. by other systems or
+	// with PropagateNone, are only seen by readers after the next rebuild. The
+	// store must implement WarmupStore. When 0 (default), Redis is always read.
+	BloomFilterInterval time.Duration
+
+	// BloomFilterKeys is the number of keys the Bloom filter is sized for, at a 1%
+	// false positive rate; larger key spaces make it skip fewer reads. Defaults to
+	// 100000, about 120 KB.
+	BloomFilterKeys int
+
+	// HeartbeatInterval makes each pod publish a heartbeat on the sync channel at
+	// this interval. A pod receiving a heartbeat from another pod with its own
+	// PodID reports ErrDuplicatePodID via OnError and counts it in
+	// Stats.DuplicatePodIDs: such pods ignore each other's events as their own.
+	// Heartbeats also list the pods sharing the sync channel, see Peers.
+	// When 0 (default), no heartbeats are published, Peers returns nil and
+	// duplicates are only detected while the subscription is confirmed.
+	HeartbeatInterval time.Duration
+
+	// LeaderElection makes pods compete for a lease held as a lock in the store, so
+	// that a single pod at a time reports IsLeader, e.g. to gate single-writer code
+	// paths. The leader renews the lease every third of LeaderLeaseTTL and releases
+	// it on Close; if it stops renewing, another pod takes over once the lease expires.
+	// It requires a store implementing LockingStore and LeasingStore, as the Redis
+	// store does.
+	LeaderElection bool
+
+	// LeaderLeaseTTL is how long the leader lease lasts without being renewed.
+	// When 0 (default), 15 seconds is used.
+	LeaderLeaseTTL time.Duration
+
+	// PeerWarmupTimeout bounds how long New waits for the snapshot.
+	// When 0 (default), ContextTimeout is used.
+	PeerWarmupTimeout time.Duration
+
+	// PropagationRules maps key patterns, where '*' matches any sequence of
+	// characters (e.g. "session:*"), to how Set announces matching keys to other
+	// pods: PropagateValue, PropagateInvalidate or PropagateNone. The longest
+	// matching pattern wins. Rules override the propagation implied by WritePolicy,
+	// while WithInvalidateOnly, WithNoPropagate and WithWritePolicy(WritePolicyInvalidateOnly)
+	// on a call override rules. Keys matching no rule follow WritePolicy.
+	PropagationRules map[string]Propagation
+
+	// TTLJitterPercent randomizes the TTL of each write with WithTTL by up to this
+	// percentage in either direction, so keys written together (e.g. at deploy time)
+	// do not expire at the same instant and stampede the database. Must be within
+	// [0, 100). When 0 (default), TTLs are used as given.
+	TTLJitterPercent int
+
+	// MaxPropagationSize is the largest serialized value, in bytes, propagated to
+	// other pods with ActionSet. Larger values are stored as usual but other pods
+	// only receive an invalidation and fetch them from Redis on their next Get,
+	// counted in Stats.OversizedPropagations. When 0 (default), there is no limit.
+	MaxPropagationSize int
+
+	// RelayRemoteHits makes a Get that misses the local cache but hits Redis
+	// publish the value to the other pods as a relayed ActionSet, so a popular key
+	// read after a deploy is fetched from Redis once instead of once per pod.
+	// Relayed values only fill the local caches that neither hold the key nor
+	// applied a write to it since the value was read. They are never relayed
+	// again. Keys that would not propagate their value on Set are not relayed.
+	// Relays are counted in Stats.RelayedHits.
+	RelayRemoteHits bool
+
+	// MaxClockSkew bounds how far ahead of this pod's wall clock the hybrid logical
+	// clock of a received event may be. The stamps of events beyond it are ignored,
+	// so a pod whose clock is set far in the future cannot make the others discard
+	// every later write. Such events are counted in Stats.SkewedEvents. When 0
+	// (default), 1 minute is used.
+	MaxClockSkew time.Duration
+
+	// MaxValueSize is the largest serialized value, in bytes, that Set stores.
+	// Larger values are rejected with ErrValueTooLarge, counted in
+	// Stats.OversizedValues, instead of being written to Redis and pub/sub.
+	// Values loaded by a Loader above the limit are returned but not cached.
+	// When 0 (default), there is no limit.
+	MaxValueSize int
+
+	// PropagationWorkers is the number of workers used to deserialize and apply
+	// incoming synchronization events in parallel. Events for the same key are
+	// always applied by the same worker, preserving per-key ordering.
+	// When 0 (default), events are applied inline on the subscriber goroutine.
+	PropagationWorkers int
+
+	// PropagationQueueSize bounds the events buffered per propagation worker.
+	// When a worker's queue is full, the listener waits for room and the
+	// overflow is counted in Stats.EventQueueOverflows. When 0, 1024 is used.
+	PropagationQueueSize int
+
+	// SubscribeTimeout makes New block until the pub/sub subscription is confirmed
+	// active by a self-ping round trip on every subscribed channel, for at most this
+	// duration. Without it there is a short startup window where events from other
+	// pods are silently missed. New fails with ErrSubscriptionNotReady on timeout.
+	// When 0 (default), New returns without waiting.
+	SubscribeTimeout time.Duration
+
+	// BreakerThreshold enables a circuit breaker around Redis that opens after
+	// this many consecutive failed calls. While open, Get serves the local cache only
+	// and Set, SetWithInvalidate and Delete follow DegradedWritePolicy, so requests do
+	// not each pay a connection timeout during an outage. Every BreakerCooldown
+	// (default 5s) the breaker half-opens and lets one call through, closing again if
+	// it succeeds. When 0 (default), Redis is always called.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// DegradedWritePolicy selects what writes do while the circuit breaker is open.
+	// Defaults to DegradedWriteFailFast.
+	DegradedWritePolicy DegradedWritePolicy
+
+	// DegradedQueueSize bounds the number of keys queued under DegradedWriteQueue.
+	// When 0 (default), writes for up to 1024 keys are queued.
+	DegradedQueueSize int
+
+	// PublishQueueSize enables retrying synchronization events whose Publish failed
+	// in Set, SetWithInvalidate, Delete and Clear, which otherwise leave other pods
+	// stale. Only the latest failed event per key is kept, for at most this many keys.
+	// When 0 (default), failed publishes are only reported via OnError.
+	PublishQueueSize int
+
+	// PublishRetries is the number of times a queued event is published again before
+	// it is dropped (default 10), waiting PublishRetryBackoff (default 100ms) before
+	// the first retry and doubling the wait after each failure, up to 30s.
+	PublishRetries      int
+	PublishRetryBackoff time.Duration
+
+	// PublishRateLimit bounds the synchronization events published by Set,
+	// SetWithInvalidate and Delete to this many per second on average, with bursts
+	// of up to PublishBurst events (default one second worth), so a bulk job cannot
+	// saturate the sync channel. Events over the limit follow RateLimitPolicy.
+	// When 0 (default), events are not limited.
+	PublishRateLimit float64
+	PublishBurst     int
+
+	// RateLimitPolicy selects what happens to events over PublishRateLimit.
+	// Defaults to RateLimitCoalesce.
+	RateLimitPolicy RateLimitPolicy
+
+	// WriteRateLimit bounds the Redis writes made by Set, SetWithInvalidate and
+	// Delete to this many per second on average, with bursts of up to WriteBurst
+	// writes (default one second worth). Writes over the limit fail with
+	// ErrRateLimited, counted in Stats.RateLimitedWrites, without publishing an
+	// event. When 0 (default), writes are not limited.
+	WriteRateLimit float64
+	WriteBurst     int
+
+	// OnPublishDropped is called with the event and the last error when a failed
+	// event is given up on, either because PublishRetries was exhausted or with
+	// ErrPublishQueueFull because the queue was full.
+	OnPublishDropped func(event InvalidationEvent, err error)
+
+	// OnEventGap is called when events from another pod were lost in transit,
+	// detected from gaps in the per-sender sequence numbers of Pub/Sub events.
+	OnEventGap func(gap EventGap)
+
+	// InvalidateOnGap clears the local cache when events were lost in transit, as
+	// the keys they concerned are unknown. Values are then fetched again from the
+	// remote store. When false (default), gaps are only counted and reported.
+	InvalidateOnGap bool
+
+	// Writer persists values to a backing database on Set and SetWithInvalidate.
+	// In write-through mode (default) the value is written before it is cached and
+	// Set returns the Writer error if all attempts fail.
+	// When nil (default), values are only stored in the cache.
+	Writer Writer
+
+	// WriteBehind makes Set persist through Writer asynchronously from a bounded
+	// queue instead of synchronously. Set fails with ErrWriteQueueFull when the
+	// queue is full. Values still queued on Close are written before Close returns.
+	// WritePolicyWriteBehind implies it.
+	WriteBehind bool
+
+	// WriteBehindQueueSize bounds the write-behind queue.
+	// When 0 (default), a queue of 1024 values is used.
+	WriteBehindQueueSize int
+
+	// WriteRetries is the number of times a failed Writer call is retried,
+	// waiting WriteRetryInterval between attempts. Defaults to 0 (no retries).
+	WriteRetries int
+
+	// WriteRetryInterval is the delay between Writer retries.
+	WriteRetryInterval time.Duration
+
+	// OnWriteFailed is the dead-letter callback for write-behind mode. It receives
+	// values the Writer could not persist after all retries, and the values still
+	// queued when the Shutdown context is done.
+	OnWriteFailed func(key string, value any, err error)
+
+	// FaultInjector fails Redis commands and drops, duplicates or delays received
+	// events, to test stale-data behavior and the circuit breaker in CI. It is
+	// installed as a hook on the Redis clients, including RedisClient and
+	// SyncRedisClient. Never set it in production.
+	FaultInjector FaultInjector
+}
+
+// GeneratePodID returns a pod ID unique to this process: the hostname, which is
+// the pod name on Kubernetes, followed by a random suffix. Pods sharing a pod ID
+// ignore each other's events as their own, so every pod needs a distinct one.
+// Set a stable PodID instead when it must survive restarts, as with
+// SyncTransportStreams.
+func GeneratePodID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "pod"
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return hostname + "-" + hex.EncodeToString(suffix)
 }
 
 // DefaultOptions returns default cache options.
 func DefaultOptions() Options {
 	return Options{
-		PodID:               "default-pod",
+		PodID:               GeneratePodID(),
 		RedisAddr:           "localhost:6379",
 		RedisDB:             0,
 		InvalidationChannel: "cache:invalidate",
+		SyncTransport:       SyncTransportPubSub,
 		SerializationFormat: "json",
 		ContextTimeout:      5 * time.Second,
 		EnableMetrics:       true,
@@ -108,6 +726,18 @@ func DefaultOptions() Options {
 		DebugMode:           false,
 		ReaderCanSetToRedis: false, // Default: readers cannot write to Redis
 		OnSetLocalCache:     nil,   // Default: unmarshal and store in local cache
+		CostFunc:            nil,   // Default: serialized size in bytes
+		RejectedSetPolicy:   RejectedSetLog,
+		LocalValueMode:      LocalValueDecoded,
+		DegradedWritePolicy: DegradedWriteFailFast,
+		ConsistencyMode:     ConsistencyLocalFirst,
+		PrefetchHint:        nil,   // Default: no predictive prefetching
+		HotKeys:             0,     // Default: no persisted hot list
+		PropagationWorkers:  0,     // Default: apply events inline
+		SubscribeTimeout:    0,     // Default: do not wait for the subscription
+		InvalidateOnGap:     false, // Default: only report lost events
+		Writer:              nil,   // Default: no backing database
+		WriteBehind:         false, // Default: write-through when Writer is set
 	}
 }
 
@@ -122,27 +752,260 @@ func DefaultLocalCacheConfig() LocalCacheConfig {
 	}
 }
 
-// Validate validates the options.
+// Validate validates the options. It returns a *ConfigError for every invalid
+// option, joined with errors.Join, each naming the option and the problem so that
+// misconfigurations can be diagnosed from a single log line.
 func (o *Options) Validate() error {
+	var errs configErrors
 	if o.PodID == "" {
-		return ErrInvalidConfig
+		errs.add("PodID", "is empty")
 	}
-	if o.RedisAddr == "" {
-		return ErrInvalidConfig
+	if o.RedisAddr == "" && o.RedisClient == nil && o.ConnectionManager == nil {
+		errs.add("RedisAddr", "is empty and neither RedisClient nor ConnectionManager is set")
 	}
+	if o.ConnectionManager != nil && (o.SyncRedisAddr != "" || o.SyncRedisClient != nil) {
+		errs.add("ConnectionManager", "cannot be combined with SyncRedisAddr or SyncRedisClient")
+	}
+	if len(o.RedisPartitions) > 0 && (o.RedisClient != nil || o.ConnectionManager != nil) {
+		errs.add("RedisPartitions", "cannot be combined with RedisClient or ConnectionManager")
+	}
+	nonNegative(&errs, "PartitionVNodes", o.PartitionVNodes)
+	nonNegative(&errs, "PartitionReplicas", o.PartitionReplicas)
+	nonNegative(&errs, "RedisPoolSize", o.RedisPoolSize)
+	nonNegative(&errs, "RedisMinIdleConns", o.RedisMinIdleConns)
+	nonNegative(&errs, "RedisDialTimeout", o.RedisDialTimeout)
+	nonNegative(&errs, "RedisReadTimeout", o.RedisReadTimeout)
+	nonNegative(&errs, "RedisWriteTimeout", o.RedisWriteTimeout)
 	if o.InvalidationChannel == "" {
-		return ErrInvalidConfig
+		errs.add("InvalidationChannel", "is empty")
+	}
+	nonNegative(&errs, "PropagationWorkers", o.PropagationWorkers)
+	nonNegative(&errs, "PropagationQueueSize", o.PropagationQueueSize)
+	nonNegative(&errs, "MaxPropagationSize", o.MaxPropagationSize)
+	nonNegative(&errs, "MaxValueSize", o.MaxValueSize)
+	nonNegative(&errs, "LocalCacheShards", o.LocalCacheShards)
+	nonNegative(&errs, "SpilloverThreshold", o.SpilloverThreshold)
+	nonNegative(&errs, "SpilloverMaxBytes", o.SpilloverMaxBytes)
+	if o.TTLJitterPercent < 0 || o.TTLJitterPercent >= 100 {
+		errs.add("TTLJitterPercent", fmt.Sprintf("is %v, must be at least 0 and below 100", o.TTLJitterPercent))
+	}
+	nonNegative(&errs, "StaleWhileRevalidate", o.StaleWhileRevalidate)
+	nonNegative(&errs, "LocalTTL", o.LocalTTL)
+	nonNegative(&errs, "RemoteGetTimeout", o.RemoteGetTimeout)
+	nonNegative(&errs, "SubscribeTimeout", o.SubscribeTimeout)
+	nonNegative(&errs, "PeerWarmupTimeout", o.PeerWarmupTimeout)
+	nonNegative(&errs, "AntiEntropyInterval", o.AntiEntropyInterval)
+	nonNegative(&errs, "BloomFilterInterval", o.BloomFilterInterval)
+	nonNegative(&errs, "BloomFilterKeys", o.BloomFilterKeys)
+	nonNegative(&errs, "HeartbeatInterval", o.HeartbeatInterval)
+	nonNegative(&errs, "LeaderLeaseTTL", o.LeaderLeaseTTL)
+	nonNegative(&errs, "MaxClockSkew", o.MaxClockSkew)
+	nonNegative(&errs, "BreakerThreshold", o.BreakerThreshold)
+	nonNegative(&errs, "BreakerCooldown", o.BreakerCooldown)
+	nonNegative(&errs, "DegradedQueueSize", o.DegradedQueueSize)
+	switch o.WritePolicy {
+	case "", WritePolicyWriteThrough, WritePolicyLocalOnly, WritePolicyInvalidateOnly:
+	case WritePolicyWriteBehind:
+		if o.Writer == nil {
+			errs.add("WritePolicy", "is write-behind but Writer is not set")
+		}
+	default:
+		errs.unknown("WritePolicy", o.WritePolicy)
+	}
+	switch o.ConsistencyMode {
+	case "", ConsistencyLocalFirst, ConsistencyRemoteFirst, ConsistencyOutbox:
+	default:
+		errs.unknown("ConsistencyMode", o.ConsistencyMode)
+	}
+	switch o.DegradedWritePolicy {
+	case "", DegradedWriteFailFast, DegradedWriteQueue:
+	default:
+		errs.unknown("DegradedWritePolicy", o.DegradedWritePolicy)
+	}
+	nonNegative(&errs, "PublishQueueSize", o.PublishQueueSize)
+	nonNegative(&errs, "PublishRetries", o.PublishRetries)
+	nonNegative(&errs, "PublishRetryBackoff", o.PublishRetryBackoff)
+	nonNegative(&errs, "PublishRateLimit", o.PublishRateLimit)
+	nonNegative(&errs, "PublishBurst", o.PublishBurst)
+	switch o.RateLimitPolicy {
+	case "", RateLimitCoalesce, RateLimitDrop:
+	default:
+		errs.unknown("RateLimitPolicy", o.RateLimitPolicy)
+	}
+	nonNegative(&errs, "WriteRateLimit", o.WriteRateLimit)
+	nonNegative(&errs, "WriteBurst", o.WriteBurst)
+	if o.DebugSampleRate < 0 || o.DebugSampleRate > 1 {
+		errs.add("DebugSampleRate", fmt.Sprintf("is %v, must be between 0 and 1", o.DebugSampleRate))
+	}
+	for _, category := range o.DebugCategories {
+		switch category {
+		case DebugOps, DebugSync, DebugSerialization:
+		default:
+			errs.unknown("DebugCategories", category)
+		}
+	}
+	nonNegative(&errs, "HotKeys", o.HotKeys)
+	nonNegative(&errs, "HotKeysInterval", o.HotKeysInterval)
+	nonNegative(&errs, "WriteBehindQueueSize", o.WriteBehindQueueSize)
+	nonNegative(&errs, "WriteRetries", o.WriteRetries)
+	nonNegative(&errs, "WriteRetryInterval", o.WriteRetryInterval)
+	for _, pattern := range slices.Sorted(maps.Keys(o.PropagationRules)) {
+		if pattern == "" {
+			errs.add("PropagationRules", "has an empty pattern")
+		}
+		switch propagation := o.PropagationRules[pattern]; propagation {
+		case PropagateValue, PropagateInvalidate, PropagateNone:
+		default:
+			errs.unknown("PropagationRules["+strconv.Quote(pattern)+"]", propagation)
+		}
+	}
+	for _, pattern := range slices.Sorted(maps.Keys(o.MarshallerRules)) {
+		field := "MarshallerRules[" + strconv.Quote(pattern) + "]"
+		if pattern == "" {
+			errs.add("MarshallerRules", "has an empty pattern")
+		}
+		rule := o.MarshallerRules[pattern]
+		if rule.ID == 0 {
+			errs.add(field+".ID", "must not be 0")
+		}
+		if rule.Marshaller == nil {
+			errs.add(field+".Marshaller", "is nil")
+		}
+	}
+	for _, prefix := range slices.Sorted(maps.Keys(o.PrefixChannels)) {
+		if prefix == "" {
+			errs.add("PrefixChannels", "has an empty prefix")
+		}
+		if o.PrefixChannels[prefix] == "" {
+			errs.add("PrefixChannels["+strconv.Quote(prefix)+"]", "is empty")
+		}
+	}
+	for i, channel := range o.AdditionalChannels {
+		if channel == "" {
+			errs.add("AdditionalChannels["+strconv.Itoa(i)+"]", "is empty")
+		}
+	}
+	if len(o.AdditionalChannels) > 0 && o.ConsistencyMode == ConsistencyOutbox {
+		errs.add("AdditionalChannels", "cannot be used with the outbox ConsistencyMode")
+	}
+	for i, pattern := range o.PatternChannels {
+		if pattern == "" {
+			errs.add("PatternChannels["+strconv.Itoa(i)+"]", "is empty")
+		}
+	}
+	for _, prefix := range slices.Sorted(maps.Keys(o.ExternalFormats)) {
+		if prefix == "" {
+			errs.add("ExternalFormats", "has an empty prefix")
+		}
+		switch encoding := o.ExternalFormats[prefix].Encoding; encoding {
+		case "", EncodingString, EncodingHash:
+		default:
+			errs.unknown("ExternalFormats["+strconv.Quote(prefix)+"].Encoding", encoding)
+		}
+	}
+	switch o.SyncTransport {
+	case "", SyncTransportPubSub:
+	case SyncTransportStreams:
+		if len(o.PrefixChannels) > 0 {
+			errs.add("PrefixChannels", "cannot be used with the streams SyncTransport")
+		}
+		if o.ReceiveOwnEvents {
+			errs.add("ReceiveOwnEvents", "cannot be used with the streams SyncTransport")
+		}
+		if len(o.PatternChannels) > 0 {
+			errs.add("PatternChannels", "cannot be used with the streams SyncTransport")
+		}
+		if len(o.AdditionalChannels) > 0 {
+			errs.add("AdditionalChannels", "cannot be used with the streams SyncTransport")
+		}
+	default:
+		errs.unknown("SyncTransport", o.SyncTransport)
+	}
+	nonNegative(&errs, "StreamMaxLen", o.StreamMaxLen)
+	switch o.EventEncoding {
+	case "", EventEncodingJSON, EventEncodingBinary:
+	default:
+		errs.unknown("EventEncoding", o.EventEncoding)
+	}
+	switch o.RejectedSetPolicy {
+	case "", RejectedSetLog, RejectedSetRetry, RejectedSetForcePropagated:
+	default:
+		errs.unknown("RejectedSetPolicy", o.RejectedSetPolicy)
+	}
+	switch o.LocalValueMode {
+	case "", LocalValueDecoded, LocalValueRaw, LocalValueBoth:
+	default:
+		errs.unknown("LocalValueMode", o.LocalValueMode)
 	}
 	if o.SerializationFormat != "json" && o.SerializationFormat != "msgpack" {
-		return ErrInvalidConfig
+		errs.add("SerializationFormat", fmt.Sprintf("is %q, must be \"json\" or \"msgpack\"", o.SerializationFormat))
 	}
 	if o.LocalCacheConfig.NumCounters <= 0 {
-		return ErrInvalidConfig
+		errs.add("LocalCacheConfig.NumCounters", "must be positive")
 	}
 	if o.LocalCacheConfig.MaxCost <= 0 {
-		return ErrInvalidConfig
+		errs.add("LocalCacheConfig.MaxCost", "must be positive")
+	}
+	return errors.Join(errs...)
+}
+
+// redisOptions returns the go-redis client options for the Redis settings.
+func (o *Options) redisOptions() *redis.Options {
+	return &redis.Options{
+		Addr:         o.RedisAddr,
+		Username:     o.RedisUsername,
+		Password:     o.RedisPassword,
+		TLSConfig:    o.RedisTLSConfig,
+		DB:           o.RedisDB,
+		PoolSize:     o.RedisPoolSize,
+		MinIdleConns: o.RedisMinIdleConns,
+		DialTimeout:  o.RedisDialTimeout,
+		ReadTimeout:  o.RedisReadTimeout,
+		WriteTimeout: o.RedisWriteTimeout,
+	}
+}
+
+// syncRedisOptions returns the go-redis client options for the synchronizer connection.
+func (o *Options) syncRedisOptions() *redis.Options {
+	opts := o.redisOptions()
+	opts.Addr = o.SyncRedisAddr
+	return opts
+}
+
+// ConfigError describes one invalid option. Validate returns every ConfigError
+// found, joined with errors.Join; each matches ErrInvalidConfig with errors.Is.
+type ConfigError struct {
+	Field  string // option name, e.g. "PodID" or "PrefixChannels[\"user:\"]"
+	Reason string // what is wrong with it, e.g. "is empty"
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid config: " + e.Field + " " + e.Reason
+}
+
+// Unwrap returns ErrInvalidConfig.
+func (e *ConfigError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// configErrors collects the problems found by Validate.
+type configErrors []error
+
+// add records that field is invalid for reason.
+func (c *configErrors) add(field, reason string) {
+	*c = append(*c, &ConfigError{Field: field, Reason: reason})
+}
+
+// unknown records that field holds a value it does not accept.
+func (c *configErrors) unknown(field string, value any) {
+	c.add(field, fmt.Sprintf("has unknown value %q", value))
+}
+
+// nonNegative records that field must not be negative when v is.
+func nonNegative[T ~int | ~int64 | ~float64](c *configErrors, field string, v T) {
+	if v < 0 {
+		c.add(field, fmt.Sprintf("is %v, must not be negative", v))
 	}
-	return nil
 }
 
 // ErrInvalidConfig is returned when options are invalid.
@@ -1,8 +1,13 @@
 package cache
 
 import (
+	"crypto/tls"
+	"errors"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 func TestDefaultOptions(t *testing.T) {
@@ -139,7 +144,7 @@ func TestOptionsValidateEmptyInvalidationChannel(t *testing.T) {
 		t.Fatal("Expected error for empty InvalidationChannel")
 	}
 
-	if err != ErrInvalidConfig {
+	if !errors.Is(err, ErrInvalidConfig) {
 		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
 	}
 }
@@ -154,7 +159,7 @@ func TestOptionsValidateNegativeNumCounters(t *testing.T) {
 		t.Fatal("Expected error for negative NumCounters")
 	}
 
-	if err != ErrInvalidConfig {
+	if !errors.Is(err, ErrInvalidConfig) {
 		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
 	}
 }
@@ -169,7 +174,7 @@ func TestOptionsValidateZeroNumCounters(t *testing.T) {
 		t.Fatal("Expected error for zero NumCounters")
 	}
 
-	if err != ErrInvalidConfig {
+	if !errors.Is(err, ErrInvalidConfig) {
 		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
 	}
 }
@@ -184,7 +189,7 @@ func TestOptionsValidateNegativeMaxCost(t *testing.T) {
 		t.Fatal("Expected error for negative MaxCost")
 	}
 
-	if err != ErrInvalidConfig {
+	if !errors.Is(err, ErrInvalidConfig) {
 		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
 	}
 }
@@ -199,7 +204,396 @@ func TestOptionsValidateZeroMaxCost(t *testing.T) {
 		t.Fatal("Expected error for zero MaxCost")
 	}
 
-	if err != ErrInvalidConfig {
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+// TestOptionsValidatePrefixChannels tests validation of PrefixChannels entries
+func TestOptionsValidatePrefixChannels(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PrefixChannels = map[string]string{"user:": "cache:invalidate:users"}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected valid options, got %v", err)
+	}
+
+	opts.PrefixChannels = map[string]string{"": "cache:invalidate:users"}
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for empty prefix, got %v", err)
+	}
+
+	opts.PrefixChannels = map[string]string{"user:": ""}
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for empty channel, got %v", err)
+	}
+}
+
+func TestOptionsValidateAdditionalChannels(t *testing.T) {
+	opts := DefaultOptions()
+	opts.AdditionalChannels = []string{"cache:audit"}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected valid additional channels, got %v", err)
+	}
+
+	opts.ConsistencyMode = ConsistencyOutbox
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for AdditionalChannels with the outbox, got %v", err)
+	}
+
+	opts.ConsistencyMode = ""
+	opts.AdditionalChannels = []string{""}
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for an empty channel, got %v", err)
+	}
+}
+
+func TestOptionsValidatePatternChannels(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PatternChannels = []string{"cache:invalidate:*"}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected valid pattern channels, got %v", err)
+	}
+
+	opts.PatternChannels = []string{""}
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for an empty pattern, got %v", err)
+	}
+}
+
+// TestOptionsValidateNegativePropagationWorkers tests validation with negative PropagationWorkers
+func TestOptionsValidateNegativePropagationWorkers(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PropagationWorkers = -1
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+// TestOptionsValidateNegativeLocalCacheShards tests validation with negative LocalCacheShards
+func TestOptionsValidateNegativeLocalCacheShards(t *testing.T) {
+	opts := DefaultOptions()
+	opts.LocalCacheShards = -1
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+// TestOptionsValidateNegativeSpillover tests validation with negative spillover bounds
+func TestOptionsValidateNegativeSpillover(t *testing.T) {
+	opts := DefaultOptions()
+	opts.SpilloverThreshold = -1
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestOptionsValidateConnectionManager(t *testing.T) {
+	opts := DefaultOptions()
+	opts.RedisAddr = ""
+	opts.ConnectionManager = NewConnectionManager(nil)
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected a ConnectionManager to replace RedisAddr, got %v", err)
+	}
+
+	opts.SyncRedisAddr = "localhost:6380"
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig with SyncRedisAddr, got %v", err)
+	}
+}
+
+func TestOptionsValidateRedisPartitions(t *testing.T) {
+	opts := DefaultOptions()
+	opts.RedisPartitions = []string{"redis-0:6379", "redis-1:6379"}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected valid partitions, got %v", err)
+	}
+
+	opts.PartitionReplicas = -1
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig with negative PartitionReplicas, got %v", err)
+	}
+
+	opts.PartitionReplicas = 0
+	opts.ConnectionManager = NewConnectionManager(nil)
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig with a ConnectionManager, got %v", err)
+	}
+}
+
+func TestOptionsValidateNegativeMaxValueSize(t *testing.T) {
+	opts := DefaultOptions()
+	opts.MaxValueSize = -1
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+// TestOptionsValidateNegativeStaleWhileRevalidate tests validation with negative StaleWhileRevalidate
+func TestOptionsValidateNegativeStaleWhileRevalidate(t *testing.T) {
+	opts := DefaultOptions()
+	opts.StaleWhileRevalidate = -time.Second
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+// TestOptionsValidateNegativeRemoteGetTimeout tests validation with negative RemoteGetTimeout
+func TestOptionsValidateNegativeRemoteGetTimeout(t *testing.T) {
+	opts := DefaultOptions()
+	opts.RemoteGetTimeout = -time.Second
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+// TestOptionsValidateNegativeSubscribeTimeout tests validation with negative SubscribeTimeout
+func TestOptionsValidateNegativeSubscribeTimeout(t *testing.T) {
+	opts := DefaultOptions()
+	opts.SubscribeTimeout = -time.Second
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+// TestOptionsValidateNegativePeerWarmupTimeout tests validation with negative PeerWarmupTimeout
+func TestOptionsValidateNegativePeerWarmupTimeout(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PeerWarmupTimeout = -time.Second
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+// TestOptionsValidateCircuitBreaker tests validation of the circuit breaker settings
+func TestOptionsValidateCircuitBreaker(t *testing.T) {
+	for _, mutate := range []func(*Options){
+		func(o *Options) { o.BreakerThreshold = -1 },
+		func(o *Options) { o.BreakerCooldown = -time.Second },
+		func(o *Options) { o.DegradedQueueSize = -1 },
+		func(o *Options) { o.DegradedWritePolicy = "drop" },
+	} {
+		opts := DefaultOptions()
+		mutate(&opts)
+		if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+		}
+	}
+
+	opts := DefaultOptions()
+	opts.BreakerThreshold = 5
+	opts.DegradedWritePolicy = DegradedWriteQueue
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected valid circuit breaker settings, got %v", err)
+	}
+}
+
+func TestOptionsValidateWritePolicy(t *testing.T) {
+	opts := DefaultOptions()
+	opts.WritePolicy = "read-only"
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+
+	opts.WritePolicy = WritePolicyWriteBehind
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for write-behind without a Writer, got %v", err)
+	}
+
+	opts.Writer = &recordingWriter{}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected a valid write policy, got %v", err)
+	}
+}
+
+func TestOptionsValidateConsistencyMode(t *testing.T) {
+	opts := DefaultOptions()
+	opts.ConsistencyMode = "eventual"
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+
+	opts.ConsistencyMode = ConsistencyOutbox
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected a valid consistency mode, got %v", err)
+	}
+}
+
+func TestOptionsValidatePublishRetries(t *testing.T) {
+	for _, mutate := range []func(*Options){
+		func(o *Options) { o.PublishQueueSize = -1 },
+		func(o *Options) { o.PublishRetries = -1 },
+		func(o *Options) { o.PublishRetryBackoff = -time.Second },
+	} {
+		opts := DefaultOptions()
+		mutate(&opts)
+		if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+		}
+	}
+}
+
+func TestOptionsValidateDebugSampling(t *testing.T) {
+	for _, mutate := range []func(*Options){
+		func(o *Options) { o.DebugSampleRate = -0.1 },
+		func(o *Options) { o.DebugSampleRate = 1.5 },
+		func(o *Options) { o.DebugCategories = []DebugCategory{"verbose"} },
+	} {
+		opts := DefaultOptions()
+		mutate(&opts)
+		if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+		}
+	}
+}
+
+func TestOptionsValidateTTLJitterPercent(t *testing.T) {
+	for _, percent := range []int{-1, 100} {
+		opts := DefaultOptions()
+		opts.TTLJitterPercent = percent
+		if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig for %d%%, got %v", percent, err)
+		}
+	}
+}
+
+func TestOptionsValidateRedisClientSettings(t *testing.T) {
+	for _, mutate := range []func(*Options){
+		func(o *Options) { o.RedisPoolSize = -1 },
+		func(o *Options) { o.RedisMinIdleConns = -1 },
+		func(o *Options) { o.RedisDialTimeout = -time.Second },
+		func(o *Options) { o.RedisReadTimeout = -time.Second },
+		func(o *Options) { o.RedisWriteTimeout = -time.Second },
+	} {
+		opts := DefaultOptions()
+		mutate(&opts)
+		if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+		}
+	}
+
+	opts := DefaultOptions()
+	opts.RedisAddr = ""
+	opts.RedisClient = redis.NewClient(&redis.Options{})
+	defer opts.RedisClient.Close()
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected a pre-built client to replace RedisAddr, got %v", err)
+	}
+}
+
+func TestOptionsRedisOptions(t *testing.T) {
+	opts := DefaultOptions()
+	opts.RedisUsername = "cache"
+	opts.RedisPassword = "secret"
+	opts.RedisTLSConfig = &tls.Config{ServerName: "redis.example.com"}
+	opts.RedisDB = 2
+	opts.RedisPoolSize = 50
+	opts.RedisMinIdleConns = 5
+	opts.RedisDialTimeout = time.Second
+	opts.RedisReadTimeout = 2 * time.Second
+	opts.RedisWriteTimeout = 3 * time.Second
+
+	got := opts.redisOptions()
+	if got.Addr != opts.RedisAddr || got.Password != "secret" || got.DB != 2 {
+		t.Errorf("Unexpected connection settings: %+v", got)
+	}
+	if got.Username != "cache" || got.TLSConfig != opts.RedisTLSConfig {
+		t.Errorf("Expected the ACL username and TLS config, got %+v", got)
+	}
+	if got.PoolSize != 50 || got.MinIdleConns != 5 {
+		t.Errorf("Unexpected pool settings: %+v", got)
+	}
+	if got.DialTimeout != time.Second || got.ReadTimeout != 2*time.Second || got.WriteTimeout != 3*time.Second {
+		t.Errorf("Unexpected timeouts: %+v", got)
+	}
+}
+
+func TestOptionsSyncRedisOptions(t *testing.T) {
+	opts := DefaultOptions()
+	opts.RedisPassword = "secret"
+	opts.RedisPoolSize = 20
+	opts.SyncRedisAddr = "redis-replica:6379"
+
+	got := opts.syncRedisOptions()
+	if got.Addr != "redis-replica:6379" {
+		t.Errorf("Expected the synchronizer address, got %s", got.Addr)
+	}
+	if got.Password != "secret" || got.PoolSize != 20 {
+		t.Errorf("Expected the data connection settings to be reused, got %+v", got)
+	}
+	if opts.redisOptions().Addr != opts.RedisAddr {
+		t.Error("Expected the data connection to keep RedisAddr")
+	}
+}
+
+func TestOptionsValidateEventEncoding(t *testing.T) {
+	opts := DefaultOptions()
+	opts.EventEncoding = EventEncodingBinary
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected binary encoding to be valid, got %v", err)
+	}
+	opts.EventEncoding = "xml"
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+// TestOptionsValidateNegativeWriterSettings tests validation with negative Writer settings
+func TestOptionsValidateNegativeWriterSettings(t *testing.T) {
+	for _, mutate := range []func(*Options){
+		func(o *Options) { o.WriteBehindQueueSize = -1 },
+		func(o *Options) { o.WriteRetries = -1 },
+		func(o *Options) { o.WriteRetryInterval = -time.Second },
+	} {
+		opts := DefaultOptions()
+		mutate(&opts)
+		if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+		}
+	}
+}
+
+// TestOptionsValidateRejectedSetPolicy tests validation of RejectedSetPolicy
+func TestOptionsValidateRejectedSetPolicy(t *testing.T) {
+	opts := DefaultOptions()
+	for _, policy := range []RejectedSetPolicy{"", RejectedSetLog, RejectedSetRetry, RejectedSetForcePropagated} {
+		opts.RejectedSetPolicy = policy
+		if err := opts.Validate(); err != nil {
+			t.Fatalf("Expected policy %q to be valid, got %v", policy, err)
+		}
+	}
+
+	opts.RejectedSetPolicy = "drop"
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+// TestOptionsValidateLocalValueMode tests validation of LocalValueMode
+func TestOptionsValidateLocalValueMode(t *testing.T) {
+	opts := DefaultOptions()
+	for _, mode := range []LocalValueMode{"", LocalValueDecoded, LocalValueRaw, LocalValueBoth} {
+		opts.LocalValueMode = mode
+		if err := opts.Validate(); err != nil {
+			t.Fatalf("Expected mode %q to be valid, got %v", mode, err)
+		}
+	}
+
+	opts.LocalValueMode = "bytes"
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+// TestOptionsValidateMarshallerRules tests validation of MarshallerRules
+func TestOptionsValidateMarshallerRules(t *testing.T) {
+	opts := DefaultOptions()
+	opts.MarshallerRules = map[string]KeyMarshaller{"feed:*": {ID: 1, Marshaller: NewJSONMarshaller()}}
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected the rules to be valid, got %v", err)
+	}
+
+	opts.MarshallerRules = map[string]KeyMarshaller{"feed:*": {}}
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
 		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
 	}
 }
@@ -224,3 +618,60 @@ func TestErrInvalidConfigMessage(t *testing.T) {
 		t.Fatalf("Expected 'invalid cache configuration', got '%s'", errMsg)
 	}
 }
+
+func TestOptionsValidateSyncTransport(t *testing.T) {
+	opts := DefaultOptions()
+	opts.SyncTransport = SyncTransportStreams
+	if err := opts.Validate(); err != nil {
+		t.Fatalf("Expected streams transport to be valid, got %v", err)
+	}
+
+	opts.PrefixChannels = map[string]string{"user:": "users"}
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for PrefixChannels with streams, got %v", err)
+	}
+
+	opts.PrefixChannels = nil
+	opts.ReceiveOwnEvents = true
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for ReceiveOwnEvents with streams, got %v", err)
+	}
+
+	opts.ReceiveOwnEvents = false
+	opts.PatternChannels = []string{"cache:invalidate:*"}
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for PatternChannels with streams, got %v", err)
+	}
+
+	opts.PatternChannels = nil
+	opts.SyncTransport = "kafka"
+	if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig for unknown transport, got %v", err)
+	}
+}
+
+func TestOptionsValidateReportsEveryField(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PodID = ""
+	opts.RedisPoolSize = -1
+	opts.WritePolicy = "write-around"
+
+	err := opts.Validate()
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("Expected ErrInvalidConfig, got %v", err)
+	}
+	for _, want := range []string{
+		`invalid config: PodID is empty`,
+		`invalid config: RedisPoolSize is -1, must not be negative`,
+		`invalid config: WritePolicy has unknown value "write-around"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in %q", want, err.Error())
+		}
+	}
+
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Field != "PodID" {
+		t.Errorf("Expected the first *ConfigError to be about PodID, got %+v", configErr)
+	}
+}
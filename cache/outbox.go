@@ -0,0 +1,56 @@
+package cache
+
+import "context"
+
+// ConsistencyMode selects the order in which Set updates the local cache, writes
+// Redis and publishes the synchronization event, see Options.ConsistencyMode.
+type ConsistencyMode string
+
+const (
+	// ConsistencyLocalFirst updates the local cache first, then writes Redis and
+	// publishes the event. Readers on the writing pod see the value immediately, but
+	// keep it even if the Redis write fails.
+	ConsistencyLocalFirst ConsistencyMode = "local-first"
+	// ConsistencyRemoteFirst writes Redis first and only publishes the event and
+	// updates the local cache once the write succeeded.
+	ConsistencyRemoteFirst ConsistencyMode = "remote-first"
+	// ConsistencyOutbox writes Redis and publishes the event in a single transaction,
+	// then updates the local cache, so other pods are notified if and only if the
+	// write succeeded. It requires an OutboxStore and an OutboxSynchronizer, and
+	// behaves like ConsistencyRemoteFirst otherwise.
+	ConsistencyOutbox ConsistencyMode = "outbox"
+)
+
+// storeAndPublishAtomic writes a serialized value to the store and publishes its
+// synchronization event in one transaction. It reports false without error when
+// the store or synchronizer cannot do so, leaving the write to the caller.
+func (sc *SyncedCache) storeAndPublishAtomic(ctx context.Context, key string, data []byte, cfg setConfig) (bool, error) {
+	store, ok := sc.store.(OutboxStore)
+	if !ok {
+		return false, nil
+	}
+	synchronizer, ok := sc.synchronizer.(OutboxSynchronizer)
+	if !ok {
+		if sc.debugging(DebugSync) {
+			sc.logger.Debug("Set: synchronizer cannot publish within a transaction, publishing after the write", "key", key)
+		}
+		return false, nil
+	}
+
+	event := sc.setEvent(key, data, cfg)
+	channel, message, err := synchronizer.PrepareEvent(event)
+	if err != nil {
+		return false, err
+	}
+	if err := store.SetAndPublish(ctx, key, data, channel, message); err != nil {
+		synchronizer.CancelEvent(channel, message)
+		return false, err
+	}
+
+	sc.trackPublish(event, nil)
+	cfg.published.record(event, nil)
+	if sc.debugging(DebugSync) {
+		sc.logger.Debug("Set: stored and published synchronization event atomically", "key", key, "action", event.Action)
+	}
+	return true, nil
+}
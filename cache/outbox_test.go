@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// outboxStore records the writes made with SetAndPublish.
+type outboxStore struct {
+	errorStore
+	channel  string
+	messages [][]byte
+	txError  error
+}
+
+func (ob *outboxStore) SetAndPublish(ctx context.Context, key string, value []byte, channel string, message []byte) error {
+	if ob.txError != nil {
+		return ob.txError
+	}
+	ob.channel = channel
+	ob.messages = append(ob.messages, message)
+	return nil
+}
+
+// outboxSynchronizer prepares events for an outboxStore and records the events
+// published directly.
+type outboxSynchronizer struct {
+	publishingSynchronizer
+	canceled [][]byte
+}
+
+func (ob *outboxSynchronizer) PrepareEvent(event InvalidationEvent) (string, []byte, error) {
+	return "outbox-channel", []byte(event.Key), nil
+}
+
+func (ob *outboxSynchronizer) CancelEvent(channel string, message []byte) {
+	ob.canceled = append(ob.canceled, message)
+}
+
+func TestConsistencyLocalFirstKeepsLocalValueOnRemoteFailure(t *testing.T) {
+	sc := newMockedCache(t, Options{ReaderCanSetToRedis: true})
+	defer sc.Close()
+	sc.store = &errorStore{setError: errors.New("redis down")}
+	publisher := &publishingSynchronizer{}
+	sc.synchronizer = publisher
+
+	if err := sc.Set(context.Background(), "key", "value"); err == nil {
+		t.Fatal("Expected Set to fail")
+	}
+	if _, ok := sc.local.Get("key"); !ok {
+		t.Error("Expected local-first to have updated the local cache")
+	}
+	if len(publisher.events) != 0 {
+		t.Errorf("Expected no event to be published, got %d", len(publisher.events))
+	}
+}
+
+func TestRollbackLocalOnError(t *testing.T) {
+	sc := newMockedCache(t, Options{ReaderCanSetToRedis: true, RollbackLocalOnError: true})
+	defer sc.Close()
+	sc.local.Set("key", "old", 1)
+	sc.store = &errorStore{setError: errors.New("redis down")}
+
+	if err := sc.Set(context.Background(), "key", "new"); err == nil {
+		t.Fatal("Expected Set to fail")
+	}
+	if value, ok := sc.local.Get("key"); ok {
+		t.Errorf("Expected the local value to be rolled back, got %v", value)
+	}
+}
+
+func TestConsistencyRemoteFirstSkipsLocalOnRemoteFailure(t *testing.T) {
+	sc := newMockedCache(t, Options{ReaderCanSetToRedis: true, ConsistencyMode: ConsistencyRemoteFirst})
+	defer sc.Close()
+	store := &errorStore{setError: errors.New("redis down")}
+	sc.store = store
+	publisher := &publishingSynchronizer{}
+	sc.synchronizer = publisher
+
+	if err := sc.Set(context.Background(), "key", "value"); err == nil {
+		t.Fatal("Expected Set to fail")
+	}
+	if _, ok := sc.local.Get("key"); ok {
+		t.Error("Expected remote-first to leave the local cache untouched")
+	}
+
+	store.setError = nil
+	if err := sc.Set(context.Background(), "key", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, ok := sc.local.Get("key"); !ok {
+		t.Error("Expected the local cache to be updated after a successful write")
+	}
+	if len(publisher.events) != 1 {
+		t.Errorf("Expected one event to be published, got %d", len(publisher.events))
+	}
+}
+
+func TestConsistencyOutboxPublishesWithWrite(t *testing.T) {
+	sc := newMockedCache(t, Options{ReaderCanSetToRedis: true, ConsistencyMode: ConsistencyOutbox})
+	defer sc.Close()
+	store := &outboxStore{}
+	sc.store = store
+	synchronizer := &outboxSynchronizer{}
+	sc.synchronizer = synchronizer
+	ctx := context.Background()
+
+	if err := sc.Set(ctx, "key", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if store.channel != "outbox-channel" || len(store.messages) != 1 || string(store.messages[0]) != "key" {
+		t.Errorf("Expected the event to be published in the write transaction, got %q on %q", store.messages, store.channel)
+	}
+	if len(synchronizer.events) != 0 {
+		t.Errorf("Expected no separate publish, got %d", len(synchronizer.events))
+	}
+	if _, ok := sc.local.Get("key"); !ok {
+		t.Error("Expected the local cache to be updated after the transaction")
+	}
+
+	store.txError = errors.New("transaction aborted")
+	if err := sc.Set(ctx, "other", "value"); err == nil {
+		t.Fatal("Expected Set to fail when the transaction fails")
+	}
+	if _, ok := sc.local.Get("other"); ok {
+		t.Error("Expected a failed transaction to leave the local cache untouched")
+	}
+	if len(store.messages) != 1 || len(synchronizer.events) != 0 {
+		t.Error("Expected nothing to be published for a failed transaction")
+	}
+	if len(synchronizer.canceled) != 1 || string(synchronizer.canceled[0]) != "other" {
+		t.Errorf("Expected the event of the failed transaction to be canceled, got %q", synchronizer.canceled)
+	}
+}
+
+func TestConsistencyOutboxFallsBackWithoutSupport(t *testing.T) {
+	sc := newMockedCache(t, Options{ReaderCanSetToRedis: true, ConsistencyMode: ConsistencyOutbox})
+	defer sc.Close()
+	sc.store = &outboxStore{}
+	publisher := &publishingSynchronizer{}
+	sc.synchronizer = publisher
+
+	if err := sc.Set(context.Background(), "key", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if len(publisher.events) != 1 {
+		t.Errorf("Expected the event to be published after the write, got %d events", len(publisher.events))
+	}
+}
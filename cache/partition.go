@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"github.com/huykn/distributed-cache/storage"
+)
+
+// PartitionStatus reports the health of one Redis instance of Options.RedisPartitions.
+type PartitionStatus = storage.PartitionStatus
+
+// newPartitionedStore connects to every address of Options.RedisPartitions and
+// returns the partitioned store along with the partition of the first address,
+// whose client carries synchronization by default.
+func newPartitionedStore(opts Options) (*storage.PartitionedStore, *storage.RedisStore, error) {
+	stores := make(map[string]*storage.RedisStore, len(opts.RedisPartitions))
+	closeAll := func() {
+		for _, store := range stores {
+			store.Close()
+		}
+	}
+
+	for _, addr := range opts.RedisPartitions {
+		if _, dup := stores[addr]; dup {
+			closeAll()
+			return nil, nil, ErrInvalidConfig
+		}
+		redisOpts := opts.redisOptions()
+		redisOpts.Addr = addr
+		store, err := storage.NewRedisStoreWithOptions(redisOpts)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		if opts.FaultInjector != nil {
+			store.GetClient().AddHook(faultHook{opts.FaultInjector})
+		}
+		stores[addr] = store
+	}
+
+	first := stores[opts.RedisPartitions[0]]
+	return storage.NewPartitionedStore(stores, opts.PartitionVNodes, opts.PartitionReplicas), first, nil
+}
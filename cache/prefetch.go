@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Prefetch warms the local cache with the given keys in the background.
+// Keys already present in the local cache are skipped and missing keys are
+// fetched from the remote store, in batches of a single round trip when the
+// store implements WarmupStore. The call does not block; values of ctx are
+// kept but its cancellation is ignored so that prefetching outlives the request
+// that triggered it, bounded by ContextTimeout and the cache lifetime. Failures
+// are reported via OnError.
+func (sc *SyncedCache) Prefetch(ctx context.Context, keys ...string) {
+	if atomic.LoadInt32(&sc.closed) != 0 || len(keys) == 0 {
+		return
+	}
+
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("Prefetch: scheduling keys", "count", len(keys))
+	}
+
+	parent := context.WithoutCancel(ctx)
+	sc.goBackground(func(bgCtx context.Context) {
+		ctx, cancel := context.WithTimeout(parent, sc.options.ContextTimeout)
+		defer cancel()
+		stop := context.AfterFunc(bgCtx, cancel)
+		defer stop()
+
+		if err := sc.Warmup(ctx, keys...); err != nil && bgCtx.Err() == nil {
+			sc.reportError(OpWarmup, "", ErrRemoteStore, err)
+			if sc.logging(DebugOps) {
+				sc.logger.Error("Prefetch: failed to warm keys", "error", err)
+			}
+		}
+	})
+}
+
+// prefetchRelated prefetches the keys suggested by Options.PrefetchHint for an accessed key.
+func (sc *SyncedCache) prefetchRelated(key string) {
+	if sc.options.PrefetchHint == nil {
+		return
+	}
+	if related := sc.options.PrefetchHint(key); len(related) > 0 {
+		sc.Prefetch(context.Background(), related...)
+	}
+}
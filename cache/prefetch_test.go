@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// waitForLocal polls the local cache until key is present or the timeout expires.
+func waitForLocal(c *SyncedCache, key string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, found := c.local.Get(key); found {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
+
+func TestSyncedCachePrefetch(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PodID = "test-pod-prefetch"
+	opts.RedisAddr = "localhost:6379"
+	opts.LocalCacheFactory = NewLRUCacheFactory(100)
+	opts.ReaderCanSetToRedis = true
+
+	c, err := New(opts)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	keys := []string{"test:prefetch:1", "test:prefetch:2", "test:prefetch:3"}
+	for _, key := range keys {
+		if err := c.Set(ctx, key, key); err != nil {
+			t.Fatalf("Failed to set value: %v", err)
+		}
+	}
+	c.local.Clear()
+
+	c.Prefetch(ctx, append(keys, "test:prefetch:missing")...)
+
+	for _, key := range keys {
+		if !waitForLocal(c, key, 2*time.Second) {
+			t.Fatalf("Expected %s to be prefetched into local cache", key)
+		}
+	}
+
+	_, found, info := c.GetWithInfo(ctx, keys[0])
+	if !found || info.Level != LevelLocal || info.Source != SourceRemote {
+		t.Fatalf("Expected local hit of prefetched value, got found=%v %s/%s", found, info.Level, info.Source)
+	}
+}
+
+func TestSyncedCachePrefetchHint(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PodID = "test-pod-prefetch-hint"
+	opts.RedisAddr = "localhost:6379"
+	opts.LocalCacheFactory = NewLRUCacheFactory(100)
+	opts.ReaderCanSetToRedis = true
+	opts.PrefetchHint = func(key string) []string {
+		if key == "test:prefetch:product:1" {
+			return []string{"test:prefetch:related:1"}
+		}
+		return nil
+	}
+
+	c, err := New(opts)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.Set(ctx, "test:prefetch:product:1", "product"); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := c.Set(ctx, "test:prefetch:related:1", "related"); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	c.local.Delete("test:prefetch:related:1")
+
+	if _, found := c.Get(ctx, "test:prefetch:product:1"); !found {
+		t.Fatal("Expected product to be found")
+	}
+
+	if !waitForLocal(c, "test:prefetch:related:1", 2*time.Second) {
+		t.Fatal("Expected related key to be prefetched after accessing product")
+	}
+}
+
+func TestSyncedCachePrefetchOnClosedCache(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PodID = "test-pod-prefetch-closed"
+	opts.RedisAddr = "localhost:6379"
+
+	c, err := New(opts)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	c.Close()
+
+	// Should be a no-op and must not panic
+	c.Prefetch(context.Background(), "test:prefetch:closed")
+	if c.goBackground(func(context.Context) {}) {
+		t.Fatal("Background work should not start after Close")
+	}
+}
+
+func TestSyncedCachePrefetchBatchesRemoteReads(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	store := &warmupStore{values: map[string][]byte{"a": []byte(`"1"`), "b": []byte(`"2"`)}}
+	c.store = store
+	c.setLocal("local", "kept", 1, SourceSet)
+
+	c.Prefetch(context.Background(), "a", "b", "missing", "local")
+	c.bgWG.Wait()
+
+	if len(store.batches) != 1 || len(store.batches[0]) != 3 {
+		t.Fatalf("Expected a single batch of 3 keys, got %v", store.batches)
+	}
+	for key, want := range map[string]string{"a": "1", "b": "2"} {
+		if value, found := c.local.Get(key); !found || value != want {
+			t.Errorf("Expected %s to be prefetched as %q, got %v", key, want, value)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// duplicateWarnInterval is the minimum time between two duplicate PodID warnings.
+const duplicateWarnInterval = time.Minute
+
+// peerExpiryHeartbeats is the number of heartbeat intervals after which a pod
+// that stopped sending heartbeats is no longer listed by Peers.
+const peerExpiryHeartbeats = 3
+
+// Peer is another pod sharing the sync channel, known from its heartbeats.
+type Peer struct {
+	PodID    string    `json:"pod_id"`
+	LastSeen time.Time `json:"last_seen"`         // when its last heartbeat was received
+	Version  string    `json:"version,omitempty"` // library version the pod runs
+}
+
+// heartbeat is the payload of a heartbeat event.
+type heartbeat struct {
+	Version string `json:"version,omitempty"`
+}
+
+// peerRegistry holds the peers seen by heartbeats. The zero value is ready to use.
+type peerRegistry struct {
+	mu    sync.Mutex
+	peers map[string]Peer
+}
+
+// seen records a heartbeat of peer.
+func (pr *peerRegistry) seen(peer Peer) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	if pr.peers == nil {
+		pr.peers = make(map[string]Peer)
+	}
+	pr.peers[peer.PodID] = peer
+}
+
+// remove forgets a peer, e.g. once it announced it is leaving.
+func (pr *peerRegistry) remove(podID string) {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	delete(pr.peers, podID)
+}
+
+// list returns the peers seen after since sorted by PodID, forgetting the others.
+func (pr *peerRegistry) list(since time.Time) []Peer {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+	peers := make([]Peer, 0, len(pr.peers))
+	for podID, peer := range pr.peers {
+		if peer.LastSeen.Before(since) {
+			delete(pr.peers, podID)
+			continue
+		}
+		peers = append(peers, peer)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].PodID < peers[j].PodID })
+	return peers
+}
+
+// Peers returns the other pods currently sharing the sync channel, sorted by
+// PodID. A pod is listed once its first heartbeat is received, and until it
+// announces it is leaving or misses three heartbeats of this pod's
+// Options.HeartbeatInterval, so pods should use the same interval. It returns
+// nil when HeartbeatInterval is not set.
+func (sc *SyncedCache) Peers() []Peer {
+	if sc.options.HeartbeatInterval <= 0 {
+		return nil
+	}
+	return sc.peers.list(time.Now().Add(-peerExpiryHeartbeats * sc.options.HeartbeatInterval))
+}
+
+// startHeartbeats starts publishing a heartbeat every Options.HeartbeatInterval.
+// The synchronizer keys heartbeats by its own instance, so a heartbeat received
+// with this pod's PodID and another instance reveals a duplicate PodID.
+// It is a no-op when the interval is not set.
+func (sc *SyncedCache) startHeartbeats() {
+	if sc.options.HeartbeatInterval <= 0 {
+		return
+	}
+	sc.goBackground(func(ctx context.Context) {
+		ticker := time.NewTicker(sc.options.HeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := sc.sendHeartbeat(ctx); err != nil {
+				sc.reportError(OpHeartbeat, "", ErrPublish, err)
+				if sc.logging(DebugSync) {
+					sc.logger.Error("Heartbeat: failed to publish", "error", err)
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// sendHeartbeat publishes a heartbeat of this pod.
+func (sc *SyncedCache) sendHeartbeat(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, sc.options.ContextTimeout)
+	defer cancel()
+
+	payload, err := json.Marshal(heartbeat{Version: Version})
+	if err != nil {
+		return err
+	}
+	return sc.synchronizer.Publish(ctx, InvalidationEvent{
+		Key:    "*",
+		Value:  payload,
+		Sender: sc.options.PodID,
+		Action: ActionHeartbeat,
+	})
+}
+
+// applyHeartbeat records the pod sending a heartbeat as a peer. Heartbeats
+// without a decodable payload still record the pod, with an unknown version.
+func (sc *SyncedCache) applyHeartbeat(event InvalidationEvent) {
+	var payload heartbeat
+	if len(event.Value) > 0 {
+		if err := json.Unmarshal(event.Value, &payload); err != nil && sc.logging(DebugSerialization) {
+			sc.logger.Error("Sync: failed to decode heartbeat", "sender", event.Sender, "error", err)
+		}
+	}
+	sc.peers.seen(Peer{PodID: event.Sender, LastSeen: time.Now(), Version: payload.Version})
+}
+
+// handleDuplicatePodID reports that another pod publishes with this pod's PodID:
+// both pods ignore each other's events as their own, so their local caches are
+// never invalidated by the other. It is always logged as it is a misconfiguration,
+// at most once per duplicateWarnInterval.
+func (sc *SyncedCache) handleDuplicatePodID() {
+	atomic.AddInt64(&sc.stats.DuplicatePodIDs, 1)
+	sc.reportError(OpHeartbeat, "", nil, ErrDuplicatePodID)
+
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&sc.lastDupWarn)
+	if last != 0 && now-last < int64(duplicateWarnInterval) {
+		return
+	}
+	if !atomic.CompareAndSwapInt64(&sc.lastDupWarn, last, now) {
+		return
+	}
+	sc.logger.Warn("Sync: another pod uses the same PodID, events between them are ignored", "pod_id", sc.options.PodID)
+}
+
+// ErrDuplicatePodID is reported via OnError when another pod publishes with the
+// same PodID.
+var ErrDuplicatePodID = NewError("another pod uses the same pod ID")
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSyncedCacheDuplicatePodIDWarnsOnce(t *testing.T) {
+	logger := &argsLogger{}
+	c := newMockedCache(t, Options{PodID: "pod-1"})
+	defer c.Close()
+	c.logger = logger
+
+	c.handleDuplicatePodID()
+	c.handleDuplicatePodID()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.calls) != 1 {
+		t.Fatalf("Expected a single warning per interval, got %d", len(logger.calls))
+	}
+}
+
+func TestGeneratePodIDIsUnique(t *testing.T) {
+	first, second := GeneratePodID(), GeneratePodID()
+	if first == "" || first == second {
+		t.Fatalf("Expected distinct pod IDs, got %q and %q", first, second)
+	}
+}
+
+func TestSyncedCacheHeartbeats(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1", HeartbeatInterval: time.Hour})
+	defer c.Close()
+	synchronizer := &publishingSynchronizer{}
+	c.synchronizer = synchronizer
+
+	if err := c.sendHeartbeat(context.Background()); err != nil {
+		t.Fatalf("sendHeartbeat failed: %v", err)
+	}
+	synchronizer.mu.Lock()
+	events := synchronizer.events
+	synchronizer.mu.Unlock()
+	if len(events) != 1 || events[0].Action != ActionHeartbeat || events[0].Sender != "pod-1" {
+		t.Fatalf("Expected a heartbeat of pod-1, got %+v", events)
+	}
+}
+
+func TestSyncedCacheDuplicatePodIDReported(t *testing.T) {
+	var reported []error
+	c := newMockedCache(t, Options{PodID: "pod-1", OnError: func(err error) {
+		reported = append(reported, err)
+	}})
+	defer c.Close()
+	c.logger = &argsLogger{}
+
+	c.handleDuplicatePodID()
+	c.handleDuplicatePodID()
+	if len(reported) != 2 || !errors.Is(reported[0], ErrDuplicatePodID) {
+		t.Fatalf("Expected ErrDuplicatePodID to be reported twice, got %v", reported)
+	}
+	if stats := c.Stats(); stats.DuplicatePodIDs != 2 {
+		t.Fatalf("Expected 2 duplicate pod IDs, got %d", stats.DuplicatePodIDs)
+	}
+}
+
+func TestSyncedCachePeers(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1", HeartbeatInterval: time.Hour})
+	defer c.Close()
+	synchronizer := &publishingSynchronizer{}
+	c.synchronizer = synchronizer
+
+	if err := c.sendHeartbeat(context.Background()); err != nil {
+		t.Fatalf("sendHeartbeat failed: %v", err)
+	}
+	synchronizer.mu.Lock()
+	beat := synchronizer.events[0]
+	synchronizer.mu.Unlock()
+	beat.Sender = "pod-2"
+	c.applyEvent(beat)
+	c.applyEvent(InvalidationEvent{Key: "*", Sender: "pod-0", Action: ActionHeartbeat})
+
+	peers := c.Peers()
+	if len(peers) != 2 || peers[0].PodID != "pod-0" || peers[1].PodID != "pod-2" {
+		t.Fatalf("Expected pod-0 and pod-2, got %+v", peers)
+	}
+	if peers[0].Version != "" || peers[1].Version != Version || peers[1].LastSeen.IsZero() {
+		t.Errorf("Expected the version announced by pod-2 only, got %+v", peers)
+	}
+
+	leaving, _ := json.Marshal(LifecycleEvent{Type: LifecyclePodLeaving, PodID: "pod-2"})
+	c.applyEvent(InvalidationEvent{Key: "*", Value: leaving, Sender: "pod-2", Action: ActionLifecycle})
+	if peers := c.Peers(); len(peers) != 1 || peers[0].PodID != "pod-0" {
+		t.Errorf("Expected pod-2 to be forgotten once leaving, got %+v", peers)
+	}
+
+	c.peers.seen(Peer{PodID: "pod-3", LastSeen: time.Now().Add(-peerExpiryHeartbeats * time.Hour)})
+	if peers := c.Peers(); len(peers) != 1 {
+		t.Errorf("Expected pod-3 to have expired, got %+v", peers)
+	}
+}
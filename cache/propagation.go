@@ -0,0 +1,69 @@
+package cache
+
+import "sort"
+
+// Propagation selects how a stored value is announced to other pods,
+// see Options.PropagationRules.
+type Propagation string
+
+const (
+	// PropagateValue sends the value to other pods, which store it locally (ActionSet).
+	PropagateValue Propagation = "value"
+	// PropagateInvalidate makes other pods drop the key and fetch it from Redis on
+	// their next Get, like SetWithInvalidate.
+	PropagateInvalidate Propagation = "invalidate"
+	// PropagateNone does not notify other pods: the value stays local to this pod,
+	// and in Redis when the write policy writes it.
+	PropagateNone Propagation = "none"
+)
+
+// propagationRule is a PropagationRules entry.
+type propagationRule struct {
+	pattern     string
+	propagation Propagation
+}
+
+// newPropagationRules returns the rules ordered from the longest pattern to the shortest.
+func newPropagationRules(rules map[string]Propagation) []propagationRule {
+	sorted := make([]propagationRule, 0, len(rules))
+	for pattern, propagation := range rules {
+		sorted = append(sorted, propagationRule{pattern: pattern, propagation: propagation})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i].pattern) != len(sorted[j].pattern) {
+			return len(sorted[i].pattern) > len(sorted[j].pattern)
+		}
+		return sorted[i].pattern < sorted[j].pattern
+	})
+	return sorted
+}
+
+// propagationFor returns the propagation of the longest rule pattern matching key.
+func (sc *SyncedCache) propagationFor(key string) (Propagation, bool) {
+	for _, rule := range sc.propRules {
+		if MatchPattern(rule.pattern, key) {
+			return rule.propagation, true
+		}
+	}
+	return "", false
+}
+
+// applyPropagationRule applies the rule matching key to c and reports whether
+// one applied. Rules do not override the per-call WithInvalidateOnly and
+// WithNoPropagate options, which take precedence.
+func (sc *SyncedCache) applyPropagationRule(c *setConfig, key string) bool {
+	if c.invalidateOnly || c.noPropagate {
+		return false
+	}
+	propagation, ok := sc.propagationFor(key)
+	if !ok {
+		return false
+	}
+	switch propagation {
+	case PropagateInvalidate:
+		c.invalidateOnly = true
+	case PropagateNone:
+		c.noPropagate = true
+	}
+	return true
+}
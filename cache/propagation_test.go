@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPropagationRules(t *testing.T) {
+	sc := newMockedCache(t, Options{PropagationRules: map[string]Propagation{
+		"session:*":       PropagateInvalidate,
+		"config:*":        PropagateValue,
+		"tmp:*":           PropagateNone,
+		"session:admin:*": PropagateValue,
+	}})
+	defer sc.Close()
+	publisher := &publishingSynchronizer{}
+	sc.synchronizer = publisher
+	ctx := context.Background()
+
+	for _, key := range []string{"session:1", "config:app", "tmp:scratch", "session:admin:1", "user:1"} {
+		if err := sc.Set(ctx, key, "value"); err != nil {
+			t.Fatalf("Set %s failed: %v", key, err)
+		}
+		if _, found := sc.Get(ctx, key); !found {
+			t.Errorf("Expected %s in the local cache", key)
+		}
+	}
+
+	want := map[string]Action{
+		"session:1":       ActionInvalidate,
+		"config:app":      ActionSet,
+		"session:admin:1": ActionSet,
+		"user:1":          ActionSet,
+	}
+	if len(publisher.events) != len(want) {
+		t.Fatalf("Expected %d published events, got %+v", len(want), publisher.events)
+	}
+	for _, event := range publisher.events {
+		if action, ok := want[event.Key]; !ok || event.Action != action {
+			t.Errorf("Unexpected event for %s: %s", event.Key, event.Action)
+		}
+	}
+}
+
+func TestPropagationRulesPrecedence(t *testing.T) {
+	sc := newMockedCache(t, Options{
+		WritePolicy:      WritePolicyInvalidateOnly,
+		PropagationRules: map[string]Propagation{"config:*": PropagateValue},
+	})
+	defer sc.Close()
+
+	tests := []struct {
+		name               string
+		key                string
+		opts               []SetOption
+		wantInvalidateOnly bool
+		wantNoPropagate    bool
+	}{
+		{"rule overrides write policy", "config:app", nil, false, false},
+		{"write policy without rule", "user:1", nil, true, false},
+		{"call overrides rule", "config:app", []SetOption{WithInvalidateOnly()}, true, false},
+		{"call skips propagation", "config:app", []SetOption{WithNoPropagate()}, true, true},
+		{"call write policy overrides rule", "config:app", []SetOption{WithWritePolicy(WritePolicyInvalidateOnly)}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := sc.setConfig(tt.key, tt.opts)
+			if cfg.invalidateOnly != tt.wantInvalidateOnly || cfg.noPropagate != tt.wantNoPropagate {
+				t.Errorf("Expected invalidateOnly=%v noPropagate=%v, got %+v", tt.wantInvalidateOnly, tt.wantNoPropagate, cfg)
+			}
+		})
+	}
+}
+
+func TestOptionsValidatePropagationRules(t *testing.T) {
+	for _, rules := range []map[string]Propagation{
+		{"": PropagateValue},
+		{"user:*": "broadcast"},
+	} {
+		opts := DefaultOptions()
+		opts.PropagationRules = rules
+		if err := opts.Validate(); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("Expected ErrInvalidConfig for %v, got %v", rules, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtoMarshaller serializes proto.Message values in the Protocol Buffers wire
+// format, so services exchanging protobuf messages store and propagate them
+// without a JSON round trip. Other values are serialized by a fallback Marshaller.
+//
+// Protobuf payloads do not describe their type: register the message type of
+// their key prefix in a TypeRegistry (Options.TypeRegistry), e.g.
+// Register("feed:", &pb.Feed{}, 1), or return it from Options.DecodeInto, so
+// values read from Redis and received from other pods decode into it. Protobuf
+// decodes messages written by other versions of their type on its own;
+// SchemaConverters, which receive values decoded into an any, cannot be used.
+type ProtoMarshaller struct {
+	fallback Marshaller
+}
+
+// NewProtoMarshaller creates a marshaller serializing proto.Message values as
+// protobuf and other values with fallback, or as JSON when fallback is nil.
+func NewProtoMarshaller(fallback Marshaller) *ProtoMarshaller {
+	if fallback == nil {
+		fallback = NewJSONMarshaller()
+	}
+	return &ProtoMarshaller{fallback: fallback}
+}
+
+// Marshal serializes v as protobuf when it is a proto.Message, and with the
+// fallback Marshaller otherwise.
+func (pm *ProtoMarshaller) Marshal(v any) ([]byte, error) {
+	if msg, ok := v.(proto.Message); ok {
+		return proto.Marshal(msg)
+	}
+	return pm.fallback.Marshal(v)
+}
+
+// Unmarshal deserializes protobuf data into v when it is a proto.Message, and
+// with the fallback Marshaller otherwise. Protobuf payloads cannot be decoded
+// into an any; decoding them with the fallback fails.
+func (pm *ProtoMarshaller) Unmarshal(data []byte, v any) error {
+	if msg, ok := v.(proto.Message); ok {
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return fmt.Errorf("decoding %T: %w", msg, err)
+		}
+		return nil
+	}
+	return pm.fallback.Unmarshal(data, v)
+}
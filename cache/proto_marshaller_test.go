@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestProtoMarshaller(t *testing.T) {
+	pm := NewProtoMarshaller(nil)
+
+	data, err := pm.Marshal(wrapperspb.String("hello"))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var msg wrapperspb.StringValue
+	if err := pm.Unmarshal(data, &msg); err != nil || msg.GetValue() != "hello" {
+		t.Fatalf("Expected the message back, got %v (err=%v)", msg.GetValue(), err)
+	}
+
+	// Other values use the JSON fallback
+	data, err = pm.Marshal(map[string]any{"name": "alice"})
+	if err != nil || string(data) != `{"name":"alice"}` {
+		t.Fatalf("Expected JSON for other values, got %s (err=%v)", data, err)
+	}
+	var value any
+	if err := pm.Unmarshal(data, &value); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+}
+
+func TestSyncedCacheProtoValues(t *testing.T) {
+	ctx := context.Background()
+	types := NewTypeRegistry()
+	types.Register("greeting:", &wrapperspb.StringValue{}, 1)
+
+	pod1 := newMockedCache(t, Options{PodID: "pod-1", TypeRegistry: types})
+	defer pod1.Close()
+	pod1.serializer = NewProtoMarshaller(nil)
+	sync1 := &publishingSynchronizer{}
+	pod1.synchronizer = sync1
+	if err := pod1.Set(ctx, "greeting:1", wrapperspb.String("hello")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	pod2 := newMockedCache(t, Options{PodID: "pod-2", TypeRegistry: types})
+	defer pod2.Close()
+	pod2.serializer = NewProtoMarshaller(nil)
+	pod2.applyEvent(sync1.events[0])
+
+	value, found := pod2.Get(ctx, "greeting:1")
+	msg, ok := value.(*wrapperspb.StringValue)
+	if !found || !ok || !proto.Equal(msg, wrapperspb.String("hello")) {
+		t.Fatalf("Expected the propagated message, got %#v", value)
+	}
+}
@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultPublishRetries is the number of times a failed publish is retried when
+// Options.PublishRetries is not set.
+const defaultPublishRetries = 10
+
+// defaultPublishRetryBackoff is the delay before the first retry when
+// Options.PublishRetryBackoff is not set.
+const defaultPublishRetryBackoff = 100 * time.Millisecond
+
+// maxPublishRetryBackoff caps the exponential backoff between retries.
+const maxPublishRetryBackoff = 30 * time.Second
+
+// publishRetryPollInterval is how often the retry queue is checked for due events.
+const publishRetryPollInterval = 50 * time.Millisecond
+
+// pendingPublish is a synchronization event waiting to be published again.
+type pendingPublish struct {
+	event    InvalidationEvent
+	attempts int
+	due      time.Time
+}
+
+// publishRetryQueue holds the latest failed event per key, in the order keys were
+// first queued, so a retried event never overwrites a newer one on other pods.
+type publishRetryQueue struct {
+	mu      sync.Mutex
+	size    int
+	keys    []string
+	pending map[string]*pendingPublish
+}
+
+// newPublishRetryQueue creates a queue holding events for at most size keys.
+func newPublishRetryQueue(size int) *publishRetryQueue {
+	return &publishRetryQueue{size: size, pending: make(map[string]*pendingPublish)}
+}
+
+// push queues p, replacing an older event for the same key. It returns false when full.
+func (q *publishRetryQueue) push(p *pendingPublish) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, queued := q.pending[p.event.Key]; !queued {
+		if len(q.keys) >= q.size {
+			return false
+		}
+		q.keys = append(q.keys, p.event.Key)
+	}
+	q.pending[p.event.Key] = p
+	return true
+}
+
+// requeue queues p again after a failed retry, unless a newer event for the same
+// key was queued meanwhile. It returns false when full.
+func (q *publishRetryQueue) requeue(p *pendingPublish) bool {
+	q.mu.Lock()
+	_, queued := q.pending[p.event.Key]
+	q.mu.Unlock()
+	if queued {
+		return true
+	}
+	return q.push(p)
+}
+
+// forget drops the queued event for key, superseded by a newer published event.
+// A published clear event supersedes every queued event.
+func (q *publishRetryQueue) forget(event InvalidationEvent) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if event.Action == ActionClear {
+		q.keys = nil
+		q.pending = make(map[string]*pendingPublish)
+		return
+	}
+	if _, queued := q.pending[event.Key]; !queued {
+		return
+	}
+	delete(q.pending, event.Key)
+	for i, key := range q.keys {
+		if key == event.Key {
+			q.keys = append(q.keys[:i], q.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// takeDue removes and returns the events due at now, in queue order.
+func (q *publishRetryQueue) takeDue(now time.Time) []*pendingPublish {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var due []*pendingPublish
+	keys := q.keys[:0]
+	for _, key := range q.keys {
+		p := q.pending[key]
+		if p.due.After(now) {
+			keys = append(keys, key)
+			continue
+		}
+		due = append(due, p)
+		delete(q.pending, key)
+	}
+	q.keys = keys
+	return due
+}
+
+// len returns the number of queued events.
+func (q *publishRetryQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.keys)
+}
+
+// trackPublish records the outcome of publishing event. A failed event is queued
+// for retry when Options.PublishQueueSize is set; a successful one supersedes the
+// queued event for the same key.
+func (sc *SyncedCache) trackPublish(event InvalidationEvent, err error) {
+	if sc.retryQueue == nil {
+		return
+	}
+	if err == nil {
+		sc.retryQueue.forget(event)
+		return
+	}
+	p := &pendingPublish{event: event, due: time.Now().Add(sc.publishBackoff(0))}
+	if !sc.retryQueue.push(p) {
+		sc.dropPublish(event, ErrPublishQueueFull)
+	}
+}
+
+// publishBackoff returns the delay before retry attempt+1, doubling from
+// PublishRetryBackoff up to maxPublishRetryBackoff.
+func (sc *SyncedCache) publishBackoff(attempt int) time.Duration {
+	backoff := sc.options.PublishRetryBackoff
+	if backoff <= 0 {
+		backoff = defaultPublishRetryBackoff
+	}
+	for i := 0; i < attempt && backoff < maxPublishRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxPublishRetryBackoff)
+}
+
+// runPublishRetries publishes queued events when they are due until ctx is
+// cancelled, then makes a last attempt for every queued event.
+func (sc *SyncedCache) runPublishRetries(ctx context.Context) {
+	ticker := time.NewTicker(publishRetryPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, p := range sc.retryQueue.takeDue(time.Now()) {
+				sc.retryPublish(ctx, p, false)
+			}
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), sc.options.ContextTimeout)
+			for _, p := range sc.retryQueue.takeDue(time.Now().Add(maxPublishRetryBackoff)) {
+				sc.retryPublish(flushCtx, p, true)
+			}
+			cancel()
+			return
+		}
+	}
+}
+
+// retryPublish publishes a queued event again, queueing it for a later attempt on
+// failure until PublishRetries is exhausted or last is set.
+func (sc *SyncedCache) retryPublish(parent context.Context, p *pendingPublish, last bool) {
+	ctx, cancel := context.WithTimeout(parent, sc.options.ContextTimeout)
+	defer cancel()
+
+	err := sc.publish(ctx, p.event)
+	sc.breaker.record(err)
+	if err == nil {
+		if sc.debugging(DebugSync) {
+			sc.logger.Debug("Publish: retried event published", "key", p.event.Key, "action", p.event.Action, "attempts", p.attempts+1)
+		}
+		return
+	}
+
+	p.attempts++
+	retries := sc.options.PublishRetries
+	if retries <= 0 {
+		retries = defaultPublishRetries
+	}
+	if last || p.attempts >= retries {
+		sc.dropPublish(p.event, err)
+		return
+	}
+	p.due = time.Now().Add(sc.publishBackoff(p.attempts))
+	if !sc.retryQueue.requeue(p) {
+		sc.dropPublish(p.event, ErrPublishQueueFull)
+	}
+}
+
+// dropPublish gives up on publishing event, reporting it via OnPublishDropped.
+func (sc *SyncedCache) dropPublish(event InvalidationEvent, err error) {
+	atomic.AddInt64(&sc.stats.DroppedPublishes, 1)
+	if sc.options.OnPublishDropped != nil {
+		sc.options.OnPublishDropped(event, err)
+	}
+	if sc.logging(DebugSync) {
+		sc.logger.Warn("Publish: dropped synchronization event", "key", event.Key, "action", event.Action, "error", err)
+	}
+}
+
+// ErrPublishQueueFull is passed to OnPublishDropped for events dropped because the
+// publish retry queue was full.
+var ErrPublishQueueFull = NewError("publish retry queue is full")
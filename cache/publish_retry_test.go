@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakySynchronizer fails every Publish while down is set and records the
+// events published otherwise.
+type flakySynchronizer struct {
+	publishingSynchronizer
+	down atomic.Bool
+}
+
+func (fs *flakySynchronizer) Publish(ctx context.Context, event InvalidationEvent) error {
+	if fs.down.Load() {
+		return errors.New("pubsub down")
+	}
+	return fs.publishingSynchronizer.Publish(ctx, event)
+}
+
+func (fs *flakySynchronizer) published() []InvalidationEvent {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	return append([]InvalidationEvent(nil), fs.events...)
+}
+
+func newRetryingCache(t *testing.T, opts Options) (*SyncedCache, *flakySynchronizer) {
+	t.Helper()
+	sc := newMockedCache(t, opts)
+	flaky := &flakySynchronizer{}
+	sc.synchronizer = flaky
+	sc.retryQueue = newPublishRetryQueue(opts.PublishQueueSize)
+	sc.goBackground(sc.runPublishRetries)
+	return sc, flaky
+}
+
+func TestPublishRetryRepublishesLatestEvent(t *testing.T) {
+	sc, flaky := newRetryingCache(t, Options{PublishQueueSize: 10, PublishRetryBackoff: 10 * time.Millisecond})
+	defer sc.Close()
+	ctx := context.Background()
+
+	flaky.down.Store(true)
+	sc.publishSet(ctx, "key", []byte(`"v1"`), setConfig{})
+	sc.publishSet(ctx, "key", []byte(`"v2"`), setConfig{})
+	if pending := sc.Stats().PendingPublishes; pending != 1 {
+		t.Fatalf("Expected only the latest event per key to be queued, got %d", pending)
+	}
+
+	flaky.down.Store(false)
+	deadline := time.Now().Add(time.Second)
+	for len(flaky.published()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	events := flaky.published()
+	if len(events) != 1 || string(events[0].Value) != `"v2"` {
+		t.Fatalf("Expected the latest event to be republished once, got %+v", events)
+	}
+	if pending := sc.Stats().PendingPublishes; pending != 0 {
+		t.Errorf("Expected the queue to be empty, got %d", pending)
+	}
+}
+
+func TestPublishRetrySupersededBySuccessfulPublish(t *testing.T) {
+	sc, flaky := newRetryingCache(t, Options{PublishQueueSize: 10, PublishRetryBackoff: time.Hour})
+	defer sc.Close()
+	ctx := context.Background()
+
+	flaky.down.Store(true)
+	sc.publishSet(ctx, "a", []byte(`"old"`), setConfig{})
+	sc.publishSet(ctx, "b", []byte(`"old"`), setConfig{})
+	flaky.down.Store(false)
+
+	sc.publishSet(ctx, "a", []byte(`"new"`), setConfig{})
+	if pending := sc.Stats().PendingPublishes; pending != 1 {
+		t.Fatalf("Expected the newer event to supersede the queued one, got %d pending", pending)
+	}
+	if err := sc.Clear(ctx); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if pending := sc.Stats().PendingPublishes; pending != 0 {
+		t.Errorf("Expected a published clear event to supersede every queued event, got %d pending", pending)
+	}
+}
+
+func TestPublishRetryDropsEvents(t *testing.T) {
+	var mu sync.Mutex
+	dropped := make(map[string]error)
+	opts := Options{
+		PublishQueueSize:    1,
+		PublishRetries:      2,
+		PublishRetryBackoff: 5 * time.Millisecond,
+		OnPublishDropped: func(event InvalidationEvent, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			dropped[event.Key] = err
+		},
+	}
+	sc, flaky := newRetryingCache(t, opts)
+	defer sc.Close()
+	ctx := context.Background()
+
+	flaky.down.Store(true)
+	sc.publishSet(ctx, "a", []byte(`"v"`), setConfig{})
+	sc.publishSet(ctx, "b", []byte(`"v"`), setConfig{})
+
+	deadline := time.Now().Add(time.Second)
+	for sc.Stats().DroppedPublishes < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !errors.Is(dropped["b"], ErrPublishQueueFull) {
+		t.Errorf("Expected the overflowing event to be dropped with ErrPublishQueueFull, got %v", dropped["b"])
+	}
+	if err := dropped["a"]; err == nil || errors.Is(err, ErrPublishQueueFull) {
+		t.Errorf("Expected the queued event to be dropped with the publish error after its retries, got %v", err)
+	}
+}
+
+func TestPublishBackoff(t *testing.T) {
+	sc := newMockedCache(t, Options{PublishRetryBackoff: time.Second})
+	defer sc.Close()
+
+	if got := sc.publishBackoff(0); got != time.Second {
+		t.Errorf("Expected the first retry after 1s, got %s", got)
+	}
+	if got := sc.publishBackoff(3); got != 8*time.Second {
+		t.Errorf("Expected the backoff to double, got %s", got)
+	}
+	if got := sc.publishBackoff(20); got != maxPublishRetryBackoff {
+		t.Errorf("Expected the backoff to be capped, got %s", got)
+	}
+}
@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxCoalescedKeys bounds the keys waiting for an event under RateLimitCoalesce.
+// Events over the limit are dropped once that many keys are pending.
+const maxCoalescedKeys = 10000
+
+// RateLimitPolicy selects what happens to the synchronization events published by
+// Set and Delete over Options.PublishRateLimit.
+type RateLimitPolicy string
+
+const (
+	// RateLimitCoalesce replaces the events over the limit by an invalidation of
+	// their key, or a deletion for Delete, published once the rate allows. Writes
+	// to a key whose event is pending are coalesced into that single event.
+	RateLimitCoalesce RateLimitPolicy = "coalesce"
+	// RateLimitDrop drops the events over the limit. Other pods keep serving their
+	// local value until it expires or is repaired by anti-entropy.
+	RateLimitDrop RateLimitPolicy = "drop"
+)
+
+// tokenBucket allows rate operations per second on average, with bursts of up
+// to burst operations. A nil bucket allows every operation.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a bucket allowing rate operations per second, or nil
+// when rate is 0. A burst of 0 allows one second worth of operations.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = int(math.Ceil(rate))
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// allow takes a token if one is available and reports whether it did.
+func (tb *tokenBucket) allow() bool {
+	if tb == nil {
+		return true
+	}
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	now := time.Now()
+	tb.tokens = math.Min(tb.burst, tb.tokens+now.Sub(tb.last).Seconds()*tb.rate)
+	tb.last = now
+	if tb.tokens < 1 {
+		return false
+	}
+	tb.tokens--
+	return true
+}
+
+// coalescedEvents holds the keys whose event was deferred by RateLimitCoalesce,
+// with the action to publish for each.
+type coalescedEvents struct {
+	mu      sync.Mutex
+	actions map[string]Action
+}
+
+// add defers the event for key, replacing any event pending for it. It reports
+// false when maxCoalescedKeys other keys are already pending.
+func (ce *coalescedEvents) add(key string, action Action) bool {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	if _, pending := ce.actions[key]; !pending && len(ce.actions) >= maxCoalescedKeys {
+		return false
+	}
+	ce.actions[key] = action
+	return true
+}
+
+// len returns the number of pending keys.
+func (ce *coalescedEvents) len() int {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	return len(ce.actions)
+}
+
+// take removes and returns one pending key and its action.
+func (ce *coalescedEvents) take() (string, Action, bool) {
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	for key, action := range ce.actions {
+		delete(ce.actions, key)
+		return key, action, true
+	}
+	return "", "", false
+}
+
+// allowPublish reports whether event may be published under Options.PublishRateLimit.
+// Events over the limit are coalesced or dropped following Options.RateLimitPolicy.
+func (sc *SyncedCache) allowPublish(event InvalidationEvent) bool {
+	if sc.publishLimit.allow() {
+		return true
+	}
+	if sc.coalesced != nil {
+		action := ActionInvalidate
+		if event.Action == ActionDelete {
+			action = ActionDelete
+		}
+		if sc.coalesced.add(event.Key, action) {
+			atomic.AddInt64(&sc.stats.CoalescedEvents, 1)
+			return false
+		}
+	}
+	atomic.AddInt64(&sc.stats.RateLimitedEvents, 1)
+	if sc.debugging(DebugSync) {
+		sc.logger.Debug("Sync: dropped event over PublishRateLimit", "key", event.Key, "action", event.Action)
+	}
+	return false
+}
+
+// limitWrite returns ErrRateLimited when a Redis write by op on key exceeds
+// Options.WriteRateLimit.
+func (sc *SyncedCache) limitWrite(op, key string) error {
+	if sc.writeLimit.allow() {
+		return nil
+	}
+	atomic.AddInt64(&sc.stats.RateLimitedWrites, 1)
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("Write rejected over WriteRateLimit", "op", op, "key", key)
+	}
+	return ErrRateLimited
+}
+
+// runCoalescedEvents publishes the events deferred by RateLimitCoalesce as the
+// rate allows, and every remaining one when the cache is closed.
+func (sc *SyncedCache) runCoalescedEvents(ctx context.Context) {
+	interval := time.Duration(float64(time.Second) / sc.options.PublishRateLimit)
+	if interval < time.Millisecond {
+		interval = time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for sc.coalesced.len() > 0 && sc.publishLimit.allow() {
+				sc.publishCoalesced(ctx)
+			}
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), sc.options.ContextTimeout)
+			for sc.publishCoalesced(flushCtx) {
+			}
+			cancel()
+			return
+		}
+	}
+}
+
+// publishCoalesced publishes one deferred event. It reports false when none is pending.
+func (sc *SyncedCache) publishCoalesced(ctx context.Context) bool {
+	key, action, ok := sc.coalesced.take()
+	if !ok {
+		return false
+	}
+	event := sc.stamp(InvalidationEvent{Key: key, Sender: sc.options.PodID, Action: action})
+	ctx, cancel := context.WithTimeout(ctx, sc.options.ContextTimeout)
+	defer cancel()
+	err := sc.publish(ctx, event)
+	sc.trackPublish(event, err)
+	if err != nil {
+		sc.reportError(OpSync, key, ErrPublish, err)
+		if sc.logging(DebugSync) {
+			sc.logger.Warn("Sync: failed to publish coalesced event", "key", key, "action", action, "error", err)
+		}
+	}
+	return true
+}
+
+// ErrRateLimited is returned by Set and Delete when their Redis write exceeds
+// Options.WriteRateLimit.
+var ErrRateLimited = NewError("write rate limit exceeded")
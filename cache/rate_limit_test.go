@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTokenBucket(t *testing.T) {
+	var unlimited *tokenBucket
+	if !unlimited.allow() {
+		t.Fatal("Expected a nil bucket to allow every operation")
+	}
+
+	tb := newTokenBucket(0.001, 2)
+	if !tb.allow() || !tb.allow() {
+		t.Fatal("Expected the burst to be allowed")
+	}
+	if tb.allow() {
+		t.Fatal("Expected operations over the burst to be denied")
+	}
+	if newTokenBucket(2.5, 0).burst != 3 {
+		t.Error("Expected a default burst of one second worth of operations")
+	}
+}
+
+func TestSyncedCachePublishRateLimitCoalesce(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1"})
+	defer c.Close()
+	synchronizer := &publishingSynchronizer{}
+	c.synchronizer = synchronizer
+	c.publishLimit = newTokenBucket(0.001, 1)
+	c.coalesced = &coalescedEvents{actions: make(map[string]Action)}
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b", "b", "b"} {
+		if err := c.Set(ctx, key, "value"); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	if err := c.Delete(ctx, "c"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if len(synchronizer.events) != 1 || synchronizer.events[0].Key != "a" {
+		t.Fatalf("Expected only the first event to be published, got %+v", synchronizer.events)
+	}
+	if stats := c.Stats(); stats.CoalescedEvents != 4 || c.coalesced.len() != 2 {
+		t.Fatalf("Expected 4 events coalesced into 2 keys, got %d and %d", stats.CoalescedEvents, c.coalesced.len())
+	}
+
+	for c.publishCoalesced(ctx) {
+	}
+	actions := make(map[string]Action)
+	for _, event := range synchronizer.events[1:] {
+		actions[event.Key] = event.Action
+	}
+	if len(actions) != 2 || actions["b"] != ActionInvalidate || actions["c"] != ActionDelete {
+		t.Errorf("Expected an invalidation of b and a deletion of c, got %v", actions)
+	}
+}
+
+func TestSyncedCachePublishRateLimitDrop(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1"})
+	defer c.Close()
+	synchronizer := &publishingSynchronizer{}
+	c.synchronizer = synchronizer
+	c.publishLimit = newTokenBucket(0.001, 1)
+	ctx := context.Background()
+
+	_ = c.Set(ctx, "a", "value")
+	_ = c.Set(ctx, "b", "value")
+	if stats := c.Stats(); len(synchronizer.events) != 1 || stats.RateLimitedEvents != 1 {
+		t.Fatalf("Expected one event published and one dropped, got %d and %d", len(synchronizer.events), stats.RateLimitedEvents)
+	}
+}
+
+func TestSyncedCacheWriteRateLimit(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1", WritePolicy: WritePolicyWriteThrough})
+	defer c.Close()
+	synchronizer := &publishingSynchronizer{}
+	c.synchronizer = synchronizer
+	c.writeLimit = newTokenBucket(0.001, 1)
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "a", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := c.Set(ctx, "b", "value"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	if err := c.Delete(ctx, "a"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	if stats := c.Stats(); stats.RateLimitedWrites != 2 || len(synchronizer.events) != 1 {
+		t.Errorf("Expected 2 rejected writes and a single event, got %d and %d", stats.RateLimitedWrites, len(synchronizer.events))
+	}
+}
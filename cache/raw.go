@@ -0,0 +1,31 @@
+package cache
+
+import "context"
+
+// GetRaw retrieves the serialized bytes of a value, as encoded by the Marshaller,
+// for handlers writing them to a response as-is. With Options.KeepRawBytes the
+// bytes received on Set, remote Get or propagation are served; otherwise, or
+// once they are no longer tracked, the value is marshalled again. The bytes held
+// under LocalValueRaw are served as they are.
+//
+// Values transformed by OnSetLocalCache are served as the bytes they were
+// received as, not as their transformed form.
+func (sc *SyncedCache) GetRaw(ctx context.Context, key string) ([]byte, bool) {
+	value, found := sc.Get(ctx, key)
+	if !found {
+		return nil, false
+	}
+	if raw, ok := sc.entryInfos.raw(key); ok {
+		return sc.payload(key, raw), true
+	}
+
+	data, err := sc.marshalLocal(key, value)
+	if err != nil {
+		sc.reportError(OpGet, key, ErrSerialization, err)
+		if sc.logging(DebugSerialization) {
+			sc.logger.Error("GetRaw: serialization failed", "key", key, "error", err)
+		}
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetRawKeepsReceivedBytes(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	c.entryInfos.keepRaw = true
+	ctx := context.Background()
+
+	// Propagated bytes are served as received, not as re-encoded by the Marshaller
+	received := []byte(`{"name": "alice"}`)
+	c.applyEvent(InvalidationEvent{Key: "user:1", Action: ActionSet, Value: received})
+	raw, found := c.GetRaw(ctx, "user:1")
+	if !found || string(raw) != string(received) {
+		t.Fatalf("Expected %s, got %s (found=%v)", received, raw, found)
+	}
+
+	if err := c.Set(ctx, "user:2", map[string]any{"name": "bob"}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if raw, found := c.GetRaw(ctx, "user:2"); !found || string(raw) != `{"name":"bob"}` {
+		t.Fatalf("Expected the serialized value, got %s (found=%v)", raw, found)
+	}
+
+	if _, found := c.GetRaw(ctx, "missing"); found {
+		t.Fatal("Expected a miss")
+	}
+}
+
+func TestGetRawMarshalsWithoutKeptBytes(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	ctx := context.Background()
+
+	c.applyEvent(InvalidationEvent{Key: "user:1", Action: ActionSet, Value: []byte(`{"name": "alice"}`)})
+	raw, found := c.GetRaw(ctx, "user:1")
+	if !found || string(raw) != `{"name":"alice"}` {
+		t.Fatalf("Expected the re-encoded value, got %s (found=%v)", raw, found)
+	}
+}
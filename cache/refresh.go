@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// refreshSchedule holds the keys registered with RefreshAhead.
+type refreshSchedule struct {
+	mu    sync.Mutex
+	stops map[string]context.CancelFunc
+}
+
+// replace registers stop for key, stopping the refresh loop it replaces.
+func (rs *refreshSchedule) replace(key string, stop context.CancelFunc) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if previous := rs.stops[key]; previous != nil {
+		previous()
+	}
+	if stop == nil {
+		delete(rs.stops, key)
+		return
+	}
+	if rs.stops == nil {
+		rs.stops = make(map[string]context.CancelFunc)
+	}
+	rs.stops[key] = stop
+}
+
+// RefreshAhead keeps a hot key fresh by loading it with loader every interval and
+// storing the result like Set, which propagates it to the other pods, so reads of
+// the key never miss or wait for the source. Pick an interval shorter than the
+// key's TTL so it is refreshed before it expires. With Options.LeaderElection only
+// the leader refreshes; otherwise every pod does. A nil value returned by loader
+// leaves the cached value as is.
+//
+// Calling RefreshAhead again for key replaces its interval and loader, and an
+// interval of 0 stops refreshing it. Refreshing stops when the cache is closed.
+func (sc *SyncedCache) RefreshAhead(key string, interval time.Duration, loader LoaderFunc) error {
+	if sc.options.ReadOnly {
+		return ErrReadOnly
+	}
+	if interval < 0 || (interval > 0 && loader == nil) {
+		return ErrInvalidRefresh
+	}
+	if interval == 0 {
+		sc.refreshes.replace(key, nil)
+		return nil
+	}
+
+	ctx, stop := context.WithCancel(sc.bgCtx)
+	sc.refreshes.replace(key, stop)
+	started := sc.goBackground(func(context.Context) {
+		defer stop()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if !sc.options.LeaderElection || sc.IsLeader() {
+				sc.refresh(ctx, key, loader)
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+	if !started {
+		stop()
+		return ErrCacheClosed
+	}
+	return nil
+}
+
+// refresh loads key with loader and stores the value, reporting failures as OpRefresh.
+func (sc *SyncedCache) refresh(ctx context.Context, key string, loader LoaderFunc) {
+	ctx, cancel := context.WithTimeout(ctx, sc.options.ContextTimeout)
+	defer cancel()
+
+	value, err := loader(ctx, key)
+	if err != nil {
+		if ctx.Err() == nil {
+			sc.reportError(OpRefresh, key, nil, err)
+			if sc.logging(DebugOps) {
+				sc.logger.Error("RefreshAhead: loader failed", "key", key, "error", err)
+			}
+		}
+		return
+	}
+	if value == nil {
+		if sc.debugging(DebugOps) {
+			sc.logger.Debug("RefreshAhead: not found by loader", "key", key)
+		}
+		return
+	}
+	// Set reports its own failures via OnError
+	if sc.Set(ctx, key, value) == nil {
+		atomic.AddInt64(&sc.stats.Refreshes, 1)
+		if sc.debugging(DebugOps) {
+			sc.logger.Debug("RefreshAhead: refreshed key", "key", key)
+		}
+	}
+}
+
+// ErrInvalidRefresh is returned by RefreshAhead for a negative interval or a
+// nil loader.
+var ErrInvalidRefresh = NewError("refresh ahead requires a positive interval and a loader")
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSyncedCacheRefreshAhead(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1"})
+	defer c.Close()
+	synchronizer := &publishingSynchronizer{}
+	c.synchronizer = synchronizer
+
+	var loads int32
+	err := c.RefreshAhead("config", 10*time.Millisecond, func(ctx context.Context, key string) (any, error) {
+		return int(atomic.AddInt32(&loads, 1)), nil
+	})
+	if err != nil {
+		t.Fatalf("RefreshAhead failed: %v", err)
+	}
+	waitFor(t, "the key to be refreshed twice", func() bool {
+		return c.Stats().Refreshes >= 2
+	})
+	if value, found := c.local.Get("config"); !found || value.(int) < 2 {
+		t.Errorf("Expected a refreshed value, got %v (found=%v)", value, found)
+	}
+	synchronizer.mu.Lock()
+	published := len(synchronizer.events)
+	synchronizer.mu.Unlock()
+	if published < 2 {
+		t.Errorf("Expected refreshed values to be propagated, got %d events", published)
+	}
+
+	if err := c.RefreshAhead("config", 0, nil); err != nil {
+		t.Fatalf("Stopping RefreshAhead failed: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	stopped := atomic.LoadInt32(&loads)
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&loads) != stopped {
+		t.Error("Expected refreshing to stop with an interval of 0")
+	}
+}
+
+func TestSyncedCacheRefreshAheadLeaderOnly(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1", LeaderElection: true})
+	defer c.Close()
+
+	var loads int32
+	err := c.RefreshAhead("config", 5*time.Millisecond, func(ctx context.Context, key string) (any, error) {
+		atomic.AddInt32(&loads, 1)
+		return "value", nil
+	})
+	if err != nil {
+		t.Fatalf("RefreshAhead failed: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&loads) != 0 {
+		t.Fatal("Expected followers not to refresh")
+	}
+
+	c.setLeader(true)
+	waitFor(t, "the leader to refresh the key", func() bool {
+		return c.Stats().Refreshes > 0
+	})
+}
+
+func TestSyncedCacheRefreshAheadErrors(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1"})
+	loader := func(ctx context.Context, key string) (any, error) { return nil, nil }
+
+	if err := c.RefreshAhead("config", -time.Second, loader); !errors.Is(err, ErrInvalidRefresh) {
+		t.Errorf("Expected ErrInvalidRefresh for a negative interval, got %v", err)
+	}
+	if err := c.RefreshAhead("config", time.Second, nil); !errors.Is(err, ErrInvalidRefresh) {
+		t.Errorf("Expected ErrInvalidRefresh for a nil loader, got %v", err)
+	}
+	c.Close()
+	if err := c.RefreshAhead("config", time.Second, loader); !errors.Is(err, ErrCacheClosed) {
+		t.Errorf("Expected ErrCacheClosed after Close, got %v", err)
+	}
+
+	readOnly := newMockedCache(t, Options{PodID: "pod-1", ReadOnly: true})
+	defer readOnly.Close()
+	if err := readOnly.RefreshAhead("config", time.Second, loader); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+}
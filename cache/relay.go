@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// relayRemoteHit publishes a value this pod read from Redis after a local miss to
+// the other pods, see Options.RelayRemoteHits. The relay is stamped with readAt,
+// the hybrid logical clock taken before the read, so pods that already applied a
+// newer write discard it. Keys that would not propagate their value on Set,
+// because of WritePolicy, PropagationRules or MaxPropagationSize, are not relayed,
+// and relays are skipped rather than coalesced over PublishRateLimit.
+func (sc *SyncedCache) relayRemoteHit(key string, data []byte, readAt uint64) {
+	if !sc.options.RelayRemoteHits || sc.options.WritePolicy == WritePolicyInvalidateOnly {
+		return
+	}
+	if propagation, ok := sc.propagationFor(key); ok && propagation != PropagateValue {
+		return
+	}
+	if sc.options.MaxPropagationSize > 0 && len(data) > sc.options.MaxPropagationSize {
+		return
+	}
+	if !sc.publishLimit.allow() {
+		return
+	}
+
+	sc.goBackground(func(bgCtx context.Context) {
+		ctx, cancel := context.WithTimeout(bgCtx, sc.options.ContextTimeout)
+		defer cancel()
+
+		err := sc.synchronizer.Publish(ctx, InvalidationEvent{
+			Key:    key,
+			Sender: sc.options.PodID,
+			Action: ActionSet,
+			Value:  data,
+			Relay:  true,
+			Clock:  readAt,
+		})
+		if err != nil {
+			sc.reportError(OpGet, key, ErrPublish, err)
+			if sc.logging(DebugSync) {
+				sc.logger.Warn("Get: failed to relay remote hit", "key", key, "error", err)
+			}
+			return
+		}
+		atomic.AddInt64(&sc.stats.RelayedHits, 1)
+		if sc.debugging(DebugSync) {
+			sc.logger.Debug("Get: relayed remote hit to other pods", "key", key)
+		}
+	})
+}
+
+// skipRelay reports whether a relayed event must be ignored because the key is
+// already held locally: the relayed value was read from Redis and may be older
+// than the one this pod received from the writer.
+func (sc *SyncedCache) skipRelay(event InvalidationEvent) bool {
+	if !event.Relay {
+		return false
+	}
+	_, found := sc.local.Get(event.Key)
+	return found
+}
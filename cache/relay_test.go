@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSyncedCacheRelayRemoteHits(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1", RelayRemoteHits: true})
+	defer c.Close()
+	synchronizer := &publishingSynchronizer{}
+	c.synchronizer = synchronizer
+	c.store = &valueStore{values: map[string][]byte{"user:1": []byte(`"alice"`)}}
+
+	value, found := c.Get(context.Background(), "user:1")
+	if !found || value != "alice" {
+		t.Fatalf("Expected alice from Redis, got %v (found=%v)", value, found)
+	}
+	waitFor(t, "the remote hit to be relayed", func() bool {
+		return c.Stats().RelayedHits == 1
+	})
+
+	synchronizer.mu.Lock()
+	defer synchronizer.mu.Unlock()
+	if len(synchronizer.events) != 1 {
+		t.Fatalf("Expected one relayed event, got %+v", synchronizer.events)
+	}
+	event := synchronizer.events[0]
+	if event.Action != ActionSet || !event.Relay || string(event.Value) != `"alice"` {
+		t.Errorf("Expected a relayed set of alice, got %+v", event)
+	}
+}
+
+func TestSyncedCacheRelayRemoteHitsDisabled(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1"})
+	defer c.Close()
+	synchronizer := &publishingSynchronizer{}
+	c.synchronizer = synchronizer
+	c.store = &valueStore{values: map[string][]byte{"user:1": []byte(`"alice"`)}}
+
+	if _, found := c.Get(context.Background(), "user:1"); !found {
+		t.Fatal("Expected a remote hit")
+	}
+	c.bgWG.Wait()
+	if len(synchronizer.events) != 0 {
+		t.Errorf("Expected no relayed event without RelayRemoteHits, got %+v", synchronizer.events)
+	}
+}
+
+func TestSyncedCacheRelayedEventsOnlyFillMisses(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-2"})
+	defer c.Close()
+
+	c.local.Set("user:1", "newer", 1)
+	c.applyEvent(InvalidationEvent{Key: "user:1", Sender: "pod-1", Action: ActionSet, Value: []byte(`"older"`), Relay: true})
+	c.applyEvent(InvalidationEvent{Key: "user:2", Sender: "pod-1", Action: ActionSet, Value: []byte(`"bob"`), Relay: true})
+
+	if value, _ := c.local.Get("user:1"); value != "newer" {
+		t.Errorf("Expected the relayed value not to overwrite user:1, got %v", value)
+	}
+	if value, found := c.local.Get("user:2"); !found || value != "bob" {
+		t.Errorf("Expected the relayed value to fill user:2, got %v (found=%v)", value, found)
+	}
+}
+
+func TestSyncedCacheLateRelayBehindWrite(t *testing.T) {
+	ctx := context.Background()
+	reader := newMockedCache(t, Options{PodID: "pod-1", RelayRemoteHits: true})
+	defer reader.Close()
+	relays := &publishingSynchronizer{}
+	reader.synchronizer = relays
+	reader.store = &valueStore{values: map[string][]byte{"user:1": []byte(`"older"`), "user:2": []byte(`"older"`)}}
+	for _, key := range []string{"user:1", "user:2"} {
+		if _, found := reader.Get(ctx, key); !found {
+			t.Fatalf("Expected a remote hit for %s", key)
+		}
+	}
+	waitFor(t, "the remote hits to be relayed", func() bool {
+		return reader.Stats().RelayedHits == 2
+	})
+
+	// The writer updates user:1 and deletes user:2 after they were read
+	writer := newMockedCache(t, Options{PodID: "pod-2"})
+	defer writer.Close()
+	writes := &publishingSynchronizer{}
+	writer.synchronizer = writes
+	for _, event := range relays.events {
+		writer.clock.observe(event.Clock)
+	}
+	if err := writer.Set(ctx, "user:1", "newer"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := writer.Delete(ctx, "user:2"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	peer := newMockedCache(t, Options{PodID: "pod-3"})
+	defer peer.Close()
+	for _, event := range writes.events {
+		peer.applyEvent(event)
+	}
+	for _, event := range relays.events {
+		peer.applyEvent(event)
+	}
+	if value, _ := peer.local.Get("user:1"); value != "newer" {
+		t.Errorf("Expected the late relay not to overwrite the newer write, got %v", value)
+	}
+	if value, found := peer.local.Get("user:2"); found {
+		t.Errorf("Expected the late relay not to refill the deleted key, got %v", value)
+	}
+	if reordered := peer.Stats().ReorderedEvents; reordered != 2 {
+		t.Errorf("Expected both relays to be discarded, got %d", reordered)
+	}
+}
+
+func TestSyncedCacheRelayDoesNotDiscardLaterWrites(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-3"})
+	defer c.Close()
+
+	c.applyEvent(InvalidationEvent{Key: "user:1", Sender: "pod-1", Action: ActionSet, Value: []byte(`"older"`), Relay: true, Clock: 20})
+	c.applyEvent(InvalidationEvent{Key: "user:1", Sender: "pod-2", Action: ActionSet, Value: []byte(`"newer"`), Clock: 10})
+	if value, _ := c.local.Get("user:1"); value != "newer" {
+		t.Errorf("Expected the write to apply after a relay, got %v", value)
+	}
+}
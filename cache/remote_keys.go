@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+)
+
+// remoteKeysBatchSize is the number of keys scanned per round trip by RemoteKeys.
+const remoteKeysBatchSize = 100
+
+// RemoteKeys calls fn with batches of the keys of the remote store namespace
+// starting with prefix, until every key was visited or fn returns an error.
+// Keys written meanwhile may be missed, and fn may delete the keys it is passed.
+// It requires a store implementing WarmupStore.
+func (sc *SyncedCache) RemoteKeys(ctx context.Context, prefix string, fn func(keys []string) error) error {
+	if atomic.LoadInt32(&sc.closed) != 0 {
+		return ErrCacheClosed
+	}
+	store, ok := sc.store.(WarmupStore)
+	if !ok || sc.options.DisableRemoteStore {
+		return ErrScanNotSupported
+	}
+
+	var fnErr error
+	err := store.ScanKeys(ctx, escapeGlob(prefix)+"*", remoteKeysBatchSize, func(keys []string) error {
+		fnErr = fn(keys)
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return categorize(ErrRemoteStore, err)
+	}
+	return nil
+}
+
+// escapeGlob escapes glob special characters so s matches itself in a pattern.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ErrScanNotSupported is returned by RemoteKeys when the store cannot scan keys,
+// or when Options.DisableRemoteStore is set.
+var ErrScanNotSupported = NewError("store does not support scanning keys")
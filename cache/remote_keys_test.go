@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestSyncedCacheRemoteKeys(t *testing.T) {
+	ctx := context.Background()
+	c := newMockedCache(t, Options{})
+	c.store = &warmupStore{values: map[string][]byte{
+		"user:1": []byte(`"a"`), "user:2": []byte(`"b"`), "user*x": []byte(`"c"`), "order:1": []byte(`1`),
+	}}
+
+	var keys []string
+	collect := func(batch []string) error {
+		keys = append(keys, batch...)
+		return nil
+	}
+	if err := c.RemoteKeys(ctx, "user:", collect); err != nil {
+		t.Fatalf("RemoteKeys failed: %v", err)
+	}
+	if !slices.Equal(keys, []string{"user:1", "user:2"}) {
+		t.Fatalf("Expected the user keys, got %v", keys)
+	}
+
+	keys = nil
+	if err := c.RemoteKeys(ctx, "user*", collect); err != nil {
+		t.Fatalf("RemoteKeys failed: %v", err)
+	}
+	if !slices.Equal(keys, []string{"user*x"}) {
+		t.Fatalf("Expected the prefix to match literally, got %v", keys)
+	}
+
+	stop := errors.New("stop")
+	if err := c.RemoteKeys(ctx, "", func([]string) error { return stop }); err != stop {
+		t.Fatalf("Expected the error of fn, got %v", err)
+	}
+
+	c.store = &errorStore{}
+	if err := c.RemoteKeys(ctx, "user:", collect); err != ErrScanNotSupported {
+		t.Fatalf("Expected ErrScanNotSupported, got %v", err)
+	}
+}
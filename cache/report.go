@@ -0,0 +1,68 @@
+package cache
+
+import "fmt"
+
+// Operations passed to Options.OnErrorEx, telling which operation failed.
+const (
+	OpGet         = "get"          // Get, GetRaw and GetWithETag
+	OpSet         = "set"          // Set and SetIfVersion
+	OpDelete      = "delete"       // Delete
+	OpClear       = "clear"        // Clear
+	OpLoad        = "load"         // a registered loader filling a missed key
+	OpSync        = "sync"         // applying an event received from another pod
+	OpSubscribe   = "subscribe"    // confirming the sync subscription
+	OpLifecycle   = "lifecycle"    // publishing a lifecycle event
+	OpSnapshot    = "snapshot"     // requesting or sending a snapshot
+	OpAntiEntropy = "anti_entropy" // publishing an anti-entropy digest
+	OpHeartbeat   = "heartbeat"    // publishing a heartbeat or detecting a duplicate PodID
+	OpLeader      = "leader"       // acquiring or renewing the leader lease
+	OpRefresh     = "refresh"      // a RefreshAhead loader refreshing a key
+	OpBloomFilter = "bloom_filter" // scanning Redis keys into the Bloom filter
+	OpWarmup      = "warmup"       // warming the local cache
+	OpDump        = "dump"         // DumpRemote and RestoreRemote
+	OpHotKeys     = "hot_keys"     // reading or updating the hot key list
+	OpWrite       = "write"        // persisting a value with Options.Writer
+	OpReplay      = "replay"       // replaying writes queued while the circuit was open
+)
+
+// reportError reports err, raised by op on key, to Options.OnError as is and to
+// Options.OnErrorEx wrapped with category, one of ErrSerialization,
+// ErrDeserialization, ErrRemoteStore, ErrPublish and ErrSubscription. Errors of
+// the application's own callbacks, such as loaders, and errors that already tell
+// what failed have no category.
+func (sc *SyncedCache) reportError(op, key string, category, err error) {
+	if sc.options.OnError != nil {
+		sc.options.OnError(err)
+	}
+	if sc.options.OnErrorEx != nil {
+		if category != nil {
+			err = categorize(category, err)
+		}
+		sc.options.OnErrorEx(op, key, err)
+	}
+}
+
+// categorize wraps err with category so callers can test both with errors.Is.
+func categorize(category, err error) error {
+	return fmt.Errorf("%w: %w", category, err)
+}
+
+// ErrSerialization is wrapped by errors returned or reported via OnErrorEx when
+// a value cannot be serialized.
+var ErrSerialization = NewError("serialization failed")
+
+// ErrDeserialization is wrapped by errors reported via OnErrorEx when a value
+// read from Redis or received from another pod cannot be deserialized.
+var ErrDeserialization = NewError("deserialization failed")
+
+// ErrRemoteStore is wrapped by errors returned or reported via OnErrorEx when a
+// remote store operation fails or times out.
+var ErrRemoteStore = NewError("remote store operation failed")
+
+// ErrPublish is wrapped by errors reported via OnErrorEx when a synchronization
+// event cannot be published.
+var ErrPublish = NewError("publishing synchronization event failed")
+
+// ErrSubscription is wrapped by errors returned by New or reported via
+// OnErrorEx when the sync subscription cannot be established or confirmed.
+var ErrSubscription = NewError("sync subscription failed")
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// reportedError is an error passed to OnErrorEx.
+type reportedError struct {
+	op, key string
+	err     error
+}
+
+func TestSyncedCacheOnErrorExCategories(t *testing.T) {
+	var reported []reportedError
+	var plain []error
+	c := newMockedCache(t, Options{
+		ReaderCanSetToRedis: true,
+		OnError:             func(err error) { plain = append(plain, err) },
+		OnErrorEx: func(op, key string, err error) {
+			reported = append(reported, reportedError{op, key, err})
+		},
+	})
+	defer c.Close()
+	setErr := errors.New("redis set error")
+	publishErr := errors.New("publish error")
+	c.store = &errorStore{setError: setErr}
+	c.synchronizer = &errorSynchronizer{publishError: publishErr}
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "user:1", "alice"); err == nil {
+		t.Fatal("Expected Set to fail when Redis fails")
+	}
+	if err := c.Set(ctx, "user:2", make(chan int)); err == nil {
+		t.Fatal("Expected Set to fail for an unserializable value")
+	}
+	c.Delete(ctx, "user:3")
+	c.applyEvent(InvalidationEvent{Key: "user:4", Sender: "other", Action: ActionSet, Value: []byte("{invalid")})
+
+	want := []struct {
+		op, key  string
+		category error
+	}{
+		{OpSet, "user:1", ErrRemoteStore},
+		{OpSet, "user:2", ErrSerialization},
+		{OpDelete, "user:3", ErrPublish},
+		{OpSync, "user:4", ErrDeserialization},
+	}
+	if len(reported) != len(want) {
+		t.Fatalf("Expected %d reported errors, got %+v", len(want), reported)
+	}
+	for i, w := range want {
+		got := reported[i]
+		if got.op != w.op || got.key != w.key || !errors.Is(got.err, w.category) {
+			t.Errorf("Expected %s on %s wrapping %v, got %+v", w.op, w.key, w.category, got)
+		}
+	}
+	if !errors.Is(reported[0].err, setErr) || !errors.Is(reported[2].err, publishErr) {
+		t.Errorf("Expected the categorized errors to wrap the cause, got %v and %v", reported[0].err, reported[2].err)
+	}
+	if len(plain) != len(want) || plain[0] != setErr {
+		t.Errorf("Expected OnError to receive the unwrapped errors, got %v", plain)
+	}
+}
+
+func TestSyncedCacheReturnsCategorizedErrors(t *testing.T) {
+	c := newMockedCache(t, Options{ReaderCanSetToRedis: true})
+	defer c.Close()
+	storeErr := errors.New("connection reset")
+	c.store = &errorStore{setError: storeErr, deleteError: storeErr, clearError: storeErr}
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "user:1", "alice"); !errors.Is(err, ErrRemoteStore) || !errors.Is(err, storeErr) {
+		t.Errorf("Expected Set to wrap ErrRemoteStore and the cause, got %v", err)
+	}
+	if err := c.Set(ctx, "user:1", make(chan int)); !errors.Is(err, ErrSerialization) {
+		t.Errorf("Expected Set to wrap ErrSerialization, got %v", err)
+	}
+	if err := c.Delete(ctx, "user:1"); !errors.Is(err, ErrRemoteStore) || !errors.Is(err, storeErr) {
+		t.Errorf("Expected Delete to wrap ErrRemoteStore and the cause, got %v", err)
+	}
+	if err := c.Clear(ctx); !errors.Is(err, ErrRemoteStore) || !errors.Is(err, storeErr) {
+		t.Errorf("Expected Clear to wrap ErrRemoteStore and the cause, got %v", err)
+	}
+}
@@ -0,0 +1,218 @@
+package cache
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// schemaMagic prefixes payloads of keys registered in a TypeRegistry, followed
+// by their schema version as a uvarint. It is never produced by JSON; MessagePack
+// only produces it for the integer -11, which registered types do not encode to.
+const schemaMagic byte = 0xF5
+
+// ErrSchemaMismatch is wrapped by errors reported via OnErrorEx when a value
+// written at another schema version cannot be decoded into the registered type.
+var ErrSchemaMismatch = NewError("schema version mismatch")
+
+// SchemaConverter converts a value written at another schema version, decoded
+// into an any (e.g. a map[string]any for JSON objects), to the registered type.
+// It returns either a value of the registered type or a value that encodes to
+// the registered schema, such as an updated map.
+type SchemaConverter func(value any) (any, error)
+
+// TypeRegistry maps key prefixes to the Go types their values are decoded into
+// and to the schema version they are written with. Payloads of registered keys
+// carry their version, so pods of a rolling deploy whose types differ convert
+// them with a SchemaConverter instead of failing to decode them. Register types
+// before passing the registry to Options.TypeRegistry.
+type TypeRegistry struct {
+	mu      sync.RWMutex
+	schemas []*schema // longest prefix first
+}
+
+// schema is a type registered for a key prefix.
+type schema struct {
+	prefix     string
+	typ        reflect.Type
+	version    uint64
+	converters map[uint64]SchemaConverter
+}
+
+// NewTypeRegistry creates an empty type registry.
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{}
+}
+
+// Register decodes the values of keys starting with prefix into the type of
+// sample, e.g. User{}, and writes them at schema version. Get returns values of
+// that type; for pointer types, such as protobuf messages registered as
+// &pb.User{}, it returns a new pointer. Registering a prefix again replaces its
+// type, version and converters.
+func (r *TypeRegistry) Register(prefix string, sample any, version uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := &schema{prefix: prefix, typ: reflect.TypeOf(sample), version: version, converters: make(map[uint64]SchemaConverter)}
+	for i, existing := range r.schemas {
+		if existing.prefix == prefix {
+			r.schemas[i] = s
+			return
+		}
+	}
+	r.schemas = append(r.schemas, s)
+	sort.SliceStable(r.schemas, func(i, j int) bool {
+		return len(r.schemas[i].prefix) > len(r.schemas[j].prefix)
+	})
+}
+
+// RegisterConverter converts the values of prefix written at schema version from,
+// older or newer than the registered one, with fn. It returns an error when
+// prefix is not registered.
+func (r *TypeRegistry) RegisterConverter(prefix string, from uint64, fn SchemaConverter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.schemas {
+		if s.prefix == prefix {
+			s.converters[from] = fn
+			return nil
+		}
+	}
+	return fmt.Errorf("no type registered for prefix %q", prefix)
+}
+
+// lookup returns the schema of the longest registered prefix of key, if any.
+func (r *TypeRegistry) lookup(key string) *schema {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, s := range r.schemas {
+		if strings.HasPrefix(key, s.prefix) {
+			return s
+		}
+	}
+	return nil
+}
+
+// encode serializes a value written to key with its Marshaller, see
+// Options.MarshallerRules, prefixing it with the ID of the Marshaller when a rule
+// matches and with the schema version of key when it is registered in
+// Options.TypeRegistry, or wrapping it in an envelope with Options.PayloadEnvelope.
+func (sc *SyncedCache) encode(key string, value any) ([]byte, error) {
+	serializer, id := sc.marshallerFor(key)
+	data, err := serializer.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return sc.wrap(key, id, data), nil
+}
+
+// wrap returns the payload of data, a value of key serialized by the Marshaller
+// with ID id, as encode writes it.
+func (sc *SyncedCache) wrap(key string, id byte, data []byte) []byte {
+	if sc.options.PayloadEnvelope {
+		return sc.seal(key, id, data)
+	}
+	s := sc.options.TypeRegistry.lookup(key)
+	if s == nil && id == 0 {
+		return data
+	}
+	out := make([]byte, 0, len(data)+3+binary.MaxVarintLen64)
+	if s != nil {
+		out = append(out, schemaMagic)
+		out = binary.AppendUvarint(out, s.version)
+	}
+	if id != 0 {
+		out = append(out, codecMagic, id)
+	}
+	return append(out, data...)
+}
+
+// splitSchema returns the schema version and the serialized value of a payload
+// of key. Payloads of unregistered keys, and those written before their key was
+// registered, have no version and ok is false.
+func (sc *SyncedCache) splitSchema(key string, data []byte) (version uint64, body []byte, ok bool) {
+	if len(data) == 0 || data[0] != schemaMagic || sc.options.TypeRegistry.lookup(key) == nil {
+		return 0, data, false
+	}
+	version, n := binary.Uvarint(data[1:])
+	if n <= 0 {
+		return 0, data, false
+	}
+	return version, data[1+n:], true
+}
+
+// decodeSchema decodes the serialized value body of a key registered with s,
+// converting it when it was written at another schema version.
+func (sc *SyncedCache) decodeSchema(s *schema, serializer Marshaller, key string, version uint64, versioned bool, body []byte) (any, error) {
+	if !versioned || version == s.version {
+		return s.decode(serializer, body)
+	}
+
+	sc.options.TypeRegistry.mu.RLock()
+	convert := s.converters[version]
+	sc.options.TypeRegistry.mu.RUnlock()
+	if convert == nil {
+		// Compatible changes, such as added fields, decode as they are
+		value, err := s.decode(serializer, body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q was written at version %d, registered version is %d: %w", ErrSchemaMismatch, key, version, s.version, err)
+		}
+		return value, nil
+	}
+
+	var old any
+	if err := serializer.Unmarshal(body, &old); err != nil {
+		return nil, err
+	}
+	converted, err := convert(old)
+	if err == nil && converted == nil {
+		err = errors.New("converter returned nil")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: converting %q from version %d: %w", ErrSchemaMismatch, key, version, err)
+	}
+	switch v := reflect.ValueOf(converted); {
+	case v.Type() == s.typ:
+		return converted, nil
+	case v.Kind() == reflect.Pointer && v.Type().Elem() == s.typ:
+		return v.Elem().Interface(), nil
+	}
+	data, err := serializer.Marshal(converted)
+	if err != nil {
+		return nil, err
+	}
+	return s.decode(serializer, data)
+}
+
+// decode decodes data into a new value of the registered type. Pointer types,
+// such as protobuf messages, are decoded into a new pointed-to value.
+func (s *schema) decode(serializer Marshaller, data []byte) (any, error) {
+	if s.typ.Kind() == reflect.Pointer {
+		target := reflect.New(s.typ.Elem()).Interface()
+		if err := serializer.Unmarshal(data, target); err != nil {
+			return nil, err
+		}
+		return target, nil
+	}
+	return decodeInto(serializer, data, reflect.New(s.typ).Interface())
+}
+
+// decodeInto decodes data into target, a pointer, and returns the pointed-to
+// value, or target itself when it is a protobuf message, which must not be copied.
+func decodeInto(serializer Marshaller, data []byte, target any) (any, error) {
+	if err := serializer.Unmarshal(data, target); err != nil {
+		return nil, err
+	}
+	if _, ok := target.(proto.Message); ok {
+		return target, nil
+	}
+	return reflect.Indirect(reflect.ValueOf(target)).Interface(), nil
+}
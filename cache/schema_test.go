@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type userV1 struct {
+	Name string `json:"name"`
+}
+
+type userV2 struct {
+	FirstName string `json:"first_name"`
+	Age       int    `json:"age"`
+}
+
+func TestSyncedCacheTypeRegistryConvertsVersions(t *testing.T) {
+	ctx := context.Background()
+
+	// pod-1 still runs the version writing userV1
+	oldTypes := NewTypeRegistry()
+	oldTypes.Register("user:", userV1{}, 1)
+	pod1 := newMockedCache(t, Options{PodID: "pod-1", TypeRegistry: oldTypes})
+	defer pod1.Close()
+	sync1 := &publishingSynchronizer{}
+	pod1.synchronizer = sync1
+	if err := pod1.Set(ctx, "user:1", userV1{Name: "alice"}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if len(sync1.events) != 1 || sync1.events[0].Value[0] != schemaMagic {
+		t.Fatalf("Expected a versioned payload to be published, got %+v", sync1.events)
+	}
+	if value, _ := pod1.Get(ctx, "user:1"); value != (userV1{Name: "alice"}) {
+		t.Errorf("Expected the value set, got %#v", value)
+	}
+
+	var reported []error
+	newTypes := NewTypeRegistry()
+	newTypes.Register("user:", userV2{}, 2)
+	if err := newTypes.RegisterConverter("user:", 1, func(value any) (any, error) {
+		old := value.(map[string]any)
+		return map[string]any{"first_name": old["name"], "age": 0}, nil
+	}); err != nil {
+		t.Fatalf("RegisterConverter failed: %v", err)
+	}
+	pod2 := newMockedCache(t, Options{PodID: "pod-2", TypeRegistry: newTypes, OnError: func(err error) {
+		reported = append(reported, err)
+	}})
+	defer pod2.Close()
+
+	pod2.applyEvent(sync1.events[0])
+	if value, _ := pod2.Get(ctx, "user:1"); value != (userV2{FirstName: "alice"}) {
+		t.Errorf("Expected the value converted to userV2, got %#v", value)
+	}
+
+	// Unversioned payloads, written before the prefix was registered, decode as they are
+	pod2.applyEvent(InvalidationEvent{Key: "user:2", Sender: "pod-1", Action: ActionSet, Value: []byte(`{"first_name": "bob"}`)})
+	if value, _ := pod2.Get(ctx, "user:2"); value != (userV2{FirstName: "bob"}) {
+		t.Errorf("Expected an unversioned payload decoded into userV2, got %#v", value)
+	}
+
+	// Versions without a converter fail with ErrSchemaMismatch when they do not decode
+	pod2.applyEvent(InvalidationEvent{Key: "user:3", Sender: "pod-1", Action: ActionSet, Value: []byte{schemaMagic, 3, '[', ']'}})
+	if _, found := pod2.Get(ctx, "user:3"); found {
+		t.Error("Expected a value not matching the schema to be dropped")
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], ErrSchemaMismatch) {
+		t.Errorf("Expected ErrSchemaMismatch to be reported, got %v", reported)
+	}
+}
+
+func TestTypeRegistryRegisterConverterUnknownPrefix(t *testing.T) {
+	if err := NewTypeRegistry().RegisterConverter("user:", 1, nil); err == nil {
+		t.Fatal("Expected an error for a prefix without a registered type")
+	}
+}
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// SetOption customizes a single Set call, see WithTTL, WithLocalTTL, WithTags, WithCost,
+// WithNoPropagate, WithInvalidateOnly, WithVersion, WithWritePolicy, WithDependsOn and
+// WithGroup.
+type SetOption func(*setConfig)
+
+// setConfig is the effective configuration of a Set call.
+type setConfig struct {
+	policy         WritePolicy
+	invalidateOnly bool
+	noPropagate    bool
+	ttl            time.Duration
+	localTTL       time.Duration
+	tags           []string
+	dependsOn      []string
+	group          string
+	cost           int64
+	version        uint64
+	checkVersion   bool
+	published      *publishedEvent // receives the synchronization event of the write, see SetAck
+}
+
+// WithTTL expires the value after ttl, in Redis and in the local caches of every
+// pod receiving it. It requires a store implementing ExpiringStore and cannot be
+// combined with WithVersion.
+func WithTTL(ttl time.Duration) SetOption {
+	return func(c *setConfig) { c.ttl = ttl }
+}
+
+// WithLocalTTL expires the value from this pod's local cache after ttl, overriding
+// Options.LocalTTL, so it is revalidated from Redis on the next Get. It does not
+// affect Redis or other pods.
+func WithLocalTTL(ttl time.Duration) SetOption {
+	return func(c *setConfig) { c.localTTL = ttl }
+}
+
+// WithTags attaches tags to the value, reported in HitInfo.Tags by GetWithInfo
+// on every pod receiving the value.
+func WithTags(tags ...string) SetOption {
+	return func(c *setConfig) { c.tags = append(c.tags, tags...) }
+}
+
+// WithDependsOn declares the keys the value was computed from. Changing or
+// deleting one of them deletes the value from Redis and the local cache of every
+// pod, and in turn the values depending on it.
+//
+// Dependencies are not stored in Redis. Each pod keeps those it learned from its
+// own writes and from the synchronization events in memory, for as many values
+// as LocalCacheConfig.MaxSize. A change made on a pod that does not know the
+// dependency, such as one started after the value was written, leaves the value
+// in Redis, and values stored with WithNoPropagate are only invalidated by
+// changes made on this pod. Combine it with WithTTL to bound how long a value
+// can outlive a change.
+func WithDependsOn(keys ...string) SetOption {
+	return func(c *setConfig) { c.dependsOn = append(c.dependsOn, keys...) }
+}
+
+// WithGroup records the key under group in Redis, so that DeleteGroup removes it
+// along with every other key written under group, e.g. all the entries of a
+// tenant. A key belongs to the group it was last written under: deleting it, or
+// writing it again without a group, removes it from its group. It requires a
+// store implementing GroupStore and has no effect on values that are not
+// written to Redis.
+func WithGroup(group string) SetOption {
+	return func(c *setConfig) { c.group = group }
+}
+
+// WithCost sets the local cache cost of the value, overriding Options.CostFunc.
+func WithCost(cost int64) SetOption {
+	return func(c *setConfig) { c.cost = cost }
+}
+
+// WithNoPropagate stores the value without publishing a synchronization event, so
+// other pods keep serving their local copy until it is evicted or invalidated.
+func WithNoPropagate() SetOption {
+	return func(c *setConfig) { c.noPropagate = true }
+}
+
+// WithInvalidateOnly makes Set behave like SetWithInvalidate: other pods drop the
+// key and fetch it from Redis instead of receiving the value.
+func WithInvalidateOnly() SetOption {
+	return func(c *setConfig) { c.invalidateOnly = true }
+}
+
+// WithVersion makes Set behave like SetIfVersion: the value is only stored if its
+// version in the remote store equals expectedVersion, failing with
+// ErrVersionConflict otherwise.
+func WithVersion(expectedVersion uint64) SetOption {
+	return func(c *setConfig) {
+		c.version = expectedVersion
+		c.checkVersion = true
+	}
+}
+
+// WithWritePolicy overrides Options.WritePolicy for this call. Whether Writer is
+// called synchronously or from the write-behind queue is set per cache instance.
+func WithWritePolicy(policy WritePolicy) SetOption {
+	return func(c *setConfig) { c.policy = policy }
+}
+
+// setConfig returns the configuration of a Set call of key with opts and the
+// matching PropagationRules entry applied.
+func (sc *SyncedCache) setConfig(key string, opts []SetOption) setConfig {
+	var c setConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.policy == WritePolicyInvalidateOnly {
+		c.invalidateOnly = true
+	}
+	c.ttl = jitterTTL(c.ttl, sc.options.TTLJitterPercent)
+	ruled := sc.applyPropagationRule(&c, key)
+	if c.policy == "" {
+		c.policy = sc.options.effectiveWritePolicy()
+		if c.policy == WritePolicyInvalidateOnly && !ruled {
+			c.invalidateOnly = true
+		}
+	}
+	return c
+}
+
+// jitterTTL returns ttl randomized by up to percent of it in either direction.
+func jitterTTL(ttl time.Duration, percent int) time.Duration {
+	if ttl <= 0 || percent <= 0 {
+		return ttl
+	}
+	spread := ttl * time.Duration(percent) / 100
+	if spread <= 0 {
+		return ttl
+	}
+	return ttl - spread + rand.N(2*spread+1)
+}
+
+// localCost returns the local cache cost of a value stored with c.
+func (sc *SyncedCache) localCost(c setConfig, key string, value any, data []byte) int64 {
+	if c.cost > 0 {
+		return c.cost
+	}
+	return sc.cost(key, value, data)
+}
+
+// ErrTTLNotSupported is returned by Set with WithTTL when the store does not
+// implement ExpiringStore, or when WithTTL is combined with WithVersion.
+var ErrTTLNotSupported = NewError("store does not support expiring values")
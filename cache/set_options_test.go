@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// expiringStore records the TTL of values written with SetWithTTL.
+type expiringStore struct {
+	errorStore
+	ttls map[string]time.Duration
+}
+
+func (es *expiringStore) SetWithTTL(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	es.ttls[key] = ttl
+	return nil
+}
+
+func TestSetWithTTL(t *testing.T) {
+	sc := newMockedCache(t, Options{WritePolicy: WritePolicyWriteThrough})
+	defer sc.Close()
+	store := &expiringStore{ttls: make(map[string]time.Duration)}
+	sc.store = store
+	publisher := &publishingSynchronizer{}
+	sc.synchronizer = publisher
+	ctx := context.Background()
+
+	if err := sc.Set(ctx, "key", "value", WithTTL(20*time.Millisecond)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if ttl := store.ttls["key"]; ttl != 20*time.Millisecond {
+		t.Errorf("Expected the Redis write to expire after 20ms, got %s", ttl)
+	}
+	if len(publisher.events) != 1 || publisher.events[0].TTL != 20*time.Millisecond {
+		t.Errorf("Expected the TTL to be propagated, got %+v", publisher.events)
+	}
+	if _, found := sc.Get(ctx, "key"); !found {
+		t.Fatal("Expected the value before it expires")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if value, found := sc.Get(ctx, "key"); found {
+		t.Errorf("Expected the local value to expire, got %v", value)
+	}
+}
+
+func TestSetWithTTLNotSupported(t *testing.T) {
+	sc := newMockedCache(t, Options{WritePolicy: WritePolicyWriteThrough})
+	defer sc.Close()
+
+	if err := sc.Set(context.Background(), "key", "value", WithTTL(time.Minute)); !errors.Is(err, ErrTTLNotSupported) {
+		t.Fatalf("Expected ErrTTLNotSupported, got %v", err)
+	}
+}
+
+func TestPropagatedTTLAndTags(t *testing.T) {
+	sc := newMockedCache(t, Options{})
+	defer sc.Close()
+
+	sc.applyEvent(InvalidationEvent{
+		Key:    "key",
+		Sender: "other-pod",
+		Action: ActionSet,
+		Value:  []byte(`"value"`),
+		TTL:    20 * time.Millisecond,
+		Tags:   []string{"users"},
+	})
+	_, found, info := sc.GetWithInfo(context.Background(), "key")
+	if !found || len(info.Tags) != 1 || info.Tags[0] != "users" {
+		t.Fatalf("Expected the propagated value with its tags, got found=%v %+v", found, info)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, found := sc.Get(context.Background(), "key"); found {
+		t.Error("Expected the propagated value to expire")
+	}
+}
+
+func TestSetWithTagsAndCost(t *testing.T) {
+	costCalls := 0
+	sc := newMockedCache(t, Options{CostFunc: func(key string, value any, serialized []byte) int64 {
+		costCalls++
+		return 1
+	}})
+	defer sc.Close()
+	ctx := context.Background()
+
+	if err := sc.Set(ctx, "key", "value", WithTags("users", "eu"), WithCost(42)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if costCalls != 0 {
+		t.Errorf("Expected WithCost to override CostFunc, got %d calls", costCalls)
+	}
+	_, _, info := sc.GetWithInfo(ctx, "key")
+	if len(info.Tags) != 2 || info.Tags[0] != "users" || info.Tags[1] != "eu" {
+		t.Errorf("Expected the tags in HitInfo, got %v", info.Tags)
+	}
+}
+
+func TestSetWithNoPropagateAndInvalidateOnly(t *testing.T) {
+	sc := newMockedCache(t, Options{})
+	defer sc.Close()
+	publisher := &publishingSynchronizer{}
+	sc.synchronizer = publisher
+	ctx := context.Background()
+
+	if err := sc.Set(ctx, "quiet", "value", WithNoPropagate()); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if len(publisher.events) != 0 {
+		t.Fatalf("Expected no event with WithNoPropagate, got %+v", publisher.events)
+	}
+
+	if err := sc.Set(ctx, "key", "value", WithInvalidateOnly()); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if len(publisher.events) != 1 || publisher.events[0].Action != ActionInvalidate {
+		t.Errorf("Expected an invalidation with WithInvalidateOnly, got %+v", publisher.events)
+	}
+}
+
+func TestSetWithVersion(t *testing.T) {
+	sc := newMockedCache(t, Options{})
+	defer sc.Close()
+	sc.store = newVersionedStore()
+	ctx := context.Background()
+
+	if err := sc.Set(ctx, "counter", 1, WithVersion(0)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := sc.Set(ctx, "counter", 2, WithVersion(0)); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("Expected ErrVersionConflict, got %v", err)
+	}
+	if err := sc.Set(ctx, "counter", 2, WithVersion(1), WithTTL(time.Minute)); !errors.Is(err, ErrTTLNotSupported) {
+		t.Fatalf("Expected ErrTTLNotSupported when combining WithTTL and WithVersion, got %v", err)
+	}
+}
+
+func TestSetWithWritePolicy(t *testing.T) {
+	sc := newMockedCache(t, Options{WritePolicy: WritePolicyWriteThrough})
+	defer sc.Close()
+	store := &externalStore{}
+	sc.store = store
+
+	if err := sc.Set(context.Background(), "key", "value", WithWritePolicy(WritePolicyLocalOnly)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if store.writes != 0 {
+		t.Errorf("Expected the per-call policy to skip Redis, got %d writes", store.writes)
+	}
+}
+
+func TestSetWithTTLJitter(t *testing.T) {
+	sc := newMockedCache(t, Options{WritePolicy: WritePolicyWriteThrough, TTLJitterPercent: 10})
+	defer sc.Close()
+	store := &expiringStore{ttls: make(map[string]time.Duration)}
+	sc.store = store
+	ctx := context.Background()
+
+	distinct := make(map[time.Duration]bool)
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("key:%d", i)
+		if err := sc.Set(ctx, key, "value", WithTTL(time.Hour)); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		ttl := store.ttls[key]
+		if ttl < 54*time.Minute || ttl > 66*time.Minute {
+			t.Fatalf("Expected a TTL within 10%% of an hour, got %s", ttl)
+		}
+		distinct[ttl] = true
+	}
+	if len(distinct) < 2 {
+		t.Error("Expected jittered TTLs to differ between keys")
+	}
+}
+
+func TestLocalTTLRevalidates(t *testing.T) {
+	sc := newMockedCache(t, Options{LocalTTL: 20 * time.Millisecond})
+	defer sc.Close()
+	store := newCountingStore(&errorStore{}, 0)
+	sc.store = store
+	ctx := context.Background()
+
+	if err := sc.Set(ctx, "short", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := sc.Set(ctx, "long", "value", WithLocalTTL(time.Minute)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, found := sc.Get(ctx, "short"); !found {
+		t.Fatal("Expected the value while it is fresh")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, found := sc.Get(ctx, "short"); found {
+		t.Error("Expected the local entry to expire")
+	}
+	if store.getCount("short") != 1 {
+		t.Errorf("Expected the expired entry to be revalidated from Redis, got %d reads", store.getCount("short"))
+	}
+	if _, found := sc.Get(ctx, "long"); !found {
+		t.Error("Expected WithLocalTTL to override LocalTTL")
+	}
+}
+
+func TestExpiringLocalCacheEvicts(t *testing.T) {
+	sc := newMockedCache(t, Options{})
+	defer sc.Close()
+	ctx := context.Background()
+
+	if err := sc.Set(ctx, "key", "value", WithLocalTTL(20*time.Millisecond)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if keys := sc.LocalKeys(); len(keys) != 0 {
+		t.Errorf("Expected the local cache to expire the entry itself, got %v", keys)
+	}
+}
@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// shutdownPollInterval is how often Shutdown checks whether received events were applied.
+const shutdownPollInterval = 10 * time.Millisecond
+
+// writeTracker counts in-flight writes so Shutdown can wait for their events to be
+// published. The zero value is ready to use.
+type writeTracker struct {
+	mu      sync.Mutex
+	closing bool
+	active  int
+	idle    chan struct{}
+}
+
+// begin registers a write. It returns false once the tracker is draining.
+func (wt *writeTracker) begin() bool {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	if wt.closing {
+		return false
+	}
+	wt.active++
+	return true
+}
+
+// end unregisters a write registered with begin.
+func (wt *writeTracker) end() {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	wt.active--
+	if wt.active == 0 && wt.idle != nil {
+		close(wt.idle)
+		wt.idle = nil
+	}
+}
+
+// drain rejects new writes and waits for the in-flight ones to end or ctx to be done.
+func (wt *writeTracker) drain(ctx context.Context) error {
+	wt.mu.Lock()
+	wt.closing = true
+	if wt.active == 0 {
+		wt.mu.Unlock()
+		return nil
+	}
+	idle := make(chan struct{})
+	wt.idle = idle
+	wt.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// beginWrite registers an in-flight write, returning false when the cache is closed.
+// Callers must call sc.writes.end when the write returns.
+func (sc *SyncedCache) beginWrite() bool {
+	if atomic.LoadInt32(&sc.closed) != 0 {
+		return false
+	}
+	return sc.writes.begin()
+}
+
+// Shutdown closes the cache gracefully. It stops accepting operations, waits for
+// in-flight writes to publish their events, for received events to be applied and
+// for the write-behind queue to be persisted until ctx is done, then stops
+// background work and releases all resources. Resources are released even when
+// ctx expires first, in which case ctx.Err() is returned, unpublished events or
+// unapplied invalidations may be lost, and values left in the write-behind queue
+// are handed to Options.OnWriteFailed.
+func (sc *SyncedCache) Shutdown(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&sc.closed, 0, 1) {
+		return nil
+	}
+
+	drainErr := sc.writes.drain(ctx)
+	if drainErr != nil && sc.logging(DebugOps) {
+		sc.logger.Warn("Shutdown: in-flight writes did not complete", "error", drainErr)
+	}
+
+	sc.announceBackground(LifecyclePodLeaving, nil)
+
+	// Stop background work before tearing down the resources it uses
+	sc.bgMutex.Lock()
+	sc.bgStopped = true
+	sc.drainCtx = ctx
+	sc.bgMutex.Unlock()
+	sc.bgCancel()
+	sc.bgWG.Wait()
+	if err := ctx.Err(); err != nil && drainErr == nil {
+		// Values left in the write-behind queue were not persisted
+		drainErr = err
+	}
+
+	var errs []error
+
+	if err := sc.synchronizer.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if sc.syncConn != nil {
+		if err := sc.syncConn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if sc.applyPool != nil {
+		if err := sc.drainEvents(ctx); err != nil && drainErr == nil {
+			drainErr = err
+		}
+		sc.applyPool.close()
+	}
+
+	if err := sc.store.Close(); err != nil {
+		errs = append(errs, err)
+	}
+
+	sc.local.Close()
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return drainErr
+}
+
+// drainEvents waits until every received event was applied or ctx is done.
+func (sc *SyncedCache) drainEvents(ctx context.Context) error {
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	for {
+		if pending, _ := sc.backlog.snapshot(); pending == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if sc.logging(DebugOps) {
+				pending, _ := sc.backlog.snapshot()
+				sc.logger.Warn("Shutdown: received events not applied", "pending", pending)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
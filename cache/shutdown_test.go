@@ -0,0 +1,151 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingSynchronizer is a synchronizer whose Publish of set events blocks until
+// released, recording events published after Close.
+type blockingSynchronizer struct {
+	errorSynchronizer
+	publishing chan struct{}
+	release    chan struct{}
+	mu         sync.Mutex
+	closed     bool
+	published  int
+	late       int
+}
+
+func newBlockingSynchronizer() *blockingSynchronizer {
+	return &blockingSynchronizer{publishing: make(chan struct{}, 1), release: make(chan struct{})}
+}
+
+func (bs *blockingSynchronizer) Publish(ctx context.Context, event InvalidationEvent) error {
+	if event.Action == ActionSet {
+		bs.publishing <- struct{}{}
+		<-bs.release
+	}
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.closed {
+		bs.late++
+	} else if event.Action == ActionSet {
+		bs.published++
+	}
+	return nil
+}
+
+func (bs *blockingSynchronizer) Close() error {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	bs.closed = true
+	return nil
+}
+
+func TestSyncedCacheShutdownWaitsForInFlightWrites(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	synchronizer := newBlockingSynchronizer()
+	c.synchronizer = synchronizer
+	ctx := context.Background()
+
+	setDone := make(chan error, 1)
+	go func() { setDone <- c.Set(ctx, "key", "value") }()
+	<-synchronizer.publishing
+
+	shutdownDone := make(chan error, 1)
+	go func() { shutdownDone <- c.Shutdown(ctx) }()
+
+	select {
+	case err := <-shutdownDone:
+		t.Fatalf("Expected Shutdown to wait for the in-flight Set, returned %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := c.Set(ctx, "other", "value"); !errors.Is(err, ErrCacheClosed) {
+		t.Fatalf("Expected new writes to be rejected with ErrCacheClosed, got %v", err)
+	}
+
+	close(synchronizer.release)
+	if err := <-setDone; err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := <-shutdownDone; err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if synchronizer.published != 1 || synchronizer.late != 0 {
+		t.Fatalf("Expected the event to be published before Close, got %d published and %d late",
+			synchronizer.published, synchronizer.late)
+	}
+}
+
+func TestSyncedCacheShutdownDeadline(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	synchronizer := newBlockingSynchronizer()
+	c.synchronizer = synchronizer
+	defer close(synchronizer.release)
+
+	go c.Set(context.Background(), "key", "value")
+	<-synchronizer.publishing
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if !synchronizer.closed {
+		t.Fatal("Expected resources to be released after the deadline")
+	}
+}
+
+func TestSyncedCacheShutdownDrainsReceivedEvents(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	c.applyPool = newApplyPool(2, 0, c.backlog, func(event InvalidationEvent) {
+		time.Sleep(time.Millisecond)
+		c.applyEvent(event)
+	})
+	for i := 0; i < 20; i++ {
+		c.handleInvalidation(InvalidationEvent{Key: "key", Sender: "pod-2", Action: ActionSet, Value: []byte(`"v"`)})
+	}
+
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if pending, _ := c.backlog.snapshot(); pending != 0 {
+		t.Fatalf("Expected every received event to be applied, %d pending", pending)
+	}
+}
+
+func TestSyncedCacheShutdownTwice(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Expected a second Shutdown to be a no-op, got %v", err)
+	}
+}
+
+// closeRecorder records whether it was closed.
+type closeRecorder struct {
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestSyncedCacheShutdownClosesSyncConnection(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	conn := &closeRecorder{}
+	c.syncConn = conn
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if !conn.closed {
+		t.Fatal("Expected the dedicated synchronizer connection to be closed")
+	}
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// SlogLogger adapts a *slog.Logger to the Logger interface.
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates a Logger writing to logger, or to slog.Default() when logger is nil.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogLogger{logger: logger}
+}
+
+// Debug logs a debug message.
+func (l *SlogLogger) Debug(msg string, args ...any) { l.logger.Debug(msg, args...) }
+
+// Info logs an info message.
+func (l *SlogLogger) Info(msg string, args ...any) { l.logger.Info(msg, args...) }
+
+// Warn logs a warning message.
+func (l *SlogLogger) Warn(msg string, args ...any) { l.logger.Warn(msg, args...) }
+
+// Error logs an error message.
+func (l *SlogLogger) Error(msg string, args ...any) { l.logger.Error(msg, args...) }
+
+// With returns a logger that attaches args to every record.
+func (l *SlogLogger) With(args ...any) Logger {
+	return &SlogLogger{logger: l.logger.With(args...)}
+}
+
+// Slog returns the underlying *slog.Logger.
+func (l *SlogLogger) Slog() *slog.Logger {
+	return l.logger
+}
+
+// withFields returns a logger that attaches the key-value pairs in fields to every record.
+// Loggers implementing FieldLogger attach them natively; others get them appended to args.
+func withFields(logger Logger, fields ...any) Logger {
+	switch l := logger.(type) {
+	case *NoOpLogger:
+		return logger
+	case FieldLogger:
+		return l.With(fields...)
+	default:
+		return &fieldLogger{logger: logger, fields: fields}
+	}
+}
+
+// fieldLogger appends fixed fields to the args of a Logger that cannot carry them itself.
+type fieldLogger struct {
+	logger Logger
+	fields []any
+}
+
+func (l *fieldLogger) Debug(msg string, args ...any) { l.logger.Debug(msg, l.args(args)...) }
+func (l *fieldLogger) Info(msg string, args ...any)  { l.logger.Info(msg, l.args(args)...) }
+func (l *fieldLogger) Warn(msg string, args ...any)  { l.logger.Warn(msg, l.args(args)...) }
+func (l *fieldLogger) Error(msg string, args ...any) { l.logger.Error(msg, l.args(args)...) }
+
+// With returns a logger that attaches args after the existing fields.
+func (l *fieldLogger) With(args ...any) Logger {
+	return &fieldLogger{logger: l.logger, fields: l.args(args)}
+}
+
+func (l *fieldLogger) args(args []any) []any {
+	all := make([]any, 0, len(l.fields)+len(args))
+	all = append(all, l.fields...)
+	return append(all, args...)
+}
+
+// formatArgs renders key-value pairs as "key=value" separated by spaces.
+// A trailing key without a value is rendered as "!BADKEY=key", like slog.
+func formatArgs(args []any) string {
+	var b strings.Builder
+	for i := 0; i < len(args); i += 2 {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		if i+1 == len(args) {
+			fmt.Fprintf(&b, "!BADKEY=%v", args[i])
+			break
+		}
+		fmt.Fprintf(&b, "%v=%v", args[i], args[i+1])
+	}
+	return b.String()
+}
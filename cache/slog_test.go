@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+// argsLogger records the args of every call.
+type argsLogger struct {
+	mu    sync.Mutex
+	calls [][]any
+}
+
+func (l *argsLogger) record(args []any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, args)
+}
+
+func (l *argsLogger) Debug(msg string, args ...any) { l.record(args) }
+func (l *argsLogger) Info(msg string, args ...any)  { l.record(args) }
+func (l *argsLogger) Warn(msg string, args ...any)  { l.record(args) }
+func (l *argsLogger) Error(msg string, args ...any) { l.record(args) }
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	withFields(logger, "pod_id", "pod-1").Debug("Set: stored", "key", "user:1")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Failed to decode record %q: %v", buf.String(), err)
+	}
+	if record["level"] != "DEBUG" || record["msg"] != "Set: stored" {
+		t.Errorf("Unexpected record: %v", record)
+	}
+	if record["pod_id"] != "pod-1" || record["key"] != "user:1" {
+		t.Errorf("Expected pod_id and key attributes, got %v", record)
+	}
+}
+
+func TestNewSlogLoggerNil(t *testing.T) {
+	logger := NewSlogLogger(nil).(*SlogLogger)
+	if logger.Slog() != slog.Default() {
+		t.Error("Expected slog.Default() for a nil logger")
+	}
+}
+
+func TestWithFieldsAppendsToPlainLogger(t *testing.T) {
+	inner := &argsLogger{}
+	logger := withFields(inner, "pod_id", "pod-1")
+	logger.Warn("msg", "key", "k")
+	logger.(FieldLogger).With("action", "set").Info("msg")
+
+	want := [][]any{
+		{"pod_id", "pod-1", "key", "k"},
+		{"pod_id", "pod-1", "action", "set"},
+	}
+	if len(inner.calls) != len(want) {
+		t.Fatalf("Expected %d calls, got %v", len(want), inner.calls)
+	}
+	for i := range want {
+		if len(inner.calls[i]) != len(want[i]) {
+			t.Fatalf("Call %d: expected %v, got %v", i, want[i], inner.calls[i])
+		}
+		for j := range want[i] {
+			if inner.calls[i][j] != want[i][j] {
+				t.Errorf("Call %d: expected %v, got %v", i, want[i], inner.calls[i])
+			}
+		}
+	}
+}
+
+func TestWithFieldsKeepsNoOpLogger(t *testing.T) {
+	logger := NewNoOpLogger()
+	if withFields(logger, "pod_id", "pod-1") != logger {
+		t.Error("Expected the no-op logger to be returned unchanged")
+	}
+}
+
+func TestFormatArgs(t *testing.T) {
+	tests := []struct {
+		args []any
+		want string
+	}{
+		{nil, ""},
+		{[]any{"key", "user:1"}, "key=user:1"},
+		{[]any{"key", "user:1", "attempt", 2}, "key=user:1 attempt=2"},
+		{[]any{"key", "user:1", "dangling"}, "key=user:1 !BADKEY=dangling"},
+	}
+	for _, tt := range tests {
+		if got := formatArgs(tt.args); got != tt.want {
+			t.Errorf("formatArgs(%v) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// snapshotChunkSize is the number of entries sent per snapshot chunk.
+const snapshotChunkSize = 100
+
+// snapshotClaimTTL is how long a pod holds the claim to answer a snapshot request.
+const snapshotClaimTTL = time.Minute
+
+// snapshotRequest is the payload of an ActionSnapshotRequest event.
+type snapshotRequest struct {
+	ID string `json:"id"`
+}
+
+// snapshotChunk is the payload of an ActionSnapshotChunk event, carrying serialized
+// local cache entries from the responding pod to the requesting pod, along with
+// the hybrid logical clock of the last write the responder applied to each of
+// them, when known.
+type snapshotChunk struct {
+	RequestID string            `json:"request_id"`
+	Target    string            `json:"target"`
+	Index     int               `json:"index"`
+	Last      bool              `json:"last"`
+	Entries   map[string][]byte `json:"entries,omitempty"`
+	Clocks    map[string]uint64 `json:"clocks,omitempty"`
+}
+
+// snapshotTransfer is the state of the snapshot requested by this pod.
+type snapshotTransfer struct {
+	mu        sync.Mutex
+	id        string
+	responder string
+	next      int
+	entries   int
+	failed    bool
+	done      chan struct{}
+}
+
+// finish marks the transfer as complete. t.mu must be held.
+func (t *snapshotTransfer) finish(failed bool) {
+	t.failed = failed
+	close(t.done)
+}
+
+// requestSnapshot asks the other pods for a snapshot of their local cache and applies
+// it as it arrives, for at most PeerWarmupTimeout. Only one pod answers when the store
+// implements LockingStore; otherwise the first pod to answer is used.
+func (sc *SyncedCache) requestSnapshot() {
+	if !sc.options.PeerWarmup {
+		return
+	}
+
+	id, err := newLockToken()
+	if err != nil {
+		return
+	}
+	transfer := &snapshotTransfer{id: id, done: make(chan struct{})}
+	sc.snapshot.Store(transfer)
+	defer sc.snapshot.Store(nil)
+
+	timeout := sc.options.PeerWarmupTimeout
+	if timeout <= 0 {
+		timeout = sc.options.ContextTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	data, _ := json.Marshal(snapshotRequest{ID: id})
+	err = sc.synchronizer.Publish(ctx, InvalidationEvent{
+		Key:    "*",
+		Sender: sc.options.PodID,
+		Action: ActionSnapshotRequest,
+		Value:  data,
+	})
+	if err != nil {
+		sc.reportError(OpSnapshot, "", ErrPublish, err)
+		if sc.logging(DebugSync) {
+			sc.logger.Error("Snapshot: failed to request snapshot", "error", err)
+		}
+		return
+	}
+
+	select {
+	case <-transfer.done:
+	case <-ctx.Done():
+	}
+
+	if sc.logging(DebugSync) {
+		transfer.mu.Lock()
+		defer transfer.mu.Unlock()
+		sc.logger.Info("Snapshot: peer warmup finished", "responder", transfer.responder,
+			"entries", transfer.entries, "failed", transfer.failed)
+	}
+}
+
+// serveSnapshot answers a snapshot request from another pod in the background,
+// unless another pod claimed it first.
+func (sc *SyncedCache) serveSnapshot(event InvalidationEvent) {
+	if !sc.options.PeerWarmup {
+		return
+	}
+	var request snapshotRequest
+	if err := json.Unmarshal(event.Value, &request); err != nil || request.ID == "" {
+		return
+	}
+
+	sc.goBackground(func(bgCtx context.Context) {
+		ctx, cancel := context.WithTimeout(bgCtx, sc.options.ContextTimeout)
+		defer cancel()
+
+		if store, ok := sc.store.(LockingStore); ok {
+			claimed, err := store.TryLock(ctx, "snapshot:"+request.ID, sc.options.PodID, snapshotClaimTTL)
+			if err != nil || !claimed {
+				return
+			}
+		}
+		if err := sc.sendSnapshot(ctx, event.Sender, request.ID); err != nil {
+			sc.reportError(OpSnapshot, "", ErrPublish, err)
+			if sc.logging(DebugSync) {
+				sc.logger.Error("Snapshot: failed to send snapshot", "target", event.Sender, "error", err)
+			}
+		}
+	})
+}
+
+// sendSnapshot publishes the entries of the local cache to target in chunks, each
+// stamped with the clock of the last write applied to its key.
+func (sc *SyncedCache) sendSnapshot(ctx context.Context, target, requestID string) error {
+	chunk := snapshotChunk{RequestID: requestID, Target: target,
+		Entries: make(map[string][]byte), Clocks: make(map[string]uint64)}
+	publish := func() error {
+		data, err := json.Marshal(chunk)
+		if err != nil {
+			return err
+		}
+		err = sc.synchronizer.Publish(ctx, InvalidationEvent{
+			Key:    "*",
+			Sender: sc.options.PodID,
+			Action: ActionSnapshotChunk,
+			Value:  data,
+		})
+		chunk.Index++
+		chunk.Entries = make(map[string][]byte)
+		chunk.Clocks = make(map[string]uint64)
+		return err
+	}
+
+	var err error
+	sc.localPayloads(func(key string, data []byte) bool {
+		chunk.Entries[key] = data
+		if clock := sc.clocks.last(key); clock != 0 {
+			chunk.Clocks[key] = clock
+		} else if clock := sc.entryInfos.clock(key); clock != 0 {
+			chunk.Clocks[key] = clock
+		}
+		if len(chunk.Entries) == snapshotChunkSize {
+			err = publish()
+		}
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+
+	chunk.Last = true
+	if err := publish(); err != nil {
+		return err
+	}
+	if sc.debugging(DebugSync) {
+		sc.logger.Debug("Snapshot: sent snapshot", "target", target, "chunks", chunk.Index)
+	}
+	return nil
+}
+
+// localPayloads calls fn with the payload of each entry of the local cache, as
+// a Set of this pod would have propagated it, stopping when fn returns false.
+// Entries are read without counting as accesses where the local cache allows it:
+// caches that cannot enumerate their entries are walked by the keys tracked for
+// HitInfo, and only read with Get when the bytes of the entry were not kept or
+// the cache cannot tell whether it holds a key otherwise.
+func (sc *SyncedCache) localPayloads(fn func(key string, data []byte) bool) {
+	if iterable, ok := sc.local.(IterableLocalCache); ok {
+		iterable.Iterate(func(key string, value any) bool {
+			if sc.entryInfos.expired(key) {
+				return true
+			}
+			data, err := sc.localPayload(key, value)
+			return err != nil || fn(key, data)
+		})
+		return
+	}
+
+	admitting, _ := sc.local.(AdmittingLocalCache)
+	for _, key := range sc.entryInfos.entries.Keys() {
+		if sc.entryInfos.expired(key) {
+			continue
+		}
+		data, kept := sc.entryInfos.raw(key)
+		if kept && admitting != nil {
+			if !admitting.Contains(key) {
+				continue
+			}
+		} else {
+			value, found := sc.local.Get(key)
+			if !found {
+				continue
+			}
+			var err error
+			if data, err = sc.localPayload(key, value); err != nil {
+				continue
+			}
+		}
+		if !fn(key, data) {
+			return
+		}
+	}
+}
+
+// localPayload returns the payload of value, the local entry of key: the bytes
+// kept for it, or else the value encoded as a Set would have.
+func (sc *SyncedCache) localPayload(key string, value any) ([]byte, error) {
+	if data, ok := sc.entryInfos.raw(key); ok {
+		return data, nil
+	}
+	return sc.encodeLocal(key, value)
+}
+
+// applySnapshotChunk applies a chunk of the snapshot requested by this pod, as if
+// each entry had been relayed by the responder: entries are discarded when this
+// pod holds the key or applied a newer write to it, and entries whose clock the
+// responder does not know when this pod applied any write to the key or a Clear.
+// Chunks from a second responder are ignored, and a missing chunk ends the transfer.
+func (sc *SyncedCache) applySnapshotChunk(event InvalidationEvent) {
+	transfer := sc.snapshot.Load()
+	if transfer == nil {
+		return
+	}
+	var chunk snapshotChunk
+	if err := json.Unmarshal(event.Value, &chunk); err != nil {
+		return
+	}
+	if chunk.Target != sc.options.PodID || chunk.RequestID != transfer.id {
+		return
+	}
+
+	transfer.mu.Lock()
+	defer transfer.mu.Unlock()
+	select {
+	case <-transfer.done:
+		return
+	default:
+	}
+	if transfer.responder == "" {
+		transfer.responder = event.Sender
+	}
+	if event.Sender != transfer.responder {
+		return
+	}
+	if chunk.Index != transfer.next {
+		transfer.finish(true)
+		return
+	}
+
+	for key, data := range chunk.Entries {
+		clock := chunk.Clocks[key]
+		if clock == 0 && !sc.clocks.untouched(key) {
+			continue
+		}
+		sc.applyEvent(InvalidationEvent{Key: key, Sender: event.Sender, Action: ActionSet, Value: data,
+			Relay: true, Clock: clock})
+	}
+	transfer.entries += len(chunk.Entries)
+	transfer.next++
+	if chunk.Last {
+		transfer.finish(false)
+	}
+}
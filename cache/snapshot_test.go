@@ -0,0 +1,230 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// busSynchronizer delivers published events to every other cache on the bus, as
+// the sync channel does, counting published snapshot chunks.
+type busSynchronizer struct {
+	errorSynchronizer
+	bus    *cacheBus
+	podID  string
+	chunks int
+}
+
+// cacheBus connects the caches of a test cluster.
+type cacheBus struct {
+	mu     sync.Mutex
+	caches []*SyncedCache
+}
+
+func (bs *busSynchronizer) Publish(ctx context.Context, event InvalidationEvent) error {
+	bs.bus.mu.Lock()
+	caches := append([]*SyncedCache(nil), bs.bus.caches...)
+	if event.Action == ActionSnapshotChunk {
+		bs.chunks++
+	}
+	bs.bus.mu.Unlock()
+
+	for _, c := range caches {
+		if c.options.PodID != bs.podID {
+			c.handleInvalidation(event)
+		}
+	}
+	return nil
+}
+
+// join adds a peer-warmup cache with the given pod ID to the bus.
+func (b *cacheBus) join(t *testing.T, podID string, store Store) (*SyncedCache, *busSynchronizer) {
+	c := newMockedCache(t, Options{PodID: podID, PeerWarmup: true, PeerWarmupTimeout: time.Second})
+	// Room for more than one chunk of entries
+	local, err := NewLRUCacheFactory(4 * snapshotChunkSize).Create()
+	if err != nil {
+		t.Fatalf("Failed to create local cache: %v", err)
+	}
+	c.local = local
+	c.entryInfos = newEntryInfos(4*snapshotChunkSize, 0)
+	synchronizer := &busSynchronizer{bus: b, podID: podID}
+	c.synchronizer = synchronizer
+	if store != nil {
+		c.store = store
+	}
+	b.mu.Lock()
+	b.caches = append(b.caches, c)
+	b.mu.Unlock()
+	return c, synchronizer
+}
+
+func TestSyncedCachePeerWarmup(t *testing.T) {
+	bus := &cacheBus{}
+	store := &lockingStore{locks: make(map[string]string)}
+	existing1, sync1 := bus.join(t, "pod-1", store)
+	existing2, sync2 := bus.join(t, "pod-2", store)
+	defer existing1.Close()
+	defer existing2.Close()
+
+	for i := 0; i < snapshotChunkSize+1; i++ {
+		key := "key:" + strconv.Itoa(i)
+		existing1.setLocal(key, "value", 1, SourceSet)
+		existing1.entryInfos.record(key, SourceSet, make([]byte, 7))
+		existing2.setLocal(key, "value", 1, SourceSet)
+		existing2.entryInfos.record(key, SourceSet, make([]byte, 7))
+	}
+
+	joining, _ := bus.join(t, "pod-3", store)
+	defer joining.Close()
+	start := time.Now()
+	joining.requestSnapshot()
+
+	if time.Since(start) >= time.Second {
+		t.Fatal("Expected the snapshot to complete before the timeout")
+	}
+	if sync1.chunks+sync2.chunks != 2 {
+		t.Fatalf("Expected a single responder sending 2 chunks, got %d and %d", sync1.chunks, sync2.chunks)
+	}
+	for i := 0; i < snapshotChunkSize+1; i++ {
+		key := "key:" + strconv.Itoa(i)
+		if value, found := joining.local.Get(key); !found || value != "value" {
+			t.Fatalf("Expected %q to be transferred, got %v", key, value)
+		}
+		if info := joining.entryInfos.hitInfo(key); info.Source != SourcePropagated {
+			t.Fatalf("Expected propagated source for %q, got %v", key, info.Source)
+		}
+	}
+}
+
+func TestSyncedCachePeerWarmupWithoutPeers(t *testing.T) {
+	bus := &cacheBus{}
+	joining, _ := bus.join(t, "pod-1", nil)
+	defer joining.Close()
+	joining.options.PeerWarmupTimeout = 20 * time.Millisecond
+
+	joining.requestSnapshot()
+
+	if joining.snapshot.Load() != nil {
+		t.Fatal("Expected the transfer to be forgotten after the timeout")
+	}
+}
+
+func TestSyncedCacheApplySnapshotChunkOutOfOrder(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1"})
+	transfer := &snapshotTransfer{id: "req", done: make(chan struct{})}
+	c.snapshot.Store(transfer)
+
+	c.applySnapshotChunk(chunkEvent(t, "pod-2", snapshotChunk{RequestID: "req", Target: "pod-1", Index: 1,
+		Entries: map[string][]byte{"a": []byte(`"1"`)}}))
+
+	select {
+	case <-transfer.done:
+	default:
+		t.Fatal("Expected a missing chunk to end the transfer")
+	}
+	if !transfer.failed {
+		t.Fatal("Expected the transfer to be marked failed")
+	}
+	if _, found := c.local.Get("a"); found {
+		t.Fatal("Expected entries of an out-of-order chunk to be dropped")
+	}
+}
+
+func TestSyncedCacheApplySnapshotChunkIgnoresOthers(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1"})
+	transfer := &snapshotTransfer{id: "req", done: make(chan struct{})}
+	c.snapshot.Store(transfer)
+
+	entries := map[string][]byte{"a": []byte(`"1"`)}
+	c.applySnapshotChunk(chunkEvent(t, "pod-2", snapshotChunk{RequestID: "req", Target: "pod-9", Entries: entries}))
+	c.applySnapshotChunk(chunkEvent(t, "pod-2", snapshotChunk{RequestID: "other", Target: "pod-1", Entries: entries}))
+	c.applySnapshotChunk(chunkEvent(t, "pod-2", snapshotChunk{RequestID: "req", Target: "pod-1"}))
+	c.applySnapshotChunk(chunkEvent(t, "pod-3", snapshotChunk{RequestID: "req", Target: "pod-1", Index: 1, Entries: entries}))
+
+	if _, found := c.local.Get("a"); found {
+		t.Fatal("Expected chunks for other pods, requests or responders to be ignored")
+	}
+	if transfer.responder != "pod-2" || transfer.next != 1 {
+		t.Fatalf("Expected pod-2 to be the responder after one chunk, got %q and %d", transfer.responder, transfer.next)
+	}
+}
+
+func TestSyncedCacheApplySnapshotChunkKeepsNewerWrites(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1"})
+	transfer := &snapshotTransfer{id: "req", done: make(chan struct{})}
+	c.snapshot.Store(transfer)
+	c.applyEvent(InvalidationEvent{Key: "deleted", Sender: "pod-3", Action: ActionDelete, Clock: 100})
+	c.applyEvent(InvalidationEvent{Key: "unknown", Sender: "pod-3", Action: ActionDelete, Clock: 100})
+
+	c.applySnapshotChunk(chunkEvent(t, "pod-2", snapshotChunk{RequestID: "req", Target: "pod-1", Last: true,
+		Entries: map[string][]byte{"deleted": []byte(`"old"`), "unknown": []byte(`"old"`),
+			"rewritten": []byte(`"new"`), "untouched": []byte(`"value"`)},
+		Clocks: map[string]uint64{"deleted": 50, "rewritten": 200}}))
+
+	for _, key := range []string{"deleted", "unknown"} {
+		if _, found := c.local.Get(key); found {
+			t.Errorf("Expected %q to stay deleted, as the snapshot holds an older value", key)
+		}
+	}
+	for _, key := range []string{"rewritten", "untouched"} {
+		if _, found := c.local.Get(key); !found {
+			t.Errorf("Expected %q to be transferred", key)
+		}
+	}
+	if c.clocks.last("rewritten") != 0 {
+		t.Error("Expected snapshot entries not to be recorded as writes")
+	}
+}
+
+func TestSyncedCacheSendSnapshotEncodesValues(t *testing.T) {
+	ctx := context.Background()
+	types := NewTypeRegistry()
+	types.Register("feed:", &wrapperspb.StringValue{}, 1)
+	rules := map[string]KeyMarshaller{"feed:*": {ID: 7, Marshaller: NewProtoMarshaller(nil)}}
+
+	responder := newMockedCache(t, Options{PodID: "pod-2", TypeRegistry: types, MarshallerRules: rules})
+	defer responder.Close()
+	local, err := NewLRUCacheFactory(10).Create()
+	if err != nil {
+		t.Fatalf("Failed to create local cache: %v", err)
+	}
+	responder.local = local
+	sync2 := &publishingSynchronizer{}
+	responder.synchronizer = sync2
+	if err := responder.Set(ctx, "feed:1", wrapperspb.String("news")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	hits := responder.local.Metrics().Hits
+	sync2.events = nil
+	if err := responder.sendSnapshot(ctx, "pod-1", "req"); err != nil {
+		t.Fatalf("sendSnapshot failed: %v", err)
+	}
+	if responder.local.Metrics().Hits != hits {
+		t.Error("Expected the snapshot not to count as local hits")
+	}
+
+	c := newMockedCache(t, Options{PodID: "pod-1", TypeRegistry: types, MarshallerRules: rules})
+	defer c.Close()
+	c.snapshot.Store(&snapshotTransfer{id: "req", done: make(chan struct{})})
+	for _, event := range sync2.events {
+		c.applySnapshotChunk(event)
+	}
+	if value, _ := c.Get(ctx, "feed:1"); value == nil || value.(*wrapperspb.StringValue).GetValue() != "news" {
+		t.Errorf("Expected the feed decoded with its rule's Marshaller, got %#v", value)
+	}
+}
+
+// chunkEvent returns the event carrying chunk from sender.
+func chunkEvent(t *testing.T, sender string, chunk snapshotChunk) InvalidationEvent {
+	t.Helper()
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		t.Fatalf("Failed to encode chunk: %v", err)
+	}
+	return InvalidationEvent{Key: "*", Sender: sender, Action: ActionSnapshotChunk, Value: data}
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// staleEntry is a value kept after invalidation for stale-while-revalidate.
+type staleEntry struct {
+	value      any
+	info       HitInfo
+	staleSince time.Time
+}
+
+// staleEntries holds invalidated values in a bounded LRU until they are
+// revalidated or exceed the maximum staleness.
+type staleEntries struct {
+	entries *lru.Cache[string, staleEntry]
+}
+
+// newStaleEntries creates a stale value table holding at most size entries.
+func newStaleEntries(size int) *staleEntries {
+	if size <= 0 {
+		size = defaultEntryInfoSize
+	}
+	entries, _ := lru.New[string, staleEntry](size)
+	return &staleEntries{entries: entries}
+}
+
+// add keeps value as the stale value of key, stale since staleSince.
+func (se *staleEntries) add(key string, value any, info HitInfo, staleSince time.Time) {
+	se.entries.Add(key, staleEntry{value: value, info: info, staleSince: staleSince})
+}
+
+// get returns the stale value of key if it has been stale for at most maxStaleness.
+// Entries older than that are dropped.
+func (se *staleEntries) get(key string, maxStaleness time.Duration) (staleEntry, bool) {
+	entry, ok := se.entries.Get(key)
+	if !ok {
+		return staleEntry{}, false
+	}
+	if time.Since(entry.staleSince) > maxStaleness {
+		se.entries.Remove(key)
+		return staleEntry{}, false
+	}
+	return entry, true
+}
+
+// remove forgets the stale value of key.
+func (se *staleEntries) remove(key string) {
+	se.entries.Remove(key)
+}
+
+// clear forgets all stale values.
+func (se *staleEntries) clear() {
+	se.entries.Purge()
+}
+
+// markStale keeps the current local value of an invalidated key as stale,
+// when stale-while-revalidate is enabled.
+func (sc *SyncedCache) markStale(key string) {
+	if sc.stale == nil {
+		return
+	}
+	if value, found := sc.local.Get(key); found {
+		sc.stale.add(key, value, sc.entryInfos.hitInfo(key), time.Now())
+	}
+}
+
+// markExpired keeps value, the local value of key whose TTL just ran out, as
+// stale since it expired, when stale-while-revalidate is enabled.
+func (sc *SyncedCache) markExpired(key string, value any) {
+	if sc.stale == nil {
+		return
+	}
+	sc.stale.add(key, value, sc.entryInfos.hitInfo(key), sc.entryInfos.expiry(key))
+}
+
+// getStale returns the stale value of key, if any, and starts revalidating it.
+func (sc *SyncedCache) getStale(key string) (any, HitInfo, bool) {
+	if sc.stale == nil {
+		return nil, missInfo, false
+	}
+	entry, ok := sc.stale.get(key, sc.options.StaleWhileRevalidate)
+	if !ok {
+		return nil, missInfo, false
+	}
+
+	atomic.AddInt64(&sc.stats.StaleHits, 1)
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("Get: serving stale value while revalidating", "key", key)
+	}
+	sc.revalidate(key)
+
+	info := entry.info
+	info.Age += time.Since(entry.staleSince)
+	info.Stale = true
+	return entry.value, info, true
+}
+
+// revalidate refreshes a stale key from the remote store or its loader in the background.
+// The stale value is replaced once a fresh value is stored, or dropped if the key no longer exists.
+func (sc *SyncedCache) revalidate(key string) {
+	if _, busy := sc.revalidating.LoadOrStore(key, struct{}{}); busy {
+		return
+	}
+
+	started := sc.goBackground(func(ctx context.Context) {
+		defer sc.revalidating.Delete(key)
+		if sc.fetchRemote(ctx, key) == nil && ctx.Err() == nil {
+			sc.forgetStale(key)
+		}
+	})
+	if !started {
+		sc.revalidating.Delete(key)
+	}
+}
+
+// forgetStale drops the stale value of key, if any.
+func (sc *SyncedCache) forgetStale(key string) {
+	if sc.stale != nil {
+		sc.stale.remove(key)
+	}
+}
+
+// clearStale drops all stale values.
+func (sc *SyncedCache) clearStale() {
+	if sc.stale != nil {
+		sc.stale.clear()
+	}
+}
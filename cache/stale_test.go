@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// waitForStaleGone polls until key no longer has a stale value or the timeout expires.
+func waitForStaleGone(c *SyncedCache, key string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, ok := c.stale.get(key, c.options.StaleWhileRevalidate); !ok {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return false
+}
+
+func TestSyncedCacheStaleWhileRevalidate(t *testing.T) {
+	c := newMockedCache(t, Options{StaleWhileRevalidate: time.Minute})
+	defer c.Close()
+
+	loaded := make(chan struct{})
+	c.RegisterLoader("test:*", func(ctx context.Context, key string) (any, error) {
+		<-loaded
+		return "fresh", nil
+	})
+
+	c.applyEvent(InvalidationEvent{Key: "test:swr", Sender: "other-pod", Action: ActionSet, Value: []byte(`"old"`)})
+	c.applyEvent(InvalidationEvent{Key: "test:swr", Sender: "other-pod", Action: ActionInvalidate})
+
+	value, found, info := c.GetWithInfo(context.Background(), "test:swr")
+	if !found || value != "old" {
+		t.Fatalf("Expected stale value, got %v (found=%v)", value, found)
+	}
+	if !info.Stale || info.Source != SourcePropagated {
+		t.Fatalf("Expected stale propagated hit, got %+v", info)
+	}
+	if stats := c.Stats(); stats.StaleHits != 1 {
+		t.Fatalf("Expected 1 stale hit, got %d", stats.StaleHits)
+	}
+
+	close(loaded)
+	if !waitForLocal(c, "test:swr", 2*time.Second) {
+		t.Fatal("Expected the stale key to be revalidated in the background")
+	}
+
+	value, _, info = c.GetWithInfo(context.Background(), "test:swr")
+	if value != "fresh" || info.Stale {
+		t.Fatalf("Expected fresh value after revalidation, got %v (%+v)", value, info)
+	}
+}
+
+func TestSyncedCacheStaleAfterTTL(t *testing.T) {
+	c := newMockedCache(t, Options{StaleWhileRevalidate: time.Minute})
+	defer c.Close()
+
+	loaded := make(chan struct{})
+	c.RegisterLoader("test:*", func(ctx context.Context, key string) (any, error) {
+		<-loaded
+		return "fresh", nil
+	})
+
+	c.applyEvent(InvalidationEvent{Key: "test:ttl", Sender: "other-pod", Action: ActionSet, Value: []byte(`"old"`), TTL: 10 * time.Millisecond})
+	time.Sleep(20 * time.Millisecond)
+
+	value, found, info := c.GetWithInfo(context.Background(), "test:ttl")
+	if !found || value != "old" {
+		t.Fatalf("Expected the expired value to be served stale, got %v (found=%v)", value, found)
+	}
+	if !info.Stale || info.Age < 10*time.Millisecond {
+		t.Fatalf("Expected a stale hit aged since it was stored, got %+v", info)
+	}
+
+	close(loaded)
+	if !waitForLocal(c, "test:ttl", 2*time.Second) {
+		t.Fatal("Expected the expired key to be revalidated in the background")
+	}
+	value, _, info = c.GetWithInfo(context.Background(), "test:ttl")
+	if value != "fresh" || info.Stale {
+		t.Fatalf("Expected fresh value after revalidation, got %v (%+v)", value, info)
+	}
+}
+
+func TestSyncedCacheStaleDroppedWhenKeyGone(t *testing.T) {
+	c := newMockedCache(t, Options{StaleWhileRevalidate: time.Minute})
+	defer c.Close()
+
+	c.applyEvent(InvalidationEvent{Key: "test:gone", Sender: "other-pod", Action: ActionSet, Value: []byte(`"old"`)})
+	c.applyEvent(InvalidationEvent{Key: "test:gone", Sender: "other-pod", Action: ActionInvalidate})
+
+	if _, found, info := c.GetWithInfo(context.Background(), "test:gone"); !found || !info.Stale {
+		t.Fatalf("Expected stale hit, got found=%v %+v", found, info)
+	}
+	if !waitForStaleGone(c, "test:gone", 2*time.Second) {
+		t.Fatal("Expected the stale value to be dropped when revalidation finds no value")
+	}
+}
+
+func TestSyncedCacheStaleMaxStaleness(t *testing.T) {
+	c := newMockedCache(t, Options{StaleWhileRevalidate: 10 * time.Millisecond})
+	defer c.Close()
+
+	c.applyEvent(InvalidationEvent{Key: "test:expired", Sender: "other-pod", Action: ActionSet, Value: []byte(`"old"`)})
+	c.applyEvent(InvalidationEvent{Key: "test:expired", Sender: "other-pod", Action: ActionInvalidate})
+	time.Sleep(20 * time.Millisecond)
+
+	if _, found := c.Get(context.Background(), "test:expired"); found {
+		t.Fatal("Values stale for longer than the maximum staleness should not be served")
+	}
+}
+
+func TestSyncedCacheStaleNotKeptOnDelete(t *testing.T) {
+	c := newMockedCache(t, Options{StaleWhileRevalidate: time.Minute})
+	defer c.Close()
+
+	c.applyEvent(InvalidationEvent{Key: "test:deleted", Sender: "other-pod", Action: ActionSet, Value: []byte(`"old"`)})
+	c.applyEvent(InvalidationEvent{Key: "test:deleted", Sender: "other-pod", Action: ActionDelete})
+
+	if _, found := c.Get(context.Background(), "test:deleted"); found {
+		t.Fatal("Deleted keys should not be served stale")
+	}
+}
@@ -0,0 +1,203 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// remoteSizeInterval is how often Stats refreshes RemoteSize.
+const remoteSizeInterval = 30 * time.Second
+
+// Stats returns cache statistics.
+// Counters are maintained by the cache itself; LocalSize and Evictions come from
+// the local cache Metrics, and RemoteSize is populated when the store implements SizedStore.
+// RemoteSize is refreshed in the background at most every 30 seconds, so Stats
+// never waits on Redis; it is 0 until the first refresh completes.
+// PendingEvents and OldestPendingEventAge are live gauges of the event application backlog,
+// LastEventAt, SinceLastEvent and the PropagationLag percentiles of event reception.
+func (sc *SyncedCache) Stats() Stats {
+	metrics := sc.local.Metrics()
+	pending, oldest := sc.backlog.snapshot()
+	lagP50, lagP99 := sc.lag.percentiles()
+	circuitState, circuitOpens := sc.breaker.snapshot()
+	stats := Stats{
+		LocalHits:             atomic.LoadInt64(&sc.stats.LocalHits),
+		LocalMisses:           atomic.LoadInt64(&sc.stats.LocalMisses),
+		LocalHitsSet:          atomic.LoadInt64(&sc.stats.LocalHitsSet),
+		LocalHitsRemote:       atomic.LoadInt64(&sc.stats.LocalHitsRemote),
+		LocalHitsPropagated:   atomic.LoadInt64(&sc.stats.LocalHitsPropagated),
+		LocalHitsLoader:       atomic.LoadInt64(&sc.stats.LocalHitsLoader),
+		RemoteHits:            atomic.LoadInt64(&sc.stats.RemoteHits),
+		RemoteMisses:          atomic.LoadInt64(&sc.stats.RemoteMisses),
+		LocalSize:             metrics.Size,
+		Evictions:             metrics.Evictions,
+		Invalidations:         atomic.LoadInt64(&sc.stats.Invalidations),
+		StaleHits:             atomic.LoadInt64(&sc.stats.StaleHits),
+		RejectedSets:          atomic.LoadInt64(&sc.stats.RejectedSets),
+		PendingEvents:         pending,
+		OldestPendingEventAge: oldest,
+		EventGaps:             atomic.LoadInt64(&sc.stats.EventGaps),
+		MissedEvents:          atomic.LoadInt64(&sc.stats.MissedEvents),
+		CircuitState:          circuitState,
+		CircuitOpens:          circuitOpens,
+		DroppedPublishes:      atomic.LoadInt64(&sc.stats.DroppedPublishes),
+		OversizedPropagations: atomic.LoadInt64(&sc.stats.OversizedPropagations),
+		OversizedValues:       atomic.LoadInt64(&sc.stats.OversizedValues),
+		AntiEntropyRepairs:    atomic.LoadInt64(&sc.stats.AntiEntropyRepairs),
+		BloomFilterSkips:      atomic.LoadInt64(&sc.stats.BloomFilterSkips),
+		BloomFilterBuilds:     atomic.LoadInt64(&sc.stats.BloomFilterBuilds),
+		CascadedInvalidations: atomic.LoadInt64(&sc.stats.CascadedInvalidations),
+		LocalSkips:            atomic.LoadInt64(&sc.stats.LocalSkips),
+		FilteredEvents:        atomic.LoadInt64(&sc.stats.FilteredEvents),
+		ReorderedEvents:       atomic.LoadInt64(&sc.stats.ReorderedEvents),
+		SkewedEvents:          atomic.LoadInt64(&sc.stats.SkewedEvents),
+		Refreshes:             atomic.LoadInt64(&sc.stats.Refreshes),
+		RelayedHits:           atomic.LoadInt64(&sc.stats.RelayedHits),
+		CoalescedEvents:       atomic.LoadInt64(&sc.stats.CoalescedEvents),
+		RateLimitedEvents:     atomic.LoadInt64(&sc.stats.RateLimitedEvents),
+		RateLimitedWrites:     atomic.LoadInt64(&sc.stats.RateLimitedWrites),
+		DuplicatePodIDs:       atomic.LoadInt64(&sc.stats.DuplicatePodIDs),
+		PropagationLagP50:     lagP50,
+		PropagationLagP99:     lagP99,
+	}
+	if nanos := atomic.LoadInt64(&sc.lastEvent); nanos != 0 {
+		stats.LastEventAt = time.Unix(0, nanos)
+		stats.SinceLastEvent = time.Since(stats.LastEventAt)
+	}
+	if sc.degraded != nil {
+		stats.QueuedWrites = int64(sc.degraded.len())
+	}
+	if sc.retryQueue != nil {
+		stats.PendingPublishes = int64(sc.retryQueue.len())
+	}
+	if sc.applyPool != nil {
+		stats.EventQueueOverflows = sc.applyPool.overflows.Load()
+	}
+
+	if sc.options.EnableMetrics {
+		stats.Latencies = sc.latencies.snapshot()
+	}
+
+	if partitioned, ok := sc.store.(PartitionedStore); ok {
+		stats.Partitions = partitioned.Partitions()
+	}
+
+	sc.refreshRemoteSize()
+	stats.RemoteSize = atomic.LoadInt64(&sc.remoteSize)
+
+	return stats
+}
+
+// refreshRemoteSize reads the size of the remote store in the background when
+// the last read is older than remoteSizeInterval.
+func (sc *SyncedCache) refreshRemoteSize() {
+	sizer, ok := sc.store.(SizedStore)
+	if !ok || atomic.LoadInt32(&sc.closed) != 0 {
+		return
+	}
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&sc.sizedAt)
+	if last != 0 && now-last < int64(remoteSizeInterval) {
+		return
+	}
+	if !atomic.CompareAndSwapInt64(&sc.sizedAt, last, now) {
+		return
+	}
+	sc.goBackground(func(ctx context.Context) {
+		ctx, cancel := context.WithTimeout(ctx, sc.options.ContextTimeout)
+		defer cancel()
+		if size, err := sizer.Size(ctx); err == nil {
+			atomic.StoreInt64(&sc.remoteSize, size)
+		}
+	})
+}
+
+// StatsSince returns the statistics accumulated since prev was taken.
+// Counters are reported as deltas while sizes are reported as current values,
+// so dashboards can poll at a fixed interval without diffing counters themselves.
+func (sc *SyncedCache) StatsSince(prev Stats) Stats {
+	return sc.Stats().Sub(prev)
+}
+
+// Sub returns the counter deltas between s and prev.
+// Sizes, backlog and lag gauges are taken from s as they are gauges, not counters.
+func (s Stats) Sub(prev Stats) Stats {
+	return Stats{
+		LocalHits:             s.LocalHits - prev.LocalHits,
+		LocalMisses:           s.LocalMisses - prev.LocalMisses,
+		LocalHitsSet:          s.LocalHitsSet - prev.LocalHitsSet,
+		LocalHitsRemote:       s.LocalHitsRemote - prev.LocalHitsRemote,
+		LocalHitsPropagated:   s.LocalHitsPropagated - prev.LocalHitsPropagated,
+		LocalHitsLoader:       s.LocalHitsLoader - prev.LocalHitsLoader,
+		RemoteHits:            s.RemoteHits - prev.RemoteHits,
+		RemoteMisses:          s.RemoteMisses - prev.RemoteMisses,
+		LocalSize:             s.LocalSize,
+		RemoteSize:            s.RemoteSize,
+		Evictions:             s.Evictions - prev.Evictions,
+		Invalidations:         s.Invalidations - prev.Invalidations,
+		StaleHits:             s.StaleHits - prev.StaleHits,
+		RejectedSets:          s.RejectedSets - prev.RejectedSets,
+		PendingEvents:         s.PendingEvents,
+		OldestPendingEventAge: s.OldestPendingEventAge,
+		EventGaps:             s.EventGaps - prev.EventGaps,
+		MissedEvents:          s.MissedEvents - prev.MissedEvents,
+		CircuitState:          s.CircuitState,
+		CircuitOpens:          s.CircuitOpens - prev.CircuitOpens,
+		QueuedWrites:          s.QueuedWrites,
+		PendingPublishes:      s.PendingPublishes,
+		DroppedPublishes:      s.DroppedPublishes - prev.DroppedPublishes,
+		EventQueueOverflows:   s.EventQueueOverflows - prev.EventQueueOverflows,
+		OversizedPropagations: s.OversizedPropagations - prev.OversizedPropagations,
+		OversizedValues:       s.OversizedValues - prev.OversizedValues,
+		AntiEntropyRepairs:    s.AntiEntropyRepairs - prev.AntiEntropyRepairs,
+		BloomFilterSkips:      s.BloomFilterSkips - prev.BloomFilterSkips,
+		BloomFilterBuilds:     s.BloomFilterBuilds - prev.BloomFilterBuilds,
+		CascadedInvalidations: s.CascadedInvalidations - prev.CascadedInvalidations,
+		LocalSkips:            s.LocalSkips - prev.LocalSkips,
+		FilteredEvents:        s.FilteredEvents - prev.FilteredEvents,
+		ReorderedEvents:       s.ReorderedEvents - prev.ReorderedEvents,
+		SkewedEvents:          s.SkewedEvents - prev.SkewedEvents,
+		Refreshes:             s.Refreshes - prev.Refreshes,
+		RelayedHits:           s.RelayedHits - prev.RelayedHits,
+		CoalescedEvents:       s.CoalescedEvents - prev.CoalescedEvents,
+		RateLimitedEvents:     s.RateLimitedEvents - prev.RateLimitedEvents,
+		RateLimitedWrites:     s.RateLimitedWrites - prev.RateLimitedWrites,
+		DuplicatePodIDs:       s.DuplicatePodIDs - prev.DuplicatePodIDs,
+		LastEventAt:           s.LastEventAt,
+		SinceLastEvent:        s.SinceLastEvent,
+		PropagationLagP50:     s.PropagationLagP50,
+		PropagationLagP99:     s.PropagationLagP99,
+		Latencies:             subLatencies(s.Latencies, prev.Latencies),
+		Partitions:            s.Partitions,
+	}
+}
+
+// HitRatio returns the fraction of Get calls served from either the local or remote cache.
+func (s Stats) HitRatio() float64 {
+	return ratio(s.LocalHits+s.RemoteHits, s.LocalHits+s.LocalMisses)
+}
+
+// LocalHitRatio returns the fraction of Get calls served from the local cache.
+func (s Stats) LocalHitRatio() float64 {
+	return ratio(s.LocalHits, s.LocalHits+s.LocalMisses)
+}
+
+// RemoteHitRatio returns the fraction of remote lookups that found the key in the store.
+func (s Stats) RemoteHitRatio() float64 {
+	return ratio(s.RemoteHits, s.RemoteHits+s.RemoteMisses)
+}
+
+// PropagatedHitRatio returns the fraction of local hits served from values
+// received from other pods via propagation rather than Set locally or fetched.
+func (s Stats) PropagatedHitRatio() float64 {
+	return ratio(s.LocalHitsPropagated, s.LocalHits)
+}
+
+// ratio returns part/total, or 0 when total is zero.
+func ratio(part, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
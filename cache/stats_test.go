@@ -0,0 +1,238 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStatsHitRatios(t *testing.T) {
+	stats := Stats{
+		LocalHits:    6,
+		LocalMisses:  4,
+		RemoteHits:   3,
+		RemoteMisses: 1,
+	}
+
+	if ratio := stats.LocalHitRatio(); ratio != 0.6 {
+		t.Fatalf("Expected local hit ratio 0.6, got %v", ratio)
+	}
+	if ratio := stats.RemoteHitRatio(); ratio != 0.75 {
+		t.Fatalf("Expected remote hit ratio 0.75, got %v", ratio)
+	}
+	if ratio := stats.HitRatio(); ratio != 0.9 {
+		t.Fatalf("Expected overall hit ratio 0.9, got %v", ratio)
+	}
+}
+
+func TestStatsPropagatedHitRatio(t *testing.T) {
+	stats := Stats{LocalHits: 8, LocalHitsSet: 2, LocalHitsPropagated: 6}
+	if ratio := stats.PropagatedHitRatio(); ratio != 0.75 {
+		t.Fatalf("Expected propagated hit ratio 0.75, got %v", ratio)
+	}
+
+	delta := Stats{LocalHitsPropagated: 10, LocalHitsRemote: 4}.Sub(Stats{LocalHitsPropagated: 6, LocalHitsRemote: 1})
+	if delta.LocalHitsPropagated != 4 || delta.LocalHitsRemote != 3 {
+		t.Fatalf("Unexpected per-source deltas: %+v", delta)
+	}
+}
+
+func TestSyncedCacheStatsLocalHitsBySource(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	defer c.Close()
+
+	ctx := context.Background()
+	if err := c.Set(ctx, "test:set", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	c.applyEvent(InvalidationEvent{Key: "test:propagated", Sender: "other-pod", Action: ActionSet, Value: []byte(`"value"`)})
+	c.RegisterLoader("test:loaded", func(ctx context.Context, key string) (any, error) {
+		return "value", nil
+	})
+
+	c.Get(ctx, "test:set")
+	c.Get(ctx, "test:propagated")
+	c.Get(ctx, "test:propagated")
+	c.Get(ctx, "test:loaded") // loaded, then served locally below
+	c.Get(ctx, "test:loaded")
+
+	stats := c.Stats()
+	if stats.LocalHits != 4 {
+		t.Fatalf("Expected 4 local hits, got %d", stats.LocalHits)
+	}
+	if stats.LocalHitsSet != 1 || stats.LocalHitsPropagated != 2 || stats.LocalHitsLoader != 1 || stats.LocalHitsRemote != 0 {
+		t.Fatalf("Unexpected per-source local hits: %+v", stats)
+	}
+}
+
+func TestStatsHitRatiosEmpty(t *testing.T) {
+	var stats Stats
+	if stats.HitRatio() != 0 || stats.LocalHitRatio() != 0 || stats.RemoteHitRatio() != 0 {
+		t.Fatal("Ratios should be 0 when no requests were recorded")
+	}
+}
+
+func TestStatsSub(t *testing.T) {
+	prev := Stats{LocalHits: 10, LocalMisses: 5, Evictions: 2, Invalidations: 1, LocalSize: 100}
+	curr := Stats{LocalHits: 15, LocalMisses: 7, Evictions: 3, Invalidations: 4, LocalSize: 80, RemoteSize: 50, PendingEvents: 3, OldestPendingEventAge: time.Second}
+
+	delta := curr.Sub(prev)
+	if delta.LocalHits != 5 || delta.LocalMisses != 2 || delta.Evictions != 1 || delta.Invalidations != 3 {
+		t.Fatalf("Unexpected counter deltas: %+v", delta)
+	}
+	if delta.LocalSize != 80 || delta.RemoteSize != 50 {
+		t.Fatalf("Sizes should be current values, got %+v", delta)
+	}
+	if delta.PendingEvents != 3 || delta.OldestPendingEventAge != time.Second {
+		t.Fatalf("Backlog gauges should be current values, got %+v", delta)
+	}
+}
+
+func TestSyncedCacheStatsSince(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PodID = "test-pod-stats-since"
+	opts.RedisAddr = "localhost:6379"
+	opts.LocalCacheFactory = NewLRUCacheFactory(100)
+	opts.ReaderCanSetToRedis = true
+
+	c, err := New(opts)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.Set(ctx, "test:stats:since", "value"); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	c.Get(ctx, "test:stats:since")
+
+	prev := c.Stats()
+
+	c.Get(ctx, "test:stats:since")
+	c.Get(ctx, "test:stats:since")
+
+	delta := c.StatsSince(prev)
+	if delta.LocalHits != 2 {
+		t.Fatalf("Expected 2 local hits since snapshot, got %d", delta.LocalHits)
+	}
+	if delta.LocalMisses != 0 {
+		t.Fatalf("Expected 0 local misses since snapshot, got %d", delta.LocalMisses)
+	}
+}
+
+func TestSyncedCacheStatsIncludesLocalMetrics(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PodID = "test-pod-stats-local"
+	opts.RedisAddr = "localhost:6379"
+	opts.LocalCacheFactory = NewLRUCacheFactory(100)
+
+	c, err := New(opts)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	stats := c.Stats()
+	if stats.LocalSize != c.local.Metrics().Size {
+		t.Fatalf("Expected LocalSize %d, got %d", c.local.Metrics().Size, stats.LocalSize)
+	}
+	if stats.RemoteSize < 0 {
+		t.Fatalf("RemoteSize should not be negative, got %d", stats.RemoteSize)
+	}
+}
+
+// partitionedStore is an errorStore reporting fixed partition statuses.
+type partitionedStore struct {
+	errorStore
+	statuses []PartitionStatus
+}
+
+func (ps *partitionedStore) Partitions() []PartitionStatus {
+	return ps.statuses
+}
+
+func TestSyncedCacheStatsPartitions(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	defer c.Close()
+	statuses := []PartitionStatus{{Name: "redis-0", Healthy: true}, {Name: "redis-1", Errors: 3}}
+	c.store = &partitionedStore{statuses: statuses}
+
+	if got := c.Stats().Partitions; len(got) != 2 || got[1] != statuses[1] {
+		t.Fatalf("Expected the partition statuses of the store, got %+v", got)
+	}
+}
+
+func TestSyncedCacheStatsEventLag(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	defer c.Close()
+
+	if stats := c.Stats(); !stats.LastEventAt.IsZero() || stats.SinceLastEvent != 0 || stats.PropagationLagP99 != 0 {
+		t.Fatalf("Expected no event stats before the first event, got %+v", stats)
+	}
+
+	now := time.Now()
+	for i := range 100 {
+		sent := now.Add(-time.Duration(i+1) * time.Millisecond).UnixNano()
+		c.handleInvalidation(InvalidationEvent{Key: "user:1", Sender: "other", Action: ActionInvalidate, Time: sent})
+	}
+	// Events of older pods are not stamped and do not count towards the lag
+	c.handleInvalidation(InvalidationEvent{Key: "user:1", Sender: "old", Action: ActionInvalidate})
+
+	stats := c.Stats()
+	if stats.LastEventAt.IsZero() || stats.SinceLastEvent <= 0 {
+		t.Errorf("Expected the last event time to be reported, got %v (%v ago)", stats.LastEventAt, stats.SinceLastEvent)
+	}
+	if stats.PropagationLagP50 < 50*time.Millisecond || stats.PropagationLagP99 < 99*time.Millisecond {
+		t.Errorf("Expected lag p50 >= 50ms and p99 >= 99ms, got %v and %v", stats.PropagationLagP50, stats.PropagationLagP99)
+	}
+	if stats.PropagationLagP50 > stats.PropagationLagP99 {
+		t.Errorf("Expected p50 <= p99, got %v and %v", stats.PropagationLagP50, stats.PropagationLagP99)
+	}
+}
+
+// sizedStore is an errorStore reporting a fixed size, blocking each Size call
+// until release is closed.
+type sizedStore struct {
+	errorStore
+	size    int64
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (ss *sizedStore) Size(ctx context.Context) (int64, error) {
+	ss.calls.Add(1)
+	select {
+	case <-ss.release:
+		return ss.size, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func TestSyncedCacheStatsDoesNotWaitForRemoteSize(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	defer c.Close()
+	store := &sizedStore{size: 42, release: make(chan struct{})}
+	c.store = store
+
+	done := make(chan Stats)
+	go func() { done <- c.Stats() }()
+	select {
+	case stats := <-done:
+		if stats.RemoteSize != 0 {
+			t.Fatalf("Expected no remote size before the first refresh, got %d", stats.RemoteSize)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Stats waited for the remote store")
+	}
+
+	close(store.release)
+	waitFor(t, "remote size", func() bool { return c.Stats().RemoteSize == 42 })
+	if calls := store.calls.Load(); calls != 1 {
+		t.Fatalf("Expected a single Size call per refresh interval, got %d", calls)
+	}
+}
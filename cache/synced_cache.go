@@ -2,8 +2,11 @@ package cache
 
 import (
 	"context"
+	"errors"
+	"io"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"golang.org/x/sync/singleflight"
 
@@ -16,13 +19,57 @@ type SyncedCache struct {
 	local        LocalCache
 	store        Store
 	synchronizer Synchronizer
+	syncConn     io.Closer // dedicated synchronizer connection, nil when sharing the store's
 	serializer   Marshaller
 	logger       Logger
 	options      Options
 	closed       int32
+	lastEvent    int64 // unix nanoseconds of the last received event
+	lastDupWarn  int64 // unix nanoseconds of the last duplicate PodID warning
+	remoteSize   int64 // last size reported by a SizedStore, see refreshRemoteSize
+	sizedAt      int64 // unix nanoseconds of the last remote size refresh
+	leader       int32 // 1 while this pod holds the leader lease
+	lag          propagationLag
 	stats        Stats
-	statsMutex   sync.RWMutex
 	sfGroup      singleflight.Group
+	entryInfos   *entryInfos
+	applyPool    *applyPool
+	backlog      *eventBacklog
+	writeQueue   chan pendingWrite
+	loaders      []patternLoader
+	extFormats   []prefixFormat
+	propRules    []propagationRule
+	codecs       keyMarshallers
+	stale        *staleEntries
+	hotKeys      *hotKeyCounter
+	watchers     lifecycleWatchers
+	peers        peerRegistry
+	acks         ackWaiters
+	leaderFns    leaderCallbacks
+	refreshes    refreshSchedule
+	clock        hybridClock
+	clocks       keyClocks
+	latencies    latencyHistograms
+	deps         dependencyGraph
+	changes      changeSubscribers
+	writes       writeTracker
+	breaker      *circuitBreaker
+	degraded     *degradedQueue
+	retryQueue   *publishRetryQueue
+	publishLimit *tokenBucket
+	writeLimit   *tokenBucket
+	coalesced    *coalescedEvents
+	snapshot     atomic.Pointer[snapshotTransfer]
+	bloom        atomic.Pointer[bloomFilter]
+	building     atomic.Pointer[bloomFilter]
+	revalidating sync.Map
+	loadersMutex sync.RWMutex
+	bgCtx        context.Context
+	bgCancel     context.CancelFunc
+	bgWG         sync.WaitGroup
+	bgMutex      sync.Mutex
+	bgStopped    bool
+	drainCtx     context.Context // Shutdown context, bounding the write-behind drain
 }
 
 // New creates a new SyncedCache instance.
@@ -33,7 +80,12 @@ func New(opts Options) (*SyncedCache, error) {
 
 	// Set defaults for optional fields
 	if opts.LocalCacheFactory == nil {
-		opts.LocalCacheFactory = NewLFUCacheFactory(opts.LocalCacheConfig)
+		config := opts.LocalCacheConfig
+		if opts.LocalCacheShards > 1 {
+			// Share the configured capacity among the shards
+			config = config.perShard(opts.LocalCacheShards)
+		}
+		opts.LocalCacheFactory = defaultLocalCacheFactory(config)
 	}
 	if opts.Marshaller == nil {
 		opts.Marshaller = NewJSONMarshaller()
@@ -41,30 +93,183 @@ func New(opts Options) (*SyncedCache, error) {
 	if opts.Logger == nil {
 		opts.Logger = NewNoOpLogger()
 	}
+	if opts.SyncTransport == "" {
+		opts.SyncTransport = SyncTransportPubSub
+	}
+	if opts.ConsistencyMode == "" {
+		opts.ConsistencyMode = ConsistencyLocalFirst
+	}
+	if opts.DegradedWritePolicy == "" {
+		opts.DegradedWritePolicy = DegradedWriteFailFast
+	}
+	if opts.RejectedSetPolicy == "" {
+		opts.RejectedSetPolicy = RejectedSetLog
+	}
+	if opts.LocalValueMode == "" {
+		opts.LocalValueMode = LocalValueDecoded
+	}
+	if opts.WritePolicy == WritePolicyWriteBehind {
+		opts.WriteBehind = true
+	}
+	opts.WritePolicy = opts.effectiveWritePolicy()
+	if opts.WriteBehind && opts.WriteBehindQueueSize == 0 {
+		opts.WriteBehindQueueSize = defaultWriteBehindQueueSize
+	}
+	if opts.RateLimitPolicy == "" {
+		opts.RateLimitPolicy = RateLimitCoalesce
+	}
+	if opts.LeaderElection && opts.LeaderLeaseTTL == 0 {
+		opts.LeaderLeaseTTL = defaultLeaderLeaseTTL
+	}
+	if opts.MaxClockSkew == 0 {
+		opts.MaxClockSkew = defaultMaxClockSkew
+	}
 
 	// Create local cache
-	local, err := opts.LocalCacheFactory.Create()
+	factory := opts.LocalCacheFactory
+	if opts.LocalCacheShards > 1 {
+		factory = NewShardedLocalCacheFactory(factory, opts.LocalCacheShards)
+	}
+	if opts.SpilloverDir != "" {
+		if opts.SpilloverThreshold == 0 {
+			opts.SpilloverThreshold = defaultSpilloverThreshold
+		}
+		if opts.SpilloverMaxBytes == 0 {
+			opts.SpilloverMaxBytes = defaultSpilloverMaxBytes
+		}
+		disk := NewDiskCacheFactory(opts.SpilloverDir, opts.SpilloverMaxBytes, opts.Marshaller)
+		factory = NewTieredLocalCacheFactory(factory, disk, opts.SpilloverThreshold)
+	}
+	local, err := factory.Create()
 	if err != nil {
 		return nil, err
 	}
 
-	// Create Redis store
-	store, err := storage.NewRedisStore(opts.RedisAddr, opts.RedisPassword, opts.RedisDB)
+	// Create Redis store, partitioned when configured
+	var store *storage.RedisStore
+	var remote Store
+	if opts.ConnectionManager != nil {
+		store, err = storage.NewRedisStoreWithClient(opts.ConnectionManager.Client())
+	} else if opts.RedisClient != nil {
+		store, err = storage.NewRedisStoreWithClient(opts.RedisClient)
+	} else if len(opts.RedisPartitions) > 0 {
+		var partitioned *storage.PartitionedStore
+		partitioned, store, err = newPartitionedStore(opts)
+		if err == nil {
+			partitioned.SetNamespace(opts.Namespace)
+			remote = partitioned
+		}
+	} else {
+		store, err = storage.NewRedisStoreWithOptions(opts.redisOptions())
+	}
 	if err != nil {
 		local.Close()
-		return nil, err
+		return nil, categorize(ErrRemoteStore, err)
+	}
+	if remote == nil {
+		store.SetNamespace(opts.Namespace)
+		remote = store
 	}
 
-	// Create synchronizer
-	synchronizer := cachesync.NewPubSubSynchronizer(store.GetClient(), opts.InvalidationChannel, opts.PodID)
+	// Create synchronizer, on its own connection when configured
+	syncClient := store.GetClient()
+	var syncConn io.Closer
+	if opts.SyncRedisClient != nil || opts.SyncRedisAddr != "" {
+		var conn *storage.RedisStore
+		if opts.SyncRedisClient != nil {
+			conn, err = storage.NewRedisStoreWithClient(opts.SyncRedisClient)
+		} else {
+			conn, err = storage.NewRedisStoreWithOptions(opts.syncRedisOptions())
+		}
+		if err != nil {
+			remote.Close()
+			local.Close()
+			return nil, categorize(ErrSubscription, err)
+		}
+		syncClient, syncConn = conn.GetClient(), conn
+	}
+	if opts.FaultInjector != nil {
+		// Partitions are hooked when created
+		if len(opts.RedisPartitions) == 0 {
+			store.GetClient().AddHook(faultHook{opts.FaultInjector})
+		}
+		if syncConn != nil {
+			syncClient.AddHook(faultHook{opts.FaultInjector})
+		}
+	}
+	var synchronizer Synchronizer
+	if opts.SyncTransport == SyncTransportStreams {
+		streams := cachesync.NewStreamsSynchronizer(syncClient, opts.InvalidationChannel, opts.PodID, opts.StreamMaxLen)
+		streams.SetEncoding(opts.EventEncoding)
+		synchronizer = streams
+	} else {
+		pubsub := cachesync.NewPubSubSynchronizer(syncClient, opts.InvalidationChannel, opts.PodID)
+		pubsub.SetEncoding(opts.EventEncoding)
+		if len(opts.PrefixChannels) > 0 {
+			pubsub.SetPrefixChannels(opts.PrefixChannels)
+		}
+		if len(opts.PatternChannels) > 0 {
+			pubsub.SetPatternChannels(opts.PatternChannels)
+		}
+		if len(opts.AdditionalChannels) > 0 {
+			pubsub.SetAdditionalChannels(opts.AdditionalChannels)
+		}
+		if opts.ReceiveOwnEvents {
+			pubsub.SetReceiveOwnEvents(true)
+		}
+		if opts.ConnectionManager != nil {
+			pubsub.SetMux(opts.ConnectionManager.mux)
+		}
+		synchronizer = pubsub
+	}
 
 	sc := &SyncedCache{
 		local:        local,
-		store:        store,
+		store:        remote,
 		synchronizer: synchronizer,
+		syncConn:     syncConn,
 		serializer:   opts.Marshaller,
-		logger:       opts.Logger,
+		logger:       withFields(opts.Logger, "pod_id", opts.PodID, "channel", opts.InvalidationChannel),
 		options:      opts,
+		entryInfos:   newEntryInfos(opts.LocalCacheConfig.MaxSize, opts.LocalTTL),
+		backlog:      newEventBacklog(),
+		extFormats:   newPrefixFormats(opts.ExternalFormats),
+		propRules:    newPropagationRules(opts.PropagationRules),
+		codecs:       newKeyMarshallers(opts.MarshallerRules),
+	}
+	sc.entryInfos.keepRaw = opts.KeepRawBytes || opts.LocalValueMode == LocalValueBoth
+	sc.entryInfos.onDrop = sc.local.Delete
+	sc.clock.maxSkew = opts.MaxClockSkew
+	sc.clocks.size = opts.LocalCacheConfig.MaxSize
+	sc.deps.size = opts.LocalCacheConfig.MaxSize
+	if evicting, ok := local.(EvictingLocalCache); ok {
+		evicting.OnEvict(sc.onLocalEvict)
+	}
+	sc.entryInfos.etags = opts.ComputeETags
+	if opts.StaleWhileRevalidate > 0 {
+		sc.stale = newStaleEntries(opts.LocalCacheConfig.MaxSize)
+	}
+	sc.breaker = newCircuitBreaker(opts.BreakerThreshold, opts.BreakerCooldown, sc.replayDegraded)
+	if sc.breaker != nil && opts.DegradedWritePolicy == DegradedWriteQueue {
+		sc.degraded = newDegradedQueue(opts.DegradedQueueSize)
+	}
+	sc.bgCtx, sc.bgCancel = context.WithCancel(context.Background())
+	if opts.PropagationWorkers > 0 {
+		sc.applyPool = newApplyPool(opts.PropagationWorkers, opts.PropagationQueueSize, sc.backlog, sc.applyTimed)
+	}
+	if opts.PublishQueueSize > 0 {
+		sc.retryQueue = newPublishRetryQueue(opts.PublishQueueSize)
+		sc.goBackground(sc.runPublishRetries)
+	}
+	sc.publishLimit = newTokenBucket(opts.PublishRateLimit, opts.PublishBurst)
+	sc.writeLimit = newTokenBucket(opts.WriteRateLimit, opts.WriteBurst)
+	if sc.publishLimit != nil && opts.RateLimitPolicy == RateLimitCoalesce {
+		sc.coalesced = &coalescedEvents{actions: make(map[string]Action)}
+		sc.goBackground(sc.runCoalescedEvents)
+	}
+	if opts.Writer != nil && opts.WriteBehind {
+		sc.writeQueue = make(chan pendingWrite, opts.WriteBehindQueueSize)
+		sc.goBackground(sc.runWriteBehind)
 	}
 
 	// Subscribe to invalidation events
@@ -73,386 +278,999 @@ func New(opts Options) (*SyncedCache, error) {
 
 	if err := synchronizer.Subscribe(ctx); err != nil {
 		sc.Close()
-		return nil, err
+		return nil, categorize(ErrSubscription, err)
 	}
 
 	// Register invalidation callback
-	synchronizer.OnInvalidate(sc.handleInvalidation)
+	if opts.FaultInjector != nil {
+		synchronizer.OnInvalidate(injectEventFaults(opts.FaultInjector, sc.handleInvalidation))
+	} else {
+		synchronizer.OnInvalidate(sc.handleInvalidation)
+	}
+	if detector, ok := synchronizer.(GapDetectingSynchronizer); ok {
+		detector.OnGap(sc.handleGap)
+	}
+	if detector, ok := synchronizer.(DuplicateDetectingSynchronizer); ok {
+		detector.OnDuplicatePodID(sc.handleDuplicatePodID)
+	}
+
+	if err := sc.waitForSubscription(); err != nil {
+		sc.Close()
+		return nil, categorize(ErrSubscription, err)
+	}
+
+	sc.requestSnapshot()
+	sc.startWarmup()
+	sc.announceBackground(LifecyclePodJoined, nil)
+	sc.startHotKeys()
+	sc.startAntiEntropy()
+	sc.startHeartbeats()
+	sc.startLeaderElection()
+	sc.startBloomFilter()
+
+	if opts.DebugMode {
+		sc.logger.Info("Cache started", "config", sc.Describe())
+	}
 
 	return sc, nil
 }
 
+// waitForSubscription blocks until the synchronizer confirms its subscription is
+// active, for at most Options.SubscribeTimeout. It is a no-op when the timeout is
+// not set or the synchronizer does not implement ReadySynchronizer.
+func (sc *SyncedCache) waitForSubscription() error {
+	if sc.options.SubscribeTimeout <= 0 {
+		return nil
+	}
+	ready, ok := sc.synchronizer.(ReadySynchronizer)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sc.options.SubscribeTimeout)
+	defer cancel()
+
+	if err := ready.WaitReady(ctx); err != nil {
+		sc.reportError(OpSubscribe, "", ErrSubscription, err)
+		if sc.logging(DebugSync) {
+			sc.logger.Error("Subscription not confirmed", "timeout", sc.options.SubscribeTimeout, "error", err)
+		}
+		return ErrSubscriptionNotReady
+	}
+
+	if sc.debugging(DebugSync) {
+		sc.logger.Debug("Subscription confirmed")
+	}
+	return nil
+}
+
 // Get retrieves a value from the cache.
 func (sc *SyncedCache) Get(ctx context.Context, key string) (any, bool) {
+	value, found, _ := sc.get(ctx, key)
+	return value, found
+}
+
+// get is the internal implementation of Get operations.
+func (sc *SyncedCache) get(ctx context.Context, key string) (any, bool, HitInfo) {
 	if atomic.LoadInt32(&sc.closed) != 0 {
-		return nil, false
+		return nil, false, missInfo
 	}
 
-	if sc.options.DebugMode {
+	if sc.debugging(DebugOps) {
 		sc.logger.Debug("Get: attempting to retrieve key", "key", key)
 	}
 
 	// Try local cache first
-	value, found := sc.local.Get(key)
+	start := sc.startTimer()
+	value, found := sc.getLocal(key)
+	sc.latencies.localGet.observe(start)
 	if found {
-		sc.recordLocalHit()
-		if sc.options.DebugMode {
+		info := sc.entryInfos.hitInfo(key)
+		sc.recordLocalHit(info.Source)
+		if sc.debugging(DebugOps) {
 			sc.logger.Debug("Get: found in local cache", "key", key)
 		}
-		return value, true
+		sc.recordHotKey(key)
+		sc.prefetchRelated(key)
+		return value, true, info
 	}
 
 	sc.recordLocalMiss()
-	if sc.options.DebugMode {
+
+	if value, info, ok := sc.getStale(key); ok {
+		sc.recordHotKey(key)
+		return value, true, info
+	}
+
+	if sc.debugging(DebugOps) {
 		sc.logger.Debug("Get: not found in local cache, checking remote", "key", key)
 	}
 
+	res := sc.fetchRemote(ctx, key)
+	if res == nil {
+		return nil, false, missInfo
+	}
+	sc.recordHotKey(key)
+	sc.prefetchRelated(key)
+	return res.value, true, res.info
+}
+
+// getLocal returns a value from the local cache, dropping it if it outlived the
+// TTL it was stored with, or keeping it as stale under StaleWhileRevalidate.
+func (sc *SyncedCache) getLocal(key string) (any, bool) {
+	value, found := sc.local.Get(key)
+	if found && sc.entryInfos.expired(key) {
+		sc.markExpired(key, value)
+		sc.local.Delete(key)
+		sc.entryInfos.remove(key)
+		sc.changes.emit(ChangeEvent{Type: ChangeEvicted, Key: key})
+		return nil, false
+	}
+	return value, found
+}
+
+// fetchRemote loads a key from the remote store into the local cache.
+// It returns nil when the key is not found or cannot be deserialized.
+// Each caller waits for at most its own ctx deadline, while the shared fetch is
+// bounded by RemoteGetTimeout (or ContextTimeout) so that one caller giving up
+// does not fail the others waiting on the same key.
+func (sc *SyncedCache) fetchRemote(ctx context.Context, key string) *getResult {
+	if err := ctx.Err(); err != nil {
+		sc.reportContextError(key, err)
+		return nil
+	}
+
 	// Fallback to Redis using singleflight to prevent thundering herd.
 	// Multiple concurrent requests for the same key will share a single Redis query.
-	result, _, _ := sc.sfGroup.Do(key, func() (any, error) {
+	ch := sc.sfGroup.DoChan(key, func() (any, error) {
+		ctx, cancel := sc.remoteGetContext(ctx)
+		defer cancel()
+
 		// Double-check local cache inside singleflight in case another goroutine
 		// populated it while we were waiting for the singleflight lock.
-		if value, found := sc.local.Get(key); found {
-			if sc.options.DebugMode {
+		if value, found := sc.getLocal(key); found {
+			if sc.debugging(DebugOps) {
 				sc.logger.Debug("Get: found in local cache during singleflight", "key", key)
 			}
-			return value, nil
+			return &getResult{value: value, info: sc.entryInfos.hitInfo(key)}, nil
+		}
+
+		// Redis holds no values under DisableRemoteStore: load from the source of truth
+		if sc.options.DisableRemoteStore {
+			if res := sc.load(ctx, key); res != nil {
+				return res, nil
+			}
+			return nil, nil
 		}
 
-		data, err := sc.store.Get(ctx, key)
+		// Serve the local cache only while Redis is unavailable
+		if !sc.breaker.allow() {
+			if sc.debugging(DebugOps) {
+				sc.logger.Debug("Get: circuit open, skipping remote cache", "key", key)
+			}
+			return nil, nil
+		}
+
+		format := sc.externalFormatFor(key)
+		if format == nil && sc.bloomSkips(key) {
+			if res := sc.load(ctx, key); res != nil {
+				return res, nil
+			}
+			return nil, nil
+		}
+		serializer := sc.serializer
+		var data []byte
+		var err error
+		var readAt uint64
+		start := sc.startTimer()
+		if format != nil {
+			serializer = format.format.Marshaller
+			data, err = sc.getExternal(ctx, format, key)
+		} else {
+			readAt = sc.clock.tick()
+			data, err = sc.store.Get(ctx, key)
+		}
+		sc.latencies.remoteGet.observe(start)
+		sc.breaker.record(err)
+		if err != nil && ctx.Err() != nil {
+			sc.reportContextError(key, ctx.Err())
+			return nil, nil
+		}
 		if err != nil {
 			sc.recordRemoteMiss()
-			if sc.options.DebugMode {
+			if sc.debugging(DebugOps) {
 				sc.logger.Debug("Get: not found in remote cache", "key", key, "error", err)
 			}
+			if res := sc.load(ctx, key); res != nil {
+				return res, nil
+			}
 			return nil, nil
 		}
 
 		sc.recordRemoteHit()
-		if sc.options.DebugMode {
+		if sc.debugging(DebugOps) {
 			sc.logger.Debug("Get: found in remote cache", "key", key)
 		}
 
 		// Deserialize
-		var val any
-		if err := sc.serializer.Unmarshal(data, &val); err != nil {
-			if sc.options.OnError != nil {
-				sc.options.OnError(err)
-			}
-			if sc.options.DebugMode {
+		val, err := sc.decodeLocal(serializer, key, data)
+		if err != nil {
+			sc.reportError(OpGet, key, ErrDeserialization, err)
+			if sc.logging(DebugSerialization) {
 				sc.logger.Error("Get: deserialization failed", "key", key, "error", err)
 			}
 			return nil, nil
 		}
 
 		// Populate local cache
-		sc.local.Set(key, val, 1)
-		if sc.options.DebugMode {
-			sc.logger.Debug("Get: populated local cache", "key", key)
+		if !sc.skipLocal(key) {
+			sc.setLocal(key, val, sc.cost(key, val, data), SourceRemote)
+			sc.entryInfos.record(key, SourceRemote, data)
+			if sc.debugging(DebugOps) {
+				sc.logger.Debug("Get: populated local cache", "key", key)
+			}
+		}
+		if format == nil {
+			sc.relayRemoteHit(key, data, readAt)
 		}
 
-		return val, nil
+		info := HitInfo{Level: LevelRemote, Source: SourceRemote, Size: len(data)}
+		info.CreatedAt, info.Origin = writtenBy(data)
+		return &getResult{value: val, info: info}, nil
 	})
 
-	return result, result != nil
+	var result any
+	select {
+	case r := <-ch:
+		result = r.Val
+	case <-ctx.Done():
+		sc.reportContextError(key, ctx.Err())
+		return nil
+	}
+
+	res, ok := result.(*getResult)
+	if !ok || res.value == nil {
+		return nil
+	}
+	return res
+}
+
+// remoteGetContext returns the context for a shared remote fetch. It keeps the
+// values of ctx but not its cancellation, and is bounded by RemoteGetTimeout,
+// falling back to ContextTimeout.
+func (sc *SyncedCache) remoteGetContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := sc.options.RemoteGetTimeout
+	if timeout <= 0 {
+		timeout = sc.options.ContextTimeout
+	}
+	ctx = context.WithoutCancel(ctx)
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// reportContextError reports a Get abandoned because its context ended.
+// Deadline errors are reported via OnError as ErrTimeout; cancellations are not reported.
+func (sc *SyncedCache) reportContextError(key string, err error) {
+	if sc.logging(DebugOps) {
+		sc.logger.Warn("Get: context done before remote fetch completed", "key", key, "error", err)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		sc.reportError(OpGet, key, ErrRemoteStore, ErrTimeout)
+	}
+}
+
+// getResult is the value shared between singleflight callers of get.
+type getResult struct {
+	value any
+	info  HitInfo
 }
 
 // Set stores a value in the cache and propagates it to other pods.
 // This is the default behavior - the value is sent to other pods so they can
-// update their local caches without fetching from Redis.
-func (sc *SyncedCache) Set(ctx context.Context, key string, value any) error {
-	return sc.setInternal(ctx, key, value, false)
+// update their local caches without fetching from Redis. opts customize this
+// call, e.g. WithTTL or WithInvalidateOnly.
+func (sc *SyncedCache) Set(ctx context.Context, key string, value any, opts ...SetOption) error {
+	if sc.options.ReadOnly {
+		return ErrReadOnly
+	}
+	cfg := sc.setConfig(key, opts)
+	if cfg.checkVersion {
+		_, err := sc.setIfVersion(ctx, key, value, cfg)
+		return err
+	}
+	return sc.setInternal(ctx, key, value, cfg)
 }
 
 // SetWithInvalidate stores a value in the cache and invalidates it on other pods.
 // Use this when you want other pods to fetch the value from Redis instead of
 // receiving it directly (useful for large values or when you want lazy loading).
 func (sc *SyncedCache) SetWithInvalidate(ctx context.Context, key string, value any) error {
-	return sc.setInternal(ctx, key, value, true)
+	return sc.Set(ctx, key, value, WithInvalidateOnly())
 }
 
 // setInternal is the internal implementation of Set operations.
-func (sc *SyncedCache) setInternal(ctx context.Context, key string, value any, invalidateOnly bool) error {
-	if atomic.LoadInt32(&sc.closed) != 0 {
+func (sc *SyncedCache) setInternal(ctx context.Context, key string, value any, cfg setConfig) error {
+	if !sc.beginWrite() {
 		return ErrCacheClosed
 	}
+	defer sc.writes.end()
 
-	if sc.options.DebugMode {
-		sc.logger.Debug("Set: storing value", "key", key, "invalidateOnly", invalidateOnly)
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("Set: storing value", "key", key, "invalidate_only", cfg.invalidateOnly, "policy", cfg.policy)
 	}
-
-	// Set in local cache
-	sc.local.Set(key, value, 1)
-	if sc.options.DebugMode {
-		sc.logger.Debug("Set: stored in local cache", "key", key)
+	if cfg.ttl > 0 {
+		if _, ok := sc.store.(ExpiringStore); !ok && cfg.policy.writesRemote() && !sc.options.DisableRemoteStore {
+			return ErrTTLNotSupported
+		}
+	}
+	if cfg.group != "" {
+		if _, ok := sc.store.(GroupStore); !ok && cfg.policy.writesRemote() && !sc.options.DisableRemoteStore {
+			return ErrGroupsNotSupported
+		}
 	}
 
 	// Serialize
-	data, err := sc.serializer.Marshal(value)
+	start := sc.startTimer()
+	data, err := sc.encode(key, value)
+	sc.latencies.marshal.observe(start)
 	if err != nil {
-		if sc.options.OnError != nil {
-			sc.options.OnError(err)
-		}
-		if sc.options.DebugMode {
+		sc.reportError(OpSet, key, ErrSerialization, err)
+		if sc.logging(DebugSerialization) {
 			sc.logger.Error("Set: serialization failed", "key", key, "error", err)
 		}
+		return categorize(ErrSerialization, err)
+	}
+	if err := sc.checkValueSize(key, data); err != nil {
 		return err
 	}
 
-	// ReaderCanSetToRedis prevents reader nodes from overwriting data in Redis with potentially stale values
-	if sc.options.ReaderCanSetToRedis {
-		// Set in Redis
-		if err := sc.store.Set(ctx, key, data); err != nil {
-			if sc.options.OnError != nil {
-				sc.options.OnError(err)
-			}
-			if sc.options.DebugMode {
-				sc.logger.Error("Set: failed to store in remote cache", "key", key, "error", err)
-			}
-			return err
+	open := !sc.breaker.allow()
+	if open && sc.degraded == nil {
+		return ErrCircuitOpen
+	}
+
+	// Persist to the backing database before caching so a failed write-through
+	// never leaves a value in the cache that the database does not have
+	if err := sc.persist(ctx, key, value); err != nil {
+		if sc.logging(DebugOps) {
+			sc.logger.Error("Set: failed to persist value", "key", key, "error", err)
 		}
-	} else {
-		if sc.options.DebugMode {
-			sc.logger.Debug("Set: skipping Redis write (ReaderCanSetToRedis=false)", "key", key)
+		return err
+	}
+
+	if open {
+		return sc.degradedSet(key, value, data, cfg)
+	}
+
+	if err := sc.storeAndPublish(ctx, key, value, data, SourceSet, cfg); err != nil {
+		return err
+	}
+	sc.dependencyWritten(ctx, key, cfg.dependsOn)
+	return nil
+}
+
+// checkValueSize returns ErrValueTooLarge when data is larger than
+// Options.MaxValueSize, counting the rejection in Stats.OversizedValues.
+func (sc *SyncedCache) checkValueSize(key string, data []byte) error {
+	if sc.options.MaxValueSize <= 0 || len(data) <= sc.options.MaxValueSize {
+		return nil
+	}
+	atomic.AddInt64(&sc.stats.OversizedValues, 1)
+	sc.reportError(OpSet, key, nil, ErrValueTooLarge)
+	if sc.logging(DebugOps) {
+		sc.logger.Warn("Set: value exceeds MaxValueSize", "key", key, "size", len(data), "max", sc.options.MaxValueSize)
+	}
+	return ErrValueTooLarge
+}
+
+// storeAndPublish stores a serialized value in the local and remote caches and
+// publishes the synchronization event for it, in the order set by ConsistencyMode.
+// source is recorded as the entry source.
+func (sc *SyncedCache) storeAndPublish(ctx context.Context, key string, value any, data []byte, source HitSource, cfg setConfig) error {
+	remoteFirst := sc.options.ConsistencyMode == ConsistencyRemoteFirst || sc.options.ConsistencyMode == ConsistencyOutbox
+	if !remoteFirst {
+		sc.storeLocal(key, value, data, source, cfg)
+	}
+
+	published, err := sc.storeRemote(ctx, key, data, cfg)
+	if err != nil {
+		if !remoteFirst && sc.options.RollbackLocalOnError {
+			sc.rollbackLocal(key)
 		}
+		return err
+	}
+	if !published && !cfg.noPropagate {
+		sc.publishSet(ctx, key, data, cfg)
 	}
 
-	if sc.options.DebugMode {
-		sc.logger.Debug("Set: stored in remote cache", "key", key)
+	if remoteFirst {
+		sc.storeLocal(key, value, data, source, cfg)
 	}
+	return nil
+}
 
-	// Publish synchronization event
-	var event InvalidationEvent
-	if invalidateOnly {
-		// Invalidate-only mode: other pods will delete the key from local cache
-		event = InvalidationEvent{
-			Key:    key,
-			Sender: sc.options.PodID,
-			Action: ActionInvalidate,
+// storeLocal stores a serialized value in the local cache. Loaded values of keys
+// rejected by Options.ShouldCacheLocally are not stored.
+func (sc *SyncedCache) storeLocal(key string, value any, data []byte, source HitSource, cfg setConfig) {
+	if source == SourceLoader && sc.skipLocal(key) {
+		return
+	}
+	value = sc.localValue(key, value, data)
+	sc.setLocalWithTTL(key, value, sc.localCost(cfg, key, value, data), sc.entryInfos.window(cfg.ttl, cfg.localTTL), source)
+	sc.entryInfos.record(key, source, data)
+	sc.entryInfos.annotate(key, cfg.ttl, cfg.localTTL, cfg.tags)
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("Set: stored in local cache", "key", key)
+	}
+}
+
+// rollbackLocal removes a value whose remote write failed from the local cache,
+// so the next Get reads the value Redis still holds instead of one only this pod has.
+func (sc *SyncedCache) rollbackLocal(key string) {
+	sc.local.Delete(key)
+	sc.entryInfos.remove(key)
+	sc.forgetStale(key)
+	sc.changes.emit(ChangeEvent{Type: ChangeInvalidated, Key: key})
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("Set: rolled back local cache after remote write failure", "key", key)
+	}
+}
+
+// storeRemote stores a serialized value in the remote cache. It reports whether the
+// synchronization event was published along with it under ConsistencyOutbox.
+func (sc *SyncedCache) storeRemote(ctx context.Context, key string, data []byte, cfg setConfig) (bool, error) {
+	// WritePolicyLocalOnly prevents reader nodes from overwriting data in Redis with potentially stale values
+	if sc.externalFormatFor(key) != nil {
+		if sc.debugging(DebugOps) {
+			sc.logger.Debug("Set: skipping Redis write for key owned by an external writer", "key", key)
 		}
-	} else {
-		// Propagation mode: other pods will update their local cache with the value
-		event = InvalidationEvent{
-			Key:    key,
-			Sender: sc.options.PodID,
-			Action: ActionSet,
-			Value:  data,
+		return false, nil
+	}
+	if !cfg.policy.writesRemote() {
+		if sc.debugging(DebugOps) {
+			sc.logger.Debug("Set: skipping Redis write (WritePolicyLocalOnly)", "key", key)
+		}
+		return false, nil
+	}
+	if sc.options.DisableRemoteStore {
+		if sc.debugging(DebugOps) {
+			sc.logger.Debug("Set: skipping Redis write (DisableRemoteStore)", "key", key)
 		}
+		return false, nil
 	}
 
-	if err := sc.synchronizer.Publish(ctx, event); err != nil {
-		if sc.options.OnError != nil {
-			sc.options.OnError(err)
+	if err := sc.limitWrite(OpSet, key); err != nil {
+		return false, err
+	}
+	// Added before the write so that readers never skip a key being written
+	sc.bloomAdd(key)
+
+	// Set in Redis, together with the event when both can be written atomically
+	var published bool
+	var err error
+	if sc.options.ConsistencyMode == ConsistencyOutbox && cfg.ttl <= 0 && !cfg.noPropagate {
+		published, err = sc.storeAndPublishAtomic(ctx, key, data, cfg)
+	}
+	if !published && err == nil {
+		if store, ok := sc.store.(ExpiringStore); ok && cfg.ttl > 0 {
+			err = store.SetWithTTL(ctx, key, data, cfg.ttl)
+		} else {
+			err = sc.store.Set(ctx, key, data)
+		}
+	}
+	sc.breaker.record(err)
+	if err != nil {
+		sc.reportError(OpSet, key, ErrRemoteStore, err)
+		if sc.logging(DebugOps) {
+			sc.logger.Error("Set: failed to store in remote cache", "key", key, "error", err)
 		}
-		if sc.options.DebugMode {
+		return false, categorize(ErrRemoteStore, err)
+	}
+	// Grouped after the write, as writing a key removes it from its previous group
+	if err := sc.addToGroup(ctx, key, cfg.group, cfg.ttl); err != nil {
+		return published, err
+	}
+
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("Set: stored in remote cache", "key", key)
+	}
+	return published, nil
+}
+
+// publishSet publishes the synchronization event for a stored value: the value
+// itself, or an invalidation when cfg.invalidateOnly is set. Failures are reported
+// via OnError but do not fail the Set.
+func (sc *SyncedCache) publishSet(ctx context.Context, key string, data []byte, cfg setConfig) {
+	event := sc.setEvent(key, data, cfg)
+	if !sc.allowPublish(event) {
+		return
+	}
+	err := sc.publish(ctx, event)
+	sc.breaker.record(err)
+	sc.trackPublish(event, err)
+	cfg.published.record(event, err)
+	if err != nil {
+		sc.reportError(OpSet, key, ErrPublish, err)
+		if sc.logging(DebugSync) {
 			sc.logger.Warn("Set: failed to publish synchronization event", "key", key, "action", event.Action, "error", err)
 		}
-	} else if sc.options.DebugMode {
+	} else if sc.debugging(DebugSync) {
 		sc.logger.Debug("Set: published synchronization event", "key", key, "action", event.Action)
 	}
+}
 
-	return nil
+// setEvent returns the synchronization event for a stored value. Values larger
+// than Options.MaxPropagationSize are downgraded to an invalidation.
+func (sc *SyncedCache) setEvent(key string, data []byte, cfg setConfig) InvalidationEvent {
+	if !cfg.invalidateOnly && sc.options.MaxPropagationSize > 0 && len(data) > sc.options.MaxPropagationSize {
+		atomic.AddInt64(&sc.stats.OversizedPropagations, 1)
+		if sc.debugging(DebugSync) {
+			sc.logger.Debug("Set: value exceeds MaxPropagationSize, propagating an invalidation", "key", key, "size", len(data))
+		}
+		cfg.invalidateOnly = true
+	}
+	if cfg.invalidateOnly {
+		// Invalidate-only mode: other pods will delete the key from local cache
+		return sc.stamp(InvalidationEvent{
+			Key:       key,
+			Sender:    sc.options.PodID,
+			Action:    ActionInvalidate,
+			DependsOn: cfg.dependsOn,
+		})
+	}
+	// Propagation mode: other pods will update their local cache with the value
+	return sc.stamp(InvalidationEvent{
+		Key:       key,
+		Sender:    sc.options.PodID,
+		Action:    ActionSet,
+		Value:     data,
+		TTL:       cfg.ttl,
+		Tags:      cfg.tags,
+		DependsOn: cfg.dependsOn,
+	})
 }
 
 // Delete removes a value from the cache.
 func (sc *SyncedCache) Delete(ctx context.Context, key string) error {
-	if atomic.LoadInt32(&sc.closed) != 0 {
+	if sc.options.ReadOnly {
+		return ErrReadOnly
+	}
+	if !sc.beginWrite() {
 		return ErrCacheClosed
 	}
+	defer sc.writes.end()
 
-	if sc.options.DebugMode {
+	if sc.debugging(DebugOps) {
 		sc.logger.Debug("Delete: removing key", "key", key)
 	}
 
+	if !sc.breaker.allow() {
+		return sc.degradedDelete(key)
+	}
+
 	// Delete from local cache
 	sc.local.Delete(key)
-	if sc.options.DebugMode {
+	sc.entryInfos.remove(key)
+	sc.forgetStale(key)
+	sc.changes.emit(ChangeEvent{Type: ChangeDeleted, Key: key})
+	if sc.debugging(DebugOps) {
 		sc.logger.Debug("Delete: removed from local cache", "key", key)
 	}
+	sc.dependencyWritten(ctx, key, nil)
 
-	// Delete from Redis
-	if err := sc.store.Delete(ctx, key); err != nil {
-		if sc.options.OnError != nil {
-			sc.options.OnError(err)
+	// Delete from Redis, unless the key is owned by an external writer
+	if sc.externalFormatFor(key) != nil {
+		if sc.debugging(DebugOps) {
+			sc.logger.Debug("Delete: skipping Redis delete for key owned by an external writer", "key", key)
 		}
-		if sc.options.DebugMode {
-			sc.logger.Error("Delete: failed to remove from remote cache", "key", key, "error", err)
+	} else if sc.options.DisableRemoteStore {
+		if sc.debugging(DebugOps) {
+			sc.logger.Debug("Delete: skipping Redis delete (DisableRemoteStore)", "key", key)
 		}
+	} else if err := sc.limitWrite(OpDelete, key); err != nil {
 		return err
+	} else if err := sc.store.Delete(ctx, key); err != nil {
+		sc.breaker.record(err)
+		sc.reportError(OpDelete, key, ErrRemoteStore, err)
+		if sc.logging(DebugOps) {
+			sc.logger.Error("Delete: failed to remove from remote cache", "key", key, "error", err)
+		}
+		return categorize(ErrRemoteStore, err)
 	}
 
-	if sc.options.DebugMode {
+	if sc.debugging(DebugOps) {
 		sc.logger.Debug("Delete: removed from remote cache", "key", key)
 	}
 
-	// Publish delete event
-	event := InvalidationEvent{
+	sc.publishDelete(ctx, key)
+	return nil
+}
+
+// publishDelete publishes the delete event of key. Failures are reported via
+// OnError but do not fail the Delete.
+func (sc *SyncedCache) publishDelete(ctx context.Context, key string) {
+	event := sc.stamp(InvalidationEvent{
 		Key:    key,
 		Sender: sc.options.PodID,
 		Action: ActionDelete,
+	})
+	if !sc.allowPublish(event) {
+		return
 	}
-	if err := sc.synchronizer.Publish(ctx, event); err != nil {
-		if sc.options.OnError != nil {
-			sc.options.OnError(err)
-		}
-		if sc.options.DebugMode {
+	err := sc.publish(ctx, event)
+	sc.breaker.record(err)
+	sc.trackPublish(event, err)
+	if err != nil {
+		sc.reportError(OpDelete, key, ErrPublish, err)
+		if sc.logging(DebugSync) {
 			sc.logger.Warn("Delete: failed to publish delete event", "key", key, "error", err)
 		}
-	} else if sc.options.DebugMode {
+	} else if sc.debugging(DebugSync) {
 		sc.logger.Debug("Delete: published delete event", "key", key)
 	}
-
-	return nil
 }
 
 // Clear removes all values from the cache.
 func (sc *SyncedCache) Clear(ctx context.Context) error {
-	if atomic.LoadInt32(&sc.closed) != 0 {
+	if sc.options.ReadOnly {
+		return ErrReadOnly
+	}
+	if !sc.beginWrite() {
 		return ErrCacheClosed
 	}
+	defer sc.writes.end()
 
-	if sc.options.DebugMode {
+	if sc.debugging(DebugOps) {
 		sc.logger.Debug("Clear: clearing all cache entries")
 	}
 
 	// Clear local cache
 	sc.local.Clear()
-	if sc.options.DebugMode {
+	sc.entryInfos.clear()
+	sc.clearStale()
+	sc.deps.clear()
+	sc.changes.emit(ChangeEvent{Type: ChangeCleared})
+	if sc.debugging(DebugOps) {
 		sc.logger.Debug("Clear: cleared local cache")
 	}
 
 	// Clear Redis
-	if err := sc.store.Clear(ctx); err != nil {
-		if sc.options.OnError != nil {
-			sc.options.OnError(err)
-		}
-		if sc.options.DebugMode {
+	if err := sc.clearRemote(ctx); err != nil {
+		sc.reportError(OpClear, "", ErrRemoteStore, err)
+		if sc.logging(DebugOps) {
 			sc.logger.Error("Clear: failed to clear remote cache", "error", err)
 		}
-		return err
+		return categorize(ErrRemoteStore, err)
 	}
 
-	if sc.options.DebugMode {
+	if sc.debugging(DebugOps) {
 		sc.logger.Debug("Clear: cleared remote cache")
 	}
 
 	// Publish clear event
-	event := InvalidationEvent{
+	event := sc.stamp(InvalidationEvent{
 		Key:    "*",
 		Sender: sc.options.PodID,
 		Action: ActionClear,
-	}
-	if err := sc.synchronizer.Publish(ctx, event); err != nil {
-		if sc.options.OnError != nil {
-			sc.options.OnError(err)
-		}
-		if sc.options.DebugMode {
+	})
+	err := sc.publish(ctx, event)
+	sc.trackPublish(event, err)
+	if err != nil {
+		sc.reportError(OpClear, "", ErrPublish, err)
+		if sc.logging(DebugSync) {
 			sc.logger.Warn("Clear: failed to publish clear event", "error", err)
 		}
-	} else if sc.options.DebugMode {
+	} else if sc.debugging(DebugSync) {
 		sc.logger.Debug("Clear: published clear event")
 	}
 
+	var details map[string]string
+	if sc.options.DangerousFullFlush {
+		details = map[string]string{"full_flush": "true"}
+	}
+	_ = sc.announce(ctx, LifecycleCleared, details)
+
 	return nil
 }
 
-// Close closes the cache and releases all resources.
-func (sc *SyncedCache) Close() error {
-	if !atomic.CompareAndSwapInt32(&sc.closed, 0, 1) {
+// clearRemote clears the remote store. It only removes the keys of this cache's
+// namespace unless DangerousFullFlush is set, in which case the whole database is flushed.
+func (sc *SyncedCache) clearRemote(ctx context.Context) error {
+	if sc.options.DisableRemoteStore {
 		return nil
 	}
+	if !sc.options.DangerousFullFlush {
+		return sc.store.Clear(ctx)
+	}
 
-	var errs []error
-
-	if err := sc.synchronizer.Close(); err != nil {
-		errs = append(errs, err)
+	// Always logged: this wipes data of every application sharing the database
+	sc.logger.Warn("Clear: flushing the entire Redis database (DangerousFullFlush)", "redis_db", sc.options.RedisDB)
+	if flusher, ok := sc.store.(FlushableStore); ok {
+		return flusher.FlushDB(ctx)
 	}
+	return sc.store.Clear(ctx)
+}
 
-	if err := sc.store.Close(); err != nil {
-		errs = append(errs, err)
+// Close closes the cache and releases all resources. It is Shutdown bounded by
+// ContextTimeout, so in-flight writes get a chance to publish their events.
+func (sc *SyncedCache) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), sc.options.ContextTimeout)
+	defer cancel()
+	return sc.Shutdown(ctx)
+}
+
+// goBackground runs fn in a goroutine tied to the cache lifetime.
+// The context passed to fn is cancelled on Close, which waits for fn to return.
+// It returns false without running fn when the cache is closing.
+func (sc *SyncedCache) goBackground(fn func(ctx context.Context)) bool {
+	sc.bgMutex.Lock()
+	if sc.bgStopped {
+		sc.bgMutex.Unlock()
+		return false
 	}
+	sc.bgWG.Add(1)
+	sc.bgMutex.Unlock()
+
+	go func() {
+		defer sc.bgWG.Done()
+		fn(sc.bgCtx)
+	}()
+	return true
+}
 
-	sc.local.Close()
+// handleInvalidation handles cache synchronization events.
+// Events are applied inline, or on the propagation worker pool when PropagationWorkers is set.
+func (sc *SyncedCache) handleInvalidation(event InvalidationEvent) {
+	now := time.Now()
+	atomic.StoreInt64(&sc.lastEvent, now.UnixNano())
+	if event.Time != 0 {
+		sc.lag.record(event.Time, now)
+	}
+	if sc.debugging(DebugSync) {
+		sc.logger.Debug("Received synchronization event", "action", event.Action, "key", event.Key, "sender", event.Sender)
+	}
+	if sc.filtered(event) {
+		return
+	}
 
-	if len(errs) > 0 {
-		return errs[0]
+	if sc.applyPool != nil {
+		sc.applyPool.dispatch(event)
+		return
 	}
+	received := sc.backlog.add()
+	sc.applyTimed(event)
+	sc.backlog.done(received)
+}
 
-	return nil
+// skipLocal reports whether Options.ShouldCacheLocally rejects key, counting it
+// in Stats.LocalSkips.
+func (sc *SyncedCache) skipLocal(key string) bool {
+	if sc.options.ShouldCacheLocally == nil || sc.options.ShouldCacheLocally(key) {
+		return false
+	}
+	atomic.AddInt64(&sc.stats.LocalSkips, 1)
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("Not caching key locally (ShouldCacheLocally)", "key", key)
+	}
+	return true
 }
 
-// Stats returns cache statistics.
-func (sc *SyncedCache) Stats() Stats {
-	sc.statsMutex.RLock()
-	defer sc.statsMutex.RUnlock()
-	return sc.stats
+// filtered reports whether Options.EventFilter drops event.
+func (sc *SyncedCache) filtered(event InvalidationEvent) bool {
+	if sc.options.EventFilter == nil {
+		return false
+	}
+	switch event.Action {
+	case ActionSet, ActionInvalidate, ActionDelete:
+	default:
+		return false
+	}
+	if sc.options.EventFilter(event) {
+		return false
+	}
+	atomic.AddInt64(&sc.stats.FilteredEvents, 1)
+	if sc.debugging(DebugSync) {
+		sc.logger.Debug("Sync: event dropped by EventFilter", "action", event.Action, "key", event.Key, "sender", event.Sender)
+	}
+	return true
 }
 
-// handleInvalidation handles cache synchronization events.
-func (sc *SyncedCache) handleInvalidation(event InvalidationEvent) {
-	if sc.options.DebugMode {
-		sc.logger.Info("Received synchronization event", "action", event.Action, "key", event.Key, "sender", event.Sender)
+// applyEvent applies a synchronization event to the local cache.
+func (sc *SyncedCache) applyEvent(event InvalidationEvent) {
+	if !sc.inOrder(event) {
+		return
 	}
 
 	switch event.Action {
 	case ActionSet:
+		// The key exists in Redis unless the sender only writes locally
+		sc.bloomAdd(event.Key)
+		if !event.Relay {
+			sc.deps.set(event.Key, event.DependsOn)
+			sc.invalidateDependentsLocal(event.Key)
+		}
 		// Propagate the value to local cache
-		if len(event.Value) > 0 {
+		if len(event.Value) > 0 && sc.skipLocal(event.Key) {
+			// Drop a value this pod may still hold from before the key moved away
+			sc.local.Delete(event.Key)
+			sc.entryInfos.remove(event.Key)
+		} else if len(event.Value) > 0 && !sc.skipRelay(event) {
 			var value any
 			if sc.options.OnSetLocalCache != nil {
 				// Use custom callback to process and transform the event data
 				value = sc.options.OnSetLocalCache(event)
-				if sc.options.DebugMode {
+				if sc.debugging(DebugSync) {
 					sc.logger.Debug("Sync: processed event via OnSetLocalCache callback", "key", event.Key, "sender", event.Sender)
 				}
 			} else {
 				// Default behavior: unmarshal before storing
-				if err := sc.serializer.Unmarshal(event.Value, &value); err != nil {
-					if sc.options.OnError != nil {
-						sc.options.OnError(err)
-					}
-					if sc.options.DebugMode {
+				var err error
+				if value, err = sc.decodeLocal(sc.serializer, event.Key, event.Value); err != nil {
+					sc.reportError(OpSync, event.Key, ErrDeserialization, err)
+					if sc.logging(DebugSerialization) {
 						sc.logger.Error("Sync: failed to deserialize value", "key", event.Key, "error", err)
 					}
 					return
 				}
-				if sc.options.DebugMode {
+				if sc.debugging(DebugSerialization) {
 					sc.logger.Debug("Sync: unmarshaled value for local cache", "key", event.Key, "sender", event.Sender)
 				}
 			}
 			// Store the processed/unmarshaled value in local cache
-			sc.local.Set(event.Key, value, 1)
-			if sc.options.DebugMode {
+			sc.setLocalWithTTL(event.Key, value, sc.cost(event.Key, value, event.Value), sc.entryInfos.window(event.TTL, 0), SourcePropagated)
+			sc.entryInfos.record(event.Key, SourcePropagated, event.Value)
+			sc.entryInfos.annotate(event.Key, event.TTL, 0, event.Tags)
+			sc.entryInfos.stamp(event.Key, event.Clock)
+			if sc.debugging(DebugSync) {
 				sc.logger.Debug("Sync: updated local cache", "key", event.Key, "sender", event.Sender)
 			}
 		}
 
 	case ActionInvalidate, ActionDelete:
-		// Remove from local cache
+		if event.Action == ActionInvalidate {
+			sc.bloomAdd(event.Key)
+		}
+		// Remove from local cache, keeping invalidated values for stale-while-revalidate
+		if event.Action == ActionInvalidate {
+			sc.markStale(event.Key)
+		} else {
+			sc.forgetStale(event.Key)
+		}
 		sc.local.Delete(event.Key)
+		sc.entryInfos.remove(event.Key)
+		sc.deps.set(event.Key, event.DependsOn)
+		sc.invalidateDependentsLocal(event.Key)
 		atomic.AddInt64(&sc.stats.Invalidations, 1)
-		if sc.options.DebugMode {
+		if event.Action == ActionInvalidate {
+			sc.changes.emit(ChangeEvent{Type: ChangeInvalidated, Key: event.Key, Remote: true})
+		} else {
+			sc.changes.emit(ChangeEvent{Type: ChangeDeleted, Key: event.Key, Remote: true})
+		}
+		if sc.debugging(DebugSync) {
 			sc.logger.Debug("Sync: deleted key from local cache", "key", event.Key, "action", event.Action, "sender", event.Sender)
 		}
 
 	case ActionClear:
 		// Clear entire local cache
 		sc.local.Clear()
+		sc.entryInfos.clear()
+		sc.clearStale()
+		sc.deps.clear()
 		atomic.AddInt64(&sc.stats.Invalidations, 1)
-		if sc.options.DebugMode {
+		sc.changes.emit(ChangeEvent{Type: ChangeCleared, Remote: true})
+		if sc.debugging(DebugSync) {
 			sc.logger.Debug("Sync: cleared local cache", "sender", event.Sender)
 		}
 
+	case ActionLifecycle:
+		sc.applyLifecycle(event)
+
+	case ActionSnapshotRequest:
+		sc.serveSnapshot(event)
+
+	case ActionSnapshotChunk:
+		sc.applySnapshotChunk(event)
+
+	case ActionDigest:
+		sc.applyDigest(event)
+
+	case ActionHeartbeat:
+		sc.applyHeartbeat(event)
+
+	case ActionAckRequest:
+		sc.serveAck(event)
+
+	case ActionAck:
+		sc.applyAck(event)
+
 	default:
-		if sc.options.DebugMode {
+		if sc.logging(DebugSync) {
 			sc.logger.Warn("Sync: unknown action", "action", event.Action, "key", event.Key, "sender", event.Sender)
 		}
 	}
 }
 
-// recordLocalHit records a local cache hit.
-func (sc *SyncedCache) recordLocalHit() {
+// setLocal stores a value in the local cache and applies Options.RejectedSetPolicy
+// when the local cache rejects or drops it. It reports whether the value was admitted.
+func (sc *SyncedCache) setLocal(key string, value any, cost int64, source HitSource) bool {
+	return sc.setLocalWithTTL(key, value, cost, sc.entryInfos.window(0, 0), source)
+}
+
+// setLocalWithTTL is setLocal for a value expiring from the local cache after ttl.
+// The entry metadata expires it either way; local caches implementing
+// ExpiringLocalCache also evict it themselves.
+func (sc *SyncedCache) setLocalWithTTL(key string, value any, cost int64, ttl time.Duration, source HitSource) bool {
+	sc.forgetStale(key)
+	admitted := sc.putLocal(key, value, cost, ttl)
+
+	admitting, ok := sc.local.(AdmittingLocalCache)
+	if ok && sc.options.RejectedSetPolicy != "" && sc.options.RejectedSetPolicy != RejectedSetLog {
+		if admitted {
+			admitting.Wait()
+			admitted = admitting.Contains(key)
+		}
+		if !admitted {
+			if sc.options.RejectedSetPolicy == RejectedSetForcePropagated && source == SourcePropagated {
+				admitted = admitting.ForceSet(key, value, cost)
+			} else if sc.putLocal(key, value, cost, ttl) {
+				admitting.Wait()
+				admitted = admitting.Contains(key)
+			}
+		}
+	}
+
+	if admitted {
+		sc.changes.emit(ChangeEvent{Type: ChangeSet, Key: key, Value: value, Source: source, Remote: source == SourcePropagated})
+	} else {
+		atomic.AddInt64(&sc.stats.RejectedSets, 1)
+		if sc.logging(DebugOps) {
+			sc.logger.Warn("Local cache rejected value", "key", key, "source", source, "cost", cost, "policy", sc.options.RejectedSetPolicy)
+		}
+	}
+	return admitted
+}
+
+// putLocal stores a value in the local cache, expiring after ttl when it is
+// positive and the local cache implements ExpiringLocalCache.
+func (sc *SyncedCache) putLocal(key string, value any, cost int64, ttl time.Duration) bool {
+	if expiring, ok := sc.local.(ExpiringLocalCache); ok && ttl > 0 {
+		// Outlive the TTL by the maximum staleness, so that getLocal can keep the
+		// expired value as stale
+		if sc.stale != nil {
+			ttl += sc.options.StaleWhileRevalidate
+		}
+		return expiring.SetWithTTL(key, value, cost, ttl)
+	}
+	return sc.local.Set(key, value, cost)
+}
+
+// cost returns the local cache cost of an entry using Options.CostFunc,
+// defaulting to the serialized size in bytes (at least 1).
+func (sc *SyncedCache) cost(key string, value any, serialized []byte) int64 {
+	if sc.options.CostFunc != nil {
+		return sc.options.CostFunc(key, value, serialized)
+	}
+	if len(serialized) == 0 {
+		return 1
+	}
+	return int64(len(serialized))
+}
+
+// recordLocalHit records a local cache hit served from an entry with the given source.
+func (sc *SyncedCache) recordLocalHit(source HitSource) {
 	atomic.AddInt64(&sc.stats.LocalHits, 1)
+	switch source {
+	case SourceSet:
+		atomic.AddInt64(&sc.stats.LocalHitsSet, 1)
+	case SourceRemote:
+		atomic.AddInt64(&sc.stats.LocalHitsRemote, 1)
+	case SourcePropagated:
+		atomic.AddInt64(&sc.stats.LocalHitsPropagated, 1)
+	case SourceLoader:
+		atomic.AddInt64(&sc.stats.LocalHitsLoader, 1)
+	}
 }
 
 // recordLocalMiss records a local cache miss.
@@ -472,3 +1290,19 @@ func (sc *SyncedCache) recordRemoteMiss() {
 
 // ErrCacheClosed is returned when operations are performed on a closed cache.
 var ErrCacheClosed = NewError("cache is closed")
+
+// ErrReadOnly is returned by Set, SetIfVersion, Delete and Clear when
+// Options.ReadOnly is set.
+var ErrReadOnly = NewError("cache is read-only")
+
+// ErrValueTooLarge is returned by Set when the serialized value is larger than
+// Options.MaxValueSize.
+var ErrValueTooLarge = NewError("value exceeds the maximum size")
+
+// ErrTimeout is reported via OnError when a Get gives up on the remote store
+// because its context deadline or RemoteGetTimeout expired.
+var ErrTimeout = NewError("cache operation timed out")
+
+// ErrSubscriptionNotReady is returned by New when the subscription is not confirmed
+// active within Options.SubscribeTimeout.
+var ErrSubscriptionNotReady = NewError("subscription not ready")
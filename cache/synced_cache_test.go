@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -92,6 +93,42 @@ func (es *errorSynchronizer) Close() error {
 	return nil
 }
 
+// newMockedCache creates a cache backed by a mock store and synchronizer.
+func newMockedCache(t *testing.T, opts Options) *SyncedCache {
+	t.Helper()
+
+	local, err := NewLRUCacheFactory(100).Create()
+	if err != nil {
+		t.Fatalf("Failed to create local cache: %v", err)
+	}
+	if opts.ContextTimeout == 0 {
+		opts.ContextTimeout = time.Second
+	}
+
+	sc := &SyncedCache{
+		local:        local,
+		store:        &errorStore{},
+		synchronizer: &errorSynchronizer{},
+		serializer:   NewJSONMarshaller(),
+		logger:       NewNoOpLogger(),
+		options:      opts,
+		entryInfos:   newEntryInfos(100, opts.LocalTTL),
+		backlog:      newEventBacklog(),
+		extFormats:   newPrefixFormats(opts.ExternalFormats),
+		propRules:    newPropagationRules(opts.PropagationRules),
+		codecs:       newKeyMarshallers(opts.MarshallerRules),
+	}
+	sc.bgCtx, sc.bgCancel = context.WithCancel(context.Background())
+	if opts.StaleWhileRevalidate > 0 {
+		sc.stale = newStaleEntries(100)
+	}
+	if opts.WriteBehind {
+		sc.writeQueue = make(chan pendingWrite, opts.WriteBehindQueueSize)
+		sc.goBackground(sc.runWriteBehind)
+	}
+	return sc
+}
+
 func TestNewSyncedCache(t *testing.T) {
 	opts := DefaultOptions()
 	opts.PodID = "test-pod"
@@ -246,6 +283,7 @@ func TestSyncedCacheDelete(t *testing.T) {
 func TestSyncedCacheClear(t *testing.T) {
 	opts := DefaultOptions()
 	opts.PodID = "test-pod"
+	opts.Namespace = "test:"
 	opts.RedisAddr = "localhost:6379"
 	opts.ReaderCanSetToRedis = true
 
@@ -1342,6 +1380,7 @@ func TestSyncedCacheDoubleClose(t *testing.T) {
 func TestSyncedCacheClearWithDebugMode(t *testing.T) {
 	opts := DefaultOptions()
 	opts.PodID = "test-pod-clear-debug"
+	opts.Namespace = "test:"
 	opts.RedisAddr = "localhost:6379"
 	opts.DebugMode = true
 	opts.Logger = NewConsoleLogger("test-clear")
@@ -1678,6 +1717,7 @@ func TestSyncedCacheDeleteWithConsoleLoggerSuccess(t *testing.T) {
 func TestSyncedCacheClearWithConsoleLoggerSuccess(t *testing.T) {
 	opts := DefaultOptions()
 	opts.PodID = "test-pod-clear-console"
+	opts.Namespace = "test:"
 	opts.RedisAddr = "localhost:6379"
 	opts.DebugMode = true
 	opts.Logger = NewConsoleLogger("test-clear")
@@ -2194,3 +2234,526 @@ func TestSyncedCacheSingleflightDifferentKeysNotDeduplicated(t *testing.T) {
 		t.Fatalf("Expected exactly 1 Redis Get for key2, got %d", countingStore.getCount("key2"))
 	}
 }
+
+// costRecordingCache wraps a LocalCache and records the cost passed to Set per key.
+type costRecordingCache struct {
+	LocalCache
+	costs map[string]int64
+	mu    sync.Mutex
+}
+
+func (cr *costRecordingCache) Set(key string, value any, cost int64) bool {
+	cr.mu.Lock()
+	cr.costs[key] = cost
+	cr.mu.Unlock()
+	return cr.LocalCache.Set(key, value, cost)
+}
+
+func (cr *costRecordingCache) costOf(key string) int64 {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	return cr.costs[key]
+}
+
+// TestSyncedCacheDefaultCostIsSerializedSize verifies that Set, remote population and
+// propagation use the serialized size as the local cache cost by default.
+func TestSyncedCacheDefaultCostIsSerializedSize(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PodID = "test-pod-default-cost"
+	opts.RedisAddr = "localhost:6379"
+	opts.ReaderCanSetToRedis = true
+
+	c, err := New(opts)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	lru, _ := NewLRUCache(100)
+	recorder := &costRecordingCache{LocalCache: lru, costs: make(map[string]int64)}
+	c.local = recorder
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Set
+	if err := c.Set(ctx, "test:cost:set", "12345"); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if cost := recorder.costOf("test:cost:set"); cost != int64(len(`"12345"`)) {
+		t.Fatalf("Expected Set cost %d, got %d", len(`"12345"`), cost)
+	}
+
+	// Remote population
+	c.local.Delete("test:cost:set")
+	if _, found := c.Get(ctx, "test:cost:set"); !found {
+		t.Fatal("Expected remote hit")
+	}
+	if cost := recorder.costOf("test:cost:set"); cost != int64(len(`"12345"`)) {
+		t.Fatalf("Expected remote population cost %d, got %d", len(`"12345"`), cost)
+	}
+
+	// Propagation
+	data, _ := json.Marshal("propagated-value")
+	c.handleInvalidation(InvalidationEvent{Key: "test:cost:event", Sender: "other-pod", Action: ActionSet, Value: data})
+	if cost := recorder.costOf("test:cost:event"); cost != int64(len(data)) {
+		t.Fatalf("Expected propagation cost %d, got %d", len(data), cost)
+	}
+}
+
+// TestSyncedCacheCustomCostFunc verifies that Options.CostFunc overrides the default cost.
+func TestSyncedCacheCustomCostFunc(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PodID = "test-pod-custom-cost"
+	opts.RedisAddr = "localhost:6379"
+	opts.CostFunc = func(key string, value any, serialized []byte) int64 {
+		return 42
+	}
+
+	c, err := New(opts)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer c.Close()
+
+	lru, _ := NewLRUCache(100)
+	recorder := &costRecordingCache{LocalCache: lru, costs: make(map[string]int64)}
+	c.local = recorder
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := c.Set(ctx, "test:cost:custom", "value"); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if cost := recorder.costOf("test:cost:custom"); cost != 42 {
+		t.Fatalf("Expected custom cost 42, got %d", cost)
+	}
+
+	data, _ := json.Marshal("propagated")
+	c.handleInvalidation(InvalidationEvent{Key: "test:cost:custom:event", Sender: "other-pod", Action: ActionSet, Value: data})
+	if cost := recorder.costOf("test:cost:custom:event"); cost != 42 {
+		t.Fatalf("Expected custom propagation cost 42, got %d", cost)
+	}
+}
+
+// readySynchronizer is a synchronizer whose WaitReady returns a fixed error.
+type readySynchronizer struct {
+	errorSynchronizer
+	readyError error
+	waited     bool
+}
+
+func (rs *readySynchronizer) WaitReady(ctx context.Context) error {
+	rs.waited = true
+	return rs.readyError
+}
+
+func TestSyncedCacheWaitForSubscription(t *testing.T) {
+	synchronizer := &readySynchronizer{}
+	sc := &SyncedCache{synchronizer: synchronizer, logger: NewNoOpLogger()}
+
+	if err := sc.waitForSubscription(); err != nil || synchronizer.waited {
+		t.Fatalf("Expected no wait without SubscribeTimeout, got err=%v waited=%v", err, synchronizer.waited)
+	}
+
+	sc.options.SubscribeTimeout = time.Second
+	if err := sc.waitForSubscription(); err != nil || !synchronizer.waited {
+		t.Fatalf("Expected successful wait, got err=%v waited=%v", err, synchronizer.waited)
+	}
+}
+
+func TestSyncedCacheWaitForSubscriptionTimeout(t *testing.T) {
+	var reported error
+	sc := &SyncedCache{
+		synchronizer: &readySynchronizer{readyError: context.DeadlineExceeded},
+		logger:       NewNoOpLogger(),
+		options: Options{
+			SubscribeTimeout: time.Second,
+			OnError:          func(err error) { reported = err },
+		},
+	}
+
+	if err := sc.waitForSubscription(); err != ErrSubscriptionNotReady {
+		t.Fatalf("Expected ErrSubscriptionNotReady, got %v", err)
+	}
+	if reported != context.DeadlineExceeded {
+		t.Fatalf("Expected OnError to receive the wait error, got %v", reported)
+	}
+}
+
+func TestNewSyncedCacheWithSubscribeTimeout(t *testing.T) {
+	opts := DefaultOptions()
+	opts.PodID = "test-pod-subscribe"
+	opts.RedisAddr = "localhost:6379"
+	opts.SubscribeTimeout = 2 * time.Second
+
+	c, err := New(opts)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+	defer c.Close()
+}
+
+// blockingStore is a store whose Get blocks until its context is done.
+type blockingStore struct {
+	errorStore
+}
+
+func (bs *blockingStore) Get(ctx context.Context, key string) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSyncedCacheGetRemoteGetTimeout(t *testing.T) {
+	reported := make(chan error, 1)
+	c := newMockedCache(t, Options{
+		RemoteGetTimeout: 20 * time.Millisecond,
+		OnError:          func(err error) { reported <- err },
+	})
+	c.store = &blockingStore{}
+	defer c.Close()
+
+	start := time.Now()
+	if _, found := c.Get(context.Background(), "test:timeout"); found {
+		t.Fatal("Expected miss when the remote store times out")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Get should give up after RemoteGetTimeout, took %v", elapsed)
+	}
+	if err := <-reported; err != ErrTimeout {
+		t.Fatalf("Expected ErrTimeout, got %v", err)
+	}
+	if stats := c.Stats(); stats.RemoteMisses != 0 {
+		t.Fatalf("Timeouts should not be counted as remote misses, got %d", stats.RemoteMisses)
+	}
+}
+
+func TestSyncedCacheGetHonorsCallerDeadline(t *testing.T) {
+	reported := make(chan error, 2)
+	c := newMockedCache(t, Options{
+		RemoteGetTimeout: time.Second,
+		OnError:          func(err error) { reported <- err },
+	})
+	c.store = &blockingStore{}
+	defer c.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, found := c.Get(ctx, "test:deadline"); found {
+		t.Fatal("Expected miss when the caller deadline expires")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Get should return at the caller deadline, took %v", elapsed)
+	}
+	if err := <-reported; err != ErrTimeout {
+		t.Fatalf("Expected ErrTimeout, got %v", err)
+	}
+}
+
+func TestSyncedCacheGetCancelledContextNotReported(t *testing.T) {
+	var reported error
+	c := newMockedCache(t, Options{OnError: func(err error) { reported = err }})
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, found := c.Get(ctx, "test:cancelled"); found {
+		t.Fatal("Expected miss with a cancelled context")
+	}
+	if reported != nil {
+		t.Fatalf("Cancellation should not be reported via OnError, got %v", reported)
+	}
+}
+
+// rejectingCache is an admitting local cache that silently drops the first rejections Sets.
+type rejectingCache struct {
+	LocalCache
+	rejections int
+	sets       int
+	forced     int
+}
+
+func (rc *rejectingCache) Set(key string, value any, cost int64) bool {
+	rc.sets++
+	if rc.sets <= rc.rejections {
+		return true // accepted into the buffer, then dropped by the policy
+	}
+	return rc.LocalCache.Set(key, value, cost)
+}
+
+func (rc *rejectingCache) Wait() {}
+
+func (rc *rejectingCache) Contains(key string) bool {
+	_, found := rc.LocalCache.Get(key)
+	return found
+}
+
+func (rc *rejectingCache) ForceSet(key string, value any, cost int64) bool {
+	rc.forced++
+	return rc.LocalCache.Set(key, value, cost)
+}
+
+func newRejectingCache(t *testing.T, rejections int) *rejectingCache {
+	t.Helper()
+	local, err := NewLRUCacheFactory(100).Create()
+	if err != nil {
+		t.Fatalf("Failed to create local cache: %v", err)
+	}
+	return &rejectingCache{LocalCache: local, rejections: rejections}
+}
+
+func TestSyncedCacheRejectedSetRetry(t *testing.T) {
+	c := newMockedCache(t, Options{RejectedSetPolicy: RejectedSetRetry})
+	local := newRejectingCache(t, 1)
+	c.local = local
+	defer c.Close()
+
+	if !c.setLocal("test:rejected", "value", 1, SourceSet) {
+		t.Fatal("Expected the retried Set to be admitted")
+	}
+	if local.sets != 2 {
+		t.Fatalf("Expected 2 Sets, got %d", local.sets)
+	}
+	if stats := c.Stats(); stats.RejectedSets != 0 {
+		t.Fatalf("Expected no rejected sets, got %d", stats.RejectedSets)
+	}
+
+	local.rejections = local.sets + 2
+	if c.setLocal("test:rejected:twice", "value", 1, SourceSet) {
+		t.Fatal("Expected a value rejected twice not to be admitted")
+	}
+	if stats := c.Stats(); stats.RejectedSets != 1 {
+		t.Fatalf("Expected 1 rejected set, got %d", stats.RejectedSets)
+	}
+}
+
+func TestSyncedCacheRejectedSetForcePropagated(t *testing.T) {
+	c := newMockedCache(t, Options{RejectedSetPolicy: RejectedSetForcePropagated})
+	local := newRejectingCache(t, 1)
+	c.local = local
+	defer c.Close()
+
+	if !c.setLocal("test:propagated", "value", 1, SourcePropagated) {
+		t.Fatal("Expected the propagated value to be force-admitted")
+	}
+	if local.forced != 1 {
+		t.Fatalf("Expected ForceSet to be used for propagated values, got %d calls", local.forced)
+	}
+
+	local.rejections = local.sets + 1
+	if !c.setLocal("test:set", "value", 1, SourceSet) || local.forced != 1 {
+		t.Fatalf("Expected non-propagated values to be retried without ForceSet, got %d calls", local.forced)
+	}
+}
+
+func TestSyncedCacheRejectedSetLogOnlyCountsDrops(t *testing.T) {
+	c := newMockedCache(t, Options{RejectedSetPolicy: RejectedSetLog})
+	local := newRejectingCache(t, 1)
+	c.local = local
+	defer c.Close()
+
+	// Asynchronous drops are not detected without verification
+	if !c.setLocal("test:log", "value", 1, SourceSet) || local.sets != 1 {
+		t.Fatalf("Expected a single unverified Set, got %d", local.sets)
+	}
+}
+
+// flushingStore is a store recording whether Clear or FlushDB was used.
+type flushingStore struct {
+	errorStore
+	cleared bool
+	flushed bool
+}
+
+func (fs *flushingStore) Clear(ctx context.Context) error {
+	fs.cleared = true
+	return nil
+}
+
+func (fs *flushingStore) FlushDB(ctx context.Context) error {
+	fs.flushed = true
+	return nil
+}
+
+func TestSyncedCacheClearIsNamespaceScopedByDefault(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	store := &flushingStore{}
+	c.store = store
+	defer c.Close()
+
+	if err := c.Clear(context.Background()); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if !store.cleared || store.flushed {
+		t.Fatalf("Expected scoped Clear without FlushDB, got cleared=%v flushed=%v", store.cleared, store.flushed)
+	}
+}
+
+func TestSyncedCacheClearDangerousFullFlush(t *testing.T) {
+	c := newMockedCache(t, Options{DangerousFullFlush: true})
+	store := &flushingStore{}
+	c.store = store
+	defer c.Close()
+
+	if err := c.Clear(context.Background()); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if !store.flushed || store.cleared {
+		t.Fatalf("Expected FlushDB with DangerousFullFlush, got cleared=%v flushed=%v", store.cleared, store.flushed)
+	}
+}
+
+func TestMaxPropagationSize(t *testing.T) {
+	sc := newMockedCache(t, Options{MaxPropagationSize: 16})
+	defer sc.Close()
+	publisher := &publishingSynchronizer{}
+	sc.synchronizer = publisher
+	ctx := context.Background()
+
+	if err := sc.Set(ctx, "small", "tiny"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := sc.Set(ctx, "large", strings.Repeat("x", 64)); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if len(publisher.events) != 2 {
+		t.Fatalf("Expected 2 published events, got %d", len(publisher.events))
+	}
+	if small := publisher.events[0]; small.Action != ActionSet || len(small.Value) == 0 {
+		t.Errorf("Expected the small value to be propagated, got %+v", small)
+	}
+	if large := publisher.events[1]; large.Action != ActionInvalidate || large.Value != nil {
+		t.Errorf("Expected the large value to be propagated as an invalidation, got %+v", large)
+	}
+	if _, found := sc.Get(ctx, "large"); !found {
+		t.Error("Expected the large value to stay in the local cache")
+	}
+	if got := sc.Stats().OversizedPropagations; got != 1 {
+		t.Errorf("Expected 1 oversized propagation, got %d", got)
+	}
+}
+
+func TestMaxValueSize(t *testing.T) {
+	sc := newMockedCache(t, Options{MaxValueSize: 16})
+	defer sc.Close()
+	publisher := &publishingSynchronizer{}
+	sc.synchronizer = publisher
+	var reported error
+	sc.options.OnError = func(err error) { reported = err }
+	ctx := context.Background()
+
+	if err := sc.Set(ctx, "small", "tiny"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := sc.Set(ctx, "large", strings.Repeat("x", 64)); !errors.Is(err, ErrValueTooLarge) {
+		t.Fatalf("Expected ErrValueTooLarge, got %v", err)
+	}
+	if !errors.Is(reported, ErrValueTooLarge) {
+		t.Errorf("Expected ErrValueTooLarge to be reported via OnError, got %v", reported)
+	}
+
+	if len(publisher.events) != 1 || publisher.events[0].Key != "small" {
+		t.Fatalf("Expected only the small value to be published, got %+v", publisher.events)
+	}
+	if _, found := sc.Get(ctx, "large"); found {
+		t.Error("Expected the large value not to be cached")
+	}
+	if got := sc.Stats().OversizedValues; got != 1 {
+		t.Errorf("Expected 1 oversized value, got %d", got)
+	}
+}
+
+func TestSyncedCacheReadOnly(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "reader", ReadOnly: true})
+	defer c.Close()
+	synchronizer := &publishingSynchronizer{}
+	c.synchronizer = synchronizer
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "key", "value"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected Set to fail with ErrReadOnly, got %v", err)
+	}
+	if _, err := c.SetIfVersion(ctx, "key", "value", 0); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected SetIfVersion to fail with ErrReadOnly, got %v", err)
+	}
+	if err := c.Delete(ctx, "key"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected Delete to fail with ErrReadOnly, got %v", err)
+	}
+	if err := c.Clear(ctx); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected Clear to fail with ErrReadOnly, got %v", err)
+	}
+	if _, found := c.local.Get("key"); found || len(synchronizer.events) != 0 {
+		t.Fatalf("Expected no local change nor event, got %d events", len(synchronizer.events))
+	}
+
+	data, _ := c.serializer.Marshal("propagated")
+	c.applyEvent(InvalidationEvent{Key: "key", Value: data, Sender: "writer", Action: ActionSet})
+	if value, found := c.Get(ctx, "key"); !found || value != "propagated" {
+		t.Errorf("Expected events from other pods to be applied, got %v", value)
+	}
+}
+
+func TestSyncedCacheEventFilter(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1", EventFilter: func(event InvalidationEvent) bool {
+		return strings.HasPrefix(event.Key, "shard-a:")
+	}})
+	defer c.Close()
+	c.local.Set("shard-b:1", "kept", 1)
+
+	c.handleInvalidation(InvalidationEvent{Key: "shard-a:1", Sender: "pod-2", Action: ActionSet, Value: []byte(`"a"`)})
+	c.handleInvalidation(InvalidationEvent{Key: "shard-b:2", Sender: "pod-2", Action: ActionSet, Value: []byte(`"b"`)})
+	c.handleInvalidation(InvalidationEvent{Key: "shard-b:1", Sender: "pod-2", Action: ActionDelete})
+
+	if _, found := c.local.Get("shard-a:1"); !found {
+		t.Error("Expected the event of a matching key to be applied")
+	}
+	if _, found := c.local.Get("shard-b:2"); found {
+		t.Error("Expected the set of a filtered key to be dropped")
+	}
+	if _, found := c.local.Get("shard-b:1"); !found {
+		t.Error("Expected the delete of a filtered key to be dropped")
+	}
+	if stats := c.Stats(); stats.FilteredEvents != 2 {
+		t.Errorf("Expected 2 filtered events, got %d", stats.FilteredEvents)
+	}
+
+	c.handleInvalidation(InvalidationEvent{Key: "*", Sender: "pod-2", Action: ActionClear})
+	if _, found := c.local.Get("shard-a:1"); found {
+		t.Error("Expected clear events to bypass the filter")
+	}
+}
+
+func TestSyncedCacheShouldCacheLocally(t *testing.T) {
+	c := newMockedCache(t, Options{PodID: "pod-1", ShouldCacheLocally: func(key string) bool {
+		return strings.HasPrefix(key, "mine:")
+	}})
+	defer c.Close()
+	c.store = &valueStore{values: map[string][]byte{"mine:1": []byte(`"a"`), "other:1": []byte(`"b"`)}}
+	ctx := context.Background()
+
+	for _, key := range []string{"mine:1", "other:1"} {
+		if _, found := c.Get(ctx, key); !found {
+			t.Fatalf("Expected %s to be served from Redis", key)
+		}
+	}
+	if _, found := c.local.Get("mine:1"); !found {
+		t.Error("Expected a key of this pod to be cached locally")
+	}
+	if _, found := c.local.Get("other:1"); found {
+		t.Error("Expected a key of another pod not to be cached locally")
+	}
+
+	c.local.Set("other:2", "old", 1)
+	c.applyEvent(InvalidationEvent{Key: "other:2", Sender: "pod-2", Action: ActionSet, Value: []byte(`"new"`)})
+	if _, found := c.local.Get("other:2"); found {
+		t.Error("Expected a propagated value of another pod's key to be dropped")
+	}
+	if stats := c.Stats(); stats.LocalSkips != 2 {
+		t.Errorf("Expected 2 local skips, got %d", stats.LocalSkips)
+	}
+}
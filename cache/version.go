@@ -0,0 +1,5 @@
+package cache
+
+// Version is the version of the distributed-cache library, announced to other
+// pods in heartbeats, see Peers.
+const Version = "v1.0.4"
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// SetIfVersion stores a value only if its version in the remote store equals
+// expectedVersion, giving optimistic concurrency for read-modify-write updates:
+// read the value and its Version, compute the update, then SetIfVersion with the
+// version read, retrying on ErrVersionConflict.
+//
+// Versions are kept by the remote store next to the value; 0 means the key was
+// never written with SetIfVersion. Once a key has a version, every write
+// increments it, Set included, so a version read before a Set no longer matches.
+// Versions are deleted and expire together with the value. On success the new version is returned,
+// the value is persisted through Options.Writer before Redis, cached locally and
+// propagated to other pods. On conflict the current version is returned with
+// ErrVersionConflict and nothing is stored.
+// The write is atomic in Redis regardless of WritePolicy; under ConsistencyOutbox
+// the event is published after it, as the version check cannot join the
+// transaction.
+func (sc *SyncedCache) SetIfVersion(ctx context.Context, key string, value any, expectedVersion uint64) (uint64, error) {
+	if sc.options.ReadOnly {
+		return 0, ErrReadOnly
+	}
+	return sc.setIfVersion(ctx, key, value, sc.setConfig(key, []SetOption{WithVersion(expectedVersion)}))
+}
+
+// setIfVersion is the implementation of SetIfVersion and Set with WithVersion.
+// It goes through the checks of Set: ReadOnly, the circuit breaker,
+// WriteRateLimit and Options.Writer, which persists the value before Redis.
+// The local cache is written after Redis, whose outcome it depends on, and
+// before or after the event following ConsistencyMode.
+func (sc *SyncedCache) setIfVersion(ctx context.Context, key string, value any, cfg setConfig) (uint64, error) {
+	if sc.options.ReadOnly {
+		return 0, ErrReadOnly
+	}
+	if !sc.beginWrite() {
+		return 0, ErrCacheClosed
+	}
+	defer sc.writes.end()
+	store, ok := sc.store.(VersionedStore)
+	if !ok || sc.options.DisableRemoteStore {
+		return 0, ErrVersioningNotSupported
+	}
+	if sc.externalFormatFor(key) != nil {
+		return 0, ErrExternalKey
+	}
+	if cfg.ttl > 0 {
+		return 0, ErrTTLNotSupported
+	}
+	if _, ok := sc.store.(GroupStore); cfg.group != "" && !ok {
+		return 0, ErrGroupsNotSupported
+	}
+
+	data, err := sc.encode(key, value)
+	if err != nil {
+		sc.reportError(OpSet, key, ErrSerialization, err)
+		if sc.logging(DebugSerialization) {
+			sc.logger.Error("SetIfVersion: serialization failed", "key", key, "error", err)
+		}
+		return 0, categorize(ErrSerialization, err)
+	}
+	if err := sc.checkValueSize(key, data); err != nil {
+		return 0, err
+	}
+
+	// The version check needs Redis: nothing is queued while it is unavailable
+	if !sc.breaker.allow() {
+		return 0, ErrCircuitOpen
+	}
+	if err := sc.limitWrite(OpSet, key); err != nil {
+		return 0, err
+	}
+
+	if sc.options.Writer != nil {
+		// Reject conflicts before persisting, so the backing database only receives
+		// values that pass the version check unless a concurrent write wins meanwhile
+		current, err := store.Version(ctx, key)
+		sc.breaker.record(err)
+		if err != nil {
+			return 0, sc.versionedStoreError(key, err)
+		}
+		if current != cfg.version {
+			return current, ErrVersionConflict
+		}
+		if err := sc.persist(ctx, key, value); err != nil {
+			if sc.logging(DebugOps) {
+				sc.logger.Error("SetIfVersion: failed to persist value", "key", key, "error", err)
+			}
+			return 0, err
+		}
+	}
+
+	sc.bloomAdd(key)
+	version, stored, err := store.SetIfVersion(ctx, key, data, cfg.version)
+	sc.breaker.record(err)
+	if err != nil {
+		return 0, sc.versionedStoreError(key, err)
+	}
+	if !stored {
+		if sc.debugging(DebugOps) {
+			sc.logger.Debug("SetIfVersion: version conflict", "key", key, "expected", cfg.version, "current", version)
+		}
+		return version, ErrVersionConflict
+	}
+	if err := sc.addToGroup(ctx, key, cfg.group, cfg.ttl); err != nil {
+		return 0, err
+	}
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("SetIfVersion: stored value", "key", key, "version", version)
+	}
+
+	localFirst := sc.options.ConsistencyMode == ConsistencyLocalFirst
+	if localFirst {
+		sc.storeLocal(key, value, data, SourceSet, cfg)
+	}
+	if !cfg.noPropagate {
+		sc.publishSet(ctx, key, data, cfg)
+	}
+	if !localFirst {
+		sc.storeLocal(key, value, data, SourceSet, cfg)
+	}
+	sc.dependencyWritten(ctx, key, cfg.dependsOn)
+	return version, nil
+}
+
+// versionedStoreError reports a failed versioned read or write of key and
+// returns it categorized as ErrRemoteStore.
+func (sc *SyncedCache) versionedStoreError(key string, err error) error {
+	sc.reportError(OpSet, key, ErrRemoteStore, err)
+	if sc.logging(DebugOps) {
+		sc.logger.Error("SetIfVersion: failed to store in remote cache", "key", key, "error", err)
+	}
+	return categorize(ErrRemoteStore, err)
+}
+
+// Version returns the version of key in the remote store, as used by SetIfVersion.
+// It is 0 for keys never written with SetIfVersion.
+func (sc *SyncedCache) Version(ctx context.Context, key string) (uint64, error) {
+	if atomic.LoadInt32(&sc.closed) != 0 {
+		return 0, ErrCacheClosed
+	}
+	store, ok := sc.store.(VersionedStore)
+	if !ok || sc.options.DisableRemoteStore {
+		return 0, ErrVersioningNotSupported
+	}
+	version, err := store.Version(ctx, key)
+	if err != nil {
+		return 0, categorize(ErrRemoteStore, err)
+	}
+	return version, nil
+}
+
+// ErrVersionConflict is returned by SetIfVersion when the stored version does not
+// match the expected version.
+var ErrVersionConflict = NewError("version conflict")
+
+// ErrVersioningNotSupported is returned by SetIfVersion and Version when the store
+// does not implement VersionedStore.
+var ErrVersioningNotSupported = NewError("store does not support versioned writes")
+
+// ErrExternalKey is returned by SetIfVersion for keys matching Options.ExternalFormats,
+// which are never written to Redis by this cache.
+var ErrExternalKey = NewError("key is owned by an external writer")
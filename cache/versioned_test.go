@@ -0,0 +1,183 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// versionedStore is a store keeping values and versions in memory.
+type versionedStore struct {
+	errorStore
+	mu       sync.Mutex
+	values   map[string][]byte
+	versions map[string]uint64
+}
+
+func newVersionedStore() *versionedStore {
+	return &versionedStore{values: make(map[string][]byte), versions: make(map[string]uint64)}
+}
+
+func (vs *versionedStore) SetIfVersion(ctx context.Context, key string, value []byte, expectedVersion uint64) (uint64, bool, error) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+	if current := vs.versions[key]; current != expectedVersion {
+		return current, false, nil
+	}
+	vs.values[key] = value
+	vs.versions[key]++
+	return vs.versions[key], true, nil
+}
+
+func (vs *versionedStore) Version(ctx context.Context, key string) (uint64, error) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+	return vs.versions[key], nil
+}
+
+func TestSyncedCacheSetIfVersion(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	store := newVersionedStore()
+	c.store = store
+	defer c.Close()
+
+	ctx := context.Background()
+	version, err := c.SetIfVersion(ctx, "counter", 1, 0)
+	if err != nil || version != 1 {
+		t.Fatalf("Expected version 1, got %d (err=%v)", version, err)
+	}
+	if value, found := c.Get(ctx, "counter"); !found || value != 1 {
+		t.Fatalf("Expected value to be cached locally, got %v (found=%v)", value, found)
+	}
+
+	// A writer holding an outdated version loses
+	version, err = c.SetIfVersion(ctx, "counter", 5, 0)
+	if err != ErrVersionConflict || version != 1 {
+		t.Fatalf("Expected ErrVersionConflict with current version 1, got %d (err=%v)", version, err)
+	}
+	if value, _ := c.Get(ctx, "counter"); value != 1 {
+		t.Fatalf("Conflicting write must not be cached, got %v", value)
+	}
+
+	current, err := c.Version(ctx, "counter")
+	if err != nil || current != 1 {
+		t.Fatalf("Expected Version 1, got %d (err=%v)", current, err)
+	}
+	if version, err := c.SetIfVersion(ctx, "counter", 2, current); err != nil || version != 2 {
+		t.Fatalf("Expected version 2, got %d (err=%v)", version, err)
+	}
+}
+
+func TestSyncedCacheSetIfVersionConcurrent(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	c.store = newVersionedStore()
+	defer c.Close()
+
+	ctx := context.Background()
+	const writers = 10
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				version, err := c.Version(ctx, "counter")
+				if err != nil {
+					t.Errorf("Version failed: %v", err)
+					return
+				}
+				if _, err := c.SetIfVersion(ctx, "counter", version+1, version); err == nil {
+					return
+				} else if err != ErrVersionConflict {
+					t.Errorf("SetIfVersion failed: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if version, _ := c.Version(ctx, "counter"); version != writers {
+		t.Fatalf("Expected every increment to be applied once, got version %d", version)
+	}
+}
+
+func TestSyncedCacheSetIfVersionErrors(t *testing.T) {
+	c := newMockedCache(t, Options{ExternalFormats: map[string]ExternalFormat{"legacy:": {}}})
+	ctx := context.Background()
+
+	if _, err := c.SetIfVersion(ctx, "counter", 1, 0); err != ErrVersioningNotSupported {
+		t.Fatalf("Expected ErrVersioningNotSupported, got %v", err)
+	}
+
+	c.store = newVersionedStore()
+	if _, err := c.SetIfVersion(ctx, "legacy:1", 1, 0); err != ErrExternalKey {
+		t.Fatalf("Expected ErrExternalKey, got %v", err)
+	}
+
+	c.Close()
+	if _, err := c.SetIfVersion(ctx, "counter", 1, 0); err != ErrCacheClosed {
+		t.Fatalf("Expected ErrCacheClosed, got %v", err)
+	}
+}
+
+func TestSyncedCacheSetIfVersionGoesThroughSetChecks(t *testing.T) {
+	ctx := context.Background()
+
+	c := newMockedCache(t, Options{ReadOnly: true})
+	c.store = newVersionedStore()
+	if _, err := c.SetIfVersion(ctx, "counter", 1, 0); err != ErrReadOnly {
+		t.Fatalf("Expected ErrReadOnly, got %v", err)
+	}
+	c.Close()
+
+	c = newMockedCache(t, Options{})
+	defer c.Close()
+	store := newVersionedStore()
+	c.store = store
+	c.breaker = openBreaker(nil)
+	if _, err := c.SetIfVersion(ctx, "counter", 1, 0); err != ErrCircuitOpen {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	c.breaker = nil
+
+	c.writeLimit = newTokenBucket(1, 1)
+	if _, err := c.SetIfVersion(ctx, "counter", 1, 0); err != nil {
+		t.Fatalf("SetIfVersion failed: %v", err)
+	}
+	if _, err := c.SetIfVersion(ctx, "counter", 2, 1); err != ErrRateLimited {
+		t.Fatalf("Expected ErrRateLimited, got %v", err)
+	}
+	if version, _ := store.Version(ctx, "counter"); version != 1 {
+		t.Fatalf("Expected rejected writes to leave version 1, got %d", version)
+	}
+}
+
+func TestSyncedCacheSetIfVersionPersistsFirst(t *testing.T) {
+	writer := &recordingWriter{failures: 1}
+	c := newMockedCache(t, Options{Writer: writer})
+	defer c.Close()
+	store := newVersionedStore()
+	c.store = store
+	ctx := context.Background()
+
+	if _, err := c.SetIfVersion(ctx, "counter", 1, 0); err == nil {
+		t.Fatal("Expected the Writer error")
+	}
+	if version, _ := store.Version(ctx, "counter"); version != 0 {
+		t.Fatalf("Expected a value the Writer rejected not to reach Redis, got version %d", version)
+	}
+	if _, found := c.local.Get("counter"); found {
+		t.Fatal("Expected a value the Writer rejected not to be cached")
+	}
+
+	if _, err := c.SetIfVersion(ctx, "counter", 1, 0); err != nil {
+		t.Fatalf("SetIfVersion failed: %v", err)
+	}
+	if _, err := c.SetIfVersion(ctx, "counter", 5, 0); err != ErrVersionConflict {
+		t.Fatalf("Expected ErrVersionConflict, got %v", err)
+	}
+	if value, _ := writer.value("counter"); value != 1 {
+		t.Fatalf("Expected a conflicting value not to be persisted, got %v", value)
+	}
+}
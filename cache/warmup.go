@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// warmupBatchSize is the number of keys scanned and read per round trip by Warmup.
+const warmupBatchSize = 100
+
+// Warmup populates the local cache with the given keys from the remote store and
+// blocks until they are loaded or ctx is done. Keys already present locally or
+// missing from the remote store are skipped. The remote reads are batched when the
+// store implements WarmupStore.
+func (sc *SyncedCache) Warmup(ctx context.Context, keys ...string) error {
+	if atomic.LoadInt32(&sc.closed) != 0 {
+		return ErrCacheClosed
+	}
+
+	store, batched := sc.store.(WarmupStore)
+	batched = batched && !sc.options.DisableRemoteStore
+	batch := make([]string, 0, warmupBatchSize)
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, found := sc.local.Get(key); found {
+			continue
+		}
+		// External keys are read one by one with their own encoding
+		if !batched || sc.externalFormatFor(key) != nil {
+			sc.fetchRemote(ctx, key)
+			continue
+		}
+
+		batch = append(batch, key)
+		if len(batch) == warmupBatchSize {
+			if err := sc.warmupBatch(ctx, store, batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		return sc.warmupBatch(ctx, store, batch)
+	}
+	return nil
+}
+
+// warmupPattern warms the local cache with every key of the remote store matching pattern.
+func (sc *SyncedCache) warmupPattern(ctx context.Context, pattern string) error {
+	store, ok := sc.store.(WarmupStore)
+	if !ok || sc.options.DisableRemoteStore {
+		return ErrWarmupNotSupported
+	}
+	return store.ScanKeys(ctx, pattern, warmupBatchSize, func(keys []string) error {
+		return sc.Warmup(ctx, keys...)
+	})
+}
+
+// warmupBatch reads keys in a single round trip and stores the values found locally.
+func (sc *SyncedCache) warmupBatch(ctx context.Context, store WarmupStore, keys []string) error {
+	values, err := store.GetMulti(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	for key, data := range values {
+		val, err := sc.decodeLocal(sc.serializer, key, data)
+		if err != nil {
+			sc.reportError(OpWarmup, key, ErrDeserialization, err)
+			if sc.logging(DebugSerialization) {
+				sc.logger.Error("Warmup: deserialization failed", "key", key, "error", err)
+			}
+			continue
+		}
+		if sc.skipLocal(key) {
+			continue
+		}
+		sc.setLocal(key, val, sc.cost(key, val, data), SourceRemote)
+		sc.entryInfos.record(key, SourceRemote, data)
+	}
+
+	if sc.debugging(DebugOps) {
+		sc.logger.Debug("Warmup: loaded batch", "keys", len(keys), "found", len(values))
+	}
+	return nil
+}
+
+// startWarmup warms the local cache from Options.WarmupKeys and Options.WarmupPattern
+// before New returns, for at most ContextTimeout. Failures are reported via OnError
+// and do not prevent the cache from starting.
+func (sc *SyncedCache) startWarmup() {
+	if len(sc.options.WarmupKeys) == 0 && sc.options.WarmupPattern == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sc.options.ContextTimeout)
+	defer cancel()
+
+	err := sc.Warmup(ctx, sc.options.WarmupKeys...)
+	if err == nil && sc.options.WarmupPattern != "" {
+		err = sc.warmupPattern(ctx, sc.options.WarmupPattern)
+	}
+	if err != nil {
+		sc.reportError(OpWarmup, "", ErrRemoteStore, err)
+		if sc.logging(DebugOps) {
+			sc.logger.Error("Warmup: failed to warm local cache", "error", err)
+		}
+		return
+	}
+
+	if sc.logging(DebugOps) {
+		sc.logger.Info("Warmup: local cache warmed", "size", sc.local.Metrics().Size)
+	}
+}
+
+// ErrWarmupNotSupported is returned when Options.WarmupPattern is set but the store
+// does not implement WarmupStore.
+var ErrWarmupNotSupported = NewError("store does not support scanning keys for warmup")
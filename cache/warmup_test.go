@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// warmupStore is a store keeping values in memory and counting GetMulti round trips.
+type warmupStore struct {
+	errorStore
+	values  map[string][]byte
+	batches [][]string
+}
+
+func (ws *warmupStore) ScanKeys(ctx context.Context, pattern string, batchSize int, fn func(keys []string) error) error {
+	var keys []string
+	for key := range ws.values {
+		if ok, _ := path.Match(pattern, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for len(keys) > 0 {
+		n := min(batchSize, len(keys))
+		if err := fn(keys[:n]); err != nil {
+			return err
+		}
+		keys = keys[n:]
+	}
+	return nil
+}
+
+func (ws *warmupStore) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	ws.batches = append(ws.batches, append([]string(nil), keys...))
+	values := make(map[string][]byte)
+	for _, key := range keys {
+		if data, ok := ws.values[key]; ok {
+			values[key] = data
+		}
+	}
+	return values, nil
+}
+
+func TestSyncedCacheWarmup(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	store := &warmupStore{values: map[string][]byte{"a": []byte(`"1"`), "b": []byte(`"2"`)}}
+	c.store = store
+	c.setLocal("local", "kept", 1, SourceSet)
+
+	if err := c.Warmup(context.Background(), "a", "b", "missing", "local"); err != nil {
+		t.Fatalf("Warmup failed: %v", err)
+	}
+
+	if len(store.batches) != 1 || len(store.batches[0]) != 3 {
+		t.Fatalf("Expected a single batch of 3 keys, got %v", store.batches)
+	}
+	for key, expected := range map[string]string{"a": "1", "b": "2", "local": "kept"} {
+		if value, found := c.local.Get(key); !found || value != expected {
+			t.Fatalf("Expected %q for key %q, got %v (found %v)", expected, key, value, found)
+		}
+	}
+	if _, found := c.local.Get("missing"); found {
+		t.Fatal("Expected missing key not to be cached")
+	}
+	if info := c.entryInfos.hitInfo("a"); info.Source != SourceRemote {
+		t.Fatalf("Expected remote source, got %v", info.Source)
+	}
+}
+
+func TestSyncedCacheWarmupBatches(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	store := &warmupStore{values: map[string][]byte{}}
+	c.store = store
+
+	keys := make([]string, warmupBatchSize+1)
+	for i := range keys {
+		keys[i] = "key:" + strconv.Itoa(i)
+	}
+	if err := c.Warmup(context.Background(), keys...); err != nil {
+		t.Fatalf("Warmup failed: %v", err)
+	}
+	if len(store.batches) != 2 || len(store.batches[1]) != 1 {
+		t.Fatalf("Expected 2 batches, got %d", len(store.batches))
+	}
+}
+
+func TestSyncedCacheWarmupWithoutWarmupStore(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	c.store = &hotKeysStore{values: map[string][]byte{"a": []byte(`"1"`)}}
+
+	if err := c.Warmup(context.Background(), "a"); err != nil {
+		t.Fatalf("Warmup failed: %v", err)
+	}
+	if _, found := c.local.Get("a"); !found {
+		t.Fatal("Expected key to be warmed with single reads")
+	}
+}
+
+func TestSyncedCacheStartWarmup(t *testing.T) {
+	c := newMockedCache(t, Options{WarmupKeys: []string{"a"}, WarmupPattern: "config:*"})
+	c.store = &warmupStore{values: map[string][]byte{
+		"a":        []byte(`"1"`),
+		"config:x": []byte(`"x"`),
+		"config:y": []byte(`"y"`),
+		"other":    []byte(`"o"`),
+	}}
+
+	c.startWarmup()
+
+	for _, key := range []string{"a", "config:x", "config:y"} {
+		if _, found := c.local.Get(key); !found {
+			t.Fatalf("Expected %q to be warmed", key)
+		}
+	}
+	if _, found := c.local.Get("other"); found {
+		t.Fatal("Expected keys outside the pattern not to be warmed")
+	}
+}
+
+func TestSyncedCacheStartWarmupNotSupported(t *testing.T) {
+	var reported error
+	c := newMockedCache(t, Options{WarmupPattern: "config:*", OnError: func(err error) { reported = err }})
+
+	c.startWarmup()
+
+	if !errors.Is(reported, ErrWarmupNotSupported) {
+		t.Fatalf("Expected ErrWarmupNotSupported, got %v", reported)
+	}
+}
+
+func TestSyncedCacheWarmupClosed(t *testing.T) {
+	c := newMockedCache(t, Options{})
+	c.Close()
+
+	if err := c.Warmup(context.Background(), "a"); !errors.Is(err, ErrCacheClosed) {
+		t.Fatalf("Expected ErrCacheClosed, got %v", err)
+	}
+}
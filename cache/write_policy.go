@@ -0,0 +1,42 @@
+package cache
+
+// WritePolicy selects which levels Set writes and which event it publishes to
+// other pods, see Options.WritePolicy.
+type WritePolicy string
+
+const (
+	// WritePolicyWriteThrough stores values in the local cache and Redis and
+	// propagates them to other pods. Writer, if set, is called synchronously.
+	WritePolicyWriteThrough WritePolicy = "write-through"
+	// WritePolicyLocalOnly stores values in the local cache and propagates them to
+	// other pods but never writes Redis, for reader nodes that must not overwrite
+	// fresher data. Set does not fail for lack of a Redis write: the value lives only
+	// in local caches until evicted.
+	WritePolicyLocalOnly WritePolicy = "local-only"
+	// WritePolicyInvalidateOnly stores values in the local cache and Redis and makes
+	// Set behave like SetWithInvalidate: other pods drop the key and fetch it from Redis.
+	WritePolicyInvalidateOnly WritePolicy = "invalidate-only"
+	// WritePolicyWriteBehind is WritePolicyWriteThrough with Writer called
+	// asynchronously from a bounded queue, see Options.WriteBehindQueueSize.
+	WritePolicyWriteBehind WritePolicy = "write-behind"
+)
+
+// effectiveWritePolicy returns WritePolicy, or the policy matching the deprecated
+// ReaderCanSetToRedis and WriteBehind settings when it is not set.
+func (o Options) effectiveWritePolicy() WritePolicy {
+	switch {
+	case o.WritePolicy != "":
+		return o.WritePolicy
+	case !o.ReaderCanSetToRedis:
+		return WritePolicyLocalOnly
+	case o.WriteBehind:
+		return WritePolicyWriteBehind
+	default:
+		return WritePolicyWriteThrough
+	}
+}
+
+// writesRemote reports whether policy writes values to Redis.
+func (p WritePolicy) writesRemote() bool {
+	return p != WritePolicyLocalOnly
+}
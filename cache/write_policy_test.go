@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEffectiveWritePolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want WritePolicy
+	}{
+		{"default", Options{}, WritePolicyLocalOnly},
+		{"reader can set", Options{ReaderCanSetToRedis: true}, WritePolicyWriteThrough},
+		{"write behind", Options{ReaderCanSetToRedis: true, WriteBehind: true}, WritePolicyWriteBehind},
+		{"explicit wins", Options{WritePolicy: WritePolicyInvalidateOnly}, WritePolicyInvalidateOnly},
+	}
+	for _, tt := range tests {
+		if got := tt.opts.effectiveWritePolicy(); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestWritePolicyLocalOnlySkipsRedis(t *testing.T) {
+	sc := newMockedCache(t, Options{ReaderCanSetToRedis: true, WritePolicy: WritePolicyLocalOnly})
+	defer sc.Close()
+	store := &externalStore{}
+	sc.store = store
+	publisher := &publishingSynchronizer{}
+	sc.synchronizer = publisher
+
+	if err := sc.Set(context.Background(), "key", "value"); err != nil {
+		t.Fatalf("Expected Set to succeed on a local-only node, got %v", err)
+	}
+	if store.writes != 0 {
+		t.Errorf("Expected no Redis write, got %d", store.writes)
+	}
+	if len(publisher.events) != 1 || publisher.events[0].Action != ActionSet {
+		t.Errorf("Expected the value to be propagated, got %+v", publisher.events)
+	}
+}
+
+func TestWritePolicyInvalidateOnly(t *testing.T) {
+	sc := newMockedCache(t, Options{WritePolicy: WritePolicyInvalidateOnly})
+	defer sc.Close()
+	store := &externalStore{}
+	sc.store = store
+	publisher := &publishingSynchronizer{}
+	sc.synchronizer = publisher
+
+	if err := sc.Set(context.Background(), "key", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if store.writes != 1 {
+		t.Errorf("Expected one Redis write, got %d", store.writes)
+	}
+	if len(publisher.events) != 1 || publisher.events[0].Action != ActionInvalidate {
+		t.Errorf("Expected Set to publish an invalidation, got %+v", publisher.events)
+	}
+}
@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// defaultWriteBehindQueueSize is the write-behind queue size used when
+// Options.WriteBehindQueueSize is not set.
+const defaultWriteBehindQueueSize = 1024
+
+// pendingWrite is a value waiting in the write-behind queue.
+type pendingWrite struct {
+	key   string
+	value any
+}
+
+// persist writes a value through Options.Writer.
+// In write-through mode it blocks until the Writer succeeds or gives up; in
+// write-behind mode it only queues the value and fails when the queue is full.
+func (sc *SyncedCache) persist(ctx context.Context, key string, value any) error {
+	if sc.options.Writer == nil {
+		return nil
+	}
+
+	if sc.writeQueue == nil {
+		return sc.writeWithRetries(ctx, key, value)
+	}
+
+	select {
+	case sc.writeQueue <- pendingWrite{key: key, value: value}:
+		return nil
+	default:
+		return ErrWriteQueueFull
+	}
+}
+
+// runWriteBehind persists queued values until the cache is closed.
+// Values still queued on Close are written before returning, until the
+// Shutdown context is done.
+func (sc *SyncedCache) runWriteBehind(ctx context.Context) {
+	// Writes outlive ctx, cancelled on Close, until the Shutdown context is done
+	writeCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	defer cancel(nil)
+	stop := context.AfterFunc(ctx, func() {
+		shutdownCtx := sc.shutdownContext()
+		context.AfterFunc(shutdownCtx, func() { cancel(shutdownCtx.Err()) })
+	})
+	defer stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			sc.drainWriteBehind(writeCtx)
+			return
+		case w := <-sc.writeQueue:
+			sc.writeBehind(writeCtx, w)
+		}
+	}
+}
+
+// shutdownContext returns the context passed to Shutdown, or a background
+// context when the cache is not shutting down.
+func (sc *SyncedCache) shutdownContext() context.Context {
+	sc.bgMutex.Lock()
+	defer sc.bgMutex.Unlock()
+	if sc.drainCtx == nil {
+		return context.Background()
+	}
+	return sc.drainCtx
+}
+
+// drainWriteBehind persists the values left in the write-behind queue until ctx
+// is done, then abandons the rest.
+func (sc *SyncedCache) drainWriteBehind(ctx context.Context) {
+	for {
+		select {
+		case w := <-sc.writeQueue:
+			if ctx.Err() != nil {
+				sc.abandonWriteBehind(w, context.Cause(ctx))
+				return
+			}
+			sc.writeBehind(ctx, w)
+		default:
+			return
+		}
+	}
+}
+
+// abandonWriteBehind reports w and the values left in the write-behind queue,
+// which Shutdown gives up persisting, and hands them to OnWriteFailed with err.
+func (sc *SyncedCache) abandonWriteBehind(w pendingWrite, err error) {
+	abandoned := 0
+	for {
+		abandoned++
+		sc.reportError(OpWrite, w.key, nil, err)
+		if sc.options.OnWriteFailed != nil {
+			sc.options.OnWriteFailed(w.key, w.value, err)
+		}
+		select {
+		case w = <-sc.writeQueue:
+		default:
+			if sc.logging(DebugOps) {
+				sc.logger.Warn("Shutdown: write-behind values not persisted", "count", abandoned, "error", err)
+			}
+			return
+		}
+	}
+}
+
+// writeBehind persists a queued value, handing it to OnWriteFailed when all attempts fail.
+func (sc *SyncedCache) writeBehind(parent context.Context, w pendingWrite) {
+	ctx, cancel := context.WithTimeout(parent, sc.options.ContextTimeout)
+	defer cancel()
+
+	if err := sc.writeWithRetries(ctx, w.key, w.value); err != nil && sc.options.OnWriteFailed != nil {
+		sc.options.OnWriteFailed(w.key, w.value, err)
+	}
+}
+
+// writeWithRetries calls the Writer, retrying up to Options.WriteRetries times.
+func (sc *SyncedCache) writeWithRetries(ctx context.Context, key string, value any) error {
+	var err error
+	for attempt := 0; attempt <= sc.options.WriteRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(sc.options.WriteRetryInterval):
+			}
+		}
+
+		if err = sc.options.Writer.Write(ctx, key, value); err == nil {
+			if sc.debugging(DebugOps) {
+				sc.logger.Debug("Writer: persisted value", "key", key, "attempt", attempt+1)
+			}
+			return nil
+		}
+
+		sc.reportError(OpWrite, key, nil, err)
+		if sc.logging(DebugOps) {
+			sc.logger.Warn("Writer: failed to persist value", "key", key, "attempt", attempt+1, "error", err)
+		}
+	}
+	return err
+}
+
+// ErrWriteQueueFull is returned by Set when the write-behind queue is full.
+var ErrWriteQueueFull = NewError("write-behind queue is full")
@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingWriter records persisted values and fails the first failures calls.
+type recordingWriter struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	written  map[string]any
+}
+
+func (rw *recordingWriter) Write(ctx context.Context, key string, value any) error {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	rw.calls++
+	if rw.calls <= rw.failures {
+		return errors.New("write error")
+	}
+	if rw.written == nil {
+		rw.written = make(map[string]any)
+	}
+	rw.written[key] = value
+	return nil
+}
+
+func (rw *recordingWriter) value(key string) (any, bool) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	value, ok := rw.written[key]
+	return value, ok
+}
+
+func TestSyncedCacheWriteThrough(t *testing.T) {
+	writer := &recordingWriter{}
+	c := newMockedCache(t, Options{Writer: writer})
+	defer c.Close()
+
+	if err := c.Set(context.Background(), "test:writer", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if value, ok := writer.value("test:writer"); !ok || value != "value" {
+		t.Fatalf("Expected value to be written through, got %v", value)
+	}
+}
+
+func TestSyncedCacheWriteThroughFailureSkipsCache(t *testing.T) {
+	writer := &recordingWriter{failures: 1}
+	c := newMockedCache(t, Options{Writer: writer})
+	defer c.Close()
+
+	if err := c.Set(context.Background(), "test:writer", "value"); err == nil {
+		t.Fatal("Expected Set to return the writer error")
+	}
+	if _, found := c.local.Get("test:writer"); found {
+		t.Fatal("Value should not be cached when write-through fails")
+	}
+}
+
+func TestSyncedCacheWriteThroughRetries(t *testing.T) {
+	writer := &recordingWriter{failures: 2}
+	c := newMockedCache(t, Options{Writer: writer, WriteRetries: 2, WriteRetryInterval: time.Millisecond})
+	defer c.Close()
+
+	if err := c.Set(context.Background(), "test:writer", "value"); err != nil {
+		t.Fatalf("Set should succeed after retries: %v", err)
+	}
+	if writer.calls != 3 {
+		t.Fatalf("Expected 3 writer calls, got %d", writer.calls)
+	}
+}
+
+func TestSyncedCacheWriteBehind(t *testing.T) {
+	writer := &recordingWriter{}
+	c := newMockedCache(t, Options{Writer: writer, WriteBehind: true, WriteBehindQueueSize: 10})
+
+	for _, key := range []string{"test:wb:1", "test:wb:2", "test:wb:3"} {
+		if err := c.Set(context.Background(), key, key); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	c.Close()
+
+	for _, key := range []string{"test:wb:1", "test:wb:2", "test:wb:3"} {
+		if value, ok := writer.value(key); !ok || value != key {
+			t.Fatalf("Expected %s to be written behind before Close returns, got %v", key, value)
+		}
+	}
+}
+
+func TestSyncedCacheWriteBehindDeadLetter(t *testing.T) {
+	writer := &recordingWriter{failures: 2}
+	deadLetters := make(chan string, 1)
+	c := newMockedCache(t, Options{
+		Writer:               writer,
+		WriteBehind:          true,
+		WriteBehindQueueSize: 10,
+		WriteRetries:         1,
+		OnWriteFailed: func(key string, value any, err error) {
+			deadLetters <- key
+		},
+	})
+	defer c.Close()
+
+	if err := c.Set(context.Background(), "test:wb:dead", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	select {
+	case key := <-deadLetters:
+		if key != "test:wb:dead" {
+			t.Fatalf("Expected dead letter for test:wb:dead, got %s", key)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for dead letter")
+	}
+}
+
+func TestSyncedCacheWriteBehindQueueFull(t *testing.T) {
+	c := newMockedCache(t, Options{Writer: &recordingWriter{}})
+	defer c.Close()
+
+	// A queue with no consumer fills up immediately
+	c.writeQueue = make(chan pendingWrite, 1)
+
+	if err := c.Set(context.Background(), "test:wb:1", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := c.Set(context.Background(), "test:wb:2", "value"); err != ErrWriteQueueFull {
+		t.Fatalf("Expected ErrWriteQueueFull, got %v", err)
+	}
+}
+
+// blockingWriter blocks every write until its context is done.
+type blockingWriter struct{}
+
+func (blockingWriter) Write(ctx context.Context, key string, value any) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestSyncedCacheShutdownBoundsWriteBehindDrain(t *testing.T) {
+	var mu sync.Mutex
+	var failed []string
+	c := newMockedCache(t, Options{
+		Writer:               blockingWriter{},
+		WriteBehind:          true,
+		WriteBehindQueueSize: 10,
+		ContextTimeout:       time.Minute,
+		OnWriteFailed: func(key string, value any, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			failed = append(failed, key)
+		},
+	})
+
+	keys := []string{"test:wb:1", "test:wb:2", "test:wb:3"}
+	for _, key := range keys {
+		if err := c.Set(context.Background(), key, key); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected Shutdown to report its deadline, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected Shutdown to stop draining at its deadline, took %v", elapsed)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(failed) != len(keys) {
+		t.Fatalf("Expected every unflushed value to be reported, got %v", failed)
+	}
+}
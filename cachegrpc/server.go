@@ -0,0 +1,158 @@
+// Package cachegrpc serves a cache.Cache over gRPC, so sidecars and non-Go
+// services can Get, Set and Invalidate through a pod's cache. The service is
+// defined in cachepb/cache.proto; values are exchanged as JSON documents.
+//
+//	srv := grpc.NewServer()
+//	cachepb.RegisterCacheServiceServer(srv, cachegrpc.NewServer(c))
+package cachegrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/huykn/distributed-cache/cache"
+	"github.com/huykn/distributed-cache/cachegrpc/cachepb"
+)
+
+// Server implements cachepb.CacheServiceServer on top of a cache.Cache.
+type Server struct {
+	cachepb.UnimplementedCacheServiceServer
+	cache cache.Cache
+}
+
+// NewServer creates a gRPC service wrapping c.
+func NewServer(c cache.Cache) *Server {
+	return &Server{cache: c}
+}
+
+// Get returns the JSON encoding of the value of a key.
+func (s *Server) Get(ctx context.Context, req *cachepb.GetRequest) (*cachepb.GetResponse, error) {
+	if req.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing key")
+	}
+	value, found, info := s.cache.GetWithInfo(ctx, req.GetKey())
+	resp := &cachepb.GetResponse{
+		Found:   found,
+		Level:   string(info.Level),
+		Source:  string(info.Source),
+		Version: info.Version,
+		Tags:    info.Tags,
+	}
+	if !found {
+		return resp, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "encode value: %v", err)
+	}
+	resp.Value = data
+	return resp, nil
+}
+
+// Set decodes a JSON value and stores it.
+func (s *Server) Set(ctx context.Context, req *cachepb.SetRequest) (*cachepb.SetResponse, error) {
+	if req.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing key")
+	}
+	var value any
+	if err := json.Unmarshal(req.GetValue(), &value); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "value is not valid JSON: %v", err)
+	}
+
+	var opts []cache.SetOption
+	if req.GetTtlMs() > 0 {
+		opts = append(opts, cache.WithTTL(time.Duration(req.GetTtlMs())*time.Millisecond))
+	}
+	if req.GetInvalidateOnly() {
+		opts = append(opts, cache.WithInvalidateOnly())
+	}
+	if len(req.GetTags()) > 0 {
+		opts = append(opts, cache.WithTags(req.GetTags()...))
+	}
+	if err := s.cache.Set(ctx, req.GetKey(), value, opts...); err != nil {
+		return nil, toStatus(err)
+	}
+	return &cachepb.SetResponse{}, nil
+}
+
+// Invalidate deletes a key from every level and every pod.
+func (s *Server) Invalidate(ctx context.Context, req *cachepb.InvalidateRequest) (*cachepb.InvalidateResponse, error) {
+	if req.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing key")
+	}
+	if err := s.cache.Delete(ctx, req.GetKey()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &cachepb.InvalidateResponse{}, nil
+}
+
+// Keys lists the keys held in the local cache, sorted.
+func (s *Server) Keys(ctx context.Context, req *cachepb.KeysRequest) (*cachepb.KeysResponse, error) {
+	var keys []string
+	for _, key := range s.cache.LocalKeys() {
+		if strings.HasPrefix(key, req.GetPrefix()) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return &cachepb.KeysResponse{Keys: keys}, nil
+}
+
+// Stats returns the cache statistics.
+func (s *Server) Stats(ctx context.Context, req *cachepb.StatsRequest) (*cachepb.StatsResponse, error) {
+	stats := s.cache.Stats()
+	return &cachepb.StatsResponse{
+		LocalHits:     stats.LocalHits,
+		LocalMisses:   stats.LocalMisses,
+		RemoteHits:    stats.RemoteHits,
+		RemoteMisses:  stats.RemoteMisses,
+		LocalSize:     stats.LocalSize,
+		RemoteSize:    stats.RemoteSize,
+		Evictions:     stats.Evictions,
+		Invalidations: stats.Invalidations,
+		PendingEvents: stats.PendingEvents,
+	}, nil
+}
+
+// Health checks Redis, the sync subscription and the local cache.
+func (s *Server) Health(ctx context.Context, req *cachepb.HealthRequest) (*cachepb.HealthResponse, error) {
+	health := s.cache.Health(ctx)
+	return &cachepb.HealthResponse{
+		Healthy:           health.Healthy,
+		RedisReachable:    health.RedisReachable,
+		RedisError:        health.RedisError,
+		SubscriptionAlive: health.SubscriptionAlive,
+		SubscriptionError: health.SubscriptionError,
+		LocalCacheUsable:  health.LocalCacheUsable,
+	}, nil
+}
+
+// toStatus maps cache errors to gRPC status codes.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, cache.ErrCacheClosed), errors.Is(err, cache.ErrCircuitOpen):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, cache.ErrVersionConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, cache.ErrTTLNotSupported), errors.Is(err, cache.ErrExternalKey),
+		errors.Is(err, cache.ErrReadOnly):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, cache.ErrGroupsNotSupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, cache.ErrValueTooLarge):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,125 @@
+package cachegrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/huykn/distributed-cache/cache"
+	"github.com/huykn/distributed-cache/cachegrpc/cachepb"
+	"github.com/huykn/distributed-cache/cachetest"
+)
+
+// dial serves c over an in-memory listener and returns a connected client.
+func dial(t *testing.T, c cache.Cache) cachepb.CacheServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	cachepb.RegisterCacheServiceServer(srv, NewServer(c))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return cachepb.NewCacheServiceClient(conn)
+}
+
+func TestServerSetGetInvalidate(t *testing.T) {
+	ctx := context.Background()
+	c := cachetest.New()
+	client := dial(t, c)
+
+	if _, err := client.Set(ctx, &cachepb.SetRequest{Key: "user:1", Value: []byte(`{"name":"alice"}`)}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	got, err := client.Get(ctx, &cachepb.GetRequest{Key: "user:1"})
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if !got.Found || string(got.Value) != `{"name":"alice"}` {
+		t.Errorf("Expected the stored value, got %+v", got)
+	}
+
+	if _, err := client.Invalidate(ctx, &cachepb.InvalidateRequest{Key: "user:1"}); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	got, err = client.Get(ctx, &cachepb.GetRequest{Key: "user:1"})
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if got.Found {
+		t.Errorf("Expected a miss after Invalidate, got %+v", got)
+	}
+}
+
+func TestServerKeys(t *testing.T) {
+	c := cachetest.New()
+	c.Seed(map[string]any{"user:2": "bob", "user:1": "alice", "order:1": 42})
+	client := dial(t, c)
+
+	resp, err := client.Keys(context.Background(), &cachepb.KeysRequest{Prefix: "user:"})
+	if err != nil {
+		t.Fatalf("Keys failed: %v", err)
+	}
+	if len(resp.Keys) != 2 || resp.Keys[0] != "user:1" || resp.Keys[1] != "user:2" {
+		t.Errorf("Expected the sorted user keys, got %v", resp.Keys)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	ctx := context.Background()
+	c := cachetest.New()
+	c.FailOn(cachetest.OpSet, cache.ErrCacheClosed)
+	c.FailOnKey(cachetest.OpSet, "read-only", cache.ErrReadOnly)
+	c.FailOnKey(cachetest.OpSet, "grouped", cache.ErrGroupsNotSupported)
+	client := dial(t, c)
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"missing key", func() error {
+			_, err := client.Get(ctx, &cachepb.GetRequest{})
+			return err
+		}, codes.InvalidArgument},
+		{"invalid JSON", func() error {
+			_, err := client.Set(ctx, &cachepb.SetRequest{Key: "k", Value: []byte("{")})
+			return err
+		}, codes.InvalidArgument},
+		{"closed cache", func() error {
+			_, err := client.Set(ctx, &cachepb.SetRequest{Key: "k", Value: []byte("1")})
+			return err
+		}, codes.Unavailable},
+		{"read-only cache", func() error {
+			_, err := client.Set(ctx, &cachepb.SetRequest{Key: "read-only", Value: []byte("1")})
+			return err
+		}, codes.FailedPrecondition},
+		{"groups not supported", func() error {
+			_, err := client.Set(ctx, &cachepb.SetRequest{Key: "grouped", Value: []byte("1")})
+			return err
+		}, codes.Unimplemented},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := status.Code(tt.call()); code != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,878 @@
+// Package cachetest provides an in-memory implementation of the cache.Cache
+// interface for unit-testing code that embeds the distributed cache, without
+// Redis or a real SyncedCache.
+//
+// Values are stored as-is, without serialization. Hits are scripted by seeding
+// values, misses by forcing keys to miss, and errors by failing operations.
+// Every call is recorded so tests can assert on how the cache was used.
+package cachetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huykn/distributed-cache/cache"
+)
+
+// Op identifies a Cache method.
+type Op string
+
+// Operations recorded by Cache.
+const (
+	OpGet               Op = "Get"
+	OpGetWithInfo       Op = "GetWithInfo"
+	OpGetRaw            Op = "GetRaw"
+	OpGetWithETag       Op = "GetWithETag"
+	OpCheckETag         Op = "CheckETag"
+	OpPrefetch          Op = "Prefetch"
+	OpWarmup            Op = "Warmup"
+	OpRegisterLoader    Op = "RegisterLoader"
+	OpRefreshAhead      Op = "RefreshAhead"
+	OpSet               Op = "Set"
+	OpSetWithInvalidate Op = "SetWithInvalidate"
+	OpSetAck            Op = "SetAck"
+	OpSetIfVersion      Op = "SetIfVersion"
+	OpVersion           Op = "Version"
+	OpDelete            Op = "Delete"
+	OpDeleteGroup       Op = "DeleteGroup"
+	OpClear             Op = "Clear"
+	OpDumpRemote        Op = "DumpRemote"
+	OpRestoreRemote     Op = "RestoreRemote"
+	OpLock              Op = "Lock"
+	OpUnlock            Op = "Unlock"
+	OpWatch             Op = "Watch"
+	OpSubscribe         Op = "Subscribe"
+	OpAnnounce          Op = "Announce"
+	OpLocalKeys         Op = "LocalKeys"
+	OpRemoteKeys        Op = "RemoteKeys"
+	OpIterateLocal      Op = "IterateLocal"
+	OpEstimateMemory    Op = "EstimateMemory"
+	OpPing              Op = "Ping"
+	OpHealth            Op = "Health"
+	OpShutdown          Op = "Shutdown"
+	OpClose             Op = "Close"
+	OpPeers             Op = "Peers"
+	OpIsLeader          Op = "IsLeader"
+)
+
+// Call is a recorded call to a Cache method.
+type Call struct {
+	Op    Op
+	Key   string // key, loader pattern, prefix or lifecycle type; empty for Clear, Watch, Shutdown and Close
+	Value any    // value passed to Set, SetWithInvalidate and SetIfVersion, or details passed to Announce
+}
+
+// Cache is an in-memory cache.Cache for tests. It is safe for concurrent use.
+type Cache struct {
+	mu        sync.Mutex
+	values    map[string]any
+	versions  map[string]uint64
+	misses    map[string]bool
+	errors    map[Op]error
+	keyErrors map[Op]map[string]error
+	loaders   map[string]cache.LoaderFunc
+	locks     map[string]*lock
+	watchers  []chan cache.LifecycleEvent
+	changes   map[int]func(event cache.ChangeEvent)
+	peers     []cache.Peer
+	acks      int
+	leader    bool
+	leaderFns []func(leader bool)
+	nextID    int
+	calls     []Call
+	stats     cache.Stats
+	closed    bool
+}
+
+var _ cache.Cache = (*Cache)(nil)
+
+// New creates an empty Cache.
+func New() *Cache {
+	return &Cache{
+		values:    make(map[string]any),
+		versions:  make(map[string]uint64),
+		misses:    make(map[string]bool),
+		errors:    make(map[Op]error),
+		keyErrors: make(map[Op]map[string]error),
+		loaders:   make(map[string]cache.LoaderFunc),
+		locks:     make(map[string]*lock),
+		changes:   make(map[int]func(event cache.ChangeEvent)),
+	}
+}
+
+// Seed stores values without recording calls, scripting hits for later Gets.
+func (c *Cache) Seed(values map[string]any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, value := range values {
+		c.values[key] = value
+	}
+}
+
+// ForceMiss makes Get report the given keys as missing even if they hold a value.
+func (c *Cache) ForceMiss(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		c.misses[key] = true
+	}
+}
+
+// FailOn makes every call to op return err. Passing a nil err removes the failure.
+// Get and GetWithInfo report a miss instead of returning the error.
+func (c *Cache) FailOn(op Op, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		delete(c.errors, op)
+		return
+	}
+	c.errors[op] = err
+}
+
+// FailOnKey makes calls to op for key return err. Passing a nil err removes the failure.
+func (c *Cache) FailOnKey(op Op, key string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		delete(c.keyErrors[op], key)
+		return
+	}
+	if c.keyErrors[op] == nil {
+		c.keyErrors[op] = make(map[string]error)
+	}
+	c.keyErrors[op][key] = err
+}
+
+// SetPeers scripts the peers returned by Peers.
+func (c *Cache) SetPeers(peers ...cache.Peer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.peers = append([]cache.Peer(nil), peers...)
+}
+
+// SetAcks scripts the number of pods confirming each SetAck.
+func (c *Cache) SetAcks(acks int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.acks = acks
+}
+
+// SetLeader scripts whether the cache is the leader, calling the functions
+// registered with OnLeadershipChange when it changes.
+func (c *Cache) SetLeader(leader bool) {
+	c.mu.Lock()
+	changed := c.leader != leader
+	c.leader = leader
+	fns := append([]func(bool){}, c.leaderFns...)
+	c.mu.Unlock()
+	if !changed {
+		return
+	}
+	for _, fn := range fns {
+		fn(leader)
+	}
+}
+
+// Calls returns the recorded calls in order.
+func (c *Cache) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+// CallsTo returns the recorded calls to op in order.
+func (c *Cache) CallsTo(op Op) []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var calls []Call
+	for _, call := range c.calls {
+		if call.Op == op {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Values returns a copy of the values currently stored.
+func (c *Cache) Values() map[string]any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	values := make(map[string]any, len(c.values))
+	for key, value := range c.values {
+		values[key] = value
+	}
+	return values
+}
+
+// Reset clears stored values, scripted behavior, recorded calls and statistics.
+func (c *Cache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values = make(map[string]any)
+	c.versions = make(map[string]uint64)
+	c.misses = make(map[string]bool)
+	c.errors = make(map[Op]error)
+	c.keyErrors = make(map[Op]map[string]error)
+	c.loaders = make(map[string]cache.LoaderFunc)
+	c.locks = make(map[string]*lock)
+	c.peers = nil
+	c.acks = 0
+	c.leader = false
+	c.calls = nil
+	c.stats = cache.Stats{}
+	c.closed = false
+}
+
+// Get retrieves a value, falling back to a registered loader on a miss.
+func (c *Cache) Get(ctx context.Context, key string) (any, bool) {
+	value, found, _ := c.get(ctx, OpGet, key)
+	return value, found
+}
+
+// GetWithInfo retrieves a value along with a HitInfo describing a local hit,
+// a loader hit or a miss.
+func (c *Cache) GetWithInfo(ctx context.Context, key string) (any, bool, cache.HitInfo) {
+	return c.get(ctx, OpGetWithInfo, key)
+}
+
+// get records and serves a Get or GetWithInfo call.
+func (c *Cache) get(ctx context.Context, op Op, key string) (any, bool, cache.HitInfo) {
+	miss := cache.HitInfo{Level: cache.LevelMiss, Source: cache.SourceNone}
+
+	c.mu.Lock()
+	c.record(Call{Op: op, Key: key})
+	if c.closed || c.errorFor(op, key) != nil || c.misses[key] {
+		c.stats.LocalMisses++
+		c.mu.Unlock()
+		return nil, false, miss
+	}
+	if value, found := c.values[key]; found {
+		c.stats.LocalHits++
+		c.stats.LocalHitsSet++
+		c.mu.Unlock()
+		return value, true, cache.HitInfo{Level: cache.LevelLocal, Source: cache.SourceSet}
+	}
+	c.stats.LocalMisses++
+	loader := c.loaderFor(key)
+	c.mu.Unlock()
+
+	if loader == nil {
+		return nil, false, miss
+	}
+	value, err := loader(ctx, key)
+	if err != nil || value == nil {
+		return nil, false, miss
+	}
+
+	c.mu.Lock()
+	c.values[key] = value
+	c.mu.Unlock()
+	return value, true, cache.HitInfo{Level: cache.LevelLoader, Source: cache.SourceLoader}
+}
+
+// GetRaw retrieves a value like Get and returns it encoded as JSON.
+func (c *Cache) GetRaw(ctx context.Context, key string) ([]byte, bool) {
+	value, found, _ := c.get(ctx, OpGetRaw, key)
+	if !found {
+		return nil, false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// GetWithETag retrieves a value like Get along with the ETag of its JSON encoding.
+func (c *Cache) GetWithETag(ctx context.Context, key string) (any, string, bool) {
+	value, found, _ := c.get(ctx, OpGetWithETag, key)
+	if !found {
+		return nil, "", false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, "", false
+	}
+	return value, cache.ETag(data), true
+}
+
+// CheckETag reports whether etag is the ETag of the JSON encoding of the value of key.
+func (c *Cache) CheckETag(ctx context.Context, key string, etag string) bool {
+	value, found, _ := c.get(ctx, OpCheckETag, key)
+	if !found || etag == "" {
+		return false
+	}
+	data, err := json.Marshal(value)
+	return err == nil && cache.ETag(data) == etag
+}
+
+// Prefetch records the call. Values are already in memory, so nothing is warmed.
+func (c *Cache) Prefetch(ctx context.Context, keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		c.record(Call{Op: OpPrefetch, Key: key})
+	}
+}
+
+// Warmup records the call. Values are already in memory, so nothing is warmed.
+func (c *Cache) Warmup(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		c.record(Call{Op: OpWarmup, Key: key})
+	}
+	return c.check(OpWarmup, "")
+}
+
+// RegisterLoader registers a loader used by Get on misses for keys matching pattern.
+func (c *Cache) RegisterLoader(pattern string, loader cache.LoaderFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpRegisterLoader, Key: pattern})
+	c.loaders[pattern] = loader
+}
+
+// RefreshAhead records the call. Keys are not refreshed; call Set to simulate a refresh.
+func (c *Cache) RefreshAhead(key string, interval time.Duration, loader cache.LoaderFunc) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpRefreshAhead, Key: key})
+	return c.check(OpRefreshAhead, key)
+}
+
+// Set stores a value. Options are accepted but ignored.
+func (c *Cache) Set(ctx context.Context, key string, value any, opts ...cache.SetOption) error {
+	return c.set(OpSet, key, value)
+}
+
+// SetWithInvalidate stores a value. There are no other pods to invalidate.
+func (c *Cache) SetWithInvalidate(ctx context.Context, key string, value any) error {
+	return c.set(OpSetWithInvalidate, key, value)
+}
+
+// set records and applies a Set or SetWithInvalidate call.
+func (c *Cache) set(op Op, key string, value any) error {
+	c.mu.Lock()
+	c.record(Call{Op: op, Key: key, Value: value})
+	if err := c.check(op, key); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	c.values[key] = value
+	fns := c.subscribers()
+	c.mu.Unlock()
+	notify(fns, cache.ChangeEvent{Type: cache.ChangeSet, Key: key, Value: value, Source: cache.SourceSet})
+	return nil
+}
+
+// SetAck stores a value and returns the number of pods scripted with SetAcks,
+// with cache.ErrInsufficientAcks when it is below minAcks. It does not wait.
+func (c *Cache) SetAck(ctx context.Context, key string, value any, minAcks int, timeout time.Duration) (int, error) {
+	if err := c.set(OpSetAck, key, value); err != nil {
+		return 0, err
+	}
+	c.mu.Lock()
+	acks := c.acks
+	c.mu.Unlock()
+	if acks < minAcks {
+		return acks, cache.ErrInsufficientAcks
+	}
+	return acks, nil
+}
+
+// SetIfVersion stores a value only if its version equals expectedVersion and
+// returns the new version. On conflict it returns the current version and
+// cache.ErrVersionConflict. Values stored by Seed and Set have version 0.
+func (c *Cache) SetIfVersion(ctx context.Context, key string, value any, expectedVersion uint64) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpSetIfVersion, Key: key, Value: value})
+	if err := c.check(OpSetIfVersion, key); err != nil {
+		return 0, err
+	}
+	if current := c.versions[key]; current != expectedVersion {
+		return current, cache.ErrVersionConflict
+	}
+	c.values[key] = value
+	c.versions[key]++
+	return c.versions[key], nil
+}
+
+// Version returns the version of key, as used by SetIfVersion.
+func (c *Cache) Version(ctx context.Context, key string) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpVersion, Key: key})
+	if err := c.check(OpVersion, key); err != nil {
+		return 0, err
+	}
+	return c.versions[key], nil
+}
+
+// Delete removes a value.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	c.record(Call{Op: OpDelete, Key: key})
+	if err := c.check(OpDelete, key); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	delete(c.values, key)
+	fns := c.subscribers()
+	c.mu.Unlock()
+	notify(fns, cache.ChangeEvent{Type: cache.ChangeDeleted, Key: key})
+	return nil
+}
+
+// DeleteGroup records the call. Options passed to Set are ignored, so groups
+// are not tracked and no value is removed; call Delete to simulate it.
+func (c *Cache) DeleteGroup(ctx context.Context, group string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpDeleteGroup, Key: group})
+	return c.check(OpDeleteGroup, group)
+}
+
+// Clear removes all values.
+func (c *Cache) Clear(ctx context.Context) error {
+	c.mu.Lock()
+	c.record(Call{Op: OpClear})
+	if err := c.check(OpClear, ""); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	c.values = make(map[string]any)
+	c.versions = make(map[string]uint64)
+	fns := c.subscribers()
+	c.mu.Unlock()
+	notify(fns, cache.ChangeEvent{Type: cache.ChangeCleared})
+	return nil
+}
+
+// DumpRemote writes every value, sorted by key and encoded as JSON, in the
+// format of cache.SyncedCache.DumpRemote.
+func (c *Cache) DumpRemote(ctx context.Context, w io.Writer) error {
+	c.mu.Lock()
+	c.record(Call{Op: OpDumpRemote})
+	if err := c.check(OpDumpRemote, ""); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	entries := make([]cache.DumpEntry, 0, len(c.values))
+	for key, value := range c.values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			c.mu.Unlock()
+			return err
+		}
+		entries = append(entries, cache.DumpEntry{Key: key, Value: data})
+	}
+	c.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	enc := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreRemote stores the entries written by DumpRemote, decoding their values
+// from JSON.
+func (c *Cache) RestoreRemote(ctx context.Context, r io.Reader) error {
+	c.mu.Lock()
+	c.record(Call{Op: OpRestoreRemote})
+	err := c.check(OpRestoreRemote, "")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(r)
+	for {
+		var entry cache.DumpEntry
+		if err := dec.Decode(&entry); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		var value any
+		if err := json.Unmarshal(entry.Value, &value); err != nil {
+			return err
+		}
+		c.mu.Lock()
+		c.values[entry.Key] = value
+		fns := c.subscribers()
+		c.mu.Unlock()
+		notify(fns, cache.ChangeEvent{Type: cache.ChangeInvalidated, Key: entry.Key})
+	}
+}
+
+// Lock acquires an in-memory lock on key, held for at most ttl, blocking until
+// it is acquired or ctx is done.
+func (c *Cache) Lock(ctx context.Context, key string, ttl time.Duration) (cache.Lock, error) {
+	c.mu.Lock()
+	c.record(Call{Op: OpLock, Key: key})
+	if err := c.check(OpLock, key); err != nil {
+		c.mu.Unlock()
+		return nil, err
+	}
+	c.mu.Unlock()
+	if ttl <= 0 {
+		return nil, cache.ErrInvalidLockTTL
+	}
+	if strings.HasPrefix(key, "\x00") {
+		return nil, cache.ErrReservedLockKey
+	}
+
+	for {
+		c.mu.Lock()
+		held := c.locks[key]
+		if held == nil || time.Now().After(held.expires) {
+			l := &lock{cache: c, key: key, expires: time.Now().Add(ttl)}
+			c.locks[key] = l
+			c.mu.Unlock()
+			return l, nil
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, cache.ErrLockNotAcquired
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+// Watch returns a channel receiving the events passed to Announce.
+// It is closed when ctx is done or the cache is closed.
+func (c *Cache) Watch(ctx context.Context) <-chan cache.LifecycleEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpWatch})
+	ch := make(chan cache.LifecycleEvent, 64)
+	if c.closed {
+		close(ch)
+		return ch
+	}
+	c.watchers = append(c.watchers, ch)
+	context.AfterFunc(ctx, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.removeWatcher(ch)
+	})
+	return ch
+}
+
+// Subscribe registers fn to be called after each Set, SetWithInvalidate, Delete
+// and Clear, until the returned function is called.
+func (c *Cache) Subscribe(fn func(event cache.ChangeEvent)) (unsubscribe func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpSubscribe})
+	c.nextID++
+	id := c.nextID
+	c.changes[id] = fn
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.changes, id)
+	}
+}
+
+// subscribers returns the functions registered with Subscribe. It must be called with c.mu held.
+func (c *Cache) subscribers() []func(event cache.ChangeEvent) {
+	fns := make([]func(event cache.ChangeEvent), 0, len(c.changes))
+	for _, fn := range c.changes {
+		fns = append(fns, fn)
+	}
+	return fns
+}
+
+// notify calls fns with event. It is called without c.mu held, so they may use the Cache.
+func notify(fns []func(event cache.ChangeEvent), event cache.ChangeEvent) {
+	for _, fn := range fns {
+		fn(event)
+	}
+}
+
+// Announce delivers a lifecycle event from pod "cachetest" to the Watch channels.
+func (c *Cache) Announce(ctx context.Context, eventType cache.LifecycleType, details map[string]string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpAnnounce, Key: string(eventType), Value: details})
+	if err := c.check(OpAnnounce, string(eventType)); err != nil {
+		return err
+	}
+	event := cache.LifecycleEvent{Type: eventType, PodID: "cachetest", Time: time.Now(), Details: details}
+	for _, ch := range c.watchers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+// LocalKeys returns the stored keys in sorted order.
+func (c *Cache) LocalKeys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpLocalKeys})
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// RemoteKeys calls fn once with the stored keys starting with prefix, in key
+// order. fn may call back into the cache.
+func (c *Cache) RemoteKeys(ctx context.Context, prefix string, fn func(keys []string) error) error {
+	c.mu.Lock()
+	c.record(Call{Op: OpRemoteKeys, Key: prefix})
+	if err := c.check(OpRemoteKeys, ""); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	var keys []string
+	for key := range c.values {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+	return fn(keys)
+}
+
+// IterateLocal calls fn for each stored value in key order, stopping when fn
+// returns false. fn may call back into the cache.
+func (c *Cache) IterateLocal(fn func(key string, value any) bool) {
+	c.mu.Lock()
+	c.record(Call{Op: OpIterateLocal})
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values := make([]any, len(keys))
+	for i, key := range keys {
+		values[i] = c.values[key]
+	}
+	c.mu.Unlock()
+
+	for i, key := range keys {
+		if !fn(key, values[i]) {
+			return
+		}
+	}
+}
+
+// EstimateMemory measures every stored value by its JSON encoding, with a cost
+// of its size. sampleRate is ignored.
+func (c *Cache) EstimateMemory(sampleRate int) cache.MemoryEstimate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpEstimateMemory})
+	estimate := cache.MemoryEstimate{Prefixes: make(map[string]cache.PrefixMemory)}
+	for key, value := range c.values {
+		data, _ := json.Marshal(value)
+		size := int64(len(key) + len(data))
+		prefix := ""
+		if i := strings.IndexByte(key, ':'); i >= 0 {
+			prefix = key[:i+1]
+		}
+		p := estimate.Prefixes[prefix]
+		p.Entries++
+		p.Bytes += size
+		p.Cost += size
+		estimate.Prefixes[prefix] = p
+		estimate.Entries++
+		estimate.Bytes += size
+		estimate.Cost += size
+	}
+	return estimate
+}
+
+// Ping returns the error scripted for OpPing, or cache.ErrCacheClosed once closed.
+func (c *Cache) Ping(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpPing})
+	return c.check(OpPing, "")
+}
+
+// Health reports the cache healthy unless it is closed or an error is scripted for OpHealth.
+func (c *Cache) Health(ctx context.Context) cache.Health {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpHealth})
+	if err := c.check(OpHealth, ""); err != nil {
+		return cache.Health{RedisError: err.Error()}
+	}
+	return cache.Health{Healthy: true, RedisReachable: true, SubscriptionAlive: true, LocalCacheUsable: true}
+}
+
+// Close marks the cache closed; later operations fail with cache.ErrCacheClosed.
+func (c *Cache) Close() error {
+	return c.close(OpClose)
+}
+
+// Shutdown closes the cache like Close. There are no in-flight events to drain.
+func (c *Cache) Shutdown(ctx context.Context) error {
+	return c.close(OpShutdown)
+}
+
+// close records op and closes the cache.
+func (c *Cache) close(op Op) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: op})
+	if err := c.errors[op]; err != nil {
+		return err
+	}
+	c.closed = true
+	for len(c.watchers) > 0 {
+		c.removeWatcher(c.watchers[0])
+	}
+	return nil
+}
+
+// removeWatcher unregisters and closes a Watch channel. c.mu must be held.
+func (c *Cache) removeWatcher(ch chan cache.LifecycleEvent) {
+	for i, watcher := range c.watchers {
+		if watcher == ch {
+			c.watchers = append(c.watchers[:i], c.watchers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+// Stats returns hit and miss counters for Get calls and the number of stored values.
+func (c *Cache) Stats() cache.Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.LocalSize = int64(len(c.values))
+	return stats
+}
+
+// Describe returns a description identifying the in-memory cache.
+func (c *Cache) Describe() cache.Description {
+	return cache.Description{PodID: "cachetest", LocalCacheFactory: "cachetest.Cache"}
+}
+
+// Peers returns the peers scripted with SetPeers.
+func (c *Cache) Peers() []cache.Peer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpPeers})
+	return append([]cache.Peer(nil), c.peers...)
+}
+
+// IsLeader reports whether the cache is the leader, as scripted with SetLeader.
+func (c *Cache) IsLeader() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpIsLeader})
+	return c.leader
+}
+
+// OnLeadershipChange registers fn to be called by SetLeader.
+func (c *Cache) OnLeadershipChange(fn func(leader bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.leaderFns = append(c.leaderFns, fn)
+}
+
+// record appends a call. c.mu must be held.
+func (c *Cache) record(call Call) {
+	c.calls = append(c.calls, call)
+}
+
+// check returns the error an operation should fail with. c.mu must be held.
+func (c *Cache) check(op Op, key string) error {
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+	return c.errorFor(op, key)
+}
+
+// errorFor returns the scripted error for op and key. c.mu must be held.
+func (c *Cache) errorFor(op Op, key string) error {
+	if err := c.keyErrors[op][key]; err != nil {
+		return err
+	}
+	return c.errors[op]
+}
+
+// loaderFor returns the loader for the longest pattern matching key. c.mu must be held.
+func (c *Cache) loaderFor(key string) cache.LoaderFunc {
+	patterns := make([]string, 0, len(c.loaders))
+	for pattern := range c.loaders {
+		if cache.MatchPattern(pattern, key) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		return len(patterns[i]) > len(patterns[j])
+	})
+	return c.loaders[patterns[0]]
+}
+
+// lock is a cache.Lock held in a Cache.
+type lock struct {
+	cache    *Cache
+	key      string
+	expires  time.Time
+	released bool
+}
+
+// Key returns the locked key.
+func (l *lock) Key() string {
+	return l.key
+}
+
+// Unlock releases the lock, or returns cache.ErrLockNotHeld if it expired.
+// Releasing a lock more than once is a no-op.
+func (l *lock) Unlock(ctx context.Context) error {
+	c := l.cache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.record(Call{Op: OpUnlock, Key: l.key})
+	if err := c.errorFor(OpUnlock, l.key); err != nil {
+		return err
+	}
+	if l.released {
+		return nil
+	}
+	l.released = true
+	if c.locks[l.key] != l {
+		return cache.ErrLockNotHeld
+	}
+	delete(c.locks, l.key)
+	if time.Now().After(l.expires) {
+		return cache.ErrLockNotHeld
+	}
+	return nil
+}
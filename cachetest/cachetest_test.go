@@ -0,0 +1,294 @@
+package cachetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/huykn/distributed-cache/cache"
+)
+
+func TestCacheSetGetDelete(t *testing.T) {
+	c := New()
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "user:1", "alice"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	value, found, info := c.GetWithInfo(ctx, "user:1")
+	if !found || value != "alice" || info.Level != cache.LevelLocal {
+		t.Fatalf("Expected local hit of alice, got %v (found=%v, %+v)", value, found, info)
+	}
+
+	if err := c.Delete(ctx, "user:1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, found := c.Get(ctx, "user:1"); found {
+		t.Fatal("Expected miss after Delete")
+	}
+
+	stats := c.Stats()
+	if stats.LocalHits != 1 || stats.LocalMisses != 1 {
+		t.Fatalf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestCacheGetRaw(t *testing.T) {
+	c := New()
+	c.Seed(map[string]any{"user:1": map[string]any{"name": "alice"}})
+
+	raw, found := c.GetRaw(context.Background(), "user:1")
+	if !found || string(raw) != `{"name":"alice"}` {
+		t.Fatalf("Expected the JSON value, got %s (found=%v)", raw, found)
+	}
+	if calls := c.Calls(); len(calls) != 1 || calls[0].Op != OpGetRaw {
+		t.Fatalf("Expected a recorded GetRaw call, got %+v", calls)
+	}
+}
+
+func TestCacheETag(t *testing.T) {
+	c := New()
+	c.Seed(map[string]any{"user:1": "alice"})
+	ctx := context.Background()
+
+	_, etag, found := c.GetWithETag(ctx, "user:1")
+	if !found || etag != cache.ETag([]byte(`"alice"`)) {
+		t.Fatalf("Unexpected ETag %s (found=%v)", etag, found)
+	}
+	if !c.CheckETag(ctx, "user:1", etag) {
+		t.Fatal("Expected the ETag to match")
+	}
+	c.Seed(map[string]any{"user:1": "bob"})
+	if c.CheckETag(ctx, "user:1", etag) {
+		t.Fatal("Expected the ETag not to match a new value")
+	}
+}
+
+func TestCacheScriptedBehavior(t *testing.T) {
+	c := New()
+	ctx := context.Background()
+	c.Seed(map[string]any{"user:1": "alice", "user:2": "bob"})
+
+	c.ForceMiss("user:2")
+	if _, found := c.Get(ctx, "user:2"); found {
+		t.Fatal("Expected forced miss")
+	}
+
+	errWrite := errors.New("write failed")
+	c.FailOnKey(OpSet, "user:3", errWrite)
+	if err := c.Set(ctx, "user:3", "carol"); err != errWrite {
+		t.Fatalf("Expected scripted error, got %v", err)
+	}
+	if err := c.Set(ctx, "user:4", "dave"); err != nil {
+		t.Fatalf("Only user:3 should fail, got %v", err)
+	}
+
+	c.FailOn(OpClear, errWrite)
+	if err := c.Clear(ctx); err != errWrite {
+		t.Fatalf("Expected scripted Clear error, got %v", err)
+	}
+	c.FailOn(OpClear, nil)
+	if err := c.Clear(ctx); err != nil {
+		t.Fatalf("Expected Clear to succeed once the failure is removed, got %v", err)
+	}
+}
+
+func TestCacheRecordsCalls(t *testing.T) {
+	c := New()
+	ctx := context.Background()
+
+	_ = c.Set(ctx, "k", 1)
+	c.Get(ctx, "k")
+	c.Prefetch(ctx, "a", "b")
+
+	calls := c.Calls()
+	if len(calls) != 4 {
+		t.Fatalf("Expected 4 calls, got %+v", calls)
+	}
+	if calls[0] != (Call{Op: OpSet, Key: "k", Value: 1}) {
+		t.Fatalf("Unexpected first call: %+v", calls[0])
+	}
+	if prefetches := c.CallsTo(OpPrefetch); len(prefetches) != 2 || prefetches[1].Key != "b" {
+		t.Fatalf("Unexpected prefetch calls: %+v", prefetches)
+	}
+
+	c.Reset()
+	if len(c.Calls()) != 0 || len(c.Values()) != 0 {
+		t.Fatal("Reset should clear calls and values")
+	}
+}
+
+func TestCacheLoader(t *testing.T) {
+	c := New()
+	ctx := context.Background()
+
+	c.RegisterLoader("user:*", func(ctx context.Context, key string) (any, error) {
+		return "loaded-" + key, nil
+	})
+
+	value, found, info := c.GetWithInfo(ctx, "user:1")
+	if !found || value != "loaded-user:1" || info.Level != cache.LevelLoader {
+		t.Fatalf("Expected loader hit, got %v (found=%v, %+v)", value, found, info)
+	}
+	if _, _, info := c.GetWithInfo(ctx, "user:1"); info.Level != cache.LevelLocal {
+		t.Fatalf("Expected loaded value to be stored, got %+v", info)
+	}
+}
+
+func TestCacheClose(t *testing.T) {
+	c := New()
+	ctx := context.Background()
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err := c.Set(ctx, "k", 1); err != cache.ErrCacheClosed {
+		t.Fatalf("Expected ErrCacheClosed, got %v", err)
+	}
+}
+
+func TestCacheLock(t *testing.T) {
+	c := New()
+	ctx := context.Background()
+
+	lock, err := c.Lock(ctx, "counter", time.Second)
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Lock(waitCtx, "counter", time.Second); err != cache.ErrLockNotAcquired {
+		t.Fatalf("Expected ErrLockNotAcquired while held, got %v", err)
+	}
+
+	if err := lock.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if _, err := c.Lock(ctx, "counter", time.Second); err != nil {
+		t.Fatalf("Expected Lock to succeed once released, got %v", err)
+	}
+	if locks := c.CallsTo(OpLock); len(locks) != 3 {
+		t.Fatalf("Expected 3 recorded Lock calls, got %+v", locks)
+	}
+}
+
+func TestCacheSetIfVersion(t *testing.T) {
+	c := New()
+	ctx := context.Background()
+
+	version, err := c.SetIfVersion(ctx, "counter", 1, 0)
+	if err != nil || version != 1 {
+		t.Fatalf("Expected version 1, got %d (err=%v)", version, err)
+	}
+	if version, err := c.SetIfVersion(ctx, "counter", 2, 0); err != cache.ErrVersionConflict || version != 1 {
+		t.Fatalf("Expected ErrVersionConflict at version 1, got %d (err=%v)", version, err)
+	}
+	if value, _ := c.Get(ctx, "counter"); value != 1 {
+		t.Fatalf("Expected value 1, got %v", value)
+	}
+}
+
+func TestCacheWatch(t *testing.T) {
+	c := New()
+	ctx := context.Background()
+	events := c.Watch(ctx)
+
+	if err := c.Announce(ctx, cache.LifecycleConfigReloaded, nil); err != nil {
+		t.Fatalf("Announce failed: %v", err)
+	}
+	if event := <-events; event.Type != cache.LifecycleConfigReloaded {
+		t.Fatalf("Unexpected event: %+v", event)
+	}
+
+	c.Close()
+	if _, ok := <-events; ok {
+		t.Fatal("Expected Watch channel to be closed on Close")
+	}
+}
+
+func TestCacheLocalKeys(t *testing.T) {
+	c := New()
+	ctx := context.Background()
+	_ = c.Set(ctx, "b", 2)
+	_ = c.Set(ctx, "a", 1)
+
+	if keys := c.LocalKeys(); len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("Expected sorted keys, got %v", keys)
+	}
+
+	var visited []string
+	c.IterateLocal(func(key string, value any) bool {
+		visited = append(visited, key)
+		return false
+	})
+	if len(visited) != 1 || visited[0] != "a" {
+		t.Errorf("Expected iteration to stop after the first key, got %v", visited)
+	}
+}
+
+func TestCacheSubscribe(t *testing.T) {
+	c := New()
+	ctx := context.Background()
+
+	var events []cache.ChangeEvent
+	unsubscribe := c.Subscribe(func(event cache.ChangeEvent) {
+		// Subscribers may use the cache
+		c.Values()
+		events = append(events, event)
+	})
+	if err := c.Set(ctx, "user:1", "alice"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := c.Delete(ctx, "user:1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	unsubscribe()
+	if err := c.Clear(ctx); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+
+	if len(events) != 2 || events[0].Type != cache.ChangeSet || events[1].Type != cache.ChangeDeleted {
+		t.Fatalf("Unexpected events %+v", events)
+	}
+}
+
+func TestCacheLeadership(t *testing.T) {
+	c := New()
+	var changes []bool
+	c.OnLeadershipChange(func(leader bool) { changes = append(changes, leader) })
+
+	if c.IsLeader() {
+		t.Fatal("Expected the cache not to lead by default")
+	}
+	c.SetLeader(true)
+	c.SetLeader(true)
+	c.SetLeader(false)
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Fatalf("Expected one notification per change, got %v", changes)
+	}
+}
+
+func TestCacheDumpAndRestoreRemote(t *testing.T) {
+	source := New()
+	source.Seed(map[string]any{"user:1": "alice", "user:2": map[string]any{"name": "bob"}})
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	if err := source.DumpRemote(ctx, &buf); err != nil {
+		t.Fatalf("DumpRemote failed: %v", err)
+	}
+
+	target := New()
+	if err := target.RestoreRemote(ctx, &buf); err != nil {
+		t.Fatalf("RestoreRemote failed: %v", err)
+	}
+	if value, found := target.Get(ctx, "user:1"); !found || value != "alice" {
+		t.Errorf("Expected user:1 to be restored, got %v (found=%v)", value, found)
+	}
+	if value, found := target.Get(ctx, "user:2"); !found || value.(map[string]any)["name"] != "bob" {
+		t.Errorf("Expected user:2 to be restored, got %v (found=%v)", value, found)
+	}
+}
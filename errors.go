@@ -1,24 +1,134 @@
 package distributedcache
 
-import "errors"
+import (
+	"github.com/huykn/distributed-cache/cache"
+	"github.com/huykn/distributed-cache/storage"
+)
 
-// ErrNotFound is returned when a key is not found in the cache.
-var ErrNotFound = errors.New("key not found")
+// ErrNotFound is returned by stores when a key does not exist in Redis.
+var ErrNotFound = storage.ErrNotFound
 
 // ErrCacheClosed is returned when operations are performed on a closed cache.
-var ErrCacheClosed = errors.New("cache is closed")
+var ErrCacheClosed = cache.ErrCacheClosed
 
-// ErrInvalidConfig is returned when the cache configuration is invalid.
-var ErrInvalidConfig = errors.New("invalid cache configuration")
+// ErrInvalidConfig is returned by New when the cache configuration is invalid.
+var ErrInvalidConfig = cache.ErrInvalidConfig
 
-// ErrSerializationFailed is returned when serialization fails.
-var ErrSerializationFailed = errors.New("serialization failed")
+// ErrSerializationFailed is wrapped by errors of Set and SetIfVersion when the
+// value cannot be serialized. It is ErrSerialization.
+var ErrSerializationFailed = cache.ErrSerialization
 
-// ErrDeserializationFailed is returned when deserialization fails.
-var ErrDeserializationFailed = errors.New("deserialization failed")
+// ErrDeserializationFailed is wrapped by errors reported via OnErrorEx when a
+// value cannot be deserialized. It is ErrDeserialization.
+var ErrDeserializationFailed = cache.ErrDeserialization
 
-// ErrRedisConnection is returned when Redis connection fails.
-var ErrRedisConnection = errors.New("redis connection failed")
+// ErrRedisConnection is wrapped by errors of New, Set, SetIfVersion, Version,
+// Delete and Clear when Redis cannot be reached or the command fails. It is
+// ErrRemoteStore.
+var ErrRedisConnection = cache.ErrRemoteStore
 
-// ErrPubSubFailed is returned when pub/sub operations fail.
-var ErrPubSubFailed = errors.New("pub/sub operation failed")
+// ErrPubSubFailed is wrapped by errors reported via OnErrorEx when a
+// synchronization event cannot be published. It is ErrPublish; subscription
+// failures wrap ErrSubscription instead.
+var ErrPubSubFailed = cache.ErrPublish
+
+// ErrTimeout is reported via OnError when a Get gives up on the remote store
+// because its context deadline or RemoteGetTimeout expired.
+var ErrTimeout = cache.ErrTimeout
+
+// ErrLockNotAcquired is returned by Lock when the lock is still held by another
+// owner once the context is done.
+var ErrLockNotAcquired = cache.ErrLockNotAcquired
+
+// ErrLockNotHeld is returned by Unlock when the lock expired before it was released.
+var ErrLockNotHeld = cache.ErrLockNotHeld
+
+// ErrReservedLockKey is returned by Lock for keys starting with a NUL byte, which
+// are reserved for the locks the cache takes for itself, such as the leader lease.
+var ErrReservedLockKey = cache.ErrReservedLockKey
+
+// ErrInsufficientAcks is returned by SetAck when fewer pods than requested
+// confirmed the value before the timeout.
+var ErrInsufficientAcks = cache.ErrInsufficientAcks
+
+// ErrReadOnly is returned by write operations when the cache is read-only.
+var ErrReadOnly = cache.ErrReadOnly
+
+// ErrVersionConflict is returned by SetIfVersion when the stored version does not
+// match the expected version.
+var ErrVersionConflict = cache.ErrVersionConflict
+
+// ErrCircuitOpen is returned by writes while the circuit breaker around Redis is
+// open and DegradedWritePolicy is DegradedWriteFailFast.
+var ErrCircuitOpen = cache.ErrCircuitOpen
+
+// ErrDegradedQueueFull is returned by writes while the circuit breaker is open and
+// the DegradedWriteQueue queue is full.
+var ErrDegradedQueueFull = cache.ErrDegradedQueueFull
+
+// ErrTTLNotSupported is returned by Set with WithTTL when the store cannot expire
+// values, or when WithTTL is combined with WithVersion.
+var ErrTTLNotSupported = cache.ErrTTLNotSupported
+
+// ErrDumpNotSupported is returned by DumpRemote when the store cannot scan keys,
+// and by DumpRemote and RestoreRemote when DisableRemoteStore is set.
+var ErrDumpNotSupported = cache.ErrDumpNotSupported
+
+// ErrScanNotSupported is returned by RemoteKeys when the store cannot scan keys,
+// or when DisableRemoteStore is set.
+var ErrScanNotSupported = cache.ErrScanNotSupported
+
+// ErrSchemaMismatch is wrapped by errors reported via OnErrorEx when a value
+// written at another schema version cannot be decoded into its registered type.
+var ErrSchemaMismatch = cache.ErrSchemaMismatch
+
+// ErrGroupsNotSupported is returned by DeleteGroup, and by Set with WithGroup,
+// when the store cannot group keys.
+var ErrGroupsNotSupported = cache.ErrGroupsNotSupported
+
+// ErrPublishQueueFull is passed to OnPublishDropped for synchronization events
+// dropped because the publish retry queue was full.
+var ErrPublishQueueFull = cache.ErrPublishQueueFull
+
+// ErrRateLimited is returned by Set and Delete when their Redis write exceeds
+// WriteRateLimit.
+var ErrRateLimited = cache.ErrRateLimited
+
+// ErrInvalidRefresh is returned by RefreshAhead for a negative interval or a
+// nil loader.
+var ErrInvalidRefresh = cache.ErrInvalidRefresh
+
+// ErrValueTooLarge is returned by Set when the serialized value is larger than
+// MaxValueSize.
+var ErrValueTooLarge = cache.ErrValueTooLarge
+
+// ErrWarmupNotSupported is reported via OnError when WarmupPattern is set but
+// the store cannot scan keys.
+var ErrWarmupNotSupported = cache.ErrWarmupNotSupported
+
+// ErrDuplicatePodID is reported via OnError when another pod publishes with the
+// same PodID.
+var ErrDuplicatePodID = cache.ErrDuplicatePodID
+
+// ErrInjectedFault is returned by Redis commands failed by RandomFaults.
+var ErrInjectedFault = cache.ErrInjectedFault
+
+// ErrSerialization is wrapped by errors passed to OnErrorEx when a value cannot
+// be serialized.
+var ErrSerialization = cache.ErrSerialization
+
+// ErrDeserialization is wrapped by errors passed to OnErrorEx when a value
+// cannot be deserialized.
+var ErrDeserialization = cache.ErrDeserialization
+
+// ErrRemoteStore is wrapped by errors passed to OnErrorEx when a Redis operation
+// fails or times out.
+var ErrRemoteStore = cache.ErrRemoteStore
+
+// ErrPublish is wrapped by errors passed to OnErrorEx when a synchronization
+// event cannot be published.
+var ErrPublish = cache.ErrPublish
+
+// ErrSubscription is wrapped by errors passed to OnErrorEx when the sync
+// subscription cannot be confirmed.
+var ErrSubscription = cache.ErrSubscription
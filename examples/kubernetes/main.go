@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/huykn/distributed-cache/cache"
@@ -29,6 +31,15 @@ func init() {
 		cfg.Cache.PodID = podName
 	}
 
+	// Decode products read from Redis or propagated by other pods as Product,
+	// the type they were set with, rather than as map[string]any
+	cfg.Cache.DecodeInto = func(key string) any {
+		if strings.HasPrefix(key, "product:") {
+			return &Product{}
+		}
+		return nil
+	}
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		log.Fatalf("Invalid configuration: %v", err)
@@ -59,10 +70,10 @@ func getProduct(w http.ResponseWriter, r *http.Request) {
 
 	// Try to get from cache
 	value, found := globalCache.Get(ctx, key)
-	if found {
+	if product, ok := value.(Product); found && ok {
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("X-Cache", "HIT")
-		fmt.Fprintf(w, `{"product": %v, "cached": true}`, value)
+		json.NewEncoder(w).Encode(map[string]any{"product": product, "cached": true})
 		return
 	}
 
@@ -81,7 +92,7 @@ func getProduct(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("X-Cache", "MISS")
-	fmt.Fprintf(w, `{"product": %v, "cached": false}`, product)
+	json.NewEncoder(w).Encode(map[string]any{"product": product, "cached": false})
 }
 
 // updateProduct updates a product and propagates the new value to all pods.
@@ -151,10 +162,18 @@ func stats(w http.ResponseWriter, r *http.Request) {
 	}`, stats.LocalHits, stats.LocalMisses, stats.RemoteHits, stats.RemoteMisses, stats.Invalidations)
 }
 
-// health returns health status.
+// health returns the cache health, failing with 503 so the pod is taken out of
+// rotation while Redis or the sync subscription is down.
 func health(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+	defer cancel()
+
+	status := globalCache.Health(ctx)
 	w.Header().Set("Content-Type", "application/json")
-	fmt.Fprintf(w, `{"status": "healthy"}`)
+	if !status.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
 }
 
 func main() {
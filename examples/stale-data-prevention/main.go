@@ -234,7 +234,10 @@ func createCache(podID string, canWrite bool, logger cache.Logger, detector *Sta
 	cfg.InvalidationChannel = "verification-test"
 	cfg.DebugMode = false
 	cfg.Logger = cache.NewConsoleLogger("quiet") // Reduce noise
-	cfg.ReaderCanSetToRedis = canWrite
+	cfg.WritePolicy = dc.WritePolicyLocalOnly
+	if canWrite {
+		cfg.WritePolicy = dc.WritePolicyWriteThrough
+	}
 
 	cfg.OnSetLocalCache = func(event dc.InvalidationEvent) any {
 		var data VersionedData
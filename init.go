@@ -1,8 +1,12 @@
 package distributedcache
 
 import (
+	"crypto/tls"
+	"log/slog"
 	"time"
 
+	"github.com/redis/go-redis/v9"
+
 	"github.com/huykn/distributed-cache/cache"
 )
 
@@ -19,18 +23,122 @@ type Config struct {
 	// If nil, defaults to Ristretto factory.
 	LocalCacheFactory LocalCacheFactory
 
+	// LocalCacheShards splits the local cache into this many shards by key hash to
+	// reduce lock contention. Each shard gets the full factory configuration.
+	LocalCacheShards int
+
+	// SpilloverDir keeps values costing at least SpilloverThreshold (default 1MB)
+	// in files under this directory, using at most SpilloverMaxBytes (default 1GB).
+	SpilloverDir       string
+	SpilloverThreshold int64
+	SpilloverMaxBytes  int64
+
 	// RedisAddr is the Redis server address (e.g., "localhost:6379").
 	RedisAddr string
 
+	// RedisUsername is the optional Redis ACL username (Redis 6+).
+	RedisUsername string
+
 	// RedisPassword is the optional Redis password.
 	RedisPassword string
 
+	// RedisTLSConfig enables TLS for connections to Redis (e.g. managed Redis services).
+	RedisTLSConfig *tls.Config
+
 	// RedisDB is the Redis database number.
 	RedisDB int
 
+	// RedisPoolSize is the maximum number of connections to Redis. 0 uses the go-redis default.
+	RedisPoolSize int
+
+	// RedisMinIdleConns is the number of idle connections kept open to Redis.
+	RedisMinIdleConns int
+
+	// RedisDialTimeout, RedisReadTimeout and RedisWriteTimeout bound connecting to Redis
+	// and socket reads and writes. 0 uses the go-redis defaults.
+	RedisDialTimeout  time.Duration
+	RedisReadTimeout  time.Duration
+	RedisWriteTimeout time.Duration
+
+	// RedisClient is a pre-built Redis client (e.g. *redis.ClusterClient) used instead
+	// of the address, credentials and pool settings above. The cache does not close it.
+	RedisClient redis.UniversalClient
+
+	// ConnectionManager shares one Redis client and one Pub/Sub connection between
+	// caches, e.g. one per tenant, each with its own InvalidationChannel and Namespace.
+	ConnectionManager *ConnectionManager
+
+	// RedisPartitions spreads keys over several Redis instances with consistent hashing,
+	// using the credentials and pool settings above for each address.
+	RedisPartitions []string
+
+	// PartitionVNodes is the number of hash ring points per partition (default 160) and
+	// PartitionReplicas the number of partitions each key is written to (default 1).
+	PartitionVNodes   int
+	PartitionReplicas int
+
+	// Namespace is a prefix applied to every key stored in Redis, scoping Clear
+	// to this cache's keys. Without it, Clear flushes the entire Redis database.
+	Namespace string
+
+	// DangerousFullFlush makes Clear flush the entire Redis database even when Namespace
+	// is set, including keys written by other applications. Every such flush is logged as a warning.
+	DangerousFullFlush bool
+
+	// SyncRedisAddr moves synchronization to its own connection pool on this address
+	// (e.g. a replica), isolating data operations from slow subscriptions.
+	SyncRedisAddr string
+
+	// SyncRedisClient is a pre-built client used for synchronization instead of SyncRedisAddr.
+	SyncRedisClient redis.UniversalClient
+
 	// InvalidationChannel is the Redis pub/sub channel for cache invalidation.
 	InvalidationChannel string
 
+	// SyncTransport selects the transport for synchronization events: SyncTransportPubSub
+	// (default) or SyncTransportStreams, where a restarting pod with the same PodID replays
+	// the events it missed. Streams are trimmed to approximately StreamMaxLen entries (default 10000).
+	SyncTransport SyncTransport
+	StreamMaxLen  int64
+
+	// EventEncoding selects how published events are encoded: EventEncodingJSON (default)
+	// or the compact EventEncodingBinary. Switch to binary once every pod can decode it.
+	EventEncoding EventEncoding
+
+	// PayloadEnvelope wraps stored values in an envelope recording their Marshaller,
+	// schema version, compression, creation time and origin pod, reported by GetWithInfo.
+	PayloadEnvelope bool
+
+	// MarshallerRules maps key patterns (e.g. "feed:*") to the Marshaller serializing
+	// matching keys instead of Marshaller. The longest matching pattern wins.
+	MarshallerRules map[string]KeyMarshaller
+
+	// PropagationRules maps key patterns (e.g. "session:*") to how Set announces matching
+	// keys to other pods: PropagateValue, PropagateInvalidate or PropagateNone.
+	// The longest matching pattern wins; per-call options override rules.
+	PropagationRules map[string]Propagation
+
+	// ReceiveOwnEvents makes caches sharing a PodID, e.g. several caches in one process,
+	// apply each other's events. Every cache sharing the PodID must set it.
+	ReceiveOwnEvents bool
+
+	// PrefixChannels maps key prefixes to dedicated pub/sub channels.
+	// Events for keys matching a prefix are published on the mapped channel instead of InvalidationChannel.
+	PrefixChannels map[string]string
+
+	// PatternChannels are channel patterns (e.g. "cache:invalidate:*") also subscribed to,
+	// receiving the events of several namespaces or tenants through one subscription.
+	PatternChannels []string
+
+	// AdditionalChannels are channels Set, Delete and Clear also publish their events to,
+	// e.g. for audit or change data capture consumers. Not supported with ConsistencyOutbox.
+	AdditionalChannels []string
+
+	// ExternalFormats maps key prefixes to the encoding used by other systems writing
+	// those keys to Redis (hash, string under another prefix, msgpack, ...).
+	// Matching keys are read-through only: they are never written or deleted in Redis.
+	ExternalFormats map[string]ExternalFormat
+
 	// SerializationFormat specifies how values are serialized ("json" or "msgpack").
 	SerializationFormat string
 
@@ -45,55 +153,363 @@ type Config struct {
 	// DebugMode enables debug logging.
 	DebugMode bool
 
+	// DebugSampleRate is the fraction of Debug records logged in DebugMode, in (0, 1].
+	// 0 logs every record.
+	DebugSampleRate float64
+
+	// DebugCategories limits DebugMode logging to the listed categories. Empty logs every category.
+	DebugCategories []DebugCategory
+
 	// ContextTimeout is the default timeout for cache operations.
 	ContextTimeout time.Duration
 
-	// EnableMetrics enables metrics collection.
+	// StaleWhileRevalidate enables stale-while-revalidate with this maximum staleness:
+	// invalidated values are served flagged as stale while being refreshed in the background.
+	// When 0 (default), invalidated values are dropped immediately.
+	StaleWhileRevalidate time.Duration
+
+	// LocalTTL is how long values stay fresh in the local cache before being
+	// revalidated from Redis, guarding against missed synchronization events.
+	LocalTTL time.Duration
+
+	// RemoteGetTimeout bounds how long Get waits on the remote store after a local miss.
+	// Timeouts are reported via OnError as ErrTimeout. When 0 (default), ContextTimeout is used.
+	RemoteGetTimeout time.Duration
+
+	// EnableMetrics enables metrics collection, such as the latency histograms of
+	// Stats.Latencies.
 	EnableMetrics bool
 
 	// OnError is called when an error occurs in background operations.
 	OnError func(error)
 
+	// OnErrorEx is called with the same errors as OnError, together with the
+	// operation that failed (OpGet, OpSet...) and the key involved. The error wraps
+	// ErrSerialization, ErrDeserialization, ErrRemoteStore, ErrPublish or ErrSubscription.
+	OnErrorEx func(op, key string, err error)
+
+	// WritePolicy selects which levels Set writes and which event it publishes.
+	// When empty (default), it is derived from ReaderCanSetToRedis and WriteBehind.
+	WritePolicy WritePolicy
+
+	// DisableRemoteStore uses Redis only as a message bus: values are never read
+	// from or written to Redis, but Set and Delete still propagate to other pods.
+	DisableRemoteStore bool
+
+	// ReadOnly makes Set, SetIfVersion, Delete and Clear return ErrReadOnly, for
+	// reader-tier pods that must never write. Get and received events still work.
+	ReadOnly bool
+
 	// ReaderCanSetToRedis controls whether reader nodes are allowed to write data to Redis.
 	// When false (default), reader nodes will only update local cache but NOT write to Redis.
+	//
+	// Deprecated: Use WritePolicy, which takes precedence when set.
 	ReaderCanSetToRedis bool
 
+	// ConsistencyMode selects the order of the local, Redis and publish steps of Set.
+	// Defaults to ConsistencyLocalFirst; see ConsistencyOutbox for atomic store+publish.
+	ConsistencyMode ConsistencyMode
+
+	// RollbackLocalOnError removes the value from the local cache when the Redis
+	// write of a Set fails under ConsistencyLocalFirst.
+	RollbackLocalOnError bool
+
+	// ShouldCacheLocally reports whether this pod keeps key in its local cache, e.g. only
+	// the keys of its shard behind a sharded load balancer. Other keys are served from Redis.
+	ShouldCacheLocally func(key string) bool
+
+	// EventFilter drops the "set", "invalidate" and "delete" events received from other pods
+	// for which it returns false, e.g. on pods serving only a shard of the keys.
+	EventFilter func(event InvalidationEvent) bool
+
 	// OnSetLocalCache is a callback for custom processing of data before storing in local cache.
 	// This callback is invoked when an invalidation event with action "set" is received.
 	// When nil (default), the default behavior is used: unmarshal the value and store in local cache.
 	OnSetLocalCache func(event InvalidationEvent) any
+
+	// CostFunc computes the local cache cost of an entry from its key, value and serialized bytes.
+	// When nil (default), the cost is the serialized size in bytes.
+	CostFunc func(key string, value any, serialized []byte) int64
+
+	// DecodeInto returns a pointer to a new value of the type the value of key is
+	// decoded into, e.g. &User{}, so values read from Redis or received from
+	// other pods keep their type. When nil (default), values are decoded into an any.
+	DecodeInto func(key string) any
+
+	// TypeRegistry registers the Go type and schema version of the values of key
+	// prefixes, converting values written at other versions during rolling deploys.
+	TypeRegistry *TypeRegistry
+
+	// LocalValueMode selects whether the local cache holds decoded values, their
+	// serialized bytes, or both. When empty, LocalValueDecoded is used.
+	LocalValueMode LocalValueMode
+
+	// KeepRawBytes keeps the serialized bytes of local entries so GetRaw serves
+	// them without marshalling the value again.
+	KeepRawBytes bool
+
+	// ComputeETags computes the ETag of local entries when they are stored, so
+	// GetWithETag and CheckETag serve local hits without hashing the value.
+	ComputeETags bool
+
+	// RejectedSetPolicy selects what happens when the local cache rejects or drops a Set.
+	// When empty, RejectedSetLog is used.
+	RejectedSetPolicy RejectedSetPolicy
+
+	// PrefetchHint is an optional predictive prefetcher returning keys related to an
+	// accessed key, which are warmed into the local cache in the background.
+	PrefetchHint func(key string) []string
+
+	// HotKeys enables a hot list of the top accessed keys persisted in Redis per Namespace,
+	// updated every HotKeysInterval (default 10s), which new pods prefetch at startup.
+	// When 0 (default), no hot list is kept.
+	HotKeys         int
+	HotKeysInterval time.Duration
+
+	// WarmupKeys and the keys matching the glob WarmupPattern are loaded from Redis
+	// into the local cache before New returns, bounded by ContextTimeout.
+	WarmupKeys    []string
+	WarmupPattern string
+
+	// PeerWarmup makes a new pod request a snapshot of the local cache of an existing
+	// pod over the sync channel before New returns, for at most PeerWarmupTimeout
+	// (default ContextTimeout). Set it on every pod so existing pods answer.
+	PeerWarmup        bool
+	PeerWarmupTimeout time.Duration
+
+	// AntiEntropyInterval makes pods exchange digests of their local caches at this
+	// interval and fetch again the entries that differ, bounding staleness after lost events.
+	AntiEntropyInterval time.Duration
+
+	// BloomFilterInterval makes pods keep a Bloom filter of the keys in Redis, rebuilt at
+	// this interval, to skip Redis reads of keys that do not exist. BloomFilterKeys sizes it.
+	BloomFilterInterval time.Duration
+	BloomFilterKeys     int
+
+	// HeartbeatInterval makes pods publish a heartbeat at this interval, so that
+	// pods sharing a PodID are detected and reported as ErrDuplicatePodID.
+	HeartbeatInterval time.Duration
+
+	// LeaderElection makes pods compete for a lease in Redis so that a single pod at
+	// a time reports IsLeader, held for LeaderLeaseTTL (default 15s) unless renewed.
+	LeaderElection bool
+	LeaderLeaseTTL time.Duration
+
+	// TTLJitterPercent randomizes each TTL set with WithTTL by up to this percentage
+	// in either direction, spreading the expiry of keys written together.
+	TTLJitterPercent int
+
+	// MaxPropagationSize is the largest serialized value, in bytes, propagated to other
+	// pods; larger values are propagated as invalidations. When 0, there is no limit.
+	MaxPropagationSize int
+
+	// RelayRemoteHits makes a pod reading a value from Redis after a local miss
+	// publish it to the pods that do not hold it, warming them with a single read.
+	RelayRemoteHits bool
+
+	// MaxClockSkew bounds how far ahead of the wall clock the stamp of a received
+	// event may be before it is ignored. When 0, 1 minute is used.
+	MaxClockSkew time.Duration
+
+	// MaxValueSize is the largest serialized value, in bytes, that Set stores; larger
+	// values are rejected with ErrValueTooLarge. When 0, there is no limit.
+	MaxValueSize int
+
+	// PropagationWorkers is the number of workers used to deserialize and apply
+	// incoming synchronization events in parallel with per-key ordering preserved.
+	// When 0 (default), events are applied inline.
+	PropagationWorkers int
+
+	// PropagationQueueSize bounds the events buffered per propagation worker.
+	// Overflows are counted in Stats.EventQueueOverflows. When 0, 1024 is used.
+	PropagationQueueSize int
+
+	// SubscribeTimeout makes New block until the pub/sub subscription is confirmed
+	// active, for at most this duration. When 0 (default), New returns without waiting.
+	SubscribeTimeout time.Duration
+
+	// BreakerThreshold enables a circuit breaker around Redis opening after this
+	// many consecutive failures and half-opening every BreakerCooldown (default 5s).
+	// While open, Get serves the local cache only and writes follow DegradedWritePolicy,
+	// queueing writes for up to DegradedQueueSize keys (default 1024) with DegradedWriteQueue.
+	BreakerThreshold    int
+	BreakerCooldown     time.Duration
+	DegradedWritePolicy DegradedWritePolicy
+	DegradedQueueSize   int
+
+	// PublishQueueSize enables retrying failed synchronization events, keeping the
+	// latest one per key for up to this many keys. Each is retried PublishRetries
+	// times (default 10) with exponential backoff from PublishRetryBackoff (default
+	// 100ms); OnPublishDropped is called for the events given up on.
+	PublishQueueSize    int
+	PublishRetries      int
+	PublishRetryBackoff time.Duration
+	OnPublishDropped    func(event InvalidationEvent, err error)
+
+	// PublishRateLimit bounds the events published by Set and Delete per second,
+	// with bursts of PublishBurst; events over it follow RateLimitPolicy (default
+	// RateLimitCoalesce). WriteRateLimit and WriteBurst bound their Redis writes,
+	// failing the writes over the limit with ErrRateLimited.
+	PublishRateLimit float64
+	PublishBurst     int
+	RateLimitPolicy  RateLimitPolicy
+	WriteRateLimit   float64
+	WriteBurst       int
+
+	// OnEventGap is called when events from another pod were lost in transit.
+	// With InvalidateOnGap, the local cache is also cleared when that happens.
+	OnEventGap      func(gap EventGap)
+	InvalidateOnGap bool
+
+	// Writer persists values to a backing database on Set.
+	// When nil (default), values are only stored in the cache.
+	Writer Writer
+
+	// WriteBehind makes Set persist through Writer asynchronously from a bounded queue
+	// of WriteBehindQueueSize values (default 1024) instead of write-through.
+	WriteBehind          bool
+	WriteBehindQueueSize int
+
+	// WriteRetries is the number of times a failed Writer call is retried,
+	// waiting WriteRetryInterval between attempts.
+	WriteRetries       int
+	WriteRetryInterval time.Duration
+
+	// OnWriteFailed is the dead-letter callback for values the write-behind
+	// Writer could not persist after all retries.
+	OnWriteFailed func(key string, value any, err error)
+
+	// FaultInjector fails Redis commands and drops, duplicates or delays received
+	// events for fault-injection tests. Never set it in production.
+	FaultInjector FaultInjector
 }
 
 // New creates a new distributed cache instance.
 // This is the root-level initialization function that allows users to import from the root package.
 func New(cfg Config) (Cache, error) {
-	// Convert root Config to cache.Options
-	opts := cache.Options{
-		PodID:               cfg.PodID,
-		LocalCacheConfig:    cfg.LocalCacheConfig,
-		LocalCacheFactory:   cfg.LocalCacheFactory,
-		RedisAddr:           cfg.RedisAddr,
-		RedisPassword:       cfg.RedisPassword,
-		RedisDB:             cfg.RedisDB,
-		InvalidationChannel: cfg.InvalidationChannel,
-		SerializationFormat: cfg.SerializationFormat,
-		Marshaller:          cfg.Marshaller,
-		Logger:              cfg.Logger,
-		DebugMode:           cfg.DebugMode,
-		ContextTimeout:      cfg.ContextTimeout,
-		EnableMetrics:       cfg.EnableMetrics,
-		OnError:             cfg.OnError,
-		ReaderCanSetToRedis: cfg.ReaderCanSetToRedis,
-		OnSetLocalCache:     cfg.OnSetLocalCache,
-	}
+	return cache.New(cfg.options())
+}
 
-	return cache.New(opts)
+// options converts cfg to cache.Options.
+func (cfg Config) options() cache.Options {
+	return cache.Options{
+		PodID:                cfg.PodID,
+		LocalCacheConfig:     cfg.LocalCacheConfig,
+		LocalCacheFactory:    cfg.LocalCacheFactory,
+		LocalCacheShards:     cfg.LocalCacheShards,
+		SpilloverDir:         cfg.SpilloverDir,
+		SpilloverThreshold:   cfg.SpilloverThreshold,
+		SpilloverMaxBytes:    cfg.SpilloverMaxBytes,
+		RedisAddr:            cfg.RedisAddr,
+		RedisUsername:        cfg.RedisUsername,
+		RedisPassword:        cfg.RedisPassword,
+		RedisTLSConfig:       cfg.RedisTLSConfig,
+		RedisDB:              cfg.RedisDB,
+		RedisPoolSize:        cfg.RedisPoolSize,
+		RedisMinIdleConns:    cfg.RedisMinIdleConns,
+		RedisDialTimeout:     cfg.RedisDialTimeout,
+		RedisReadTimeout:     cfg.RedisReadTimeout,
+		RedisWriteTimeout:    cfg.RedisWriteTimeout,
+		RedisClient:          cfg.RedisClient,
+		RedisPartitions:      cfg.RedisPartitions,
+		PartitionVNodes:      cfg.PartitionVNodes,
+		PartitionReplicas:    cfg.PartitionReplicas,
+		ConnectionManager:    cfg.ConnectionManager,
+		Namespace:            cfg.Namespace,
+		DangerousFullFlush:   cfg.DangerousFullFlush,
+		SyncRedisAddr:        cfg.SyncRedisAddr,
+		SyncRedisClient:      cfg.SyncRedisClient,
+		InvalidationChannel:  cfg.InvalidationChannel,
+		SyncTransport:        cfg.SyncTransport,
+		StreamMaxLen:         cfg.StreamMaxLen,
+		EventEncoding:        cfg.EventEncoding,
+		ReceiveOwnEvents:     cfg.ReceiveOwnEvents,
+		PrefixChannels:       cfg.PrefixChannels,
+		PatternChannels:      cfg.PatternChannels,
+		AdditionalChannels:   cfg.AdditionalChannels,
+		PayloadEnvelope:      cfg.PayloadEnvelope,
+		MarshallerRules:      cfg.MarshallerRules,
+		PropagationRules:     cfg.PropagationRules,
+		ExternalFormats:      cfg.ExternalFormats,
+		SerializationFormat:  cfg.SerializationFormat,
+		Marshaller:           cfg.Marshaller,
+		Logger:               cfg.Logger,
+		DebugMode:            cfg.DebugMode,
+		DebugSampleRate:      cfg.DebugSampleRate,
+		DebugCategories:      cfg.DebugCategories,
+		ContextTimeout:       cfg.ContextTimeout,
+		RemoteGetTimeout:     cfg.RemoteGetTimeout,
+		StaleWhileRevalidate: cfg.StaleWhileRevalidate,
+		LocalTTL:             cfg.LocalTTL,
+		EnableMetrics:        cfg.EnableMetrics,
+		OnError:              cfg.OnError,
+		OnErrorEx:            cfg.OnErrorEx,
+		WritePolicy:          cfg.WritePolicy,
+		DisableRemoteStore:   cfg.DisableRemoteStore,
+		ReadOnly:             cfg.ReadOnly,
+		ReaderCanSetToRedis:  cfg.ReaderCanSetToRedis,
+		ConsistencyMode:      cfg.ConsistencyMode,
+		RollbackLocalOnError: cfg.RollbackLocalOnError,
+		ShouldCacheLocally:   cfg.ShouldCacheLocally,
+		EventFilter:          cfg.EventFilter,
+		OnSetLocalCache:      cfg.OnSetLocalCache,
+		CostFunc:             cfg.CostFunc,
+		DecodeInto:           cfg.DecodeInto,
+		TypeRegistry:         cfg.TypeRegistry,
+		LocalValueMode:       cfg.LocalValueMode,
+		KeepRawBytes:         cfg.KeepRawBytes,
+		ComputeETags:         cfg.ComputeETags,
+		RejectedSetPolicy:    cfg.RejectedSetPolicy,
+		PrefetchHint:         cfg.PrefetchHint,
+		HotKeys:              cfg.HotKeys,
+		HotKeysInterval:      cfg.HotKeysInterval,
+		WarmupKeys:           cfg.WarmupKeys,
+		WarmupPattern:        cfg.WarmupPattern,
+		PeerWarmup:           cfg.PeerWarmup,
+		PeerWarmupTimeout:    cfg.PeerWarmupTimeout,
+		AntiEntropyInterval:  cfg.AntiEntropyInterval,
+		BloomFilterInterval:  cfg.BloomFilterInterval,
+		BloomFilterKeys:      cfg.BloomFilterKeys,
+		HeartbeatInterval:    cfg.HeartbeatInterval,
+		LeaderElection:       cfg.LeaderElection,
+		LeaderLeaseTTL:       cfg.LeaderLeaseTTL,
+		TTLJitterPercent:     cfg.TTLJitterPercent,
+		MaxPropagationSize:   cfg.MaxPropagationSize,
+		RelayRemoteHits:      cfg.RelayRemoteHits,
+		MaxClockSkew:         cfg.MaxClockSkew,
+		MaxValueSize:         cfg.MaxValueSize,
+		PropagationWorkers:   cfg.PropagationWorkers,
+		PropagationQueueSize: cfg.PropagationQueueSize,
+		SubscribeTimeout:     cfg.SubscribeTimeout,
+		BreakerThreshold:     cfg.BreakerThreshold,
+		BreakerCooldown:      cfg.BreakerCooldown,
+		DegradedWritePolicy:  cfg.DegradedWritePolicy,
+		DegradedQueueSize:    cfg.DegradedQueueSize,
+		PublishQueueSize:     cfg.PublishQueueSize,
+		PublishRetries:       cfg.PublishRetries,
+		PublishRetryBackoff:  cfg.PublishRetryBackoff,
+		OnPublishDropped:     cfg.OnPublishDropped,
+		PublishRateLimit:     cfg.PublishRateLimit,
+		PublishBurst:         cfg.PublishBurst,
+		RateLimitPolicy:      cfg.RateLimitPolicy,
+		WriteRateLimit:       cfg.WriteRateLimit,
+		WriteBurst:           cfg.WriteBurst,
+		OnEventGap:           cfg.OnEventGap,
+		InvalidateOnGap:      cfg.InvalidateOnGap,
+		Writer:               cfg.Writer,
+		WriteBehind:          cfg.WriteBehind,
+		WriteBehindQueueSize: cfg.WriteBehindQueueSize,
+		WriteRetries:         cfg.WriteRetries,
+		WriteRetryInterval:   cfg.WriteRetryInterval,
+		OnWriteFailed:        cfg.OnWriteFailed,
+		FaultInjector:        cfg.FaultInjector,
+	}
 }
 
 // DefaultConfig returns default cache configuration.
 func DefaultConfig() Config {
 	return Config{
-		PodID:               "default-pod",
+		PodID:               GeneratePodID(),
 		RedisAddr:           "localhost:6379",
 		RedisDB:             0,
 		InvalidationChannel: "cache:invalidate",
@@ -113,3 +529,304 @@ type Cache = cache.Cache
 
 // Stats is an alias for cache.Stats.
 type Stats = cache.Stats
+
+// DumpEntry is an alias for cache.DumpEntry.
+type DumpEntry = cache.DumpEntry
+
+// MemoryEstimate is an alias for cache.MemoryEstimate.
+type MemoryEstimate = cache.MemoryEstimate
+
+// PrefixMemory is an alias for cache.PrefixMemory.
+type PrefixMemory = cache.PrefixMemory
+
+// HitInfo is an alias for cache.HitInfo.
+type HitInfo = cache.HitInfo
+
+// HitLevel is an alias for cache.HitLevel.
+type HitLevel = cache.HitLevel
+
+// HitSource is an alias for cache.HitSource.
+type HitSource = cache.HitSource
+
+// Hit levels and sources reported by GetWithInfo.
+const (
+	LevelMiss   = cache.LevelMiss
+	LevelLocal  = cache.LevelLocal
+	LevelRemote = cache.LevelRemote
+	LevelLoader = cache.LevelLoader
+
+	SourceNone       = cache.SourceNone
+	SourceSet        = cache.SourceSet
+	SourceRemote     = cache.SourceRemote
+	SourcePropagated = cache.SourcePropagated
+	SourceLoader     = cache.SourceLoader
+	SourceUnknown    = cache.SourceUnknown
+)
+
+// LoaderFunc is an alias for cache.LoaderFunc.
+type LoaderFunc = cache.LoaderFunc
+
+// RejectedSetPolicy is an alias for cache.RejectedSetPolicy.
+type RejectedSetPolicy = cache.RejectedSetPolicy
+
+// Policies for values rejected by the local cache.
+const (
+	RejectedSetLog             = cache.RejectedSetLog
+	RejectedSetRetry           = cache.RejectedSetRetry
+	RejectedSetForcePropagated = cache.RejectedSetForcePropagated
+)
+
+// TypeRegistry is an alias for cache.TypeRegistry.
+type TypeRegistry = cache.TypeRegistry
+
+// SchemaConverter is an alias for cache.SchemaConverter.
+type SchemaConverter = cache.SchemaConverter
+
+// NewTypeRegistry creates an empty type registry.
+func NewTypeRegistry() *TypeRegistry {
+	return cache.NewTypeRegistry()
+}
+
+// LocalValueMode is an alias for cache.LocalValueMode.
+type LocalValueMode = cache.LocalValueMode
+
+// Forms of values in the local cache.
+const (
+	LocalValueDecoded = cache.LocalValueDecoded
+	LocalValueRaw     = cache.LocalValueRaw
+	LocalValueBoth    = cache.LocalValueBoth
+)
+
+// CircuitState is an alias for cache.CircuitState.
+type CircuitState = cache.CircuitState
+
+// States of the circuit breaker around Redis.
+const (
+	CircuitClosed   = cache.CircuitClosed
+	CircuitOpen     = cache.CircuitOpen
+	CircuitHalfOpen = cache.CircuitHalfOpen
+)
+
+// DegradedWritePolicy is an alias for cache.DegradedWritePolicy.
+type DegradedWritePolicy = cache.DegradedWritePolicy
+
+// Policies for writes made while the circuit breaker is open.
+const (
+	DegradedWriteFailFast = cache.DegradedWriteFailFast
+	DegradedWriteQueue    = cache.DegradedWriteQueue
+)
+
+// RateLimitPolicy is an alias for cache.RateLimitPolicy.
+type RateLimitPolicy = cache.RateLimitPolicy
+
+// Policies for events published over PublishRateLimit.
+const (
+	RateLimitCoalesce = cache.RateLimitCoalesce
+	RateLimitDrop     = cache.RateLimitDrop
+)
+
+// DebugCategory is an alias for cache.DebugCategory.
+type DebugCategory = cache.DebugCategory
+
+// Debug log categories.
+const (
+	DebugOps           = cache.DebugOps
+	DebugSync          = cache.DebugSync
+	DebugSerialization = cache.DebugSerialization
+)
+
+// EventEncoding is an alias for cache.EventEncoding.
+type EventEncoding = cache.EventEncoding
+
+// Event encodings.
+const (
+	EventEncodingJSON   = cache.EventEncodingJSON
+	EventEncodingBinary = cache.EventEncodingBinary
+)
+
+// KeyMarshaller is an alias for cache.KeyMarshaller.
+type KeyMarshaller = cache.KeyMarshaller
+
+// Propagation is an alias for cache.Propagation.
+type Propagation = cache.Propagation
+
+// Propagations for PropagationRules.
+const (
+	PropagateValue      = cache.PropagateValue
+	PropagateInvalidate = cache.PropagateInvalidate
+	PropagateNone       = cache.PropagateNone
+)
+
+// SetOption is an alias for cache.SetOption.
+type SetOption = cache.SetOption
+
+// WithTTL expires the value after ttl, see cache.WithTTL.
+func WithTTL(ttl time.Duration) SetOption { return cache.WithTTL(ttl) }
+
+// WithLocalTTL expires the value from this pod's local cache after ttl, see cache.WithLocalTTL.
+func WithLocalTTL(ttl time.Duration) SetOption { return cache.WithLocalTTL(ttl) }
+
+// WithTags attaches tags to the value, see cache.WithTags.
+func WithTags(tags ...string) SetOption { return cache.WithTags(tags...) }
+
+// WithGroup records the key under group for DeleteGroup, see cache.WithGroup.
+func WithGroup(group string) SetOption { return cache.WithGroup(group) }
+
+// WithDependsOn declares the keys the value was computed from, see cache.WithDependsOn.
+func WithDependsOn(keys ...string) SetOption { return cache.WithDependsOn(keys...) }
+
+// WithCost sets the local cache cost of the value, see cache.WithCost.
+func WithCost(cost int64) SetOption { return cache.WithCost(cost) }
+
+// WithNoPropagate stores the value without notifying other pods, see cache.WithNoPropagate.
+func WithNoPropagate() SetOption { return cache.WithNoPropagate() }
+
+// WithInvalidateOnly invalidates the key on other pods, see cache.WithInvalidateOnly.
+func WithInvalidateOnly() SetOption { return cache.WithInvalidateOnly() }
+
+// WithVersion stores the value only at the expected version, see cache.WithVersion.
+func WithVersion(expectedVersion uint64) SetOption { return cache.WithVersion(expectedVersion) }
+
+// WithWritePolicy overrides the write policy for one call, see cache.WithWritePolicy.
+func WithWritePolicy(policy WritePolicy) SetOption { return cache.WithWritePolicy(policy) }
+
+// WritePolicy is an alias for cache.WritePolicy.
+type WritePolicy = cache.WritePolicy
+
+// Policies selecting which levels Set writes.
+const (
+	WritePolicyWriteThrough   = cache.WritePolicyWriteThrough
+	WritePolicyLocalOnly      = cache.WritePolicyLocalOnly
+	WritePolicyInvalidateOnly = cache.WritePolicyInvalidateOnly
+	WritePolicyWriteBehind    = cache.WritePolicyWriteBehind
+)
+
+// ConsistencyMode is an alias for cache.ConsistencyMode.
+type ConsistencyMode = cache.ConsistencyMode
+
+// Orderings of the local, Redis and publish steps of Set.
+const (
+	ConsistencyLocalFirst  = cache.ConsistencyLocalFirst
+	ConsistencyRemoteFirst = cache.ConsistencyRemoteFirst
+	ConsistencyOutbox      = cache.ConsistencyOutbox
+)
+
+// ExternalFormat is an alias for cache.ExternalFormat.
+type ExternalFormat = cache.ExternalFormat
+
+// Encoding is an alias for cache.Encoding.
+type Encoding = cache.Encoding
+
+// Redis encodings of values written by other systems.
+const (
+	EncodingString = cache.EncodingString
+	EncodingHash   = cache.EncodingHash
+)
+
+// SyncTransport is an alias for cache.SyncTransport.
+type SyncTransport = cache.SyncTransport
+
+// Transports for synchronization events.
+const (
+	SyncTransportPubSub  = cache.SyncTransportPubSub
+	SyncTransportStreams = cache.SyncTransportStreams
+)
+
+// LifecycleEvent is an alias for cache.LifecycleEvent.
+type LifecycleEvent = cache.LifecycleEvent
+
+// LifecycleType is an alias for cache.LifecycleType.
+type LifecycleType = cache.LifecycleType
+
+// Lifecycle events published on the sync channel.
+const (
+	LifecyclePodJoined      = cache.LifecyclePodJoined
+	LifecyclePodLeaving     = cache.LifecyclePodLeaving
+	LifecycleCleared        = cache.LifecycleCleared
+	LifecycleConfigReloaded = cache.LifecycleConfigReloaded
+)
+
+// LatencyOp is an alias for cache.LatencyOp.
+type LatencyOp = cache.LatencyOp
+
+// LatencyHistogram is an alias for cache.LatencyHistogram.
+type LatencyHistogram = cache.LatencyHistogram
+
+// Operations whose latencies are reported in Stats.Latencies.
+const (
+	LatencyLocalGet   = cache.LatencyLocalGet
+	LatencyRemoteGet  = cache.LatencyRemoteGet
+	LatencyMarshal    = cache.LatencyMarshal
+	LatencyUnmarshal  = cache.LatencyUnmarshal
+	LatencyPublish    = cache.LatencyPublish
+	LatencyApplyEvent = cache.LatencyApplyEvent
+)
+
+// ChangeEvent is an alias for cache.ChangeEvent.
+type ChangeEvent = cache.ChangeEvent
+
+// ChangeType is an alias for cache.ChangeType.
+type ChangeType = cache.ChangeType
+
+// Local cache changes delivered to Cache.Subscribe.
+const (
+	ChangeSet         = cache.ChangeSet
+	ChangeInvalidated = cache.ChangeInvalidated
+	ChangeDeleted     = cache.ChangeDeleted
+	ChangeEvicted     = cache.ChangeEvicted
+	ChangeCleared     = cache.ChangeCleared
+)
+
+// Operations passed to OnErrorEx.
+const (
+	OpGet         = cache.OpGet
+	OpSet         = cache.OpSet
+	OpDelete      = cache.OpDelete
+	OpClear       = cache.OpClear
+	OpLoad        = cache.OpLoad
+	OpSync        = cache.OpSync
+	OpSubscribe   = cache.OpSubscribe
+	OpLifecycle   = cache.OpLifecycle
+	OpSnapshot    = cache.OpSnapshot
+	OpAntiEntropy = cache.OpAntiEntropy
+	OpHeartbeat   = cache.OpHeartbeat
+	OpLeader      = cache.OpLeader
+	OpRefresh     = cache.OpRefresh
+	OpBloomFilter = cache.OpBloomFilter
+	OpWarmup      = cache.OpWarmup
+	OpDump        = cache.OpDump
+	OpHotKeys     = cache.OpHotKeys
+	OpWrite       = cache.OpWrite
+	OpReplay      = cache.OpReplay
+)
+
+// Description is an alias for cache.Description.
+type Description = cache.Description
+
+// Health is an alias for cache.Health.
+type Health = cache.Health
+
+// Peer is an alias for cache.Peer.
+type Peer = cache.Peer
+
+// ConfigError is an alias for cache.ConfigError.
+type ConfigError = cache.ConfigError
+
+// PartitionStatus is an alias for cache.PartitionStatus.
+type PartitionStatus = cache.PartitionStatus
+
+// ConnectionManager is an alias for cache.ConnectionManager.
+type ConnectionManager = cache.ConnectionManager
+
+// NewConnectionManager creates a manager sharing client and one Pub/Sub connection
+// between caches, see cache.NewConnectionManager.
+func NewConnectionManager(client redis.UniversalClient) *ConnectionManager {
+	return cache.NewConnectionManager(client)
+}
+
+// GeneratePodID returns a pod ID unique to this process, the hostname followed by
+// a random suffix, see cache.GeneratePodID.
+func GeneratePodID() string { return cache.GeneratePodID() }
+
+// NewSlogLogger adapts a *slog.Logger to the Logger interface, see cache.NewSlogLogger.
+func NewSlogLogger(logger *slog.Logger) Logger { return cache.NewSlogLogger(logger) }
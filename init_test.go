@@ -47,8 +47,8 @@ func TestNewWithDefaults(t *testing.T) {
 func TestDefaultConfig(t *testing.T) {
 	cfg := DefaultConfig()
 
-	if cfg.PodID != "default-pod" {
-		t.Errorf("Expected PodID 'default-pod', got %s", cfg.PodID)
+	if cfg.PodID == "" || cfg.PodID == DefaultConfig().PodID {
+		t.Errorf("Expected a unique generated PodID, got %q", cfg.PodID)
 	}
 
 	if cfg.RedisAddr != "localhost:6379" {
@@ -5,9 +5,18 @@ import "github.com/huykn/distributed-cache/cache"
 // Logger is an alias for cache.Logger.
 type Logger = cache.Logger
 
+// FieldLogger is an alias for cache.FieldLogger.
+type FieldLogger = cache.FieldLogger
+
 // Marshaller is an alias for cache.Marshaller.
 type Marshaller = cache.Marshaller
 
+// Writer is an alias for cache.Writer.
+type Writer = cache.Writer
+
+// Lock is an alias for cache.Lock.
+type Lock = cache.Lock
+
 // LocalCache is an alias for cache.LocalCache.
 type LocalCache = cache.LocalCache
 
@@ -23,6 +32,18 @@ type LocalCacheConfig = cache.LocalCacheConfig
 // InvalidationEvent is an alias for cache.InvalidationEvent.
 type InvalidationEvent = cache.InvalidationEvent
 
+// EventGap is an alias for cache.EventGap.
+type EventGap = cache.EventGap
+
+// FaultInjector is an alias for cache.FaultInjector.
+type FaultInjector = cache.FaultInjector
+
+// EventFault is an alias for cache.EventFault.
+type EventFault = cache.EventFault
+
+// RandomFaults is an alias for cache.RandomFaults.
+type RandomFaults = cache.RandomFaults
+
 // DefaultLocalCacheConfig returns default local cache configuration for Ristretto.
 func DefaultLocalCacheConfig() LocalCacheConfig {
 	return cache.DefaultLocalCacheConfig()
@@ -0,0 +1,91 @@
+package distributedcache
+
+import "github.com/redis/go-redis/v9"
+
+// Option sets a field of the Config used by NewWithOptions.
+type Option func(*Config)
+
+// NewWithOptions creates a new distributed cache instance from DefaultConfig,
+// whose PodID is generated from the hostname, with opts applied in order:
+//
+//	c, err := distributedcache.NewWithOptions(
+//		distributedcache.WithRedis("redis:6379"),
+//		distributedcache.WithNamespace("orders:"),
+//	)
+func NewWithOptions(opts ...Option) (Cache, error) {
+	return New(newConfig(opts...))
+}
+
+// newConfig returns the Config NewWithOptions creates a cache with.
+func newConfig(opts ...Option) Config {
+	cfg := DefaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithPodID sets the unique identifier of this pod, overriding the generated one.
+func WithPodID(podID string) Option {
+	return func(cfg *Config) { cfg.PodID = podID }
+}
+
+// WithRedis sets the address of the Redis server.
+func WithRedis(addr string) Option {
+	return func(cfg *Config) { cfg.RedisAddr = addr }
+}
+
+// WithRedisAuth sets the username and password to authenticate to Redis with.
+// The username may be empty for password-only authentication.
+func WithRedisAuth(username, password string) Option {
+	return func(cfg *Config) {
+		cfg.RedisUsername = username
+		cfg.RedisPassword = password
+	}
+}
+
+// WithRedisDB selects the Redis database.
+func WithRedisDB(db int) Option {
+	return func(cfg *Config) { cfg.RedisDB = db }
+}
+
+// WithRedisClient uses an existing Redis client instead of connecting to the
+// address set with WithRedis.
+func WithRedisClient(client redis.UniversalClient) Option {
+	return func(cfg *Config) { cfg.RedisClient = client }
+}
+
+// WithChannel sets the Redis channel synchronization events are published on.
+func WithChannel(channel string) Option {
+	return func(cfg *Config) { cfg.InvalidationChannel = channel }
+}
+
+// WithNamespace sets the prefix applied to every key stored in Redis.
+func WithNamespace(namespace string) Option {
+	return func(cfg *Config) { cfg.Namespace = namespace }
+}
+
+// WithLocalCache sets the factory creating the local cache.
+func WithLocalCache(factory LocalCacheFactory) Option {
+	return func(cfg *Config) { cfg.LocalCacheFactory = factory }
+}
+
+// WithMarshaller sets how values are serialized.
+func WithMarshaller(marshaller Marshaller) Option {
+	return func(cfg *Config) { cfg.Marshaller = marshaller }
+}
+
+// WithLogger sets the logger.
+func WithLogger(logger Logger) Option {
+	return func(cfg *Config) { cfg.Logger = logger }
+}
+
+// WithOnError sets the callback receiving errors of background operations.
+func WithOnError(onError func(error)) Option {
+	return func(cfg *Config) { cfg.OnError = onError }
+}
+
+// WithConfig applies fn to the Config, to set the fields no other Option covers.
+func WithConfig(fn func(*Config)) Option {
+	return Option(fn)
+}
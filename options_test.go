@@ -0,0 +1,78 @@
+package distributedcache
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewConfigAppliesOptions(t *testing.T) {
+	cfg := newConfig()
+	if hostname, err := os.Hostname(); err == nil && !strings.HasPrefix(cfg.PodID, hostname+"-") {
+		t.Errorf("Expected the PodID to be generated from the hostname %q, got %q", hostname, cfg.PodID)
+	}
+
+	cfg = newConfig(
+		WithPodID("pod-1"),
+		WithRedis("redis:6379"),
+		WithRedisAuth("app", "secret"),
+		WithNamespace("orders:"),
+		WithConfig(func(cfg *Config) { cfg.MaxValueSize = 1 << 20 }),
+	)
+	if cfg.PodID != "pod-1" || cfg.RedisAddr != "redis:6379" || cfg.RedisUsername != "app" ||
+		cfg.RedisPassword != "secret" || cfg.Namespace != "orders:" || cfg.MaxValueSize != 1<<20 {
+		t.Errorf("Expected the options to be applied, got %+v", cfg)
+	}
+	if cfg.InvalidationChannel != DefaultConfig().InvalidationChannel {
+		t.Errorf("Expected unset fields to keep their default, got channel %q", cfg.InvalidationChannel)
+	}
+}
+
+// TestConfigOptionsCopiesEveryField guards against Config fields that are not
+// passed on to cache.Options.
+func TestConfigOptionsCopiesEveryField(t *testing.T) {
+	var cfg Config
+	cfgValue := reflect.ValueOf(&cfg).Elem()
+	for i := range cfgValue.NumField() {
+		field := cfgValue.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString("x")
+		case reflect.Bool:
+			field.SetBool(true)
+		case reflect.Int, reflect.Int64:
+			field.SetInt(1)
+		case reflect.Float64:
+			field.SetFloat(1)
+		case reflect.Slice:
+			field.Set(reflect.MakeSlice(field.Type(), 1, 1))
+		case reflect.Map:
+			field.Set(reflect.MakeMap(field.Type()))
+		case reflect.Pointer:
+			field.Set(reflect.New(field.Type().Elem()))
+		case reflect.Func:
+			field.Set(reflect.MakeFunc(field.Type(), func([]reflect.Value) []reflect.Value { return nil }))
+		case reflect.Struct:
+			field.Field(0).Set(reflect.ValueOf(int64(1)).Convert(field.Field(0).Type()))
+		}
+	}
+
+	opts := reflect.ValueOf(cfg.options())
+	for i := range cfgValue.NumField() {
+		name := cfgValue.Type().Field(i).Name
+		want := cfgValue.Field(i)
+		got := opts.FieldByName(name)
+		if !got.IsValid() {
+			t.Errorf("cache.Options has no field %s", name)
+			continue
+		}
+		if got.Type() != want.Type() {
+			t.Errorf("Field %s is %v in Config but %v in cache.Options", name, want.Type(), got.Type())
+			continue
+		}
+		if got.IsZero() != want.IsZero() {
+			t.Errorf("Field %s is not copied to cache.Options", name)
+		}
+	}
+}
@@ -0,0 +1,491 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultVirtualNodes is the number of points each partition gets on the hash ring
+// when NewPartitionedStore is given 0.
+const DefaultVirtualNodes = 160
+
+// PartitionStatus reports the health of one partition of a PartitionedStore.
+type PartitionStatus struct {
+	// Name identifies the partition, usually its Redis address.
+	Name string `json:"name"`
+
+	// Healthy is false when the last operation on the partition failed.
+	Healthy bool `json:"healthy"`
+
+	// Errors is the number of failed operations on the partition.
+	Errors int64 `json:"errors"`
+}
+
+// partition is a RedisStore owning a share of the hash ring.
+type partition struct {
+	name   string
+	store  *RedisStore
+	failed atomic.Bool
+	errors atomic.Int64
+}
+
+// record updates the health of the partition after an operation.
+func (p *partition) record(err error) {
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		p.failed.Store(true)
+		p.errors.Add(1)
+		return
+	}
+	p.failed.Store(false)
+}
+
+// hashRing maps keys to partitions with consistent hashing.
+type hashRing struct {
+	points []uint32
+	owners map[uint32]*partition
+	count  int
+}
+
+// newHashRing places virtualNodes points per partition on a ring. Partitions are
+// placed by name order so that every pod resolves colliding points the same way.
+func newHashRing(partitions []*partition, virtualNodes int) *hashRing {
+	ring := &hashRing{owners: make(map[uint32]*partition), count: len(partitions)}
+	partitions = slices.Clone(partitions)
+	slices.SortFunc(partitions, func(a, b *partition) int { return strings.Compare(a.name, b.name) })
+	for _, p := range partitions {
+		for i := range virtualNodes {
+			point := hashKey(p.name + "#" + strconv.Itoa(i))
+			if _, taken := ring.owners[point]; taken {
+				continue
+			}
+			ring.owners[point] = p
+			ring.points = append(ring.points, point)
+		}
+	}
+	slices.Sort(ring.points)
+	return ring
+}
+
+// lookup returns up to n distinct partitions owning key, primary owner first.
+func (r *hashRing) lookup(key string, n int) []*partition {
+	if len(r.points) == 0 {
+		return nil
+	}
+	n = min(n, r.count)
+	owners := make([]*partition, 0, n)
+	hash := hashKey(key)
+	start := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= hash })
+	for i := 0; len(owners) < n && i < len(r.points); i++ {
+		p := r.owners[r.points[(start+i)%len(r.points)]]
+		if !slices.Contains(owners, p) {
+			owners = append(owners, p)
+		}
+	}
+	return owners
+}
+
+// hashKey hashes a key or a virtual node name onto the ring.
+func hashKey(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
+
+// PartitionedStore spreads keys over several RedisStores with consistent hashing,
+// so a dataset larger than one Redis instance can be cached. Each key is written to
+// replicationFactor partitions and read from the first of them that responds.
+//
+// Adding or removing a partition moves a share of the keys to new owners. Until
+// FinishRebalance is called, a key missing on its new owners is looked up on the
+// owners it had before the change and copied over, so the change does not turn
+// into a burst of misses.
+type PartitionedStore struct {
+	mu           sync.RWMutex
+	partitions   []*partition
+	ring         *hashRing
+	previous     *hashRing // ring before the last topology change, nil once rebalanced
+	virtualNodes int
+	replication  int
+	namespace    string
+}
+
+// NewPartitionedStore creates a store partitioned over stores, keyed by a name
+// identifying each partition, usually its Redis address. It places virtualNodes
+// points per partition on the hash ring (DefaultVirtualNodes when 0) and writes
+// each key to replicationFactor partitions (1 when 0). Every pod must use the same
+// names for keys to map to the same partitions.
+func NewPartitionedStore(stores map[string]*RedisStore, virtualNodes, replicationFactor int) *PartitionedStore {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+	if replicationFactor <= 0 {
+		replicationFactor = 1
+	}
+	ps := &PartitionedStore{virtualNodes: virtualNodes, replication: replicationFactor}
+	for name, store := range stores {
+		ps.partitions = append(ps.partitions, &partition{name: name, store: store})
+	}
+	ps.ring = newHashRing(ps.partitions, virtualNodes)
+	return ps
+}
+
+// AddPartition adds store under name, starting a rebalance. The store takes the
+// namespace of the partitioned store and is closed by Close.
+func (ps *PartitionedStore) AddPartition(name string, store *RedisStore) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	store.SetNamespace(ps.namespace)
+	ps.partitions = append(ps.partitions, &partition{name: name, store: store})
+	ps.rebuild()
+}
+
+// RemovePartition removes the partition named name, starting a rebalance, and
+// returns its store, left open so that keys can still be read from it until FinishRebalance is
+// called. It returns nil when there is no such partition.
+func (ps *PartitionedStore) RemovePartition(name string) *RedisStore {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	i := slices.IndexFunc(ps.partitions, func(p *partition) bool { return p.name == name })
+	if i < 0 {
+		return nil
+	}
+	store := ps.partitions[i].store
+	ps.partitions = slices.Delete(ps.partitions, i, i+1)
+	ps.rebuild()
+	return store
+}
+
+// rebuild recomputes the ring after a topology change, keeping the current ring
+// as the previous one unless a rebalance is already in progress.
+func (ps *PartitionedStore) rebuild() {
+	if ps.previous == nil && len(ps.ring.points) > 0 {
+		ps.previous = ps.ring
+	}
+	ps.ring = newHashRing(ps.partitions, ps.virtualNodes)
+}
+
+// Rebalancing reports whether keys are still looked up on their owners from
+// before the last topology change.
+func (ps *PartitionedStore) Rebalancing() bool {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return ps.previous != nil
+}
+
+// FinishRebalance stops looking up keys on their previous owners.
+func (ps *PartitionedStore) FinishRebalance() {
+	ps.mu.Lock()
+	ps.previous = nil
+	ps.mu.Unlock()
+}
+
+// SetNamespace sets a prefix applied to every key on every partition.
+// Must be called before the store is used.
+func (ps *PartitionedStore) SetNamespace(namespace string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.namespace = namespace
+	for _, p := range ps.partitions {
+		p.store.SetNamespace(namespace)
+	}
+}
+
+// Partitions returns the health of every partition, ordered by name.
+func (ps *PartitionedStore) Partitions() []PartitionStatus {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	statuses := make([]PartitionStatus, len(ps.partitions))
+	for i, p := range ps.partitions {
+		statuses[i] = PartitionStatus{Name: p.name, Healthy: !p.failed.Load(), Errors: p.errors.Load()}
+	}
+	slices.SortFunc(statuses, func(a, b PartitionStatus) int { return strings.Compare(a.Name, b.Name) })
+	return statuses
+}
+
+// owners returns the partitions owning key under the current and previous rings.
+func (ps *PartitionedStore) owners(key string) (current, previous []*partition) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	current = ps.ring.lookup(key, ps.replication)
+	if ps.previous != nil {
+		previous = ps.previous.lookup(key, ps.replication)
+	}
+	return current, previous
+}
+
+// primary returns the first partition owning key.
+func (ps *PartitionedStore) primary(key string) (*partition, error) {
+	current, _ := ps.owners(key)
+	if len(current) == 0 {
+		return nil, ErrNoPartitions
+	}
+	return current[0], nil
+}
+
+// all returns every partition.
+func (ps *PartitionedStore) all() []*partition {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return slices.Clone(ps.partitions)
+}
+
+// Get retrieves a value from the first owner of key that responds. While
+// rebalancing, a value missing on the owners of key is read from its previous
+// owners and copied to the current ones.
+func (ps *PartitionedStore) Get(ctx context.Context, key string) ([]byte, error) {
+	current, previous := ps.owners(key)
+	if len(current) == 0 {
+		return nil, ErrNoPartitions
+	}
+
+	value, err := getFrom(ctx, current, key)
+	if !errors.Is(err, ErrNotFound) || len(previous) == 0 || slices.Equal(current, previous) {
+		return value, err
+	}
+
+	value, err = getFrom(ctx, previous, key)
+	if err != nil {
+		return nil, err
+	}
+	// The value is returned even if moving it fails; the next Get retries
+	_ = setOn(ctx, current, func(store *RedisStore) error { return store.Set(ctx, key, value) })
+	return value, nil
+}
+
+// getFrom reads key from the first partition that responds.
+func getFrom(ctx context.Context, partitions []*partition, key string) ([]byte, error) {
+	var err error
+	for _, p := range partitions {
+		var value []byte
+		value, err = p.store.Get(ctx, key)
+		p.record(err)
+		if err == nil || errors.Is(err, ErrNotFound) {
+			return value, err
+		}
+	}
+	return nil, err
+}
+
+// setOn applies a write to every partition, returning the first error.
+func setOn(ctx context.Context, partitions []*partition, write func(store *RedisStore) error) error {
+	var first error
+	for _, p := range partitions {
+		err := write(p.store)
+		p.record(err)
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// write applies a write to every owner of key.
+func (ps *PartitionedStore) write(ctx context.Context, key string, write func(store *RedisStore) error) error {
+	current, _ := ps.owners(key)
+	if len(current) == 0 {
+		return ErrNoPartitions
+	}
+	return setOn(ctx, current, write)
+}
+
+// Set stores a value on every owner of key.
+func (ps *PartitionedStore) Set(ctx context.Context, key string, value []byte) error {
+	return ps.write(ctx, key, func(store *RedisStore) error { return store.Set(ctx, key, value) })
+}
+
+// SetWithTTL stores a value that expires after ttl on every owner of key.
+func (ps *PartitionedStore) SetWithTTL(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return ps.write(ctx, key, func(store *RedisStore) error { return store.SetWithTTL(ctx, key, value, ttl) })
+}
+
+// Delete removes a value from every owner of key, and from its previous owners
+// while rebalancing so it cannot be copied back.
+func (ps *PartitionedStore) Delete(ctx context.Context, key string) error {
+	current, previous := ps.owners(key)
+	if len(current) == 0 {
+		return ErrNoPartitions
+	}
+	for _, p := range previous {
+		if !slices.Contains(current, p) {
+			current = append(current, p)
+		}
+	}
+	return setOn(ctx, current, func(store *RedisStore) error { return store.Delete(ctx, key) })
+}
+
+// Clear removes all values in the namespace from every partition.
+func (ps *PartitionedStore) Clear(ctx context.Context) error {
+	return setOn(ctx, ps.all(), func(store *RedisStore) error { return store.Clear(ctx) })
+}
+
+// FlushDB flushes the database of every partition.
+func (ps *PartitionedStore) FlushDB(ctx context.Context) error {
+	return setOn(ctx, ps.all(), func(store *RedisStore) error { return store.FlushDB(ctx) })
+}
+
+// Ping checks every partition, updating their health.
+func (ps *PartitionedStore) Ping(ctx context.Context) error {
+	return setOn(ctx, ps.all(), func(store *RedisStore) error { return store.Ping(ctx) })
+}
+
+// Size returns the number of keys in the namespace across partitions, counting
+// replicated keys once.
+func (ps *PartitionedStore) Size(ctx context.Context) (int64, error) {
+	partitions := ps.all()
+	var total int64
+	for _, p := range partitions {
+		size, err := p.store.Size(ctx)
+		p.record(err)
+		if err != nil {
+			return 0, err
+		}
+		total += size
+	}
+	if replicas := int64(min(ps.replication, len(partitions))); replicas > 1 {
+		total /= replicas
+	}
+	return total, nil
+}
+
+// ScanKeys calls fn with batches of keys matching the glob pattern on every
+// partition. Each key is reported once, by its primary owner.
+func (ps *PartitionedStore) ScanKeys(ctx context.Context, pattern string, batchSize int, fn func(keys []string) error) error {
+	for _, p := range ps.all() {
+		err := p.store.ScanKeys(ctx, pattern, batchSize, func(keys []string) error {
+			owned := make([]string, 0, len(keys))
+			for _, key := range keys {
+				if primary, err := ps.primary(key); err == nil && primary == p {
+					owned = append(owned, key)
+				}
+			}
+			if len(owned) == 0 {
+				return nil
+			}
+			return fn(owned)
+		})
+		p.record(err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetMulti returns the values of the given keys, read from their primary owners.
+// Missing keys are omitted.
+func (ps *PartitionedStore) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	groups := make(map[*partition][]string)
+	for _, key := range keys {
+		p, err := ps.primary(key)
+		if err != nil {
+			return nil, err
+		}
+		groups[p] = append(groups[p], key)
+	}
+
+	values := make(map[string][]byte, len(keys))
+	for p, group := range groups {
+		found, err := p.store.GetMulti(ctx, group)
+		p.record(err)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range found {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// AddToGroup records that keys were written under group with ttl on every owner
+// of each key.
+func (ps *PartitionedStore) AddToGroup(ctx context.Context, group string, ttl time.Duration, keys ...string) error {
+	for _, key := range keys {
+		err := ps.write(ctx, key, func(store *RedisStore) error { return store.AddToGroup(ctx, group, ttl, key) })
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteGroup removes the keys written under group from every partition and
+// returns them, each once.
+func (ps *PartitionedStore) DeleteGroup(ctx context.Context, group string) ([]string, error) {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, p := range ps.all() {
+		deleted, err := p.store.DeleteGroup(ctx, group)
+		p.record(err)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range deleted {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// TryLock acquires the lock on key on its primary owner.
+func (ps *PartitionedStore) TryLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	p, err := ps.primary(key)
+	if err != nil {
+		return false, err
+	}
+	locked, err := p.store.TryLock(ctx, key, token, ttl)
+	p.record(err)
+	return locked, err
+}
+
+// Unlock releases the lock on key on its primary owner.
+func (ps *PartitionedStore) Unlock(ctx context.Context, key, token string) (bool, error) {
+	p, err := ps.primary(key)
+	if err != nil {
+		return false, err
+	}
+	unlocked, err := p.store.Unlock(ctx, key, token)
+	p.record(err)
+	return unlocked, err
+}
+
+// ExtendLock extends the lock on key on its primary owner.
+func (ps *PartitionedStore) ExtendLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	p, err := ps.primary(key)
+	if err != nil {
+		return false, err
+	}
+	extended, err := p.store.ExtendLock(ctx, key, token, ttl)
+	p.record(err)
+	return extended, err
+}
+
+// Close closes every partition.
+func (ps *PartitionedStore) Close() error {
+	var first error
+	for _, p := range ps.all() {
+		if err := p.store.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// ErrNoPartitions is returned by a PartitionedStore without partitions.
+var ErrNoPartitions = errors.New("partitioned store has no partitions")
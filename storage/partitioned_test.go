@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newMiniPartitions starts n miniredis servers and returns a store for each, by name.
+func newMiniPartitions(t *testing.T, n int) (map[string]*RedisStore, map[string]*miniredis.Miniredis) {
+	stores := make(map[string]*RedisStore, n)
+	servers := make(map[string]*miniredis.Miniredis, n)
+	for i := range n {
+		name := fmt.Sprintf("redis-%d", i)
+		servers[name] = miniredis.RunT(t)
+		store, err := NewRedisStoreWithOptions(&redis.Options{Addr: servers[name].Addr()})
+		if err != nil {
+			t.Fatalf("Failed to create Redis store: %v", err)
+		}
+		stores[name] = store
+	}
+	return stores, servers
+}
+
+func TestPartitionedStoreSpreadsKeys(t *testing.T) {
+	stores, servers := newMiniPartitions(t, 3)
+	ps := NewPartitionedStore(stores, 0, 1)
+	defer ps.Close()
+	ps.SetNamespace("app:")
+	ctx := context.Background()
+
+	for i := range 300 {
+		if err := ps.Set(ctx, fmt.Sprintf("key:%d", i), []byte("value")); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	for name, server := range servers {
+		if keys := len(server.Keys()); keys < 50 {
+			t.Errorf("Expected keys to spread evenly, %s holds %d of 300", name, keys)
+		}
+	}
+	if size, err := ps.Size(ctx); err != nil || size != 300 {
+		t.Errorf("Expected size 300, got %d (err=%v)", size, err)
+	}
+
+	value, err := ps.Get(ctx, "key:42")
+	if err != nil || string(value) != "value" {
+		t.Fatalf("Expected value, got %q (err=%v)", value, err)
+	}
+	if _, err := ps.Get(ctx, "missing"); err != ErrNotFound {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+
+	var scanned []string
+	err = ps.ScanKeys(ctx, "*", 100, func(keys []string) error {
+		scanned = append(scanned, keys...)
+		return nil
+	})
+	if err != nil || len(scanned) != 300 {
+		t.Fatalf("Expected 300 scanned keys, got %d (err=%v)", len(scanned), err)
+	}
+	values, err := ps.GetMulti(ctx, []string{"key:1", "key:2", "missing"})
+	if err != nil || len(values) != 2 {
+		t.Fatalf("Expected 2 values, got %v (err=%v)", values, err)
+	}
+}
+
+func TestPartitionedStoreReplicasFailover(t *testing.T) {
+	stores, servers := newMiniPartitions(t, 3)
+	ps := NewPartitionedStore(stores, 0, 2)
+	defer ps.Close()
+	ctx := context.Background()
+
+	if err := ps.Set(ctx, "user:1", []byte("alice")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	owners, _ := ps.owners("user:1")
+	if len(owners) != 2 {
+		t.Fatalf("Expected 2 owners, got %d", len(owners))
+	}
+	for _, p := range owners {
+		if got, _ := servers[p.name].Get("user:1"); got != "alice" {
+			t.Errorf("Expected the value on replica %s, got %q", p.name, got)
+		}
+	}
+	if size, err := ps.Size(ctx); err != nil || size != 1 {
+		t.Errorf("Expected replicated keys to be counted once, got %d (err=%v)", size, err)
+	}
+
+	servers[owners[0].name].Close()
+	value, err := ps.Get(ctx, "user:1")
+	if err != nil || string(value) != "alice" {
+		t.Fatalf("Expected the replica to serve the value, got %q (err=%v)", value, err)
+	}
+
+	statuses := ps.Partitions()
+	i := slices.IndexFunc(statuses, func(s PartitionStatus) bool { return s.Name == owners[0].name })
+	if statuses[i].Healthy || statuses[i].Errors == 0 {
+		t.Errorf("Expected the closed partition to be reported unhealthy, got %+v", statuses[i])
+	}
+}
+
+func TestPartitionedStoreRebalance(t *testing.T) {
+	stores, _ := newMiniPartitions(t, 2)
+	ps := NewPartitionedStore(stores, 0, 1)
+	defer ps.Close()
+	ctx := context.Background()
+
+	for i := range 100 {
+		if err := ps.Set(ctx, fmt.Sprintf("key:%d", i), []byte("value")); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	if ps.Rebalancing() {
+		t.Fatal("Expected no rebalance before a topology change")
+	}
+
+	added, servers := newMiniPartitions(t, 1)
+	ps.AddPartition("redis-new", added["redis-0"])
+	if !ps.Rebalancing() {
+		t.Fatal("Expected a rebalance after adding a partition")
+	}
+	for i := range 100 {
+		if _, err := ps.Get(ctx, fmt.Sprintf("key:%d", i)); err != nil {
+			t.Fatalf("Expected key:%d to be found during the rebalance, got %v", i, err)
+		}
+	}
+	moved := len(servers["redis-0"].Keys())
+	if moved == 0 || moved == 100 {
+		t.Errorf("Expected a share of the keys to move to the new partition, got %d of 100", moved)
+	}
+
+	ps.FinishRebalance()
+	for i := range 100 {
+		if _, err := ps.Get(ctx, fmt.Sprintf("key:%d", i)); err != nil {
+			t.Fatalf("Expected key:%d to stay found after the rebalance, got %v", i, err)
+		}
+	}
+}
+
+func TestPartitionedStoreWithoutPartitions(t *testing.T) {
+	ps := NewPartitionedStore(nil, 0, 0)
+	if _, err := ps.Get(context.Background(), "key"); err != ErrNoPartitions {
+		t.Fatalf("Expected ErrNoPartitions, got %v", err)
+	}
+}
+
+func TestPartitionedStoreDeleteGroup(t *testing.T) {
+	stores, servers := newMiniPartitions(t, 3)
+	ps := NewPartitionedStore(stores, 0, 2)
+	defer ps.Close()
+	ps.SetNamespace("app:")
+	ctx := context.Background()
+
+	var grouped []string
+	for i := range 30 {
+		key := fmt.Sprintf("tenant:1:%d", i)
+		grouped = append(grouped, key)
+		if err := ps.Set(ctx, key, []byte("value")); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		if err := ps.AddToGroup(ctx, "tenant:1", 0, key); err != nil {
+			t.Fatalf("AddToGroup failed: %v", err)
+		}
+	}
+	if err := ps.Set(ctx, "tenant:2:0", []byte("value")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	deleted, err := ps.DeleteGroup(ctx, "tenant:1")
+	if err != nil {
+		t.Fatalf("DeleteGroup failed: %v", err)
+	}
+	slices.Sort(deleted)
+	slices.Sort(grouped)
+	if !slices.Equal(deleted, grouped) {
+		t.Fatalf("Expected every grouped key once, got %v", deleted)
+	}
+	for name, server := range servers {
+		for _, key := range server.Keys() {
+			if key != "app:tenant:2:0" {
+				t.Errorf("Expected only the ungrouped key to remain, %s holds %s", name, key)
+			}
+		}
+	}
+}
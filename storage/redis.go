@@ -2,40 +2,226 @@ package storage
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"errors"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
 // RedisStore implements the Store interface using Redis.
 type RedisStore struct {
-	client *redis.Client
+	client    redis.UniversalClient
+	namespace string
+	borrowed  bool // client was supplied by the caller and is left open by Close
 }
 
+// internalKeyPrefix starts the keys the store uses for itself, after the
+// namespace. No cache key starts with a NUL byte, so locks, versions, groups and
+// the hot list never overwrite values and ScanKeys never skips a value.
+const internalKeyPrefix = "\x00"
+
+// lockKeyPrefix is prepended to keys locked with TryLock so locks never collide with values.
+const lockKeyPrefix = internalKeyPrefix + "lock:"
+
+// unlockScript deletes a lock key only if it still holds the caller's token.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// extendLockScript resets the expiry of a lock key only if it still holds the caller's token.
+var extendLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// versionKeyPrefix is prepended to keys holding the version of values written with SetIfVersion.
+const versionKeyPrefix = internalKeyPrefix + "version:"
+
+// setScript writes the value KEYS[1], expiring after ARGV[2] milliseconds when
+// positive. An existing version KEYS[2] is incremented and expires with the
+// value, so SetIfVersion never overwrites a value written since its version was
+// read. The group of the key is read from KEYS[3], cleared and returned. When
+// ARGV[3] is set, ARGV[4] is published on that channel along with the write.
+var setScript = redis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ttl)
+else
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+if redis.call("EXISTS", KEYS[2]) == 1 then
+	redis.call("INCR", KEYS[2])
+	if ttl > 0 then
+		redis.call("PEXPIRE", KEYS[2], ttl)
+	else
+		redis.call("PERSIST", KEYS[2])
+	end
+end
+local group = redis.call("GET", KEYS[3]) or ""
+redis.call("DEL", KEYS[3])
+if ARGV[3] ~= "" then
+	redis.call("PUBLISH", ARGV[3], ARGV[4])
+end
+return group
+`)
+
+// scriptCommands names the scripts standing in for a Redis command, see CommandName.
+var scriptCommands = map[string]string{setScript.Hash(): "set"}
+
+// CommandName returns the lowercase name of cmd, where a script standing in for
+// a Redis command is named after it, e.g. "set" for the script writing values.
+func CommandName(cmd redis.Cmder) string {
+	name := cmd.Name()
+	args := cmd.Args()
+	if len(args) < 2 {
+		return name
+	}
+	var hash string
+	switch name {
+	case "evalsha":
+		hash, _ = args[1].(string)
+	case "eval":
+		src, _ := args[1].(string)
+		sum := sha1.Sum([]byte(src))
+		hash = hex.EncodeToString(sum[:])
+	default:
+		return name
+	}
+	if command, ok := scriptCommands[hash]; ok {
+		return command
+	}
+	return name
+}
+
+// setIfVersionScript writes the value KEYS[1] and increments its version KEYS[2]
+// only if the current version matches the expected one, clearing the group of
+// the key KEYS[3]. It returns {1, newVersion, group} on success and
+// {0, currentVersion, ""} on conflict.
+var setIfVersionScript = redis.NewScript(`
+local current = tonumber(redis.call("GET", KEYS[2]) or "0")
+if current ~= tonumber(ARGV[2]) then
+	return {0, current, ""}
+end
+redis.call("SET", KEYS[1], ARGV[1])
+local version = redis.call("INCR", KEYS[2])
+redis.call("PERSIST", KEYS[2])
+local group = redis.call("GET", KEYS[3]) or ""
+redis.call("DEL", KEYS[3])
+return {1, version, group}
+`)
+
+// hotKeysKey is the sorted set holding the hot list, scored by access count.
+const hotKeysKey = internalKeyPrefix + "hotkeys"
+
+// groupKeyPrefix is prepended to the sets holding the keys written under a group.
+const groupKeyPrefix = internalKeyPrefix + "group:"
+
+// memberKeyPrefix is prepended to keys holding the group a key was last written
+// under. A key leaves its group when it is deleted or written again.
+const memberKeyPrefix = internalKeyPrefix + "member:"
+
+// addToGroupScript adds the keys ARGV[2:] to the group set KEYS[1] and keeps the
+// set at least as long as its longest-lived member, where ARGV[1] is the TTL of
+// the keys in milliseconds, or 0 if they do not expire.
+var addToGroupScript = redis.NewScript(`
+local ttl = tonumber(ARGV[1])
+local current = redis.call("PTTL", KEYS[1])
+redis.call("SADD", KEYS[1], unpack(ARGV, 2))
+if ttl <= 0 then
+	redis.call("PERSIST", KEYS[1])
+elseif current == -2 or (current >= 0 and current < ttl) then
+	redis.call("PEXPIRE", KEYS[1], ttl)
+end
+return 1
+`)
+
+// clearBatchSize is the number of keys scanned and deleted per round trip by Clear.
+const clearBatchSize = 500
+
 // NewRedisStore creates a new Redis-based store.
 func NewRedisStore(addr, password string, db int) (*RedisStore, error) {
-	client := redis.NewClient(&redis.Options{
+	return NewRedisStoreWithOptions(&redis.Options{
 		Addr:     addr,
 		Password: password,
 		DB:       db,
 	})
+}
 
-	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*1000*1000*1000) // 5 seconds
-	defer cancel()
+// NewRedisStoreWithOptions creates a store with a client built from opts,
+// exposing the pool sizes, timeouts and other go-redis client settings.
+func NewRedisStoreWithOptions(opts *redis.Options) (*RedisStore, error) {
+	client := redis.NewClient(opts)
+	if err := ping(client); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return &RedisStore{client: client}, nil
+}
 
-	if err := client.Ping(ctx).Err(); err != nil {
+// NewRedisStoreWithClient creates a store on a client built by the caller, such
+// as a *redis.ClusterClient or a failover client. Close leaves the client open.
+func NewRedisStoreWithClient(client redis.UniversalClient) (*RedisStore, error) {
+	if err := ping(client); err != nil {
 		return nil, err
 	}
+	return &RedisStore{client: client, borrowed: true}, nil
+}
+
+// ping tests the connection of a new client.
+func ping(client redis.UniversalClient) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return client.Ping(ctx).Err()
+}
+
+// SetNamespace sets a prefix applied to every key, scoping the store to a
+// namespace within the Redis database so that Clear only removes its own keys.
+// Must be called before the store is used.
+func (rs *RedisStore) SetNamespace(namespace string) {
+	rs.namespace = namespace
+}
 
-	return &RedisStore{
-		client: client,
-	}, nil
+// key returns the Redis key for a cache key.
+func (rs *RedisStore) key(key string) string {
+	return rs.namespace + key
+}
+
+// slotKey returns the internal key prefix+key of the namespace, placed in the
+// Redis Cluster hash slot of the value of key so that a script can use both.
+// Keys without a hash tag that contain '}' cannot share their slot this way.
+func (rs *RedisStore) slotKey(prefix, key string) string {
+	return rs.key(prefix + "{" + hashTag(rs.key(key)) + "}" + key)
+}
+
+// valueKeys returns the value, version and group member keys of key, which
+// share a hash slot.
+func (rs *RedisStore) valueKeys(key string) []string {
+	return []string{rs.key(key), rs.slotKey(versionKeyPrefix, key), rs.slotKey(memberKeyPrefix, key)}
+}
+
+// hashTag returns the part of key Redis Cluster hashes to pick its slot: the
+// content of its first non-empty {...} section, or the whole key.
+func hashTag(key string) string {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			return key[start+1 : start+1+end]
+		}
+	}
+	return key
 }
 
 // Get retrieves a value from Redis.
 func (rs *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
-	val, err := rs.client.Get(ctx, key).Bytes()
+	val, err := rs.client.Get(ctx, rs.key(key)).Bytes()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			return nil, ErrNotFound
@@ -45,30 +231,371 @@ func (rs *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
 	return val, nil
 }
 
-// Set stores a value in Redis.
+// Set stores a value in Redis. The key leaves the group it was written under.
 func (rs *RedisStore) Set(ctx context.Context, key string, value []byte) error {
-	return rs.client.Set(ctx, key, value, 0).Err()
+	return rs.SetWithTTL(ctx, key, value, 0)
 }
 
-// Delete removes a value from Redis.
+// SetWithTTL stores a value in Redis that expires after ttl. Its SetIfVersion
+// version, if it has one, is incremented and expires with it. The key leaves
+// the group it was written under.
+func (rs *RedisStore) SetWithTTL(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return rs.set(ctx, key, value, ttl, "", nil)
+}
+
+// SetAndPublish stores a value and publishes message on channel in a single
+// script, so subscribers are never notified of a write that failed.
+func (rs *RedisStore) SetAndPublish(ctx context.Context, key string, value []byte, channel string, message []byte) error {
+	return rs.set(ctx, key, value, 0, channel, message)
+}
+
+// set runs setScript, then removes key from the group it was written under.
+func (rs *RedisStore) set(ctx context.Context, key string, value []byte, ttl time.Duration, channel string, message []byte) error {
+	group, err := setScript.Run(ctx, rs.client, rs.valueKeys(key), value, ttl.Milliseconds(), channel, message).Text()
+	if err != nil {
+		return err
+	}
+	return rs.leaveGroup(ctx, key, group)
+}
+
+// Delete removes a value from Redis, together with its SetIfVersion version,
+// and removes it from the group it was written under.
 func (rs *RedisStore) Delete(ctx context.Context, key string) error {
-	return rs.client.Del(ctx, key).Err()
+	keys := rs.valueKeys(key)
+	var group *redis.StringCmd
+	_, err := rs.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keys[0], keys[1])
+		group = pipe.GetDel(ctx, keys[2])
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	return rs.leaveGroup(ctx, key, group.Val())
 }
 
-// Clear removes all values from Redis.
+// Clear removes all values in the store namespace, leaving other keys in the
+// database untouched. Without a namespace it flushes the whole database, as
+// every key then belongs to the store.
 func (rs *RedisStore) Clear(ctx context.Context) error {
+	if rs.namespace == "" {
+		return rs.FlushDB(ctx)
+	}
+
+	iter := rs.client.Scan(ctx, 0, escapePattern(rs.namespace)+"*", clearBatchSize).Iterator()
+	batch := make([]string, 0, clearBatchSize)
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == clearBatchSize {
+			if err := rs.client.Unlink(ctx, batch...).Err(); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return rs.client.Unlink(ctx, batch...).Err()
+	}
+	return nil
+}
+
+// FlushDB removes every key in the selected Redis database, including keys
+// written by other applications sharing it.
+func (rs *RedisStore) FlushDB(ctx context.Context) error {
 	return rs.client.FlushDB(ctx).Err()
 }
 
-// Close closes the Redis connection.
+// ScanKeys calls fn with batches of at most batchSize keys of the store namespace
+// matching the glob pattern. Keys are passed without the namespace, and the keys
+// the store uses internally for locks, versions and the hot list are skipped.
+func (rs *RedisStore) ScanKeys(ctx context.Context, pattern string, batchSize int, fn func(keys []string) error) error {
+	iter := rs.client.Scan(ctx, 0, escapePattern(rs.namespace)+pattern, int64(batchSize)).Iterator()
+	batch := make([]string, 0, batchSize)
+	for iter.Next(ctx) {
+		key := strings.TrimPrefix(iter.Val(), rs.namespace)
+		if isInternalKey(key) {
+			continue
+		}
+		batch = append(batch, key)
+		if len(batch) == batchSize {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
+// GetMulti retrieves the values of keys with a single MGET. Missing keys are omitted.
+func (rs *RedisStore) GetMulti(ctx context.Context, keys []string) (map[string][]byte, error) {
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = rs.key(key)
+	}
+	vals, err := rs.client.MGet(ctx, redisKeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string][]byte, len(vals))
+	for i, val := range vals {
+		if s, ok := val.(string); ok {
+			values[keys[i]] = []byte(s)
+		}
+	}
+	return values, nil
+}
+
+// isInternalKey reports whether key is used by the store itself rather than holding a value.
+func isInternalKey(key string) bool {
+	return strings.HasPrefix(key, internalKeyPrefix)
+}
+
+// GetExternal retrieves the string value of a Redis key written by another system.
+// The key is used as is, outside the store's namespace.
+func (rs *RedisStore) GetExternal(ctx context.Context, key string) ([]byte, error) {
+	val, err := rs.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return val, nil
+}
+
+// GetExternalHash retrieves the fields of a Redis hash written by another system.
+// The key is used as is, outside the store's namespace.
+func (rs *RedisStore) GetExternalHash(ctx context.Context, key string) (map[string]string, error) {
+	fields, err := rs.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, ErrNotFound
+	}
+	return fields, nil
+}
+
+// TryLock acquires the lock on key for ttl using SET NX PX, storing token as its owner.
+// It reports whether the lock was acquired.
+func (rs *RedisStore) TryLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	return rs.client.SetNX(ctx, rs.key(lockKeyPrefix+key), token, ttl).Result()
+}
+
+// Unlock releases the lock on key if it is still owned by token.
+// It reports whether the lock was released; false means it had expired or was taken by another owner.
+func (rs *RedisStore) Unlock(ctx context.Context, key, token string) (bool, error) {
+	n, err := unlockScript.Run(ctx, rs.client, []string{rs.key(lockKeyPrefix + key)}, token).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// ExtendLock resets the expiry of the lock on key to ttl if it is still owned by token.
+// It reports whether the lock was extended; false means it had expired or was taken by another owner.
+func (rs *RedisStore) ExtendLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	n, err := extendLockScript.Run(ctx, rs.client, []string{rs.key(lockKeyPrefix + key)}, token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// SetIfVersion stores value only if the version of key equals expectedVersion,
+// where 0 means the key was never written with SetIfVersion. It returns the new
+// version and true on success, or the current version and false on conflict.
+// Writes by Set and SetWithTTL increment an existing version too.
+func (rs *RedisStore) SetIfVersion(ctx context.Context, key string, value []byte, expectedVersion uint64) (uint64, bool, error) {
+	res, err := setIfVersionScript.Run(ctx, rs.client, rs.valueKeys(key), value, strconv.FormatUint(expectedVersion, 10)).Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	stored, _ := res[0].(int64)
+	version, _ := res[1].(int64)
+	group, _ := res[2].(string)
+	if stored != 1 {
+		return uint64(version), false, nil
+	}
+	if err := rs.leaveGroup(ctx, key, group); err != nil {
+		return 0, false, err
+	}
+	return uint64(version), true, nil
+}
+
+// Version returns the version of key written with SetIfVersion, or 0 if it has none.
+func (rs *RedisStore) Version(ctx context.Context, key string) (uint64, error) {
+	version, err := rs.client.Get(ctx, rs.slotKey(versionKeyPrefix, key)).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return version, err
+}
+
+// AddToGroup records that keys, just written with ttl, belong to group, and
+// removes them from the group they were written under before. The group lives
+// at least as long as its longest-lived key; a ttl of 0 means the keys do not expire.
+func (rs *RedisStore) AddToGroup(ctx context.Context, group string, ttl time.Duration, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	previous := make([]*redis.StatusCmd, len(keys))
+	_, err := rs.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			previous[i] = pipe.SetArgs(ctx, rs.slotKey(memberKeyPrefix, key), group, redis.SetArgs{TTL: ttl, Get: true})
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	for i, key := range keys {
+		if prev := previous[i].Val(); prev != group {
+			if err := rs.leaveGroup(ctx, key, prev); err != nil {
+				return err
+			}
+		}
+	}
+
+	args := make([]any, 0, len(keys)+1)
+	args = append(args, ttl.Milliseconds())
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	return addToGroupScript.Run(ctx, rs.client, []string{rs.key(groupKeyPrefix + group)}, args...).Err()
+}
+
+// leaveGroup removes key from group, if set.
+func (rs *RedisStore) leaveGroup(ctx context.Context, key, group string) error {
+	if group == "" {
+		return nil
+	}
+	return rs.client.SRem(ctx, rs.key(groupKeyPrefix+group), key).Err()
+}
+
+// DeleteGroup removes the keys written under group, and the group itself, and
+// returns them. The group is read and removed in a single transaction, so keys
+// added to it meanwhile are kept for the next call. Keys that expired or were
+// written under another group since are left untouched.
+func (rs *RedisStore) DeleteGroup(ctx context.Context, group string) ([]string, error) {
+	var members *redis.StringSliceCmd
+	_, err := rs.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		members = pipe.SMembers(ctx, rs.key(groupKeyPrefix+group))
+		pipe.Del(ctx, rs.key(groupKeyPrefix+group))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	all := members.Val()
+	for start := 0; start < len(all); start += clearBatchSize {
+		batch := all[start:min(start+clearBatchSize, len(all))]
+		groups := make([]*redis.StringCmd, len(batch))
+		_, err := rs.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range batch {
+				groups[i] = pipe.Get(ctx, rs.slotKey(memberKeyPrefix, key))
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+
+		// One command per key, as keys hash to different cluster slots
+		_, err = rs.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range batch {
+				if groups[i].Val() == group {
+					keys = append(keys, key)
+					pipe.Unlink(ctx, rs.valueKeys(key)...)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// RecordHotKeys adds access counts to the hot list of the store namespace and
+// trims it to its top keep keys.
+func (rs *RedisStore) RecordHotKeys(ctx context.Context, counts map[string]int64, keep int) error {
+	key := rs.key(hotKeysKey)
+	pipe := rs.client.Pipeline()
+	for member, count := range counts {
+		pipe.ZIncrBy(ctx, key, float64(count), member)
+	}
+	if keep > 0 {
+		pipe.ZRemRangeByRank(ctx, key, 0, int64(-keep-1))
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// HotKeys returns the n most accessed keys of the store namespace, most accessed first.
+func (rs *RedisStore) HotKeys(ctx context.Context, n int) ([]string, error) {
+	return rs.client.ZRevRange(ctx, rs.key(hotKeysKey), 0, int64(n-1)).Result()
+}
+
+// Ping checks that Redis is reachable.
+func (rs *RedisStore) Ping(ctx context.Context) error {
+	return rs.client.Ping(ctx).Err()
+}
+
+// Size returns the number of values in the store namespace. Without a
+// namespace it returns the number of keys in the selected Redis database, in a
+// single command; with one it scans the namespace.
+func (rs *RedisStore) Size(ctx context.Context) (int64, error) {
+	if rs.namespace == "" {
+		return rs.client.DBSize(ctx).Result()
+	}
+	var size int64
+	err := rs.ScanKeys(ctx, "*", clearBatchSize, func(keys []string) error {
+		size += int64(len(keys))
+		return nil
+	})
+	return size, err
+}
+
+// Close closes the Redis connection, unless the client was supplied with NewRedisStoreWithClient.
 func (rs *RedisStore) Close() error {
+	if rs.borrowed {
+		return nil
+	}
 	return rs.client.Close()
 }
 
 // GetClient returns the underlying Redis client.
-func (rs *RedisStore) GetClient() *redis.Client {
+func (rs *RedisStore) GetClient() redis.UniversalClient {
 	return rs.client
 }
 
+// escapePattern escapes glob special characters so s matches itself in SCAN MATCH.
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 // ErrNotFound is returned when a key is not found.
 var ErrNotFound = errors.New("key not found in redis")
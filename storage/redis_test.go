@@ -2,8 +2,13 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 func TestNewRedisStore(t *testing.T) {
@@ -18,6 +23,35 @@ func TestNewRedisStore(t *testing.T) {
 	}
 }
 
+func TestNewRedisStoreWithOptionsUnreachable(t *testing.T) {
+	_, err := NewRedisStoreWithOptions(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond})
+	if err == nil {
+		t.Fatal("Expected an error for an unreachable Redis")
+	}
+}
+
+func TestNewRedisStoreWithClient(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	defer client.Close()
+
+	store, err := NewRedisStoreWithClient(client)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	if store.GetClient() != client {
+		t.Fatal("Expected the store to use the supplied client")
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Fatalf("Expected the supplied client to stay open after Close: %v", err)
+	}
+}
+
 func TestRedisStoreSet(t *testing.T) {
 	store, err := NewRedisStore("localhost:6379", "", 0)
 	if err != nil {
@@ -118,6 +152,7 @@ func TestRedisStoreClear(t *testing.T) {
 		t.Fatalf("Failed to create Redis store: %v", err)
 	}
 	defer store.Close()
+	store.SetNamespace("test:clear:")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -226,3 +261,551 @@ func TestRedisStoreGetError(t *testing.T) {
 		t.Fatalf("Expected ErrNotFound, got %v", err)
 	}
 }
+
+func TestRedisStoreSize(t *testing.T) {
+	store, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	defer store.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := store.FlushDB(ctx); err != nil {
+		t.Fatalf("Failed to flush store: %v", err)
+	}
+	if err := store.Set(ctx, "test:size:1", []byte("a")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := store.Set(ctx, "test:size:2", []byte("b")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	size, err := store.Size(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get size: %v", err)
+	}
+	if size != 2 {
+		t.Fatalf("Expected size 2, got %d", size)
+	}
+
+	store.SetNamespace("test:size:")
+	if err := store.Set(ctx, "3", []byte("c")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := store.AddToGroup(ctx, "g", 0, "3"); err != nil {
+		t.Fatalf("AddToGroup failed: %v", err)
+	}
+	if err := store.client.Set(ctx, "other:1", "d", 0).Err(); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	size, err = store.Size(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get size: %v", err)
+	}
+	if size != 3 {
+		t.Fatalf("Expected the 3 values of the namespace, got %d", size)
+	}
+}
+
+func TestRedisStoreClearOnlyRemovesNamespace(t *testing.T) {
+	store, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	defer store.Close()
+
+	other, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	defer other.Close()
+
+	store.SetNamespace("app-a[1]:")
+	other.SetNamespace("app-b:")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := store.Set(ctx, "key", []byte("a")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := other.Set(ctx, "key", []byte("b")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+
+	if err := store.Clear(ctx); err != nil {
+		t.Fatalf("Failed to clear store: %v", err)
+	}
+	if _, err := store.Get(ctx, "key"); err != ErrNotFound {
+		t.Fatalf("Expected namespaced key to be cleared, got %v", err)
+	}
+	if value, err := other.Get(ctx, "key"); err != nil || string(value) != "b" {
+		t.Fatalf("Keys of other namespaces should survive Clear, got %q (%v)", value, err)
+	}
+}
+
+func TestRedisStoreClearWithoutNamespace(t *testing.T) {
+	store, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	if err := store.Set(ctx, "key", []byte("a")); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := store.Clear(ctx); err != nil {
+		t.Fatalf("Clear without a namespace should flush the database: %v", err)
+	}
+	if _, err := store.Get(ctx, "key"); err != ErrNotFound {
+		t.Fatalf("Expected key to be cleared, got %v", err)
+	}
+}
+
+func TestEscapePattern(t *testing.T) {
+	if got := escapePattern(`a*b?c[d]e\f`); got != `a\*b\?c\[d\]e\\f` {
+		t.Fatalf("Unexpected escaped pattern: %s", got)
+	}
+}
+
+func TestRedisStoreSlotKeysShareHashSlot(t *testing.T) {
+	for _, tt := range []struct{ namespace, key string }{
+		{"", "user:1"},
+		{"app:", "user:1"},
+		{"app:", "user:{42}:profile"},
+		{"app:{tenant}:", "user:1"},
+	} {
+		store := &RedisStore{namespace: tt.namespace}
+		want := hashTag(store.key(tt.key))
+		for _, key := range store.valueKeys(tt.key)[1:] {
+			if got := hashTag(key); got != want {
+				t.Errorf("%q in namespace %q: expected %q to hash %q, got %q", tt.key, tt.namespace, key, want, got)
+			}
+		}
+	}
+}
+
+func TestRedisStoreLock(t *testing.T) {
+	store, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	defer store.Close()
+	store.SetNamespace("test:lock:")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	acquired, err := store.TryLock(ctx, "key", "owner-a", time.Second)
+	if err != nil || !acquired {
+		t.Fatalf("Expected lock to be acquired, got %v (err=%v)", acquired, err)
+	}
+	defer store.Unlock(ctx, "key", "owner-a")
+
+	if acquired, _ := store.TryLock(ctx, "key", "owner-b", time.Second); acquired {
+		t.Fatal("Lock should not be acquired while held")
+	}
+	if released, _ := store.Unlock(ctx, "key", "owner-b"); released {
+		t.Fatal("Unlock should not release a lock held by another owner")
+	}
+	if extended, _ := store.ExtendLock(ctx, "key", "owner-b", time.Second); extended {
+		t.Fatal("ExtendLock should not extend a lock held by another owner")
+	}
+	if extended, err := store.ExtendLock(ctx, "key", "owner-a", time.Second); err != nil || !extended {
+		t.Fatalf("Expected lock to be extended, got %v (err=%v)", extended, err)
+	}
+
+	released, err := store.Unlock(ctx, "key", "owner-a")
+	if err != nil || !released {
+		t.Fatalf("Expected lock to be released, got %v (err=%v)", released, err)
+	}
+	if acquired, _ := store.TryLock(ctx, "key", "owner-b", time.Second); !acquired {
+		t.Fatal("Lock should be acquired once released")
+	}
+	store.Unlock(ctx, "key", "owner-b")
+}
+
+func TestRedisStoreGetExternal(t *testing.T) {
+	store, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	defer store.Close()
+	store.SetNamespace("test:external:")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	client := store.GetClient()
+	defer client.Del(ctx, "legacy:string", "legacy:hash")
+	if err := client.Set(ctx, "legacy:string", "value", 0).Err(); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	if err := client.HSet(ctx, "legacy:hash", "field", "value").Err(); err != nil {
+		t.Fatalf("Failed to set hash: %v", err)
+	}
+
+	// External keys are read outside the namespace
+	data, err := store.GetExternal(ctx, "legacy:string")
+	if err != nil || string(data) != "value" {
+		t.Fatalf("Expected external string, got %q (err=%v)", data, err)
+	}
+	fields, err := store.GetExternalHash(ctx, "legacy:hash")
+	if err != nil || fields["field"] != "value" {
+		t.Fatalf("Expected external hash, got %v (err=%v)", fields, err)
+	}
+
+	if _, err := store.GetExternal(ctx, "legacy:missing"); err != ErrNotFound {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := store.GetExternalHash(ctx, "legacy:missing"); err != ErrNotFound {
+		t.Fatalf("Expected ErrNotFound for missing hash, got %v", err)
+	}
+}
+
+func TestRedisStoreSetIfVersion(t *testing.T) {
+	store, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	defer store.Close()
+	store.SetNamespace("test:version:")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	defer store.Clear(ctx)
+
+	version, stored, err := store.SetIfVersion(ctx, "key", []byte("v1"), 0)
+	if err != nil || !stored || version != 1 {
+		t.Fatalf("Expected version 1, got %d (stored=%v, err=%v)", version, stored, err)
+	}
+
+	version, stored, err = store.SetIfVersion(ctx, "key", []byte("stale"), 0)
+	if err != nil || stored || version != 1 {
+		t.Fatalf("Expected conflict at version 1, got %d (stored=%v, err=%v)", version, stored, err)
+	}
+	if data, _ := store.Get(ctx, "key"); string(data) != "v1" {
+		t.Fatalf("Conflicting write must not be stored, got %q", data)
+	}
+
+	if current, err := store.Version(ctx, "key"); err != nil || current != 1 {
+		t.Fatalf("Expected Version 1, got %d (err=%v)", current, err)
+	}
+	if current, err := store.Version(ctx, "missing"); err != nil || current != 0 {
+		t.Fatalf("Expected Version 0 for a missing key, got %d (err=%v)", current, err)
+	}
+}
+
+func TestRedisStoreSetBumpsVersion(t *testing.T) {
+	store, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	defer store.Close()
+	store.SetNamespace("test:version-bump:")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	defer store.Clear(ctx)
+
+	if _, _, err := store.SetIfVersion(ctx, "key", []byte("v1"), 0); err != nil {
+		t.Fatalf("SetIfVersion failed: %v", err)
+	}
+	read, err := store.Version(ctx, "key")
+	if err != nil {
+		t.Fatalf("Version failed: %v", err)
+	}
+	if err := store.Set(ctx, "key", []byte("other writer")); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, stored, err := store.SetIfVersion(ctx, "key", []byte("v2"), read); err != nil || stored {
+		t.Fatalf("Expected a conflict after a Set, got stored=%v (err=%v)", stored, err)
+	}
+	if data, _ := store.Get(ctx, "key"); string(data) != "other writer" {
+		t.Fatalf("Expected the value written by Set to be kept, got %q", data)
+	}
+	if err := store.SetAndPublish(ctx, "key", []byte("published"), "test:version-bump:channel", []byte("m")); err != nil {
+		t.Fatalf("SetAndPublish failed: %v", err)
+	}
+	if version, _ := store.Version(ctx, "key"); version != read+2 {
+		t.Fatalf("Expected every write to bump the version to %d, got %d", read+2, version)
+	}
+}
+
+func TestRedisStoreVersionFollowsValue(t *testing.T) {
+	store, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	defer store.Close()
+	store.SetNamespace("test:version-lifecycle:")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	defer store.Clear(ctx)
+
+	if _, _, err := store.SetIfVersion(ctx, "key", []byte("v1"), 0); err != nil {
+		t.Fatalf("SetIfVersion failed: %v", err)
+	}
+	if err := store.SetWithTTL(ctx, "key", []byte("v2"), time.Minute); err != nil {
+		t.Fatalf("SetWithTTL failed: %v", err)
+	}
+	if ttl := store.client.PTTL(ctx, store.slotKey(versionKeyPrefix, "key")).Val(); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("Expected the version to expire with the value, got TTL %v", ttl)
+	}
+
+	if err := store.Delete(ctx, "key"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if version, err := store.Version(ctx, "key"); err != nil || version != 0 {
+		t.Fatalf("Expected the version to be deleted with the value, got %d (err=%v)", version, err)
+	}
+}
+
+func TestRedisStoreGroupMembership(t *testing.T) {
+	store, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	defer store.Close()
+	store.SetNamespace("test:group-membership:")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	defer store.Clear(ctx)
+
+	write := func(key, group string, ttl time.Duration) {
+		t.Helper()
+		if err := store.SetWithTTL(ctx, key, []byte("v"), ttl); err != nil {
+			t.Fatalf("SetWithTTL failed: %v", err)
+		}
+		if group != "" {
+			if err := store.AddToGroup(ctx, group, ttl, key); err != nil {
+				t.Fatalf("AddToGroup failed: %v", err)
+			}
+		}
+	}
+	members := func(group string) string {
+		keys, _ := store.client.SMembers(ctx, store.key(groupKeyPrefix+group)).Result()
+		sort.Strings(keys)
+		return strings.Join(keys, ",")
+	}
+
+	write("overwritten", "g", 0)
+	write("deleted", "g", 0)
+	write("moved", "g", 0)
+	write("kept", "g", 0)
+	write("overwritten", "", 0)
+	if err := store.Delete(ctx, "deleted"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	write("moved", "other", 0)
+	if got := members("g"); got != "kept" {
+		t.Fatalf("Expected overwritten, deleted and moved keys to leave the group, got %q", got)
+	}
+	if got := members("other"); got != "moved" {
+		t.Fatalf("Expected the moved key in its new group, got %q", got)
+	}
+
+	// The group outlives its longest-lived member, and persists with a member that does not expire
+	write("short", "ttl", time.Minute)
+	write("long", "ttl", time.Hour)
+	write("shorter", "ttl", time.Second)
+	if ttl := store.client.PTTL(ctx, store.key(groupKeyPrefix+"ttl")).Val(); ttl <= time.Minute || ttl > time.Hour {
+		t.Fatalf("Expected the group to live as long as its longest member, got %v", ttl)
+	}
+	write("forever", "ttl", 0)
+	if ttl := store.client.PTTL(ctx, store.key(groupKeyPrefix+"ttl")).Val(); ttl != -1 {
+		t.Fatalf("Expected the group to persist with a member that does not expire, got %v", ttl)
+	}
+
+	// DeleteGroup leaves keys that were written again since alone
+	if err := store.client.SAdd(ctx, store.key(groupKeyPrefix+"g"), "overwritten").Err(); err != nil {
+		t.Fatalf("SAdd failed: %v", err)
+	}
+	deleted, err := store.DeleteGroup(ctx, "g")
+	if err != nil {
+		t.Fatalf("DeleteGroup failed: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "kept" {
+		t.Fatalf("Expected only the current member to be deleted, got %v", deleted)
+	}
+	if _, err := store.Get(ctx, "overwritten"); err != nil {
+		t.Fatalf("Expected a key that left the group to be kept, got %v", err)
+	}
+	if _, err := store.Get(ctx, "kept"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected the member to be deleted, got %v", err)
+	}
+}
+
+func TestRedisStoreInternalKeysLeaveValuesAlone(t *testing.T) {
+	store, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	defer store.Close()
+	store.SetNamespace("test:internal:")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	defer store.Clear(ctx)
+
+	userKeys := []string{"lock:key", "version:key", "group:g", "hotkeys"}
+	for _, key := range userKeys {
+		if err := store.Set(ctx, key, []byte("value")); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	if locked, err := store.TryLock(ctx, "key", "token", time.Minute); err != nil || !locked {
+		t.Fatalf("TryLock must not see the value of lock:key, got %v (err=%v)", locked, err)
+	}
+	if _, stored, err := store.SetIfVersion(ctx, "key", []byte("v1"), 0); err != nil || !stored {
+		t.Fatalf("SetIfVersion must not read the value of version:key, got %v (err=%v)", stored, err)
+	}
+	if err := store.AddToGroup(ctx, "g", 0, "key"); err != nil {
+		t.Fatalf("AddToGroup failed: %v", err)
+	}
+	if err := store.RecordHotKeys(ctx, map[string]int64{"key": 1}, 0); err != nil {
+		t.Fatalf("RecordHotKeys failed: %v", err)
+	}
+
+	for _, key := range userKeys {
+		if data, err := store.Get(ctx, key); err != nil || string(data) != "value" {
+			t.Errorf("Expected %s to keep its value, got %q (err=%v)", key, data, err)
+		}
+	}
+	var scanned []string
+	err = store.ScanKeys(ctx, "*", 10, func(keys []string) error {
+		scanned = append(scanned, keys...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanKeys failed: %v", err)
+	}
+	sort.Strings(scanned)
+	if strings.Join(scanned, ",") != "group:g,hotkeys,key,lock:key,version:key" {
+		t.Fatalf("Expected every value key and no internal key, got %q", scanned)
+	}
+}
+
+func TestRedisStoreHotKeys(t *testing.T) {
+	store, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	defer store.Close()
+	store.SetNamespace("test:hotkeys:")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	defer store.Clear(ctx)
+
+	if err := store.RecordHotKeys(ctx, map[string]int64{"a": 1, "b": 5, "c": 3}, 0); err != nil {
+		t.Fatalf("RecordHotKeys failed: %v", err)
+	}
+	if err := store.RecordHotKeys(ctx, map[string]int64{"a": 10}, 2); err != nil {
+		t.Fatalf("RecordHotKeys failed: %v", err)
+	}
+
+	keys, err := store.HotKeys(ctx, 5)
+	if err != nil {
+		t.Fatalf("HotKeys failed: %v", err)
+	}
+	if len(keys) != 2 || keys[0] != "a" || keys[1] != "b" {
+		t.Fatalf("Expected hot list [a b] trimmed to 2 keys, got %v", keys)
+	}
+}
+
+func TestRedisStoreScanKeysAndGetMulti(t *testing.T) {
+	store, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	defer store.Close()
+	store.SetNamespace("test:warmup:")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	defer store.Clear(ctx)
+
+	for _, key := range []string{"config:a", "config:b", "other"} {
+		if err := store.Set(ctx, key, []byte(key)); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	if _, err := store.TryLock(ctx, "config:a", "token", time.Minute); err != nil {
+		t.Fatalf("TryLock failed: %v", err)
+	}
+
+	var scanned []string
+	err = store.ScanKeys(ctx, "*", 1, func(keys []string) error {
+		if len(keys) != 1 {
+			t.Fatalf("Expected batches of 1 key, got %v", keys)
+		}
+		scanned = append(scanned, keys...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanKeys failed: %v", err)
+	}
+	sort.Strings(scanned)
+	if strings.Join(scanned, ",") != "config:a,config:b,other" {
+		t.Fatalf("Expected value keys without namespace or locks, got %v", scanned)
+	}
+
+	values, err := store.GetMulti(ctx, []string{"config:a", "missing", "other"})
+	if err != nil {
+		t.Fatalf("GetMulti failed: %v", err)
+	}
+	if len(values) != 2 || string(values["config:a"]) != "config:a" || string(values["other"]) != "other" {
+		t.Fatalf("Unexpected values: %v", values)
+	}
+}
+
+func TestRedisStorePing(t *testing.T) {
+	store, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+
+	if err := store.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	store.Close()
+	if err := store.Ping(context.Background()); err == nil {
+		t.Fatal("Expected Ping to fail on a closed store")
+	}
+}
+
+func TestRedisStoreSetAndPublish(t *testing.T) {
+	store, err := NewRedisStore("localhost:6379", "", 1)
+	if err != nil {
+		t.Fatalf("Failed to create Redis store: %v", err)
+	}
+	defer store.Close()
+	store.SetNamespace("outbox-test:")
+	ctx := context.Background()
+
+	sub := store.GetClient().Subscribe(ctx, "outbox-test-channel")
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+
+	if err := store.SetAndPublish(ctx, "key", []byte("value"), "outbox-test-channel", []byte("event")); err != nil {
+		t.Fatalf("SetAndPublish failed: %v", err)
+	}
+	defer store.Delete(ctx, "key")
+
+	value, err := store.Get(ctx, "key")
+	if err != nil || string(value) != "value" {
+		t.Fatalf("Expected the value to be stored, got %q, %v", value, err)
+	}
+	msg, err := sub.ReceiveMessage(ctx)
+	if err != nil || msg.Payload != "event" {
+		t.Fatalf("Expected the message to be published, got %v, %v", msg, err)
+	}
+}
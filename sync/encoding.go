@@ -0,0 +1,239 @@
+package sync
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/huykn/distributed-cache/types"
+)
+
+// EventEncoding selects how events are encoded on the wire.
+type EventEncoding string
+
+const (
+	// EventEncodingJSON encodes events as JSON documents. Values are base64
+	// encoded, inflating them by about a third. It is understood by every version.
+	EventEncodingJSON EventEncoding = "json"
+
+	// EventEncodingBinary encodes events in a compact length-prefixed framing
+	// with raw values. Every pod must run a version able to decode it.
+	EventEncodingBinary EventEncoding = "binary"
+)
+
+// binaryEventVersion is the first byte of binary-encoded events. JSON events
+// always start with '{', so DecodeEvent tells the two apart and accepts both.
+const binaryEventVersion byte = 0x01
+
+// EncodeEvent encodes event with encoding, JSON when encoding is empty.
+//
+// The binary framing is the version byte followed by the key, sender, action
+// and value as uvarint length-prefixed bytes, the sequence number as a uvarint,
+// the TTL in nanoseconds as a varint, the tag count followed by each tag and,
+// when the event is stamped or flagged, the publish time in Unix nanoseconds as
+// a varint followed, when flagged, by the flags as a uvarint, the hybrid logical
+// clock as a uvarint when flagClock is set, the origin as length-prefixed
+// bytes when flagOrigin is set and the dependency count followed by each
+// dependency when flagDependsOn is set.
+func EncodeEvent(event InvalidationEvent, encoding EventEncoding) ([]byte, error) {
+	if encoding != EventEncodingBinary {
+		return json.Marshal(event)
+	}
+
+	size := 1 + 8*binary.MaxVarintLen64 + len(event.Key) + len(event.Sender) + len(event.Action) + len(event.Value) + len(event.Origin)
+	for _, tag := range event.Tags {
+		size += binary.MaxVarintLen64 + len(tag)
+	}
+	for _, key := range event.DependsOn {
+		size += binary.MaxVarintLen64 + len(key)
+	}
+	buf := make([]byte, 0, size)
+	buf = append(buf, binaryEventVersion)
+	buf = appendBytes(buf, []byte(event.Key))
+	buf = appendBytes(buf, []byte(event.Sender))
+	buf = appendBytes(buf, []byte(event.Action))
+	buf = appendBytes(buf, event.Value)
+	buf = binary.AppendUvarint(buf, event.Seq)
+	buf = binary.AppendVarint(buf, int64(event.TTL))
+	buf = binary.AppendUvarint(buf, uint64(len(event.Tags)))
+	for _, tag := range event.Tags {
+		buf = appendBytes(buf, []byte(tag))
+	}
+	flags := eventFlags(event)
+	if event.Time != 0 || flags != 0 {
+		buf = binary.AppendVarint(buf, event.Time)
+	}
+	if flags != 0 {
+		buf = binary.AppendUvarint(buf, flags)
+	}
+	if flags&flagClock != 0 {
+		buf = binary.AppendUvarint(buf, event.Clock)
+	}
+	if flags&flagOrigin != 0 {
+		buf = appendBytes(buf, []byte(event.Origin))
+	}
+	if flags&flagDependsOn != 0 {
+		buf = binary.AppendUvarint(buf, uint64(len(event.DependsOn)))
+		for _, key := range event.DependsOn {
+			buf = appendBytes(buf, []byte(key))
+		}
+	}
+	return buf, nil
+}
+
+// DecodeEvent decodes an event encoded with any EventEncoding.
+func DecodeEvent(data []byte) (InvalidationEvent, error) {
+	var event InvalidationEvent
+	if len(data) == 0 {
+		return event, ErrMalformedEvent
+	}
+	if data[0] == '{' {
+		err := json.Unmarshal(data, &event)
+		return event, err
+	}
+	if data[0] != binaryEventVersion {
+		return event, ErrUnsupportedEventVersion
+	}
+
+	d := decoder{data: data[1:]}
+	event.Key = string(d.bytes())
+	event.Sender = string(d.bytes())
+	event.Action = types.Action(d.bytes())
+	if value := d.bytes(); len(value) > 0 {
+		event.Value = value
+	}
+	event.Seq = d.uvarint()
+	event.TTL = time.Duration(d.varint())
+	if n := d.uvarint(); n > 0 {
+		if n > uint64(len(d.data)) {
+			return InvalidationEvent{}, ErrMalformedEvent
+		}
+		event.Tags = make([]string, n)
+		for i := range event.Tags {
+			event.Tags[i] = string(d.bytes())
+		}
+	}
+	if len(d.data) > 0 {
+		// Events of older versions end after the tags; unflagged events are stamped
+		// with a time that is never 0, and flags are never 0
+		event.Time = d.varint()
+		if len(d.data) > 0 {
+			flags := d.uvarint()
+			if flags == 0 {
+				return InvalidationEvent{}, ErrMalformedEvent
+			}
+			event.Relay = flags&flagRelay != 0
+			if flags&flagClock != 0 {
+				if event.Clock = d.uvarint(); event.Clock == 0 {
+					return InvalidationEvent{}, ErrMalformedEvent
+				}
+			}
+			if flags&flagOrigin != 0 {
+				if event.Origin = string(d.bytes()); event.Origin == "" {
+					return InvalidationEvent{}, ErrMalformedEvent
+				}
+			}
+			if flags&flagDependsOn != 0 {
+				n := d.uvarint()
+				if n == 0 || n > uint64(len(d.data)) {
+					return InvalidationEvent{}, ErrMalformedEvent
+				}
+				event.DependsOn = make([]string, n)
+				for i := range event.DependsOn {
+					event.DependsOn[i] = string(d.bytes())
+				}
+			}
+		} else if event.Time == 0 {
+			return InvalidationEvent{}, ErrMalformedEvent
+		}
+	}
+	if d.err != nil || len(d.data) > 0 {
+		return InvalidationEvent{}, ErrMalformedEvent
+	}
+	return event, nil
+}
+
+// Binary event flags.
+const (
+	flagRelay     uint64 = 1 << 0 // relayed events
+	flagClock     uint64 = 1 << 1 // events stamped with a hybrid logical clock
+	flagOrigin    uint64 = 1 << 2 // events stamped with the instance of their synchronizer
+	flagDependsOn uint64 = 1 << 3 // events of values declaring dependencies
+)
+
+// eventFlags returns the binary flags of event.
+func eventFlags(event InvalidationEvent) uint64 {
+	var flags uint64
+	if event.Relay {
+		flags |= flagRelay
+	}
+	if event.Clock != 0 {
+		flags |= flagClock
+	}
+	if event.Origin != "" {
+		flags |= flagOrigin
+	}
+	if len(event.DependsOn) > 0 {
+		flags |= flagDependsOn
+	}
+	return flags
+}
+
+// appendBytes appends b prefixed with its length.
+func appendBytes(buf, b []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// decoder reads the fields of a binary event, recording the first error.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = ErrMalformedEvent
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.err = ErrMalformedEvent
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.uvarint()
+	if d.err != nil {
+		return nil
+	}
+	if n > uint64(len(d.data)) {
+		d.err = ErrMalformedEvent
+		return nil
+	}
+	b := d.data[:n:n]
+	d.data = d.data[n:]
+	return b
+}
+
+// ErrMalformedEvent is returned when an event cannot be decoded.
+var ErrMalformedEvent = errors.New("malformed synchronization event")
+
+// ErrUnsupportedEventVersion is returned for events encoded by a newer, unknown framing version.
+var ErrUnsupportedEventVersion = errors.New("unsupported synchronization event encoding version")
@@ -0,0 +1,90 @@
+package sync
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEncodeEventRoundTrip(t *testing.T) {
+	events := []InvalidationEvent{
+		{Key: "user:1", Sender: "pod-1", Action: "set", Value: []byte(`{"name":"alice"}`), Seq: 42, TTL: time.Minute, Tags: []string{"users", "tenant:7"}},
+		{Key: "user:1", Sender: "pod-1", Action: "delete"},
+		{Sender: "pod-2", Action: "clear", Seq: 1},
+		{Key: "user:2", Sender: "pod-3", Action: "invalidate", Seq: 7, Time: time.Now().UnixNano()},
+		{Key: "user:3", Sender: "pod-4", Action: "set", Value: []byte(`1`), Time: time.Now().UnixNano(), Relay: true},
+		{Key: "user:3", Sender: "pod-4", Action: "set", Value: []byte(`1`), Relay: true},
+		{Key: "user:4", Sender: "pod-5", Action: "delete", Time: time.Now().UnixNano(), Clock: 1 << 40},
+		{Key: "user:4", Sender: "pod-5", Action: "set", Value: []byte(`2`), Relay: true, Clock: 7},
+		{Key: "user:5", Sender: "pod-6", Action: "invalidate", Time: time.Now().UnixNano(), Origin: "0123456789abcdef"},
+		{Key: "report:1", Sender: "pod-7", Action: "set", Value: []byte(`3`), Clock: 9, DependsOn: []string{"user:1", "user:2"}},
+	}
+	for _, encoding := range []EventEncoding{EventEncodingJSON, EventEncodingBinary} {
+		for _, event := range events {
+			data, err := EncodeEvent(event, encoding)
+			if err != nil {
+				t.Fatalf("%s: EncodeEvent failed: %v", encoding, err)
+			}
+			got, err := DecodeEvent(data)
+			if err != nil {
+				t.Fatalf("%s: DecodeEvent failed: %v", encoding, err)
+			}
+			if !reflect.DeepEqual(got, event) {
+				t.Errorf("%s: expected %+v, got %+v", encoding, event, got)
+			}
+		}
+	}
+}
+
+func TestEncodeEventBinaryIsSmaller(t *testing.T) {
+	event := InvalidationEvent{Key: "user:1", Sender: "pod-1", Action: "set", Value: bytes.Repeat([]byte("x"), 3000)}
+
+	jsonData, _ := EncodeEvent(event, EventEncodingJSON)
+	binaryData, _ := EncodeEvent(event, EventEncodingBinary)
+	if binaryData[0] != binaryEventVersion {
+		t.Fatalf("Expected the binary version byte, got %#x", binaryData[0])
+	}
+	if len(binaryData) >= len(jsonData)*3/4 {
+		t.Errorf("Expected binary framing to avoid base64 overhead, got %d vs %d bytes", len(binaryData), len(jsonData))
+	}
+}
+
+func TestDecodeEventAcceptsLegacyJSON(t *testing.T) {
+	data, _ := json.Marshal(map[string]any{"key": "k", "sender": "old-pod", "action": "invalidate"})
+	event, err := DecodeEvent(data)
+	if err != nil {
+		t.Fatalf("DecodeEvent failed: %v", err)
+	}
+	if event.Key != "k" || event.Sender != "old-pod" || event.Action != "invalidate" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+}
+
+func TestDecodeEventRejectsInvalidFrames(t *testing.T) {
+	valid, _ := EncodeEvent(InvalidationEvent{Key: "k", Sender: "p", Action: "set", Tags: []string{"t"}}, EventEncodingBinary)
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"empty", nil, ErrMalformedEvent},
+		{"unknown version", []byte{0x7f, 0x00}, ErrUnsupportedEventVersion},
+		{"truncated", valid[:len(valid)-1], ErrMalformedEvent},
+		{"trailing bytes", append(append([]byte{}, valid...), 0x00), ErrMalformedEvent},
+		{"empty flags", append(append([]byte{}, valid...), 0x00, 0x00), ErrMalformedEvent},
+		{"empty clock", append(append([]byte{}, valid...), 0x00, byte(flagClock), 0x00), ErrMalformedEvent},
+		{"empty origin", append(append([]byte{}, valid...), 0x00, byte(flagOrigin), 0x00), ErrMalformedEvent},
+		{"empty dependencies", append(append([]byte{}, valid...), 0x00, byte(flagDependsOn), 0x00), ErrMalformedEvent},
+		{"oversized length", []byte{binaryEventVersion, 0x7f}, ErrMalformedEvent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeEvent(tt.data); err != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
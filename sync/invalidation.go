@@ -2,8 +2,14 @@ package sync
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 
@@ -13,32 +19,163 @@ import (
 // InvalidationEvent is an alias for types.InvalidationEvent
 type InvalidationEvent = types.InvalidationEvent
 
+// actionPing marks events used by WaitReady to confirm the subscription round trip.
+const actionPing types.Action = "ping"
+
+// unsubscribeTimeout bounds unsubscribing a closed synchronizer from a PubSubMux.
+const unsubscribeTimeout = 5 * time.Second
+
+// pingInterval is how often WaitReady re-publishes pings that have not come back.
+const pingInterval = 50 * time.Millisecond
+
 // PubSubSynchronizer implements cache synchronization using Redis Pub/Sub.
 type PubSubSynchronizer struct {
-	client         *redis.Client
+	client         redis.UniversalClient
 	channel        string
 	podID          string
+	prefixChannels []prefixChannel
+	patterns       []string
+	additional     []string
+	encoding       EventEncoding
+	receiveOwn     bool
 	pubsub         *redis.PubSub
+	mux            *PubSubMux
+	unsubscribe    func(ctx context.Context) error
 	callbacks      []func(event InvalidationEvent)
 	callbacksMutex sync.RWMutex
+	instance       string // random prefix of the ping tokens of this synchronizer
+	pings          map[string]bool
+	pingsMutex     sync.Mutex
+	seqs           map[string]uint64 // channel -> last published sequence
+	lastSeqs       map[string]uint64 // sender and channel -> last received sequence
+	seqsMutex      sync.Mutex
+	gapCallbacks   []func(gap types.EventGap)
+	dupCallbacks   []func()
 	done           chan struct{}
 	wg             sync.WaitGroup
 }
 
 // NewPubSubSynchronizer creates a new Pub/Sub synchronizer.
-func NewPubSubSynchronizer(client *redis.Client, channel, podID string) *PubSubSynchronizer {
+func NewPubSubSynchronizer(client redis.UniversalClient, channel, podID string) *PubSubSynchronizer {
+	instance, _ := newPingToken()
 	return &PubSubSynchronizer{
 		client:    client,
 		channel:   channel,
 		podID:     podID,
+		instance:  instance,
 		callbacks: make([]func(event InvalidationEvent), 0),
+		pings:     make(map[string]bool),
+		seqs:      make(map[string]uint64),
+		lastSeqs:  make(map[string]uint64),
 		done:      make(chan struct{}),
 	}
 }
 
+// prefixChannel maps a key prefix to the channel its events are published on.
+type prefixChannel struct {
+	prefix  string
+	channel string
+}
+
+// SetPrefixChannels configures per-prefix channel routing.
+// Events for keys starting with a configured prefix are published on the mapped
+// channel instead of the default one; the longest matching prefix wins.
+// Must be called before Subscribe so the mapped channels are subscribed as well.
+func (ps *PubSubSynchronizer) SetPrefixChannels(prefixChannels map[string]string) {
+	ps.prefixChannels = make([]prefixChannel, 0, len(prefixChannels))
+	for prefix, channel := range prefixChannels {
+		ps.prefixChannels = append(ps.prefixChannels, prefixChannel{prefix: prefix, channel: channel})
+	}
+	sort.Slice(ps.prefixChannels, func(i, j int) bool {
+		return len(ps.prefixChannels[i].prefix) > len(ps.prefixChannels[j].prefix)
+	})
+}
+
+// SetPatternChannels makes the synchronizer also subscribe to glob-style channel
+// patterns, e.g. "cache:invalidate:*", to receive the events of several logical
+// channels, such as one per namespace or tenant, through one subscription. The
+// channel an event was received on is set in its Channel field for callbacks to
+// route it. Events of a channel matching several patterns are delivered once per
+// pattern, so patterns should not overlap; events of a subscribed channel are
+// delivered once. Must be called before Subscribe.
+func (ps *PubSubSynchronizer) SetPatternChannels(patterns []string) {
+	ps.patterns = patterns
+}
+
+// SetAdditionalChannels makes Publish also send the events of cache mutations,
+// "set", "invalidate", "delete" and "clear", to each of channels, in the same
+// round trip, for consumers such as audit or change data capture pipelines that
+// do not subscribe to the main channels. Fanned out events keep the sequence
+// number of their main channel. Must be called before Publish.
+func (ps *PubSubSynchronizer) SetAdditionalChannels(channels []string) {
+	ps.additional = channels
+}
+
+// SetEncoding sets how published events are encoded. Received events are
+// decoded whatever their encoding. Must be called before Subscribe.
+func (ps *PubSubSynchronizer) SetEncoding(encoding EventEncoding) {
+	ps.encoding = encoding
+}
+
+// SetReceiveOwnEvents makes the synchronizer deliver the events published by other
+// synchronizers with the same pod ID, e.g. other caches in the same process,
+// instead of dropping them as its own. Published events are then stamped with the
+// instance of this synchronizer, which only drops the events it published itself,
+// so every synchronizer sharing the pod ID must enable it. Must be called before
+// Subscribe.
+func (ps *PubSubSynchronizer) SetReceiveOwnEvents(receive bool) {
+	ps.receiveOwn = receive
+}
+
+// SetMux makes the synchronizer subscribe through mux, sharing its Pub/Sub
+// connection with the other synchronizers using it, instead of opening its own.
+// Publishing still uses the client. Must be called before Subscribe.
+func (ps *PubSubSynchronizer) SetMux(mux *PubSubMux) {
+	ps.mux = mux
+}
+
+// ChannelForKey returns the channel that events for the given key are published on.
+func (ps *PubSubSynchronizer) ChannelForKey(key string) string {
+	for _, pc := range ps.prefixChannels {
+		if strings.HasPrefix(key, pc.prefix) {
+			return pc.channel
+		}
+	}
+	return ps.channel
+}
+
+// Channels returns the distinct channels this synchronizer subscribes to.
+func (ps *PubSubSynchronizer) Channels() []string {
+	channels := []string{ps.channel}
+	seen := map[string]bool{ps.channel: true}
+	for _, pc := range ps.prefixChannels {
+		if !seen[pc.channel] {
+			seen[pc.channel] = true
+			channels = append(channels, pc.channel)
+		}
+	}
+	return channels
+}
+
 // Subscribe starts listening for invalidation events.
 func (ps *PubSubSynchronizer) Subscribe(ctx context.Context) error {
-	ps.pubsub = ps.client.Subscribe(ctx, ps.channel)
+	if ps.mux != nil {
+		unsubscribe, err := ps.mux.SubscribePatterns(ctx, ps.Channels(), ps.patterns, ps.handleMessage)
+		if err != nil {
+			return err
+		}
+		ps.unsubscribe = unsubscribe
+		return nil
+	}
+
+	ps.pubsub = ps.client.Subscribe(ctx, ps.Channels()...)
+	if len(ps.patterns) > 0 {
+		if err := ps.pubsub.PSubscribe(ctx, ps.patterns...); err != nil {
+			_ = ps.pubsub.Close()
+			ps.pubsub = nil
+			return err
+		}
+	}
 
 	ps.wg.Add(1)
 	go ps.listenForEvents()
@@ -46,14 +183,213 @@ func (ps *PubSubSynchronizer) Subscribe(ctx context.Context) error {
 	return nil
 }
 
-// Publish publishes an invalidation event.
+// WaitReady blocks until the subscription is confirmed active on every channel.
+// It publishes a ping on each subscribed channel and waits for its own pings to be
+// received back, retrying until ctx is done. Once it returns nil, events published
+// by other pods are delivered to this synchronizer.
+func (ps *PubSubSynchronizer) WaitReady(ctx context.Context) error {
+	if ps.pubsub == nil && ps.unsubscribe == nil {
+		return ErrNotSubscribed
+	}
+
+	pending := make(map[string]string) // channel -> ping token
+	for _, channel := range ps.Channels() {
+		token, err := newPingToken()
+		if err != nil {
+			return err
+		}
+		token = ps.instance + "." + token
+		pending[channel] = token
+		ps.expectPing(token)
+		defer ps.forgetPing(token)
+	}
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		for channel, token := range pending {
+			if ps.pingReceived(token) {
+				delete(pending, channel)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		// Pings published before the subscription is active are lost, so keep
+		// re-publishing until each one makes the round trip.
+		for channel, token := range pending {
+			if err := ps.publishTo(ctx, channel, InvalidationEvent{Key: token, Sender: ps.podID, Action: actionPing}); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// expectPing registers a ping token awaiting its round trip.
+func (ps *PubSubSynchronizer) expectPing(token string) {
+	ps.pingsMutex.Lock()
+	ps.pings[token] = false
+	ps.pingsMutex.Unlock()
+}
+
+// pingReceived reports whether the ping with the given token has come back.
+func (ps *PubSubSynchronizer) pingReceived(token string) bool {
+	ps.pingsMutex.Lock()
+	defer ps.pingsMutex.Unlock()
+	return ps.pings[token]
+}
+
+// forgetPing unregisters a ping token.
+func (ps *PubSubSynchronizer) forgetPing(token string) {
+	ps.pingsMutex.Lock()
+	delete(ps.pings, token)
+	ps.pingsMutex.Unlock()
+}
+
+// handlePing marks one of our own pings as received. A ping with our pod ID but
+// not issued by this synchronizer comes from another pod using the same pod ID.
+func (ps *PubSubSynchronizer) handlePing(event InvalidationEvent) {
+	if event.Sender != ps.podID {
+		return
+	}
+	if !strings.HasPrefix(event.Key, ps.instance+".") {
+		if !ps.receiveOwn {
+			ps.reportDuplicate()
+		}
+		return
+	}
+	ps.pingsMutex.Lock()
+	if _, ok := ps.pings[event.Key]; ok {
+		ps.pings[event.Key] = true
+	}
+	ps.pingsMutex.Unlock()
+}
+
+// newPingToken returns a random token identifying a single ping.
+func newPingToken() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Publish publishes an invalidation event, numbering it with the next sequence
+// of its channel so that subscribers can detect lost events, and fans mutations
+// out to the additional channels. The sequence of an event that fails to publish
+// is handed back, see CancelEvent.
 func (ps *PubSubSynchronizer) Publish(ctx context.Context, event InvalidationEvent) error {
-	data, err := json.Marshal(event)
+	channel, event := ps.number(event)
+	err := ps.publish(ctx, channel, event)
+	if err != nil {
+		ps.unnumber(channel, event.Seq)
+	}
+	return err
+}
+
+// publish publishes a numbered event on channel and, for mutations, on the
+// additional channels.
+func (ps *PubSubSynchronizer) publish(ctx context.Context, channel string, event InvalidationEvent) error {
+	if len(ps.additional) == 0 || !isMutation(event.Action) {
+		return ps.publishTo(ctx, channel, event)
+	}
+
+	data, err := EncodeEvent(event, ps.encoding)
+	if err != nil {
+		return err
+	}
+	message := string(data)
+	_, err = ps.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Publish(ctx, channel, message)
+		for _, additional := range ps.additional {
+			if additional != channel {
+				pipe.Publish(ctx, additional, message)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// isMutation reports whether action changes cached values.
+func isMutation(action types.Action) bool {
+	switch action {
+	case types.Set, types.Invalidate, types.Delete, types.Clear:
+		return true
+	}
+	return false
+}
+
+// PrepareEvent numbers event like Publish and returns the channel and message to
+// publish it with, for callers publishing it within their own Redis transaction.
+func (ps *PubSubSynchronizer) PrepareEvent(event InvalidationEvent) (string, []byte, error) {
+	channel, event := ps.number(event)
+	data, err := EncodeEvent(event, ps.encoding)
+	if err != nil {
+		return "", nil, err
+	}
+	return channel, data, nil
+}
+
+// CancelEvent hands back the sequence of an event returned by PrepareEvent that
+// was not published, so that subscribers do not detect a gap. The sequence is
+// only handed back while no other event of the channel was numbered since.
+func (ps *PubSubSynchronizer) CancelEvent(channel string, message []byte) {
+	event, err := DecodeEvent(message)
+	if err != nil {
+		return
+	}
+	ps.unnumber(channel, event.Seq)
+}
+
+// unnumber hands back seq, the sequence of an event of channel that was not
+// published, unless another event of channel was numbered since.
+func (ps *PubSubSynchronizer) unnumber(channel string, seq uint64) {
+	ps.seqsMutex.Lock()
+	defer ps.seqsMutex.Unlock()
+	if ps.seqs[channel] == seq {
+		ps.seqs[channel]--
+	}
+}
+
+// number returns the channel for event and the event numbered with the next
+// sequence of that channel and stamped with the current time, and with the
+// instance of this synchronizer when it receives its pod ID's events. Heartbeats
+// are keyed by the instance of this synchronizer to detect duplicate pod IDs.
+func (ps *PubSubSynchronizer) number(event InvalidationEvent) (string, InvalidationEvent) {
+	channel := ps.ChannelForKey(event.Key)
+	if event.Action == types.Heartbeat {
+		event.Key = ps.instance
+	}
+
+	ps.seqsMutex.Lock()
+	ps.seqs[channel]++
+	event.Seq = ps.seqs[channel]
+	ps.seqsMutex.Unlock()
+	event.Time = time.Now().UnixNano()
+	if ps.receiveOwn {
+		event.Origin = ps.instance
+	}
+
+	return channel, event
+}
+
+// publishTo publishes an event on a specific channel.
+func (ps *PubSubSynchronizer) publishTo(ctx context.Context, channel string, event InvalidationEvent) error {
+	data, err := EncodeEvent(event, ps.encoding)
 	if err != nil {
 		return err
 	}
 
-	return ps.client.Publish(ctx, ps.channel, string(data)).Err()
+	return ps.client.Publish(ctx, channel, string(data)).Err()
 }
 
 // OnInvalidate registers a callback for invalidation events.
@@ -63,11 +399,63 @@ func (ps *PubSubSynchronizer) OnInvalidate(callback func(event InvalidationEvent
 	ps.callbacks = append(ps.callbacks, callback)
 }
 
+// OnGap registers a callback for lost events, detected from gaps in the sequence
+// numbers received from each sender on each channel.
+func (ps *PubSubSynchronizer) OnGap(callback func(gap types.EventGap)) {
+	ps.callbacksMutex.Lock()
+	defer ps.callbacksMutex.Unlock()
+	ps.gapCallbacks = append(ps.gapCallbacks, callback)
+}
+
+// OnDuplicatePodID registers a callback for events revealing another pod
+// publishing with the pod ID of this synchronizer. Such pods ignore each other's
+// events as their own.
+func (ps *PubSubSynchronizer) OnDuplicatePodID(callback func()) {
+	ps.callbacksMutex.Lock()
+	defer ps.callbacksMutex.Unlock()
+	ps.dupCallbacks = append(ps.dupCallbacks, callback)
+}
+
+// reportDuplicate calls the OnDuplicatePodID callbacks.
+func (ps *PubSubSynchronizer) reportDuplicate() {
+	ps.callbacksMutex.RLock()
+	callbacks := ps.dupCallbacks
+	ps.callbacksMutex.RUnlock()
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
+// checkSequence records the sequence of an event received on channel and returns
+// the gap since the previous event of the same sender, if any. A sequence lower
+// than or equal to the last one means the sender restarted and is not a gap.
+func (ps *PubSubSynchronizer) checkSequence(channel string, event InvalidationEvent) (types.EventGap, bool) {
+	if event.Seq == 0 {
+		return types.EventGap{}, false
+	}
+
+	key := event.Sender + "\x00" + event.Origin + "\x00" + channel
+	ps.seqsMutex.Lock()
+	last, seen := ps.lastSeqs[key]
+	ps.lastSeqs[key] = event.Seq
+	ps.seqsMutex.Unlock()
+
+	if !seen || event.Seq <= last+1 {
+		return types.EventGap{}, false
+	}
+	return types.EventGap{Sender: event.Sender, Channel: channel, From: last + 1, To: event.Seq - 1}, true
+}
+
 // Close closes the synchronizer.
 func (ps *PubSubSynchronizer) Close() error {
 	close(ps.done)
 	ps.wg.Wait()
 
+	if ps.unsubscribe != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), unsubscribeTimeout)
+		defer cancel()
+		return ps.unsubscribe(ctx)
+	}
 	if ps.pubsub != nil {
 		return ps.pubsub.Close()
 	}
@@ -92,24 +480,61 @@ func (ps *PubSubSynchronizer) listenForEvents() {
 			if msg == nil {
 				return
 			}
+			ps.handleMessage(msg)
+		}
+	}
+}
 
-			var event InvalidationEvent
-			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
-				continue
-			}
+// handleMessage delivers an event received on the subscription to the callbacks.
+func (ps *PubSubSynchronizer) handleMessage(msg *redis.Message) {
+	// Messages of subscribed channels matching a pattern are received twice
+	if msg.Pattern != "" && slices.Contains(ps.Channels(), msg.Channel) {
+		return
+	}
+	event, err := DecodeEvent([]byte(msg.Payload))
+	if err != nil {
+		return
+	}
+	event.Channel = msg.Channel
 
-			// Don't invalidate your own writes
-			if event.Sender == ps.podID {
-				continue
-			}
+	// Pings only confirm the subscription and are never delivered to callbacks
+	if event.Action == actionPing {
+		ps.handlePing(event)
+		return
+	}
 
-			ps.callbacksMutex.RLock()
-			callbacks := ps.callbacks
-			ps.callbacksMutex.RUnlock()
+	// Don't invalidate your own writes
+	if ps.ownEvent(event) {
+		if event.Action == types.Heartbeat && event.Key != ps.instance {
+			ps.reportDuplicate()
+		}
+		return
+	}
 
-			for _, callback := range callbacks {
-				callback(event)
-			}
+	ps.callbacksMutex.RLock()
+	callbacks := ps.callbacks
+	gapCallbacks := ps.gapCallbacks
+	ps.callbacksMutex.RUnlock()
+
+	if gap, ok := ps.checkSequence(msg.Channel, event); ok {
+		for _, callback := range gapCallbacks {
+			callback(gap)
 		}
 	}
+
+	for _, callback := range callbacks {
+		callback(event)
+	}
 }
+
+// ownEvent reports whether event was published by this synchronizer: by any
+// synchronizer with its pod ID, unless SetReceiveOwnEvents is enabled.
+func (ps *PubSubSynchronizer) ownEvent(event InvalidationEvent) bool {
+	if event.Sender != ps.podID {
+		return false
+	}
+	return !ps.receiveOwn || event.Origin == "" || event.Origin == ps.instance
+}
+
+// ErrNotSubscribed is returned by WaitReady when Subscribe has not been called.
+var ErrNotSubscribed = errors.New("synchronizer is not subscribed")
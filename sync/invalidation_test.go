@@ -2,9 +2,11 @@ package sync
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/redis/go-redis/v9"
 
 	"github.com/huykn/distributed-cache/types"
@@ -362,3 +364,454 @@ func TestPubSubSynchronizerClearAction(t *testing.T) {
 		t.Fatal("Timeout waiting for event")
 	}
 }
+
+func TestPubSubSynchronizerChannelForKey(t *testing.T) {
+	client := setupRedisClient(t)
+	defer client.Close()
+
+	sync := NewPubSubSynchronizer(client, "default-channel", "pod-1")
+	sync.SetPrefixChannels(map[string]string{
+		"user:":       "users-channel",
+		"user:admin:": "admins-channel",
+		"order:":      "users-channel",
+	})
+
+	tests := map[string]string{
+		"user:42":      "users-channel",
+		"user:admin:1": "admins-channel",
+		"order:7":      "users-channel",
+		"product:1":    "default-channel",
+		"*":            "default-channel",
+	}
+	for key, expected := range tests {
+		if got := sync.ChannelForKey(key); got != expected {
+			t.Fatalf("Expected channel %q for key %q, got %q", expected, key, got)
+		}
+	}
+
+	channels := sync.Channels()
+	if len(channels) != 3 {
+		t.Fatalf("Expected 3 distinct channels, got %v", channels)
+	}
+	if channels[0] != "default-channel" {
+		t.Fatalf("Expected default channel first, got %v", channels)
+	}
+}
+
+func TestPubSubSynchronizerPrefixChannelIsolation(t *testing.T) {
+	client := setupRedisClient(t)
+	defer client.Close()
+
+	// Service A routes "a:" keys to its own channel
+	syncA1 := NewPubSubSynchronizer(client, "test-channel-prefix", "pod-a1")
+	syncA1.SetPrefixChannels(map[string]string{"a:": "test-channel-prefix-a"})
+	defer syncA1.Close()
+
+	syncA2 := NewPubSubSynchronizer(client, "test-channel-prefix", "pod-a2")
+	syncA2.SetPrefixChannels(map[string]string{"a:": "test-channel-prefix-a"})
+	defer syncA2.Close()
+
+	// Service B only listens on its own channel
+	syncB := NewPubSubSynchronizer(client, "test-channel-prefix-b", "pod-b")
+	defer syncB.Close()
+
+	ctx := context.Background()
+	syncA1.Subscribe(ctx)
+	syncA2.Subscribe(ctx)
+	syncB.Subscribe(ctx)
+
+	time.Sleep(100 * time.Millisecond)
+
+	receivedA := make(chan InvalidationEvent, 1)
+	syncA2.OnInvalidate(func(event InvalidationEvent) {
+		receivedA <- event
+	})
+	receivedB := make(chan InvalidationEvent, 1)
+	syncB.OnInvalidate(func(event InvalidationEvent) {
+		receivedB <- event
+	})
+
+	event := InvalidationEvent{
+		Key:    "a:1",
+		Sender: "pod-a1",
+		Action: types.Invalidate,
+	}
+	if err := syncA1.Publish(ctx, event); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	select {
+	case receivedEvent := <-receivedA:
+		if receivedEvent.Key != "a:1" {
+			t.Fatalf("Expected key 'a:1', got %s", receivedEvent.Key)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for event on prefix channel")
+	}
+
+	select {
+	case <-receivedB:
+		t.Fatal("Service B should not receive events from service A's channel")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestPubSubSynchronizerWaitReady(t *testing.T) {
+	client := setupRedisClient(t)
+	defer client.Close()
+
+	sync := NewPubSubSynchronizer(client, "test-channel-ready", "pod-1")
+	sync.SetPrefixChannels(map[string]string{"a:": "test-channel-ready-a"})
+	defer sync.Close()
+
+	received := make(chan InvalidationEvent, 1)
+	sync.OnInvalidate(func(event InvalidationEvent) {
+		received <- event
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := sync.Subscribe(ctx); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if err := sync.WaitReady(ctx); err != nil {
+		t.Fatalf("WaitReady failed: %v", err)
+	}
+
+	select {
+	case event := <-received:
+		t.Fatalf("Pings should not be delivered to callbacks, got %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestPubSubSynchronizerWaitReadyWithoutSubscribe(t *testing.T) {
+	client := setupRedisClient(t)
+	defer client.Close()
+
+	sync := NewPubSubSynchronizer(client, "test-channel-ready", "pod-1")
+	if err := sync.WaitReady(context.Background()); err != ErrNotSubscribed {
+		t.Fatalf("Expected ErrNotSubscribed, got %v", err)
+	}
+}
+
+func TestPubSubSynchronizerPrepareEvent(t *testing.T) {
+	// Preparing an event does not touch Redis
+	sync := NewPubSubSynchronizer(nil, "test-channel", "pod-1")
+	sync.SetPrefixChannels(map[string]string{"user:": "users-channel"})
+
+	for i, want := range []uint64{1, 2} {
+		channel, message, err := sync.PrepareEvent(InvalidationEvent{Key: "user:1", Sender: "pod-1", Action: types.Set})
+		if err != nil {
+			t.Fatalf("PrepareEvent failed: %v", err)
+		}
+		if channel != "users-channel" {
+			t.Errorf("Expected the prefix channel, got %s", channel)
+		}
+		var event InvalidationEvent
+		if err := json.Unmarshal(message, &event); err != nil {
+			t.Fatalf("Failed to decode message: %v", err)
+		}
+		if event.Key != "user:1" || event.Seq != want {
+			t.Errorf("Event %d: expected key user:1 with seq %d, got %+v", i, want, event)
+		}
+	}
+}
+
+func TestPubSubSynchronizerCancelEvent(t *testing.T) {
+	sync := NewPubSubSynchronizer(nil, "test-channel", "pod-1")
+	prepare := func() (string, []byte) {
+		channel, message, err := sync.PrepareEvent(InvalidationEvent{Key: "key", Sender: "pod-1", Action: types.Set})
+		if err != nil {
+			t.Fatalf("PrepareEvent failed: %v", err)
+		}
+		return channel, message
+	}
+	seq := func(message []byte) uint64 {
+		event, err := DecodeEvent(message)
+		if err != nil {
+			t.Fatalf("Failed to decode message: %v", err)
+		}
+		return event.Seq
+	}
+
+	channel, first := prepare()
+	sync.CancelEvent(channel, first)
+	if _, message := prepare(); seq(message) != 1 {
+		t.Errorf("Expected the canceled sequence to be reused, got %d", seq(message))
+	}
+
+	// A sequence numbered since can no longer be handed back
+	_, second := prepare()
+	_, third := prepare()
+	sync.CancelEvent(channel, second)
+	if _, message := prepare(); seq(message) != seq(third)+1 {
+		t.Errorf("Expected the sequence after %d, got %d", seq(third), seq(message))
+	}
+}
+
+func TestPubSubSynchronizerCheckSequence(t *testing.T) {
+	// Sequence tracking does not touch Redis
+	sync := NewPubSubSynchronizer(nil, "test-channel", "pod-1")
+
+	steps := []struct {
+		sender  string
+		seq     uint64
+		gap     bool
+		from    uint64
+		to      uint64
+		channel string
+	}{
+		{sender: "pod-2", seq: 5, channel: "a"},                            // first event from sender
+		{sender: "pod-2", seq: 6, channel: "a"},                            // in order
+		{sender: "pod-2", seq: 9, channel: "a", gap: true, from: 7, to: 8}, // lost 7 and 8
+		{sender: "pod-2", seq: 1, channel: "a"},                            // sender restarted
+		{sender: "pod-2", seq: 1, channel: "b"},                            // sequences are per channel
+		{sender: "pod-3", seq: 4, channel: "a"},                            // and per sender
+		{sender: "pod-3", seq: 0, channel: "a"},                            // unnumbered events are ignored
+		{sender: "pod-3", seq: 5, channel: "a"},
+	}
+	for i, step := range steps {
+		gap, ok := sync.checkSequence(step.channel, InvalidationEvent{Sender: step.sender, Seq: step.seq})
+		if ok != step.gap {
+			t.Fatalf("Step %d: expected gap %v, got %v", i, step.gap, ok)
+		}
+		if ok && (gap.From != step.from || gap.To != step.to || gap.Sender != step.sender || gap.Channel != step.channel) {
+			t.Fatalf("Step %d: unexpected gap %+v", i, gap)
+		}
+	}
+}
+
+func TestPubSubSynchronizerDetectsDuplicatePodID(t *testing.T) {
+	// Ping handling does not touch Redis
+	sync := NewPubSubSynchronizer(nil, "test-channel", "pod-1")
+	duplicates := 0
+	sync.OnDuplicatePodID(func() { duplicates++ })
+
+	own := sync.instance + ".0123456789abcdef"
+	sync.expectPing(own)
+	sync.handlePing(InvalidationEvent{Key: own, Sender: "pod-1", Action: actionPing})
+	if !sync.pingReceived(own) || duplicates != 0 {
+		t.Fatalf("Expected the own ping to be received, got received=%v duplicates=%d", sync.pingReceived(own), duplicates)
+	}
+
+	other := NewPubSubSynchronizer(nil, "test-channel", "pod-1")
+	sync.handlePing(InvalidationEvent{Key: other.instance + ".0123456789abcdef", Sender: "pod-1", Action: actionPing})
+	sync.handlePing(InvalidationEvent{Key: other.instance + ".0123456789abcdef", Sender: "pod-2", Action: actionPing})
+	if duplicates != 1 {
+		t.Fatalf("Expected the ping of another pod with the same ID to be reported once, got %d", duplicates)
+	}
+}
+
+func TestPubSubSynchronizerDetectsDuplicateHeartbeat(t *testing.T) {
+	// Message handling does not touch Redis
+	sync := NewPubSubSynchronizer(nil, "test-channel", "pod-1")
+	duplicates := 0
+	sync.OnDuplicatePodID(func() { duplicates++ })
+
+	heartbeat := func(key string) *redis.Message {
+		payload, err := EncodeEvent(InvalidationEvent{Key: key, Sender: "pod-1", Action: types.Heartbeat}, EventEncodingJSON)
+		if err != nil {
+			t.Fatalf("EncodeEvent failed: %v", err)
+		}
+		return &redis.Message{Channel: "test-channel", Payload: string(payload)}
+	}
+
+	sync.handleMessage(heartbeat(sync.instance))
+	if duplicates != 0 {
+		t.Fatalf("Expected the own heartbeat to be ignored, got %d duplicates", duplicates)
+	}
+
+	other := NewPubSubSynchronizer(nil, "test-channel", "pod-1")
+	sync.handleMessage(heartbeat(other.instance))
+	if duplicates != 1 {
+		t.Fatalf("Expected the heartbeat of another pod with the same ID to be reported, got %d", duplicates)
+	}
+}
+
+func TestPubSubSynchronizerReceiveOwnEvents(t *testing.T) {
+	// Message handling does not touch Redis
+	sync := NewPubSubSynchronizer(nil, "test-channel", "pod-1")
+	sync.SetReceiveOwnEvents(true)
+	other := NewPubSubSynchronizer(nil, "test-channel", "pod-1")
+	other.SetReceiveOwnEvents(true)
+	var received []string
+	sync.OnInvalidate(func(event InvalidationEvent) { received = append(received, event.Key) })
+	duplicates := 0
+	sync.OnDuplicatePodID(func() { duplicates++ })
+
+	message := func(ps *PubSubSynchronizer, key string, action types.Action) *redis.Message {
+		_, event := ps.number(InvalidationEvent{Key: key, Sender: "pod-1", Action: action})
+		payload, err := EncodeEvent(event, EventEncodingBinary)
+		if err != nil {
+			t.Fatalf("EncodeEvent failed: %v", err)
+		}
+		return &redis.Message{Channel: "test-channel", Payload: string(payload)}
+	}
+
+	sync.handleMessage(message(sync, "own", types.Invalidate))
+	sync.handleMessage(message(other, "sibling", types.Invalidate))
+	legacy, _ := EncodeEvent(InvalidationEvent{Key: "legacy", Sender: "pod-1", Action: types.Invalidate}, EventEncodingJSON)
+	sync.handleMessage(&redis.Message{Channel: "test-channel", Payload: string(legacy)})
+	if len(received) != 1 || received[0] != "sibling" {
+		t.Errorf("Expected only the event of the other instance to be delivered, got %v", received)
+	}
+
+	sync.handleMessage(message(other, "", types.Heartbeat))
+	sync.handlePing(InvalidationEvent{Key: other.instance + ".0123456789abcdef", Sender: "pod-1", Action: actionPing})
+	if duplicates != 0 {
+		t.Errorf("Expected instances sharing the pod ID not to be reported as duplicates, got %d", duplicates)
+	}
+}
+
+func TestPubSubSynchronizerPatternChannels(t *testing.T) {
+	client := setupRedisClient(t)
+	defer client.Close()
+
+	listener := NewPubSubSynchronizer(client, "test-tenants:a", "pod-1")
+	listener.SetPatternChannels([]string{"test-tenants:*"})
+	defer listener.Close()
+	received := make(chan InvalidationEvent, 4)
+	listener.OnInvalidate(func(event InvalidationEvent) {
+		received <- event
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := listener.Subscribe(ctx); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if err := listener.WaitReady(ctx); err != nil {
+		t.Fatalf("WaitReady failed: %v", err)
+	}
+
+	for _, channel := range []string{"test-tenants:a", "test-tenants:b"} {
+		publisher := NewPubSubSynchronizer(client, channel, "pod-2")
+		if err := publisher.Publish(ctx, InvalidationEvent{Key: channel, Sender: "pod-2", Action: types.Invalidate}); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-received:
+			if event.Channel != event.Key {
+				t.Errorf("Expected the event to be received on %s, got %s", event.Key, event.Channel)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting for events of the pattern channels")
+		}
+	}
+	select {
+	case event := <-received:
+		t.Fatalf("Expected each event once, got %+v again", event)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestPubSubSynchronizerPatternMessages(t *testing.T) {
+	// Message handling does not touch Redis
+	sync := NewPubSubSynchronizer(nil, "tenants:a", "pod-1")
+	sync.SetPatternChannels([]string{"tenants:*"})
+	var channels []string
+	sync.OnInvalidate(func(event InvalidationEvent) { channels = append(channels, event.Channel) })
+
+	payload, _ := EncodeEvent(InvalidationEvent{Key: "k", Sender: "pod-2", Action: types.Invalidate}, EventEncodingJSON)
+	sync.handleMessage(&redis.Message{Channel: "tenants:a", Payload: string(payload)})
+	sync.handleMessage(&redis.Message{Channel: "tenants:a", Pattern: "tenants:*", Payload: string(payload)})
+	sync.handleMessage(&redis.Message{Channel: "tenants:b", Pattern: "tenants:*", Payload: string(payload)})
+	if len(channels) != 2 || channels[0] != "tenants:a" || channels[1] != "tenants:b" {
+		t.Errorf("Expected one event per channel routed by channel, got %v", channels)
+	}
+}
+
+func TestPubSubSynchronizerAdditionalChannels(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	audit := client.Subscribe(ctx, "test-audit")
+	defer audit.Close()
+	if _, err := audit.Receive(ctx); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	sync := NewPubSubSynchronizer(client, "test-channel", "pod-1")
+	sync.SetAdditionalChannels([]string{"test-audit"})
+	if err := sync.Publish(ctx, InvalidationEvent{Key: "k", Sender: "pod-1", Action: types.Delete}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := sync.Publish(ctx, InvalidationEvent{Key: "*", Sender: "pod-1", Action: types.Heartbeat}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	msg, err := audit.ReceiveMessage(ctx)
+	if err != nil {
+		t.Fatalf("Expected the mutation on the additional channel: %v", err)
+	}
+	event, err := DecodeEvent([]byte(msg.Payload))
+	if err != nil || event.Key != "k" || event.Action != types.Delete || event.Seq != 1 {
+		t.Fatalf("Expected the delete of k numbered on the main channel, got %+v (%v)", event, err)
+	}
+	select {
+	case msg := <-audit.Channel():
+		t.Fatalf("Expected only mutations on the additional channel, got %s", msg.Payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestPubSubSynchronizerDetectsGap(t *testing.T) {
+	client := setupRedisClient(t)
+	defer client.Close()
+
+	sender := NewPubSubSynchronizer(client, "test-channel-gap", "pod-1")
+	defer sender.Close()
+	receiver := NewPubSubSynchronizer(client, "test-channel-gap", "pod-2")
+	defer receiver.Close()
+
+	ctx := context.Background()
+	if err := receiver.Subscribe(ctx); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if err := receiver.WaitReady(ctx); err != nil {
+		t.Fatalf("WaitReady failed: %v", err)
+	}
+
+	received := make(chan InvalidationEvent, 10)
+	receiver.OnInvalidate(func(event InvalidationEvent) {
+		received <- event
+	})
+	gaps := make(chan types.EventGap, 1)
+	receiver.OnGap(func(gap types.EventGap) {
+		gaps <- gap
+	})
+
+	event := InvalidationEvent{Key: "key", Sender: "pod-1", Action: types.Invalidate}
+	if err := sender.Publish(ctx, event); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	// Simulate the loss of the second event
+	sender.seqs["test-channel-gap"]++
+	if err := sender.Publish(ctx, event); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-received:
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for event")
+		}
+	}
+
+	select {
+	case gap := <-gaps:
+		if gap.Sender != "pod-1" || gap.From != 2 || gap.To != 2 || gap.Missed() != 1 {
+			t.Fatalf("Unexpected gap %+v", gap)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for gap")
+	}
+}
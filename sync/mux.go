@@ -0,0 +1,208 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PubSubMux multiplexes the subscriptions of several PubSubSynchronizers over a
+// single Redis Pub/Sub connection, routing each message to the synchronizers
+// subscribed to its channel or pattern. Messages are delivered on one goroutine, so a slow
+// handler delays the messages of every synchronizer sharing the connection.
+type PubSubMux struct {
+	client   redis.UniversalClient
+	mu       sync.Mutex
+	pubsub   *redis.PubSub
+	routes   map[string]map[int]func(msg *redis.Message)
+	patterns map[string]map[int]func(msg *redis.Message)
+	nextID   int
+	closed   bool
+	done     chan struct{}
+	wg       sync.WaitGroup
+	listener sync.Once
+}
+
+// NewPubSubMux creates a Pub/Sub multiplexer on client. The Pub/Sub connection is
+// opened by the first subscription and closed by Close; client is not closed.
+func NewPubSubMux(client redis.UniversalClient) *PubSubMux {
+	return &PubSubMux{
+		client:   client,
+		routes:   make(map[string]map[int]func(msg *redis.Message)),
+		patterns: make(map[string]map[int]func(msg *redis.Message)),
+		done:     make(chan struct{}),
+	}
+}
+
+// Subscribe routes the messages received on channels to handler, subscribing the
+// shared connection to the channels it is not subscribed to yet. The returned
+// function removes handler, unsubscribing from channels left without handlers.
+func (m *PubSubMux) Subscribe(ctx context.Context, channels []string, handler func(msg *redis.Message)) (func(ctx context.Context) error, error) {
+	return m.subscribe(ctx, channels, nil, handler)
+}
+
+// SubscribePatterns is Subscribe for both channels and glob-style patterns, e.g.
+// "cache:invalidate:*", routing the messages received through a pattern to the
+// handlers of that pattern.
+func (m *PubSubMux) SubscribePatterns(ctx context.Context, channels, patterns []string, handler func(msg *redis.Message)) (func(ctx context.Context) error, error) {
+	return m.subscribe(ctx, channels, patterns, handler)
+}
+
+// subscribe registers handler for channels and patterns.
+func (m *PubSubMux) subscribe(ctx context.Context, channels, patterns []string, handler func(msg *redis.Message)) (func(ctx context.Context) error, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, ErrMuxClosed
+	}
+
+	if added := unrouted(m.routes, channels); len(added) > 0 {
+		if m.pubsub == nil {
+			m.pubsub = m.client.Subscribe(ctx, added...)
+		} else if err := m.pubsub.Subscribe(ctx, added...); err != nil {
+			return nil, err
+		}
+	}
+	if added := unrouted(m.patterns, patterns); len(added) > 0 {
+		if m.pubsub == nil {
+			m.pubsub = m.client.PSubscribe(ctx, added...)
+		} else if err := m.pubsub.PSubscribe(ctx, added...); err != nil {
+			return nil, err
+		}
+	}
+
+	id := m.nextID
+	m.nextID++
+	route(m.routes, channels, id, handler)
+	route(m.patterns, patterns, id, handler)
+	m.listener.Do(func() {
+		m.wg.Add(1)
+		go m.listen(m.pubsub.Channel())
+	})
+
+	return func(ctx context.Context) error {
+		return m.unsubscribe(ctx, channels, patterns, id)
+	}, nil
+}
+
+// unrouted returns the names without handlers in routes.
+func unrouted(routes map[string]map[int]func(msg *redis.Message), names []string) []string {
+	var added []string
+	for _, name := range names {
+		if len(routes[name]) == 0 {
+			added = append(added, name)
+		}
+	}
+	return added
+}
+
+// route registers handler under id for names in routes.
+func route(routes map[string]map[int]func(msg *redis.Message), names []string, id int, handler func(msg *redis.Message)) {
+	for _, name := range names {
+		if routes[name] == nil {
+			routes[name] = make(map[int]func(msg *redis.Message))
+		}
+		routes[name][id] = handler
+	}
+}
+
+// unroute removes the handler registered under id for names in routes and
+// returns the names left without handlers.
+func unroute(routes map[string]map[int]func(msg *redis.Message), names []string, id int) []string {
+	var removed []string
+	for _, name := range names {
+		if _, ok := routes[name][id]; !ok {
+			continue
+		}
+		delete(routes[name], id)
+		if len(routes[name]) == 0 {
+			delete(routes, name)
+			removed = append(removed, name)
+		}
+	}
+	return removed
+}
+
+// unsubscribe removes the handler registered under id from channels and patterns.
+func (m *PubSubMux) unsubscribe(ctx context.Context, channels, patterns []string, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := unroute(m.routes, channels, id)
+	removedPatterns := unroute(m.patterns, patterns, id)
+	if m.closed {
+		return nil
+	}
+	var err error
+	if len(removed) > 0 {
+		err = m.pubsub.Unsubscribe(ctx, removed...)
+	}
+	if len(removedPatterns) > 0 {
+		err = errors.Join(err, m.pubsub.PUnsubscribe(ctx, removedPatterns...))
+	}
+	return err
+}
+
+// Channels returns the number of channels the shared connection is subscribed to.
+func (m *PubSubMux) Channels() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.routes)
+}
+
+// Close closes the shared Pub/Sub connection. Synchronizers still subscribed stop
+// receiving events.
+func (m *PubSubMux) Close() error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	pubsub := m.pubsub
+	m.mu.Unlock()
+
+	close(m.done)
+	m.wg.Wait()
+	if pubsub != nil {
+		return pubsub.Close()
+	}
+	return nil
+}
+
+// listen delivers the messages received on the shared connection to the handlers
+// of their channel, or of their pattern when received through one.
+func (m *PubSubMux) listen(ch <-chan *redis.Message) {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case msg := <-ch:
+			if msg == nil {
+				return
+			}
+
+			m.mu.Lock()
+			routes := m.routes[msg.Channel]
+			if msg.Pattern != "" {
+				routes = m.patterns[msg.Pattern]
+			}
+			handlers := make([]func(msg *redis.Message), 0, len(routes))
+			for _, handler := range routes {
+				handlers = append(handlers, handler)
+			}
+			m.mu.Unlock()
+
+			for _, handler := range handlers {
+				handler(msg)
+			}
+		}
+	}
+}
+
+// ErrMuxClosed is returned by PubSubMux.Subscribe once the multiplexer is closed.
+var ErrMuxClosed = errors.New("pub/sub multiplexer is closed")
@@ -0,0 +1,84 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/huykn/distributed-cache/types"
+)
+
+func TestPubSubMuxRoutesByChannel(t *testing.T) {
+	client := setupRedisClient(t)
+	defer client.Close()
+
+	mux := NewPubSubMux(client)
+	defer mux.Close()
+
+	// Two tenants share the mux, a peer of tenant A uses its own connection
+	tenantA := NewPubSubSynchronizer(client, "test-mux-a", "pod-1")
+	tenantA.SetMux(mux)
+	defer tenantA.Close()
+	tenantB := NewPubSubSynchronizer(client, "test-mux-b", "pod-1")
+	tenantB.SetMux(mux)
+	peerA := NewPubSubSynchronizer(client, "test-mux-a", "pod-2")
+	defer peerA.Close()
+
+	receivedA := make(chan InvalidationEvent, 1)
+	tenantA.OnInvalidate(func(event InvalidationEvent) {
+		receivedA <- event
+	})
+	receivedB := make(chan InvalidationEvent, 1)
+	tenantB.OnInvalidate(func(event InvalidationEvent) {
+		receivedB <- event
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	for _, sync := range []*PubSubSynchronizer{tenantA, tenantB, peerA} {
+		if err := sync.Subscribe(ctx); err != nil {
+			t.Fatalf("Subscribe failed: %v", err)
+		}
+		if err := sync.WaitReady(ctx); err != nil {
+			t.Fatalf("WaitReady failed: %v", err)
+		}
+	}
+	if got := mux.Channels(); got != 2 {
+		t.Fatalf("Expected the mux to be subscribed to 2 channels, got %d", got)
+	}
+
+	if err := peerA.Publish(ctx, InvalidationEvent{Key: "k", Sender: "pod-2", Action: types.Invalidate}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	select {
+	case event := <-receivedA:
+		if event.Key != "k" {
+			t.Fatalf("Expected key 'k', got %s", event.Key)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for event on the shared connection")
+	}
+	select {
+	case event := <-receivedB:
+		t.Fatalf("Tenant B should not receive events of tenant A, got %+v", event)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	if err := tenantB.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if got := mux.Channels(); got != 1 {
+		t.Fatalf("Expected closing tenant B to unsubscribe its channel, got %d channels", got)
+	}
+}
+
+func TestPubSubMuxSubscribeAfterClose(t *testing.T) {
+	// A mux that never subscribed does not touch Redis
+	mux := NewPubSubMux(nil)
+	if err := mux.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := mux.Subscribe(context.Background(), []string{"channel"}, nil); err != ErrMuxClosed {
+		t.Fatalf("Expected ErrMuxClosed, got %v", err)
+	}
+}
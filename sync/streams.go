@@ -0,0 +1,217 @@
+package sync
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/huykn/distributed-cache/types"
+)
+
+// streamBlock is how long a read waits for new entries before checking for Close.
+const streamBlock = 500 * time.Millisecond
+
+// streamRetryInterval is how long the reader waits after a failed read.
+const streamRetryInterval = time.Second
+
+// streamReadCount is the maximum number of entries read per round trip.
+const streamReadCount = 100
+
+// DefaultStreamMaxLen is the approximate number of entries kept in the stream
+// when no maximum length is configured.
+const DefaultStreamMaxLen = 10000
+
+// streamEventField is the stream entry field holding the JSON-encoded event.
+const streamEventField = "event"
+
+// StreamsSynchronizer implements cache synchronization using Redis Streams.
+// Unlike Pub/Sub, events are kept in the stream: every pod reads it through its
+// own consumer group named after its pod ID, so a pod restarting with the same
+// pod ID resumes from the last entry it acknowledged and replays the events it missed.
+type StreamsSynchronizer struct {
+	client         redis.UniversalClient
+	stream         string
+	podID          string
+	group          string
+	maxLen         int64
+	encoding       EventEncoding
+	instance       string // random key of the heartbeats of this synchronizer
+	callbacks      []func(event InvalidationEvent)
+	dupCallbacks   []func()
+	callbacksMutex sync.RWMutex
+	done           chan struct{}
+	wg             sync.WaitGroup
+}
+
+// NewStreamsSynchronizer creates a new Streams synchronizer on the given stream key.
+// The stream is trimmed to approximately maxLen entries, or DefaultStreamMaxLen when maxLen is 0.
+func NewStreamsSynchronizer(client redis.UniversalClient, stream, podID string, maxLen int64) *StreamsSynchronizer {
+	if maxLen <= 0 {
+		maxLen = DefaultStreamMaxLen
+	}
+	instance, _ := newPingToken()
+	return &StreamsSynchronizer{
+		instance:  instance,
+		client:    client,
+		stream:    stream,
+		podID:     podID,
+		group:     "cache:" + podID,
+		maxLen:    maxLen,
+		callbacks: make([]func(event InvalidationEvent), 0),
+		done:      make(chan struct{}),
+	}
+}
+
+// SetEncoding sets how published events are encoded. Received events are
+// decoded whatever their encoding. Must be called before Subscribe.
+func (ss *StreamsSynchronizer) SetEncoding(encoding EventEncoding) {
+	ss.encoding = encoding
+}
+
+// Subscribe creates the consumer group of this pod if it does not exist yet and
+// starts reading events. A new group starts at the end of the stream; an existing
+// group resumes after the last entry acknowledged by this pod.
+func (ss *StreamsSynchronizer) Subscribe(ctx context.Context) error {
+	err := ss.client.XGroupCreateMkStream(ctx, ss.stream, ss.group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	ss.wg.Add(1)
+	go ss.readEvents()
+
+	return nil
+}
+
+// Publish appends an event to the stream, stamped with the current time.
+// Heartbeats are keyed by the instance of this synchronizer to detect duplicate pod IDs.
+func (ss *StreamsSynchronizer) Publish(ctx context.Context, event InvalidationEvent) error {
+	event.Time = time.Now().UnixNano()
+	if event.Action == types.Heartbeat {
+		event.Key = ss.instance
+	}
+	data, err := EncodeEvent(event, ss.encoding)
+	if err != nil {
+		return err
+	}
+
+	return ss.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: ss.stream,
+		MaxLen: ss.maxLen,
+		Approx: true,
+		Values: map[string]any{streamEventField: string(data)},
+	}).Err()
+}
+
+// OnInvalidate registers a callback for invalidation events.
+func (ss *StreamsSynchronizer) OnInvalidate(callback func(event InvalidationEvent)) {
+	ss.callbacksMutex.Lock()
+	defer ss.callbacksMutex.Unlock()
+	ss.callbacks = append(ss.callbacks, callback)
+}
+
+// OnDuplicatePodID registers a callback for heartbeats revealing another pod
+// reading the stream with the pod ID of this synchronizer. Such pods share a
+// consumer group, each receiving only part of the events.
+func (ss *StreamsSynchronizer) OnDuplicatePodID(callback func()) {
+	ss.callbacksMutex.Lock()
+	defer ss.callbacksMutex.Unlock()
+	ss.dupCallbacks = append(ss.dupCallbacks, callback)
+}
+
+// reportDuplicate calls the OnDuplicatePodID callbacks.
+func (ss *StreamsSynchronizer) reportDuplicate() {
+	ss.callbacksMutex.RLock()
+	callbacks := ss.dupCallbacks
+	ss.callbacksMutex.RUnlock()
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
+// Close stops reading events. The consumer group is kept so that the pod can
+// resume from where it stopped.
+func (ss *StreamsSynchronizer) Close() error {
+	close(ss.done)
+	ss.wg.Wait()
+	return nil
+}
+
+// readEvents delivers the entries read by this pod but not acknowledged before a
+// restart, then new entries, until Close is called.
+func (ss *StreamsSynchronizer) readEvents() {
+	defer ss.wg.Done()
+
+	ctx := context.Background()
+	id := "0" // pending entries first, then ">" for new ones
+	for {
+		select {
+		case <-ss.done:
+			return
+		default:
+		}
+
+		streams, err := ss.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    ss.group,
+			Consumer: ss.podID,
+			Streams:  []string{ss.stream, id},
+			Count:    streamReadCount,
+			Block:    streamBlock,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			select {
+			case <-ss.done:
+				return
+			case <-time.After(streamRetryInterval):
+			}
+			continue
+		}
+
+		for _, stream := range streams {
+			if id == "0" && len(stream.Messages) == 0 {
+				id = ">"
+			}
+			for _, msg := range stream.Messages {
+				ss.deliver(msg)
+				ss.client.XAck(ctx, ss.stream, ss.group, msg.ID)
+			}
+		}
+	}
+}
+
+// deliver decodes a stream entry and passes it to the callbacks, skipping this pod's own events.
+func (ss *StreamsSynchronizer) deliver(msg redis.XMessage) {
+	payload, ok := msg.Values[streamEventField].(string)
+	if !ok {
+		return
+	}
+
+	event, err := DecodeEvent([]byte(payload))
+	if err != nil {
+		return
+	}
+	event.Channel = ss.stream
+
+	// Don't invalidate your own writes
+	if event.Sender == ss.podID {
+		if event.Action == types.Heartbeat && event.Key != ss.instance {
+			ss.reportDuplicate()
+		}
+		return
+	}
+
+	ss.callbacksMutex.RLock()
+	callbacks := ss.callbacks
+	ss.callbacksMutex.RUnlock()
+
+	for _, callback := range callbacks {
+		callback(event)
+	}
+}
@@ -0,0 +1,114 @@
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/huykn/distributed-cache/types"
+)
+
+// collect registers a callback on s sending received events to the returned channel.
+func collect(s *StreamsSynchronizer) <-chan InvalidationEvent {
+	received := make(chan InvalidationEvent, 10)
+	s.OnInvalidate(func(event InvalidationEvent) {
+		received <- event
+	})
+	return received
+}
+
+func TestStreamsSynchronizerPublishAndReceive(t *testing.T) {
+	client := setupRedisClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	pod1 := NewStreamsSynchronizer(client, "test-stream", "pod-1", 0)
+	pod2 := NewStreamsSynchronizer(client, "test-stream", "pod-2", 0)
+	defer pod1.Close()
+	defer pod2.Close()
+
+	received1 := collect(pod1)
+	received2 := collect(pod2)
+	if err := pod1.Subscribe(ctx); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if err := pod2.Subscribe(ctx); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	event := InvalidationEvent{Key: "key1", Sender: "pod-1", Action: types.Invalidate}
+	if err := pod1.Publish(ctx, event); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	select {
+	case got := <-received2:
+		if got.Key != event.Key || got.Action != event.Action {
+			t.Fatalf("Expected %+v, got %+v", event, got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Event was not received by the other pod")
+	}
+
+	select {
+	case got := <-received1:
+		t.Fatalf("Pod should not receive its own event, got %+v", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestStreamsSynchronizerReplaysMissedEvents(t *testing.T) {
+	client := setupRedisClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	pod1 := NewStreamsSynchronizer(client, "test-stream", "pod-1", 0)
+	defer pod1.Close()
+	pod2 := NewStreamsSynchronizer(client, "test-stream", "pod-2", 0)
+	if err := pod2.Subscribe(ctx); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	// pod-2 restarts and misses an event published while it is down
+	pod2.Close()
+	missed := InvalidationEvent{Key: "key1", Sender: "pod-1", Action: types.Delete}
+	if err := pod1.Publish(ctx, missed); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	restarted := NewStreamsSynchronizer(client, "test-stream", "pod-2", 0)
+	defer restarted.Close()
+	received := collect(restarted)
+	if err := restarted.Subscribe(ctx); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	select {
+	case got := <-received:
+		if got.Key != missed.Key || got.Action != missed.Action {
+			t.Fatalf("Expected %+v, got %+v", missed, got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Missed event was not replayed after restart")
+	}
+}
+
+func TestStreamsSynchronizerTrimsStream(t *testing.T) {
+	client := setupRedisClient(t)
+	defer client.Close()
+
+	ctx := context.Background()
+	s := NewStreamsSynchronizer(client, "test-stream", "pod-1", 10)
+	defer s.Close()
+
+	for i := 0; i < 1000; i++ {
+		if err := s.Publish(ctx, InvalidationEvent{Key: "key", Sender: "pod-1", Action: types.Invalidate}); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	// Trimming is approximate, so only check the stream stays bounded
+	if n := client.XLen(ctx, "test-stream").Val(); n >= 1000 {
+		t.Fatalf("Expected stream to be trimmed, got %d entries", n)
+	}
+}
@@ -0,0 +1,71 @@
+package testutil
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/huykn/distributed-cache/cache"
+)
+
+// AssertPublished fails the test unless an event with the given key and action is
+// published within DefaultTimeout, and returns the first such event.
+func (r *Redis) AssertPublished(t testing.TB, key string, action cache.Action) Event {
+	t.Helper()
+	var found Event
+	ok := eventually(func() bool {
+		for _, event := range r.Events() {
+			if event.Key == key && event.Action == action {
+				found = event
+				return true
+			}
+		}
+		return false
+	})
+	if !ok {
+		t.Fatalf("Expected a %s event for %s, got %+v", action, key, r.Events())
+	}
+	return found
+}
+
+// AssertNotPublished fails the test if an event for key has been published.
+func (r *Redis) AssertNotPublished(t testing.TB, key string) {
+	t.Helper()
+	for _, event := range r.Events() {
+		if event.Key == key {
+			t.Fatalf("Expected no event for %s, got %+v", key, event)
+		}
+	}
+}
+
+// AssertInvalidated fails the test unless key leaves the local cache of peer
+// within DefaultTimeout.
+func AssertInvalidated(t testing.TB, peer cache.Cache, key string) {
+	t.Helper()
+	if !eventually(func() bool { return !slices.Contains(peer.LocalKeys(), key) }) {
+		t.Fatalf("Expected %s to be invalidated on the peer", key)
+	}
+}
+
+// AssertLocal fails the test unless key is held in the local cache of peer
+// within DefaultTimeout.
+func AssertLocal(t testing.TB, peer cache.Cache, key string) {
+	t.Helper()
+	if !eventually(func() bool { return slices.Contains(peer.LocalKeys(), key) }) {
+		t.Fatalf("Expected %s in the local cache of the peer", key)
+	}
+}
+
+// eventually polls cond until it holds or DefaultTimeout elapses.
+func eventually(cond func() bool) bool {
+	deadline := time.Now().Add(DefaultTimeout)
+	for {
+		if cond() {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,193 @@
+// Package testutil runs distributed caches against an in-process miniredis server
+// for deterministic multi-pod tests, without a Redis server.
+//
+// Redis.Config returns a ready Config whose Redis client records every
+// synchronization event published by the cache, so tests can assert on events
+// and on their effect on peers. Published events can be dropped, or held and
+// released in another order, to simulate Pub/Sub message loss and reordering.
+package testutil
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	distributedcache "github.com/huykn/distributed-cache"
+	"github.com/huykn/distributed-cache/cache"
+	cachesync "github.com/huykn/distributed-cache/sync"
+)
+
+// DefaultTimeout bounds how long assertions wait for events to propagate.
+const DefaultTimeout = 2 * time.Second
+
+// Event is a synchronization event published on a channel.
+type Event struct {
+	Channel string
+	cache.InvalidationEvent
+}
+
+// Redis is a miniredis server shared by the caches of a test. It is safe for concurrent use.
+type Redis struct {
+	*miniredis.Miniredis
+
+	t      testing.TB
+	mu     sync.Mutex
+	events []Event
+	drop   int
+	hold   bool
+	held   []heldMessage
+}
+
+// heldMessage is a published message withheld from subscribers.
+type heldMessage struct {
+	channel string
+	message string
+}
+
+// NewRedis starts a miniredis server stopped when the test ends.
+func NewRedis(t testing.TB) *Redis {
+	t.Helper()
+	return &Redis{Miniredis: miniredis.RunT(t), t: t}
+}
+
+// Config returns a Config for a cache with the given PodID connected to the server.
+// It writes through to Redis and uses an LRU local cache, so values are visible
+// as soon as Set returns. The Redis client is closed when the test ends.
+func (r *Redis) Config(podID string) distributedcache.Config {
+	client := redis.NewClient(&redis.Options{Addr: r.Addr()})
+	client.AddHook(publishHook{r})
+	r.t.Cleanup(func() { client.Close() })
+
+	cfg := distributedcache.DefaultConfig()
+	cfg.PodID = podID
+	cfg.RedisAddr = r.Addr()
+	cfg.RedisClient = client
+	cfg.WritePolicy = distributedcache.WritePolicyWriteThrough
+	cfg.LocalCacheFactory = cache.NewLRUCacheFactory(10000)
+	cfg.ContextTimeout = DefaultTimeout
+	return cfg
+}
+
+// NewCache creates a cache from cfg, failing the test on error. The cache is
+// closed when the test ends.
+func (r *Redis) NewCache(cfg distributedcache.Config) distributedcache.Cache {
+	r.t.Helper()
+	c, err := distributedcache.New(cfg)
+	if err != nil {
+		r.t.Fatalf("Failed to create cache %s: %v", cfg.PodID, err)
+	}
+	r.t.Cleanup(func() { c.Close() })
+	return c
+}
+
+// Events returns the events published by caches created from Config, in order.
+// Dropped and held events are included.
+func (r *Redis) Events() []Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...)
+}
+
+// DropNext drops the next n published events: publishers see them succeed but
+// no subscriber receives them.
+func (r *Redis) DropNext(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.drop += n
+}
+
+// Hold withholds published events from subscribers until Release or ReleaseReversed.
+func (r *Redis) Hold() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hold = true
+}
+
+// Release delivers the held events in the order they were published and stops holding.
+func (r *Redis) Release() {
+	r.release(false)
+}
+
+// ReleaseReversed delivers the held events in reverse order and stops holding,
+// simulating reordering by the network.
+func (r *Redis) ReleaseReversed() {
+	r.release(true)
+}
+
+func (r *Redis) release(reversed bool) {
+	r.mu.Lock()
+	held := r.held
+	r.held, r.hold = nil, false
+	r.mu.Unlock()
+
+	for i := range held {
+		m := held[i]
+		if reversed {
+			m = held[len(held)-1-i]
+		}
+		r.Publish(m.channel, m.message)
+	}
+}
+
+// intercept records a published message and reports whether it must be
+// withheld from the server. Only cache data events are subject to faults, so
+// subscription readiness checks and lifecycle events are unaffected.
+func (r *Redis) intercept(channel, message string) bool {
+	event, err := cachesync.DecodeEvent([]byte(message))
+	if err != nil {
+		return false
+	}
+	switch event.Action {
+	case cache.ActionSet, cache.ActionInvalidate, cache.ActionDelete, cache.ActionClear:
+	default:
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, Event{Channel: channel, InvalidationEvent: event})
+	if r.drop > 0 {
+		r.drop--
+		return true
+	}
+	if r.hold {
+		r.held = append(r.held, heldMessage{channel: channel, message: message})
+		return true
+	}
+	return false
+}
+
+// publishHook routes PUBLISH commands through Redis.intercept.
+type publishHook struct {
+	r *Redis
+}
+
+func (h publishHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h publishHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		args := cmd.Args()
+		if cmd.Name() != "publish" || len(args) != 3 {
+			return next(ctx, cmd)
+		}
+		channel, _ := args[1].(string)
+		message, _ := args[2].(string)
+		if !h.r.intercept(channel, message) {
+			return next(ctx, cmd)
+		}
+		if intCmd, ok := cmd.(*redis.IntCmd); ok {
+			intCmd.SetVal(0)
+		}
+		return nil
+	}
+}
+
+func (h publishHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
@@ -0,0 +1,115 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/huykn/distributed-cache/cache"
+)
+
+func TestPropagationBetweenPods(t *testing.T) {
+	r := NewRedis(t)
+	a := r.NewCache(r.Config("pod-a"))
+	b := r.NewCache(r.Config("pod-b"))
+	ctx := context.Background()
+
+	if err := a.Set(ctx, "user:1", "alice"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	event := r.AssertPublished(t, "user:1", cache.ActionSet)
+	if event.Sender != "pod-a" {
+		t.Errorf("Expected the event from pod-a, got %s", event.Sender)
+	}
+	AssertLocal(t, b, "user:1")
+
+	if err := a.SetWithInvalidate(ctx, "user:1", "bob"); err != nil {
+		t.Fatalf("SetWithInvalidate failed: %v", err)
+	}
+	r.AssertPublished(t, "user:1", cache.ActionInvalidate)
+	AssertInvalidated(t, b, "user:1")
+	if value, found := b.Get(ctx, "user:1"); !found || value != "bob" {
+		t.Errorf("Expected the peer to fetch bob from Redis, got %v (found=%v)", value, found)
+	}
+}
+
+func TestDropNext(t *testing.T) {
+	r := NewRedis(t)
+	a := r.NewCache(r.Config("pod-a"))
+	b := r.NewCache(r.Config("pod-b"))
+	ctx := context.Background()
+
+	r.DropNext(1)
+	if err := a.Set(ctx, "lost", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := a.Set(ctx, "delivered", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	AssertLocal(t, b, "delivered")
+	if slices.Contains(b.LocalKeys(), "lost") {
+		t.Error("Expected the dropped event not to reach the peer")
+	}
+	r.AssertPublished(t, "lost", cache.ActionSet)
+}
+
+func TestHoldAndReleaseReversed(t *testing.T) {
+	r := NewRedis(t)
+	a := r.NewCache(r.Config("pod-a"))
+	b := r.NewCache(r.Config("pod-b"))
+	ctx := context.Background()
+
+	r.Hold()
+	for _, key := range []string{"k1", "k2"} {
+		if err := a.Set(ctx, key, "value"); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	if keys := b.LocalKeys(); len(keys) != 0 {
+		t.Fatalf("Expected held events not to reach the peer, got %v", keys)
+	}
+
+	r.ReleaseReversed()
+	AssertLocal(t, b, "k1")
+	AssertLocal(t, b, "k2")
+}
+
+// failingOps fails the listed Redis commands.
+type failingOps map[string]bool
+
+func (f failingOps) StoreFault(ctx context.Context, op string) error {
+	if f[op] {
+		return cache.ErrInjectedFault
+	}
+	return nil
+}
+
+func (f failingOps) EventFault(event cache.InvalidationEvent) cache.EventFault {
+	return cache.EventFault{Drop: event.Key == "dropped"}
+}
+
+func TestFaultInjector(t *testing.T) {
+	r := NewRedis(t)
+	cfg := r.Config("pod-a")
+	cfg.FaultInjector = failingOps{"set": true}
+	a := r.NewCache(cfg)
+	peer := r.Config("pod-b")
+	peer.FaultInjector = failingOps{}
+	b := r.NewCache(peer)
+	ctx := context.Background()
+
+	if err := a.Set(ctx, "user:1", "alice"); !errors.Is(err, cache.ErrInjectedFault) {
+		t.Fatalf("Expected ErrInjectedFault, got %v", err)
+	}
+	if err := b.Set(ctx, "dropped", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := b.Set(ctx, "delivered", "value"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	AssertLocal(t, a, "delivered")
+	if slices.Contains(a.LocalKeys(), "dropped") {
+		t.Error("Expected the dropped event not to reach the peer")
+	}
+}
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 type Action string
 
 const (
@@ -7,13 +9,46 @@ const (
 	Invalidate Action = "invalidate"
 	Delete     Action = "delete"
 	Clear      Action = "clear"
+	Lifecycle  Action = "lifecycle"
+
+	SnapshotRequest Action = "snapshot_request"
+	SnapshotChunk   Action = "snapshot_chunk"
+
+	Digest    Action = "digest"
+	Heartbeat Action = "heartbeat"
+
+	AckRequest Action = "ack_request"
+	Ack        Action = "ack"
 )
 
 // InvalidationEvent represents a cache synchronization event.
 // It can be used to propagate cache values or invalidate entries across pods.
 type InvalidationEvent struct {
-	Key    string `json:"key"`
-	Sender string `json:"sender"`
-	Action Action `json:"action"`          // "set", "invalidate", "delete", or "clear"
-	Value  []byte `json:"value,omitempty"` // Serialized value for "set" action
+	Key       string        `json:"key"`
+	Sender    string        `json:"sender"`
+	Action    Action        `json:"action"`               // "set", "invalidate", "delete", "clear", "lifecycle", "snapshot_*", "digest", "heartbeat" or "ack*"
+	Value     []byte        `json:"value,omitempty"`      // Serialized value for "set", lifecycle event, snapshot or digest payload otherwise
+	Seq       uint64        `json:"seq,omitempty"`        // Per-sender, per-channel sequence number; 0 if not numbered
+	TTL       time.Duration `json:"ttl,omitempty"`        // Time to live of a "set" value; 0 if it does not expire
+	Tags      []string      `json:"tags,omitempty"`       // Tags attached to a "set" value
+	Time      int64         `json:"ts,omitempty"`         // Unix nanoseconds the event was published at; 0 if not stamped
+	Relay     bool          `json:"relay,omitempty"`      // "set" of a value read from Redis by the sender, only filling misses
+	Clock     uint64        `json:"hlc,omitempty"`        // Hybrid logical clock of a write, ordering writes to a key across pods; 0 if not stamped
+	Origin    string        `json:"origin,omitempty"`     // Instance of the sender's synchronizer when several share its pod ID; empty otherwise
+	DependsOn []string      `json:"depends_on,omitempty"` // Keys a "set" or "invalidate" value was computed from, see WithDependsOn
+	Channel   string        `json:"-"`                    // Channel or stream the event was received on, set by synchronizers; not encoded
+}
+
+// EventGap describes synchronization events lost between two numbered events
+// received from the same sender on the same channel.
+type EventGap struct {
+	Sender  string // pod that published the lost events
+	Channel string // channel the events were lost on
+	From    uint64 // first missing sequence number
+	To      uint64 // last missing sequence number
+}
+
+// Missed returns the number of lost events.
+func (g EventGap) Missed() uint64 {
+	return g.To - g.From + 1
 }
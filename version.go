@@ -1,7 +1,9 @@
 package distributedcache
 
+import "github.com/huykn/distributed-cache/cache"
+
 // Version is the current version of the distributed-cache library.
-const Version = "v1.0.4"
+const Version = cache.Version
 
 // VersionInfo provides version information.
 type VersionInfo struct {